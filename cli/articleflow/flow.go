@@ -4,31 +4,57 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ilkoid/PonchoAiFramework/core/budget"
+	"github.com/ilkoid/PonchoAiFramework/core/structured"
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
 	"github.com/ilkoid/PonchoAiFramework/tools/s3"
 	"github.com/ilkoid/PonchoAiFramework/tools/wildberries"
 )
 
+// FlowName is the name article flow executions are budgeted and recorded under
+const FlowName = "article_flow"
+
+// Flow steps that send model requests, recorded in the usage ledger
+const (
+	StepVisionAnalysis      = "vision_analysis"
+	StepCreativeDescription = "creative_description"
+	StepSubjectSelection    = "subject_selection"
+	StepFinalPayload        = "final_payload"
+)
+
+// Generator runs model requests. PonchoFramework implements it, so requests sent
+// through it pass the framework middleware: budgets, usage ledger and response cache.
+type Generator interface {
+	Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error)
+}
+
 // ArticleFlow orchestrates the article processing pipeline
 type ArticleFlow struct {
 	s3Tool       interfaces.PonchoTool
 	visionModel  interfaces.PonchoModel
 	textModel    interfaces.PonchoModel
 	wbClient     *wildberries.WBClient
+	generator    Generator
 	logger       interfaces.Logger
 	config       *ArticleFlowConfig
 	wbCache      WBCache
 }
 
-// NewArticleFlow creates a new article flow instance
+// NewArticleFlow creates a new article flow instance.
+// With a generator, model requests go through it, addressed by the model names
+// of config.ModelParams; without one they are sent to the models directly.
 func NewArticleFlow(
 	s3Tool interfaces.PonchoTool,
 	visionModel interfaces.PonchoModel,
 	textModel interfaces.PonchoModel,
 	wbClient *wildberries.WBClient,
+	generator Generator,
 	logger interfaces.Logger,
 	config *ArticleFlowConfig,
 	wbCache WBCache,
@@ -42,6 +68,7 @@ func NewArticleFlow(
 		visionModel:  visionModel,
 		textModel:    textModel,
 		wbClient:     wbClient,
+		generator:    generator,
 		logger:       logger,
 		config:       config,
 		wbCache:      wbCache,
@@ -54,6 +81,9 @@ func (f *ArticleFlow) Run(ctx context.Context, articleID string) (*ArticleFlowSt
 		"article_id", articleID,
 	)
 
+	// Model calls of this run share the article flow budget
+	ctx = budget.WithFlowExecution(ctx, FlowName)
+
 	// Initialize state
 	state := NewArticleFlowState(articleID)
 
@@ -217,7 +247,7 @@ func (f *ArticleFlow) runVisionAnalysis(ctx context.Context, state *ArticleFlowS
 		ctx,
 		state.Images,
 		func(ctx context.Context, img ImageRef) (*TechInfo, error) {
-			return f.analyzeImageWithVision(ctx, img, visionPrompt)
+			return f.analyzeImageWithVision(ctx, state.ArticleID, img, visionPrompt)
 		},
 		f.config.Concurrency.VisionAnalysisWorkers,
 	)
@@ -239,7 +269,7 @@ func (f *ArticleFlow) runVisionAnalysis(ctx context.Context, state *ArticleFlowS
 }
 
 // analyzeImageWithVision analyzes a single image using the vision model
-func (f *ArticleFlow) analyzeImageWithVision(ctx context.Context, img ImageRef, prompt string) (*TechInfo, error) {
+func (f *ArticleFlow) analyzeImageWithVision(ctx context.Context, articleID string, img ImageRef, prompt string) (*TechInfo, error) {
 	// For now, use the image URL directly
 	// TODO: Implement image resizing when media pipeline is available
 
@@ -259,8 +289,9 @@ func (f *ArticleFlow) analyzeImageWithVision(ctx context.Context, img ImageRef,
 	}
 
 	// Create request
+	generator, model := f.model(f.visionModel, f.config.ModelParams.VisionModel)
 	req := &interfaces.PonchoModelRequest{
-		Model: f.visionModel.Name(),
+		Model: model,
 		Messages: []*interfaces.PonchoMessage{
 			{
 				Role:    interfaces.PonchoRoleUser,
@@ -269,10 +300,11 @@ func (f *ArticleFlow) analyzeImageWithVision(ctx context.Context, img ImageRef,
 		},
 		Temperature: &f.config.ModelParams.Temperature,
 		MaxTokens:   &f.config.ModelParams.MaxTokens,
+		Metadata:    f.metadata(articleID, StepVisionAnalysis),
 	}
 
 	// Execute vision model
-	resp, err := f.visionModel.Generate(ctx, req)
+	resp, err := generator.Generate(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("vision model generation failed: %w", err)
 	}
@@ -319,7 +351,7 @@ func (f *ArticleFlow) generateCreativeDescriptions(ctx context.Context, state *A
 		ctx,
 		state.Images,
 		func(ctx context.Context, img ImageRef) (string, error) {
-			return f.generateCreativeDescription(ctx, img, creativePrompt)
+			return f.generateCreativeDescription(ctx, state.ArticleID, img, creativePrompt)
 		},
 		f.config.Concurrency.CreativeWorkers,
 	)
@@ -341,14 +373,15 @@ func (f *ArticleFlow) generateCreativeDescriptions(ctx context.Context, state *A
 }
 
 // generateCreativeDescription generates a creative description for a single image
-func (f *ArticleFlow) generateCreativeDescription(ctx context.Context, img ImageRef, basePrompt string) (string, error) {
+func (f *ArticleFlow) generateCreativeDescription(ctx context.Context, articleID string, img ImageRef, basePrompt string) (string, error) {
 	// TODO: Pass tech analysis as parameter when needed
 	// For now, use base prompt only
 	prompt := basePrompt
 
 	// Create request
+	generator, model := f.model(f.textModel, f.config.ModelParams.TextModel)
 	req := &interfaces.PonchoModelRequest{
-		Model: f.textModel.Name(),
+		Model: model,
 		Messages: []*interfaces.PonchoMessage{
 			{
 				Role:    interfaces.PonchoRoleUser,
@@ -357,10 +390,11 @@ func (f *ArticleFlow) generateCreativeDescription(ctx context.Context, img Image
 		},
 		Temperature: &f.config.ModelParams.Temperature,
 		MaxTokens:   &f.config.ModelParams.MaxTokens,
+		Metadata:    f.metadata(articleID, StepCreativeDescription),
 	}
 
 	// Execute text model
-	resp, err := f.textModel.Generate(ctx, req)
+	resp, err := generator.Generate(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("text model generation failed: %w", err)
 	}
@@ -401,8 +435,9 @@ func (f *ArticleFlow) selectWBSubject(ctx context.Context, state *ArticleFlowSta
 	prompt := f.buildSubjectSelectionPrompt(state)
 
 	// Create request
+	generator, model := f.model(f.textModel, f.config.ModelParams.TextModel)
 	req := &interfaces.PonchoModelRequest{
-		Model: f.textModel.Name(),
+		Model: model,
 		Messages: []*interfaces.PonchoMessage{
 			{
 				Role:    interfaces.PonchoRoleUser,
@@ -411,10 +446,11 @@ func (f *ArticleFlow) selectWBSubject(ctx context.Context, state *ArticleFlowSta
 		},
 		Temperature: &f.config.ModelParams.Temperature,
 		MaxTokens:   &f.config.ModelParams.MaxTokens,
+		Metadata:    f.metadata(state.ArticleID, StepSubjectSelection),
 	}
 
 	// Execute model
-	resp, err := f.textModel.Generate(ctx, req)
+	resp, err := generator.Generate(ctx, req)
 	if err != nil {
 		return fmt.Errorf("subject selection failed: %w", err)
 	}
@@ -428,6 +464,11 @@ func (f *ArticleFlow) selectWBSubject(ctx context.Context, state *ArticleFlowSta
 		return fmt.Errorf("failed to parse subject selection: %w", err)
 	}
 
+	// Models configured with logprobs report how sure they were of the ID
+	if confidence, ok := common.TextConfidence(resp.LogProbs, strconv.Itoa(selection.SubjectID)); ok {
+		state.SubjectConfidence = &confidence
+	}
+
 	// Find selected subject
 	for _, subject := range state.WBSubjects {
 		if subject.ID == selection.SubjectID {
@@ -435,6 +476,7 @@ func (f *ArticleFlow) selectWBSubject(ctx context.Context, state *ArticleFlowSta
 			f.logger.Info("Selected Wildberries subject",
 				"subject_id", selection.SubjectID,
 				"subject_name", subject.Name,
+				"confidence", state.SubjectConfidence,
 			)
 			return nil
 		}
@@ -467,6 +509,41 @@ func (f *ArticleFlow) fetchWBCharacteristics(ctx context.Context, state *Article
 	return nil
 }
 
+// finalPayloadSchema returns the JSON Schema the final Wildberries payload must
+// match: a card of the content API (cards/upload) for the selected subject with
+// at least one variant carrying its vendor code, texts and characteristics
+func finalPayloadSchema(subjectID int) map[string]interface{} {
+	characteristic := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":    map[string]interface{}{"type": "integer", "minimum": 1},
+			"value": map[string]interface{}{"type": []string{"string", "number", "array"}},
+		},
+		"required": []string{"id", "value"},
+	}
+
+	variant := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"vendorCode":      map[string]interface{}{"type": "string", "minLength": 1, "maxLength": 72},
+			"title":           map[string]interface{}{"type": "string", "minLength": 1, "maxLength": 60},
+			"description":     map[string]interface{}{"type": "string", "minLength": 1, "maxLength": 5000},
+			"brand":           map[string]interface{}{"type": "string"},
+			"characteristics": map[string]interface{}{"type": "array", "minItems": 1, "items": characteristic},
+		},
+		"required": []string{"vendorCode", "title", "description", "characteristics"},
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"subjectID": map[string]interface{}{"const": subjectID},
+			"variants":  map[string]interface{}{"type": "array", "minItems": 1, "items": variant},
+		},
+		"required": []string{"subjectID", "variants"},
+	}
+}
+
 // generateFinalWBPayload executes Prompt 4 to create final JSON
 func (f *ArticleFlow) generateFinalWBPayload(ctx context.Context, state *ArticleFlowState) error {
 	f.logger.Info("Generating final Wildberries payload")
@@ -475,8 +552,9 @@ func (f *ArticleFlow) generateFinalWBPayload(ctx context.Context, state *Article
 	prompt := f.buildFinalPayloadPrompt(state)
 
 	// Create request
+	generator, model := f.model(f.textModel, f.config.ModelParams.TextModel)
 	req := &interfaces.PonchoModelRequest{
-		Model: f.textModel.Name(),
+		Model: model,
 		Messages: []*interfaces.PonchoMessage{
 			{
 				Role:    interfaces.PonchoRoleUser,
@@ -485,21 +563,19 @@ func (f *ArticleFlow) generateFinalWBPayload(ctx context.Context, state *Article
 		},
 		Temperature: &f.config.ModelParams.Temperature,
 		MaxTokens:   &f.config.ModelParams.MaxTokens,
+		Metadata:    f.metadata(state.ArticleID, StepFinalPayload),
 	}
 
-	// Execute model
-	resp, err := f.textModel.Generate(ctx, req)
+	// Execute model, validating the payload and repairing it when needed
+	result, err := structured.Generate(ctx, generator, req, finalPayloadSchema(state.SelectedSubject.ID), nil, structured.Options{
+		JSONMode: structured.SupportsJSONMode(f.textModel),
+	})
 	if err != nil {
 		return fmt.Errorf("final payload generation failed: %w", err)
 	}
 
-	// Validate JSON
-	if !json.Valid([]byte(resp.Message.Content[0].Text)) {
-		return fmt.Errorf("generated payload is not valid JSON")
-	}
-
 	// Store final payload
-	state.SetFinalWBPayload([]byte(resp.Message.Content[0].Text))
+	state.SetFinalWBPayload([]byte(result.JSON))
 
 	f.logger.Info("Generated final Wildberries payload",
 		"size", len(state.FinalWBPayload),
@@ -508,6 +584,25 @@ func (f *ArticleFlow) generateFinalWBPayload(ctx context.Context, state *Article
 	return nil
 }
 
+// model returns what a request for model is sent to and the model name it carries:
+// the generator and the configured name when a generator is set, else the model itself
+func (f *ArticleFlow) model(model interfaces.PonchoModel, configured string) (Generator, string) {
+	if f.generator != nil && configured != "" {
+		return f.generator, configured
+	}
+	return model, model.Name()
+}
+
+// metadata tags a request of step with the flow and article, so the usage
+// ledger records the cost of every article
+func (f *ArticleFlow) metadata(articleID, step string) map[string]interface{} {
+	return map[string]interface{}{
+		usage.MetadataFlow:      FlowName,
+		usage.MetadataStep:      step,
+		usage.MetadataArticleID: articleID,
+	}
+}
+
 // Prompt building methods
 
 func (f *ArticleFlow) buildVisionAnalysisPrompt() string {
@@ -3,6 +3,7 @@ package articleflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -53,6 +54,7 @@ type ArticleFlowState struct {
 	WBParents          []wildberries.ParentCategory     `json:"wb_parents"`
 	WBSubjects         []wildberries.Subject            `json:"wb_subjects"`
 	SelectedSubject    *wildberries.Subject             `json:"selected_subject"`
+	SubjectConfidence  *float64                         `json:"subject_confidence,omitempty"` // from logprobs, when the model returns them
 	WBCharacteristics  []wildberries.SubjectCharacteristic `json:"wb_characteristics"`
 
 	// Final output
@@ -190,7 +192,7 @@ func ProcessImagesConcurrently[T any](
 	}
 
 	results := make(map[string]T)
-	errors := make(map[string]error)
+	failures := make(map[string]error)
 	var mu sync.Mutex
 
 	// Create semaphore for concurrency control
@@ -210,7 +212,7 @@ func ProcessImagesConcurrently[T any](
 			// Check context
 			if ctx.Err() != nil {
 				mu.Lock()
-				errors[imgRef.ID] = ctx.Err()
+				failures[imgRef.ID] = ctx.Err()
 				mu.Unlock()
 				return
 			}
@@ -220,7 +222,7 @@ func ProcessImagesConcurrently[T any](
 
 			mu.Lock()
 			if err != nil {
-				errors[imgRef.ID] = err
+				failures[imgRef.ID] = err
 			} else {
 				results[imgRef.ID] = result
 			}
@@ -232,13 +234,13 @@ func ProcessImagesConcurrently[T any](
 	wg.Wait()
 
 	// Return combined results
-	if len(errors) > 0 {
-		// Create combined error message
-		errMsg := fmt.Sprintf("errors processing %d images:", len(errors))
-		for imgID, err := range errors {
-			errMsg += fmt.Sprintf("\n  %s: %v", imgID, err)
+	if len(failures) > 0 {
+		// Combine the errors, keeping them inspectable with errors.Is/As
+		errs := make([]error, 0, len(failures))
+		for imgID, err := range failures {
+			errs = append(errs, fmt.Errorf("  %s: %w", imgID, err))
 		}
-		return results, fmt.Errorf(errMsg)
+		return results, fmt.Errorf("errors processing %d images:\n%w", len(failures), errors.Join(errs...))
 	}
 
 	return results, nil
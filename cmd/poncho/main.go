@@ -0,0 +1,229 @@
+package main
+
+// poncho is the command line entry point of the PonchoFramework service.
+// The "serve" command loads the framework configuration, registers the built-in
+// model factories, starts the framework and exposes it over HTTP via the server
+// package on :8080 - the address the nginx and prometheus configs point to.
+// The configuration is reloaded without a restart on SIGHUP and, unless
+// -watch-config=false, whenever the configuration file changes.
+// API keys and rate limits from the "security" section are enforced by a
+// security.Guard; changing them requires a restart.
+//
+// Usage:
+//   poncho serve [-config config.yaml] [-addr :8080] [-log-level info] [-log-format text] [-watch-config]
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core"
+	"github.com/ilkoid/PonchoAiFramework/core/config"
+	"github.com/ilkoid/PonchoAiFramework/factories/models"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/security"
+	"github.com/ilkoid/PonchoAiFramework/server"
+)
+
+// ServeOptions holds the flags of the serve command
+type ServeOptions struct {
+	ConfigPath      string
+	Addr            string
+	LogLevel        string
+	LogFormat       string
+	ShutdownTimeout time.Duration
+	WatchConfig     bool
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	switch command {
+	case "serve":
+		if err := runServe(os.Args[2:]); err != nil {
+			log.Fatalf("serve failed: %v", err)
+		}
+	case "help", "-h", "--help":
+		printUsage()
+	default:
+		fmt.Printf("Unknown command: %s\n\n", command)
+		printUsage()
+		os.Exit(2)
+	}
+}
+
+// parseServeFlags parses the flags of the serve command
+func parseServeFlags(args []string) (*ServeOptions, error) {
+	opts := &ServeOptions{}
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.StringVar(&opts.ConfigPath, "config", envOrDefault("PONCHO_CONFIG", "config.yaml"), "Path to framework configuration file")
+	fs.StringVar(&opts.Addr, "addr", envOrDefault("PONCHO_ADDR", server.DefaultAddr), "HTTP listen address")
+	fs.StringVar(&opts.LogLevel, "log-level", envOrDefault("LOG_LEVEL", "info"), "Log level (debug, info, warn, error)")
+	fs.StringVar(&opts.LogFormat, "log-format", "text", "Log format (text, json)")
+	fs.DurationVar(&opts.ShutdownTimeout, "shutdown-timeout", 30*time.Second, "Graceful shutdown timeout")
+	fs.BoolVar(&opts.WatchConfig, "watch-config", true, "Reload configuration when the config file changes")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
+
+// runServe starts the framework and serves it over HTTP until SIGINT/SIGTERM
+func runServe(args []string) error {
+	opts, err := parseServeFlags(args)
+	if err != nil {
+		return err
+	}
+
+	logger := core.CreateLoggerFromConfig(&interfaces.LoggingConfig{
+		Level:  opts.LogLevel,
+		Format: opts.LogFormat,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	framework, err := newFramework(opts.ConfigPath, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := framework.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start framework: %w", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+		defer cancel()
+		if err := framework.Stop(stopCtx); err != nil {
+			logger.Error("Failed to stop framework", "error", err)
+		}
+	}()
+
+	if opts.WatchConfig {
+		if err := framework.WatchConfig(ctx); err != nil {
+			return fmt.Errorf("failed to watch configuration: %w", err)
+		}
+	}
+	go reloadOnSignal(ctx, framework, opts.ShutdownTimeout, logger)
+
+	serverConfig := server.DefaultConfig()
+	serverConfig.Addr = opts.Addr
+	serverConfig.ShutdownTimeout = opts.ShutdownTimeout
+
+	securityConfig, err := framework.GetSecurityConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load security configuration: %w", err)
+	}
+
+	var served interfaces.PonchoFramework = framework
+	var guard *security.Guard
+	if securityConfig != nil {
+		if guard, err = security.NewGuard(framework, securityConfig, logger); err != nil {
+			return fmt.Errorf("failed to create security guard: %w", err)
+		}
+		served = guard
+	}
+
+	srv := server.NewServer(served, serverConfig, logger)
+	if guard != nil {
+		srv.Exporter().Register(guard)
+	}
+
+	logger.Info("PonchoFramework service starting", "addr", opts.Addr, "config", opts.ConfigPath)
+	return srv.ListenAndServe(ctx)
+}
+
+// reloadOnSignal reloads the framework configuration on every SIGHUP until ctx is done
+func reloadOnSignal(ctx context.Context, framework *core.PonchoFrameworkImpl, timeout time.Duration, logger interfaces.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Info("SIGHUP received, reloading configuration")
+			reloadCtx, cancel := context.WithTimeout(ctx, timeout)
+			if err := framework.ReloadConfig(reloadCtx); err != nil {
+				logger.Error("Failed to reload configuration", "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// newFramework creates a framework configured from the given file with the
+// built-in model and embedding model factories registered in its service locator
+func newFramework(configPath string, logger interfaces.Logger) (*core.PonchoFrameworkImpl, error) {
+	framework := core.NewPonchoFramework(nil, logger)
+	framework.SetConfigManager(config.NewConfigManager(config.ConfigOptions{
+		FilePaths: []string{configPath},
+		Logger:    logger,
+	}))
+
+	// The core never imports concrete factories, so the binary wires them in.
+	// Initialize is idempotent, Start will reuse the initialized locator.
+	locator := framework.GetServiceLocator()
+	if err := locator.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize service locator: %w", err)
+	}
+
+	factoryManager := models.NewModelFactoryManager(logger)
+	for _, provider := range factoryManager.GetSupportedProviders() {
+		factory, err := factoryManager.GetFactory(provider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get model factory %s: %w", provider, err)
+		}
+		if err := locator.RegisterModelFactory(provider, factory); err != nil {
+			return nil, err
+		}
+	}
+	registrar, ok := locator.(core.EmbeddingFactoryRegistrar)
+	if !ok {
+		return nil, fmt.Errorf("service locator does not support embedding models")
+	}
+	for _, provider := range factoryManager.GetSupportedEmbeddingProviders() {
+		factory, err := factoryManager.GetEmbeddingFactory(provider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get embedding model factory %s: %w", provider, err)
+		}
+		if err := registrar.RegisterEmbeddingModelFactory(provider, factory); err != nil {
+			return nil, err
+		}
+	}
+
+	return framework, nil
+}
+
+// envOrDefault returns the environment variable value or the fallback
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func printUsage() {
+	fmt.Printf("PonchoFramework service\n\n")
+	fmt.Printf("Usage: poncho <command> [flags]\n\n")
+	fmt.Printf("Commands:\n")
+	fmt.Printf("  serve    Start the HTTP API server\n")
+	fmt.Printf("  help     Show this help\n\n")
+	fmt.Printf("Examples:\n")
+	fmt.Printf("  poncho serve\n")
+	fmt.Printf("  poncho serve -config config.yaml -addr :8080\n")
+}
@@ -0,0 +1,366 @@
+package core
+
+// Tool-calling agent loop for the PonchoFramework
+//
+// RunWithTools (or Agent.Run) sends a model request with registry tools attached,
+// executes the tool calls the model returns through ExecuteTool and sends the
+// results back as tool messages, each with a tool_result part answering its
+// call ID. The loop ends when the model answers without tool calls, or with
+// ErrAgentMaxIterations / ErrAgentMaxCost when a limit is reached before that.
+//
+// Every model call goes through Generate or GenerateStreaming, so middleware,
+// budgets and the usage ledger apply to each iteration. Tool failures are not
+// fatal: the error is sent to the model as a tool result flagged IsError, so it
+// can recover.
+//
+// Usage Example:
+//
+//	result, err := framework.RunWithTools(ctx, &interfaces.PonchoModelRequest{
+//	    Model:    "deepseek-chat",
+//	    Messages: messages,
+//	}, core.AgentOptions{Tools: []string{"article_importer"}, MaxCost: 0.05})
+//	answer := result.Response.Message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// DefaultAgentMaxIterations bounds the model calls of a run when AgentOptions.MaxIterations is zero
+const DefaultAgentMaxIterations = 10
+
+// metadataRoutedModel names the model that served a routed response (see models/router)
+const metadataRoutedModel = "routed_model"
+
+var (
+	// ErrAgentMaxIterations is returned when the model still calls tools after the last allowed iteration
+	ErrAgentMaxIterations = errors.New("agent reached the maximum number of iterations")
+
+	// ErrAgentMaxCost is returned when the estimated cost reaches the limit while the model still calls tools
+	ErrAgentMaxCost = errors.New("agent reached the maximum cost")
+)
+
+// AgentOptions configures a tool-calling run
+type AgentOptions struct {
+	// Tools lists the registry names of the tools offered to the model; empty offers all
+	Tools []string
+
+	// MaxIterations limits the number of model calls (default DefaultAgentMaxIterations)
+	MaxIterations int
+
+	// MaxCost limits the estimated cost in USD of all model calls; zero means no limit.
+	// Models without pricing in the tokenizer are counted as free.
+	MaxCost float64
+
+	// Stream makes every model call through GenerateStreaming
+	Stream bool
+
+	// OnChunk receives every stream chunk when Stream is set
+	OnChunk interfaces.PonchoStreamCallback
+}
+
+// AgentToolCall records one executed tool call
+type AgentToolCall struct {
+	Iteration int                    `json:"iteration"`
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Args      map[string]interface{} `json:"args"`
+	Result    interface{}            `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Duration  time.Duration          `json:"duration"`
+}
+
+// AgentResult represents the outcome of a tool-calling run. On ErrAgentMaxIterations
+// and ErrAgentMaxCost it holds everything up to the point the run stopped.
+type AgentResult struct {
+	Response   *interfaces.PonchoModelResponse `json:"response"`   // last model response
+	Messages   []*interfaces.PonchoMessage     `json:"messages"`   // conversation including tool calls and results
+	ToolCalls  []*AgentToolCall                `json:"tool_calls"` // executed tool calls, in order
+	Iterations int                             `json:"iterations"` // model calls made
+	Usage      *interfaces.PonchoUsage         `json:"usage"`      // summed over all model calls
+	Cost       float64                         `json:"cost"`       // estimated, USD
+}
+
+// Agent runs the tool-calling loop against a framework
+type Agent struct {
+	framework interfaces.PonchoFramework
+	options   AgentOptions
+	tokenizer *common.Tokenizer
+	logger    interfaces.Logger
+}
+
+// NewAgent creates an agent using the models and tools of framework
+func NewAgent(framework interfaces.PonchoFramework, options AgentOptions, logger interfaces.Logger) *Agent {
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	if options.MaxIterations <= 0 {
+		options.MaxIterations = DefaultAgentMaxIterations
+	}
+
+	return &Agent{
+		framework: framework,
+		options:   options,
+		tokenizer: common.NewTokenizer(logger),
+		logger:    logger,
+	}
+}
+
+// RunWithTools runs req through the tool-calling loop, see Agent.Run
+func (pf *PonchoFrameworkImpl) RunWithTools(ctx context.Context, req *interfaces.PonchoModelRequest, options AgentOptions) (*AgentResult, error) {
+	return NewAgent(pf, options, pf.logger).Run(ctx, req)
+}
+
+// Run sends req with the agent's tools and executes tool calls until the model
+// answers without them. req itself is not modified.
+func (a *Agent) Run(ctx context.Context, req *interfaces.PonchoModelRequest) (*AgentResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	tools, schemas, err := a.toolDefinitions()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := append([]*interfaces.PonchoMessage(nil), req.Messages...)
+	result := &AgentResult{Usage: &interfaces.PonchoUsage{}}
+
+	for {
+		call := *req
+		call.Messages = messages
+		call.Tools = append(append([]*interfaces.PonchoToolDef(nil), req.Tools...), tools...)
+		call.Stream = a.options.Stream
+
+		resp, err := a.generate(ctx, &call)
+		if err != nil {
+			return result, fmt.Errorf("agent iteration %d failed: %w", result.Iterations+1, err)
+		}
+
+		result.Iterations++
+		result.Response = resp
+		a.addUsage(result, &call, resp)
+
+		if resp.Message != nil && len(resp.Message.Content) > 0 {
+			messages = append(messages, resp.Message)
+		}
+		result.Messages = messages
+
+		calls := toolCallParts(resp.Message)
+		if len(calls) == 0 {
+			a.logger.Debug("Agent run completed",
+				"model", req.Model,
+				"iterations", result.Iterations,
+				"tool_calls", len(result.ToolCalls))
+			return result, nil
+		}
+
+		if result.Iterations >= a.options.MaxIterations {
+			return result, ErrAgentMaxIterations
+		}
+		if a.options.MaxCost > 0 && result.Cost >= a.options.MaxCost {
+			return result, ErrAgentMaxCost
+		}
+
+		for i, part := range calls {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+
+			record, message := a.executeTool(ctx, part, schemas, result.Iterations, i)
+			result.ToolCalls = append(result.ToolCalls, record)
+			messages = append(messages, message)
+		}
+		result.Messages = messages
+	}
+}
+
+// toolDefinitions converts the selected registry tools to tool definitions.
+// The returned map holds the input schema of every offered tool.
+func (a *Agent) toolDefinitions() ([]*interfaces.PonchoToolDef, map[string]map[string]interface{}, error) {
+	registry := a.framework.GetToolRegistry()
+
+	names := a.options.Tools
+	if len(names) == 0 {
+		names = registry.List()
+		sort.Strings(names)
+	}
+
+	definitions := make([]*interfaces.PonchoToolDef, 0, len(names))
+	schemas := make(map[string]map[string]interface{}, len(names))
+	for _, name := range names {
+		tool, err := registry.Get(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tool '%s' not found: %w", name, err)
+		}
+
+		schema := tool.InputSchema()
+		if len(schema) == 0 {
+			schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+
+		definitions = append(definitions, &interfaces.PonchoToolDef{
+			Name:        name,
+			Description: tool.Description(),
+			Parameters:  schema,
+		})
+		schemas[name] = schema
+	}
+
+	return definitions, schemas, nil
+}
+
+// generate makes one model call and returns the complete response
+func (a *Agent) generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	if !a.options.Stream {
+		return a.framework.Generate(ctx, req)
+	}
+
+	stream := common.NewStreamAccumulator()
+	if err := a.framework.GenerateStreaming(ctx, req, stream.Callback(a.options.OnChunk)); err != nil {
+		return nil, err
+	}
+
+	return stream.Response(), nil
+}
+
+// executeTool validates and executes one tool call and returns its record and
+// the tool message answering it. Failures become error results for the model.
+func (a *Agent) executeTool(ctx context.Context, part *interfaces.PonchoToolPart, schemas map[string]map[string]interface{}, iteration, index int) (*AgentToolCall, *interfaces.PonchoMessage) {
+	record := &AgentToolCall{
+		Iteration: iteration,
+		ID:        part.ID,
+		Name:      part.Name,
+		Args:      part.Args,
+	}
+	if record.ID == "" {
+		// The assistant message must carry the ID its tool result answers
+		record.ID = fmt.Sprintf("call_%d_%d", iteration, index+1)
+		part.ID = record.ID
+	}
+	if record.Args == nil {
+		record.Args = make(map[string]interface{})
+	}
+
+	startTime := time.Now()
+	var err error
+	if schema, offered := schemas[part.Name]; !offered {
+		err = fmt.Errorf("tool '%s' is not available", part.Name)
+	} else if err = validateToolArgs(schema, record.Args); err == nil {
+		record.Result, err = a.framework.ExecuteTool(ctx, part.Name, record.Args)
+	}
+	record.Duration = time.Since(startTime)
+
+	result := &interfaces.PonchoToolResultPart{
+		ToolCallID: record.ID,
+		Name:       part.Name,
+		Content:    record.Result,
+	}
+	if err != nil {
+		record.Error = err.Error()
+		result.Content = record.Error
+		result.IsError = true
+		a.logger.Warn("Agent tool call failed", "tool", part.Name, "id", record.ID, "error", err)
+	} else {
+		a.logger.Debug("Agent tool call completed", "tool", part.Name, "id", record.ID, "duration_ms", record.Duration.Milliseconds())
+	}
+
+	name := part.Name
+	return record, &interfaces.PonchoMessage{
+		Role:       interfaces.PonchoRoleTool,
+		Name:       &name,
+		ToolCallID: record.ID,
+		Content:    []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeToolResult, ToolResult: result}},
+	}
+}
+
+// addUsage adds the usage and estimated cost of one model call to result. Routed
+// calls are costed at the prices of the model that served them.
+func (a *Agent) addUsage(result *AgentResult, req *interfaces.PonchoModelRequest, resp *interfaces.PonchoModelResponse) {
+	name := req.Model
+	if routed, ok := resp.Metadata[metadataRoutedModel].(string); ok && routed != "" {
+		name = routed
+	}
+	provider, modelName := a.resolveModel(name)
+
+	callUsage := resp.Usage
+	if callUsage == nil || callUsage.TotalTokens == 0 {
+		estimated, err := a.tokenizer.CountRequestTokens(req, common.Provider(provider), modelName)
+		if err != nil {
+			return
+		}
+		callUsage = estimated
+	}
+
+	result.Usage.PromptTokens += callUsage.PromptTokens
+	result.Usage.CompletionTokens += callUsage.CompletionTokens
+	result.Usage.TotalTokens += callUsage.TotalTokens
+
+	if cost, err := a.tokenizer.EstimateCost(callUsage, common.Provider(provider), modelName); err == nil {
+		result.Cost += cost
+	}
+}
+
+// resolveModel returns the provider and provider model name of a registered model
+func (a *Agent) resolveModel(name string) (provider, modelName string) {
+	if pf, ok := a.framework.(*PonchoFrameworkImpl); ok {
+		model, _ := pf.modelRegistry.Get(name)
+		return pf.resolveModel(name, model)
+	}
+
+	model, err := a.framework.GetModelRegistry().Get(name)
+	if err != nil {
+		return "", name
+	}
+	return model.Provider(), model.Name()
+}
+
+// toolCallParts returns the tool calls of a model message
+func toolCallParts(message *interfaces.PonchoMessage) []*interfaces.PonchoToolPart {
+	if message == nil {
+		return nil
+	}
+
+	var calls []*interfaces.PonchoToolPart
+	for _, part := range message.Content {
+		if part != nil && part.Type == interfaces.PonchoContentTypeTool && part.Tool != nil {
+			calls = append(calls, part.Tool)
+		}
+	}
+	return calls
+}
+
+// validateToolArgs checks tool call arguments against the required properties of schema
+func validateToolArgs(schema map[string]interface{}, args map[string]interface{}) error {
+	var required []string
+	switch fields := schema["required"].(type) {
+	case []string:
+		required = fields
+	case []interface{}:
+		for _, field := range fields {
+			if name, ok := field.(string); ok {
+				required = append(required, name)
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range required {
+		if _, ok := args[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required arguments: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
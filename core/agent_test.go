@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+)
+
+// newAgentFramework starts a framework with a scripted model and an article tool
+func newAgentFramework(t *testing.T, steps ...fake.Step) (*PonchoFrameworkImpl, *fake.FakeModel, *[]interface{}) {
+	t.Helper()
+
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{}, interfaces.NewNoOpLogger())
+	if err := framework.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { framework.Stop(context.Background()) })
+
+	model, err := fake.NewScriptedModel(steps...)
+	if err != nil {
+		t.Fatalf("NewScriptedModel() error = %v", err)
+	}
+	if err := framework.RegisterModel("fake-model", model); err != nil {
+		t.Fatalf("RegisterModel() error = %v", err)
+	}
+
+	var inputs []interface{}
+	tool := NewMockTool("get_article", "Returns an article by ID", "1.0.0", "test")
+	tool.SetInputSchema(map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}},
+		"required":   []interface{}{"id"},
+	})
+	tool.executeFunc = func(ctx context.Context, input interface{}) (interface{}, error) {
+		inputs = append(inputs, input)
+		return map[string]interface{}{"title": "Red dress"}, nil
+	}
+	if err := framework.RegisterTool("get_article", tool); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	return framework, model, &inputs
+}
+
+func agentRequest(text string) *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Model: "fake-model",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: text}},
+		}},
+	}
+}
+
+func articleCall(id string) fake.ToolCall {
+	return fake.ToolCall{ID: id, Name: "get_article", Args: map[string]interface{}{"id": 42}}
+}
+
+func TestAgent_RunWithTools(t *testing.T) {
+	framework, model, inputs := newAgentFramework(t,
+		fake.Step{ToolCalls: []fake.ToolCall{articleCall("call_a"), articleCall("call_b")}},
+		fake.Step{Text: "The article is about a red dress."},
+	)
+
+	req := agentRequest("What is article 42 about?")
+	result, err := framework.RunWithTools(context.Background(), req, AgentOptions{})
+	if err != nil {
+		t.Fatalf("RunWithTools() error = %v", err)
+	}
+
+	if result.Iterations != 2 {
+		t.Errorf("Iterations = %d, want 2", result.Iterations)
+	}
+	if got := result.Response.Message.Content[0].Text; got != "The article is about a red dress." {
+		t.Errorf("final text = %q", got)
+	}
+	if len(*inputs) != 2 || len(result.ToolCalls) != 2 {
+		t.Fatalf("executed %d tools, recorded %d, want 2", len(*inputs), len(result.ToolCalls))
+	}
+	if len(req.Messages) != 1 {
+		t.Errorf("request messages were modified: %d", len(req.Messages))
+	}
+
+	// The tool definition is built from the tool's input schema
+	first := model.Requests()[0]
+	if len(first.Tools) != 1 || first.Tools[0].Name != "get_article" || first.Tools[0].Parameters["required"] == nil {
+		t.Errorf("unexpected tool definitions: %+v", first.Tools)
+	}
+
+	// The second call carries the assistant tool calls and one answer per call ID
+	second := model.LastRequest()
+	if len(second.Messages) != 4 {
+		t.Fatalf("second call has %d messages, want 4", len(second.Messages))
+	}
+	for i, id := range []string{"call_a", "call_b"} {
+		msg := second.Messages[2+i]
+		if msg.Role != interfaces.PonchoRoleTool || msg.ToolCallID != id {
+			t.Errorf("message %d: role = %s, tool_call_id = %q, want tool/%s", 2+i, msg.Role, msg.ToolCallID, id)
+		}
+		toolResult := msg.Content[0].ToolResult
+		if msg.Content[0].Type != interfaces.PonchoContentTypeToolResult || toolResult.ToolCallID != id || toolResult.IsError {
+			t.Errorf("message %d: unexpected tool result %+v", 2+i, msg.Content[0])
+		}
+	}
+	if len(result.Messages) != 5 {
+		t.Errorf("result has %d messages, want 5", len(result.Messages))
+	}
+	if result.Usage.TotalTokens == 0 {
+		t.Error("expected usage to be summed")
+	}
+}
+
+func TestAgent_Streaming(t *testing.T) {
+	framework, model, inputs := newAgentFramework(t,
+		fake.Step{Chunks: []string{"Looking it up. "}, ToolCalls: []fake.ToolCall{articleCall("call_a")}},
+		fake.Step{Chunks: []string{"Red ", "dress"}},
+	)
+
+	var deltas []string
+	result, err := framework.RunWithTools(context.Background(), agentRequest("Article 42?"), AgentOptions{
+		Stream: true,
+		OnChunk: func(chunk *interfaces.PonchoStreamChunk) error {
+			if chunk.Delta != nil {
+				for _, part := range chunk.Delta.Content {
+					deltas = append(deltas, part.Text)
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunWithTools() error = %v", err)
+	}
+
+	if len(*inputs) != 1 {
+		t.Errorf("executed %d tools, want 1", len(*inputs))
+	}
+	if got := result.Response.Message.Content[0].Text; got != "Red dress" {
+		t.Errorf("final text = %q, want %q", got, "Red dress")
+	}
+	if !strings.Contains(strings.Join(deltas, ""), "Looking it up. Red dress") {
+		t.Errorf("chunks were not forwarded: %q", deltas)
+	}
+
+	// The streamed assistant turn keeps its text and tool call
+	assistant := model.LastRequest().Messages[1]
+	if len(assistant.Content) != 2 || assistant.Content[1].Tool.ID != "call_a" {
+		t.Errorf("unexpected assistant message: %+v", assistant.Content)
+	}
+	if !model.LastRequest().Stream {
+		t.Error("expected streaming requests")
+	}
+}
+
+func TestAgent_GeneratedToolCallIDs(t *testing.T) {
+	framework, _, _ := newAgentFramework(t)
+	agent := NewAgent(framework, AgentOptions{}, interfaces.NewNoOpLogger())
+	_, schemas, err := agent.toolDefinitions()
+	if err != nil {
+		t.Fatalf("toolDefinitions() error = %v", err)
+	}
+
+	// A call without an ID gets one, and the assistant turn carries it too
+	part := &interfaces.PonchoToolPart{Name: "get_article", Args: map[string]interface{}{"id": 42}}
+	record, message := agent.executeTool(context.Background(), part, schemas, 2, 0)
+	if record.ID != "call_2_1" || part.ID != record.ID {
+		t.Errorf("record ID = %q, tool part ID = %q, want call_2_1", record.ID, part.ID)
+	}
+	if message.ToolCallID != part.ID || message.Content[0].ToolResult.ToolCallID != part.ID {
+		t.Errorf("tool message answers %q, want %q", message.ToolCallID, part.ID)
+	}
+}
+
+func TestAgent_MaxIterations(t *testing.T) {
+	loop := fake.Step{Match: ".", ToolCalls: []fake.ToolCall{articleCall("")}}
+	framework, _, inputs := newAgentFramework(t, loop)
+
+	result, err := framework.RunWithTools(context.Background(), agentRequest("Article 42?"), AgentOptions{MaxIterations: 3})
+	if !errors.Is(err, ErrAgentMaxIterations) {
+		t.Fatalf("RunWithTools() error = %v, want ErrAgentMaxIterations", err)
+	}
+	if result.Iterations != 3 || len(*inputs) != 2 {
+		t.Errorf("iterations = %d, tool executions = %d, want 3 and 2", result.Iterations, len(*inputs))
+	}
+}
+
+func TestAgent_ToolErrorsAreReturnedToModel(t *testing.T) {
+	framework, model, inputs := newAgentFramework(t,
+		fake.Step{ToolCalls: []fake.ToolCall{
+			{ID: "call_a", Name: "delete_article", Args: map[string]interface{}{"id": 42}},
+			{ID: "call_b", Name: "get_article"},
+		}},
+		fake.Step{Text: "Sorry."},
+	)
+
+	result, err := framework.RunWithTools(context.Background(), agentRequest("Delete article 42"), AgentOptions{})
+	if err != nil {
+		t.Fatalf("RunWithTools() error = %v", err)
+	}
+
+	if len(*inputs) != 0 {
+		t.Errorf("invalid calls must not be executed, got %d executions", len(*inputs))
+	}
+	for i, want := range []string{"not available", "missing required arguments: id"} {
+		if !strings.Contains(result.ToolCalls[i].Error, want) {
+			t.Errorf("tool call %d error = %q, want %q", i, result.ToolCalls[i].Error, want)
+		}
+		if result := model.LastRequest().Messages[2+i].Content[0].ToolResult; result == nil || !result.IsError {
+			t.Errorf("tool message %d = %+v, want an error result", i, result)
+		}
+	}
+}
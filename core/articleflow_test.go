@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/cli/articleflow"
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+	"github.com/ilkoid/PonchoAiFramework/tools/wildberries"
+)
+
+// articleWBCache serves a single Wildberries subject and its characteristics
+type articleWBCache struct{}
+
+func (c *articleWBCache) GetParents(ctx context.Context) ([]wildberries.ParentCategory, error) {
+	return []wildberries.ParentCategory{{ID: 1, Name: "Одежда"}}, nil
+}
+
+func (c *articleWBCache) GetSubjects(ctx context.Context) ([]wildberries.Subject, error) {
+	return []wildberries.Subject{{ID: 105, Name: "Платья", ParentID: 1}}, nil
+}
+
+func (c *articleWBCache) GetCharacteristics(ctx context.Context, subjectID int) ([]wildberries.SubjectCharacteristic, error) {
+	return []wildberries.SubjectCharacteristic{{CharcID: 14177449, SubjectID: subjectID, Name: "Цвет"}}, nil
+}
+
+func (c *articleWBCache) Invalidate(ctx context.Context) error                      { return nil }
+func (c *articleWBCache) InvalidateSubject(ctx context.Context, subjectID int) error { return nil }
+
+// newArticleFlow creates an article flow whose model calls go through framework,
+// with the vision and text models registered under the default configuration names
+func newArticleFlow(t *testing.T, framework *PonchoFrameworkImpl, vision, text *fake.FakeModel) *articleflow.ArticleFlow {
+	t.Helper()
+
+	config := articleflow.DefaultFlowConfig()
+	if err := framework.RegisterModel(config.ModelParams.VisionModel, vision); err != nil {
+		t.Fatalf("RegisterModel() error = %v", err)
+	}
+	if err := framework.RegisterModel(config.ModelParams.TextModel, text); err != nil {
+		t.Fatalf("RegisterModel() error = %v", err)
+	}
+
+	s3Tool := NewMockTool("article_importer", "Loads articles from S3", "1.0.0", "s3")
+	s3Tool.executeFunc = func(ctx context.Context, input interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"article": map[string]interface{}{
+				"json_data": `{"name": "Платье миди"}`,
+				"images": []interface{}{
+					map[string]interface{}{"filename": "front.jpg", "url": "https://s3.local/12345/front.jpg", "content_type": "image/jpeg"},
+				},
+			},
+		}, nil
+	}
+
+	return articleflow.NewArticleFlow(s3Tool, vision, text, nil, framework, interfaces.NewNoOpLogger(), config, &articleWBCache{})
+}
+
+func TestArticleFlow_FlowBudget(t *testing.T) {
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Budgets: &interfaces.BudgetConfig{Flows: map[string]*interfaces.BudgetLimit{articleflow.FlowName: {MaxTokens: 100}}},
+	}, interfaces.NewNoOpLogger())
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	vision, err := fake.NewScriptedModel(fake.Step{
+		Text:  `{"garment_type": "dress"}`,
+		Usage: &interfaces.PonchoUsage{PromptTokens: 400, CompletionTokens: 100},
+	})
+	if err != nil {
+		t.Fatalf("NewScriptedModel() error = %v", err)
+	}
+	text, err := fake.NewScriptedModel(fake.Step{Match: ".", Text: "Лёгкое платье миди"})
+	if err != nil {
+		t.Fatalf("NewScriptedModel() error = %v", err)
+	}
+
+	// The vision call spends 500 tokens, so the article's next call is over budget
+	flow := newArticleFlow(t, framework, vision, text)
+	_, err = flow.Run(ctx, "12345")
+
+	var modelErr *common.ModelError
+	if !errors.As(err, &modelErr) || modelErr.Code != common.ErrorCodeBudgetExceeded {
+		t.Fatalf("Expected a budget error, got %v", err)
+	}
+	if vision.CallCount() != 1 || text.CallCount() != 0 {
+		t.Errorf("Expected only the vision call to reach a model, got %d vision and %d text calls", vision.CallCount(), text.CallCount())
+	}
+}
+
+func TestArticleFlow_RecordsUsagePerArticle(t *testing.T) {
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Usage: &interfaces.UsageConfig{Path: filepath.Join(t.TempDir(), "usage.jsonl")},
+	}, interfaces.NewNoOpLogger())
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	vision, err := fake.NewScriptedModel(fake.Step{Text: `{"garment_type": "dress"}`})
+	if err != nil {
+		t.Fatalf("NewScriptedModel() error = %v", err)
+	}
+	text, err := fake.NewScriptedModel(
+		fake.Step{Call: 1, Text: "Лёгкое платье миди"},
+		fake.Step{Call: 2, Text: `{"subject_id": 105, "reason": "платье"}`},
+		fake.Step{Call: 3, Text: `{"subjectID": 105, "variants": [{"vendorCode": "12345", "title": "Платье миди", "description": "Лёгкое платье миди", "characteristics": [{"id": 14177449, "value": "красный"}]}]}`},
+	)
+	if err != nil {
+		t.Fatalf("NewScriptedModel() error = %v", err)
+	}
+
+	flow := newArticleFlow(t, framework, vision, text)
+	if _, err := flow.Run(ctx, "12345"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	records, err := framework.UsageLedger().Query(usage.Filter{Flow: articleflow.FlowName, ArticleID: "12345"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	steps := []string{
+		articleflow.StepVisionAnalysis,
+		articleflow.StepCreativeDescription,
+		articleflow.StepSubjectSelection,
+		articleflow.StepFinalPayload,
+	}
+	if len(records) != len(steps) {
+		t.Fatalf("Expected %d records for the article, got %d", len(steps), len(records))
+	}
+	for i, record := range records {
+		if record.Step != steps[i] || record.Model == "" {
+			t.Errorf("record %d: step = %q, model = %q, want step %q", i, record.Step, record.Model, steps[i])
+		}
+	}
+}
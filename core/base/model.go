@@ -352,6 +352,10 @@ func (m *PonchoBaseModel) ValidateRequest(req *interfaces.PonchoModelRequest) er
 				if !m.SupportsTools() {
 					return fmt.Errorf("model '%s' does not support tool calling", m.name)
 				}
+			case interfaces.PonchoContentTypeToolResult:
+				if part.ToolResult == nil {
+					return fmt.Errorf("tool result content part %d in message %d must have a tool result", j, i)
+				}
 			}
 		}
 	}
@@ -0,0 +1,428 @@
+// Package budget enforces model spend budgets
+//
+// Budgets cap tokens and estimated cost (see interfaces.BudgetConfig):
+//   - per flow execution, for all flows or by flow name
+//   - per tenant per UTC day, for all tenants or by tenant (Metadata["tenant"])
+//   - per model per UTC day
+//
+// Before a request is sent to the provider, its prompt and completion tokens are
+// estimated with common.Tokenizer.CountRequestTokens and its cost with EstimateCost.
+// A request whose estimate would take any budget past its limit is rejected with a
+// common.ModelError of code BUDGET_EXCEEDED. The estimate is reserved while the call
+// runs, so concurrent calls cannot overrun a budget together; once the call finishes
+// the reservation is replaced by the usage the provider reported, or returned when a
+// failed call reported none.
+//
+// Enforcement is a model middleware (see Middleware), so it applies to calls made
+// through PonchoFramework.Generate, GenerateStreaming and Embed. Flow executions are
+// scoped with WithFlowExecution, which the framework does in ExecuteFlow and
+// cli/articleflow does for each article it processes.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// Budget scopes reported in BUDGET_EXCEEDED error details
+const (
+	ScopeFlow   = "flow"
+	ScopeTenant = "tenant"
+	ScopeModel  = "model"
+)
+
+// dateLayout is the day format of daily budgets
+const dateLayout = "2006-01-02"
+
+// metadataCacheHit marks responses served from the response cache (see core/cache)
+const metadataCacheHit = "cache_hit"
+
+// ModelResolver returns the provider and provider model name of a registered model
+type ModelResolver func(model string) (provider, modelName string)
+
+// Spend is the tokens and estimated cost charged to a budget
+type Spend struct {
+	Tokens int     `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+func (s *Spend) add(other Spend) {
+	s.Tokens += other.Tokens
+	s.Cost += other.Cost
+}
+
+func (s *Spend) sub(other Spend) {
+	s.Tokens -= other.Tokens
+	s.Cost -= other.Cost
+}
+
+// exceeds reports whether adding estimate would take the spend past limit
+func (s Spend) exceeds(estimate Spend, limit *interfaces.BudgetLimit) bool {
+	if limit == nil {
+		return false
+	}
+	if limit.MaxTokens > 0 && s.Tokens+estimate.Tokens > limit.MaxTokens {
+		return true
+	}
+	return limit.MaxCost > 0 && s.Cost+estimate.Cost > limit.MaxCost
+}
+
+// Details describes a budget rejection; it is set as ModelError.Details
+type Details struct {
+	Scope    string                  `json:"scope"`
+	Name     string                  `json:"name"`
+	Limit    *interfaces.BudgetLimit `json:"limit"`
+	Spent    Spend                   `json:"spent"`
+	Estimate Spend                   `json:"estimate"`
+}
+
+// Enforcer tracks spend and rejects requests that would exceed a budget
+type Enforcer struct {
+	config    *interfaces.BudgetConfig
+	resolve   ModelResolver
+	tokenizer *common.Tokenizer
+	logger    interfaces.Logger
+	now       func() time.Time
+
+	date  string
+	daily map[string]*Spend // "tenant:<name>" and "model:<name>" for the current date
+	mutex sync.Mutex
+}
+
+// NewEnforcer creates a budget enforcer
+func NewEnforcer(config *interfaces.BudgetConfig, resolve ModelResolver, logger interfaces.Logger) (*Enforcer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("budget config cannot be nil")
+	}
+	if resolve == nil {
+		resolve = func(model string) (string, string) { return "", model }
+	}
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	return &Enforcer{
+		config:    config,
+		resolve:   resolve,
+		tokenizer: common.NewTokenizer(interfaces.NewNoOpLogger()),
+		logger:    logger,
+		now:       time.Now,
+		daily:     make(map[string]*Spend),
+	}, nil
+}
+
+// flowExecution is the spend of a single flow execution
+type flowExecution struct {
+	name  string
+	spent Spend
+	mutex sync.Mutex
+}
+
+type flowExecutionKey struct{}
+
+// WithFlowExecution starts a flow execution budget scope for flow.
+// An enclosing scope (a flow started from a flow) is kept.
+func WithFlowExecution(ctx context.Context, flow string) context.Context {
+	if _, ok := ctx.Value(flowExecutionKey{}).(*flowExecution); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, flowExecutionKey{}, &flowExecution{name: flow})
+}
+
+// FlowSpend returns the spend of the flow execution in ctx
+func FlowSpend(ctx context.Context) (Spend, bool) {
+	execution, ok := ctx.Value(flowExecutionKey{}).(*flowExecution)
+	if !ok {
+		return Spend{}, false
+	}
+
+	execution.mutex.Lock()
+	defer execution.mutex.Unlock()
+	return execution.spent, true
+}
+
+// Seed charges already recorded usage (e.g. today's ledger records) to the daily budgets
+func (e *Enforcer) Seed(records []*usage.Record) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.rollover()
+	for _, record := range records {
+		if record.Timestamp.UTC().Format(dateLayout) != e.date {
+			continue
+		}
+		spend := Spend{Tokens: record.TotalTokens, Cost: record.Cost}
+		if record.CacheHit {
+			spend = Spend{}
+		}
+		if record.Tenant != "" {
+			e.dailySpend(ScopeTenant, record.Tenant).add(spend)
+		}
+		e.dailySpend(ScopeModel, record.Model).add(spend)
+	}
+}
+
+// DailySpend returns today's spend of a tenant or model (scope ScopeTenant or ScopeModel)
+func (e *Enforcer) DailySpend(scope, name string) Spend {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.rollover()
+	if spend, exists := e.daily[scope+":"+name]; exists {
+		return *spend
+	}
+	return Spend{}
+}
+
+// Middleware returns a model middleware enforcing the budgets
+func (e *Enforcer) Middleware() *interfaces.Middleware {
+	return &interfaces.Middleware{
+		Name: "budget",
+		Unary: func(next interfaces.ModelHandler) interfaces.ModelHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+				reservation, err := e.Reserve(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+
+				resp, err := next(ctx, req)
+				if err != nil {
+					reservation.Release()
+					return resp, err
+				}
+
+				reservation.Commit(resp)
+				return resp, nil
+			}
+		},
+		Stream: func(next interfaces.StreamHandler) interfaces.StreamHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+				reservation, err := e.Reserve(ctx, req)
+				if err != nil {
+					return err
+				}
+
+				var reported *interfaces.PonchoUsage
+				err = next(ctx, req, func(chunk *interfaces.PonchoStreamChunk) error {
+					if chunk != nil && chunk.Usage != nil {
+						reported = chunk.Usage
+					}
+					return callback(chunk)
+				})
+
+				// A failed stream is charged only the usage it reported before failing
+				if err != nil && (reported == nil || reported.TotalTokens == 0) {
+					reservation.Release()
+					return err
+				}
+
+				reservation.Commit(&interfaces.PonchoModelResponse{Usage: reported})
+				return err
+			}
+		},
+	}
+}
+
+// Reservation holds the estimated spend of a call until it completes
+type Reservation struct {
+	enforcer  *Enforcer
+	flow      *flowExecution
+	tenant    string
+	model     string
+	date      string
+	estimate  Spend
+	provider  string
+	modelName string
+	done      bool
+}
+
+// Reserve checks the request against all applicable budgets and reserves its estimate.
+// It returns a BUDGET_EXCEEDED ModelError if any budget would be exceeded.
+func (e *Enforcer) Reserve(ctx context.Context, req *interfaces.PonchoModelRequest) (*Reservation, error) {
+	provider, modelName := e.resolve(req.Model)
+	r := &Reservation{
+		enforcer:  e,
+		model:     req.Model,
+		tenant:    metadataString(req.Metadata, usage.MetadataTenant),
+		estimate:  e.estimate(req, provider, modelName),
+		provider:  provider,
+		modelName: modelName,
+	}
+	r.flow, _ = ctx.Value(flowExecutionKey{}).(*flowExecution)
+
+	// Lock order: enforcer, then flow execution
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if r.flow != nil {
+		r.flow.mutex.Lock()
+		defer r.flow.mutex.Unlock()
+	}
+
+	e.rollover()
+	r.date = e.date
+
+	if r.flow != nil {
+		if limit := e.flowLimit(r.flow.name); r.flow.spent.exceeds(r.estimate, limit) {
+			return nil, r.reject(ScopeFlow, r.flow.name, limit, r.flow.spent)
+		}
+	}
+	if limit := e.tenantLimit(r.tenant); r.tenant != "" && limit != nil {
+		if spent := e.dailySpend(ScopeTenant, r.tenant); spent.exceeds(r.estimate, limit) {
+			return nil, r.reject(ScopeTenant, r.tenant, limit, *spent)
+		}
+	}
+	if limit := e.config.Models[r.model]; limit != nil {
+		if spent := e.dailySpend(ScopeModel, r.model); spent.exceeds(r.estimate, limit) {
+			return nil, r.reject(ScopeModel, r.model, limit, *spent)
+		}
+	}
+
+	r.charge(r.estimate, true)
+	return r, nil
+}
+
+// Commit replaces the reserved estimate with the usage reported in resp.
+// If resp reports no usage, the estimate is kept; cached responses cost nothing.
+func (r *Reservation) Commit(resp *interfaces.PonchoModelResponse) {
+	actual := r.estimate
+	if resp != nil {
+		if resp.Metadata[metadataCacheHit] == true {
+			actual = Spend{}
+		} else if resp.Usage != nil && resp.Usage.TotalTokens > 0 {
+			actual = Spend{Tokens: resp.Usage.TotalTokens, Cost: r.enforcer.cost(resp.Usage, r.provider, r.modelName)}
+		}
+	}
+	r.settle(actual)
+}
+
+// Release returns the reserved estimate, for calls that failed before reaching the provider
+func (r *Reservation) Release() {
+	r.settle(Spend{})
+}
+
+// settle charges actual in place of the estimate
+func (r *Reservation) settle(actual Spend) {
+	e := r.enforcer
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if r.flow != nil {
+		r.flow.mutex.Lock()
+		defer r.flow.mutex.Unlock()
+	}
+
+	if r.done {
+		return
+	}
+	r.done = true
+
+	r.charge(r.estimate, false)
+	r.charge(actual, true)
+}
+
+// charge adds (or removes) spend from every budget of the reservation.
+// Callers must hold the enforcer and flow execution locks.
+func (r *Reservation) charge(spend Spend, add bool) {
+	e := r.enforcer
+
+	apply := func(target *Spend) {
+		if add {
+			target.add(spend)
+		} else {
+			target.sub(spend)
+		}
+	}
+
+	if r.flow != nil {
+		apply(&r.flow.spent)
+	}
+	// Daily budgets of a previous day were reset at rollover
+	if r.date != e.date {
+		return
+	}
+	if r.tenant != "" {
+		apply(e.dailySpend(ScopeTenant, r.tenant))
+	}
+	apply(e.dailySpend(ScopeModel, r.model))
+}
+
+// reject logs a rejection and returns the typed error
+func (r *Reservation) reject(scope, name string, limit *interfaces.BudgetLimit, spent Spend) error {
+	r.enforcer.logger.Warn("Request rejected by budget",
+		"scope", scope,
+		"name", name,
+		"model", r.model,
+		"spent_tokens", spent.Tokens,
+		"estimated_tokens", r.estimate.Tokens)
+
+	return common.NewBudgetExceededError(
+		fmt.Sprintf("%s budget '%s' would be exceeded: spent %d tokens ($%.4f), request needs about %d tokens ($%.4f)",
+			scope, name, spent.Tokens, spent.Cost, r.estimate.Tokens, r.estimate.Cost),
+		r.provider, r.modelName,
+	).WithDetails(&Details{Scope: scope, Name: name, Limit: limit, Spent: spent, Estimate: r.estimate})
+}
+
+// estimate returns the pre-flight estimate of a request.
+// Models unknown to the tokenizer are estimated as free, so only their
+// recorded usage counts against budgets.
+func (e *Enforcer) estimate(req *interfaces.PonchoModelRequest, provider, modelName string) Spend {
+	estimated, err := e.tokenizer.CountRequestTokens(req, common.Provider(provider), modelName)
+	if err != nil {
+		return Spend{}
+	}
+	return Spend{Tokens: estimated.TotalTokens, Cost: e.cost(estimated, provider, modelName)}
+}
+
+func (e *Enforcer) cost(u *interfaces.PonchoUsage, provider, modelName string) float64 {
+	cost, err := e.tokenizer.EstimateCost(u, common.Provider(provider), modelName)
+	if err != nil {
+		return 0
+	}
+	return cost
+}
+
+func (e *Enforcer) flowLimit(flow string) *interfaces.BudgetLimit {
+	if limit, exists := e.config.Flows[flow]; exists {
+		return limit
+	}
+	return e.config.FlowExecution
+}
+
+func (e *Enforcer) tenantLimit(tenant string) *interfaces.BudgetLimit {
+	if limit, exists := e.config.Tenants[tenant]; exists {
+		return limit
+	}
+	return e.config.TenantDaily
+}
+
+// rollover resets daily budgets when the UTC date changes. Callers must hold the lock.
+func (e *Enforcer) rollover() {
+	date := e.now().UTC().Format(dateLayout)
+	if date != e.date {
+		e.date = date
+		e.daily = make(map[string]*Spend)
+	}
+}
+
+// dailySpend returns today's spend for a scope. Callers must hold the lock.
+func (e *Enforcer) dailySpend(scope, name string) *Spend {
+	key := scope + ":" + name
+	spend, exists := e.daily[key]
+	if !exists {
+		spend = &Spend{}
+		e.daily[key] = spend
+	}
+	return spend
+}
+
+func metadataString(metadata map[string]interface{}, key string) string {
+	if value, ok := metadata[key]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+func deepseekResolver(model string) (string, string) {
+	return "deepseek", "deepseek-chat"
+}
+
+func newTestEnforcer(t *testing.T, config *interfaces.BudgetConfig) *Enforcer {
+	t.Helper()
+
+	enforcer, err := NewEnforcer(config, deepseekResolver, interfaces.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+	return enforcer
+}
+
+func budgetRequest(tenant string) *interfaces.PonchoModelRequest {
+	maxTokens := 100
+	req := &interfaces.PonchoModelRequest{
+		Model:     "chat",
+		MaxTokens: &maxTokens,
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "describe the dress"}},
+		}},
+	}
+	if tenant != "" {
+		req.Metadata = map[string]interface{}{usage.MetadataTenant: tenant}
+	}
+	return req
+}
+
+func assertBudgetExceeded(t *testing.T, err error, scope string) {
+	t.Helper()
+
+	var modelErr *common.ModelError
+	if !errors.As(err, &modelErr) || modelErr.Code != common.ErrorCodeBudgetExceeded {
+		t.Fatalf("Expected BUDGET_EXCEEDED error, got: %v", err)
+	}
+	if details, ok := modelErr.Details.(*Details); !ok || details.Scope != scope {
+		t.Errorf("Expected %s budget details, got %+v", scope, modelErr.Details)
+	}
+	if modelErr.Retryable {
+		t.Error("Expected budget errors not to be retryable")
+	}
+}
+
+func TestEnforcer_FlowExecutionBudget(t *testing.T) {
+	enforcer := newTestEnforcer(t, &interfaces.BudgetConfig{
+		FlowExecution: &interfaces.BudgetLimit{MaxTokens: 1000},
+	})
+
+	calls := 0
+	handler := interfaces.ChainModel(func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+		calls++
+		return &interfaces.PonchoModelResponse{Usage: &interfaces.PonchoUsage{TotalTokens: 900}}, nil
+	}, enforcer.Middleware())
+
+	ctx := WithFlowExecution(context.Background(), "article_importer")
+	if _, err := handler(ctx, budgetRequest("")); err != nil {
+		t.Fatalf("Expected first call to pass: %v", err)
+	}
+
+	// 900 tokens spent, the estimate of another call does not fit into 1000
+	_, err := handler(ctx, budgetRequest(""))
+	assertBudgetExceeded(t, err, ScopeFlow)
+	if calls != 1 {
+		t.Errorf("Expected rejected call not to reach the provider, got %d calls", calls)
+	}
+
+	if spend, _ := FlowSpend(ctx); spend.Tokens != 900 {
+		t.Errorf("Expected flow spend of 900 tokens, got %d", spend.Tokens)
+	}
+
+	// A new flow execution starts with an empty budget
+	if _, err := handler(WithFlowExecution(context.Background(), "article_importer"), budgetRequest("")); err != nil {
+		t.Errorf("Expected new flow execution to pass: %v", err)
+	}
+}
+
+func TestEnforcer_TenantDailyBudget(t *testing.T) {
+	enforcer := newTestEnforcer(t, &interfaces.BudgetConfig{
+		TenantDaily: &interfaces.BudgetLimit{MaxTokens: 1000},
+		Tenants:     map[string]*interfaces.BudgetLimit{"vip": {MaxTokens: 100000}},
+	})
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	enforcer.now = func() time.Time { return now }
+
+	enforcer.Seed([]*usage.Record{
+		{Timestamp: now, Model: "chat", Tenant: "acme", TotalTokens: 950},
+		{Timestamp: now.Add(-24 * time.Hour), Model: "chat", Tenant: "acme", TotalTokens: 5000},
+	})
+
+	ctx := context.Background()
+	_, err := enforcer.Reserve(ctx, budgetRequest("acme"))
+	assertBudgetExceeded(t, err, ScopeTenant)
+
+	if _, err := enforcer.Reserve(ctx, budgetRequest("vip")); err != nil {
+		t.Errorf("Expected tenant override to allow the call: %v", err)
+	}
+
+	// Daily budgets reset at UTC midnight
+	now = now.Add(2 * time.Hour)
+	if _, err := enforcer.Reserve(ctx, budgetRequest("acme")); err != nil {
+		t.Errorf("Expected budget to reset on a new day: %v", err)
+	}
+}
+
+func TestEnforcer_ModelBudgetReservations(t *testing.T) {
+	enforcer := newTestEnforcer(t, &interfaces.BudgetConfig{
+		Models: map[string]*interfaces.BudgetLimit{"chat": {MaxTokens: 200}},
+	})
+	ctx := context.Background()
+
+	first, err := enforcer.Reserve(ctx, budgetRequest(""))
+	if err != nil {
+		t.Fatalf("Expected first reservation to pass: %v", err)
+	}
+	estimate := enforcer.DailySpend(ScopeModel, "chat").Tokens
+	if estimate == 0 || estimate > 200 {
+		t.Fatalf("Expected a reserved estimate within the budget, got %d", estimate)
+	}
+
+	// The in-flight reservation counts against the budget
+	_, err = enforcer.Reserve(ctx, budgetRequest(""))
+	assertBudgetExceeded(t, err, ScopeModel)
+
+	// The provider reported less than estimated
+	first.Commit(&interfaces.PonchoModelResponse{Usage: &interfaces.PonchoUsage{TotalTokens: 20}})
+	if spent := enforcer.DailySpend(ScopeModel, "chat").Tokens; spent != 20 {
+		t.Errorf("Expected reported usage to replace the estimate, got %d", spent)
+	}
+
+	second, err := enforcer.Reserve(ctx, budgetRequest(""))
+	if err != nil {
+		t.Fatalf("Expected reservation to pass after commit: %v", err)
+	}
+	second.Release()
+	second.Release()
+	if spent := enforcer.DailySpend(ScopeModel, "chat").Tokens; spent != 20 {
+		t.Errorf("Expected released reservation to be returned once, got %d", spent)
+	}
+}
+
+func TestEnforcer_FailedStream(t *testing.T) {
+	enforcer := newTestEnforcer(t, &interfaces.BudgetConfig{
+		Models: map[string]*interfaces.BudgetLimit{"chat": {MaxTokens: 200}},
+	})
+
+	var usageBeforeFailure *interfaces.PonchoUsage
+	handler := interfaces.ChainStream(func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+		if usageBeforeFailure != nil {
+			if err := callback(&interfaces.PonchoStreamChunk{Usage: usageBeforeFailure}); err != nil {
+				return err
+			}
+		}
+		return errors.New("connection reset")
+	}, enforcer.Middleware())
+	noop := func(*interfaces.PonchoStreamChunk) error { return nil }
+
+	// A stream that failed without reporting usage returns its reservation
+	if err := handler(context.Background(), budgetRequest(""), noop); err == nil {
+		t.Fatal("Expected the stream error")
+	}
+	if spent := enforcer.DailySpend(ScopeModel, "chat").Tokens; spent != 0 {
+		t.Errorf("Expected failed stream to release the estimate, got %d", spent)
+	}
+
+	// Usage reported before the failure is charged instead of the estimate
+	usageBeforeFailure = &interfaces.PonchoUsage{TotalTokens: 15}
+	if err := handler(context.Background(), budgetRequest(""), noop); err == nil {
+		t.Fatal("Expected the stream error")
+	}
+	if spent := enforcer.DailySpend(ScopeModel, "chat").Tokens; spent != 15 {
+		t.Errorf("Expected the reported 15 tokens, got %d", spent)
+	}
+}
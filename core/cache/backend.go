@@ -0,0 +1,332 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lruEntry is an entry of the LRU index
+type lruEntry struct {
+	key       string
+	value     []byte // nil for entries stored on disk
+	expiresAt time.Time
+}
+
+// lruIndex tracks entries in least recently used order with TTL expiry.
+// onRemove is called for every removed entry. Callers must hold the lock.
+type lruIndex struct {
+	maxSize   int
+	ttl       time.Duration
+	entries   map[string]*list.Element
+	order     *list.List
+	evictions int64
+	onRemove  func(entry *lruEntry)
+}
+
+func newLRUIndex(maxSize int, ttl time.Duration, onRemove func(entry *lruEntry)) *lruIndex {
+	return &lruIndex{
+		maxSize:  maxSize,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		onRemove: onRemove,
+	}
+}
+
+// get returns a live entry and marks it as recently used
+func (idx *lruIndex) get(key string) (*lruEntry, bool) {
+	element, exists := idx.entries[key]
+	if !exists {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if idx.ttl > 0 && time.Now().After(entry.expiresAt) {
+		idx.remove(element)
+		return nil, false
+	}
+
+	idx.order.MoveToFront(element)
+	return entry, true
+}
+
+// put adds or replaces an entry, evicting the least recently used entries
+func (idx *lruIndex) put(entry *lruEntry) {
+	if element, exists := idx.entries[entry.key]; exists {
+		element.Value = entry
+		idx.order.MoveToFront(element)
+		return
+	}
+
+	idx.entries[entry.key] = idx.order.PushFront(entry)
+
+	for idx.maxSize > 0 && idx.order.Len() > idx.maxSize {
+		idx.remove(idx.order.Back())
+		idx.evictions++
+	}
+}
+
+// remove removes an entry from the index
+func (idx *lruIndex) remove(element *list.Element) {
+	entry := element.Value.(*lruEntry)
+	idx.order.Remove(element)
+	delete(idx.entries, entry.key)
+	if idx.onRemove != nil {
+		idx.onRemove(entry)
+	}
+}
+
+// clear removes all entries
+func (idx *lruIndex) clear() {
+	for idx.order.Len() > 0 {
+		idx.remove(idx.order.Back())
+	}
+}
+
+// expiresAt returns the expiry time for a new entry
+func (idx *lruIndex) expiresAt() time.Time {
+	if idx.ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(idx.ttl)
+}
+
+// MemoryBackend is an in-memory LRU backend with TTL
+type MemoryBackend struct {
+	index *lruIndex
+	mutex sync.Mutex
+}
+
+// NewMemoryBackend creates a memory backend. A zero ttl disables expiry.
+func NewMemoryBackend(maxSize int, ttl time.Duration) *MemoryBackend {
+	return &MemoryBackend{index: newLRUIndex(maxSize, ttl, nil)}
+}
+
+// Get returns a cached value
+func (b *MemoryBackend) Get(key string) ([]byte, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	entry, ok := b.index.get(key)
+	if !ok {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores a value
+func (b *MemoryBackend) Set(key string, value []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.index.put(&lruEntry{key: key, value: value, expiresAt: b.index.expiresAt()})
+	return nil
+}
+
+// Clear removes all values
+func (b *MemoryBackend) Clear() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.index.clear()
+	return nil
+}
+
+// Len returns the number of cached values
+func (b *MemoryBackend) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.index.order.Len()
+}
+
+// Evictions returns the number of values evicted because the cache was full
+func (b *MemoryBackend) Evictions() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.index.evictions
+}
+
+// fileEntry is the on-disk format of a cached value
+type fileEntry struct {
+	ExpiresAt time.Time       `json:"expires_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// FileBackend stores values as JSON files in a directory.
+// The LRU index is rebuilt from the directory on start, ordered by file
+// modification time.
+type FileBackend struct {
+	dir   string
+	index *lruIndex
+	mutex sync.Mutex
+}
+
+// NewFileBackend creates a file backend in dir. A zero ttl disables expiry.
+func NewFileBackend(dir string, maxSize int, ttl time.Duration) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	b := &FileBackend{dir: dir}
+	b.index = newLRUIndex(maxSize, ttl, func(entry *lruEntry) {
+		_ = os.Remove(b.path(entry.key))
+	})
+
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// load rebuilds the LRU index from the cache directory
+func (b *FileBackend) load() error {
+	files, err := os.ReadDir(b.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type stored struct {
+		key     string
+		modTime time.Time
+	}
+	var entries []stored
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, stored{key: strings.TrimSuffix(file.Name(), ".json"), modTime: info.ModTime()})
+	}
+
+	// Oldest first, so the most recently used entry ends up in front
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+	for _, entry := range entries {
+		b.index.put(&lruEntry{key: entry.key, expiresAt: b.readExpiry(entry.key)})
+	}
+	b.index.evictions = 0
+
+	return nil
+}
+
+// readExpiry reads the expiry time of a stored entry
+func (b *FileBackend) readExpiry(key string) time.Time {
+	entry, err := b.read(key)
+	if err != nil {
+		// Unreadable entries expire immediately
+		return time.Unix(0, 0)
+	}
+	return entry.ExpiresAt
+}
+
+func (b *FileBackend) read(key string) (*fileEntry, error) {
+	data, err := os.ReadFile(b.path(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry fileEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (b *FileBackend) path(key string) string {
+	return filepath.Join(b.dir, key+".json")
+}
+
+// Get returns a cached value
+func (b *FileBackend) Get(key string) ([]byte, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.index.get(key); !ok {
+		return nil, false
+	}
+
+	entry, err := b.read(key)
+	if err != nil {
+		if element, exists := b.index.entries[key]; exists {
+			b.index.remove(element)
+		}
+		return nil, false
+	}
+
+	// Keep modification time in access order for the index rebuilt on start
+	now := time.Now()
+	_ = os.Chtimes(b.path(key), now, now)
+
+	return entry.Value, true
+}
+
+// Set stores a value
+func (b *FileBackend) Set(key string, value []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	expiresAt := b.index.expiresAt()
+	data, err := json.Marshal(fileEntry{ExpiresAt: expiresAt, Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	// Write atomically so readers never see partial files
+	tmp, err := os.CreateTemp(b.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), b.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	b.index.put(&lruEntry{key: key, expiresAt: expiresAt})
+	return nil
+}
+
+// Clear removes all values
+func (b *FileBackend) Clear() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.index.clear()
+	return nil
+}
+
+// Len returns the number of cached values
+func (b *FileBackend) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.index.order.Len()
+}
+
+// Evictions returns the number of values evicted because the cache was full
+func (b *FileBackend) Evictions() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.index.evictions
+}
@@ -0,0 +1,344 @@
+// Package cache provides a response cache for model generation requests
+//
+// Responses are keyed on the normalized PonchoModelRequest: model, messages (with
+// media replaced by content digests, tool calls, tool results and reasoning),
+// temperature, max tokens, generation parameters, response format and tools.
+// Request Metadata is not part of the key.
+//
+// Backends:
+//   - memory: in-process LRU with TTL
+//   - file:   on-disk entries (one JSON file per key) with an in-memory LRU index,
+//     so cached responses survive restarts
+//
+// The cache is applied to Generate calls as a model middleware (see Middleware).
+// Streaming calls are never cached. A single request can opt out by setting
+// Metadata["no_cache"] = true.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// Cache backend types
+const (
+	TypeMemory = "memory"
+	TypeFile   = "file"
+)
+
+// Defaults applied when CacheConfig leaves values empty
+const (
+	DefaultTTL     = time.Hour
+	DefaultMaxSize = 1000
+	DefaultDir     = ".poncho_cache"
+)
+
+// Request and response metadata keys
+const (
+	// MetadataNoCache disables the cache for a request when set to true
+	MetadataNoCache = "no_cache"
+	// MetadataCacheHit is set to true on responses served from the cache
+	MetadataCacheHit = "cache_hit"
+)
+
+// Backend stores serialized responses by key
+type Backend interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte) error
+	Clear() error
+	Len() int
+	Evictions() int64
+}
+
+// ResponseCache caches model responses
+type ResponseCache struct {
+	backend Backend
+	maxSize int
+	logger  interfaces.Logger
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// New creates a response cache from configuration
+func New(config *interfaces.CacheConfig, logger interfaces.Logger) (*ResponseCache, error) {
+	if config == nil {
+		return nil, fmt.Errorf("cache config cannot be nil")
+	}
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	ttl := DefaultTTL
+	if config.TTL != "" {
+		parsed, err := time.ParseDuration(config.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache ttl '%s': %w", config.TTL, err)
+		}
+		ttl = parsed
+	}
+
+	maxSize := config.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	var backend Backend
+	switch config.Type {
+	case "", TypeMemory:
+		backend = NewMemoryBackend(maxSize, ttl)
+	case TypeFile:
+		dir := config.Dir
+		if dir == "" {
+			dir = DefaultDir
+		}
+		fileBackend, err := NewFileBackend(dir, maxSize, ttl)
+		if err != nil {
+			return nil, err
+		}
+		backend = fileBackend
+	default:
+		return nil, fmt.Errorf("unsupported cache type: %s", config.Type)
+	}
+
+	logger.Info("Response cache created", "type", config.Type, "ttl", ttl, "max_size", maxSize)
+	return NewResponseCache(backend, maxSize, logger), nil
+}
+
+// NewResponseCache creates a response cache on top of a backend
+func NewResponseCache(backend Backend, maxSize int, logger interfaces.Logger) *ResponseCache {
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	return &ResponseCache{
+		backend: backend,
+		maxSize: maxSize,
+		logger:  logger,
+	}
+}
+
+// Get returns the cached response for a request
+func (c *ResponseCache) Get(req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, bool) {
+	key, err := Key(req)
+	if err != nil {
+		return nil, false
+	}
+
+	data, ok := c.backend.Get(key)
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	var resp interfaces.PonchoModelResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		c.logger.Warn("Failed to decode cached response", "key", key, "error", err)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return &resp, true
+}
+
+// Set stores the response for a request
+func (c *ResponseCache) Set(req *interfaces.PonchoModelRequest, resp *interfaces.PonchoModelResponse) error {
+	key, err := Key(req)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+
+	return c.backend.Set(key, data)
+}
+
+// Clear removes all cached responses
+func (c *ResponseCache) Clear() error {
+	return c.backend.Clear()
+}
+
+// Stats returns cache metrics
+func (c *ResponseCache) Stats() *interfaces.CacheMetrics {
+	hits, misses := c.hits.Load(), c.misses.Load()
+
+	stats := &interfaces.CacheMetrics{
+		Hits:       hits,
+		Misses:     misses,
+		Entries:    int64(c.backend.Len()),
+		MaxEntries: int64(c.maxSize),
+		Evictions:  c.backend.Evictions(),
+	}
+	if hits+misses > 0 {
+		stats.HitRate = float64(hits) / float64(hits+misses)
+	}
+
+	return stats
+}
+
+// Middleware returns a model middleware serving Generate calls from the cache
+func (c *ResponseCache) Middleware() *interfaces.Middleware {
+	return &interfaces.Middleware{
+		Name: "cache",
+		Unary: func(next interfaces.ModelHandler) interfaces.ModelHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+				if !Cacheable(req) {
+					return next(ctx, req)
+				}
+
+				if resp, ok := c.Get(req); ok {
+					if resp.Metadata == nil {
+						resp.Metadata = make(map[string]interface{})
+					}
+					resp.Metadata[MetadataCacheHit] = true
+					return resp, nil
+				}
+
+				resp, err := next(ctx, req)
+				if err != nil || resp == nil {
+					return resp, err
+				}
+
+				if resp.FinishReason != interfaces.PonchoFinishReasonError {
+					if err := c.Set(req, resp); err != nil {
+						c.logger.Warn("Failed to cache response", "model", req.Model, "error", err)
+					}
+				}
+
+				return resp, nil
+			}
+		},
+	}
+}
+
+// Cacheable reports whether the request may be served from the cache
+func Cacheable(req *interfaces.PonchoModelRequest) bool {
+	if req == nil || req.Stream {
+		return false
+	}
+
+	switch v := req.Metadata[MetadataNoCache].(type) {
+	case bool:
+		return !v
+	case string:
+		return !strings.EqualFold(v, "true")
+	}
+
+	return true
+}
+
+// normalizedRequest is the part of a request that determines the response
+type normalizedRequest struct {
+	Model       string                      `json:"model"`
+	Messages    []normalizedMessage         `json:"messages"`
+	Temperature *float32                    `json:"temperature,omitempty"`
+	MaxTokens   *int                        `json:"max_tokens,omitempty"`
+	Tools       []*interfaces.PonchoToolDef `json:"tools,omitempty"`
+
+	TopP             *float32 `json:"top_p,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float32 `json:"presence_penalty,omitempty"`
+	ResponseFormat   string   `json:"response_format,omitempty"`
+	Thinking         *bool    `json:"thinking,omitempty"`
+	LogProbs         *bool    `json:"logprobs,omitempty"`
+	TopLogProbs      *int     `json:"top_logprobs,omitempty"`
+}
+
+type normalizedMessage struct {
+	Role       string           `json:"role"`
+	Name       string           `json:"name,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+	Parts      []normalizedPart `json:"parts"`
+}
+
+type normalizedPart struct {
+	Type        string                           `json:"type"`
+	Text        string                           `json:"text,omitempty"` // text and reasoning
+	MediaDigest string                           `json:"media_digest,omitempty"`
+	MimeType    string                           `json:"mime_type,omitempty"`
+	Tool        *interfaces.PonchoToolPart       `json:"tool,omitempty"`
+	ToolResult  *interfaces.PonchoToolResultPart `json:"tool_result,omitempty"`
+}
+
+// Key returns the cache key of a request
+func Key(req *interfaces.PonchoModelRequest) (string, error) {
+	if req == nil {
+		return "", fmt.Errorf("request cannot be nil")
+	}
+
+	normalized := normalizedRequest{
+		Model:       req.Model,
+		Messages:    make([]normalizedMessage, 0, len(req.Messages)),
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Tools:       req.Tools,
+
+		TopP:             req.TopP,
+		Stop:             req.Stop,
+		Seed:             req.Seed,
+		FrequencyPenalty: req.FrequencyPenalty,
+		PresencePenalty:  req.PresencePenalty,
+		ResponseFormat:   string(req.ResponseFormat),
+		Thinking:         req.Thinking,
+		LogProbs:         req.LogProbs,
+		TopLogProbs:      req.TopLogProbs,
+	}
+
+	for _, message := range req.Messages {
+		if message == nil {
+			continue
+		}
+
+		nm := normalizedMessage{
+			Role:       strings.ToLower(string(message.Role)),
+			ToolCallID: message.ToolCallID,
+			Parts:      make([]normalizedPart, 0, len(message.Content)),
+		}
+		if message.Name != nil {
+			nm.Name = *message.Name
+		}
+
+		for _, part := range message.Content {
+			if part == nil {
+				continue
+			}
+
+			np := normalizedPart{Type: string(part.Type), Text: part.Text, Tool: part.Tool, ToolResult: part.ToolResult}
+			if part.Media != nil {
+				np.MediaDigest = digest(part.Media.URL)
+				np.MimeType = part.Media.MimeType
+			}
+			nm.Parts = append(nm.Parts, np)
+		}
+
+		normalized.Messages = append(normalized.Messages, nm)
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	return digest(string(data)), nil
+}
+
+// digest returns the hex encoded SHA-256 of s. Media URLs are usually data URLs,
+// so hashing keeps large base64 images out of the key.
+func digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
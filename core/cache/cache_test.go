@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+func testRequest(text string) *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Model: "glm-4.6v",
+		Messages: []*interfaces.PonchoMessage{{
+			Role: interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{
+				{Type: interfaces.PonchoContentTypeText, Text: text},
+				{Type: interfaces.PonchoContentTypeMedia, Media: &interfaces.PonchoMediaPart{URL: "data:image/jpeg;base64,AAAA", MimeType: "image/jpeg"}},
+			},
+		}},
+	}
+}
+
+func mustKey(t *testing.T, req *interfaces.PonchoModelRequest) string {
+	t.Helper()
+
+	key, err := Key(req)
+	if err != nil {
+		t.Fatalf("Key failed: %v", err)
+	}
+	return key
+}
+
+func TestKey_Normalization(t *testing.T) {
+	base := mustKey(t, testRequest("describe"))
+
+	withMetadata := testRequest("describe")
+	withMetadata.Metadata = map[string]interface{}{"trace_id": "abc"}
+	if mustKey(t, withMetadata) != base {
+		t.Error("Expected metadata to be ignored in the key")
+	}
+
+	temperature := float32(0.2)
+	withTemperature := testRequest("describe")
+	withTemperature.Temperature = &temperature
+	if mustKey(t, withTemperature) == base {
+		t.Error("Expected temperature to change the key")
+	}
+
+	otherImage := testRequest("describe")
+	otherImage.Messages[0].Content[1].Media.URL = "data:image/jpeg;base64,BBBB"
+	if mustKey(t, otherImage) == base {
+		t.Error("Expected media content to change the key")
+	}
+
+	withTools := testRequest("describe")
+	withTools.Tools = []*interfaces.PonchoToolDef{{Name: "search"}}
+	if mustKey(t, withTools) == base {
+		t.Error("Expected tools to change the key")
+	}
+
+	jsonMode := testRequest("describe")
+	jsonMode.ResponseFormat = interfaces.PonchoResponseFormatJSONObject
+	if mustKey(t, jsonMode) == base {
+		t.Error("Expected the response format to change the key")
+	}
+
+	seed := 42
+	withSeed := testRequest("describe")
+	withSeed.Seed = &seed
+	if mustKey(t, withSeed) == base {
+		t.Error("Expected generation parameters to change the key")
+	}
+}
+
+func TestKey_AgentTurns(t *testing.T) {
+	toolTurn := func(callID, content string, isError bool, reasoning string) *interfaces.PonchoModelRequest {
+		req := testRequest("describe")
+		req.Messages = append(req.Messages,
+			&interfaces.PonchoMessage{
+				Role: interfaces.PonchoRoleAssistant,
+				Content: []*interfaces.PonchoContentPart{
+					{Type: interfaces.PonchoContentTypeReasoning, Text: reasoning},
+					{Type: interfaces.PonchoContentTypeTool, Tool: &interfaces.PonchoToolPart{ID: callID, Name: "stock"}},
+				},
+			},
+			&interfaces.PonchoMessage{
+				Role:       interfaces.PonchoRoleTool,
+				ToolCallID: callID,
+				Content: []*interfaces.PonchoContentPart{{
+					Type:       interfaces.PonchoContentTypeToolResult,
+					ToolResult: &interfaces.PonchoToolResultPart{ToolCallID: callID, Name: "stock", Content: content, IsError: isError},
+				}},
+			})
+		return req
+	}
+
+	base := mustKey(t, toolTurn("call_1", "5", false, "check stock"))
+	if mustKey(t, toolTurn("call_1", "500", false, "check stock")) == base {
+		t.Error("Expected the tool result content to change the key")
+	}
+	if mustKey(t, toolTurn("call_1", "5", true, "check stock")) == base {
+		t.Error("Expected a tool error to change the key")
+	}
+	if mustKey(t, toolTurn("call_2", "5", false, "check stock")) == base {
+		t.Error("Expected the tool call ID to change the key")
+	}
+	if mustKey(t, toolTurn("call_1", "5", false, "check price")) == base {
+		t.Error("Expected reasoning to change the key")
+	}
+
+	// The tool call ID of the message alone changes the key too
+	otherMessageID := toolTurn("call_1", "5", false, "check stock")
+	otherMessageID.Messages[len(otherMessageID.Messages)-1].ToolCallID = "call_9"
+	if mustKey(t, otherMessageID) == base {
+		t.Error("Expected the message tool call ID to change the key")
+	}
+}
+
+func TestMemoryBackend_LRUAndTTL(t *testing.T) {
+	backend := NewMemoryBackend(2, 50*time.Millisecond)
+
+	backend.Set("a", []byte("1"))
+	backend.Set("b", []byte("2"))
+	backend.Get("a") // a becomes most recently used
+	backend.Set("c", []byte("3"))
+
+	if _, ok := backend.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, ok := backend.Get("a"); !ok {
+		t.Error("Expected recently used entry to be kept")
+	}
+	if backend.Evictions() != 1 {
+		t.Errorf("Expected 1 eviction, got %d", backend.Evictions())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := backend.Get("c"); ok {
+		t.Error("Expected entry to expire after TTL")
+	}
+}
+
+func TestFileBackend_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	backend, err := NewFileBackend(dir, 2, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create file backend: %v", err)
+	}
+	if err := backend.Set("a", []byte(`{"v":1}`)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	// A new backend on the same directory sees existing entries
+	reopened, err := NewFileBackend(dir, 2, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to reopen file backend: %v", err)
+	}
+	value, ok := reopened.Get("a")
+	if !ok || string(value) != `{"v":1}` {
+		t.Errorf("Expected persisted entry, got %q (found=%v)", value, ok)
+	}
+
+	reopened.Set("b", []byte(`2`))
+	reopened.Set("c", []byte(`3`))
+	if reopened.Len() != 2 || reopened.Evictions() != 1 {
+		t.Errorf("Expected 2 entries and 1 eviction, got %d and %d", reopened.Len(), reopened.Evictions())
+	}
+	if _, ok := reopened.Get("a"); ok {
+		t.Error("Expected oldest entry to be evicted")
+	}
+}
+
+func TestMiddleware_HitMissAndOptOut(t *testing.T) {
+	responseCache, err := New(&interfaces.CacheConfig{Type: TypeMemory, TTL: "1m", MaxSize: 10}, interfaces.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	calls := 0
+	handler := interfaces.ChainModel(func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+		calls++
+		return &interfaces.PonchoModelResponse{
+			Message: &interfaces.PonchoMessage{
+				Role:    interfaces.PonchoRoleAssistant,
+				Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "red dress"}},
+			},
+			Usage:        &interfaces.PonchoUsage{TotalTokens: 42},
+			FinishReason: interfaces.PonchoFinishReasonStop,
+		}, nil
+	}, responseCache.Middleware())
+
+	ctx := context.Background()
+	if _, err := handler(ctx, testRequest("describe")); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	resp, err := handler(ctx, testRequest("describe"))
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected second call to be served from cache, model called %d times", calls)
+	}
+	if resp.Metadata[MetadataCacheHit] != true || resp.Message.Content[0].Text != "red dress" || resp.Usage.TotalTokens != 42 {
+		t.Errorf("Unexpected cached response: %+v", resp)
+	}
+
+	optOut := testRequest("describe")
+	optOut.Metadata = map[string]interface{}{MetadataNoCache: true}
+	if _, err := handler(ctx, optOut); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if calls != 2 {
+		t.Error("Expected opted-out request to bypass the cache")
+	}
+
+	stats := responseCache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 || stats.HitRate != 0.5 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+}
+
+func TestNew_UnsupportedType(t *testing.T) {
+	if _, err := New(&interfaces.CacheConfig{Type: "redis"}, interfaces.NewNoOpLogger()); err == nil {
+		t.Error("Expected error for unsupported cache type")
+	}
+	if _, err := New(&interfaces.CacheConfig{TTL: "soon"}, interfaces.NewNoOpLogger()); err == nil {
+		t.Error("Expected error for invalid TTL")
+	}
+}
@@ -7,7 +7,7 @@ package config
 // Key features:
 // - Environment variable substitution for sensitive data using ${VAR_NAME} syntax
 // - Configuration for models, tools, flows, and system settings
-// - Hot-reload capabilities with configuration change watchers
+// - Hot-reload capabilities with configuration change watchers and file polling
 // - Comprehensive configuration validation with detailed error reporting
 // - Factory methods for creating and initializing components from configuration
 // - Type-safe configuration access with automatic type conversion
@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
@@ -72,14 +73,43 @@ type ConfigManager interface {
 	// GetConfig возвращает полную конфигурацию
 	GetConfig() *ConfigData
 
-	// Watch отслеживает изменения конфигурации
+	// Watch отслеживает изменения конфигурации.
+	// Если заданы файлы конфигурации, они проверяются на изменения и перезагружаются.
 	Watch(callback func(*ConfigData)) error
 
+	// StopWatching останавливает отслеживание файлов конфигурации
+	StopWatching()
+
 	// GetModelConfigs возвращает конфигурации моделей
 	GetModelConfigs() (map[string]*interfaces.ModelConfig, error)
 
 	// LoadAndInitializeModels загружает и инициализирует модели
 	LoadAndInitializeModels() (map[string]interfaces.PonchoModel, error)
+
+	// GetToolConfigs возвращает конфигурации инструментов
+	GetToolConfigs() (map[string]*interfaces.ToolConfig, error)
+
+	// GetCacheConfig возвращает конфигурацию кэша ответов (nil, если секции нет)
+	GetCacheConfig() (*interfaces.CacheConfig, error)
+
+	// GetUsageConfig возвращает конфигурацию журнала использования токенов (nil, если секции нет)
+	GetUsageConfig() (*interfaces.UsageConfig, error)
+
+	// GetBudgetConfig возвращает конфигурацию бюджетов расходов на модели (nil, если секции нет)
+	GetBudgetConfig() (*interfaces.BudgetConfig, error)
+
+	// GetTokenizersConfig возвращает файлы токенизаторов и их привязку к моделям (nil, если секции нет)
+	GetTokenizersConfig() (*interfaces.TokenizersConfig, error)
+
+	// GetSecurityConfig возвращает конфигурацию безопасности (nil, если секции нет)
+	GetSecurityConfig() (*interfaces.SecurityConfig, error)
+}
+
+// EmbeddingConfigProvider реализуется менеджерами конфигурации, поддерживающими
+// модели эмбеддингов (секция embedding_models)
+type EmbeddingConfigProvider interface {
+	// GetEmbeddingModelConfigs возвращает конфигурации моделей эмбеддингов
+	GetEmbeddingModelConfigs() (map[string]*interfaces.ModelConfig, error)
 }
 
 // ConfigManagerImpl реализация ConfigManager
@@ -95,6 +125,12 @@ type ConfigManagerImpl struct {
 	watchers  []func(*ConfigData)
 	envPrefix string
 	filePaths []string
+	mutex     sync.RWMutex
+
+	// File watching
+	watchInterval time.Duration
+	watchStop     chan struct{}
+	fileModTimes  map[string]time.Time
 }
 
 // ConfigOptions опции для создания ConfigManager
@@ -104,6 +140,9 @@ type ConfigOptions struct {
 	Loader    ConfigLoader
 	Validator ConfigValidator
 	Logger    interfaces.Logger
+
+	// WatchInterval интервал проверки файлов конфигурации (по умолчанию 2s)
+	WatchInterval time.Duration
 }
 
 // NewConfigManager создает новый экземпляр ConfigManager
@@ -120,6 +159,10 @@ func NewConfigManager(opts ConfigOptions) *ConfigManagerImpl {
 		opts.EnvPrefix = "PONCHO_"
 	}
 
+	if opts.WatchInterval <= 0 {
+		opts.WatchInterval = 2 * time.Second
+	}
+
 	// Initialize model-related components
 	// Note: modelFactoryMgr is now managed by the service locator
 	modelValidator := NewModelConfigValidator(opts.Logger)
@@ -136,6 +179,8 @@ func NewConfigManager(opts ConfigOptions) *ConfigManagerImpl {
 		envPrefix:       opts.EnvPrefix,
 		filePaths:       opts.FilePaths,
 		watchers:        make([]func(*ConfigData), 0),
+		watchInterval:   opts.WatchInterval,
+		fileModTimes:    make(map[string]time.Time),
 	}
 }
 
@@ -146,6 +191,9 @@ func (cm *ConfigManagerImpl) Load() error {
 	var configData *ConfigData
 	var err error
 
+	// Запоминаем время изменения файлов до чтения, чтобы не пропустить запись во время загрузки
+	modTimes := cm.statFiles()
+
 	// Загружаем из файлов
 	if len(cm.filePaths) > 0 {
 		configData, err = cm.loader.LoadMultiple(cm.filePaths)
@@ -169,7 +217,10 @@ func (cm *ConfigManagerImpl) Load() error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	cm.mutex.Lock()
 	cm.config = configData
+	cm.fileModTimes = modTimes
+	cm.mutex.Unlock()
 
 	// Уведомляем watchers
 	cm.notifyWatchers()
@@ -179,19 +230,20 @@ func (cm *ConfigManagerImpl) Load() error {
 	return nil
 }
 
-// Reload перезагружает конфигурацию
+// Reload перезагружает конфигурацию.
+// При ошибке загрузки или валидации остается действующей предыдущая конфигурация.
 func (cm *ConfigManagerImpl) Reload() error {
 	cm.logger.Info("Reloading configuration")
 
-	// Очищаем кэши и watchers
-	cm.config = nil
-
 	// Загружаем заново
 	return cm.Load()
 }
 
 // Get возвращает значение по ключу
 func (cm *ConfigManagerImpl) Get(key string) interface{} {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
 	if cm.config == nil {
 		return nil
 	}
@@ -328,6 +380,7 @@ func (cm *ConfigManagerImpl) GetStringSlice(key string) []string {
 
 // Set устанавливает значение
 func (cm *ConfigManagerImpl) Set(key string, value interface{}) {
+	cm.mutex.Lock()
 	if cm.config == nil {
 		cm.config = &ConfigData{
 			Source: "memory",
@@ -337,6 +390,7 @@ func (cm *ConfigManagerImpl) Set(key string, value interface{}) {
 	}
 
 	cm.setValueByPath(cm.config.Data, key, value)
+	cm.mutex.Unlock()
 
 	// Уведомляем watchers
 	cm.notifyWatchers()
@@ -349,6 +403,9 @@ func (cm *ConfigManagerImpl) Has(key string) bool {
 
 // GetSection возвращает секцию конфигурации
 func (cm *ConfigManagerImpl) GetSection(section string) map[string]interface{} {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
 	if cm.config == nil {
 		return nil
 	}
@@ -367,24 +424,116 @@ func (cm *ConfigManagerImpl) GetSection(section string) map[string]interface{} {
 
 // Validate валидирует конфигурацию
 func (cm *ConfigManagerImpl) Validate() error {
-	if cm.config == nil {
+	configData := cm.GetConfig()
+	if configData == nil {
 		return fmt.Errorf("configuration not loaded")
 	}
 
-	return cm.validator.Validate(cm.config)
+	return cm.validator.Validate(configData)
 }
 
 // GetConfig возвращает полную конфигурацию
 func (cm *ConfigManagerImpl) GetConfig() *ConfigData {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
 	return cm.config
 }
 
-// Watch отслеживает изменения конфигурации
+// Watch отслеживает изменения конфигурации.
+// Callback вызывается после каждой успешной загрузки или изменения конфигурации.
+// Если заданы файлы конфигурации, они периодически проверяются на изменения
+// (по времени модификации) и при изменении конфигурация перезагружается.
 func (cm *ConfigManagerImpl) Watch(callback func(*ConfigData)) error {
+	if callback == nil {
+		return fmt.Errorf("watch callback cannot be nil")
+	}
+
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
 	cm.watchers = append(cm.watchers, callback)
+
+	// Запускаем отслеживание файлов один раз
+	if len(cm.filePaths) > 0 && cm.watchStop == nil {
+		cm.watchStop = make(chan struct{})
+		go cm.watchFiles(cm.watchStop)
+		cm.logger.Info("Watching configuration files", "files", cm.filePaths, "interval", cm.watchInterval)
+	}
+
 	return nil
 }
 
+// StopWatching останавливает отслеживание файлов конфигурации
+func (cm *ConfigManagerImpl) StopWatching() {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if cm.watchStop != nil {
+		close(cm.watchStop)
+		cm.watchStop = nil
+	}
+}
+
+// watchFiles периодически проверяет файлы конфигурации и перезагружает их при изменении
+func (cm *ConfigManagerImpl) watchFiles(stop <-chan struct{}) {
+	ticker := time.NewTicker(cm.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !cm.filesChanged() {
+				continue
+			}
+
+			cm.logger.Info("Configuration files changed, reloading", "files", cm.filePaths)
+			if err := cm.Reload(); err != nil {
+				// Keep the previous configuration and remember the new file state
+				// so a broken file is not reloaded on every tick
+				cm.logger.Error("Failed to reload changed configuration", "error", err)
+				modTimes := cm.statFiles()
+				cm.mutex.Lock()
+				cm.fileModTimes = modTimes
+				cm.mutex.Unlock()
+			}
+		}
+	}
+}
+
+// filesChanged проверяет, изменились ли файлы конфигурации с последней загрузки
+func (cm *ConfigManagerImpl) filesChanged() bool {
+	current := cm.statFiles()
+
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	if len(current) != len(cm.fileModTimes) {
+		return true
+	}
+
+	for path, modTime := range current {
+		if previous, ok := cm.fileModTimes[path]; !ok || !previous.Equal(modTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statFiles возвращает время модификации существующих файлов конфигурации
+func (cm *ConfigManagerImpl) statFiles() map[string]time.Time {
+	modTimes := make(map[string]time.Time, len(cm.filePaths))
+	for _, path := range cm.filePaths {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
 // applyEnvironmentVariables применяет переменные окружения
 func (cm *ConfigManagerImpl) applyEnvironmentVariables(config *ConfigData) {
 	cm.logger.Debug("Applying environment variables", "prefix", cm.envPrefix)
@@ -501,14 +650,21 @@ func (cm *ConfigManagerImpl) setValueByPath(data map[string]interface{}, path st
 
 // notifyWatchers уведомляет всех watchers об изменениях
 func (cm *ConfigManagerImpl) notifyWatchers() {
-	for _, watcher := range cm.watchers {
-		go watcher(cm.config)
+	cm.mutex.RLock()
+	watchers := make([]func(*ConfigData), len(cm.watchers))
+	copy(watchers, cm.watchers)
+	configData := cm.config
+	cm.mutex.RUnlock()
+
+	for _, watcher := range watchers {
+		go watcher(configData)
 	}
 }
 
 // GetModelConfigs возвращает конфигурации моделей
 func (cm *ConfigManagerImpl) GetModelConfigs() (map[string]*interfaces.ModelConfig, error) {
-	if cm.config == nil {
+	configData := cm.GetConfig()
+	if configData == nil {
 		return nil, fmt.Errorf("configuration not loaded")
 	}
 
@@ -518,7 +674,113 @@ func (cm *ConfigManagerImpl) GetModelConfigs() (map[string]*interfaces.ModelConf
 		return nil, fmt.Errorf("loader does not support model configuration loading")
 	}
 
-	return loader.LoadModelConfigs(cm.config)
+	return loader.LoadModelConfigs(configData)
+}
+
+// GetEmbeddingModelConfigs возвращает конфигурации моделей эмбеддингов
+func (cm *ConfigManagerImpl) GetEmbeddingModelConfigs() (map[string]*interfaces.ModelConfig, error) {
+	configData := cm.GetConfig()
+	if configData == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+
+	loader, ok := cm.loader.(*ConfigLoaderImpl)
+	if !ok {
+		return nil, fmt.Errorf("loader does not support embedding model configuration loading")
+	}
+
+	return loader.LoadEmbeddingModelConfigs(configData)
+}
+
+// GetToolConfigs возвращает конфигурации инструментов
+func (cm *ConfigManagerImpl) GetToolConfigs() (map[string]*interfaces.ToolConfig, error) {
+	configData := cm.GetConfig()
+	if configData == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+
+	// Use the loader to extract tool configurations
+	loader, ok := cm.loader.(*ConfigLoaderImpl)
+	if !ok {
+		return nil, fmt.Errorf("loader does not support tool configuration loading")
+	}
+
+	return loader.LoadToolConfigs(configData)
+}
+
+// GetCacheConfig возвращает конфигурацию кэша ответов
+func (cm *ConfigManagerImpl) GetCacheConfig() (*interfaces.CacheConfig, error) {
+	configData := cm.GetConfig()
+	if configData == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+
+	loader, ok := cm.loader.(*ConfigLoaderImpl)
+	if !ok {
+		return nil, fmt.Errorf("loader does not support cache configuration loading")
+	}
+
+	return loader.LoadCacheConfig(configData)
+}
+
+// GetUsageConfig возвращает конфигурацию журнала использования токенов
+func (cm *ConfigManagerImpl) GetUsageConfig() (*interfaces.UsageConfig, error) {
+	configData := cm.GetConfig()
+	if configData == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+
+	loader, ok := cm.loader.(*ConfigLoaderImpl)
+	if !ok {
+		return nil, fmt.Errorf("loader does not support usage configuration loading")
+	}
+
+	return loader.LoadUsageConfig(configData)
+}
+
+// GetBudgetConfig возвращает конфигурацию бюджетов расходов на модели
+func (cm *ConfigManagerImpl) GetBudgetConfig() (*interfaces.BudgetConfig, error) {
+	configData := cm.GetConfig()
+	if configData == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+
+	loader, ok := cm.loader.(*ConfigLoaderImpl)
+	if !ok {
+		return nil, fmt.Errorf("loader does not support budget configuration loading")
+	}
+
+	return loader.LoadBudgetConfig(configData)
+}
+
+// GetTokenizersConfig возвращает конфигурацию токенизаторов
+func (cm *ConfigManagerImpl) GetTokenizersConfig() (*interfaces.TokenizersConfig, error) {
+	configData := cm.GetConfig()
+	if configData == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+
+	loader, ok := cm.loader.(*ConfigLoaderImpl)
+	if !ok {
+		return nil, fmt.Errorf("loader does not support tokenizers configuration loading")
+	}
+
+	return loader.LoadTokenizersConfig(configData)
+}
+
+// GetSecurityConfig возвращает конфигурацию безопасности
+func (cm *ConfigManagerImpl) GetSecurityConfig() (*interfaces.SecurityConfig, error) {
+	configData := cm.GetConfig()
+	if configData == nil {
+		return nil, fmt.Errorf("configuration not loaded")
+	}
+
+	loader, ok := cm.loader.(*ConfigLoaderImpl)
+	if !ok {
+		return nil, fmt.Errorf("loader does not support security configuration loading")
+	}
+
+	return loader.LoadSecurityConfig(configData)
 }
 
 // LoadAndInitializeModels загружает и инициализирует модели
@@ -631,7 +893,8 @@ func (cm *ConfigManagerImpl) ValidateModelConfigs() error {
 
 // GetConfigAsStruct конвертирует конфигурацию в структуру
 func (cm *ConfigManagerImpl) GetConfigAsStruct(target interface{}) error {
-	if cm.config == nil {
+	configData := cm.GetConfig()
+	if configData == nil {
 		return fmt.Errorf("configuration not loaded")
 	}
 
@@ -646,7 +909,7 @@ func (cm *ConfigManagerImpl) GetConfigAsStruct(target interface{}) error {
 		return fmt.Errorf("target must point to a struct")
 	}
 
-	return cm.convertMapToStruct(cm.config.Data, targetValue)
+	return cm.convertMapToStruct(configData.Data, targetValue)
 }
 
 // convertMapToStruct рекурсивно конвертирует map в struct
@@ -4,6 +4,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
 )
 
 func TestConfigManager_Load_FromFile(t *testing.T) {
@@ -341,6 +343,66 @@ func TestConfigManager_Watch(t *testing.T) {
 	}
 }
 
+func TestConfigManager_WatchFile(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_config_*.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	modelsContent := "models:\n" +
+		"  deepseek:\n" +
+		"    provider: \"deepseek\"\n" +
+		"    model_name: \"deepseek-chat\"\n" +
+		"    api_key: \"dummy-key-that-is-long-enough-to-pass-validation\"\n" +
+		"    max_tokens: 4000\n" +
+		"    temperature: 0.7\n" +
+		"    timeout: \"30s\"\n"
+
+	tmpFile.WriteString(modelsContent + "initial:\n  value: \"original\"\n")
+	tmpFile.Close()
+
+	manager := NewConfigManager(ConfigOptions{
+		FilePaths:     []string{tmpFile.Name()},
+		Logger:        interfaces.NewNoOpLogger(),
+		WatchInterval: 10 * time.Millisecond,
+	})
+	defer manager.StopWatching()
+
+	if err := manager.Load(); err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	changed := make(chan string, 10)
+	if err := manager.Watch(func(config *ConfigData) {
+		if value, ok := config.Data["initial"].(map[string]interface{}); ok {
+			changed <- value["value"].(string)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to add watcher: %v", err)
+	}
+
+	// Изменяем файл и сдвигаем время модификации
+	if err := os.WriteFile(tmpFile.Name(), []byte(modelsContent+"initial:\n  value: \"updated\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to update config file: %v", err)
+	}
+	future := time.Now().Add(time.Second)
+	os.Chtimes(tmpFile.Name(), future, future)
+
+	select {
+	case value := <-changed:
+		if value != "updated" {
+			t.Errorf("Expected 'updated', got '%s'", value)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected watcher to be notified about file change")
+	}
+
+	if manager.GetString("initial.value") != "updated" {
+		t.Errorf("Expected 'updated', got '%s'", manager.GetString("initial.value"))
+	}
+}
+
 func TestConfigManager_Reload(t *testing.T) {
 	configContent := "models:\n" +
 		"  deepseek:\n" +
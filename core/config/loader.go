@@ -284,6 +284,44 @@ func (cl *ConfigLoaderImpl) LoadModelConfigs(configData *ConfigData) (map[string
 	return modelConfigs, nil
 }
 
+// LoadEmbeddingModelConfigs extracts embedding model configurations from the
+// "embedding_models" section. Entries use the same fields as "models".
+func (cl *ConfigLoaderImpl) LoadEmbeddingModelConfigs(configData *ConfigData) (map[string]*interfaces.ModelConfig, error) {
+	if configData == nil || configData.Data == nil {
+		return nil, fmt.Errorf("config data is nil")
+	}
+
+	modelsData, exists := configData.Data["embedding_models"]
+	if !exists || modelsData == nil {
+		return make(map[string]*interfaces.ModelConfig), nil
+	}
+
+	modelsMap, ok := modelsData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("embedding_models section must be a map/object")
+	}
+
+	modelConfigs := make(map[string]*interfaces.ModelConfig)
+	for name, modelData := range modelsMap {
+		modelConfig, err := cl.parseModelConfig(name, modelData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse embedding model %s: %w", name, err)
+		}
+
+		if err := cl.substituteEnvVars(modelConfig); err != nil {
+			return nil, fmt.Errorf("failed to substitute env vars for embedding model %s: %w", name, err)
+		}
+
+		modelConfigs[name] = modelConfig
+	}
+
+	cl.logger.Info("Embedding model configurations loaded successfully",
+		"count", len(modelConfigs),
+		"source", configData.Source)
+
+	return modelConfigs, nil
+}
+
 // parseModelConfig parses a single model configuration
 func (cl *ConfigLoaderImpl) parseModelConfig(name string, data interface{}) (*interfaces.ModelConfig, error) {
 	// Convert to map[string]interface{}
@@ -309,7 +347,7 @@ func (cl *ConfigLoaderImpl) parseModelConfig(name string, data interface{}) (*in
 
 	if apiKey, ok := modelData["api_key"].(string); ok {
 		config.APIKey = apiKey
-	} else {
+	} else if baseURL, _ := modelData["base_url"].(string); providerRequiresAPIKey(config.Provider, baseURL) {
 		return nil, fmt.Errorf("api_key is required and must be a string")
 	}
 
@@ -375,6 +413,42 @@ func (cl *ConfigLoaderImpl) parseModelConfig(name string, data interface{}) (*in
 		}
 	}
 
+	// Parse retry and circuit breaker policies
+	if retryData, ok := modelData["retry"]; ok {
+		retryMap, ok := retryData.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("retry section must be a map/object")
+		}
+
+		config.Retry = &interfaces.RetryConfig{
+			MaxAttempts: cl.getIntOrDefault(retryMap, "max_attempts", 0),
+			Backoff:     cl.getStringOrDefault(retryMap, "backoff", ""),
+			BaseDelay:   cl.getStringOrDefault(retryMap, "base_delay", ""),
+			MaxDelay:    cl.getStringOrDefault(retryMap, "max_delay", ""),
+		}
+	}
+
+	if breakerData, ok := modelData["circuit_breaker"]; ok {
+		breakerMap, ok := breakerData.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("circuit_breaker section must be a map/object")
+		}
+
+		config.CircuitBreaker = &interfaces.CircuitBreakerConfig{
+			MaxFailures:  cl.getIntOrDefault(breakerMap, "max_failures", 0),
+			ResetTimeout: cl.getStringOrDefault(breakerMap, "reset_timeout", ""),
+		}
+	}
+
+	// Parse middleware list
+	if middlewareData, ok := modelData["middleware"]; ok {
+		middleware, err := cl.parseStringList(middlewareData)
+		if err != nil {
+			return nil, fmt.Errorf("middleware must be a list of names: %w", err)
+		}
+		config.Middleware = middleware
+	}
+
 	// Parse custom parameters
 	if customParams, ok := modelData["custom_params"]; ok {
 		customMap, ok := customParams.(map[string]interface{})
@@ -387,6 +461,422 @@ func (cl *ConfigLoaderImpl) parseModelConfig(name string, data interface{}) (*in
 	return config, nil
 }
 
+// parseStringList converts a YAML/JSON list to a string slice
+func (cl *ConfigLoaderImpl) parseStringList(data interface{}) ([]string, error) {
+	switch v := data.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string, got %T", item)
+			}
+			result = append(result, str)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected list, got %T", data)
+	}
+}
+
+// LoadCacheConfig extracts the response cache configuration from config data.
+// Returns nil if there is no cache section.
+func (cl *ConfigLoaderImpl) LoadCacheConfig(configData *ConfigData) (*interfaces.CacheConfig, error) {
+	if configData == nil || configData.Data == nil {
+		return nil, fmt.Errorf("config data is nil")
+	}
+
+	cacheData, exists := configData.Data["cache"]
+	if !exists || cacheData == nil {
+		return nil, nil
+	}
+
+	cacheMap, ok := cacheData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cache section must be a map/object")
+	}
+
+	return &interfaces.CacheConfig{
+		Type:     cl.getStringOrDefault(cacheMap, "type", "memory"),
+		RedisURL: cl.getStringOrDefault(cacheMap, "redis_url", ""),
+		Dir:      cl.getStringOrDefault(cacheMap, "dir", ""),
+		TTL:      cl.getStringOrDefault(cacheMap, "ttl", ""),
+		MaxSize:  cl.getIntOrDefault(cacheMap, "max_size", 0),
+	}, nil
+}
+
+// LoadUsageConfig extracts the usage ledger configuration from config data.
+// Returns nil if there is no usage section.
+func (cl *ConfigLoaderImpl) LoadUsageConfig(configData *ConfigData) (*interfaces.UsageConfig, error) {
+	if configData == nil || configData.Data == nil {
+		return nil, fmt.Errorf("config data is nil")
+	}
+
+	usageData, exists := configData.Data["usage"]
+	if !exists || usageData == nil {
+		return nil, nil
+	}
+
+	usageMap, ok := usageData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("usage section must be a map/object")
+	}
+
+	return &interfaces.UsageConfig{
+		Path: cl.getStringOrDefault(usageMap, "path", ""),
+	}, nil
+}
+
+// LoadBudgetConfig extracts the budget configuration from config data.
+// Returns nil if there is no budgets section.
+func (cl *ConfigLoaderImpl) LoadBudgetConfig(configData *ConfigData) (*interfaces.BudgetConfig, error) {
+	if configData == nil || configData.Data == nil {
+		return nil, fmt.Errorf("config data is nil")
+	}
+
+	budgetData, exists := configData.Data["budgets"]
+	if !exists || budgetData == nil {
+		return nil, nil
+	}
+
+	budgetMap, ok := budgetData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("budgets section must be a map/object")
+	}
+
+	config := &interfaces.BudgetConfig{}
+	var err error
+
+	if config.FlowExecution, err = cl.parseBudgetLimit(budgetMap["flow_execution"]); err != nil {
+		return nil, fmt.Errorf("flow_execution: %w", err)
+	}
+	if config.TenantDaily, err = cl.parseBudgetLimit(budgetMap["tenant_daily"]); err != nil {
+		return nil, fmt.Errorf("tenant_daily: %w", err)
+	}
+	if config.Flows, err = cl.parseBudgetLimits(budgetMap["flows"]); err != nil {
+		return nil, fmt.Errorf("flows: %w", err)
+	}
+	if config.Tenants, err = cl.parseBudgetLimits(budgetMap["tenants"]); err != nil {
+		return nil, fmt.Errorf("tenants: %w", err)
+	}
+	if config.Models, err = cl.parseBudgetLimits(budgetMap["models"]); err != nil {
+		return nil, fmt.Errorf("models: %w", err)
+	}
+
+	return config, nil
+}
+
+// parseBudgetLimits parses a map of named budget limits
+func (cl *ConfigLoaderImpl) parseBudgetLimits(data interface{}) (map[string]*interfaces.BudgetLimit, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	limitsMap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be a map/object")
+	}
+
+	limits := make(map[string]*interfaces.BudgetLimit, len(limitsMap))
+	for name, limitData := range limitsMap {
+		limit, err := cl.parseBudgetLimit(limitData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if limit != nil {
+			limits[name] = limit
+		}
+	}
+
+	return limits, nil
+}
+
+// parseBudgetLimit parses a single budget limit
+func (cl *ConfigLoaderImpl) parseBudgetLimit(data interface{}) (*interfaces.BudgetLimit, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	limitMap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("budget limit must be a map/object")
+	}
+
+	limit := &interfaces.BudgetLimit{
+		MaxTokens: cl.getIntOrDefault(limitMap, "max_tokens", 0),
+	}
+
+	if maxCost, ok := limitMap["max_cost"]; ok {
+		switch v := maxCost.(type) {
+		case float64:
+			limit.MaxCost = v
+		case int:
+			limit.MaxCost = float64(v)
+		default:
+			return nil, fmt.Errorf("max_cost must be a number")
+		}
+	}
+
+	if limit.MaxTokens < 0 || limit.MaxCost < 0 {
+		return nil, fmt.Errorf("budget limits cannot be negative")
+	}
+
+	return limit, nil
+}
+
+// LoadTokenizersConfig extracts the tokenizer files and model mapping from config data.
+// Returns nil if there is no tokenizers section.
+func (cl *ConfigLoaderImpl) LoadTokenizersConfig(configData *ConfigData) (*interfaces.TokenizersConfig, error) {
+	if configData == nil || configData.Data == nil {
+		return nil, fmt.Errorf("config data is nil")
+	}
+
+	tokenizersData, exists := configData.Data["tokenizers"]
+	if !exists || tokenizersData == nil {
+		return nil, nil
+	}
+
+	tokenizersMap, ok := tokenizersData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tokenizers section must be a map/object")
+	}
+
+	config := &interfaces.TokenizersConfig{
+		Calibrate: cl.getBoolOrDefault(tokenizersMap, "calibrate", false),
+	}
+
+	filesData, exists := tokenizersMap["files"]
+	if !exists || filesData == nil {
+		return config, nil
+	}
+
+	filesMap, ok := filesData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tokenizers.files must be a map/object")
+	}
+
+	config.Files = make(map[string]*interfaces.TokenizerConfig, len(filesMap))
+	for name, fileData := range filesMap {
+		fileMap, ok := fileData.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tokenizer %s must be a map/object", name)
+		}
+
+		tokenizerConfig := &interfaces.TokenizerConfig{
+			Type:       cl.getStringOrDefault(fileMap, "type", ""),
+			Path:       cl.getStringOrDefault(fileMap, "path", ""),
+			MergesPath: cl.getStringOrDefault(fileMap, "merges_path", ""),
+		}
+
+		if models, exists := fileMap["models"]; exists {
+			list, err := cl.parseStringList(models)
+			if err != nil {
+				return nil, fmt.Errorf("tokenizer %s: models: %w", name, err)
+			}
+			tokenizerConfig.Models = list
+		}
+
+		config.Files[name] = tokenizerConfig
+	}
+
+	return config, nil
+}
+
+// LoadSecurityConfig extracts the security configuration from config data.
+// API keys given as ${ENV_VAR} references are resolved from the environment.
+// Returns nil if there is no security section.
+func (cl *ConfigLoaderImpl) LoadSecurityConfig(configData *ConfigData) (*interfaces.SecurityConfig, error) {
+	if configData == nil || configData.Data == nil {
+		return nil, fmt.Errorf("config data is nil")
+	}
+
+	securityData, exists := configData.Data["security"]
+	if !exists || securityData == nil {
+		return nil, nil
+	}
+
+	securityMap, ok := securityData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("security section must be a map/object")
+	}
+
+	config := &interfaces.SecurityConfig{}
+
+	if keysData, ok := securityMap["api_keys"]; ok {
+		keys, err := cl.parseStringList(keysData)
+		if err != nil {
+			return nil, fmt.Errorf("api_keys must be a list of strings: %w", err)
+		}
+		for _, key := range keys {
+			if strings.HasPrefix(key, "${") && strings.HasSuffix(key, "}") {
+				envVar := key[2 : len(key)-1]
+				key = os.Getenv(envVar)
+				if key == "" {
+					return nil, fmt.Errorf("environment variable %s is not set", envVar)
+				}
+			}
+			config.APIKeys = append(config.APIKeys, key)
+		}
+	}
+
+	if rateData, ok := securityMap["rate_limiting"]; ok {
+		rateMap, ok := rateData.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("rate_limiting section must be a map/object")
+		}
+
+		config.RateLimiting = &interfaces.RateLimitConfig{
+			RequestsPerMinute: cl.getIntOrDefault(rateMap, "requests_per_minute", 0),
+			Burst:             cl.getIntOrDefault(rateMap, "burst", 0),
+		}
+
+		if modelData, ok := rateMap["model_requests_per_minute"]; ok {
+			modelMap, ok := modelData.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("model_requests_per_minute must be a map/object")
+			}
+			config.RateLimiting.ModelLimits = make(map[string]int, len(modelMap))
+			for model := range modelMap {
+				config.RateLimiting.ModelLimits[model] = cl.getIntOrDefault(modelMap, model, 0)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// LoadToolConfigs extracts and loads tool configurations from config data
+func (cl *ConfigLoaderImpl) LoadToolConfigs(configData *ConfigData) (map[string]*interfaces.ToolConfig, error) {
+	if configData == nil || configData.Data == nil {
+		return nil, fmt.Errorf("config data is nil")
+	}
+
+	cl.logger.Debug("Loading tool configurations", "source", configData.Source)
+
+	// Extract tools section from config data
+	toolsData, exists := configData.Data["tools"]
+	if !exists {
+		cl.logger.Debug("No tools section found in configuration")
+		return make(map[string]*interfaces.ToolConfig), nil
+	}
+
+	toolsMap, ok := toolsData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tools section must be a map/object")
+	}
+
+	toolConfigs := make(map[string]*interfaces.ToolConfig)
+	var errors []error
+
+	for name, toolData := range toolsMap {
+		toolConfig, err := cl.parseToolConfig(toolData)
+		if err != nil {
+			cl.logger.Error("Failed to parse tool configuration",
+				"name", name,
+				"error", err)
+			errors = append(errors, fmt.Errorf("failed to parse tool %s: %w", name, err))
+			continue
+		}
+
+		if toolConfig.CustomParams != nil {
+			if err := cl.substituteEnvVarsInMap(toolConfig.CustomParams); err != nil {
+				errors = append(errors, fmt.Errorf("failed to substitute env vars for tool %s: %w", name, err))
+				continue
+			}
+		}
+
+		toolConfigs[name] = toolConfig
+	}
+
+	if len(errors) > 0 {
+		return toolConfigs, fmt.Errorf("some tool configurations failed to load: %v", errors)
+	}
+
+	return toolConfigs, nil
+}
+
+// parseToolConfig parses a single tool configuration
+func (cl *ConfigLoaderImpl) parseToolConfig(data interface{}) (*interfaces.ToolConfig, error) {
+	toolData, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tool configuration must be a map/object")
+	}
+
+	config := &interfaces.ToolConfig{
+		Enabled: cl.getBoolOrDefault(toolData, "enabled", true),
+		Timeout: "30s", // Default value
+	}
+
+	if timeout, ok := toolData["timeout"]; ok {
+		config.Timeout = fmt.Sprintf("%v", timeout)
+	}
+
+	if retryData, ok := toolData["retry"]; ok {
+		retryMap, ok := retryData.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("retry section must be a map/object")
+		}
+
+		config.Retry = &interfaces.RetryConfig{
+			MaxAttempts: cl.getIntOrDefault(retryMap, "max_attempts", 0),
+			Backoff:     cl.getStringOrDefault(retryMap, "backoff", ""),
+			BaseDelay:   cl.getStringOrDefault(retryMap, "base_delay", ""),
+			MaxDelay:    cl.getStringOrDefault(retryMap, "max_delay", ""),
+		}
+	}
+
+	if cacheData, ok := toolData["cache"]; ok {
+		cacheMap, ok := cacheData.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cache section must be a map/object")
+		}
+
+		config.Cache = &interfaces.ToolCacheConfig{
+			TTL:     cl.getStringOrDefault(cacheMap, "ttl", ""),
+			MaxSize: cl.getIntOrDefault(cacheMap, "max_size", 0),
+		}
+	}
+
+	if dependencies, ok := toolData["dependencies"].([]interface{}); ok {
+		for _, dependency := range dependencies {
+			config.Dependencies = append(config.Dependencies, fmt.Sprintf("%v", dependency))
+		}
+	}
+
+	if customParams, ok := toolData["custom_params"]; ok {
+		customMap, ok := customParams.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("custom_params must be a map/object")
+		}
+		config.CustomParams = customMap
+	}
+
+	return config, nil
+}
+
+// getIntOrDefault gets an integer value from map with default
+func (cl *ConfigLoaderImpl) getIntOrDefault(m map[string]interface{}, key string, defaultValue int) int {
+	switch v := m[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return defaultValue
+}
+
+// getStringOrDefault gets a value from map as string with default
+func (cl *ConfigLoaderImpl) getStringOrDefault(m map[string]interface{}, key string, defaultValue string) string {
+	if value, ok := m[key]; ok && value != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return defaultValue
+}
+
 // getBoolOrDefault gets a boolean value from map with default
 func (cl *ConfigLoaderImpl) getBoolOrDefault(m map[string]interface{}, key string, defaultValue bool) bool {
 	if value, ok := m[key]; ok {
@@ -523,6 +1013,10 @@ func (cl *ConfigLoaderImpl) modelConfigToMap(config *interfaces.ModelConfig) map
 		result["base_url"] = config.BaseURL
 	}
 
+	if len(config.Middleware) > 0 {
+		result["middleware"] = config.Middleware
+	}
+
 	if config.CustomParams != nil {
 		result["custom_params"] = config.CustomParams
 	}
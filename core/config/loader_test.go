@@ -376,3 +376,254 @@ test:
 		t.Errorf("Expected 42, got %v", test["number"])
 	}
 }
+
+func TestConfigLoader_LoadToolConfigs(t *testing.T) {
+	yamlContent := `
+tools:
+  article_importer:
+    timeout: 30s
+    retry:
+      max_attempts: 3
+      backoff: "exponential"
+      base_delay: 1s
+    cache:
+      ttl: 300s
+      max_size: 1000
+    dependencies: ["s3"]
+    custom_params:
+      type: "s3_tools"
+  disabled_tool:
+    enabled: false
+`
+
+	loader := NewConfigLoader(&MockLogger{})
+	configData, err := loader.LoadFromBytes([]byte(yamlContent), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	toolConfigs, err := loader.LoadToolConfigs(configData)
+	if err != nil {
+		t.Fatalf("Failed to load tool configs: %v", err)
+	}
+
+	importer := toolConfigs["article_importer"]
+	if importer == nil {
+		t.Fatal("Expected article_importer config")
+	}
+	if !importer.Enabled {
+		t.Error("Expected tools to be enabled by default")
+	}
+	if importer.Timeout != "30s" {
+		t.Errorf("Expected timeout '30s', got '%s'", importer.Timeout)
+	}
+	if importer.Retry == nil || importer.Retry.MaxAttempts != 3 || importer.Retry.BaseDelay != "1s" {
+		t.Errorf("Unexpected retry config: %+v", importer.Retry)
+	}
+	if importer.Cache == nil || importer.Cache.MaxSize != 1000 {
+		t.Errorf("Unexpected cache config: %+v", importer.Cache)
+	}
+	if len(importer.Dependencies) != 1 || importer.Dependencies[0] != "s3" {
+		t.Errorf("Unexpected dependencies: %v", importer.Dependencies)
+	}
+	if importer.CustomParams["type"] != "s3_tools" {
+		t.Errorf("Unexpected custom params: %v", importer.CustomParams)
+	}
+
+	if toolConfigs["disabled_tool"] == nil || toolConfigs["disabled_tool"].Enabled {
+		t.Error("Expected disabled_tool to be loaded as disabled")
+	}
+}
+
+func TestConfigLoader_LoadModelConfigs_Middleware(t *testing.T) {
+	yamlContent := `
+models:
+  chat:
+    provider: "deepseek"
+    model_name: "deepseek-chat"
+    api_key: "test-key"
+    middleware: ["logging", "redact"]
+  invalid:
+    provider: "deepseek"
+    model_name: "deepseek-chat"
+    api_key: "test-key"
+    middleware: "logging"
+`
+
+	loader := NewConfigLoader(&MockLogger{})
+	configData, err := loader.LoadFromBytes([]byte(yamlContent), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if _, err := loader.LoadModelConfigs(configData); err == nil {
+		t.Error("Expected error for middleware that is not a list")
+	}
+
+	delete(configData.Data["models"].(map[string]interface{}), "invalid")
+	modelConfigs, err := loader.LoadModelConfigs(configData)
+	if err != nil {
+		t.Fatalf("Failed to load model configs: %v", err)
+	}
+
+	middleware := modelConfigs["chat"].Middleware
+	if len(middleware) != 2 || middleware[0] != "logging" || middleware[1] != "redact" {
+		t.Errorf("Expected middleware [logging redact], got %v", middleware)
+	}
+}
+
+func TestConfigLoader_LoadModelConfigs_Resilience(t *testing.T) {
+	yamlContent := `
+models:
+  glm:
+    provider: "zai"
+    model_name: "glm-4.6"
+    api_key: "test-key"
+    retry:
+      max_attempts: 4
+      backoff: "exponential"
+      base_delay: 2s
+      max_delay: 1m
+    circuit_breaker:
+      max_failures: 5
+      reset_timeout: 30s
+`
+
+	loader := NewConfigLoader(&MockLogger{})
+	configData, err := loader.LoadFromBytes([]byte(yamlContent), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	modelConfigs, err := loader.LoadModelConfigs(configData)
+	if err != nil {
+		t.Fatalf("Failed to load model configs: %v", err)
+	}
+
+	glm := modelConfigs["glm"]
+	if glm.Retry == nil || glm.Retry.MaxAttempts != 4 || glm.Retry.Backoff != "exponential" || glm.Retry.MaxDelay != "1m" {
+		t.Errorf("Unexpected retry config: %+v", glm.Retry)
+	}
+	if glm.CircuitBreaker == nil || glm.CircuitBreaker.MaxFailures != 5 || glm.CircuitBreaker.ResetTimeout != "30s" {
+		t.Errorf("Unexpected circuit breaker config: %+v", glm.CircuitBreaker)
+	}
+}
+
+func TestConfigLoader_LoadModelConfigs_FakeScript(t *testing.T) {
+	yamlContent := `
+models:
+  offline:
+    provider: "fake"
+    model_name: "fake-model"
+    custom_params:
+      script:
+        - match: "article"
+          tool_calls:
+            - name: get_article
+              args: {id: 42}
+        - text: "done"
+  cloud:
+    provider: "deepseek"
+    model_name: "deepseek-chat"
+`
+
+	loader := NewConfigLoader(&MockLogger{})
+	configData, err := loader.LoadFromBytes([]byte(yamlContent), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Cloud providers still need a key
+	if _, err := loader.LoadModelConfigs(configData); err == nil {
+		t.Error("Expected error for deepseek model without api_key")
+	}
+
+	delete(configData.Data["models"].(map[string]interface{}), "cloud")
+	modelConfigs, err := loader.LoadModelConfigs(configData)
+	if err != nil {
+		t.Fatalf("Failed to load model configs: %v", err)
+	}
+
+	script, ok := modelConfigs["offline"].CustomParams["script"].([]interface{})
+	if !ok || len(script) != 2 {
+		t.Errorf("Expected script with 2 steps, got %v", modelConfigs["offline"].CustomParams["script"])
+	}
+}
+
+func TestConfigLoader_LoadSecurityConfig(t *testing.T) {
+	os.Setenv("TEST_PONCHO_API_KEY", "env-key")
+	defer os.Unsetenv("TEST_PONCHO_API_KEY")
+
+	yamlContent := `
+security:
+  api_keys: ["static-key", "${TEST_PONCHO_API_KEY}"]
+  rate_limiting:
+    requests_per_minute: 60
+    burst: 10
+    model_requests_per_minute:
+      glm-vision: 5
+`
+
+	loader := NewConfigLoader(&MockLogger{})
+	configData, err := loader.LoadFromBytes([]byte(yamlContent), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	securityConfig, err := loader.LoadSecurityConfig(configData)
+	if err != nil {
+		t.Fatalf("Failed to load security config: %v", err)
+	}
+
+	if len(securityConfig.APIKeys) != 2 || securityConfig.APIKeys[1] != "env-key" {
+		t.Errorf("Expected API keys [static-key env-key], got %v", securityConfig.APIKeys)
+	}
+
+	rateLimiting := securityConfig.RateLimiting
+	if rateLimiting == nil || rateLimiting.RequestsPerMinute != 60 || rateLimiting.Burst != 10 || rateLimiting.ModelLimits["glm-vision"] != 5 {
+		t.Errorf("Unexpected rate limiting config: %+v", rateLimiting)
+	}
+
+	os.Unsetenv("TEST_PONCHO_API_KEY")
+	if _, err := loader.LoadSecurityConfig(configData); err == nil {
+		t.Error("Expected error for unset API key environment variable")
+	}
+}
+
+func TestConfigLoader_LoadBudgetConfig(t *testing.T) {
+	yamlContent := `
+budgets:
+  flow_execution: {max_tokens: 50000, max_cost: 0.5}
+  tenant_daily: {max_cost: 20}
+  tenants:
+    acme: {max_cost: 100}
+  models:
+    glm-4.6v: {max_tokens: 1000000}
+`
+
+	loader := NewConfigLoader(&MockLogger{})
+	configData, err := loader.LoadFromBytes([]byte(yamlContent), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	budgets, err := loader.LoadBudgetConfig(configData)
+	if err != nil {
+		t.Fatalf("Failed to load budget config: %v", err)
+	}
+
+	if budgets.FlowExecution.MaxTokens != 50000 || budgets.FlowExecution.MaxCost != 0.5 {
+		t.Errorf("Unexpected flow execution budget: %+v", budgets.FlowExecution)
+	}
+	if budgets.TenantDaily.MaxCost != 20 || budgets.Tenants["acme"].MaxCost != 100 {
+		t.Errorf("Unexpected tenant budgets: %+v, %+v", budgets.TenantDaily, budgets.Tenants)
+	}
+	if budgets.Models["glm-4.6v"].MaxTokens != 1000000 {
+		t.Errorf("Unexpected model budgets: %+v", budgets.Models)
+	}
+
+	configData.Data["budgets"].(map[string]interface{})["tenant_daily"] = map[string]interface{}{"max_cost": "a lot"}
+	if _, err := loader.LoadBudgetConfig(configData); err == nil {
+		t.Error("Expected error for non-numeric max_cost")
+	}
+}
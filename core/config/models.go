@@ -11,6 +11,7 @@ package config
 // It enables type-safe configuration management throughout the framework.
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -192,7 +193,7 @@ func (mcv *ModelConfigValidator) validateProvider(config *interfaces.ModelConfig
 	}
 	
 	// Check if provider is supported
-	supportedProviders := []string{"deepseek", "zai", "openai"}
+	supportedProviders := []string{"deepseek", "zai", "openai", "ollama", "anthropic", "gemini", "fake"}
 	for _, provider := range supportedProviders {
 		if config.Provider == provider {
 			return nil
@@ -225,7 +226,7 @@ func (mcv *ModelConfigValidator) validateModelName(config *interfaces.ModelConfi
 // validateAPIKey validates the api_key field
 func (mcv *ModelConfigValidator) validateAPIKey(config *interfaces.ModelConfig, rule ModelValidationRule) error {
 	if config.APIKey == "" {
-		if rule.Required {
+		if rule.Required && providerRequiresAPIKey(config.Provider, config.BaseURL) {
 			return fmt.Errorf("api_key is required")
 		}
 		return nil
@@ -325,11 +326,80 @@ func (mcv *ModelConfigValidator) validateProviderSpecific(config *interfaces.Mod
 		return mcv.validateZAIConfig(config)
 	case "openai":
 		return mcv.validateOpenAIConfig(config)
+	case "ollama":
+		return mcv.validateOllamaConfig(config)
+	case "anthropic":
+		return mcv.validateAnthropicConfig(config)
+	case "gemini":
+		return mcv.validateGeminiConfig(config)
 	default:
 		return nil // No specific validation for unknown providers
 	}
 }
 
+// validateOllamaConfig validates Ollama-specific configuration
+func (mcv *ModelConfigValidator) validateOllamaConfig(config *interfaces.ModelConfig) error {
+	// Ollama-specific validations
+	if config.BaseURL != "" {
+		// Validate URL format if provided
+		if !mcv.isValidURL(config.BaseURL) {
+			return fmt.Errorf("invalid base_url format")
+		}
+	}
+
+	return nil
+}
+
+// validateAnthropicConfig validates Anthropic-specific configuration
+func (mcv *ModelConfigValidator) validateAnthropicConfig(config *interfaces.ModelConfig) error {
+	// Anthropic-specific validations
+	if config.BaseURL != "" {
+		// Validate URL format if provided
+		if !mcv.isValidURL(config.BaseURL) {
+			return fmt.Errorf("invalid base_url format")
+		}
+	}
+
+	if config.Temperature > 1.0 {
+		return fmt.Errorf("anthropic temperature must be between 0.0 and 1.0")
+	}
+
+	return nil
+}
+
+// validateGeminiConfig validates Gemini-specific configuration
+func (mcv *ModelConfigValidator) validateGeminiConfig(config *interfaces.ModelConfig) error {
+	// Gemini-specific validations
+	if config.BaseURL != "" {
+		// Validate URL format if provided
+		if !mcv.isValidURL(config.BaseURL) {
+			return fmt.Errorf("invalid base_url format")
+		}
+	}
+
+	if schema, ok := config.CustomParams["response_schema"]; ok {
+		if _, isMap := schema.(map[string]interface{}); !isMap {
+			return fmt.Errorf("gemini response_schema must be an object")
+		}
+	}
+
+	return nil
+}
+
+// providerRequiresAPIKey reports whether a model config needs an api_key.
+// Local Ollama, self-hosted OpenAI-compatible servers and the scripted fake
+// provider run without authentication.
+func providerRequiresAPIKey(provider, baseURL string) bool {
+	switch provider {
+	case "ollama", "fake":
+		return false
+	case "openai":
+		return baseURL == ""
+	default:
+		return true
+	}
+}
+
 // validateDeepSeekConfig validates DeepSeek-specific configuration
 func (mcv *ModelConfigValidator) validateDeepSeekConfig(config *interfaces.ModelConfig) error {
 	// DeepSeek-specific validations
@@ -485,6 +555,9 @@ func (mi *ModelInitializer) InitializeModels(configs map[string]*interfaces.Mode
 	return models, nil
 }
 
+// healthCheckTimeout bounds the provider check of a single model or tool
+const healthCheckTimeout = 5 * time.Second
+
 // ModelHealthChecker checks health of model instances
 type ModelHealthChecker struct {
 	models map[string]interfaces.PonchoModel
@@ -538,11 +611,13 @@ func (mhc *ModelHealthChecker) checkModelHealth(model interfaces.PonchoModel) er
 		return fmt.Errorf("model provider is empty")
 	}
 	
-	// Could add more sophisticated health checks here:
-	// - Test API connectivity
-	// - Validate API key
-	// - Check model availability
-	
+	// Provider-specific checks (API key, connectivity, ...)
+	if checker, ok := model.(interfaces.HealthChecker); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		defer cancel()
+		return checker.HealthCheck(ctx)
+	}
+
 	return nil
 }
 
@@ -11,6 +11,7 @@ package config
 // It abstracts tool creation complexity from the main framework.
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -457,10 +458,12 @@ func (thc *ToolHealthChecker) checkToolHealth(tool interfaces.PonchoTool) error
 		return fmt.Errorf("tool category is empty")
 	}
 
-	// Could add more sophisticated health checks here:
-	// - Test external service connectivity
-	// - Validate API keys
-	// - Check tool-specific dependencies
+	// Tool-specific checks (connectivity, credentials, ...)
+	if checker, ok := tool.(interfaces.HealthChecker); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		defer cancel()
+		return checker.HealthCheck(ctx)
+	}
 
 	return nil
 }
@@ -46,6 +46,9 @@ type ConfigValidationRule struct {
 	Enum        []interface{}
 	CustomFunc  func(interface{}) error
 	Description string
+	// RequiredIf для wildcard-правил с Required: поле обязательно только для
+	// элементов, для которых функция вернула true (nil - для всех)
+	RequiredIf func(item map[string]interface{}) bool
 }
 
 // ValidationType тип валидации
@@ -226,7 +229,7 @@ func (cv *ConfigValidatorImpl) validateWildcardRule(config *ConfigData, rule Con
 			targetValue, exists = cv.getValueByPath(itemMap, fieldName)
 			if !exists || targetValue == nil {
 				// Если поле обязательное, добавляем ошибку
-				if rule.Required {
+				if rule.Required && (rule.RequiredIf == nil || rule.RequiredIf(itemMap)) {
 					errors = append(errors, fmt.Errorf("required field '%s.%s.%s' is missing", sectionName, key, fieldName))
 				}
 				continue // Поле не существует в этом элементе
@@ -521,7 +524,7 @@ func (cv *ConfigValidatorImpl) addDefaultRules() {
 		Path:     "models.*.provider",
 		Required: true,
 		Type:     TypeString,
-		Enum:     []interface{}{"deepseek", "zai", "openai"},
+		Enum:     []interface{}{"deepseek", "zai", "openai", "ollama", "anthropic", "gemini", "fake"},
 	})
 
 	cv.AddRule(ConfigValidationRule{
@@ -547,6 +550,12 @@ func (cv *ConfigValidatorImpl) addDefaultRules() {
 		Path:     "models.*.api_key",
 		Required: true,
 		Type:     TypeString,
+		// Local and self-hosted servers usually run without authentication
+		RequiredIf: func(model map[string]interface{}) bool {
+			provider, _ := model["provider"].(string)
+			baseURL, _ := model["base_url"].(string)
+			return providerRequiresAPIKey(provider, baseURL)
+		},
 		CustomFunc: func(value interface{}) error {
 			if apiKey, ok := value.(string); ok {
 				// Check if it's an environment variable reference
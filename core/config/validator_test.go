@@ -83,6 +83,45 @@ func TestConfigValidator_Validate_MissingRequiredField(t *testing.T) {
 	}
 }
 
+func TestConfigValidator_Validate_SelfHostedOpenAIWithoutAPIKey(t *testing.T) {
+	logger := &MockLogger{}
+	validator := NewConfigValidator(logger)
+
+	model := map[string]interface{}{
+		"provider":    "openai",
+		"model_name":  "qwen2.5-7b-instruct",
+		"base_url":    "http://localhost:8000/v1",
+		"max_tokens":  4000,
+		"temperature": 0.7,
+		"timeout":     "30s",
+	}
+	configData := &ConfigData{
+		Format: "yaml",
+		Data: map[string]interface{}{
+			"models": map[string]interface{}{"local": model},
+		},
+	}
+
+	// Self-hosted servers run without authentication
+	if err := validator.Validate(configData); err != nil {
+		t.Errorf("Expected self-hosted model without api_key to pass validation, got error: %v", err)
+	}
+
+	// The OpenAI API itself still requires a key
+	delete(model, "base_url")
+	err := validator.Validate(configData)
+	if err == nil || !containsString(err.Error(), "required field 'models.local.api_key' is missing") {
+		t.Errorf("Expected missing api_key error, got %v", err)
+	}
+
+	// Local Ollama never needs a key
+	model["provider"] = "ollama"
+	model["model_name"] = "llava"
+	if err := validator.Validate(configData); err != nil {
+		t.Errorf("Expected ollama model without api_key to pass validation, got error: %v", err)
+	}
+}
+
 func TestConfigValidator_Validate_InvalidEnumValue(t *testing.T) {
 	logger := &MockLogger{}
 	validator := NewConfigValidator(logger)
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/config"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// RegisterEmbeddingModel registers an embedding model with the framework
+func (pf *PonchoFrameworkImpl) RegisterEmbeddingModel(name string, model interfaces.PonchoEmbeddingModel) error {
+	if err := pf.embeddingRegistry.Register(name, model); err != nil {
+		pf.logger.Error("Failed to register embedding model", "name", name, "error", err)
+		return err
+	}
+
+	pf.logger.Info("Embedding model registered", "name", name, "provider", model.Provider())
+	return nil
+}
+
+// GetEmbeddingModelRegistry returns the embedding model registry
+func (pf *PonchoFrameworkImpl) GetEmbeddingModelRegistry() interfaces.PonchoEmbeddingModelRegistry {
+	return pf.embeddingRegistry
+}
+
+// Embed embeds inputs with the named embedding model and returns one vector per input.
+// Like Generate, the call goes through the model's resilience policy and the budgets,
+// and is recorded in the metrics and the usage ledger.
+func (pf *PonchoFrameworkImpl) Embed(ctx context.Context, modelName string, inputs []string) (vectors [][]float32, usage *interfaces.PonchoUsage, err error) {
+	if !pf.isStarted() {
+		return nil, nil, fmt.Errorf("framework is not started")
+	}
+
+	startTime := time.Now()
+	defer func() {
+		tokens := 0
+		if usage != nil {
+			tokens = usage.TotalTokens
+		}
+		pf.recordGenerationMetrics(modelName, time.Since(startTime).Milliseconds(), tokens, err == nil)
+	}()
+
+	model, release, err := pf.acquireEmbeddingModel(modelName)
+	if err != nil {
+		pf.recordError("embedding", "model_not_found")
+		return nil, nil, fmt.Errorf("embedding model '%s' not found: %w", modelName, err)
+	}
+	defer release()
+
+	handler := func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+		embedded, embeddingUsage, err := model.Embed(ctx, inputs)
+		if err != nil {
+			return nil, err
+		}
+		vectors = embedded
+		return &interfaces.PonchoModelResponse{Usage: embeddingUsage}, nil
+	}
+
+	// Responses are vectors, so the response cache and model middleware do not apply
+	var middleware []*interfaces.Middleware
+	if pf.budgets != nil {
+		middleware = append(middleware, pf.budgets.Middleware())
+	}
+
+	req := embeddingRequest(modelName, inputs)
+	resp, err := interfaces.ChainModel(pf.guardModel(modelName, handler), middleware...)(ctx, req)
+	if err != nil {
+		pf.recordError("embedding", "embedding_failed")
+		return nil, nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	usage = resp.Usage
+
+	pf.recordEmbeddingUsage(req, model, resp)
+
+	pf.logger.Debug("Embedding completed", "model", modelName, "inputs", len(inputs))
+	return vectors, usage, nil
+}
+
+// embeddingRequest expresses an embedding call as a model request with one user
+// message per input, so budgets and the usage ledger can estimate its tokens
+func embeddingRequest(modelName string, inputs []string) *interfaces.PonchoModelRequest {
+	messages := make([]*interfaces.PonchoMessage, len(inputs))
+	for i, input := range inputs {
+		messages[i] = &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: input}},
+		}
+	}
+	return &interfaces.PonchoModelRequest{Model: modelName, Messages: messages}
+}
+
+// embeddingModelConfigs returns the "embedding_models" config section, or nothing
+// when the config manager does not support embedding models
+func (pf *PonchoFrameworkImpl) embeddingModelConfigs() (map[string]*interfaces.ModelConfig, error) {
+	provider, ok := pf.configManager.(config.EmbeddingConfigProvider)
+	if !ok {
+		return map[string]*interfaces.ModelConfig{}, nil
+	}
+
+	modelConfigs, err := provider.GetEmbeddingModelConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding model configurations: %w", err)
+	}
+	if modelConfigs == nil {
+		modelConfigs = map[string]*interfaces.ModelConfig{}
+	}
+	return modelConfigs, nil
+}
+
+// createEmbeddingModel creates an embedding model instance through the model factory manager
+func (pf *PonchoFrameworkImpl) createEmbeddingModel(modelConfig *interfaces.ModelConfig) (interfaces.PonchoEmbeddingModel, error) {
+	factoryManager, ok := pf.GetModelFactoryManager().(interfaces.EmbeddingFactoryManager)
+	if !ok {
+		return nil, fmt.Errorf("no model factory manager available for embedding models")
+	}
+
+	return factoryManager.CreateEmbeddingModel(modelConfig)
+}
+
+// loadAndRegisterEmbeddingModels creates the models of the "embedding_models"
+// config section with the embedding factories of the model factory manager
+func (pf *PonchoFrameworkImpl) loadAndRegisterEmbeddingModels(ctx context.Context) error {
+	modelConfigs, err := pf.embeddingModelConfigs()
+	if err != nil {
+		return err
+	}
+
+	if len(modelConfigs) == 0 {
+		return nil
+	}
+
+	if err := pf.validateResilienceConfig(modelConfigs); err != nil {
+		return fmt.Errorf("invalid resilience configuration: %w", err)
+	}
+
+	for name, modelConfig := range modelConfigs {
+		model, err := pf.createEmbeddingModel(modelConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create embedding model %s: %w", name, err)
+		}
+
+		if err := pf.embeddingRegistry.Register(name, model); err != nil {
+			return fmt.Errorf("failed to register embedding model %s: %w", name, err)
+		}
+		pf.embeddingConfigs[name] = modelConfig
+	}
+
+	pf.logger.Info("Embedding models loaded and registered successfully", "count", len(modelConfigs))
+	return nil
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/core/config"
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+)
+
+// trackedEmbeddingModel is a fake embedding model that records its shutdown
+type trackedEmbeddingModel struct {
+	*fake.FakeEmbeddingModel
+	shutdown atomic.Bool
+}
+
+func (m *trackedEmbeddingModel) Shutdown(ctx context.Context) error {
+	m.shutdown.Store(true)
+	return nil
+}
+
+// fakeEmbeddingFactory creates fake embedding models from configuration
+type fakeEmbeddingFactory struct{}
+
+func (f *fakeEmbeddingFactory) CreateEmbeddingModel(cfg *interfaces.ModelConfig) (interfaces.PonchoEmbeddingModel, error) {
+	model := &trackedEmbeddingModel{FakeEmbeddingModel: fake.NewFakeEmbeddingModel()}
+	configMap := map[string]interface{}{"model_name": cfg.ModelName}
+	for key, value := range cfg.CustomParams {
+		configMap[key] = value
+	}
+	return model, model.Initialize(context.Background(), configMap)
+}
+
+func (f *fakeEmbeddingFactory) ValidateConfig(cfg *interfaces.ModelConfig) error { return nil }
+func (f *fakeEmbeddingFactory) GetProvider() string                              { return "fake" }
+
+func writeEmbeddingConfig(t *testing.T, path string, dimensions int) {
+	t.Helper()
+
+	content := "models: {}\n"
+	if dimensions > 0 {
+		content += fmt.Sprintf(`embedding_models:
+  attributes:
+    provider: fake
+    model_name: fake-embedding
+    custom_params:
+      dimensions: %d
+      max_batch_size: 1
+`, dimensions)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func newEmbeddingFramework(t *testing.T, configPath string) *PonchoFrameworkImpl {
+	t.Helper()
+
+	logger := interfaces.NewNoOpLogger()
+	framework := NewPonchoFramework(nil, logger)
+	framework.SetConfigManager(config.NewConfigManager(config.ConfigOptions{FilePaths: []string{configPath}, Logger: logger}))
+
+	locator := framework.GetServiceLocator()
+	if err := locator.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize service locator: %v", err)
+	}
+	if err := locator.(EmbeddingFactoryRegistrar).RegisterEmbeddingModelFactory("fake", &fakeEmbeddingFactory{}); err != nil {
+		t.Fatalf("Failed to register embedding factory: %v", err)
+	}
+
+	if err := framework.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	t.Cleanup(func() { _ = framework.Stop(context.Background()) })
+
+	return framework
+}
+
+func TestEmbed_ModelsFromConfiguration(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeEmbeddingConfig(t, configPath, 32)
+
+	framework := newEmbeddingFramework(t, configPath)
+
+	model, err := framework.GetEmbeddingModelRegistry().Get("attributes")
+	if err != nil {
+		t.Fatalf("Expected embedding model from configuration: %v", err)
+	}
+	if model.Dimensions() != 32 || model.MaxBatchSize() != 1 {
+		t.Errorf("Dimensions() = %d, MaxBatchSize() = %d, want 32 and 1", model.Dimensions(), model.MaxBatchSize())
+	}
+
+	vectors, usage, err := framework.Embed(context.Background(), "attributes", []string{"хлопок", "вискоза"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(vectors) != 2 || len(vectors[0]) != 32 || usage.PromptTokens != 2 {
+		t.Errorf("unexpected embeddings: %d vectors, usage %+v", len(vectors), usage)
+	}
+	if batches := model.(*trackedEmbeddingModel).Batches(); len(batches) != 2 {
+		t.Errorf("expected one batch per input, got %d", len(batches))
+	}
+
+	if _, _, err := framework.Embed(context.Background(), "missing", []string{"text"}); err == nil {
+		t.Error("Expected an error for an unknown embedding model")
+	}
+}
+
+func TestEmbed_ReloadConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeEmbeddingConfig(t, configPath, 32)
+
+	framework := newEmbeddingFramework(t, configPath)
+	previous, err := framework.GetEmbeddingModelRegistry().Get("attributes")
+	if err != nil {
+		t.Fatalf("Expected embedding model from configuration: %v", err)
+	}
+
+	// A changed configuration swaps the model and shuts the old one down
+	writeEmbeddingConfig(t, configPath, 64)
+	if err := framework.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	current, err := framework.GetEmbeddingModelRegistry().Get("attributes")
+	if err != nil {
+		t.Fatalf("Expected reloaded embedding model: %v", err)
+	}
+	if current == previous || current.Dimensions() != 64 {
+		t.Errorf("Expected a new model with 64 dimensions, got %d", current.Dimensions())
+	}
+	if !previous.(*trackedEmbeddingModel).shutdown.Load() {
+		t.Error("Expected the replaced embedding model to be shut down")
+	}
+
+	// Removing the section unregisters the model
+	writeEmbeddingConfig(t, configPath, 0)
+	if err := framework.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if _, err := framework.GetEmbeddingModelRegistry().Get("attributes"); err == nil {
+		t.Error("Expected removed embedding model to be unregistered")
+	}
+	if !current.(*trackedEmbeddingModel).shutdown.Load() {
+		t.Error("Expected the removed embedding model to be shut down")
+	}
+}
+
+func TestEmbed_RecordsUsageAndBudgets(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "usage.jsonl")
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Usage:   &interfaces.UsageConfig{Path: ledgerPath},
+		Budgets: &interfaces.BudgetConfig{Models: map[string]*interfaces.BudgetLimit{"attributes": {MaxTokens: 2}}},
+	}, interfaces.NewNoOpLogger())
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	model := fake.NewFakeEmbeddingModel()
+	if err := model.Initialize(ctx, map[string]interface{}{"dimensions": 8}); err != nil {
+		t.Fatalf("Failed to initialize embedding model: %v", err)
+	}
+	if err := framework.RegisterEmbeddingModel("attributes", model); err != nil {
+		t.Fatalf("Failed to register embedding model: %v", err)
+	}
+
+	if _, _, err := framework.Embed(ctx, "attributes", []string{"хлопок вискоза", "лён"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	records, err := framework.UsageLedger().Query(usage.Filter{Model: "attributes"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Provider != "fake" || records[0].TotalTokens != 3 {
+		t.Errorf("Expected one record of 3 tokens, got %+v", records)
+	}
+
+	metrics, err := framework.Metrics(ctx)
+	if err != nil {
+		t.Fatalf("Metrics failed: %v", err)
+	}
+	if modelMetrics := metrics.GeneratedRequests.ByModel["attributes"]; modelMetrics == nil || modelMetrics.Requests != 1 || modelMetrics.TotalTokens != 3 {
+		t.Errorf("Expected embedding call in the model metrics, got %+v", modelMetrics)
+	}
+
+	// Unknown models are estimated as free, so only the recorded 3 tokens count
+	_, _, err = framework.Embed(ctx, "attributes", []string{"шерсть"})
+	var modelErr *common.ModelError
+	if !errors.As(err, &modelErr) || modelErr.Code != common.ErrorCodeBudgetExceeded {
+		t.Errorf("Expected a budget error, got %v", err)
+	}
+}
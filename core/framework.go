@@ -10,9 +10,11 @@ package core
 // Key responsibilities:
 // - Registry management for models, tools, and flows with thread-safe operations
 // - Lifecycle management with Start/Stop methods for graceful initialization
-// - Configuration management integration with dynamic loading and validation
+// - Configuration management integration with dynamic loading, validation and hot reload
 // - Metrics collection and monitoring for all framework operations
 // - Request orchestration for model generation, tool execution, and flow processing
+// - Model middleware chains applied to every generation call
+// - Per-model retry and circuit breaker policies around provider calls
 // - Health monitoring and status reporting for system observability
 //
 // This implementation follows the PonchoFramework interface and provides the
@@ -22,14 +24,22 @@ package core
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 
+	"github.com/ilkoid/PonchoAiFramework/core/budget"
+	"github.com/ilkoid/PonchoAiFramework/core/cache"
 	"github.com/ilkoid/PonchoAiFramework/core/config"
 	"github.com/ilkoid/PonchoAiFramework/core/registry"
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
 )
 
+// DefaultReloadTimeout bounds how long a watched configuration change waits for in-flight calls
+const DefaultReloadTimeout = 30 * time.Second
+
 // PonchoFrameworkImpl is the main implementation of PonchoFramework
 type PonchoFrameworkImpl struct {
 	// Core registries
@@ -37,17 +47,53 @@ type PonchoFrameworkImpl struct {
 	toolRegistry  interfaces.PonchoToolRegistry
 	flowRegistry  interfaces.PonchoFlowRegistry
 
+	// Embedding models (see embedding.go)
+	embeddingRegistry interfaces.PonchoEmbeddingModelRegistry
+
 	// Configuration and state
-	config           *interfaces.PonchoFrameworkConfig
-	configManager    config.ConfigManager
-	logger           interfaces.Logger
-	serviceLocator   ServiceLocator
+	config         *interfaces.PonchoFrameworkConfig
+	configManager  config.ConfigManager
+	logger         interfaces.Logger
+	serviceLocator ServiceLocator
 
 	// Runtime state
 	started   bool
 	mutex     sync.RWMutex
 	startTime time.Time
 
+	// Hot reload state (see reload.go)
+	modelConfigs     map[string]*interfaces.ModelConfig
+	toolConfigs      map[string]*interfaces.ToolConfig
+	embeddingConfigs map[string]*interfaces.ModelConfig
+	inflight         *inflightTracker
+	swapMutex        sync.RWMutex
+	reloadMutex      sync.Mutex
+
+	// Configuration last applied by a reload, to skip the watcher notification of
+	// a reload already applied by ReloadConfig
+	appliedConfig *config.ConfigData
+
+	// Component health probes (see health.go)
+	health *healthProber
+
+	// Model middleware (see middleware.go)
+	middleware *middlewareSet
+
+	// Retry and circuit breaker guards (see resilience.go)
+	resilience *resilienceSet
+
+	// Response cache, nil when caching is not configured
+	responseCache *cache.ResponseCache
+
+	// Usage ledger, nil when usage recording is not configured (see ledger.go)
+	usageLedger *usage.Ledger
+
+	// Spend budgets, nil when budgets are not configured (see ledger.go)
+	budgets *budget.Enforcer
+
+	// Token calibrator, nil when calibration is not configured (see tokenizers.go)
+	tokenCalibrator *common.TokenCalibrator
+
 	// Metrics
 	metrics *interfaces.PonchoMetrics
 }
@@ -68,22 +114,34 @@ func NewPonchoFramework(cfg *interfaces.PonchoFrameworkConfig, logger interfaces
 	serviceLocator := NewServiceLocator(logger)
 
 	return &PonchoFrameworkImpl{
-		modelRegistry: registry.NewPonchoModelRegistry(logger),
-		toolRegistry:  registry.NewPonchoToolRegistry(logger),
-		flowRegistry:  registry.NewPonchoFlowRegistry(logger),
-		config:        cfg,
-		configManager:  configManager,
-		logger:        logger,
-		serviceLocator: serviceLocator,
+		modelRegistry:     registry.NewPonchoModelRegistry(logger),
+		toolRegistry:      registry.NewPonchoToolRegistry(logger),
+		flowRegistry:      registry.NewPonchoFlowRegistry(logger),
+		embeddingRegistry: registry.NewPonchoEmbeddingModelRegistry(logger),
+		config:            cfg,
+		configManager:     configManager,
+		logger:            logger,
+		serviceLocator:    serviceLocator,
+		modelConfigs:      make(map[string]*interfaces.ModelConfig),
+		toolConfigs:       make(map[string]*interfaces.ToolConfig),
+		embeddingConfigs:  make(map[string]*interfaces.ModelConfig),
+		inflight:          newInflightTracker(),
+		health:            newHealthProber(DefaultHealthOptions()),
+		middleware:        newMiddlewareSet(logger),
+		resilience:        newResilienceSet(),
 		metrics: &interfaces.PonchoMetrics{
 			GeneratedRequests: &interfaces.GenerationMetrics{
 				ByModel: make(map[string]*interfaces.ModelMetrics),
 			},
 			ToolExecutions: &interfaces.ToolMetrics{
-				ByTool: make(map[string]int64),
+				ByTool:        make(map[string]int64),
+				ErrorsByTool:  make(map[string]int64),
+				LatencyByTool: make(map[string]*interfaces.HistogramMetrics),
 			},
 			FlowExecutions: &interfaces.FlowMetrics{
-				ByFlow: make(map[string]int64),
+				ByFlow:        make(map[string]int64),
+				ErrorsByFlow:  make(map[string]int64),
+				LatencyByFlow: make(map[string]*interfaces.HistogramMetrics),
 			},
 			Errors: &interfaces.ErrorMetrics{
 				ByType:       make(map[string]int64),
@@ -140,14 +198,69 @@ func (pf *PonchoFrameworkImpl) Start(ctx context.Context) error {
 			return fmt.Errorf("failed to register factories: %w", err)
 		}
 
+		// Validate middleware referenced from configuration
+		modelConfigs, err := pf.configManager.GetModelConfigs()
+		if err != nil {
+			pf.logger.Error("Failed to get model configurations", "error", err)
+			return fmt.Errorf("failed to get model configurations: %w", err)
+		}
+		if err := pf.validateMiddlewareConfig(modelConfigs); err != nil {
+			pf.logger.Error("Invalid middleware configuration", "error", err)
+			return fmt.Errorf("invalid middleware configuration: %w", err)
+		}
+
+		// Validate retry and circuit breaker policies, including models configured in code
+		if err := pf.validateResilienceConfig(modelConfigs, pf.config.Models); err != nil {
+			pf.logger.Error("Invalid resilience configuration", "error", err)
+			return fmt.Errorf("invalid resilience configuration: %w", err)
+		}
+
 		// Load and register models from configuration
 		if err := pf.loadAndRegisterModels(ctx); err != nil {
 			pf.logger.Error("Failed to load and register models", "error", err)
 			return fmt.Errorf("failed to load and register models: %w", err)
 		}
 
-		// TODO: Load and register tools from configuration
+		// Load and register embedding models from configuration
+		if err := pf.loadAndRegisterEmbeddingModels(ctx); err != nil {
+			pf.logger.Error("Failed to load and register embedding models", "error", err)
+			return fmt.Errorf("failed to load and register embedding models: %w", err)
+		}
+
+		// Load and register tools from configuration
+		if err := pf.loadAndRegisterTools(ctx); err != nil {
+			pf.logger.Error("Failed to load and register tools", "error", err)
+			return fmt.Errorf("failed to load and register tools: %w", err)
+		}
+
 		// TODO: Load and register flows from configuration
+	} else if err := pf.validateResilienceConfig(pf.config.Models); err != nil {
+		pf.logger.Error("Invalid resilience configuration", "error", err)
+		return fmt.Errorf("invalid resilience configuration: %w", err)
+	}
+
+	// Create response cache from configuration
+	if err := pf.initResponseCache(); err != nil {
+		pf.logger.Error("Failed to create response cache", "error", err)
+		return fmt.Errorf("failed to create response cache: %w", err)
+	}
+
+	// Load tokenizer files from configuration
+	if err := pf.initTokenizers(); err != nil {
+		pf.logger.Error("Failed to load tokenizers", "error", err)
+		return fmt.Errorf("failed to load tokenizers: %w", err)
+	}
+
+	// Open usage ledger from configuration
+	if err := pf.initUsageLedger(); err != nil {
+		pf.logger.Error("Failed to open usage ledger", "error", err)
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+
+	// Create spend budgets from configuration
+	if err := pf.initBudgets(); err != nil {
+		pf.logger.Error("Failed to create budgets", "error", err)
+		return fmt.Errorf("failed to create budgets: %w", err)
 	}
 
 	pf.started = true
@@ -168,6 +281,11 @@ func (pf *PonchoFrameworkImpl) Stop(ctx context.Context) error {
 
 	pf.logger.Info("Stopping PonchoFramework")
 
+	// Stop watching configuration files
+	if pf.configManager != nil {
+		pf.configManager.StopWatching()
+	}
+
 	// Shutdown service locator
 	if pf.serviceLocator != nil {
 		pf.logger.Info("Shutting down service locator")
@@ -177,6 +295,15 @@ func (pf *PonchoFrameworkImpl) Stop(ctx context.Context) error {
 		}
 	}
 
+	pf.logTokenCalibration()
+
+	if pf.usageLedger != nil {
+		if err := pf.usageLedger.Close(); err != nil {
+			pf.logger.Error("Failed to close usage ledger", "error", err)
+		}
+		pf.usageLedger = nil
+	}
+
 	// TODO: Shutdown components gracefully
 	// This will be implemented in future phases
 
@@ -193,9 +320,13 @@ func (pf *PonchoFrameworkImpl) RegisterModel(name string, model interfaces.Ponch
 		return err
 	}
 
+	if composite, ok := model.(dispatchingModel); ok {
+		composite.SetDispatcher(pf)
+	}
+
 	// Initialize the model with framework configuration
-	if pf.config != nil && pf.config.Models != nil {
-		if modelConfig, exists := pf.config.Models[name]; exists {
+	if cfg := pf.GetConfig(); cfg != nil && cfg.Models != nil {
+		if modelConfig, exists := cfg.Models[name]; exists {
 			if err := model.Initialize(context.Background(), map[string]interface{}{
 				"provider":      modelConfig.Provider,
 				"model_name":    modelConfig.ModelName,
@@ -225,8 +356,8 @@ func (pf *PonchoFrameworkImpl) RegisterTool(name string, tool interfaces.PonchoT
 	}
 
 	// Initialize the tool with framework configuration
-	if pf.config != nil && pf.config.Tools != nil {
-		if toolConfig, exists := pf.config.Tools[name]; exists {
+	if cfg := pf.GetConfig(); cfg != nil && cfg.Tools != nil {
+		if toolConfig, exists := cfg.Tools[name]; exists {
 			if err := tool.Initialize(context.Background(), map[string]interface{}{
 				"enabled":       toolConfig.Enabled,
 				"timeout":       toolConfig.Timeout,
@@ -253,8 +384,8 @@ func (pf *PonchoFrameworkImpl) RegisterFlow(name string, flow interfaces.PonchoF
 	}
 
 	// Initialize the flow with framework configuration
-	if pf.config != nil && pf.config.Flows != nil {
-		if flowConfig, exists := pf.config.Flows[name]; exists {
+	if cfg := pf.GetConfig(); cfg != nil && cfg.Flows != nil {
+		if flowConfig, exists := cfg.Flows[name]; exists {
 			if err := flow.Initialize(context.Background(), map[string]interface{}{
 				"enabled":       flowConfig.Enabled,
 				"timeout":       flowConfig.Timeout,
@@ -273,7 +404,7 @@ func (pf *PonchoFrameworkImpl) RegisterFlow(name string, flow interfaces.PonchoF
 }
 
 // Generate generates a response using a model
-func (pf *PonchoFrameworkImpl) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+func (pf *PonchoFrameworkImpl) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (response *interfaces.PonchoModelResponse, err error) {
 	if !pf.isStarted() {
 		return nil, fmt.Errorf("framework is not started")
 	}
@@ -281,58 +412,84 @@ func (pf *PonchoFrameworkImpl) Generate(ctx context.Context, req *interfaces.Pon
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime).Milliseconds()
-		pf.recordGenerationMetrics(req.Model, duration, 0, true)
+		pf.recordGenerationMetrics(req.Model, duration, responseTokens(response), err == nil)
 	}()
 
 	pf.logger.Debug("Generating response", "model", req.Model)
 
-	model, err := pf.modelRegistry.Get(req.Model)
+	model, release, err := pf.acquireModel(req.Model)
 	if err != nil {
 		pf.recordError("model", "model_not_found")
 		return nil, fmt.Errorf("model '%s' not found: %w", req.Model, err)
 	}
+	defer release()
 
-	response, err := model.Generate(ctx, req)
+	middleware, err := pf.modelMiddleware(req.Model, model)
+	if err != nil {
+		pf.recordError("model", "middleware_failed")
+		return nil, err
+	}
+
+	response, err = interfaces.ChainModel(pf.guardModel(req.Model, model.Generate), middleware...)(ctx, req)
 	if err != nil {
 		pf.recordError("model", "generation_failed")
 		return nil, fmt.Errorf("generation failed: %w", err)
 	}
 
-	pf.logger.Debug("Generation completed", "model", req.Model, "tokens", response.Usage.TotalTokens)
+	pf.recordUsage(req, model, response, false)
+
+	pf.logger.Debug("Generation completed", "model", req.Model, "tokens", responseTokens(response))
 	return response, nil
 }
 
 // GenerateStreaming generates a streaming response using a model
-func (pf *PonchoFrameworkImpl) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+func (pf *PonchoFrameworkImpl) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) (err error) {
 	if !pf.isStarted() {
 		return fmt.Errorf("framework is not started")
 	}
 
+	stream := common.NewStreamAccumulator()
+	startTime := time.Now()
+	defer func() {
+		duration := time.Since(startTime).Milliseconds()
+		pf.recordGenerationMetrics(req.Model, duration, responseTokens(stream.Response()), err == nil)
+	}()
+
 	pf.logger.Debug("Starting streaming generation", "model", req.Model)
 
-	model, err := pf.modelRegistry.Get(req.Model)
+	model, release, err := pf.acquireModel(req.Model)
 	if err != nil {
 		pf.recordError("model", "model_not_found")
 		return fmt.Errorf("model '%s' not found: %w", req.Model, err)
 	}
+	defer release()
 
 	if !model.SupportsStreaming() {
 		pf.recordError("model", "streaming_not_supported")
 		return fmt.Errorf("model '%s' does not support streaming", req.Model)
 	}
 
-	err = model.GenerateStreaming(ctx, req, callback)
+	middleware, err := pf.modelMiddleware(req.Model, model)
+	if err != nil {
+		pf.recordError("model", "middleware_failed")
+		return err
+	}
+
+	err = interfaces.ChainStream(pf.guardStream(req.Model, model.GenerateStreaming), middleware...)(ctx, req, stream.Callback(callback))
 	if err != nil {
 		pf.recordError("model", "streaming_failed")
+		pf.recordPartialUsage(req, model, stream.Response())
 		return fmt.Errorf("streaming generation failed: %w", err)
 	}
 
+	pf.recordUsage(req, model, stream.Response(), true)
+
 	pf.logger.Debug("Streaming generation completed", "model", req.Model)
 	return nil
 }
 
 // ExecuteTool executes a tool
-func (pf *PonchoFrameworkImpl) ExecuteTool(ctx context.Context, toolName string, input interface{}) (interface{}, error) {
+func (pf *PonchoFrameworkImpl) ExecuteTool(ctx context.Context, toolName string, input interface{}) (result interface{}, err error) {
 	if !pf.isStarted() {
 		return nil, fmt.Errorf("framework is not started")
 	}
@@ -340,24 +497,25 @@ func (pf *PonchoFrameworkImpl) ExecuteTool(ctx context.Context, toolName string,
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime).Milliseconds()
-		pf.recordToolExecutionMetrics(toolName, duration, true)
+		pf.recordToolExecutionMetrics(toolName, duration, err == nil)
 	}()
 
 	pf.logger.Debug("Executing tool", "name", toolName)
 
-	tool, err := pf.toolRegistry.Get(toolName)
+	tool, release, err := pf.acquireTool(toolName)
 	if err != nil {
 		pf.recordError("tool", "tool_not_found")
 		return nil, fmt.Errorf("tool '%s' not found: %w", toolName, err)
 	}
+	defer release()
 
 	// Validate input
 	if err := tool.Validate(input); err != nil {
 		pf.recordError("tool", "validation_failed")
-		return nil, fmt.Errorf("input validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", interfaces.ErrInvalidToolInput, err)
 	}
 
-	result, err := tool.Execute(ctx, input)
+	result, err = tool.Execute(ctx, input)
 	if err != nil {
 		pf.recordError("tool", "execution_failed")
 		return nil, fmt.Errorf("tool execution failed: %w", err)
@@ -368,7 +526,7 @@ func (pf *PonchoFrameworkImpl) ExecuteTool(ctx context.Context, toolName string,
 }
 
 // ExecuteFlow executes a flow
-func (pf *PonchoFrameworkImpl) ExecuteFlow(ctx context.Context, flowName string, input interface{}) (interface{}, error) {
+func (pf *PonchoFrameworkImpl) ExecuteFlow(ctx context.Context, flowName string, input interface{}) (result interface{}, err error) {
 	if !pf.isStarted() {
 		return nil, fmt.Errorf("framework is not started")
 	}
@@ -376,16 +534,21 @@ func (pf *PonchoFrameworkImpl) ExecuteFlow(ctx context.Context, flowName string,
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime).Milliseconds()
-		pf.recordFlowExecutionMetrics(flowName, duration, true)
+		pf.recordFlowExecutionMetrics(flowName, duration, err == nil)
 	}()
 
 	pf.logger.Debug("Executing flow", "name", flowName)
 
-	flow, err := pf.flowRegistry.Get(flowName)
+	if pf.budgets != nil {
+		ctx = budget.WithFlowExecution(ctx, flowName)
+	}
+
+	flow, release, err := pf.acquireFlow(flowName)
 	if err != nil {
 		pf.recordError("flow", "flow_not_found")
 		return nil, fmt.Errorf("flow '%s' not found: %w", flowName, err)
 	}
+	defer release()
 
 	// Validate dependencies
 	if err := pf.flowRegistry.ValidateDependencies(flow, pf.modelRegistry, pf.toolRegistry); err != nil {
@@ -393,7 +556,7 @@ func (pf *PonchoFrameworkImpl) ExecuteFlow(ctx context.Context, flowName string,
 		return nil, fmt.Errorf("dependency validation failed: %w", err)
 	}
 
-	result, err := flow.Execute(ctx, input)
+	result, err = flow.Execute(ctx, input)
 	if err != nil {
 		pf.recordError("flow", "execution_failed")
 		return nil, fmt.Errorf("flow execution failed: %w", err)
@@ -404,18 +567,29 @@ func (pf *PonchoFrameworkImpl) ExecuteFlow(ctx context.Context, flowName string,
 }
 
 // ExecuteFlowStreaming executes a flow with streaming
-func (pf *PonchoFrameworkImpl) ExecuteFlowStreaming(ctx context.Context, flowName string, input interface{}, callback interfaces.PonchoStreamCallback) error {
+func (pf *PonchoFrameworkImpl) ExecuteFlowStreaming(ctx context.Context, flowName string, input interface{}, callback interfaces.PonchoStreamCallback) (err error) {
 	if !pf.isStarted() {
 		return fmt.Errorf("framework is not started")
 	}
 
+	startTime := time.Now()
+	defer func() {
+		duration := time.Since(startTime).Milliseconds()
+		pf.recordFlowExecutionMetrics(flowName, duration, err == nil)
+	}()
+
 	pf.logger.Debug("Starting streaming flow execution", "name", flowName)
 
-	flow, err := pf.flowRegistry.Get(flowName)
+	if pf.budgets != nil {
+		ctx = budget.WithFlowExecution(ctx, flowName)
+	}
+
+	flow, release, err := pf.acquireFlow(flowName)
 	if err != nil {
 		pf.recordError("flow", "flow_not_found")
 		return fmt.Errorf("flow '%s' not found: %w", flowName, err)
 	}
+	defer release()
 
 	// Validate dependencies
 	if err := pf.flowRegistry.ValidateDependencies(flow, pf.modelRegistry, pf.toolRegistry); err != nil {
@@ -450,62 +624,132 @@ func (pf *PonchoFrameworkImpl) GetFlowRegistry() interfaces.PonchoFlowRegistry {
 
 // GetConfig returns the current configuration
 func (pf *PonchoFrameworkImpl) GetConfig() *interfaces.PonchoFrameworkConfig {
+	pf.swapMutex.RLock()
+	defer pf.swapMutex.RUnlock()
+
 	return pf.config
 }
 
-// ReloadConfig reloads the framework configuration
+// ReloadConfig re-reads the configuration files and hot swaps models and tools whose
+// configuration changed. In-flight calls finish on the old instances before they are
+// shut down; ctx bounds how long ReloadConfig waits for them.
 func (pf *PonchoFrameworkImpl) ReloadConfig(ctx context.Context) error {
+	if !pf.isStarted() {
+		return fmt.Errorf("framework is not started")
+	}
+
+	if pf.configManager == nil {
+		return fmt.Errorf("config manager is not set")
+	}
+
 	pf.logger.Info("Reloading configuration")
-	// TODO: Implement configuration reloading
-	// This will be implemented in future phases
+
+	pf.reloadMutex.Lock()
+	defer pf.reloadMutex.Unlock()
+
+	// On failure the config manager keeps the previous configuration
+	if err := pf.configManager.Reload(); err != nil {
+		pf.recordError("config", "reload_failed")
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+
+	if err := pf.applyConfig(ctx); err != nil {
+		pf.recordError("config", "apply_failed")
+		return err
+	}
+
 	return nil
 }
 
-// Health returns the health status of the framework
+// WatchConfig reloads the configuration automatically whenever the configuration
+// files change. Each change is applied like ReloadConfig, waiting at most
+// DefaultReloadTimeout for in-flight calls.
+func (pf *PonchoFrameworkImpl) WatchConfig(ctx context.Context) error {
+	if pf.configManager == nil {
+		return fmt.Errorf("config manager is not set")
+	}
+
+	return pf.configManager.Watch(func(data *config.ConfigData) {
+		if !pf.isStarted() || ctx.Err() != nil {
+			return
+		}
+
+		pf.reloadMutex.Lock()
+		defer pf.reloadMutex.Unlock()
+
+		// ReloadConfig applies the configuration it loads itself
+		if data == pf.appliedConfig {
+			return
+		}
+
+		applyCtx, cancel := context.WithTimeout(ctx, DefaultReloadTimeout)
+		defer cancel()
+
+		if err := pf.applyConfig(applyCtx); err != nil {
+			pf.recordError("config", "apply_failed")
+			pf.logger.Error("Failed to apply changed configuration", "error", err)
+		}
+	})
+}
+
+// Health returns the health status of the framework.
+// Registered models, tools and flows implementing interfaces.HealthChecker are probed
+// concurrently with a per-probe timeout; results are cached (see SetHealthOptions).
 func (pf *PonchoFrameworkImpl) Health(ctx context.Context) (*interfaces.PonchoHealthStatus, error) {
 	pf.mutex.RLock()
-	defer pf.mutex.RUnlock()
+	started := pf.started
+	startTime := pf.startTime
+	pf.mutex.RUnlock()
 
 	status := &interfaces.PonchoHealthStatus{
 		Timestamp:  time.Now(),
 		Version:    "1.0.0", // TODO: Get from build info
 		Components: make(map[string]*interfaces.ComponentHealth),
-		Uptime:     time.Since(pf.startTime),
+		Uptime:     time.Since(startTime),
 	}
 
 	// Check framework status
-	if pf.started {
-		status.Status = "healthy"
-		status.Components["framework"] = &interfaces.ComponentHealth{
-			Status:  "healthy",
-			Message: "Framework is running",
-		}
-	} else {
-		status.Status = "unhealthy"
+	if !started {
+		status.Status = interfaces.HealthStatusUnhealthy
 		status.Components["framework"] = &interfaces.ComponentHealth{
-			Status:  "unhealthy",
+			Status:  interfaces.HealthStatusUnhealthy,
 			Message: "Framework is not started",
 		}
+		return status, nil
 	}
 
-	// TODO: Add health checks for other components
-	// This will be implemented in future phases
+	// Probe registered components without holding the framework lock
+	components := pf.health.probeAll(ctx, pf.healthTargets())
+	pf.applyCircuitHealth(components)
+	status.Status = overallHealthStatus(components)
+
+	for key, component := range components {
+		status.Components[key] = component
+	}
+	status.Components["framework"] = &interfaces.ComponentHealth{
+		Status:  interfaces.HealthStatusHealthy,
+		Message: "Framework is running",
+	}
 
 	return status, nil
 }
 
 // Metrics returns framework metrics
 func (pf *PonchoFrameworkImpl) Metrics(ctx context.Context) (*interfaces.PonchoMetrics, error) {
-	pf.mutex.RLock()
-	defer pf.mutex.RUnlock()
+	pf.mutex.Lock()
+	defer pf.mutex.Unlock()
 
-	// Update timestamp
+	// Update timestamp and runtime gauges
 	pf.metrics.Timestamp = time.Now()
+	pf.metrics.System = collectSystemMetrics()
+	if pf.responseCache != nil {
+		pf.metrics.Cache = pf.responseCache.Stats()
+	}
+	pf.metrics.Resilience = pf.resilienceMetrics()
+	pf.metrics.RateLimits = rateLimitMetrics()
 
-	// TODO: Collect real-time metrics
-	// This will be implemented in future phases
-
-	return pf.metrics, nil
+	// Return a snapshot so callers (e.g. exporters) never race with recorders
+	return snapshotMetrics(pf.metrics), nil
 }
 
 // Helper methods
@@ -536,18 +780,23 @@ func (pf *PonchoFrameworkImpl) recordGenerationMetrics(model string, duration in
 
 	modelMetrics := metrics.ByModel[model]
 	modelMetrics.Requests++
-
-	// Calculate success rate properly for both success and failure cases
-	previousSuccesses := int64(modelMetrics.SuccessRate * float64(modelMetrics.Requests-1))
-	if success {
-		previousSuccesses++
+	if !success {
+		modelMetrics.ErrorCount++
 	}
-	modelMetrics.SuccessRate = float64(previousSuccesses) / float64(modelMetrics.Requests)
-	
+
+	// Success rate is derived from exact counters (exported as model_availability)
+	modelMetrics.SuccessRate = float64(modelMetrics.Requests-modelMetrics.ErrorCount) / float64(modelMetrics.Requests)
+
 	modelMetrics.TotalTokens += int64(tokens)
-	
+	metrics.TotalTokens += int64(tokens)
+
 	// Update average latency (simplified)
 	modelMetrics.AvgLatency = (modelMetrics.AvgLatency + float64(duration)) / 2.0
+
+	if modelMetrics.Latency == nil {
+		modelMetrics.Latency = interfaces.NewHistogramMetrics(interfaces.DefaultLatencyBuckets)
+	}
+	modelMetrics.Latency.Observe(float64(duration) / 1000.0)
 }
 
 func (pf *PonchoFrameworkImpl) recordToolExecutionMetrics(tool string, duration int64, success bool) {
@@ -565,6 +814,14 @@ func (pf *PonchoFrameworkImpl) recordToolExecutionMetrics(tool string, duration
 
 	// Update tool-specific count
 	metrics.ByTool[tool]++
+	if !success {
+		metrics.ErrorsByTool[tool]++
+	}
+
+	if metrics.LatencyByTool[tool] == nil {
+		metrics.LatencyByTool[tool] = interfaces.NewHistogramMetrics(interfaces.DefaultLatencyBuckets)
+	}
+	metrics.LatencyByTool[tool].Observe(float64(duration) / 1000.0)
 }
 
 func (pf *PonchoFrameworkImpl) recordFlowExecutionMetrics(flow string, duration int64, success bool) {
@@ -582,6 +839,20 @@ func (pf *PonchoFrameworkImpl) recordFlowExecutionMetrics(flow string, duration
 
 	// Update flow-specific count
 	metrics.ByFlow[flow]++
+	if !success {
+		metrics.ErrorsByFlow[flow]++
+	}
+
+	if metrics.LatencyByFlow[flow] == nil {
+		metrics.LatencyByFlow[flow] = interfaces.NewHistogramMetrics(interfaces.DefaultLatencyBuckets)
+	}
+	metrics.LatencyByFlow[flow].Observe(float64(duration) / 1000.0)
+}
+
+// RecordError records an error in the framework ErrorMetrics.
+// It lets layers wrapping the framework (e.g. security.Guard) report rejections.
+func (pf *PonchoFrameworkImpl) RecordError(component, errorType string) {
+	pf.recordError(component, errorType)
 }
 
 func (pf *PonchoFrameworkImpl) recordError(component, errorType string) {
@@ -607,6 +878,137 @@ func (pf *PonchoFrameworkImpl) recordError(component, errorType string) {
 	}
 }
 
+// rateLimitMetrics returns the headroom of the shared provider rate limiters by
+// account, nil when no provider client is open
+func rateLimitMetrics() map[string]*interfaces.RateLimitMetrics {
+	limiters := common.RateLimiters()
+	if len(limiters) == 0 {
+		return nil
+	}
+
+	metrics := make(map[string]*interfaces.RateLimitMetrics, len(limiters))
+	for name, limiter := range limiters {
+		info := limiter.Info()
+		stats := limiter.Stats()
+
+		rl := &interfaces.RateLimitMetrics{
+			RequestsPerMinute: info.RequestsPerMinute,
+			TokensPerMinute:   info.TokensPerMinute,
+			Waits:             stats.Waits,
+			WaitTimeMs:        stats.WaitTime.Milliseconds(),
+		}
+		if info.RemainingRequests != nil {
+			rl.RemainingRequests = *info.RemainingRequests
+		}
+		if info.RemainingTokens != nil {
+			rl.RemainingTokens = *info.RemainingTokens
+		}
+		if info.RetryAfter != nil {
+			rl.PausedMs = info.RetryAfter.Milliseconds()
+		}
+		metrics[name] = rl
+	}
+	return metrics
+}
+
+// collectSystemMetrics reads runtime memory and goroutine statistics
+func collectSystemMetrics() *interfaces.SystemMetrics {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return &interfaces.SystemMetrics{
+		MemoryUsage:    int64(m.Alloc),
+		GoroutineCount: int64(runtime.NumGoroutine()),
+		GCCount:        int64(m.NumGC),
+		HeapSize:       int64(m.HeapSys),
+		HeapAlloc:      int64(m.HeapAlloc),
+	}
+}
+
+// snapshotMetrics returns a deep copy of the metrics (caller must hold the mutex)
+func snapshotMetrics(src *interfaces.PonchoMetrics) *interfaces.PonchoMetrics {
+	snapshot := &interfaces.PonchoMetrics{Timestamp: src.Timestamp}
+
+	if gen := src.GeneratedRequests; gen != nil {
+		genCopy := *gen
+		genCopy.ByModel = make(map[string]*interfaces.ModelMetrics, len(gen.ByModel))
+		for name, mm := range gen.ByModel {
+			mmCopy := *mm
+			mmCopy.Latency = mm.Latency.Clone()
+			genCopy.ByModel[name] = &mmCopy
+		}
+		snapshot.GeneratedRequests = &genCopy
+	}
+
+	if tools := src.ToolExecutions; tools != nil {
+		toolsCopy := *tools
+		toolsCopy.ByTool = copyCounters(tools.ByTool)
+		toolsCopy.ErrorsByTool = copyCounters(tools.ErrorsByTool)
+		toolsCopy.LatencyByTool = copyHistograms(tools.LatencyByTool)
+		snapshot.ToolExecutions = &toolsCopy
+	}
+
+	if flows := src.FlowExecutions; flows != nil {
+		flowsCopy := *flows
+		flowsCopy.ByFlow = copyCounters(flows.ByFlow)
+		flowsCopy.ErrorsByFlow = copyCounters(flows.ErrorsByFlow)
+		flowsCopy.LatencyByFlow = copyHistograms(flows.LatencyByFlow)
+		snapshot.FlowExecutions = &flowsCopy
+	}
+
+	if errs := src.Errors; errs != nil {
+		errsCopy := *errs
+		errsCopy.ByType = copyCounters(errs.ByType)
+		errsCopy.ByComponent = copyCounters(errs.ByComponent)
+		errsCopy.RecentErrors = append([]*interfaces.ErrorInfo(nil), errs.RecentErrors...)
+		snapshot.Errors = &errsCopy
+	}
+
+	if src.System != nil {
+		systemCopy := *src.System
+		snapshot.System = &systemCopy
+	}
+
+	if src.Cache != nil {
+		cacheCopy := *src.Cache
+		snapshot.Cache = &cacheCopy
+	}
+
+	if src.Resilience != nil {
+		snapshot.Resilience = make(map[string]*interfaces.ResilienceMetrics, len(src.Resilience))
+		for model, rm := range src.Resilience {
+			rmCopy := *rm
+			snapshot.Resilience[model] = &rmCopy
+		}
+	}
+
+	if src.RateLimits != nil {
+		snapshot.RateLimits = make(map[string]*interfaces.RateLimitMetrics, len(src.RateLimits))
+		for provider, rl := range src.RateLimits {
+			rlCopy := *rl
+			snapshot.RateLimits[provider] = &rlCopy
+		}
+	}
+
+	return snapshot
+}
+
+func copyCounters(src map[string]int64) map[string]int64 {
+	dst := make(map[string]int64, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func copyHistograms(src map[string]*interfaces.HistogramMetrics) map[string]*interfaces.HistogramMetrics {
+	dst := make(map[string]*interfaces.HistogramMetrics, len(src))
+	for k, v := range src {
+		dst[k] = v.Clone()
+	}
+	return dst
+}
+
 // registerFactoriesFromConfig registers factories based on configuration
 func (pf *PonchoFrameworkImpl) registerFactoriesFromConfig() error {
 	if pf.serviceLocator == nil {
@@ -660,6 +1062,8 @@ func (pf *PonchoFrameworkImpl) loadAndRegisterModels(ctx context.Context) error
 
 	// Register models with framework
 	for name, model := range models {
+		pf.modelConfigs[name] = modelConfigs[name]
+
 		if err := pf.modelRegistry.Register(name, model); err != nil {
 			pf.logger.Error("Failed to register model", "name", name, "error", err)
 			return fmt.Errorf("failed to register model %s: %w", name, err)
@@ -675,6 +1079,57 @@ func (pf *PonchoFrameworkImpl) loadAndRegisterModels(ctx context.Context) error
 	return nil
 }
 
+// loadAndRegisterTools creates and registers tools from configuration.
+// Only enabled tools with a registered tool factory are created; other tools are
+// expected to be registered manually via RegisterTool.
+func (pf *PonchoFrameworkImpl) loadAndRegisterTools(ctx context.Context) error {
+	toolConfigs, err := pf.resolveToolConfigs()
+	if err != nil {
+		return err
+	}
+
+	for name, toolConfig := range toolConfigs {
+		tool, err := pf.createTool(name, toolConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create tool %s: %w", name, err)
+		}
+
+		if err := pf.toolRegistry.Register(name, tool); err != nil {
+			return fmt.Errorf("failed to register tool %s: %w", name, err)
+		}
+		pf.toolConfigs[name] = toolConfig
+
+		pf.logger.Info("Tool registered successfully", "name", name)
+	}
+
+	return nil
+}
+
+// initResponseCache creates the response cache from the framework config or the
+// "cache" config section. Caching is disabled when neither is present.
+func (pf *PonchoFrameworkImpl) initResponseCache() error {
+	cacheConfig := pf.config.Cache
+	if cacheConfig == nil && pf.configManager != nil {
+		var err error
+		if cacheConfig, err = pf.configManager.GetCacheConfig(); err != nil {
+			return err
+		}
+	}
+
+	if cacheConfig == nil {
+		pf.responseCache = nil
+		return nil
+	}
+
+	responseCache, err := cache.New(cacheConfig, pf.logger)
+	if err != nil {
+		return err
+	}
+
+	pf.responseCache = responseCache
+	return nil
+}
+
 // reloadModels reloads models from configuration
 func (pf *PonchoFrameworkImpl) reloadModels(ctx context.Context) error {
 	pf.logger.Info("Reloading models")
@@ -688,11 +1143,32 @@ func (pf *PonchoFrameworkImpl) reloadModels(ctx context.Context) error {
 	return pf.loadAndRegisterModels(ctx)
 }
 
+// GetSecurityConfig returns the security configuration from the framework config,
+// falling back to the "security" section of the loaded configuration.
+// Returns nil if security is not configured.
+func (pf *PonchoFrameworkImpl) GetSecurityConfig() (*interfaces.SecurityConfig, error) {
+	if pf.config.Security != nil {
+		return pf.config.Security, nil
+	}
+	if pf.configManager == nil {
+		return nil, nil
+	}
+	return pf.configManager.GetSecurityConfig()
+}
+
 // GetConfigManager returns the config manager
 func (pf *PonchoFrameworkImpl) GetConfigManager() config.ConfigManager {
 	return pf.configManager
 }
 
+// SetConfigManager replaces the config manager (e.g. to load a different config file).
+// It must be called before Start.
+func (pf *PonchoFrameworkImpl) SetConfigManager(cm config.ConfigManager) {
+	pf.mutex.Lock()
+	defer pf.mutex.Unlock()
+	pf.configManager = cm
+}
+
 // GetModelFactoryManager returns the model factory manager
 func (pf *PonchoFrameworkImpl) GetModelFactoryManager() interfaces.ModelFactoryManager {
 	if pf.serviceLocator == nil {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ilkoid/PonchoAiFramework/core/base"
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
 )
 
 // Mock implementations for testing
@@ -845,6 +846,29 @@ func TestMetricsRecording(t *testing.T) {
 	}
 }
 
+func TestMetricsRateLimits(t *testing.T) {
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{}, interfaces.NewNoOpLogger())
+	if err := framework.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = framework.Stop(context.Background()) })
+
+	t.Cleanup(func() { common.UnregisterRateLimiter("custom@metrics-test") })
+	limiter := common.SharedRateLimiter("custom@metrics-test", 60, 0)
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	metrics, err := framework.Metrics(context.Background())
+	if err != nil {
+		t.Fatalf("Metrics() error = %v", err)
+	}
+	rl := metrics.RateLimits["custom@metrics-test"]
+	if rl == nil || rl.RequestsPerMinute != 60 || rl.RemainingRequests != 59 || rl.TokensPerMinute != 0 {
+		t.Errorf("unexpected rate limit metrics: %+v", rl)
+	}
+}
+
 // Benchmark tests
 
 func BenchmarkFrameworkGenerate(b *testing.B) {
@@ -0,0 +1,290 @@
+package core
+
+// Component health probes for the PonchoFramework
+//
+// Health fans out to every registered model, tool and flow. Components implementing
+// interfaces.HealthChecker are probed concurrently, each with its own timeout, and the
+// results are cached for a short TTL so frequent /health polling does not hammer
+// upstream providers. Components without a probe are reported as healthy.
+//
+// Overall status:
+// - healthy:   framework started and every component healthy
+// - degraded:  at least one component unhealthy
+// - unhealthy: framework not started, or models are registered and none is healthy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// HealthOptions configures component health probes
+type HealthOptions struct {
+	// ProbeTimeout bounds a single HealthCheck call
+	ProbeTimeout time.Duration
+	// CacheTTL is how long a probe result is reused before probing again
+	CacheTTL time.Duration
+}
+
+// DefaultHealthOptions returns the default health probe options
+func DefaultHealthOptions() HealthOptions {
+	return HealthOptions{
+		ProbeTimeout: 5 * time.Second,
+		CacheTTL:     15 * time.Second,
+	}
+}
+
+// healthTarget is a registered component to probe
+type healthTarget struct {
+	kind      string // model, tool, flow
+	name      string
+	component interface{}
+}
+
+// key returns the component key used in PonchoHealthStatus.Components
+func (t healthTarget) key() string {
+	return t.kind + ":" + t.name
+}
+
+// probeResult is a cached probe outcome
+type probeResult struct {
+	component   interface{}
+	probed      bool
+	err         error
+	latency     time.Duration
+	checkedAt   time.Time
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// healthProber runs and caches component health probes
+type healthProber struct {
+	options HealthOptions
+	results map[string]*probeResult
+	mutex   sync.Mutex
+}
+
+func newHealthProber(options HealthOptions) *healthProber {
+	return &healthProber{
+		options: options,
+		results: make(map[string]*probeResult),
+	}
+}
+
+// setOptions replaces the probe options and drops cached results
+func (hp *healthProber) setOptions(options HealthOptions) {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	hp.options = options
+	hp.results = make(map[string]*probeResult)
+}
+
+// probeAll probes all targets concurrently, reusing fresh cached results
+func (hp *healthProber) probeAll(ctx context.Context, targets []healthTarget) map[string]*interfaces.ComponentHealth {
+	hp.mutex.Lock()
+	options := hp.options
+	now := time.Now()
+
+	cached := make(map[string]bool, len(targets))
+	stale := make([]healthTarget, 0, len(targets))
+	active := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		key := target.key()
+		active[key] = true
+
+		// Reuse results for the same instance within TTL; swapped instances are probed again
+		if result, exists := hp.results[key]; exists &&
+			result.component == target.component && now.Sub(result.checkedAt) < options.CacheTTL {
+			cached[key] = true
+			continue
+		}
+		stale = append(stale, target)
+	}
+
+	// Forget components that are no longer registered
+	for key := range hp.results {
+		if !active[key] {
+			delete(hp.results, key)
+		}
+	}
+	hp.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, target := range stale {
+		wg.Add(1)
+		go func(target healthTarget) {
+			defer wg.Done()
+			hp.store(target, hp.probe(ctx, target, options.ProbeTimeout))
+		}(target)
+	}
+	wg.Wait()
+
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	components := make(map[string]*interfaces.ComponentHealth, len(targets))
+	for _, target := range targets {
+		key := target.key()
+		if result, exists := hp.results[key]; exists {
+			components[key] = result.toComponentHealth(target, cached[key])
+		}
+	}
+
+	return components
+}
+
+// probe runs a single probe, returning once it finishes or the timeout expires
+func (hp *healthProber) probe(ctx context.Context, target healthTarget, timeout time.Duration) *probeResult {
+	result := &probeResult{component: target.component}
+
+	checker, ok := target.component.(interfaces.HealthChecker)
+	if !ok {
+		result.checkedAt = time.Now()
+		return result
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("health check panicked: %v", r)
+			}
+		}()
+		done <- checker.HealthCheck(probeCtx)
+	}()
+
+	select {
+	case err := <-done:
+		result.err = err
+	case <-probeCtx.Done():
+		result.err = fmt.Errorf("health check timed out after %s: %w", timeout, probeCtx.Err())
+	}
+
+	result.probed = true
+	result.latency = time.Since(start)
+	result.checkedAt = time.Now()
+	return result
+}
+
+// store saves a probe result, carrying over the last error of previous probes
+func (hp *healthProber) store(target healthTarget, result *probeResult) {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	key := target.key()
+	if previous, exists := hp.results[key]; exists {
+		result.lastError = previous.lastError
+		result.lastErrorAt = previous.lastErrorAt
+	}
+
+	if result.err != nil {
+		result.lastError = result.err.Error()
+		result.lastErrorAt = result.checkedAt
+	}
+
+	hp.results[key] = result
+}
+
+// toComponentHealth converts a probe result to ComponentHealth
+func (r *probeResult) toComponentHealth(target healthTarget, cached bool) *interfaces.ComponentHealth {
+	health := &interfaces.ComponentHealth{
+		Status:  interfaces.HealthStatusHealthy,
+		Message: fmt.Sprintf("%s is operational", target.kind),
+		Details: map[string]interface{}{
+			"type":       target.kind,
+			"name":       target.name,
+			"probed":     r.probed,
+			"cached":     cached,
+			"latency_ms": float64(r.latency.Microseconds()) / 1000.0,
+			"checked_at": r.checkedAt,
+		},
+	}
+
+	if !r.probed {
+		health.Message = fmt.Sprintf("%s has no health probe", target.kind)
+	}
+
+	if r.err != nil {
+		health.Status = interfaces.HealthStatusUnhealthy
+		health.Message = r.err.Error()
+	}
+
+	if r.lastError != "" {
+		health.Details["last_error"] = r.lastError
+		health.Details["last_error_at"] = r.lastErrorAt
+	}
+
+	return health
+}
+
+// SetHealthOptions configures component health probes
+func (pf *PonchoFrameworkImpl) SetHealthOptions(options HealthOptions) {
+	defaults := DefaultHealthOptions()
+	if options.ProbeTimeout <= 0 {
+		options.ProbeTimeout = defaults.ProbeTimeout
+	}
+	if options.CacheTTL < 0 {
+		options.CacheTTL = 0
+	}
+
+	pf.health.setOptions(options)
+}
+
+// healthTargets returns all registered components
+func (pf *PonchoFrameworkImpl) healthTargets() []healthTarget {
+	var targets []healthTarget
+
+	for _, name := range pf.modelRegistry.List() {
+		if model, err := pf.modelRegistry.Get(name); err == nil {
+			targets = append(targets, healthTarget{kind: "model", name: name, component: model})
+		}
+	}
+
+	for _, name := range pf.toolRegistry.List() {
+		if tool, err := pf.toolRegistry.Get(name); err == nil {
+			targets = append(targets, healthTarget{kind: "tool", name: name, component: tool})
+		}
+	}
+
+	for _, name := range pf.flowRegistry.List() {
+		if flow, err := pf.flowRegistry.Get(name); err == nil {
+			targets = append(targets, healthTarget{kind: "flow", name: name, component: flow})
+		}
+	}
+
+	return targets
+}
+
+// overallHealthStatus derives the framework status from component statuses
+func overallHealthStatus(components map[string]*interfaces.ComponentHealth) string {
+	status := interfaces.HealthStatusHealthy
+	models, healthyModels := 0, 0
+
+	for _, component := range components {
+		healthy := component.Status == interfaces.HealthStatusHealthy
+		if !healthy {
+			status = interfaces.HealthStatusDegraded
+		}
+
+		if component.Details["type"] == "model" {
+			models++
+			if healthy {
+				healthyModels++
+			}
+		}
+	}
+
+	if models > 0 && healthyModels == 0 {
+		return interfaces.HealthStatusUnhealthy
+	}
+
+	return status
+}
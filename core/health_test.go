@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// probedModel implements interfaces.HealthChecker with a configurable result
+type probedModel struct {
+	*base.PonchoBaseModel
+	err   error
+	block bool
+	calls atomic.Int32
+}
+
+func (m *probedModel) HealthCheck(ctx context.Context) error {
+	m.calls.Add(1)
+	if m.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return m.err
+}
+
+func newProbedModel(name string, err error) *probedModel {
+	return &probedModel{
+		PonchoBaseModel: base.NewPonchoBaseModel(name, "test", interfaces.ModelCapabilities{}),
+		err:             err,
+	}
+}
+
+func newHealthFramework(t *testing.T) *PonchoFrameworkImpl {
+	t.Helper()
+
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{}, interfaces.NewNoOpLogger())
+	if err := framework.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	t.Cleanup(func() { _ = framework.Stop(context.Background()) })
+
+	return framework
+}
+
+func TestHealth_ProbesComponents(t *testing.T) {
+	framework := newHealthFramework(t)
+
+	healthy := newProbedModel("healthy-model", nil)
+	failing := newProbedModel("failing-model", errors.New("upstream unavailable"))
+	if err := framework.RegisterModel("healthy-model", healthy); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+	if err := framework.RegisterModel("failing-model", failing); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+	tool := base.NewPonchoBaseTool("plain-tool", "Tool without probe", "1.0.0", "test")
+	if err := framework.RegisterTool("plain-tool", tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	health, err := framework.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+
+	if health.Status != interfaces.HealthStatusDegraded {
+		t.Errorf("Expected degraded status with one failing model, got %s", health.Status)
+	}
+
+	component := health.Components["model:failing-model"]
+	if component == nil {
+		t.Fatal("Expected failing model in components")
+	}
+	if component.Status != interfaces.HealthStatusUnhealthy {
+		t.Errorf("Expected failing model to be unhealthy, got %s", component.Status)
+	}
+	if component.Details["last_error"] != "upstream unavailable" {
+		t.Errorf("Expected last_error to be recorded, got %v", component.Details["last_error"])
+	}
+	if _, ok := component.Details["latency_ms"]; !ok {
+		t.Error("Expected latency_ms in details")
+	}
+
+	if health.Components["model:healthy-model"].Status != interfaces.HealthStatusHealthy {
+		t.Error("Expected healthy model to be healthy")
+	}
+
+	toolHealth := health.Components["tool:plain-tool"]
+	if toolHealth == nil || toolHealth.Status != interfaces.HealthStatusHealthy {
+		t.Error("Expected tool without probe to be reported healthy")
+	} else if toolHealth.Details["probed"] != false {
+		t.Error("Expected tool without probe to be marked as not probed")
+	}
+}
+
+func TestHealth_AllModelsUnhealthy(t *testing.T) {
+	framework := newHealthFramework(t)
+
+	if err := framework.RegisterModel("failing-model", newProbedModel("failing-model", errors.New("down"))); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+
+	health, err := framework.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+
+	if health.Status != interfaces.HealthStatusUnhealthy {
+		t.Errorf("Expected unhealthy status when no model is healthy, got %s", health.Status)
+	}
+}
+
+func TestHealth_ProbeTimeout(t *testing.T) {
+	framework := newHealthFramework(t)
+	framework.SetHealthOptions(HealthOptions{ProbeTimeout: 20 * time.Millisecond})
+
+	slow := newProbedModel("slow-model", nil)
+	slow.block = true
+	if err := framework.RegisterModel("slow-model", slow); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+	if err := framework.RegisterModel("healthy-model", newProbedModel("healthy-model", nil)); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+
+	start := time.Now()
+	health, err := framework.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Health to respect probe timeout, took %v", elapsed)
+	}
+	if health.Components["model:slow-model"].Status != interfaces.HealthStatusUnhealthy {
+		t.Error("Expected timed out model to be unhealthy")
+	}
+	if health.Status != interfaces.HealthStatusDegraded {
+		t.Errorf("Expected degraded status, got %s", health.Status)
+	}
+}
+
+func TestHealth_CachesResults(t *testing.T) {
+	framework := newHealthFramework(t)
+	framework.SetHealthOptions(HealthOptions{ProbeTimeout: time.Second, CacheTTL: time.Minute})
+
+	model := newProbedModel("cached-model", nil)
+	if err := framework.RegisterModel("cached-model", model); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := framework.Health(ctx); err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	health, err := framework.Health(ctx)
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+
+	if calls := model.calls.Load(); calls != 1 {
+		t.Errorf("Expected one probe within cache TTL, got %d", calls)
+	}
+	if health.Components["model:cached-model"].Details["cached"] != true {
+		t.Error("Expected second result to be served from cache")
+	}
+
+	// Disabling the cache probes on every call
+	framework.SetHealthOptions(HealthOptions{ProbeTimeout: time.Second})
+	if _, err := framework.Health(ctx); err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if calls := model.calls.Load(); calls != 2 {
+		t.Errorf("Expected probe after cache was disabled, got %d calls", calls)
+	}
+}
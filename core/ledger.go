@@ -0,0 +1,182 @@
+package core
+
+// Usage ledger and budget integration for the PonchoFramework
+//
+// When a usage ledger is configured (PonchoFrameworkConfig.Usage or the "usage"
+// config section), every successful Generate, GenerateStreaming and Embed call is
+// recorded with its tokens, estimated cost and Metadata tags. See core/usage for the
+// record format and reports.
+//
+// When budgets are configured (PonchoFrameworkConfig.Budgets or the "budgets" config
+// section), calls are checked against them by the budget middleware before they reach
+// the provider. Daily budgets start from today's ledger records, so a restart does
+// not reset them. See core/budget.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/budget"
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// UsageLedger returns the usage ledger, or nil when usage recording is not configured
+func (pf *PonchoFrameworkImpl) UsageLedger() *usage.Ledger {
+	return pf.usageLedger
+}
+
+// initUsageLedger opens the usage ledger from the framework config or the
+// "usage" section of the loaded configuration
+func (pf *PonchoFrameworkImpl) initUsageLedger() error {
+	usageConfig := pf.config.Usage
+	if usageConfig == nil && pf.configManager != nil {
+		var err error
+		if usageConfig, err = pf.configManager.GetUsageConfig(); err != nil {
+			return err
+		}
+	}
+
+	if pf.usageLedger != nil {
+		if err := pf.usageLedger.Close(); err != nil {
+			pf.logger.Warn("Failed to close usage ledger", "error", err)
+		}
+		pf.usageLedger = nil
+	}
+
+	if usageConfig == nil {
+		return nil
+	}
+
+	ledger, err := usage.Open(usageConfig.Path, pf.logger)
+	if err != nil {
+		return err
+	}
+
+	pf.usageLedger = ledger
+	return nil
+}
+
+// initBudgets creates the budget enforcer from the framework config or the
+// "budgets" section of the loaded configuration. Must run after initUsageLedger.
+func (pf *PonchoFrameworkImpl) initBudgets() error {
+	budgetConfig := pf.config.Budgets
+	if budgetConfig == nil && pf.configManager != nil {
+		var err error
+		if budgetConfig, err = pf.configManager.GetBudgetConfig(); err != nil {
+			return err
+		}
+	}
+
+	if budgetConfig == nil {
+		pf.budgets = nil
+		return nil
+	}
+
+	enforcer, err := budget.NewEnforcer(budgetConfig, func(name string) (string, string) {
+		if model, err := pf.modelRegistry.Get(name); err == nil {
+			return pf.resolveModel(name, model)
+		}
+		if model, err := pf.embeddingRegistry.Get(name); err == nil {
+			return pf.resolveEmbeddingModel(name, model)
+		}
+		return pf.resolveModel(name, nil)
+	}, pf.logger)
+	if err != nil {
+		return err
+	}
+
+	if pf.usageLedger != nil {
+		records, err := pf.usageLedger.Query(usage.Day(time.Now()))
+		if err != nil {
+			return fmt.Errorf("failed to read today's usage: %w", err)
+		}
+		enforcer.Seed(records)
+	}
+
+	pf.budgets = enforcer
+	return nil
+}
+
+// resolveModel returns the provider and provider model name of a registered model,
+// preferring its configuration over the model instance. model may be nil.
+func (pf *PonchoFrameworkImpl) resolveModel(name string, model interfaces.PonchoModel) (provider, modelName string) {
+	pf.swapMutex.RLock()
+	modelConfig, exists := pf.modelConfigs[name]
+	pf.swapMutex.RUnlock()
+
+	switch {
+	case exists:
+		return modelConfig.Provider, modelConfig.ModelName
+	case model != nil:
+		return model.Provider(), model.Name()
+	}
+	return "", name
+}
+
+// resolveEmbeddingModel is resolveModel for embedding models. model may be nil.
+func (pf *PonchoFrameworkImpl) resolveEmbeddingModel(name string, model interfaces.PonchoEmbeddingModel) (provider, modelName string) {
+	pf.swapMutex.RLock()
+	modelConfig, exists := pf.embeddingConfigs[name]
+	pf.swapMutex.RUnlock()
+
+	switch {
+	case exists:
+		return modelConfig.Provider, modelConfig.ModelName
+	case model != nil:
+		return model.Provider(), model.Name()
+	}
+	return "", name
+}
+
+// recordUsage appends a completed model call to the usage ledger and the token calibrator.
+// Calls to composite models are recorded by the calls they dispatch to their targets.
+func (pf *PonchoFrameworkImpl) recordUsage(req *interfaces.PonchoModelRequest, model interfaces.PonchoModel, resp *interfaces.PonchoModelResponse, streaming bool) {
+	if _, composite := model.(compositeModel); composite {
+		return
+	}
+
+	pf.calibrateTokens(req, model, resp)
+
+	provider, modelName := pf.resolveModel(req.Model, model)
+	pf.recordLedger(&usage.Call{Request: req, Response: resp, Provider: provider, ModelName: modelName, Streaming: streaming})
+}
+
+// recordPartialUsage appends the usage a failed stream reported to the usage ledger,
+// marked partial. Budgets charge the same usage (see budget.Middleware), so a ledger
+// seeding the budgets agrees with live accounting.
+func (pf *PonchoFrameworkImpl) recordPartialUsage(req *interfaces.PonchoModelRequest, model interfaces.PonchoModel, resp *interfaces.PonchoModelResponse) {
+	if _, composite := model.(compositeModel); composite || responseTokens(resp) == 0 {
+		return
+	}
+
+	provider, modelName := pf.resolveModel(req.Model, model)
+	pf.recordLedger(&usage.Call{Request: req, Response: resp, Provider: provider, ModelName: modelName, Streaming: true, Partial: true})
+}
+
+// recordEmbeddingUsage appends a completed embedding call to the usage ledger
+func (pf *PonchoFrameworkImpl) recordEmbeddingUsage(req *interfaces.PonchoModelRequest, model interfaces.PonchoEmbeddingModel, resp *interfaces.PonchoModelResponse) {
+	provider, modelName := pf.resolveEmbeddingModel(req.Model, model)
+	pf.recordLedger(&usage.Call{Request: req, Response: resp, Provider: provider, ModelName: modelName})
+}
+
+// recordLedger appends a call to the usage ledger, if one is configured
+func (pf *PonchoFrameworkImpl) recordLedger(call *usage.Call) {
+	ledger := pf.usageLedger
+	if ledger == nil || call.Response == nil {
+		return
+	}
+
+	if _, err := ledger.Record(call); err != nil {
+		pf.logger.Warn("Failed to record usage", "model", call.Request.Model, "error", err)
+		pf.recordError("usage", "ledger_write_failed")
+	}
+}
+
+// responseTokens returns the total tokens reported in a response
+func responseTokens(resp *interfaces.PonchoModelResponse) int {
+	if resp == nil || resp.Usage == nil {
+		return 0
+	}
+	return resp.Usage.TotalTokens
+}
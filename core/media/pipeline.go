@@ -282,6 +282,11 @@ func (mp *MediaPipeline) scoreConverter(converter MediaConverter, source *MediaD
 
 // getPreferredFormat determines the best format for a model
 func (mp *MediaPipeline) getPreferredFormat(model interfaces.PonchoModel) MediaFormat {
+	// Local Ollama servers cannot fetch URLs, images must be inlined
+	if model.Provider() == "ollama" {
+		return MediaFormatBase64
+	}
+
 	// Most vision models prefer base64 data URLs
 	if strings.Contains(model.Name(), "vision") || strings.Contains(model.Name(), "glm") {
 		return MediaFormatBase64
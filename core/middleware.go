@@ -0,0 +1,246 @@
+package core
+
+// Model middleware for the PonchoFramework
+//
+// Middlewares wrap PonchoModel.Generate and GenerateStreaming calls made through the
+// framework, so cross-cutting behavior (logging, redaction, caching, tracing, quotas)
+// is written once instead of once per provider.
+//
+// Middlewares are applied in this order, the first one being the outermost:
+// 1. Global middlewares added with UseMiddleware
+// 2. Global middlewares listed in the top-level "middleware" config key
+// 3. Per-model middlewares added with UseModelMiddleware
+// 4. Per-model middlewares listed in the model's "middleware" config key
+// 5. The response cache, if configured; every middleware above also sees cache hits
+// 6. Spend budgets, if configured; being innermost, cache hits are never charged
+//
+// Calls to composite models such as routers only get their per-model middlewares:
+// the calls they dispatch to their targets go through the full chain of the target.
+//
+// Config refers to middlewares by name; names are registered with RegisterMiddleware.
+// The built-in "logging" and "recovery" middlewares are always available.
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// MiddlewareConfigKey is the top-level config key listing global middleware names
+const MiddlewareConfigKey = "middleware"
+
+// middlewareSet holds named and attached model middlewares
+type middlewareSet struct {
+	named   map[string]*interfaces.Middleware
+	global  []*interfaces.Middleware
+	byModel map[string][]*interfaces.Middleware
+	mutex   sync.RWMutex
+}
+
+func newMiddlewareSet(logger interfaces.Logger) *middlewareSet {
+	ms := &middlewareSet{
+		named:   make(map[string]*interfaces.Middleware),
+		byModel: make(map[string][]*interfaces.Middleware),
+	}
+
+	for _, mw := range []*interfaces.Middleware{
+		LoggingMiddleware(logger),
+		RecoveryMiddleware(),
+	} {
+		ms.named[mw.Name] = mw
+	}
+
+	return ms
+}
+
+// RegisterMiddleware registers a named middleware that can be referenced from config
+func (pf *PonchoFrameworkImpl) RegisterMiddleware(mw *interfaces.Middleware) error {
+	if mw == nil {
+		return fmt.Errorf("middleware cannot be nil")
+	}
+	if mw.Name == "" {
+		return fmt.Errorf("middleware name cannot be empty")
+	}
+
+	pf.middleware.mutex.Lock()
+	defer pf.middleware.mutex.Unlock()
+
+	if _, exists := pf.middleware.named[mw.Name]; exists {
+		return fmt.Errorf("middleware '%s' is already registered", mw.Name)
+	}
+	pf.middleware.named[mw.Name] = mw
+
+	pf.logger.Debug("Middleware registered", "name", mw.Name)
+	return nil
+}
+
+// UseMiddleware attaches middlewares to all models
+func (pf *PonchoFrameworkImpl) UseMiddleware(mws ...*interfaces.Middleware) {
+	pf.middleware.mutex.Lock()
+	defer pf.middleware.mutex.Unlock()
+
+	pf.middleware.global = append(pf.middleware.global, mws...)
+}
+
+// UseModelMiddleware attaches middlewares to a single model
+func (pf *PonchoFrameworkImpl) UseModelMiddleware(model string, mws ...*interfaces.Middleware) {
+	pf.middleware.mutex.Lock()
+	defer pf.middleware.mutex.Unlock()
+
+	pf.middleware.byModel[model] = append(pf.middleware.byModel[model], mws...)
+}
+
+// configuredMiddleware returns the global middleware names from config
+func (pf *PonchoFrameworkImpl) configuredMiddleware() []string {
+	if pf.configManager == nil {
+		return nil
+	}
+	return pf.configManager.GetStringSlice(MiddlewareConfigKey)
+}
+
+// validateMiddlewareConfig checks that every middleware named in config is registered
+func (pf *PonchoFrameworkImpl) validateMiddlewareConfig(modelConfigs map[string]*interfaces.ModelConfig) error {
+	pf.middleware.mutex.RLock()
+	defer pf.middleware.mutex.RUnlock()
+
+	for _, name := range pf.configuredMiddleware() {
+		if _, exists := pf.middleware.named[name]; !exists {
+			return fmt.Errorf("unknown middleware '%s'", name)
+		}
+	}
+
+	for modelName, modelConfig := range modelConfigs {
+		for _, name := range modelConfig.Middleware {
+			if _, exists := pf.middleware.named[name]; !exists {
+				return fmt.Errorf("unknown middleware '%s' for model '%s'", name, modelName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// modelMiddleware resolves the middleware chain for a model, outermost first
+func (pf *PonchoFrameworkImpl) modelMiddleware(model string, instance interfaces.PonchoModel) ([]*interfaces.Middleware, error) {
+	var configured []string
+	pf.swapMutex.RLock()
+	if modelConfig, exists := pf.modelConfigs[model]; exists {
+		configured = modelConfig.Middleware
+	}
+	pf.swapMutex.RUnlock()
+
+	_, composite := instance.(compositeModel)
+
+	var global []string
+	if !composite {
+		global = pf.configuredMiddleware()
+	}
+
+	pf.middleware.mutex.RLock()
+	defer pf.middleware.mutex.RUnlock()
+
+	chain := make([]*interfaces.Middleware, 0, len(pf.middleware.global)+len(global)+len(configured))
+	if !composite {
+		chain = append(chain, pf.middleware.global...)
+	}
+	for _, name := range global {
+		mw, exists := pf.middleware.named[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown middleware '%s'", name)
+		}
+		chain = append(chain, mw)
+	}
+
+	chain = append(chain, pf.middleware.byModel[model]...)
+	for _, name := range configured {
+		mw, exists := pf.middleware.named[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown middleware '%s' for model '%s'", name, model)
+		}
+		chain = append(chain, mw)
+	}
+
+	if composite {
+		return chain, nil
+	}
+
+	if pf.responseCache != nil {
+		chain = append(chain, pf.responseCache.Middleware())
+	}
+	if pf.budgets != nil {
+		chain = append(chain, pf.budgets.Middleware())
+	}
+
+	return chain, nil
+}
+
+// LoggingMiddleware logs every model call with its duration and outcome
+func LoggingMiddleware(logger interfaces.Logger) *interfaces.Middleware {
+	return &interfaces.Middleware{
+		Name: "logging",
+		Unary: func(next interfaces.ModelHandler) interfaces.ModelHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+				start := time.Now()
+				resp, err := next(ctx, req)
+				if err != nil {
+					logger.Warn("Model call failed", "model", req.Model, "duration", time.Since(start), "error", err)
+					return resp, err
+				}
+
+				tokens := 0
+				if resp != nil && resp.Usage != nil {
+					tokens = resp.Usage.TotalTokens
+				}
+				logger.Info("Model call completed", "model", req.Model, "duration", time.Since(start), "tokens", tokens)
+				return resp, nil
+			}
+		},
+		Stream: func(next interfaces.StreamHandler) interfaces.StreamHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+				start := time.Now()
+				chunks := 0
+				err := next(ctx, req, func(chunk *interfaces.PonchoStreamChunk) error {
+					chunks++
+					return callback(chunk)
+				})
+				if err != nil {
+					logger.Warn("Model stream failed", "model", req.Model, "duration", time.Since(start), "chunks", chunks, "error", err)
+					return err
+				}
+
+				logger.Info("Model stream completed", "model", req.Model, "duration", time.Since(start), "chunks", chunks)
+				return nil
+			}
+		},
+	}
+}
+
+// RecoveryMiddleware converts panics in model calls into errors
+func RecoveryMiddleware() *interfaces.Middleware {
+	return &interfaces.Middleware{
+		Name: "recovery",
+		Unary: func(next interfaces.ModelHandler) interfaces.ModelHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest) (resp *interfaces.PonchoModelResponse, err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						resp, err = nil, fmt.Errorf("model '%s' panicked: %v", req.Model, r)
+					}
+				}()
+				return next(ctx, req)
+			}
+		},
+		Stream: func(next interfaces.StreamHandler) interfaces.StreamHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) (err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("model '%s' panicked: %v", req.Model, r)
+					}
+				}()
+				return next(ctx, req, callback)
+			}
+		},
+	}
+}
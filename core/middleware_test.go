@@ -0,0 +1,365 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/core/budget"
+	"github.com/ilkoid/PonchoAiFramework/core/config"
+	"github.com/ilkoid/PonchoAiFramework/core/usage"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/router"
+)
+
+// streamModel streams a single chunk and panics in Generate when asked to
+type streamModel struct {
+	*base.PonchoBaseModel
+	panic bool
+}
+
+func (m *streamModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	if m.panic {
+		panic("provider bug")
+	}
+	return &interfaces.PonchoModelResponse{Usage: &interfaces.PonchoUsage{}}, nil
+}
+
+func (m *streamModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	return callback(&interfaces.PonchoStreamChunk{
+		Delta: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "secret"}},
+		},
+		Done: true,
+	})
+}
+
+func newStreamModel(name string) *streamModel {
+	return &streamModel{
+		PonchoBaseModel: base.NewPonchoBaseModel(name, "test", interfaces.ModelCapabilities{Streaming: true}),
+	}
+}
+
+// traceMiddleware records its name when a unary call passes through it
+func traceMiddleware(name string, trace *[]string) *interfaces.Middleware {
+	return &interfaces.Middleware{
+		Name: name,
+		Unary: func(next interfaces.ModelHandler) interfaces.ModelHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+				*trace = append(*trace, name)
+				return next(ctx, req)
+			}
+		},
+	}
+}
+
+func writeMiddlewareConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func newMiddlewareFramework(t *testing.T, configPath string) *PonchoFrameworkImpl {
+	t.Helper()
+
+	logger := interfaces.NewNoOpLogger()
+	framework := NewPonchoFramework(nil, logger)
+	framework.SetConfigManager(config.NewConfigManager(config.ConfigOptions{
+		FilePaths: []string{configPath},
+		Logger:    logger,
+	}))
+
+	locator := framework.GetServiceLocator()
+	if err := locator.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize service locator: %v", err)
+	}
+	if err := locator.RegisterModelFactory("openai", &reloadModelFactory{}); err != nil {
+		t.Fatalf("Failed to register model factory: %v", err)
+	}
+
+	return framework
+}
+
+const middlewareConfig = `middleware: ["config_global"]
+models:
+  chat:
+    provider: "openai"
+    model_name: "middleware-chat"
+    api_key: "middleware-test-key-that-is-long-enough"
+    max_tokens: 1000
+    temperature: 0.5
+    timeout: "30s"
+    middleware: ["config_model"]
+`
+
+func TestMiddleware_ChainOrder(t *testing.T) {
+	framework := newMiddlewareFramework(t, writeMiddlewareConfig(t, middlewareConfig))
+
+	var trace []string
+	for _, name := range []string{"config_global", "config_model"} {
+		if err := framework.RegisterMiddleware(traceMiddleware(name, &trace)); err != nil {
+			t.Fatalf("Failed to register middleware: %v", err)
+		}
+	}
+	framework.UseModelMiddleware("chat", traceMiddleware("code_model", &trace))
+	framework.UseModelMiddleware("other", traceMiddleware("other_model", &trace))
+	framework.UseMiddleware(traceMiddleware("code_global", &trace))
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	if _, err := framework.Generate(ctx, &interfaces.PonchoModelRequest{Model: "chat"}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expected := "code_global,config_global,code_model,config_model"
+	if got := strings.Join(trace, ","); got != expected {
+		t.Errorf("Expected middleware order %s, got %s", expected, got)
+	}
+}
+
+func TestMiddleware_UnknownNameFailsStart(t *testing.T) {
+	framework := newMiddlewareFramework(t, writeMiddlewareConfig(t, middlewareConfig))
+
+	err := framework.Start(context.Background())
+	if err == nil {
+		framework.Stop(context.Background())
+		t.Fatal("Expected start to fail for unknown middleware")
+	}
+	if !strings.Contains(err.Error(), "unknown middleware") {
+		t.Errorf("Expected unknown middleware error, got: %v", err)
+	}
+}
+
+func TestMiddleware_Streaming(t *testing.T) {
+	framework := newHealthFramework(t)
+	if err := framework.RegisterModel("stream", newStreamModel("stream")); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+
+	// Redact streamed text before it reaches the caller
+	framework.UseMiddleware(&interfaces.Middleware{
+		Name: "redact",
+		Stream: func(next interfaces.StreamHandler) interfaces.StreamHandler {
+			return func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+				return next(ctx, req, func(chunk *interfaces.PonchoStreamChunk) error {
+					for _, part := range chunk.Delta.Content {
+						part.Text = strings.Repeat("*", len(part.Text))
+					}
+					return callback(chunk)
+				})
+			}
+		},
+	})
+
+	var received string
+	err := framework.GenerateStreaming(context.Background(), &interfaces.PonchoModelRequest{Model: "stream"},
+		func(chunk *interfaces.PonchoStreamChunk) error {
+			received += chunk.Delta.Content[0].Text
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("GenerateStreaming failed: %v", err)
+	}
+
+	if received != "******" {
+		t.Errorf("Expected redacted stream, got %q", received)
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	framework := newHealthFramework(t)
+	model := newStreamModel("panicky")
+	model.panic = true
+	if err := framework.RegisterModel("panicky", model); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+	framework.UseModelMiddleware("panicky", RecoveryMiddleware())
+
+	_, err := framework.Generate(context.Background(), &interfaces.PonchoModelRequest{Model: "panicky"})
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Errorf("Expected panic to be converted into an error, got: %v", err)
+	}
+}
+
+func TestResponseCache_FromFrameworkConfig(t *testing.T) {
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Cache: &interfaces.CacheConfig{Type: "memory", TTL: "1m", MaxSize: 10},
+	}, interfaces.NewNoOpLogger())
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	var trace []string
+	framework.UseMiddleware(traceMiddleware("outer", &trace))
+	if err := framework.RegisterModel("cached", newStreamModel("cached")); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := framework.Generate(ctx, &interfaces.PonchoModelRequest{Model: "cached"}); err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+	}
+
+	if len(trace) != 2 {
+		t.Errorf("Expected middlewares to run on cache hits, got %d calls", len(trace))
+	}
+
+	metrics, err := framework.Metrics(ctx)
+	if err != nil {
+		t.Fatalf("Metrics failed: %v", err)
+	}
+	if metrics.Cache == nil || metrics.Cache.Hits != 1 || metrics.Cache.Misses != 1 {
+		t.Errorf("Expected one cache hit and one miss, got %+v", metrics.Cache)
+	}
+}
+
+func TestUsageLedger_RecordsGenerateCalls(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "usage.jsonl")
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Usage: &interfaces.UsageConfig{Path: ledgerPath},
+	}, interfaces.NewNoOpLogger())
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	if err := framework.RegisterModel("chat", newStreamModel("chat")); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+
+	req := &interfaces.PonchoModelRequest{Model: "chat", Metadata: map[string]interface{}{usage.MetadataFlow: "article_importer"}}
+	if _, err := framework.Generate(ctx, req); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if err := framework.GenerateStreaming(ctx, req, func(*interfaces.PonchoStreamChunk) error { return nil }); err != nil {
+		t.Fatalf("GenerateStreaming failed: %v", err)
+	}
+
+	records, err := framework.UsageLedger().Query(usage.Filter{Flow: "article_importer"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Streaming || !records[1].Streaming {
+		t.Errorf("Expected a unary and a streaming record, got %+v", records)
+	}
+}
+
+// partialStreamModel reports usage and then fails the stream
+type partialStreamModel struct {
+	*streamModel
+}
+
+func (m *partialStreamModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if err := callback(&interfaces.PonchoStreamChunk{Usage: &interfaces.PonchoUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}); err != nil {
+		return err
+	}
+	return errors.New("connection reset")
+}
+
+func TestUsageLedger_RecordsFailedStreamUsage(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "usage.jsonl")
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Usage:   &interfaces.UsageConfig{Path: ledgerPath},
+		Budgets: &interfaces.BudgetConfig{Models: map[string]*interfaces.BudgetLimit{"chat": {MaxTokens: 1000}}},
+	}, interfaces.NewNoOpLogger())
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	if err := framework.RegisterModel("chat", &partialStreamModel{newStreamModel("chat")}); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+
+	req := &interfaces.PonchoModelRequest{Model: "chat"}
+	if err := framework.GenerateStreaming(ctx, req, func(*interfaces.PonchoStreamChunk) error { return nil }); err == nil {
+		t.Fatal("Expected the stream error")
+	}
+
+	records, err := framework.UsageLedger().Query(usage.Filter{Model: "chat"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 1 || !records[0].Partial || !records[0].Streaming || records[0].TotalTokens != 15 {
+		t.Fatalf("Expected one partial streaming record of 15 tokens, got %+v", records)
+	}
+
+	// Seeding budgets from the ledger charges what live accounting charged
+	seeded, err := budget.NewEnforcer(&interfaces.BudgetConfig{}, nil, interfaces.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("NewEnforcer failed: %v", err)
+	}
+	seeded.Seed(records)
+	live := framework.budgets.DailySpend(budget.ScopeModel, "chat")
+	if got := seeded.DailySpend(budget.ScopeModel, "chat"); got != live || live.Tokens != 15 {
+		t.Errorf("Seeded spend %+v, live spend %+v, want 15 tokens each", got, live)
+	}
+}
+
+func TestUsageLedger_RecordsRoutedCallsAgainstTarget(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "usage.jsonl")
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Usage: &interfaces.UsageConfig{Path: ledgerPath},
+	}, interfaces.NewNoOpLogger())
+
+	ctx := context.Background()
+	if err := framework.Start(ctx); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	defer framework.Stop(ctx)
+
+	if err := framework.RegisterModel("chat", newStreamModel("chat")); err != nil {
+		t.Fatalf("Failed to register model: %v", err)
+	}
+	routerModel, err := router.NewRouterModel("default", framework.GetModelRegistry(), router.Config{
+		Routes: []router.Route{{Model: "chat"}},
+	}, interfaces.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	if err := framework.RegisterModel("default", routerModel); err != nil {
+		t.Fatalf("Failed to register router: %v", err)
+	}
+
+	req := &interfaces.PonchoModelRequest{Model: "default", Metadata: map[string]interface{}{usage.MetadataFlow: "article_importer"}}
+	if _, err := framework.Generate(ctx, req); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if err := framework.GenerateStreaming(ctx, req, func(*interfaces.PonchoStreamChunk) error { return nil }); err != nil {
+		t.Fatalf("GenerateStreaming failed: %v", err)
+	}
+
+	records, err := framework.UsageLedger().Query(usage.Filter{Flow: "article_importer"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected one record per routed call, got %+v", records)
+	}
+	for _, record := range records {
+		if record.Model != "chat" {
+			t.Errorf("Expected the call to be recorded against the routed model, got %q", record.Model)
+		}
+	}
+}
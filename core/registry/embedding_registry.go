@@ -0,0 +1,123 @@
+package registry
+
+// This file implements the embedding model registry for the PonchoFramework.
+// It provides thread-safe storage of embedding models, kept apart from the
+// generation models so that names can overlap and capability queries stay simple.
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// PonchoEmbeddingModelRegistry is the implementation of the embedding model registry
+type PonchoEmbeddingModelRegistry struct {
+	models map[string]interfaces.PonchoEmbeddingModel
+	mutex  sync.RWMutex
+	logger interfaces.Logger
+}
+
+// NewPonchoEmbeddingModelRegistry creates a new embedding model registry
+func NewPonchoEmbeddingModelRegistry(logger interfaces.Logger) *PonchoEmbeddingModelRegistry {
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	return &PonchoEmbeddingModelRegistry{
+		models: make(map[string]interfaces.PonchoEmbeddingModel),
+		logger: logger,
+	}
+}
+
+// Register registers an embedding model with the registry
+func (r *PonchoEmbeddingModelRegistry) Register(name string, model interfaces.PonchoEmbeddingModel) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if name == "" {
+		return fmt.Errorf("embedding model name cannot be empty")
+	}
+
+	if model == nil {
+		return fmt.Errorf("embedding model cannot be nil")
+	}
+
+	if _, exists := r.models[name]; exists {
+		return fmt.Errorf("embedding model '%s' is already registered", name)
+	}
+
+	r.models[name] = model
+	r.logger.Info("Embedding model registered", "name", name, "provider", model.Provider())
+
+	return nil
+}
+
+// Get retrieves an embedding model from the registry
+func (r *PonchoEmbeddingModelRegistry) Get(name string) (interfaces.PonchoEmbeddingModel, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	model, exists := r.models[name]
+	if !exists {
+		return nil, fmt.Errorf("embedding model '%s' not found in registry", name)
+	}
+
+	return model, nil
+}
+
+// List returns a list of all registered embedding model names
+func (r *PonchoEmbeddingModelRegistry) List() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.models))
+	for name := range r.models {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Unregister removes an embedding model from the registry
+func (r *PonchoEmbeddingModelRegistry) Unregister(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.models[name]; !exists {
+		return fmt.Errorf("embedding model '%s' not found in registry", name)
+	}
+
+	delete(r.models, name)
+	r.logger.Info("Embedding model unregistered", "name", name)
+
+	return nil
+}
+
+// Clear removes all embedding models from the registry
+func (r *PonchoEmbeddingModelRegistry) Clear() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.models = make(map[string]interfaces.PonchoEmbeddingModel)
+	r.logger.Info("Embedding model registry cleared")
+
+	return nil
+}
+
+// Count returns the number of registered embedding models
+func (r *PonchoEmbeddingModelRegistry) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.models)
+}
+
+// Has checks if an embedding model is registered
+func (r *PonchoEmbeddingModelRegistry) Has(name string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	_, exists := r.models[name]
+	return exists
+}
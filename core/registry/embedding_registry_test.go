@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// MockEmbeddingModel is a mock implementation of PonchoEmbeddingModel for testing
+type MockEmbeddingModel struct {
+	name string
+}
+
+func (m *MockEmbeddingModel) Embed(ctx context.Context, inputs []string) ([][]float32, *interfaces.PonchoUsage, error) {
+	return make([][]float32, len(inputs)), &interfaces.PonchoUsage{}, nil
+}
+func (m *MockEmbeddingModel) Name() string      { return m.name }
+func (m *MockEmbeddingModel) Provider() string  { return "test-provider" }
+func (m *MockEmbeddingModel) Dimensions() int   { return 8 }
+func (m *MockEmbeddingModel) MaxBatchSize() int { return 16 }
+func (m *MockEmbeddingModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	return nil
+}
+func (m *MockEmbeddingModel) Shutdown(ctx context.Context) error { return nil }
+
+func TestEmbeddingModelRegistry(t *testing.T) {
+	registry := NewPonchoEmbeddingModelRegistry(&MockLogger{})
+	model := &MockEmbeddingModel{name: "embedder"}
+
+	if err := registry.Register("embedder", model); err != nil {
+		t.Fatalf("Failed to register valid embedding model: %v", err)
+	}
+	if err := registry.Register("embedder", model); err == nil {
+		t.Error("Expected error when registering duplicate embedding model, got nil")
+	}
+	if err := registry.Register("", model); err == nil {
+		t.Error("Expected error when registering embedding model with empty name, got nil")
+	}
+	if err := registry.Register("nil", nil); err == nil {
+		t.Error("Expected error when registering nil embedding model, got nil")
+	}
+
+	if got, err := registry.Get("embedder"); err != nil || got != model {
+		t.Errorf("Get() = %v, %v", got, err)
+	}
+	if names := registry.List(); len(names) != 1 || names[0] != "embedder" {
+		t.Errorf("List() = %v", names)
+	}
+
+	if err := registry.Unregister("embedder"); err != nil {
+		t.Errorf("Unregister() error = %v", err)
+	}
+	if registry.Has("embedder") || registry.Count() != 0 {
+		t.Error("Expected embedding model to be removed")
+	}
+	if err := registry.Unregister("embedder"); err == nil {
+		t.Error("Expected error when unregistering missing embedding model, got nil")
+	}
+}
@@ -9,16 +9,18 @@ import (
 
 // ModelFactoryRegistryImpl implements the ModelFactoryManager interface
 type ModelFactoryRegistryImpl struct {
-	factories map[string]interfaces.ModelFactory
-	logger    interfaces.Logger
-	mutex     sync.RWMutex
+	factories          map[string]interfaces.ModelFactory
+	embeddingFactories map[string]interfaces.EmbeddingModelFactory
+	logger             interfaces.Logger
+	mutex              sync.RWMutex
 }
 
 // NewModelFactoryRegistry creates a new model factory registry
 func NewModelFactoryRegistry(logger interfaces.Logger) *ModelFactoryRegistryImpl {
 	return &ModelFactoryRegistryImpl{
-		factories: make(map[string]interfaces.ModelFactory),
-		logger:    logger,
+		factories:          make(map[string]interfaces.ModelFactory),
+		embeddingFactories: make(map[string]interfaces.EmbeddingModelFactory),
+		logger:             logger,
 	}
 }
 
@@ -118,6 +120,79 @@ func (r *ModelFactoryRegistryImpl) ValidateConfig(config *interfaces.ModelConfig
 	return factory.ValidateConfig(config)
 }
 
+// RegisterEmbeddingFactory registers an embedding model factory
+func (r *ModelFactoryRegistryImpl) RegisterEmbeddingFactory(provider string, factory interfaces.EmbeddingModelFactory) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if provider == "" {
+		return fmt.Errorf("provider cannot be empty")
+	}
+
+	if factory == nil {
+		return fmt.Errorf("factory cannot be nil")
+	}
+
+	r.embeddingFactories[provider] = factory
+	r.logger.Info("Embedding model factory registered", "provider", provider)
+
+	return nil
+}
+
+// GetEmbeddingFactory returns an embedding factory for the specified provider
+func (r *ModelFactoryRegistryImpl) GetEmbeddingFactory(provider string) (interfaces.EmbeddingModelFactory, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	factory, exists := r.embeddingFactories[provider]
+	if !exists {
+		return nil, fmt.Errorf("no embedding factory registered for provider: %s", provider)
+	}
+
+	return factory, nil
+}
+
+// GetSupportedEmbeddingProviders returns list of supported embedding providers
+func (r *ModelFactoryRegistryImpl) GetSupportedEmbeddingProviders() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	providers := make([]string, 0, len(r.embeddingFactories))
+	for provider := range r.embeddingFactories {
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+// CreateEmbeddingModel creates an embedding model using the appropriate factory
+func (r *ModelFactoryRegistryImpl) CreateEmbeddingModel(config *interfaces.ModelConfig) (interfaces.PonchoEmbeddingModel, error) {
+	if config == nil {
+		return nil, fmt.Errorf("model config cannot be nil")
+	}
+
+	if config.Provider == "" {
+		return nil, fmt.Errorf("model provider is required")
+	}
+
+	factory, err := r.GetEmbeddingFactory(config.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding factory for provider %s: %w", config.Provider, err)
+	}
+
+	model, err := factory.CreateEmbeddingModel(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding model with factory %s: %w", config.Provider, err)
+	}
+
+	r.logger.Info("Embedding model created successfully",
+		"provider", config.Provider,
+		"model_name", config.ModelName,
+		"dimensions", model.Dimensions())
+
+	return model, nil
+}
+
 // ToolFactoryRegistryImpl implements the ToolFactoryManager interface
 type ToolFactoryRegistryImpl struct {
 	factories map[string]interfaces.ToolFactory
@@ -58,6 +58,27 @@ func (r *PonchoModelRegistry) Register(name string, model interfaces.PonchoModel
 	return nil
 }
 
+// Replace atomically swaps the model registered under name and returns the previous
+// instance. If no model was registered under name, the model is registered and nil is returned.
+func (r *PonchoModelRegistry) Replace(name string, model interfaces.PonchoModel) (interfaces.PonchoModel, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if name == "" {
+		return nil, fmt.Errorf("model name cannot be empty")
+	}
+
+	if model == nil {
+		return nil, fmt.Errorf("model cannot be nil")
+	}
+
+	previous := r.models[name]
+	r.models[name] = model
+	r.logger.Info("Model replaced", "name", name, "provider", model.Provider())
+
+	return previous, nil
+}
+
 // Get retrieves a model from the registry
 func (r *PonchoModelRegistry) Get(name string) (interfaces.PonchoModel, error) {
 	r.mutex.RLock()
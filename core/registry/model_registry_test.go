@@ -206,6 +206,39 @@ func TestModelRegistry_Unregister(t *testing.T) {
 	}
 }
 
+func TestModelRegistry_Replace(t *testing.T) {
+	registry := NewPonchoModelRegistry(&MockLogger{})
+	oldModel := NewMockModel("test-model", "test-provider", interfaces.ModelCapabilities{})
+	newModel := NewMockModel("test-model", "test-provider", interfaces.ModelCapabilities{Streaming: true})
+
+	// Replace on empty registry registers the model
+	previous, err := registry.Replace("test-model", oldModel)
+	if err != nil {
+		t.Fatalf("Failed to replace model: %v", err)
+	}
+	if previous != nil {
+		t.Error("Expected no previous model")
+	}
+
+	// Replace existing model returns the old instance
+	previous, err = registry.Replace("test-model", newModel)
+	if err != nil {
+		t.Fatalf("Failed to replace model: %v", err)
+	}
+	if previous != oldModel {
+		t.Error("Expected previous model to be returned")
+	}
+
+	current, _ := registry.Get("test-model")
+	if current != newModel {
+		t.Error("Expected registry to return the new model")
+	}
+
+	if _, err := registry.Replace("test-model", nil); err == nil {
+		t.Error("Expected error when replacing with nil model, got nil")
+	}
+}
+
 func TestModelRegistry_Clear(t *testing.T) {
 	registry := NewPonchoModelRegistry(&MockLogger{})
 
@@ -57,19 +57,38 @@ func (r *PonchoToolRegistry) Register(name string, tool interfaces.PonchoTool) e
 	}
 
 	r.tools[name] = tool
+	r.addToCategory(name, tool)
 
-	// Add to category map
-	category := tool.Category()
-	if category != "" {
-		if _, exists := r.categories[category]; !exists {
-			r.categories[category] = make([]string, 0)
-		}
-		r.categories[category] = append(r.categories[category], name)
+	r.logger.Info("Tool registered", "name", name, "category", tool.Category())
+
+	return nil
+}
+
+// Replace atomically swaps the tool registered under name and returns the previous
+// instance. If no tool was registered under name, the tool is registered and nil is returned.
+func (r *PonchoToolRegistry) Replace(name string, tool interfaces.PonchoTool) (interfaces.PonchoTool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if name == "" {
+		return nil, fmt.Errorf("tool name cannot be empty")
 	}
 
-	r.logger.Info("Tool registered", "name", name, "category", category)
+	if tool == nil {
+		return nil, fmt.Errorf("tool cannot be nil")
+	}
 
-	return nil
+	previous, exists := r.tools[name]
+	if exists {
+		r.removeFromCategory(name, previous)
+	}
+
+	r.tools[name] = tool
+	r.addToCategory(name, tool)
+
+	r.logger.Info("Tool replaced", "name", name, "category", tool.Category())
+
+	return previous, nil
 }
 
 // Get retrieves a tool from the registry
@@ -128,23 +147,7 @@ func (r *PonchoToolRegistry) Unregister(name string) error {
 		return fmt.Errorf("tool '%s' not found in registry", name)
 	}
 
-	// Remove from category map
-	category := tool.Category()
-	if category != "" {
-		if tools, exists := r.categories[category]; exists {
-			for i, toolName := range tools {
-				if toolName == name {
-					// Remove from slice
-					r.categories[category] = append(tools[:i], tools[i+1:]...)
-					break
-				}
-			}
-			// If category is empty, remove it
-			if len(r.categories[category]) == 0 {
-				delete(r.categories, category)
-			}
-		}
-	}
+	r.removeFromCategory(name, tool)
 
 	delete(r.tools, name)
 	r.logger.Info("Tool unregistered", "name", name)
@@ -230,6 +233,41 @@ func (r *PonchoToolRegistry) GetByDependency(dependency string) []interfaces.Pon
 	return result
 }
 
+// addToCategory adds a tool name to its category. Caller must hold the lock.
+func (r *PonchoToolRegistry) addToCategory(name string, tool interfaces.PonchoTool) {
+	category := tool.Category()
+	if category == "" {
+		return
+	}
+
+	if _, exists := r.categories[category]; !exists {
+		r.categories[category] = make([]string, 0)
+	}
+	r.categories[category] = append(r.categories[category], name)
+}
+
+// removeFromCategory removes a tool name from its category. Caller must hold the lock.
+func (r *PonchoToolRegistry) removeFromCategory(name string, tool interfaces.PonchoTool) {
+	category := tool.Category()
+	if category == "" {
+		return
+	}
+
+	if tools, exists := r.categories[category]; exists {
+		for i, toolName := range tools {
+			if toolName == name {
+				// Remove from slice
+				r.categories[category] = append(tools[:i], tools[i+1:]...)
+				break
+			}
+		}
+		// If category is empty, remove it
+		if len(r.categories[category]) == 0 {
+			delete(r.categories, category)
+		}
+	}
+}
+
 // SetLogger sets the logger for the registry
 func (r *PonchoToolRegistry) SetLogger(logger interfaces.Logger) {
 	r.mutex.Lock()
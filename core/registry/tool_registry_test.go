@@ -215,6 +215,30 @@ func TestToolRegistry_Unregister(t *testing.T) {
 	}
 }
 
+func TestToolRegistry_Replace(t *testing.T) {
+	registry := NewPonchoToolRegistry(&MockLogger{})
+	oldTool := NewMockTool("test-tool", "Test tool", "1.0.0", "old", []string{}, []string{})
+	newTool := NewMockTool("test-tool", "Test tool", "2.0.0", "new", []string{}, []string{})
+
+	registry.Register("test-tool", oldTool)
+
+	previous, err := registry.Replace("test-tool", newTool)
+	if err != nil {
+		t.Fatalf("Failed to replace tool: %v", err)
+	}
+	if previous != oldTool {
+		t.Error("Expected previous tool to be returned")
+	}
+
+	// Categories follow the new instance
+	if tools := registry.ListByCategory("old"); len(tools) != 0 {
+		t.Errorf("Expected old category to be empty, got %v", tools)
+	}
+	if tools := registry.ListByCategory("new"); len(tools) != 1 {
+		t.Errorf("Expected 1 tool in new category, got %v", tools)
+	}
+}
+
 func TestToolRegistry_Clear(t *testing.T) {
 	registry := NewPonchoToolRegistry(&MockLogger{})
 
@@ -0,0 +1,671 @@
+package core
+
+// Configuration hot reload for the PonchoFramework
+//
+// ReloadConfig re-reads the configuration files and applies the difference to the
+// running framework without a restart:
+// - models, embedding models and tools that were added to the configuration are
+//   created and registered
+// - models, embedding models and tools whose configuration changed (API key,
+//   temperature, ...) are recreated through the factory managers and atomically
+//   swapped in the registries
+// - models, embedding models and tools that were removed from the configuration
+//   are unregistered
+//
+// Replaced and removed instances are not shut down immediately. The inflightTracker
+// counts calls that hold an instance (Generate, GenerateStreaming, Embed, ExecuteTool) and
+// flows that started before the swap (ExecuteFlow, ExecuteFlowStreaming), and the
+// old instances are shut down only after those calls have finished.
+//
+// Only components created from configuration are managed by reload; components
+// registered manually via RegisterModel/RegisterTool are never touched.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// modelReplacer is implemented by model registries supporting atomic replacement
+type modelReplacer interface {
+	Replace(name string, model interfaces.PonchoModel) (interfaces.PonchoModel, error)
+}
+
+// toolReplacer is implemented by tool registries supporting atomic replacement
+type toolReplacer interface {
+	Replace(name string, tool interfaces.PonchoTool) (interfaces.PonchoTool, error)
+}
+
+// inflightTracker tracks in-flight calls per component instance and per reload epoch
+type inflightTracker struct {
+	mutex     sync.Mutex
+	instances map[interface{}]int
+	flows     map[uint64]int
+	epoch     uint64
+	changed   chan struct{}
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{
+		instances: make(map[interface{}]int),
+		flows:     make(map[uint64]int),
+		changed:   make(chan struct{}),
+	}
+}
+
+// acquire marks the instance as used and returns the release function
+func (t *inflightTracker) acquire(instance interface{}) func() {
+	t.mutex.Lock()
+	t.instances[instance]++
+	t.mutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+
+			t.instances[instance]--
+			if t.instances[instance] <= 0 {
+				delete(t.instances, instance)
+			}
+			t.notifyLocked()
+		})
+	}
+}
+
+// acquireFlow marks a flow execution in the current epoch and returns the release function.
+// Flows resolve models and tools through the registries themselves, so a flow started
+// before a swap may still use the old instances.
+func (t *inflightTracker) acquireFlow() func() {
+	t.mutex.Lock()
+	epoch := t.epoch
+	t.flows[epoch]++
+	t.mutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+
+			t.flows[epoch]--
+			if t.flows[epoch] <= 0 {
+				delete(t.flows, epoch)
+			}
+			t.notifyLocked()
+		})
+	}
+}
+
+// advance starts a new epoch and returns the previous one
+func (t *inflightTracker) advance() uint64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previous := t.epoch
+	t.epoch++
+	return previous
+}
+
+// wait blocks until the instances are no longer used and all flows started
+// in epochs up to and including epoch have finished
+func (t *inflightTracker) wait(ctx context.Context, instances []interface{}, epoch uint64) error {
+	for {
+		t.mutex.Lock()
+		idle := t.idleLocked(instances, epoch)
+		changed := t.changed
+		t.mutex.Unlock()
+
+		if idle {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// idleLocked reports whether nothing in-flight can use the instances. Caller must hold the lock.
+func (t *inflightTracker) idleLocked(instances []interface{}, epoch uint64) bool {
+	for _, instance := range instances {
+		if t.instances[instance] > 0 {
+			return false
+		}
+	}
+
+	for flowEpoch, count := range t.flows {
+		if flowEpoch <= epoch && count > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// notifyLocked wakes up waiters. Caller must hold the lock.
+func (t *inflightTracker) notifyLocked() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// compositeModel is implemented by virtual models that delegate to other registered
+// models (e.g. router.RouterModel). Their calls are drained like flow executions,
+// since the models they use are resolved from the registry during the call.
+type compositeModel interface {
+	Targets() []string
+}
+
+// dispatchingModel is a composite model that can run the calls to its targets
+// through the framework, so each is handled and recorded as a call to the target
+type dispatchingModel interface {
+	SetDispatcher(dispatcher common.Generator)
+}
+
+// acquireModel resolves a model and marks it in-flight until release is called
+func (pf *PonchoFrameworkImpl) acquireModel(name string) (interfaces.PonchoModel, func(), error) {
+	pf.swapMutex.RLock()
+	defer pf.swapMutex.RUnlock()
+
+	model, err := pf.modelRegistry.Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := pf.inflight.acquire(model)
+	if _, ok := model.(compositeModel); ok {
+		releaseModel, releaseFlow := release, pf.inflight.acquireFlow()
+		release = func() {
+			releaseFlow()
+			releaseModel()
+		}
+	}
+
+	return model, release, nil
+}
+
+// acquireEmbeddingModel resolves an embedding model and marks it in-flight until release is called
+func (pf *PonchoFrameworkImpl) acquireEmbeddingModel(name string) (interfaces.PonchoEmbeddingModel, func(), error) {
+	pf.swapMutex.RLock()
+	defer pf.swapMutex.RUnlock()
+
+	model, err := pf.embeddingRegistry.Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return model, pf.inflight.acquire(model), nil
+}
+
+// acquireTool resolves a tool and marks it in-flight until release is called
+func (pf *PonchoFrameworkImpl) acquireTool(name string) (interfaces.PonchoTool, func(), error) {
+	pf.swapMutex.RLock()
+	defer pf.swapMutex.RUnlock()
+
+	tool, err := pf.toolRegistry.Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tool, pf.inflight.acquire(tool), nil
+}
+
+// acquireFlow resolves a flow and marks a flow execution in-flight until release is called
+func (pf *PonchoFrameworkImpl) acquireFlow(name string) (interfaces.PonchoFlow, func(), error) {
+	pf.swapMutex.RLock()
+	defer pf.swapMutex.RUnlock()
+
+	flow, err := pf.flowRegistry.Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return flow, pf.inflight.acquireFlow(), nil
+}
+
+// reloadPlan describes the changes to apply to the registries
+type reloadPlan struct {
+	modelConfigs     map[string]*interfaces.ModelConfig
+	toolConfigs      map[string]*interfaces.ToolConfig
+	embeddingConfigs map[string]*interfaces.ModelConfig
+
+	models            map[string]interfaces.PonchoModel          // added or changed
+	tools             map[string]interfaces.PonchoTool           // added or changed
+	embeddings        map[string]interfaces.PonchoEmbeddingModel // added or changed
+	removedModels     []string
+	removedTools      []string
+	removedEmbeddings []string
+}
+
+// empty reports whether the plan has no changes
+func (p *reloadPlan) empty() bool {
+	return len(p.models) == 0 && len(p.tools) == 0 && len(p.embeddings) == 0 &&
+		len(p.removedModels) == 0 && len(p.removedTools) == 0 && len(p.removedEmbeddings) == 0
+}
+
+// applyConfig diffs the current configuration of the config manager against the
+// running components and swaps changed instances. Callers hold reloadMutex, so
+// reloads requested by ReloadConfig and by the watcher are applied one at a time.
+//
+// A component whose swap fails keeps running on its previous instance and its
+// previous configuration stays recorded, so the next reload tries it again.
+func (pf *PonchoFrameworkImpl) applyConfig(ctx context.Context) error {
+	if !pf.isStarted() {
+		return fmt.Errorf("framework is not started")
+	}
+
+	pf.appliedConfig = pf.configManager.GetConfig()
+
+	plan, err := pf.buildReloadPlan()
+	if err != nil {
+		return err
+	}
+
+	if plan.empty() {
+		pf.logger.Info("Configuration reloaded, no component changes")
+		pf.swapMutex.Lock()
+		pf.recordConfigs(plan)
+		pf.swapMutex.Unlock()
+		return nil
+	}
+
+	// Swap registry entries atomically with respect to acquireModel/acquireTool
+	pf.swapMutex.Lock()
+	retired := make([]interface{}, 0, len(plan.models)+len(plan.tools)+len(plan.embeddings)+
+		len(plan.removedModels)+len(plan.removedTools)+len(plan.removedEmbeddings))
+	var failed []interface{}
+	var swapErrs []error
+
+	for name, model := range plan.models {
+		previous, err := pf.replaceModel(name, model)
+		if err != nil {
+			pf.logger.Error("Failed to swap model", "name", name, "error", err)
+			swapErrs = append(swapErrs, fmt.Errorf("model %s: %w", name, err))
+			failed = append(failed, model)
+			keepRecordedConfig(plan.modelConfigs, pf.modelConfigs, name)
+			continue
+		}
+		if previous != nil {
+			retired = append(retired, previous)
+		}
+	}
+
+	for name, tool := range plan.tools {
+		previous, err := pf.replaceTool(name, tool)
+		if err != nil {
+			pf.logger.Error("Failed to swap tool", "name", name, "error", err)
+			swapErrs = append(swapErrs, fmt.Errorf("tool %s: %w", name, err))
+			failed = append(failed, tool)
+			keepRecordedConfig(plan.toolConfigs, pf.toolConfigs, name)
+			continue
+		}
+		if previous != nil {
+			retired = append(retired, previous)
+		}
+	}
+
+	for name, model := range plan.embeddings {
+		previous, err := pf.replaceEmbeddingModel(name, model)
+		if err != nil {
+			pf.logger.Error("Failed to swap embedding model", "name", name, "error", err)
+			swapErrs = append(swapErrs, fmt.Errorf("embedding model %s: %w", name, err))
+			failed = append(failed, model)
+			keepRecordedConfig(plan.embeddingConfigs, pf.embeddingConfigs, name)
+			continue
+		}
+		if previous != nil {
+			retired = append(retired, previous)
+		}
+	}
+
+	for _, name := range plan.removedModels {
+		if model, err := pf.modelRegistry.Get(name); err == nil {
+			if err := pf.modelRegistry.Unregister(name); err == nil {
+				retired = append(retired, model)
+			}
+		}
+	}
+
+	for _, name := range plan.removedTools {
+		if tool, err := pf.toolRegistry.Get(name); err == nil {
+			if err := pf.toolRegistry.Unregister(name); err == nil {
+				retired = append(retired, tool)
+			}
+		}
+	}
+
+	for _, name := range plan.removedEmbeddings {
+		if model, err := pf.embeddingRegistry.Get(name); err == nil {
+			if err := pf.embeddingRegistry.Unregister(name); err == nil {
+				retired = append(retired, model)
+			}
+		}
+	}
+
+	pf.recordConfigs(plan)
+	epoch := pf.inflight.advance()
+	pf.swapMutex.Unlock()
+
+	// Instances that were never swapped in are not used by anyone
+	pf.shutdownInstances(ctx, failed)
+
+	pf.logger.Info("Configuration reloaded",
+		"models_updated", len(plan.models),
+		"tools_updated", len(plan.tools),
+		"embedding_models_updated", len(plan.embeddings),
+		"models_removed", len(plan.removedModels),
+		"tools_removed", len(plan.removedTools),
+		"embedding_models_removed", len(plan.removedEmbeddings),
+		"failed", len(failed))
+
+	var swapErr error
+	if len(swapErrs) > 0 {
+		swapErr = fmt.Errorf("configuration partially applied: %w", errors.Join(swapErrs...))
+	}
+
+	// Let in-flight calls finish on the old instances before shutting them down
+	if err := pf.inflight.wait(ctx, retired, epoch); err != nil {
+		pf.logger.Warn("Timed out waiting for in-flight calls, old instances will be shut down when idle",
+			"count", len(retired), "error", err)
+		go pf.retireWhenIdle(retired, epoch)
+		return errors.Join(swapErr, fmt.Errorf("configuration applied, but waiting for in-flight calls failed: %w", err))
+	}
+
+	pf.shutdownInstances(ctx, retired)
+	return swapErr
+}
+
+// recordConfigs records the configurations of the plan as applied and refreshes
+// the framework configuration returned by GetConfig. Caller must hold swapMutex.
+func (pf *PonchoFrameworkImpl) recordConfigs(plan *reloadPlan) {
+	pf.modelConfigs = plan.modelConfigs
+	pf.toolConfigs = plan.toolConfigs
+	pf.embeddingConfigs = plan.embeddingConfigs
+
+	// Models and tools registered manually keep their entries
+	updated := *pf.config
+	updated.Models = make(map[string]*interfaces.ModelConfig, len(pf.config.Models)+len(plan.modelConfigs))
+	for name, modelConfig := range pf.config.Models {
+		updated.Models[name] = modelConfig
+	}
+	for _, name := range plan.removedModels {
+		delete(updated.Models, name)
+	}
+	for name, modelConfig := range plan.modelConfigs {
+		updated.Models[name] = modelConfig
+	}
+
+	updated.Tools = make(map[string]*interfaces.ToolConfig, len(pf.config.Tools)+len(plan.toolConfigs))
+	for name, toolConfig := range pf.config.Tools {
+		updated.Tools[name] = toolConfig
+	}
+	for _, name := range plan.removedTools {
+		delete(updated.Tools, name)
+	}
+	for name, toolConfig := range plan.toolConfigs {
+		updated.Tools[name] = toolConfig
+	}
+
+	pf.config = &updated
+}
+
+// keepRecordedConfig puts back the recorded configuration of a component whose
+// swap failed, or drops it when the component was new
+func keepRecordedConfig[T any](configs, recorded map[string]*T, name string) {
+	if previous, exists := recorded[name]; exists {
+		configs[name] = previous
+	} else {
+		delete(configs, name)
+	}
+}
+
+// buildReloadPlan creates new instances for added and changed components.
+// If any instance cannot be created, the instances created so far are shut down
+// and nothing is swapped.
+func (pf *PonchoFrameworkImpl) buildReloadPlan() (plan *reloadPlan, err error) {
+	plan = &reloadPlan{
+		models:     make(map[string]interfaces.PonchoModel),
+		tools:      make(map[string]interfaces.PonchoTool),
+		embeddings: make(map[string]interfaces.PonchoEmbeddingModel),
+	}
+
+	defer func() {
+		if err != nil {
+			created := make([]interface{}, 0, len(plan.models)+len(plan.tools)+len(plan.embeddings))
+			for _, model := range plan.models {
+				created = append(created, model)
+			}
+			for _, tool := range plan.tools {
+				created = append(created, tool)
+			}
+			for _, model := range plan.embeddings {
+				created = append(created, model)
+			}
+			pf.shutdownInstances(context.Background(), created)
+		}
+	}()
+
+	plan.modelConfigs, err = pf.configManager.GetModelConfigs()
+	if err != nil {
+		return plan, fmt.Errorf("failed to get model configurations: %w", err)
+	}
+
+	if err := pf.validateMiddlewareConfig(plan.modelConfigs); err != nil {
+		return plan, fmt.Errorf("invalid middleware configuration: %w", err)
+	}
+
+	if err := pf.validateResilienceConfig(plan.modelConfigs); err != nil {
+		return plan, fmt.Errorf("invalid resilience configuration: %w", err)
+	}
+
+	plan.toolConfigs, err = pf.resolveToolConfigs()
+	if err != nil {
+		return plan, err
+	}
+
+	plan.embeddingConfigs, err = pf.embeddingModelConfigs()
+	if err != nil {
+		return plan, err
+	}
+
+	if err := pf.validateResilienceConfig(plan.embeddingConfigs); err != nil {
+		return plan, fmt.Errorf("invalid resilience configuration: %w", err)
+	}
+
+	for name, modelConfig := range plan.modelConfigs {
+		if previous, exists := pf.modelConfigs[name]; exists && reflect.DeepEqual(previous, modelConfig) {
+			continue
+		}
+
+		model, err := pf.createModel(modelConfig)
+		if err != nil {
+			return plan, fmt.Errorf("failed to create model %s: %w", name, err)
+		}
+		plan.models[name] = model
+	}
+
+	for name := range pf.modelConfigs {
+		if _, exists := plan.modelConfigs[name]; !exists {
+			plan.removedModels = append(plan.removedModels, name)
+		}
+	}
+
+	for name, toolConfig := range plan.toolConfigs {
+		if previous, exists := pf.toolConfigs[name]; exists && reflect.DeepEqual(previous, toolConfig) {
+			continue
+		}
+
+		tool, err := pf.createTool(name, toolConfig)
+		if err != nil {
+			return plan, fmt.Errorf("failed to create tool %s: %w", name, err)
+		}
+		plan.tools[name] = tool
+	}
+
+	for name := range pf.toolConfigs {
+		if _, exists := plan.toolConfigs[name]; !exists {
+			plan.removedTools = append(plan.removedTools, name)
+		}
+	}
+
+	for name, modelConfig := range plan.embeddingConfigs {
+		if previous, exists := pf.embeddingConfigs[name]; exists && reflect.DeepEqual(previous, modelConfig) {
+			continue
+		}
+
+		model, err := pf.createEmbeddingModel(modelConfig)
+		if err != nil {
+			return plan, fmt.Errorf("failed to create embedding model %s: %w", name, err)
+		}
+		plan.embeddings[name] = model
+	}
+
+	for name := range pf.embeddingConfigs {
+		if _, exists := plan.embeddingConfigs[name]; !exists {
+			plan.removedEmbeddings = append(plan.removedEmbeddings, name)
+		}
+	}
+
+	return plan, nil
+}
+
+// createModel creates a model instance through the model factory manager
+func (pf *PonchoFrameworkImpl) createModel(modelConfig *interfaces.ModelConfig) (interfaces.PonchoModel, error) {
+	factoryManager := pf.GetModelFactoryManager()
+	if factoryManager == nil {
+		return nil, fmt.Errorf("model factory manager is not available")
+	}
+
+	return factoryManager.CreateModel(modelConfig)
+}
+
+// createTool creates a tool instance through the tool factory manager
+func (pf *PonchoFrameworkImpl) createTool(name string, toolConfig *interfaces.ToolConfig) (interfaces.PonchoTool, error) {
+	factoryManager := pf.serviceLocator.GetToolFactoryManager()
+	if factoryManager == nil {
+		return nil, fmt.Errorf("tool factory manager is not available")
+	}
+
+	return factoryManager.CreateTool(toolConfig, toolType(name, toolConfig))
+}
+
+// resolveToolConfigs returns the configurations of enabled tools that have a registered factory.
+// Tools without a factory are expected to be registered manually and are skipped.
+func (pf *PonchoFrameworkImpl) resolveToolConfigs() (map[string]*interfaces.ToolConfig, error) {
+	toolConfigs, err := pf.configManager.GetToolConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tool configurations: %w", err)
+	}
+
+	var factoryManager interfaces.ToolFactoryManager
+	if pf.serviceLocator != nil {
+		factoryManager = pf.serviceLocator.GetToolFactoryManager()
+	}
+
+	resolved := make(map[string]*interfaces.ToolConfig)
+	for name, toolConfig := range toolConfigs {
+		if !toolConfig.Enabled || factoryManager == nil {
+			continue
+		}
+
+		if _, err := factoryManager.GetFactory(toolType(name, toolConfig)); err != nil {
+			pf.logger.Debug("No factory for configured tool, skipping", "name", name)
+			continue
+		}
+
+		resolved[name] = toolConfig
+	}
+
+	return resolved, nil
+}
+
+// toolType returns the factory type of a configured tool: custom_params.type or the tool name
+func toolType(name string, toolConfig *interfaces.ToolConfig) string {
+	if toolConfig.CustomParams != nil {
+		if tt, ok := toolConfig.CustomParams["type"].(string); ok && tt != "" {
+			return tt
+		}
+	}
+	return name
+}
+
+// replaceModel swaps a model in the registry and returns the previous instance
+func (pf *PonchoFrameworkImpl) replaceModel(name string, model interfaces.PonchoModel) (interfaces.PonchoModel, error) {
+	if replacer, ok := pf.modelRegistry.(modelReplacer); ok {
+		return replacer.Replace(name, model)
+	}
+
+	// Fallback for registries without Replace; still atomic for framework calls
+	// because the swap mutex is held
+	previous, err := pf.modelRegistry.Get(name)
+	if err == nil {
+		_ = pf.modelRegistry.Unregister(name)
+	} else {
+		previous = nil
+	}
+
+	return previous, pf.modelRegistry.Register(name, model)
+}
+
+// replaceTool swaps a tool in the registry and returns the previous instance
+func (pf *PonchoFrameworkImpl) replaceTool(name string, tool interfaces.PonchoTool) (interfaces.PonchoTool, error) {
+	if replacer, ok := pf.toolRegistry.(toolReplacer); ok {
+		return replacer.Replace(name, tool)
+	}
+
+	previous, err := pf.toolRegistry.Get(name)
+	if err == nil {
+		_ = pf.toolRegistry.Unregister(name)
+	} else {
+		previous = nil
+	}
+
+	return previous, pf.toolRegistry.Register(name, tool)
+}
+
+// replaceEmbeddingModel swaps an embedding model in the registry and returns the previous instance
+func (pf *PonchoFrameworkImpl) replaceEmbeddingModel(name string, model interfaces.PonchoEmbeddingModel) (interfaces.PonchoEmbeddingModel, error) {
+	previous, err := pf.embeddingRegistry.Get(name)
+	if err == nil {
+		_ = pf.embeddingRegistry.Unregister(name)
+	} else {
+		previous = nil
+	}
+
+	return previous, pf.embeddingRegistry.Register(name, model)
+}
+
+// retireWhenIdle waits without a deadline and shuts down the instances once idle
+func (pf *PonchoFrameworkImpl) retireWhenIdle(instances []interface{}, epoch uint64) {
+	if err := pf.inflight.wait(context.Background(), instances, epoch); err != nil {
+		return
+	}
+	pf.shutdownInstances(context.Background(), instances)
+}
+
+// shutdownInstances shuts down retired models, embedding models and tools
+func (pf *PonchoFrameworkImpl) shutdownInstances(ctx context.Context, instances []interface{}) {
+	for _, instance := range instances {
+		var err error
+		switch component := instance.(type) {
+		case interfaces.PonchoModel:
+			err = component.Shutdown(ctx)
+		case interfaces.PonchoTool:
+			err = component.Shutdown(ctx)
+		case interfaces.PonchoEmbeddingModel:
+			err = component.Shutdown(ctx)
+		}
+
+		if err != nil {
+			pf.logger.Warn("Failed to shutdown retired component", "error", err)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/core/config"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// reloadModel records its configuration and shutdown, and can block in Generate
+type reloadModel struct {
+	*base.PonchoBaseModel
+	temperature float32
+	shutdown    atomic.Bool
+	entered     chan struct{}
+	release     chan struct{}
+}
+
+func (m *reloadModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	if m.entered != nil {
+		close(m.entered)
+		<-m.release
+	}
+
+	if m.shutdown.Load() {
+		return nil, fmt.Errorf("model used after shutdown")
+	}
+
+	return &interfaces.PonchoModelResponse{
+		Message: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "ok"}},
+		},
+		Usage: &interfaces.PonchoUsage{TotalTokens: 1},
+	}, nil
+}
+
+func (m *reloadModel) Shutdown(ctx context.Context) error {
+	m.shutdown.Store(true)
+	return nil
+}
+
+type reloadModelFactory struct {
+	mutex   sync.Mutex
+	created []*reloadModel
+}
+
+func (f *reloadModelFactory) CreateModel(cfg *interfaces.ModelConfig) (interfaces.PonchoModel, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	model := &reloadModel{
+		PonchoBaseModel: base.NewPonchoBaseModel(cfg.ModelName, "reload", interfaces.ModelCapabilities{}),
+		temperature:     cfg.Temperature,
+	}
+	f.created = append(f.created, model)
+	return model, nil
+}
+
+func (f *reloadModelFactory) ValidateConfig(cfg *interfaces.ModelConfig) error { return nil }
+func (f *reloadModelFactory) GetProvider() string                              { return "openai" }
+
+type reloadTool struct {
+	*base.PonchoBaseTool
+	shutdown atomic.Bool
+}
+
+func (t *reloadTool) Shutdown(ctx context.Context) error {
+	t.shutdown.Store(true)
+	return nil
+}
+
+type reloadToolFactory struct{}
+
+func (f *reloadToolFactory) CreateTool(cfg *interfaces.ToolConfig) (interfaces.PonchoTool, error) {
+	return &reloadTool{PonchoBaseTool: base.NewPonchoBaseTool("echo", "Echo tool", "1.0.0", "test")}, nil
+}
+
+func (f *reloadToolFactory) ValidateConfig(cfg *interfaces.ToolConfig) error { return nil }
+func (f *reloadToolFactory) GetToolType() string                             { return "echo_tool" }
+
+func writeReloadConfig(t *testing.T, path string, temperature float64, withTool bool) {
+	t.Helper()
+
+	content := fmt.Sprintf(`models:
+  chat:
+    provider: "openai"
+    model_name: "reload-chat"
+    api_key: "reload-test-key-that-is-long-enough"
+    max_tokens: 1000
+    temperature: %v
+    timeout: "30s"
+`, temperature)
+
+	if withTool {
+		content += `tools:
+  echo:
+    enabled: true
+    timeout: "10s"
+    custom_params:
+      type: "echo_tool"
+  manual_only:
+    enabled: true
+    timeout: "10s"
+`
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func newReloadFramework(t *testing.T, configPath string) (*PonchoFrameworkImpl, *reloadModelFactory) {
+	t.Helper()
+
+	logger := interfaces.NewNoOpLogger()
+	framework := NewPonchoFramework(nil, logger)
+	framework.SetConfigManager(config.NewConfigManager(config.ConfigOptions{
+		FilePaths:     []string{configPath},
+		Logger:        logger,
+		WatchInterval: 10 * time.Millisecond,
+	}))
+
+	locator := framework.GetServiceLocator()
+	if err := locator.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize service locator: %v", err)
+	}
+
+	factory := &reloadModelFactory{}
+	if err := locator.RegisterModelFactory("openai", factory); err != nil {
+		t.Fatalf("Failed to register model factory: %v", err)
+	}
+	if err := locator.RegisterToolFactory("echo_tool", &reloadToolFactory{}); err != nil {
+		t.Fatalf("Failed to register tool factory: %v", err)
+	}
+
+	if err := framework.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	t.Cleanup(func() { _ = framework.Stop(context.Background()) })
+
+	return framework, factory
+}
+
+func TestReloadConfig_SwapsChangedModelAfterInflightCalls(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeReloadConfig(t, configPath, 0.5, true)
+
+	framework, _ := newReloadFramework(t, configPath)
+
+	current, err := framework.GetModelRegistry().Get("chat")
+	if err != nil {
+		t.Fatalf("Expected model from configuration: %v", err)
+	}
+	oldModel := current.(*reloadModel)
+	oldModel.entered = make(chan struct{})
+	oldModel.release = make(chan struct{})
+
+	// Start a call that stays in-flight on the old instance
+	generateDone := make(chan error, 1)
+	go func() {
+		_, err := framework.Generate(context.Background(), &interfaces.PonchoModelRequest{Model: "chat"})
+		generateDone <- err
+	}()
+	<-oldModel.entered
+
+	writeReloadConfig(t, configPath, 0.9, true)
+
+	reloadDone := make(chan error, 1)
+	go func() { reloadDone <- framework.ReloadConfig(context.Background()) }()
+
+	// New calls must see the new instance while the old call is still running
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		current, _ = framework.GetModelRegistry().Get("chat")
+		if current != oldModel {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Model was not swapped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	newModel := current.(*reloadModel)
+	if newModel.temperature != 0.9 {
+		t.Errorf("Expected new model temperature 0.9, got %v", newModel.temperature)
+	}
+	if oldModel.shutdown.Load() {
+		t.Error("Old model must not be shut down while a call is in-flight")
+	}
+
+	close(oldModel.release)
+
+	if err := <-generateDone; err != nil {
+		t.Errorf("In-flight call failed: %v", err)
+	}
+	if err := <-reloadDone; err != nil {
+		t.Errorf("ReloadConfig failed: %v", err)
+	}
+	if !oldModel.shutdown.Load() {
+		t.Error("Expected old model to be shut down after in-flight call finished")
+	}
+	if newModel.shutdown.Load() {
+		t.Error("New model must not be shut down")
+	}
+}
+
+func TestReloadConfig_UnchangedAndRemovedComponents(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeReloadConfig(t, configPath, 0.5, true)
+
+	framework, factory := newReloadFramework(t, configPath)
+
+	tool, err := framework.GetToolRegistry().Get("echo")
+	if err != nil {
+		t.Fatalf("Expected tool from configuration: %v", err)
+	}
+	if _, err := framework.GetToolRegistry().Get("manual_only"); err == nil {
+		t.Error("Tools without a factory must not be created from configuration")
+	}
+
+	// Unchanged configuration keeps the running instances
+	if err := framework.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if len(factory.created) != 1 {
+		t.Errorf("Expected unchanged model to be kept, factory created %d models", len(factory.created))
+	}
+
+	// Removing the tool unregisters and shuts it down
+	writeReloadConfig(t, configPath, 0.5, false)
+	if err := framework.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if _, err := framework.GetToolRegistry().Get("echo"); err == nil {
+		t.Error("Expected removed tool to be unregistered")
+	}
+	if !tool.(*reloadTool).shutdown.Load() {
+		t.Error("Expected removed tool to be shut down")
+	}
+}
+
+// failingSwapRegistry rejects replacing models while fail is set
+type failingSwapRegistry struct {
+	interfaces.PonchoModelRegistry
+	fail atomic.Bool
+}
+
+func (r *failingSwapRegistry) Replace(name string, model interfaces.PonchoModel) (interfaces.PonchoModel, error) {
+	if r.fail.Load() {
+		return nil, fmt.Errorf("registry is read-only")
+	}
+	return r.PonchoModelRegistry.(modelReplacer).Replace(name, model)
+}
+
+func TestReloadConfig_FailedSwapKeepsPreviousModel(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeReloadConfig(t, configPath, 0.5, false)
+
+	framework, factory := newReloadFramework(t, configPath)
+	registry := &failingSwapRegistry{PonchoModelRegistry: framework.modelRegistry}
+	registry.fail.Store(true)
+	framework.modelRegistry = registry
+	before, _ := framework.GetModelRegistry().Get("chat")
+
+	if err := framework.WatchConfig(context.Background()); err != nil {
+		t.Fatalf("WatchConfig failed: %v", err)
+	}
+
+	// Keep the modification time, so only ReloadConfig picks up the change
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatalf("Failed to stat config: %v", err)
+	}
+	writeReloadConfig(t, configPath, 0.2, false)
+	if err := os.Chtimes(configPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Failed to touch config: %v", err)
+	}
+
+	if err := framework.ReloadConfig(context.Background()); err == nil {
+		t.Fatal("Expected error for failed swap")
+	}
+
+	// Give the watcher notification of the reload a chance to run
+	time.Sleep(50 * time.Millisecond)
+
+	factory.mutex.Lock()
+	created := append([]*reloadModel(nil), factory.created...)
+	factory.mutex.Unlock()
+	if len(created) != 2 {
+		t.Fatalf("Expected the change to be applied once, factory created %d models", len(created))
+	}
+	if !created[1].shutdown.Load() {
+		t.Error("Expected the model that failed to swap in to be shut down")
+	}
+	if after, _ := framework.GetModelRegistry().Get("chat"); after != before || before.(*reloadModel).shutdown.Load() {
+		t.Error("Expected the previous model to keep running")
+	}
+	if temperature := framework.GetConfig().Models["chat"].Temperature; temperature != 0.5 {
+		t.Errorf("Expected the previous configuration to stay recorded, got temperature %v", temperature)
+	}
+
+	// The next reload tries the change again
+	registry.fail.Store(false)
+	if err := framework.ReloadConfig(context.Background()); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if after, _ := framework.GetModelRegistry().Get("chat"); after.(*reloadModel).temperature != 0.2 {
+		t.Error("Expected the changed model to be swapped in on the next reload")
+	}
+	if temperature := framework.GetConfig().Models["chat"].Temperature; temperature != 0.2 {
+		t.Errorf("Expected the applied configuration to be recorded, got temperature %v", temperature)
+	}
+}
+
+func TestReloadConfig_InvalidConfigKeepsRunningModels(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeReloadConfig(t, configPath, 0.5, false)
+
+	framework, _ := newReloadFramework(t, configPath)
+	before, _ := framework.GetModelRegistry().Get("chat")
+
+	if err := os.WriteFile(configPath, []byte("models: [broken"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if err := framework.ReloadConfig(context.Background()); err == nil {
+		t.Error("Expected error for invalid configuration")
+	}
+
+	after, err := framework.GetModelRegistry().Get("chat")
+	if err != nil || after != before {
+		t.Error("Expected running model to be kept after failed reload")
+	}
+	if framework.GetConfigManager().GetString("models.chat.model_name") != "reload-chat" {
+		t.Error("Expected previous configuration to be kept after failed reload")
+	}
+}
+
+func TestWatchConfig_AppliesFileChanges(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeReloadConfig(t, configPath, 0.5, false)
+
+	framework, _ := newReloadFramework(t, configPath)
+	if err := framework.WatchConfig(context.Background()); err != nil {
+		t.Fatalf("WatchConfig failed: %v", err)
+	}
+
+	writeReloadConfig(t, configPath, 0.2, false)
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(configPath, future, future); err != nil {
+		t.Fatalf("Failed to touch config: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		current, _ := framework.GetModelRegistry().Get("chat")
+		if current.(*reloadModel).temperature == 0.2 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Watched configuration change was not applied")
+}
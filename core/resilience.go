@@ -0,0 +1,360 @@
+package core
+
+// Retry and circuit breaker policies for model calls
+//
+// A model's "retry" and "circuit_breaker" config sections (or SetResiliencePolicy)
+// guard every Generate and the setup of every GenerateStreaming call:
+//
+//	models:
+//	  glm:
+//	    provider: zai
+//	    model_name: glm-4.6
+//	    retry:
+//	      max_attempts: 4
+//	      backoff: exponential
+//	      base_delay: 2s
+//	      max_delay: 1m
+//	    circuit_breaker:
+//	      max_failures: 5
+//	      reset_timeout: 30s
+//
+// The guard is the innermost handler, so middleware (cache, budgets, ...) runs once
+// per call while only the provider call is retried. Only retryable ModelErrors are
+// retried and counted by the breaker; a provider's Retry-After replaces a shorter
+// backoff delay. With a retry policy the provider's own HTTP retries are disabled,
+// leaving a single retry layer. Streams are retried only until the first chunk
+// reaches the caller.
+//
+// Circuit state is reported in Health() as the "circuit_state" detail of the model
+// (an open circuit makes the model unhealthy) and in Metrics() under Resilience.
+// Models without a policy are called directly, as before.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// ResiliencePolicy is the retry and circuit breaker policy of a model.
+// A nil section disables that part of the policy.
+type ResiliencePolicy struct {
+	Retry          *interfaces.RetryConfig
+	CircuitBreaker *interfaces.CircuitBreakerConfig
+}
+
+// empty reports whether the policy guards nothing
+func (p ResiliencePolicy) empty() bool {
+	return p.Retry == nil && p.CircuitBreaker == nil
+}
+
+// resilienceGuard applies the policy of one model
+type resilienceGuard struct {
+	policy  ResiliencePolicy
+	retry   *common.RetryExecutor  // nil without a retry policy
+	breaker *common.CircuitBreaker // nil without a circuit breaker
+
+	retries    int64
+	opens      int64
+	rejections int64
+}
+
+// resilienceSet holds the guards of all models
+type resilienceSet struct {
+	mutex     sync.Mutex
+	guards    map[string]*resilienceGuard
+	overrides map[string]ResiliencePolicy
+}
+
+func newResilienceSet() *resilienceSet {
+	return &resilienceSet{
+		guards:    make(map[string]*resilienceGuard),
+		overrides: make(map[string]ResiliencePolicy),
+	}
+}
+
+// SetResiliencePolicy sets the retry and circuit breaker policy of a model,
+// overriding its configuration. A nil policy restores the configured one.
+func (pf *PonchoFrameworkImpl) SetResiliencePolicy(model string, policy *ResiliencePolicy) error {
+	if policy != nil {
+		if _, err := newResilienceGuard(model, *policy, pf.logger); err != nil {
+			return err
+		}
+	}
+
+	pf.resilience.mutex.Lock()
+	defer pf.resilience.mutex.Unlock()
+
+	if policy == nil {
+		delete(pf.resilience.overrides, model)
+	} else {
+		pf.resilience.overrides[model] = *policy
+	}
+	return nil
+}
+
+// validateResilienceConfig checks the retry and circuit breaker policies of model configs
+func (pf *PonchoFrameworkImpl) validateResilienceConfig(modelConfigs ...map[string]*interfaces.ModelConfig) error {
+	for _, configs := range modelConfigs {
+		for name, modelConfig := range configs {
+			if modelConfig == nil {
+				continue
+			}
+			policy := ResiliencePolicy{Retry: modelConfig.Retry, CircuitBreaker: modelConfig.CircuitBreaker}
+			if _, err := newResilienceGuard(name, policy, pf.logger); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resiliencePolicy returns the policy of a model: the override set with
+// SetResiliencePolicy, or the model's configuration
+func (pf *PonchoFrameworkImpl) resiliencePolicy(model string) ResiliencePolicy {
+	pf.resilience.mutex.Lock()
+	policy, exists := pf.resilience.overrides[model]
+	pf.resilience.mutex.Unlock()
+	if exists {
+		return policy
+	}
+
+	pf.swapMutex.RLock()
+	modelConfig, exists := pf.modelConfigs[model]
+	if !exists {
+		modelConfig, exists = pf.embeddingConfigs[model]
+	}
+	if !exists && pf.config != nil {
+		modelConfig, exists = pf.config.Models[model]
+	}
+	pf.swapMutex.RUnlock()
+	if !exists || modelConfig == nil {
+		return ResiliencePolicy{}
+	}
+
+	return ResiliencePolicy{Retry: modelConfig.Retry, CircuitBreaker: modelConfig.CircuitBreaker}
+}
+
+// resilienceGuard returns the guard of a model, or nil when it has no policy.
+// Guards keep their state until the policy of the model changes.
+func (pf *PonchoFrameworkImpl) resilienceGuard(model string) *resilienceGuard {
+	policy := pf.resiliencePolicy(model)
+
+	pf.resilience.mutex.Lock()
+	defer pf.resilience.mutex.Unlock()
+
+	if policy.empty() {
+		delete(pf.resilience.guards, model)
+		return nil
+	}
+
+	if guard, exists := pf.resilience.guards[model]; exists && reflect.DeepEqual(guard.policy, policy) {
+		return guard
+	}
+
+	guard, err := newResilienceGuard(model, policy, pf.logger)
+	if err != nil {
+		// Policies are validated when set or loaded; never block calls on a bad one
+		pf.logger.Error("Invalid resilience policy, calling model unguarded", "model", model, "error", err)
+		return nil
+	}
+	pf.resilience.guards[model] = guard
+	return guard
+}
+
+// guardModel wraps a model's Generate with its retry and circuit breaker policy
+func (pf *PonchoFrameworkImpl) guardModel(model string, next interfaces.ModelHandler) interfaces.ModelHandler {
+	guard := pf.resilienceGuard(model)
+	if guard == nil {
+		return next
+	}
+
+	return func(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+		ctx = guard.context(ctx)
+
+		var resp *interfaces.PonchoModelResponse
+		err := guard.execute(ctx, model, func() error {
+			var err error
+			resp, err = next(ctx, req)
+			return err
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// guardStream wraps a model's GenerateStreaming with its retry and circuit breaker
+// policy. Once a chunk reached the callback, failures are no longer retried.
+func (pf *PonchoFrameworkImpl) guardStream(model string, next interfaces.StreamHandler) interfaces.StreamHandler {
+	guard := pf.resilienceGuard(model)
+	if guard == nil {
+		return next
+	}
+
+	return func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+		ctx = guard.context(ctx)
+
+		delivered := false
+		return guard.execute(ctx, model, func() error {
+			return next(ctx, req, func(chunk *interfaces.PonchoStreamChunk) error {
+				delivered = true
+				return callback(chunk)
+			})
+		}, func() bool { return delivered })
+	}
+}
+
+// newResilienceGuard creates the guard for a policy, validating it
+func newResilienceGuard(model string, policy ResiliencePolicy, logger interfaces.Logger) (*resilienceGuard, error) {
+	guard := &resilienceGuard{policy: policy}
+
+	if policy.Retry != nil {
+		retryConfig, err := common.RetryConfigFromSpec(policy.Retry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry policy for model '%s': %w", model, err)
+		}
+		guard.retry = common.NewRetryExecutor(retryConfig, logger).OnRetry(func(attempt int, delay time.Duration, err error) {
+			atomic.AddInt64(&guard.retries, 1)
+			logger.Warn("Retrying model call", "model", model, "attempt", attempt, "delay", delay, "error", err)
+		})
+	}
+
+	if policy.CircuitBreaker != nil {
+		breaker, err := common.CircuitBreakerFromSpec(policy.CircuitBreaker, logger)
+		if err != nil {
+			return nil, fmt.Errorf("invalid circuit breaker for model '%s': %w", model, err)
+		}
+		guard.breaker = breaker.OnStateChange(func(from, to common.CircuitState) {
+			if to == common.CircuitStateOpen {
+				atomic.AddInt64(&guard.opens, 1)
+			}
+			logger.Info("Model circuit breaker state changed", "model", model, "from", from.String(), "to", to.String())
+		})
+	}
+
+	return guard, nil
+}
+
+// context marks ctx so providers skip their own HTTP retries under a retry policy
+func (g *resilienceGuard) context(ctx context.Context) context.Context {
+	if g.retry == nil {
+		return ctx
+	}
+	return common.WithoutHTTPRetry(ctx)
+}
+
+// streamStartedError stops retries of a stream that already delivered chunks
+type streamStartedError struct {
+	err error
+}
+
+func (e *streamStartedError) Error() string {
+	return e.err.Error()
+}
+
+// execute runs call through the circuit breaker and retry executor. started, if
+// set, reports whether a failed call already produced output and must not be retried.
+func (g *resilienceGuard) execute(ctx context.Context, model string, call func() error, started func() bool) error {
+	operation := func() error {
+		var err error
+		if g.breaker != nil {
+			err = g.breaker.Execute(call)
+		} else {
+			err = call()
+		}
+		if err != nil && started != nil && started() {
+			return &streamStartedError{err: err}
+		}
+		return err
+	}
+
+	var err error
+	if g.retry != nil {
+		err = g.retry.Execute(ctx, operation)
+	} else {
+		err = operation()
+	}
+
+	var stopped *streamStartedError
+	if errors.As(err, &stopped) {
+		err = stopped.err
+	}
+
+	if modelErr, ok := common.IsModelError(err); ok && modelErr.Code == common.ErrorCodeCircuitOpen {
+		atomic.AddInt64(&g.rejections, 1)
+		if modelErr.Model == "" {
+			modelErr.Model = model
+		}
+	}
+
+	return err
+}
+
+// metrics returns the retry and circuit breaker counters of the guard
+func (g *resilienceGuard) metrics() *interfaces.ResilienceMetrics {
+	metrics := &interfaces.ResilienceMetrics{
+		Retries:           atomic.LoadInt64(&g.retries),
+		CircuitOpens:      atomic.LoadInt64(&g.opens),
+		CircuitRejections: atomic.LoadInt64(&g.rejections),
+	}
+	if g.breaker != nil {
+		metrics.CircuitState = g.breaker.GetState().String()
+		metrics.CircuitFailures = g.breaker.GetFailures()
+	}
+	return metrics
+}
+
+// resilienceMetrics returns the metrics of every guarded model, nil when none is guarded
+func (pf *PonchoFrameworkImpl) resilienceMetrics() map[string]*interfaces.ResilienceMetrics {
+	pf.resilience.mutex.Lock()
+	defer pf.resilience.mutex.Unlock()
+
+	if len(pf.resilience.guards) == 0 {
+		return nil
+	}
+
+	metrics := make(map[string]*interfaces.ResilienceMetrics, len(pf.resilience.guards))
+	for model, guard := range pf.resilience.guards {
+		metrics[model] = guard.metrics()
+	}
+	return metrics
+}
+
+// applyCircuitHealth adds the circuit state to the health of guarded models.
+// An open circuit rejects calls, so the model is unhealthy until it recovers.
+func (pf *PonchoFrameworkImpl) applyCircuitHealth(components map[string]*interfaces.ComponentHealth) {
+	for _, component := range components {
+		if component.Details["type"] != "model" {
+			continue
+		}
+		name, _ := component.Details["name"].(string)
+
+		guard := pf.resilienceGuard(name)
+		if guard == nil || guard.breaker == nil {
+			continue
+		}
+
+		state := guard.breaker.GetState()
+		component.Details["circuit_state"] = state.String()
+		component.Details["circuit_failures"] = guard.breaker.GetFailures()
+
+		switch state {
+		case common.CircuitStateOpen:
+			component.Status = interfaces.HealthStatusUnhealthy
+			component.Message = "circuit breaker is open"
+		case common.CircuitStateHalfOpen:
+			if component.Status == interfaces.HealthStatusHealthy {
+				component.Status = interfaces.HealthStatusDegraded
+				component.Message = "circuit breaker is half-open"
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+)
+
+// newResilienceFramework starts a framework with a scripted model guarded by policy
+func newResilienceFramework(t *testing.T, policy *ResiliencePolicy, steps ...fake.Step) (*PonchoFrameworkImpl, *fake.FakeModel) {
+	t.Helper()
+
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{}, interfaces.NewNoOpLogger())
+	if err := framework.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = framework.Stop(context.Background()) })
+
+	model, err := fake.NewScriptedModel(steps...)
+	if err != nil {
+		t.Fatalf("NewScriptedModel() error = %v", err)
+	}
+	if err := framework.RegisterModel("glm", model); err != nil {
+		t.Fatalf("RegisterModel() error = %v", err)
+	}
+	if err := framework.SetResiliencePolicy("glm", policy); err != nil {
+		t.Fatalf("SetResiliencePolicy() error = %v", err)
+	}
+
+	return framework, model
+}
+
+func glmRequest() *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Model: "glm",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Опиши платье"}},
+		}},
+	}
+}
+
+func TestResilience_RetriesRateLimitedCalls(t *testing.T) {
+	framework, model := newResilienceFramework(t,
+		&ResiliencePolicy{Retry: &interfaces.RetryConfig{MaxAttempts: 3, BaseDelay: "1ms", MaxDelay: "1s"}},
+		fake.Step{Call: 1, Error: &fake.StepError{Code: common.ErrorCodeRateLimitError, RetryAfter: 20 * time.Millisecond}},
+		fake.Step{Call: 2, Text: "Лёгкое летнее платье"},
+	)
+
+	start := time.Now()
+	resp, err := framework.Generate(context.Background(), glmRequest())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Message.Content[0].Text != "Лёгкое летнее платье" || model.CallCount() != 2 {
+		t.Errorf("unexpected response %q after %d calls", resp.Message.Content[0].Text, model.CallCount())
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("retried after %s, want the 20ms Retry-After honored", elapsed)
+	}
+
+	metrics, err := framework.Metrics(context.Background())
+	if err != nil {
+		t.Fatalf("Metrics() error = %v", err)
+	}
+	if rm := metrics.Resilience["glm"]; rm == nil || rm.Retries != 1 || rm.CircuitState != "" {
+		t.Errorf("unexpected resilience metrics: %+v", rm)
+	}
+	if metrics.GeneratedRequests.ByModel["glm"].ErrorCount != 0 {
+		t.Error("a call that succeeded after a retry must not count as failed")
+	}
+}
+
+func TestResilience_CircuitBreakerOpens(t *testing.T) {
+	framework, model := newResilienceFramework(t,
+		&ResiliencePolicy{CircuitBreaker: &interfaces.CircuitBreakerConfig{MaxFailures: 2, ResetTimeout: "1m"}},
+		fake.Step{Match: ".", Error: &fake.StepError{Code: common.ErrorCodeServerError}},
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := framework.Generate(context.Background(), glmRequest()); common.GetErrorCode(err) != common.ErrorCodeServerError {
+			t.Fatalf("Generate() error = %v, want SERVER_ERROR", err)
+		}
+	}
+
+	_, err := framework.Generate(context.Background(), glmRequest())
+	var modelErr *common.ModelError
+	if !errors.As(err, &modelErr) || modelErr.Code != common.ErrorCodeCircuitOpen || modelErr.Model != "glm" {
+		t.Fatalf("Generate() error = %v, want CIRCUIT_OPEN for glm", err)
+	}
+	if model.CallCount() != 2 {
+		t.Errorf("CallCount() = %d, the open circuit must not reach the model", model.CallCount())
+	}
+
+	health, err := framework.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	component := health.Components["model:glm"]
+	if component == nil || component.Status != interfaces.HealthStatusUnhealthy || component.Details["circuit_state"] != "open" {
+		t.Errorf("unexpected model health: %+v", component)
+	}
+	if health.Status != interfaces.HealthStatusUnhealthy {
+		t.Errorf("Status = %s, want unhealthy with the only model's circuit open", health.Status)
+	}
+
+	metrics, err := framework.Metrics(context.Background())
+	if err != nil {
+		t.Fatalf("Metrics() error = %v", err)
+	}
+	rm := metrics.Resilience["glm"]
+	if rm == nil || rm.CircuitState != "open" || rm.CircuitOpens != 1 || rm.CircuitRejections != 1 {
+		t.Errorf("unexpected resilience metrics: %+v", rm)
+	}
+
+	// Removing the policy calls the model directly again
+	if err := framework.SetResiliencePolicy("glm", nil); err != nil {
+		t.Fatalf("SetResiliencePolicy() error = %v", err)
+	}
+	if _, err := framework.Generate(context.Background(), glmRequest()); common.GetErrorCode(err) != common.ErrorCodeServerError {
+		t.Errorf("Generate() error = %v, want SERVER_ERROR without a policy", err)
+	}
+}
+
+func TestResilience_StreamRetriedOnlyBeforeFirstChunk(t *testing.T) {
+	policy := &ResiliencePolicy{Retry: &interfaces.RetryConfig{MaxAttempts: 3, BaseDelay: "1ms", MaxDelay: "1s"}}
+
+	// Setup failures are retried
+	framework, model := newResilienceFramework(t, policy,
+		fake.Step{Call: 1, Error: &fake.StepError{Code: common.ErrorCodeRateLimitError}},
+		fake.Step{Call: 2, Text: "готово"},
+	)
+	if err := framework.GenerateStreaming(context.Background(), glmRequest(), func(*interfaces.PonchoStreamChunk) error { return nil }); err != nil {
+		t.Fatalf("GenerateStreaming() error = %v", err)
+	}
+	if model.CallCount() != 2 {
+		t.Errorf("CallCount() = %d, want the failed setup retried once", model.CallCount())
+	}
+
+	// Failures after chunks reached the caller are not
+	framework, model = newResilienceFramework(t, policy,
+		fake.Step{Call: 1, Chunks: []string{"Лёгкое"}, Error: &fake.StepError{Code: common.ErrorCodeStreamInterrupted}},
+		fake.Step{Call: 2, Text: "Лёгкое летнее платье"},
+	)
+	chunks := 0
+	err := framework.GenerateStreaming(context.Background(), glmRequest(), func(*interfaces.PonchoStreamChunk) error {
+		chunks++
+		return nil
+	})
+	if common.GetErrorCode(err) != common.ErrorCodeStreamInterrupted || model.CallCount() != 1 || chunks != 1 {
+		t.Errorf("GenerateStreaming() = %v after %d calls and %d chunks, want the interruption after 1 call", err, model.CallCount(), chunks)
+	}
+}
+
+func TestResilience_InvalidConfiguration(t *testing.T) {
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Models: map[string]*interfaces.ModelConfig{
+			"glm": {Provider: "zai", ModelName: "glm-4.6", Retry: &interfaces.RetryConfig{MaxAttempts: 3, Backoff: "random"}},
+		},
+	}, interfaces.NewNoOpLogger())
+
+	if err := framework.Start(context.Background()); err == nil {
+		_ = framework.Stop(context.Background())
+		t.Fatal("expected Start() to fail for an unknown backoff")
+	}
+
+	if err := framework.SetResiliencePolicy("glm", &ResiliencePolicy{
+		CircuitBreaker: &interfaces.CircuitBreakerConfig{ResetTimeout: "later"},
+	}); err == nil {
+		t.Error("expected SetResiliencePolicy() to reject an invalid reset_timeout")
+	}
+}
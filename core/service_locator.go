@@ -25,6 +25,12 @@ type ServiceLocator interface {
 	Shutdown() error
 }
 
+// EmbeddingFactoryRegistrar is implemented by service locators that can register
+// embedding model factories
+type EmbeddingFactoryRegistrar interface {
+	RegisterEmbeddingModelFactory(provider string, factory interfaces.EmbeddingModelFactory) error
+}
+
 // ServiceLocatorImpl implements the ServiceLocator interface
 type ServiceLocatorImpl struct {
 	logger             interfaces.Logger
@@ -129,6 +135,28 @@ func (sl *ServiceLocatorImpl) GetModelFactoryManager() interfaces.ModelFactoryMa
 	return sl.modelFactoryMgr
 }
 
+// RegisterEmbeddingModelFactory registers an embedding model factory
+func (sl *ServiceLocatorImpl) RegisterEmbeddingModelFactory(provider string, factory interfaces.EmbeddingModelFactory) error {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+
+	if !sl.initialized {
+		return fmt.Errorf("service locator not initialized")
+	}
+
+	embeddingMgr, ok := sl.modelFactoryMgr.(interfaces.EmbeddingFactoryManager)
+	if !ok {
+		return fmt.Errorf("model factory manager does not support embedding models")
+	}
+
+	if err := embeddingMgr.RegisterEmbeddingFactory(provider, factory); err != nil {
+		return fmt.Errorf("failed to register embedding model factory for provider %s: %w", provider, err)
+	}
+
+	sl.logger.Debug("Embedding model factory registered", "provider", provider)
+	return nil
+}
+
 // GetToolFactory returns a tool factory for the specified type
 func (sl *ServiceLocatorImpl) GetToolFactory(toolType string) (interfaces.ToolFactory, error) {
 	sl.mutex.RLock()
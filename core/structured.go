@@ -0,0 +1,28 @@
+package core
+
+import (
+	"context"
+
+	"github.com/ilkoid/PonchoAiFramework/core/structured"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// GenerateStructured generates a JSON value matching schema with the requested
+// model and decodes it into target. The provider JSON mode is used when the
+// model declares it; invalid output is repaired by re-prompting with the schema
+// violations. A *structured.SchemaError lists the violations when all attempts fail.
+func (pf *PonchoFrameworkImpl) GenerateStructured(ctx context.Context, req *interfaces.PonchoModelRequest, schema map[string]interface{}, target interface{}) (*structured.Result, error) {
+	return pf.GenerateStructuredWithOptions(ctx, req, schema, target, structured.Options{})
+}
+
+// GenerateStructuredWithOptions is GenerateStructured with explicit options.
+// JSONMode is turned on automatically for models that support it.
+func (pf *PonchoFrameworkImpl) GenerateStructuredWithOptions(ctx context.Context, req *interfaces.PonchoModelRequest, schema map[string]interface{}, target interface{}, opts structured.Options) (*structured.Result, error) {
+	if req != nil {
+		if model, err := pf.modelRegistry.Get(req.Model); err == nil && structured.SupportsJSONMode(model) {
+			opts.JSONMode = true
+		}
+	}
+
+	return structured.Generate(ctx, pf, req, schema, target, opts)
+}
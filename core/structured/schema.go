@@ -0,0 +1,335 @@
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation represents one place where a value does not match its schema
+type Violation struct {
+	Path    string `json:"path"` // "$" for the root, e.g. "$.items[2].name"
+	Message string `json:"message"`
+}
+
+// String formats the violation as "path: message"
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// Validate checks a decoded JSON value against a JSON Schema and returns all
+// violations. The supported keywords are:
+//   - type (a name or a list of names), enum, const
+//   - properties, required, additionalProperties (false or a schema),
+//     minProperties, maxProperties
+//   - items, minItems, maxItems, uniqueItems
+//   - minLength, maxLength, pattern
+//   - minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf
+//   - allOf, anyOf, oneOf, not
+//
+// Other keywords, including $ref and format, are ignored.
+func Validate(schema map[string]interface{}, value interface{}) []Violation {
+	// Round-trip the schema so nested Go types (such as []string or
+	// map[string]map[string]interface{}) take their decoded JSON form
+	if data, err := json.Marshal(schema); err == nil {
+		var decoded map[string]interface{}
+		if json.Unmarshal(data, &decoded) == nil {
+			schema = decoded
+		}
+	}
+
+	var violations []Violation
+	validate(schema, normalize(value), "$", &violations)
+	return violations
+}
+
+// validate appends the violations of value at path to violations
+func validate(schema map[string]interface{}, value interface{}, path string, violations *[]Violation) {
+	add := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := stringList(schema["type"]); len(types) > 0 && !matchesAnyType(types, value) {
+		add("expected %s, got %s", strings.Join(types, " or "), typeName(value))
+		return
+	}
+
+	if enum, ok := schema["enum"]; ok {
+		found := false
+		for _, allowed := range list(enum) {
+			if reflect.DeepEqual(normalize(allowed), value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("value %s is not one of %s", encode(value), encode(enum))
+		}
+	}
+
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(normalize(constant), value) {
+		add("value must be %s", encode(constant))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		validateObject(schema, value, path, violations)
+	case []interface{}:
+		validateArray(schema, value, path, violations)
+	case string:
+		length := utf8.RuneCountInString(value)
+		if limit, ok := number(schema["minLength"]); ok && float64(length) < limit {
+			add("string is shorter than %v characters", limit)
+		}
+		if limit, ok := number(schema["maxLength"]); ok && float64(length) > limit {
+			add("string is longer than %v characters", limit)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+				add("string does not match pattern %q", pattern)
+			}
+		}
+	case float64:
+		if limit, ok := number(schema["minimum"]); ok && value < limit {
+			add("value %v is less than the minimum %v", value, limit)
+		}
+		if limit, ok := number(schema["maximum"]); ok && value > limit {
+			add("value %v is greater than the maximum %v", value, limit)
+		}
+		if limit, ok := number(schema["exclusiveMinimum"]); ok && value <= limit {
+			add("value %v must be greater than %v", value, limit)
+		}
+		if limit, ok := number(schema["exclusiveMaximum"]); ok && value >= limit {
+			add("value %v must be less than %v", value, limit)
+		}
+		if factor, ok := number(schema["multipleOf"]); ok && factor > 0 {
+			if quotient := value / factor; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				add("value %v is not a multiple of %v", value, factor)
+			}
+		}
+	}
+
+	validateCombinators(schema, value, path, violations)
+}
+
+// validateObject checks the object keywords
+func validateObject(schema map[string]interface{}, value map[string]interface{}, path string, violations *[]Violation) {
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := value[name]; !ok {
+			*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("missing required property %q", name)})
+		}
+	}
+
+	if limit, ok := number(schema["minProperties"]); ok && float64(len(value)) < limit {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("object has fewer than %v properties", limit)})
+	}
+	if limit, ok := number(schema["maxProperties"]); ok && float64(len(value)) > limit {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("object has more than %v properties", limit)})
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if propertySchema, ok := properties[name].(map[string]interface{}); ok {
+			validate(propertySchema, value[name], propertyPath, violations)
+			continue
+		}
+		if _, declared := properties[name]; declared {
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*violations = append(*violations, Violation{Path: propertyPath, Message: "additional property is not allowed"})
+			}
+		case map[string]interface{}:
+			validate(additional, value[name], propertyPath, violations)
+		}
+	}
+}
+
+// validateArray checks the array keywords
+func validateArray(schema map[string]interface{}, value []interface{}, path string, violations *[]Violation) {
+	if limit, ok := number(schema["minItems"]); ok && float64(len(value)) < limit {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("array has fewer than %v items", limit)})
+	}
+	if limit, ok := number(schema["maxItems"]); ok && float64(len(value)) > limit {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("array has more than %v items", limit)})
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("items %d and %d are equal", i, j)})
+				}
+			}
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range value {
+			validate(items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+// validateCombinators checks allOf, anyOf, oneOf and not
+func validateCombinators(schema map[string]interface{}, value interface{}, path string, violations *[]Violation) {
+	for _, sub := range schemaList(schema["allOf"]) {
+		validate(sub, value, path, violations)
+	}
+
+	if subs := schemaList(schema["anyOf"]); len(subs) > 0 && countMatches(subs, value, path) == 0 {
+		*violations = append(*violations, Violation{Path: path, Message: "value does not match any of the anyOf schemas"})
+	}
+
+	if subs := schemaList(schema["oneOf"]); len(subs) > 0 {
+		if matches := countMatches(subs, value, path); matches != 1 {
+			*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("value matches %d of the oneOf schemas, expected exactly one", matches)})
+		}
+	}
+
+	if not, ok := schema["not"].(map[string]interface{}); ok && countMatches([]map[string]interface{}{not}, value, path) == 1 {
+		*violations = append(*violations, Violation{Path: path, Message: "value must not match the not schema"})
+	}
+}
+
+// countMatches returns how many of the schemas value matches
+func countMatches(schemas []map[string]interface{}, value interface{}, path string) int {
+	matches := 0
+	for _, sub := range schemas {
+		var subViolations []Violation
+		validate(sub, value, path, &subViolations)
+		if len(subViolations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// matchesAnyType reports whether value has one of the JSON Schema types
+func matchesAnyType(types []string, value interface{}) bool {
+	for _, name := range types {
+		switch name {
+		case "integer":
+			if number, ok := value.(float64); ok && number == math.Trunc(number) {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		default:
+			if typeName(value) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeName returns the JSON Schema type name of a decoded value
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// schemaType returns the single type a schema declares, or ""
+func schemaType(schema map[string]interface{}) string {
+	if types := stringList(schema["type"]); len(types) == 1 {
+		return types[0]
+	}
+	return ""
+}
+
+// normalize converts Go values (as found in schemas written in code) to
+// their decoded JSON form, so they compare equal to parsed output
+func normalize(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, bool, float64, string, []interface{}, map[string]interface{}:
+		return value
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+// number converts a numeric schema keyword to float64
+func number(value interface{}) (float64, bool) {
+	number, ok := normalize(value).(float64)
+	return number, ok
+}
+
+// list converts a schema keyword holding a list to []interface{}
+func list(value interface{}) []interface{} {
+	items, _ := normalize(value).([]interface{})
+	return items
+}
+
+// stringList converts a string or list of strings keyword to []string
+func stringList(value interface{}) []string {
+	if name, ok := value.(string); ok {
+		return []string{name}
+	}
+
+	var names []string
+	for _, item := range list(value) {
+		if name, ok := item.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// schemaList converts a list of schemas keyword
+func schemaList(value interface{}) []map[string]interface{} {
+	var schemas []map[string]interface{}
+	for _, item := range list(value) {
+		if schema, ok := item.(map[string]interface{}); ok {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// encode formats a value as JSON for messages
+func encode(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
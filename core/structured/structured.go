@@ -0,0 +1,263 @@
+// Package structured provides JSON Schema constrained generation
+//
+// Generate sends a model request asking for a single JSON value matching a
+// schema, then:
+//   - turns on the provider JSON mode (ResponseFormat "json_object") when
+//     Options.JSONMode is set and the schema is an object
+//   - strips code fences and prose around the JSON value
+//   - validates the value against the schema (see Validate for the supported subset)
+//   - on failure, re-prompts with the violations until MaxAttempts is reached
+//
+// When every attempt fails, Generate returns a *SchemaError listing the
+// violations of the last attempt.
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// DefaultMaxAttempts is the number of model calls (the first and two repairs)
+// made when Options.MaxAttempts is zero
+const DefaultMaxAttempts = 3
+
+// Generator is implemented by models and by the framework
+type Generator interface {
+	Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error)
+}
+
+// Options configures structured generation
+type Options struct {
+	// MaxAttempts limits the model calls, including repairs (default DefaultMaxAttempts)
+	MaxAttempts int
+
+	// JSONMode asks the provider for JSON output through the request response format
+	JSONMode bool
+}
+
+// Result represents a successful structured generation
+type Result struct {
+	Response *interfaces.PonchoModelResponse `json:"response"` // last model response
+	JSON     json.RawMessage                 `json:"json"`     // the validated JSON value
+	Attempts int                             `json:"attempts"` // model calls made
+}
+
+// SchemaError is returned when no attempt produced JSON matching the schema
+type SchemaError struct {
+	Violations []Violation `json:"violations"`
+	Text       string      `json:"text"` // model text of the last attempt
+	Attempts   int         `json:"attempts"`
+}
+
+// Error implements error
+func (e *SchemaError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Sprintf("structured output does not match schema after %d attempts: %s", e.Attempts, strings.Join(messages, "; "))
+}
+
+// IsSchemaError checks if an error is a SchemaError
+func IsSchemaError(err error) (*SchemaError, bool) {
+	var schemaErr *SchemaError
+	if errors.As(err, &schemaErr) {
+		return schemaErr, true
+	}
+	return nil, false
+}
+
+// SupportsJSONMode reports whether a model declares a provider JSON mode
+func SupportsJSONMode(model interfaces.PonchoModel) bool {
+	if model, ok := model.(interface {
+		GetCapabilities() interfaces.ModelCapabilities
+	}); ok {
+		return model.GetCapabilities().JSONMode
+	}
+	return false
+}
+
+// Generate generates a JSON value matching schema and decodes it into target
+// (skipped when target is nil). req itself is not modified.
+func Generate(ctx context.Context, generator Generator, req *interfaces.PonchoModelRequest, schema map[string]interface{}, target interface{}, opts Options) (*Result, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("schema cannot be empty")
+	}
+
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	instructions, err := schemaInstructions(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	call := *req
+	call.Stream = false
+	call.Messages = withInstructions(req.Messages, instructions)
+	if opts.JSONMode && schemaType(schema) == "object" {
+		call.ResponseFormat = interfaces.PonchoResponseFormatJSONObject
+	}
+
+	var violations []Violation
+	var text string
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, err := generator.Generate(ctx, &call)
+		if err != nil {
+			return nil, fmt.Errorf("structured generation attempt %d failed: %w", attempt, err)
+		}
+
+		text = responseText(resp)
+		raw, value, err := ExtractJSON(text)
+		if err != nil {
+			violations = []Violation{{Path: "$", Message: err.Error()}}
+		} else {
+			violations = Validate(schema, value)
+		}
+
+		if len(violations) == 0 {
+			if target != nil {
+				if err := json.Unmarshal([]byte(raw), target); err != nil {
+					return nil, fmt.Errorf("failed to decode structured output: %w", err)
+				}
+			}
+			return &Result{Response: resp, JSON: json.RawMessage(raw), Attempts: attempt}, nil
+		}
+
+		messages := call.Messages[:len(call.Messages):len(call.Messages)]
+		if text != "" {
+			messages = append(messages, textMessage(interfaces.PonchoRoleAssistant, text))
+		}
+		call.Messages = append(messages, textMessage(interfaces.PonchoRoleUser, repairPrompt(violations)))
+	}
+
+	return nil, &SchemaError{Violations: violations, Text: text, Attempts: maxAttempts}
+}
+
+// ExtractJSON returns the first JSON object or array in text, ignoring code
+// fences and surrounding prose, together with its decoded value
+func ExtractJSON(text string) (string, interface{}, error) {
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return "", nil, fmt.Errorf("response contains no JSON object or array")
+	}
+
+	end := matchingBracket(text, start)
+	if end < 0 {
+		return "", nil, fmt.Errorf("response contains an unterminated JSON value")
+	}
+
+	raw := text[start : end+1]
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return "", nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	return raw, value, nil
+}
+
+// matchingBracket returns the index of the bracket closing the one at start,
+// skipping brackets inside strings, or -1
+func matchingBracket(text string, start int) int {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// schemaInstructions builds the system message describing the expected output
+func schemaInstructions(schema map[string]interface{}) (string, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("invalid schema: %w", err)
+	}
+
+	return "Respond with a single JSON value that matches this JSON Schema. " +
+		"Output only the JSON, without code fences or explanations.\n\n" + string(data), nil
+}
+
+// repairPrompt asks the model to fix the listed violations
+func repairPrompt(violations []Violation) string {
+	var prompt strings.Builder
+	prompt.WriteString("Your response does not match the required JSON Schema:\n")
+	for _, violation := range violations {
+		prompt.WriteString("- ")
+		prompt.WriteString(violation.String())
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("Respond again with only the corrected JSON.")
+	return prompt.String()
+}
+
+// responseText joins the text parts of a response
+func responseText(resp *interfaces.PonchoModelResponse) string {
+	if resp == nil || resp.Message == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	for _, part := range resp.Message.Content {
+		if part != nil && part.Type == interfaces.PonchoContentTypeText {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
+
+// withInstructions returns messages with instructions added to the system
+// prompt, prepending one if there is none. Providers that take a single system
+// prompt (Anthropic, Gemini) would otherwise get two.
+func withInstructions(messages []*interfaces.PonchoMessage, instructions string) []*interfaces.PonchoMessage {
+	for i, msg := range messages {
+		if msg.Role != interfaces.PonchoRoleSystem {
+			continue
+		}
+
+		merged := make([]*interfaces.PonchoMessage, len(messages))
+		copy(merged, messages)
+		merged[i] = textMessage(interfaces.PonchoRoleSystem, common.MessageText(msg)+"\n\n"+instructions)
+		return merged
+	}
+
+	return append([]*interfaces.PonchoMessage{textMessage(interfaces.PonchoRoleSystem, instructions)}, messages...)
+}
+
+// textMessage creates a single-part text message
+func textMessage(role interfaces.PonchoRole, text string) *interfaces.PonchoMessage {
+	return &interfaces.PonchoMessage{
+		Role:    role,
+		Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: text}},
+	}
+}
@@ -0,0 +1,203 @@
+package structured
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+)
+
+var articleSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"title": map[string]interface{}{"type": "string", "minLength": 3},
+		"price": map[string]interface{}{"type": "number", "minimum": 0},
+		"tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+	},
+	"required":             []string{"title", "price"},
+	"additionalProperties": false,
+}
+
+type article struct {
+	Title string   `json:"title"`
+	Price float64  `json:"price"`
+	Tags  []string `json:"tags"`
+}
+
+func userRequest(text string) *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Model: "fake-model",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: text}},
+		}},
+	}
+}
+
+func newModel(t *testing.T, steps ...fake.Step) *fake.FakeModel {
+	t.Helper()
+
+	model, err := fake.NewScriptedModel(steps...)
+	if err != nil {
+		t.Fatalf("NewScriptedModel() error = %v", err)
+	}
+	return model
+}
+
+func TestGenerate_ExtractsFencedJSON(t *testing.T) {
+	model := newModel(t, fake.Step{Text: "Here it is:\n```json\n{\"title\": \"Red {dress}\", \"price\": 10, \"tags\": [\"summer\"]}\n```"})
+
+	var target article
+	req := userRequest("Describe article 42")
+	result, err := Generate(context.Background(), model, req, articleSchema, &target, Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if result.Attempts != 1 || target.Title != "Red {dress}" || target.Price != 10 || len(target.Tags) != 1 {
+		t.Errorf("unexpected result: attempts = %d, target = %+v", result.Attempts, target)
+	}
+	if !strings.HasPrefix(string(result.JSON), "{") || !strings.HasSuffix(string(result.JSON), "}") {
+		t.Errorf("JSON = %s, want the bare object", result.JSON)
+	}
+	if len(req.Messages) != 1 || req.Metadata != nil {
+		t.Error("request was modified")
+	}
+
+	sent := model.LastRequest()
+	if sent.Messages[0].Role != interfaces.PonchoRoleSystem || !strings.Contains(sent.Messages[0].Content[0].Text, `"required"`) {
+		t.Errorf("expected a system message with the schema, got %+v", sent.Messages[0])
+	}
+	if sent.ResponseFormat != "" {
+		t.Error("JSON mode must not be requested without Options.JSONMode")
+	}
+}
+
+func TestGenerate_MergesSystemPrompt(t *testing.T) {
+	model := newModel(t, fake.Step{Text: `{"title": "Red dress", "price": 10}`})
+
+	req := userRequest("Describe article 42")
+	system := &interfaces.PonchoMessage{
+		Role:    interfaces.PonchoRoleSystem,
+		Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "You are a fashion expert"}},
+	}
+	req.Messages = append([]*interfaces.PonchoMessage{system}, req.Messages...)
+
+	if _, err := Generate(context.Background(), model, req, articleSchema, nil, Options{}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// The schema instructions join the existing system prompt instead of adding a second one
+	sent := model.LastRequest()
+	if len(sent.Messages) != 2 || sent.Messages[0].Role != interfaces.PonchoRoleSystem {
+		t.Fatalf("sent %d messages, want the system prompt and the user message", len(sent.Messages))
+	}
+	text := sent.Messages[0].Content[0].Text
+	if !strings.HasPrefix(text, "You are a fashion expert\n\n") || !strings.Contains(text, `"required"`) {
+		t.Errorf("system prompt = %q", text)
+	}
+	if system.Content[0].Text != "You are a fashion expert" {
+		t.Error("request system message was modified")
+	}
+}
+
+func TestGenerate_RepairsInvalidOutput(t *testing.T) {
+	model := newModel(t,
+		fake.Step{Text: `{"title": "Hi", "price": -1, "color": "red"}`},
+		fake.Step{Text: `{"title": "Red dress", "price": 10}`},
+	)
+
+	var target article
+	result, err := Generate(context.Background(), model, userRequest("Describe article 42"), articleSchema, &target, Options{JSONMode: true})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.Attempts != 2 || target.Title != "Red dress" {
+		t.Errorf("attempts = %d, target = %+v", result.Attempts, target)
+	}
+
+	// The repair request carries the invalid answer and the violations
+	repair := model.LastRequest()
+	if len(repair.Messages) != 4 || repair.Messages[2].Role != interfaces.PonchoRoleAssistant {
+		t.Fatalf("repair request has %d messages, want 4", len(repair.Messages))
+	}
+	prompt := repair.Messages[3].Content[0].Text
+	for _, want := range []string{"$.title: string is shorter than 3", "$.price: value -1 is less than the minimum 0", "$.color: additional property"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("repair prompt does not mention %q:\n%s", want, prompt)
+		}
+	}
+	if repair.ResponseFormat != interfaces.PonchoResponseFormatJSONObject {
+		t.Errorf("response_format = %v, want json_object", repair.ResponseFormat)
+	}
+}
+
+func TestGenerate_ReturnsSchemaError(t *testing.T) {
+	model := newModel(t, fake.Step{Match: ".", Text: "I cannot answer in JSON."})
+
+	_, err := Generate(context.Background(), model, userRequest("Describe article 42"), articleSchema, nil, Options{MaxAttempts: 2})
+	schemaErr, ok := IsSchemaError(err)
+	if !ok {
+		t.Fatalf("Generate() error = %v, want a SchemaError", err)
+	}
+	if schemaErr.Attempts != 2 || model.CallCount() != 2 || len(schemaErr.Violations) != 1 {
+		t.Errorf("attempts = %d, calls = %d, violations = %v", schemaErr.Attempts, model.CallCount(), schemaErr.Violations)
+	}
+	if schemaErr.Text != "I cannot answer in JSON." {
+		t.Errorf("Text = %q", schemaErr.Text)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]interface{}
+		value  interface{}
+		want   []string
+	}{
+		{"integer", map[string]interface{}{"type": "integer"}, 1.5, []string{"$: expected integer, got number"}},
+		{"type list", map[string]interface{}{"type": []string{"string", "null"}}, nil, nil},
+		{"enum", map[string]interface{}{"enum": []string{"S", "M"}}, "L", []string{`$: value "L" is not one of ["S","M"]`}},
+		{"pattern", map[string]interface{}{"type": "string", "pattern": "^[0-9]+$"}, "12a", []string{`$: string does not match pattern "^[0-9]+$"`}},
+		{"items", map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}, "maxItems": 2}, []interface{}{1.0, "x", 3.0}, []string{
+			"$: array has more than 2 items",
+			"$[1]: expected integer, got string",
+		}},
+		{"unique", map[string]interface{}{"uniqueItems": true}, []interface{}{"a", "a"}, []string{"$: items 0 and 1 are equal"}},
+		{"oneOf", map[string]interface{}{"oneOf": []interface{}{
+			map[string]interface{}{"type": "number"},
+			map[string]interface{}{"minimum": 0},
+		}}, 5.0, []string{"$: value matches 2 of the oneOf schemas, expected exactly one"}},
+		{"minProperties", map[string]interface{}{"type": "object", "minProperties": 1}, map[string]interface{}{}, []string{"$: object has fewer than 1 properties"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := Validate(tt.schema, tt.value)
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", violations, tt.want)
+			}
+			for i, violation := range violations {
+				if violation.String() != tt.want[i] {
+					t.Errorf("violation %d = %q, want %q", i, violation.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	raw, _, err := ExtractJSON("Result: [1, \"]\", {\"a\": \"\\\"}\"}] done")
+	if err != nil || raw != "[1, \"]\", {\"a\": \"\\\"}\"}]" {
+		t.Errorf("ExtractJSON() = %q, %v", raw, err)
+	}
+
+	if _, _, err := ExtractJSON("no json here"); err == nil {
+		t.Error("expected an error for text without JSON")
+	}
+	if _, _, err := ExtractJSON(`{"a": 1`); err == nil {
+		t.Error("expected an error for unterminated JSON")
+	}
+}
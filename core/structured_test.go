@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+)
+
+func TestGenerateStructured(t *testing.T) {
+	framework, model, _ := newAgentFramework(t, fake.Step{Text: "```json\n{\"id\": 42}\n```"})
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}},
+		"required":   []interface{}{"id"},
+	}
+
+	var target struct {
+		ID int `json:"id"`
+	}
+	result, err := framework.GenerateStructured(context.Background(), agentRequest("Article ID?"), schema, &target)
+	if err != nil {
+		t.Fatalf("GenerateStructured() error = %v", err)
+	}
+
+	if target.ID != 42 || string(result.JSON) != `{"id": 42}` {
+		t.Errorf("target = %+v, JSON = %s", target, result.JSON)
+	}
+	// The fake model declares JSON mode, so it is requested automatically
+	if got := model.LastRequest().ResponseFormat; got != interfaces.PonchoResponseFormatJSONObject {
+		t.Errorf("response_format = %v, want json_object", got)
+	}
+}
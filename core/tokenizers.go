@@ -0,0 +1,108 @@
+package core
+
+// Tokenizer files and token calibration for the PonchoFramework
+//
+// The "tokenizers" config section (or PonchoFrameworkConfig.Tokenizers) loads
+// tokenizer files at start and maps them to model names, so token limits, budgets
+// and ledger estimates count tokens exactly instead of with the character ratios of
+// common.Tokenizer:
+//
+//	tokenizers:
+//	  calibrate: true
+//	  files:
+//	    cl100k_base:
+//	      type: tiktoken
+//	      path: ./tokenizers/cl100k_base.tiktoken
+//	      models: ["gpt-4", "gpt-3.5-*"]
+//	    deepseek-v3:
+//	      type: bpe
+//	      path: ./tokenizers/deepseek/vocab.json
+//	      merges_path: ./tokenizers/deepseek/merges.txt
+//	      models: ["deepseek-*"]
+//
+// With calibrate set, every call that reports usage is compared with its estimate.
+// The drift per model is logged when the framework stops and available from
+// TokenCalibrator. See common.TokenCalibrator.
+
+import (
+	"fmt"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// TokenCalibrator returns the token calibrator, or nil when calibration is not configured
+func (pf *PonchoFrameworkImpl) TokenCalibrator() *common.TokenCalibrator {
+	return pf.tokenCalibrator
+}
+
+// initTokenizers loads the tokenizer files of the framework config or the
+// "tokenizers" section of the loaded configuration and maps them to their models
+func (pf *PonchoFrameworkImpl) initTokenizers() error {
+	tokenizersConfig := pf.config.Tokenizers
+	if tokenizersConfig == nil && pf.configManager != nil {
+		var err error
+		if tokenizersConfig, err = pf.configManager.GetTokenizersConfig(); err != nil {
+			return err
+		}
+	}
+
+	pf.tokenCalibrator = nil
+	if tokenizersConfig == nil {
+		return nil
+	}
+
+	for name, fileConfig := range tokenizersConfig.Files {
+		if fileConfig == nil || len(fileConfig.Models) == 0 {
+			return fmt.Errorf("tokenizer %s is not mapped to any model", name)
+		}
+
+		tokenizer, err := common.LoadTextTokenizer(name, fileConfig)
+		if err != nil {
+			return err
+		}
+
+		for _, model := range fileConfig.Models {
+			common.RegisterTextTokenizer(model, tokenizer)
+		}
+		pf.logger.Info("Tokenizer loaded", "name", name, "type", fileConfig.Type, "models", fileConfig.Models)
+	}
+
+	if tokenizersConfig.Calibrate {
+		pf.tokenCalibrator = common.NewTokenCalibrator(common.NewTokenizer(interfaces.NewNoOpLogger()), pf.logger)
+		pf.logger.Info("Token calibration enabled")
+	}
+
+	return nil
+}
+
+// calibrateTokens compares the estimate of a completed call with its reported usage
+func (pf *PonchoFrameworkImpl) calibrateTokens(req *interfaces.PonchoModelRequest, model interfaces.PonchoModel, resp *interfaces.PonchoModelResponse) {
+	calibrator := pf.tokenCalibrator
+	if calibrator == nil || resp == nil {
+		return
+	}
+
+	provider, modelName := pf.resolveModel(req.Model, model)
+	if err := calibrator.Observe(req, resp, common.Provider(provider), modelName); err != nil {
+		pf.logger.Debug("Token calibration skipped", "model", req.Model, "error", err)
+	}
+}
+
+// logTokenCalibration logs the drift of every calibrated model
+func (pf *PonchoFrameworkImpl) logTokenCalibration() {
+	if pf.tokenCalibrator == nil {
+		return
+	}
+
+	for _, stats := range pf.tokenCalibrator.Report() {
+		pf.logger.Info("Token estimate drift",
+			"provider", stats.Provider,
+			"model", stats.Model,
+			"tokenizer", stats.Tokenizer,
+			"samples", stats.Samples,
+			"prompt_drift", fmt.Sprintf("%+.1f%%", stats.PromptDrift()*100),
+			"max_prompt_drift", fmt.Sprintf("%+.1f%%", stats.MaxPromptDrift*100),
+			"completion_drift", fmt.Sprintf("%+.1f%%", stats.CompletionDrift()*100))
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/core/config"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+)
+
+func TestTokenizers_FromConfiguration(t *testing.T) {
+	dir := t.TempDir()
+
+	// A byte tokenizer: one token per UTF-8 byte
+	var ranks strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&ranks, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	tokenizerPath := filepath.Join(dir, "bytes.tiktoken")
+	if err := os.WriteFile(tokenizerPath, []byte(ranks.String()), 0644); err != nil {
+		t.Fatalf("Failed to write tokenizer: %v", err)
+	}
+
+	configPath := filepath.Join(dir, "config.yaml")
+	content := fmt.Sprintf(`models: {}
+tokenizers:
+  calibrate: true
+  files:
+    bytes:
+      type: tiktoken
+      path: %s
+      models: ["fake-calibrated*"]
+`, tokenizerPath)
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Cleanup(func() { common.UnregisterTextTokenizer("fake-calibrated*") })
+
+	logger := interfaces.NewNoOpLogger()
+	framework := NewPonchoFramework(nil, logger)
+	framework.SetConfigManager(config.NewConfigManager(config.ConfigOptions{FilePaths: []string{configPath}, Logger: logger}))
+	if err := framework.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start framework: %v", err)
+	}
+	t.Cleanup(func() { _ = framework.Stop(context.Background()) })
+
+	if tokenizer, exists := common.TextTokenizerFor("fake-calibrated-v2"); !exists || tokenizer.Name() != "bytes" {
+		t.Fatalf("expected the bytes tokenizer to be mapped from configuration")
+	}
+
+	model := fake.NewFakeModel()
+	if err := model.Initialize(context.Background(), map[string]interface{}{
+		"model_name": "fake-calibrated",
+		"script":     []fake.Step{{Text: "Готово", Usage: &interfaces.PonchoUsage{PromptTokens: 40, CompletionTokens: 12, TotalTokens: 52}}},
+	}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if err := framework.RegisterModel("writer", model); err != nil {
+		t.Fatalf("RegisterModel() error = %v", err)
+	}
+
+	_, err := framework.Generate(context.Background(), &interfaces.PonchoModelRequest{
+		Model: "writer",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Привет"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	calibrator := framework.TokenCalibrator()
+	if calibrator == nil {
+		t.Fatal("expected a token calibrator when calibrate is set")
+	}
+	report := calibrator.Report()
+	if len(report) != 1 {
+		t.Fatalf("expected one calibrated model, got %d", len(report))
+	}
+
+	// 12 bytes of "Привет" and 2 role tokens against the 40 reported
+	stats := report[0]
+	if stats.Model != "fake-calibrated" || stats.Tokenizer != "bytes" || stats.EstimatedPromptTokens != 14 || stats.ActualPromptTokens != 40 {
+		t.Errorf("unexpected calibration: %+v", stats)
+	}
+}
+
+func TestTokenizers_InvalidConfiguration(t *testing.T) {
+	framework := NewPonchoFramework(&interfaces.PonchoFrameworkConfig{
+		Tokenizers: &interfaces.TokenizersConfig{
+			Files: map[string]*interfaces.TokenizerConfig{
+				"missing": {Type: common.TokenizerTypeTiktoken, Path: filepath.Join(t.TempDir(), "missing.tiktoken"), Models: []string{"gpt-4"}},
+			},
+		},
+	}, interfaces.NewNoOpLogger())
+
+	if err := framework.Start(context.Background()); err == nil {
+		_ = framework.Stop(context.Background())
+		t.Fatal("expected Start() to fail for a missing tokenizer file")
+	}
+}
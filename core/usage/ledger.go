@@ -0,0 +1,303 @@
+// Package usage records token usage and estimated cost of model calls
+//
+// Every successful Generate and GenerateStreaming call made through the framework
+// is appended to a ledger as one JSON line. A stream that fails after reporting
+// usage is recorded too, marked partial, since budgets charge that usage. Records carry the tokens reported by the
+// provider (or estimated with common.Tokenizer when the provider reports none), the
+// cost estimated with common.Tokenizer.EstimateCost, and tags read from the request
+// Metadata:
+//   - "flow", "step":       the flow and flow step making the call
+//   - "tenant", "user":     who the call is made for
+//   - "article_id":         the article being enriched
+//
+// The ledger file is append-only; reports (daily totals, cost per flow or article)
+// are computed by reading it back with ReadFile or Ledger.Query.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// DefaultPath is the ledger file used when UsageConfig leaves it empty
+const DefaultPath = ".poncho_usage.jsonl"
+
+// Request metadata keys used to tag records
+const (
+	MetadataFlow      = "flow"
+	MetadataStep      = "step"
+	MetadataTenant    = "tenant"
+	MetadataUser      = "user"
+	MetadataArticleID = "article_id"
+)
+
+// metadataCacheHit marks responses served from the response cache (see core/cache)
+const metadataCacheHit = "cache_hit"
+
+// Record is a single ledger entry
+type Record struct {
+	Timestamp        time.Time `json:"timestamp"`
+	Model            string    `json:"model"`
+	Provider         string    `json:"provider,omitempty"`
+	ModelName        string    `json:"model_name,omitempty"`
+	Flow             string    `json:"flow,omitempty"`
+	Step             string    `json:"step,omitempty"`
+	Tenant           string    `json:"tenant,omitempty"`
+	User             string    `json:"user,omitempty"`
+	ArticleID        string    `json:"article_id,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost"`
+	Estimated        bool      `json:"estimated,omitempty"` // tokens estimated by the tokenizer
+	Streaming        bool      `json:"streaming,omitempty"`
+	Partial          bool      `json:"partial,omitempty"`   // failed stream, tokens reported before the failure
+	CacheHit         bool      `json:"cache_hit,omitempty"` // served from cache, no cost
+}
+
+// Call describes a completed model call to be recorded
+type Call struct {
+	Request   *interfaces.PonchoModelRequest
+	Response  *interfaces.PonchoModelResponse // for streaming calls, the accumulated message and usage
+	Provider  string
+	ModelName string
+	Streaming bool
+	Partial   bool // the stream failed; Response holds what it reported before failing
+}
+
+// Ledger appends usage records to a JSONL file
+type Ledger struct {
+	path      string
+	file      *os.File
+	tokenizer *common.Tokenizer
+	logger    interfaces.Logger
+	mutex     sync.Mutex
+}
+
+// Open opens (or creates) the ledger file at path for appending
+func Open(path string, logger interfaces.Logger) (*Ledger, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+
+	logger.Info("Usage ledger opened", "path", path)
+	return &Ledger{
+		path:      path,
+		file:      file,
+		tokenizer: common.NewTokenizer(interfaces.NewNoOpLogger()),
+		logger:    logger,
+	}, nil
+}
+
+// Path returns the ledger file path
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Close closes the ledger file
+func (l *Ledger) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Record builds a record for a completed call and appends it to the ledger
+func (l *Ledger) Record(call *Call) (*Record, error) {
+	record := l.newRecord(call)
+	if err := l.Append(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Append writes a record to the ledger
+func (l *Ledger) Append(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage record: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("usage ledger is closed")
+	}
+	if _, err := l.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write usage record: %w", err)
+	}
+	return nil
+}
+
+// Query reads the records matching filter
+func (l *Ledger) Query(filter Filter) ([]*Record, error) {
+	return ReadFile(l.path, filter)
+}
+
+// newRecord fills tokens, cost and tags for a call
+func (l *Ledger) newRecord(call *Call) *Record {
+	req := call.Request
+	record := &Record{
+		Timestamp: time.Now().UTC(),
+		Model:     req.Model,
+		Provider:  call.Provider,
+		ModelName: call.ModelName,
+		Flow:      metadataString(req.Metadata, MetadataFlow),
+		Step:      metadataString(req.Metadata, MetadataStep),
+		Tenant:    metadataString(req.Metadata, MetadataTenant),
+		User:      metadataString(req.Metadata, MetadataUser),
+		ArticleID: metadataString(req.Metadata, MetadataArticleID),
+		Streaming: call.Streaming,
+		Partial:   call.Partial,
+	}
+
+	resp := call.Response
+	if resp != nil && resp.Metadata[metadataCacheHit] == true {
+		// Cached responses cost nothing; keep the tokens for reference
+		record.CacheHit = true
+	}
+
+	if resp != nil && resp.Usage != nil && resp.Usage.TotalTokens > 0 {
+		record.PromptTokens = resp.Usage.PromptTokens
+		record.CompletionTokens = resp.Usage.CompletionTokens
+		record.TotalTokens = resp.Usage.TotalTokens
+	} else {
+		l.estimateTokens(record, call)
+	}
+
+	if !record.CacheHit && record.TotalTokens > 0 {
+		cost, err := l.tokenizer.EstimateCost(&interfaces.PonchoUsage{
+			PromptTokens:     record.PromptTokens,
+			CompletionTokens: record.CompletionTokens,
+			TotalTokens:      record.TotalTokens,
+		}, common.Provider(call.Provider), call.ModelName)
+		if err != nil {
+			l.logger.Debug("No cost information for model", "model", call.ModelName, "provider", call.Provider)
+		}
+		record.Cost = cost
+	}
+
+	return record
+}
+
+// estimateTokens counts tokens with the tokenizer when the provider reported no usage
+func (l *Ledger) estimateTokens(record *Record, call *Call) {
+	provider, modelName := common.Provider(call.Provider), call.ModelName
+
+	usage, err := l.tokenizer.CountRequestTokens(call.Request, provider, modelName)
+	if err != nil {
+		return
+	}
+	record.PromptTokens = usage.PromptTokens
+	record.Estimated = true
+
+	if call.Response != nil && call.Response.Message != nil {
+		if completion, err := l.tokenizer.CountMessageTokens(call.Response.Message, provider, modelName); err == nil {
+			record.CompletionTokens = completion
+		}
+	}
+	record.TotalTokens = record.PromptTokens + record.CompletionTokens
+}
+
+// Filter selects ledger records; empty fields match everything
+type Filter struct {
+	From      time.Time // inclusive
+	To        time.Time // exclusive
+	Model     string
+	Flow      string
+	Tenant    string
+	User      string
+	ArticleID string
+}
+
+// Match reports whether a record passes the filter
+func (f Filter) Match(record *Record) bool {
+	switch {
+	case !f.From.IsZero() && record.Timestamp.Before(f.From):
+		return false
+	case !f.To.IsZero() && !record.Timestamp.Before(f.To):
+		return false
+	case f.Model != "" && record.Model != f.Model:
+		return false
+	case f.Flow != "" && record.Flow != f.Flow:
+		return false
+	case f.Tenant != "" && record.Tenant != f.Tenant:
+		return false
+	case f.User != "" && record.User != f.User:
+		return false
+	case f.ArticleID != "" && record.ArticleID != f.ArticleID:
+		return false
+	}
+	return true
+}
+
+// ReadFile reads the records of a ledger file matching filter.
+// A missing file has no records; a truncated last line (interrupted write) is skipped.
+func ReadFile(path string, filter Filter) ([]*Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	var records []*Record
+	var pending error // parse error, fatal unless it is on the last line
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if pending != nil {
+			return nil, pending
+		}
+
+		var record Record
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			pending = fmt.Errorf("invalid usage record on line %d: %w", line, err)
+			continue
+		}
+		if filter.Match(&record) {
+			records = append(records, &record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+
+	return records, nil
+}
+
+func metadataString(metadata map[string]interface{}, key string) string {
+	if value, ok := metadata[key]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+func openTestLedger(t *testing.T) *Ledger {
+	t.Helper()
+
+	ledger, err := Open(filepath.Join(t.TempDir(), "usage.jsonl"), interfaces.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to open ledger: %v", err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+	return ledger
+}
+
+func textRequest(text string, metadata map[string]interface{}) *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Model: "vision",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: text}},
+		}},
+		Metadata: metadata,
+	}
+}
+
+func TestLedger_RecordReportedUsage(t *testing.T) {
+	ledger := openTestLedger(t)
+
+	record, err := ledger.Record(&Call{
+		Request: textRequest("describe the dress", map[string]interface{}{
+			MetadataFlow:      "article_importer",
+			MetadataStep:      "vision",
+			MetadataArticleID: 12345,
+		}),
+		Response:  &interfaces.PonchoModelResponse{Usage: &interfaces.PonchoUsage{PromptTokens: 1500, CompletionTokens: 500, TotalTokens: 2000}},
+		Provider:  "zai",
+		ModelName: "glm-4.6v",
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// glm-4.6v costs $0.005 per 1K tokens
+	if record.Estimated || record.TotalTokens != 2000 || math.Abs(record.Cost-0.01) > 1e-9 {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+	if record.Flow != "article_importer" || record.Step != "vision" || record.ArticleID != "12345" {
+		t.Errorf("Expected tags from metadata, got %+v", record)
+	}
+
+	records, err := ledger.Query(Filter{ArticleID: "12345"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(records) != 1 || records[0].TotalTokens != 2000 {
+		t.Errorf("Expected the record to be persisted, got %+v", records)
+	}
+}
+
+func TestLedger_EstimatesMissingUsage(t *testing.T) {
+	ledger := openTestLedger(t)
+
+	record, err := ledger.Record(&Call{
+		Request: textRequest("describe the dress", nil),
+		Response: &interfaces.PonchoModelResponse{
+			Message: &interfaces.PonchoMessage{
+				Role:    interfaces.PonchoRoleAssistant,
+				Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "a red summer dress"}},
+			},
+		},
+		Provider:  "deepseek",
+		ModelName: "deepseek-chat",
+		Streaming: true,
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	if !record.Estimated || record.PromptTokens == 0 || record.CompletionTokens == 0 || record.Cost == 0 {
+		t.Errorf("Expected estimated tokens and cost, got %+v", record)
+	}
+
+	cached, err := ledger.Record(&Call{
+		Request: textRequest("describe the dress", nil),
+		Response: &interfaces.PonchoModelResponse{
+			Usage:    &interfaces.PonchoUsage{TotalTokens: 100},
+			Metadata: map[string]interface{}{"cache_hit": true},
+		},
+		Provider:  "deepseek",
+		ModelName: "deepseek-chat",
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if !cached.CacheHit || cached.Cost != 0 {
+		t.Errorf("Expected cache hit without cost, got %+v", cached)
+	}
+}
+
+func TestReadFile_SkipsTruncatedLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	content := `{"timestamp":"2026-03-01T10:00:00Z","model":"vision","total_tokens":10,"cost":0.5}
+{"timestamp":"2026-03-01T11:00:00Z","model":"vis`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write ledger: %v", err)
+	}
+
+	records, err := ReadFile(path, Filter{})
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("Expected 1 record, got %d", len(records))
+	}
+
+	// A corrupt line in the middle is an error
+	if err := os.WriteFile(path, []byte("{bad\n"+content), 0644); err != nil {
+		t.Fatalf("Failed to write ledger: %v", err)
+	}
+	if _, err := ReadFile(path, Filter{}); err == nil {
+		t.Error("Expected error for corrupt record")
+	}
+}
+
+func TestReports(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := []*Record{
+		{Timestamp: day.Add(time.Hour), Flow: "article_importer", ArticleID: "1", TotalTokens: 100, Cost: 0.1},
+		{Timestamp: day.Add(2 * time.Hour), Flow: "article_importer", ArticleID: "2", TotalTokens: 200, Cost: 0.2},
+		{Timestamp: day.Add(25 * time.Hour), Flow: "sketch", ArticleID: "1", TotalTokens: 50, Cost: 0.05, CacheHit: true},
+	}
+
+	days := DailyTotals(records)
+	if len(days) != 2 || days[0].Date != "2026-03-01" || days[0].Requests != 2 || days[1].TotalTokens != 50 {
+		t.Errorf("Unexpected daily totals: %+v, %+v", days[0], days[1])
+	}
+
+	byFlow := CostByFlow(records)
+	if math.Abs(byFlow["article_importer"].Cost-0.3) > 1e-9 || byFlow["sketch"].CacheHits != 1 {
+		t.Errorf("Unexpected cost by flow: %+v", byFlow)
+	}
+
+	byArticle := CostByArticle(records)
+	if math.Abs(byArticle["1"].Cost-0.15) > 1e-9 {
+		t.Errorf("Unexpected cost for article 1: %+v", byArticle["1"])
+	}
+
+	filter := Day(day.Add(26 * time.Hour))
+	var matched int
+	for _, record := range records {
+		if filter.Match(record) {
+			matched++
+		}
+	}
+	if matched != 1 {
+		t.Errorf("Expected 1 record on the second day, got %d", matched)
+	}
+}
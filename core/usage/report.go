@@ -0,0 +1,95 @@
+package usage
+
+import (
+	"sort"
+	"time"
+)
+
+// dateLayout is the day format of DailyTotal.Date
+const dateLayout = "2006-01-02"
+
+// Totals aggregates usage records
+type Totals struct {
+	Requests         int     `json:"requests"`
+	CacheHits        int     `json:"cache_hits"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// Add adds a record to the totals
+func (t *Totals) Add(record *Record) {
+	t.Requests++
+	if record.CacheHit {
+		t.CacheHits++
+	}
+	t.PromptTokens += record.PromptTokens
+	t.CompletionTokens += record.CompletionTokens
+	t.TotalTokens += record.TotalTokens
+	t.Cost += record.Cost
+}
+
+// DailyTotal is the usage of a single UTC day
+type DailyTotal struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Totals
+}
+
+// Total sums all records
+func Total(records []*Record) *Totals {
+	totals := &Totals{}
+	for _, record := range records {
+		totals.Add(record)
+	}
+	return totals
+}
+
+// DailyTotals sums records per UTC day, oldest day first
+func DailyTotals(records []*Record) []*DailyTotal {
+	byDate := Summarize(records, func(record *Record) string {
+		return record.Timestamp.UTC().Format(dateLayout)
+	})
+
+	days := make([]*DailyTotal, 0, len(byDate))
+	for date, totals := range byDate {
+		days = append(days, &DailyTotal{Date: date, Totals: *totals})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	return days
+}
+
+// CostByFlow sums records per flow; calls made outside flows are grouped under ""
+func CostByFlow(records []*Record) map[string]*Totals {
+	return Summarize(records, func(record *Record) string { return record.Flow })
+}
+
+// CostByArticle sums records per article ID; untagged calls are grouped under ""
+func CostByArticle(records []*Record) map[string]*Totals {
+	return Summarize(records, func(record *Record) string { return record.ArticleID })
+}
+
+// CostByModel sums records per model
+func CostByModel(records []*Record) map[string]*Totals {
+	return Summarize(records, func(record *Record) string { return record.Model })
+}
+
+// Summarize sums records grouped by key
+func Summarize(records []*Record, key func(record *Record) string) map[string]*Totals {
+	groups := make(map[string]*Totals)
+	for _, record := range records {
+		k := key(record)
+		if groups[k] == nil {
+			groups[k] = &Totals{}
+		}
+		groups[k].Add(record)
+	}
+	return groups
+}
+
+// Day returns the [From, To) filter covering the UTC day of t
+func Day(t time.Time) Filter {
+	from := t.UTC().Truncate(24 * time.Hour)
+	return Filter{From: from, To: from.Add(24 * time.Hour)}
+}
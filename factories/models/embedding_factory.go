@@ -0,0 +1,169 @@
+package models
+
+// Embedding model factories create PonchoEmbeddingModel instances from the
+// "embedding_models" configuration section. They share ModelConfig with the
+// generation models; only api_key, model_name, base_url, timeout and the
+// custom parameters below are used.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+	"github.com/ilkoid/PonchoAiFramework/models/ollama"
+	"github.com/ilkoid/PonchoAiFramework/models/openai"
+)
+
+// OpenAIEmbeddingModelFactory creates OpenAI-compatible embedding model instances
+type OpenAIEmbeddingModelFactory struct{}
+
+// NewOpenAIEmbeddingModelFactory creates a new OpenAI embedding model factory
+func NewOpenAIEmbeddingModelFactory() *OpenAIEmbeddingModelFactory {
+	return &OpenAIEmbeddingModelFactory{}
+}
+
+// CreateEmbeddingModel creates an OpenAI embedding model instance from configuration
+func (f *OpenAIEmbeddingModelFactory) CreateEmbeddingModel(config *interfaces.ModelConfig) (interfaces.PonchoEmbeddingModel, error) {
+	if err := f.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	model := openai.NewOpenAIEmbeddingModel()
+	if err := model.Initialize(context.Background(), embeddingConfigMap(config)); err != nil {
+		return nil, fmt.Errorf("failed to initialize OpenAI embedding model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetProvider returns the provider this factory supports
+func (f *OpenAIEmbeddingModelFactory) GetProvider() string {
+	return "openai"
+}
+
+// ValidateConfig validates OpenAI embedding configuration
+func (f *OpenAIEmbeddingModelFactory) ValidateConfig(config *interfaces.ModelConfig) error {
+	if config.Provider != "openai" {
+		return fmt.Errorf("invalid provider for OpenAI embedding factory: %s", config.Provider)
+	}
+
+	if config.APIKey == "" && config.BaseURL == "" {
+		return fmt.Errorf("api_key is required for the OpenAI API")
+	}
+
+	return validateEmbeddingCustomParams(config.CustomParams, "dimensions", "max_batch_size")
+}
+
+// OllamaEmbeddingModelFactory creates Ollama embedding model instances
+type OllamaEmbeddingModelFactory struct{}
+
+// NewOllamaEmbeddingModelFactory creates a new Ollama embedding model factory
+func NewOllamaEmbeddingModelFactory() *OllamaEmbeddingModelFactory {
+	return &OllamaEmbeddingModelFactory{}
+}
+
+// CreateEmbeddingModel creates an Ollama embedding model instance from configuration
+func (f *OllamaEmbeddingModelFactory) CreateEmbeddingModel(config *interfaces.ModelConfig) (interfaces.PonchoEmbeddingModel, error) {
+	if err := f.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	model := ollama.NewOllamaEmbeddingModel()
+	if err := model.Initialize(context.Background(), embeddingConfigMap(config)); err != nil {
+		return nil, fmt.Errorf("failed to initialize Ollama embedding model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetProvider returns the provider this factory supports
+func (f *OllamaEmbeddingModelFactory) GetProvider() string {
+	return "ollama"
+}
+
+// ValidateConfig validates Ollama embedding configuration
+func (f *OllamaEmbeddingModelFactory) ValidateConfig(config *interfaces.ModelConfig) error {
+	if config.Provider != "ollama" {
+		return fmt.Errorf("invalid provider for Ollama embedding factory: %s", config.Provider)
+	}
+
+	if config.ModelName == "" {
+		return fmt.Errorf("model_name is required for Ollama provider")
+	}
+
+	return validateEmbeddingCustomParams(config.CustomParams, "dimensions", "max_batch_size", "truncate", "keep_alive")
+}
+
+// FakeEmbeddingModelFactory creates deterministic fake embedding model instances
+type FakeEmbeddingModelFactory struct{}
+
+// NewFakeEmbeddingModelFactory creates a new fake embedding model factory
+func NewFakeEmbeddingModelFactory() *FakeEmbeddingModelFactory {
+	return &FakeEmbeddingModelFactory{}
+}
+
+// CreateEmbeddingModel creates a fake embedding model instance from configuration
+func (f *FakeEmbeddingModelFactory) CreateEmbeddingModel(config *interfaces.ModelConfig) (interfaces.PonchoEmbeddingModel, error) {
+	if err := f.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	model := fake.NewFakeEmbeddingModel()
+	if err := model.Initialize(context.Background(), embeddingConfigMap(config)); err != nil {
+		return nil, fmt.Errorf("failed to initialize fake embedding model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetProvider returns the provider this factory supports
+func (f *FakeEmbeddingModelFactory) GetProvider() string {
+	return "fake"
+}
+
+// ValidateConfig validates fake embedding configuration
+func (f *FakeEmbeddingModelFactory) ValidateConfig(config *interfaces.ModelConfig) error {
+	if config.Provider != "fake" {
+		return fmt.Errorf("invalid provider for fake embedding factory: %s", config.Provider)
+	}
+
+	return validateEmbeddingCustomParams(config.CustomParams, "dimensions", "max_batch_size")
+}
+
+// embeddingConfigMap converts ModelConfig to the map passed to Initialize
+func embeddingConfigMap(config *interfaces.ModelConfig) map[string]interface{} {
+	configMap := map[string]interface{}{
+		"api_key":    config.APIKey,
+		"model_name": config.ModelName,
+		"base_url":   config.BaseURL,
+		"timeout":    config.Timeout,
+	}
+
+	for k, v := range config.CustomParams {
+		configMap[k] = v
+	}
+
+	return configMap
+}
+
+// validateEmbeddingCustomParams rejects custom parameters outside allowed
+func validateEmbeddingCustomParams(params map[string]interface{}, allowed ...string) error {
+	validParams := make(map[string]bool, len(allowed))
+	for _, param := range allowed {
+		validParams[param] = true
+	}
+
+	for param, value := range params {
+		if !validParams[param] {
+			return fmt.Errorf("invalid custom parameters: unknown embedding parameter: %s", param)
+		}
+		if param == "dimensions" || param == "max_batch_size" {
+			if err := (&OllamaModelFactory{}).validatePositiveInt(value, param); err != nil {
+				return fmt.Errorf("invalid custom parameters: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -2,7 +2,8 @@ package models
 
 // ModelFactory implements the model factory for the PonchoFramework.
 // It provides factory methods for creating AI model instances from configuration.
-// It supports multiple model providers (DeepSeek, Z.AI, etc.).
+// It supports multiple model providers (DeepSeek, Z.AI, OpenAI-compatible servers, Ollama, Anthropic, Gemini)
+// and a scripted fake provider for offline tests.
 // It handles model initialization with proper configuration and credentials.
 // It provides model capability detection and validation.
 // It serves as the central mechanism for model instantiation.
@@ -16,7 +17,12 @@ import (
 	"strings"
 
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/anthropic"
 	"github.com/ilkoid/PonchoAiFramework/models/deepseek"
+	"github.com/ilkoid/PonchoAiFramework/models/fake"
+	"github.com/ilkoid/PonchoAiFramework/models/gemini"
+	"github.com/ilkoid/PonchoAiFramework/models/ollama"
+	"github.com/ilkoid/PonchoAiFramework/models/openai"
 	"github.com/ilkoid/PonchoAiFramework/models/zai"
 )
 
@@ -118,14 +124,16 @@ func (f *DeepSeekModelFactory) prepareInitConfig(config *interfaces.ModelConfig)
 func (f *DeepSeekModelFactory) validateDeepSeekCustomParams(params map[string]interface{}) error {
 	// Validate known DeepSeek parameters
 	validParams := map[string]bool{
-		"top_p":             true,
-		"frequency_penalty": true,
-		"presence_penalty":  true,
-		"stop":              true,
-		"response_format":   true,
-		"thinking":          true,
-		"logprobs":          true,
-		"top_logprobs":      true,
+		"top_p":               true,
+		"frequency_penalty":   true,
+		"presence_penalty":    true,
+		"stop":                true,
+		"response_format":     true,
+		"thinking":            true,
+		"logprobs":            true,
+		"top_logprobs":        true,
+		"requests_per_minute": true,
+		"tokens_per_minute":   true,
 	}
 
 	for param := range params {
@@ -340,14 +348,17 @@ func (f *ZAIModelFactory) prepareInitConfig(config *interfaces.ModelConfig) map[
 func (f *ZAIModelFactory) validateZAICustomParams(params map[string]interface{}) error {
 	// Validate known Z.AI parameters
 	validParams := map[string]bool{
-		"top_p":             true,
-		"frequency_penalty": true,
-		"presence_penalty":  true,
-		"stop":              true,
-		"thinking":          true,
-		"logprobs":          true,
-		"top_logprobs":      true,
-		"max_image_size":    true,
+		"top_p":               true,
+		"frequency_penalty":   true,
+		"presence_penalty":    true,
+		"stop":                true,
+		"response_format":     true,
+		"thinking":            true,
+		"logprobs":            true,
+		"top_logprobs":        true,
+		"max_image_size":      true,
+		"requests_per_minute": true,
+		"tokens_per_minute":   true,
 	}
 
 	for param := range params {
@@ -493,7 +504,8 @@ func (f *ZAIModelFactory) validatePenalty(value interface{}, paramName string) e
 	return nil
 }
 
-// OpenAIModelFactory creates OpenAI model instances (placeholder for future)
+// OpenAIModelFactory creates OpenAI model instances. With a custom base_url the
+// same factory serves self-hosted OpenAI-compatible servers (vLLM, llama.cpp, LM Studio).
 type OpenAIModelFactory struct{}
 
 // NewOpenAIModelFactory creates a new OpenAI model factory
@@ -507,19 +519,32 @@ func (f *OpenAIModelFactory) CreateModel(config *interfaces.ModelConfig) (interf
 		return nil, fmt.Errorf("invalid provider for OpenAI factory: %s", config.Provider)
 	}
 
-	// TODO: Implement actual OpenAI model creation when available
-	// For now, return a placeholder to indicate configuration is valid
-	return &PlaceholderModel{
-		name:     config.ModelName,
-		provider: config.Provider,
-		capabilities: &interfaces.ModelCapabilities{
-			Streaming: config.Supports != nil && config.Supports.Streaming,
-			Tools:     config.Supports != nil && config.Supports.Tools,
-			Vision:    config.Supports != nil && config.Supports.Vision,
-			System:    config.Supports != nil && config.Supports.System,
-			JSONMode:  config.Supports != nil && config.Supports.JSONMode,
-		},
-	}, nil
+	model := openai.NewOpenAIModel()
+
+	// Convert ModelConfig to map[string]interface{} for Initialize
+	configMap := map[string]interface{}{
+		"api_key":     config.APIKey,
+		"model_name":  config.ModelName,
+		"max_tokens":  config.MaxTokens,
+		"temperature": config.Temperature,
+		"timeout":     config.Timeout,
+		"base_url":    config.BaseURL,
+		"supports":    config.Supports,
+	}
+
+	// Add custom parameters if any
+	if config.CustomParams != nil {
+		for k, v := range config.CustomParams {
+			configMap[k] = v
+		}
+	}
+
+	// Initialize model with the provided config
+	if err := model.Initialize(context.Background(), configMap); err != nil {
+		return nil, fmt.Errorf("failed to initialize OpenAI model: %w", err)
+	}
+
+	return model, nil
 }
 
 // GetProvider returns the provider this factory supports
@@ -533,9 +558,9 @@ func (f *OpenAIModelFactory) ValidateConfig(config *interfaces.ModelConfig) erro
 		return fmt.Errorf("invalid provider for OpenAI factory: %s", config.Provider)
 	}
 
-	// Basic OpenAI validation
-	if config.Supports != nil && config.Supports.Vision {
-		visionModels := []string{"gpt-4-vision-preview", "gpt-4o", "gpt-4o-mini"}
+	// Vision support of self-hosted models is up to the server, only check OpenAI's own models
+	if config.BaseURL == "" && config.Supports != nil && config.Supports.Vision {
+		visionModels := []string{"gpt-4-vision-preview", "gpt-4o", "gpt-4o-mini", "gpt-4.1", "gpt-4.1-mini"}
 		found := false
 		for _, model := range visionModels {
 			if config.ModelName == model {
@@ -548,20 +573,475 @@ func (f *OpenAIModelFactory) ValidateConfig(config *interfaces.ModelConfig) erro
 		}
 	}
 
+	if config.BaseURL == "" && config.APIKey == "" {
+		return fmt.Errorf("api_key is required unless base_url points to a self-hosted server")
+	}
+
+	// Validate custom parameters for OpenAI
+	if config.CustomParams != nil {
+		if err := f.validateOpenAICustomParams(config.CustomParams); err != nil {
+			return fmt.Errorf("invalid custom parameters: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateOpenAICustomParams validates OpenAI-specific custom parameters
+func (f *OpenAIModelFactory) validateOpenAICustomParams(params map[string]interface{}) error {
+	validParams := map[string]bool{
+		"top_p":             true,
+		"frequency_penalty": true,
+		"presence_penalty":  true,
+		"stop":              true,
+		"response_format":   true,
+	}
+
+	for param := range params {
+		if !validParams[param] {
+			return fmt.Errorf("unknown OpenAI parameter: %s", param)
+		}
+	}
+
+	// Range checks are shared with DeepSeek, which uses the same parameter set
+	deepseekFactory := &DeepSeekModelFactory{}
+
+	if topP, ok := params["top_p"]; ok {
+		if err := deepseekFactory.validateTopP(topP); err != nil {
+			return fmt.Errorf("invalid top_p: %w", err)
+		}
+	}
+
+	if freqPenalty, ok := params["frequency_penalty"]; ok {
+		if err := deepseekFactory.validatePenalty(freqPenalty, "frequency_penalty"); err != nil {
+			return fmt.Errorf("invalid frequency_penalty: %w", err)
+		}
+	}
+
+	if presPenalty, ok := params["presence_penalty"]; ok {
+		if err := deepseekFactory.validatePenalty(presPenalty, "presence_penalty"); err != nil {
+			return fmt.Errorf("invalid presence_penalty: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// OllamaModelFactory creates Ollama model instances for locally served models.
+// No API key is needed; base_url defaults to http://localhost:11434.
+type OllamaModelFactory struct{}
+
+// NewOllamaModelFactory creates a new Ollama model factory
+func NewOllamaModelFactory() *OllamaModelFactory {
+	return &OllamaModelFactory{}
+}
+
+// CreateModel creates an Ollama model instance from configuration
+func (f *OllamaModelFactory) CreateModel(config *interfaces.ModelConfig) (interfaces.PonchoModel, error) {
+	if config.Provider != "ollama" {
+		return nil, fmt.Errorf("invalid provider for Ollama factory: %s", config.Provider)
+	}
+
+	model := ollama.NewOllamaModel()
+
+	// Convert ModelConfig to map[string]interface{} for Initialize
+	configMap := map[string]interface{}{
+		"api_key":     config.APIKey,
+		"model_name":  config.ModelName,
+		"max_tokens":  config.MaxTokens,
+		"temperature": config.Temperature,
+		"timeout":     config.Timeout,
+		"base_url":    config.BaseURL,
+	}
+
+	// Without "supports" the model detects vision from its name
+	if config.Supports != nil {
+		configMap["supports"] = config.Supports
+	}
+
+	// Add custom parameters if any
+	if config.CustomParams != nil {
+		for k, v := range config.CustomParams {
+			configMap[k] = v
+		}
+	}
+
+	// Initialize model with the provided config
+	if err := model.Initialize(context.Background(), configMap); err != nil {
+		return nil, fmt.Errorf("failed to initialize Ollama model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetProvider returns the provider this factory supports
+func (f *OllamaModelFactory) GetProvider() string {
+	return "ollama"
+}
+
+// ValidateConfig validates Ollama-specific configuration
+func (f *OllamaModelFactory) ValidateConfig(config *interfaces.ModelConfig) error {
+	if config.Provider != "ollama" {
+		return fmt.Errorf("invalid provider for Ollama factory: %s", config.Provider)
+	}
+
+	if config.ModelName == "" {
+		return fmt.Errorf("model_name is required for Ollama provider")
+	}
+
+	// Validate custom parameters for Ollama
+	if config.CustomParams != nil {
+		if err := f.validateOllamaCustomParams(config.CustomParams); err != nil {
+			return fmt.Errorf("invalid custom parameters: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateOllamaCustomParams validates Ollama-specific custom parameters
+func (f *OllamaModelFactory) validateOllamaCustomParams(params map[string]interface{}) error {
+	validParams := map[string]bool{
+		"top_p":           true,
+		"top_k":           true,
+		"num_ctx":         true,
+		"seed":            true,
+		"stop":            true,
+		"keep_alive":      true,
+		"format":          true,
+		"response_format": true,
+	}
+
+	for param := range params {
+		if !validParams[param] {
+			return fmt.Errorf("unknown Ollama parameter: %s", param)
+		}
+	}
+
+	if topP, ok := params["top_p"]; ok {
+		if err := (&DeepSeekModelFactory{}).validateTopP(topP); err != nil {
+			return fmt.Errorf("invalid top_p: %w", err)
+		}
+	}
+
+	for _, param := range []string{"top_k", "num_ctx"} {
+		if value, ok := params[param]; ok {
+			if err := f.validatePositiveInt(value, param); err != nil {
+				return err
+			}
+		}
+	}
+
+	if keepAlive, ok := params["keep_alive"]; ok {
+		if _, isString := keepAlive.(string); !isString {
+			return fmt.Errorf("keep_alive must be a duration string, e.g. \"10m\"")
+		}
+	}
+
+	return nil
+}
+
+// validatePositiveInt validates an integer parameter that must be positive
+func (f *OllamaModelFactory) validatePositiveInt(value interface{}, paramName string) error {
+	var number float64
+	switch v := value.(type) {
+	case int:
+		number = float64(v)
+	case float64:
+		number = v
+	default:
+		return fmt.Errorf("%s must be a number", paramName)
+	}
+
+	if number <= 0 || number != float64(int(number)) {
+		return fmt.Errorf("%s must be a positive integer", paramName)
+	}
+
+	return nil
+}
+
+// AnthropicModelFactory creates Anthropic model instances
+type AnthropicModelFactory struct{}
+
+// NewAnthropicModelFactory creates a new Anthropic model factory
+func NewAnthropicModelFactory() *AnthropicModelFactory {
+	return &AnthropicModelFactory{}
+}
+
+// CreateModel creates an Anthropic model instance from configuration
+func (f *AnthropicModelFactory) CreateModel(config *interfaces.ModelConfig) (interfaces.PonchoModel, error) {
+	if config.Provider != "anthropic" {
+		return nil, fmt.Errorf("invalid provider for Anthropic factory: %s", config.Provider)
+	}
+
+	model := anthropic.NewAnthropicModel()
+
+	// Convert ModelConfig to map[string]interface{} for Initialize
+	configMap := map[string]interface{}{
+		"api_key":     config.APIKey,
+		"model_name":  config.ModelName,
+		"max_tokens":  config.MaxTokens,
+		"temperature": config.Temperature,
+		"timeout":     config.Timeout,
+		"base_url":    config.BaseURL,
+		"supports":    config.Supports,
+	}
+
+	// Add custom parameters if any
+	if config.CustomParams != nil {
+		for k, v := range config.CustomParams {
+			configMap[k] = v
+		}
+	}
+
+	// Initialize model with the provided config
+	if err := model.Initialize(context.Background(), configMap); err != nil {
+		return nil, fmt.Errorf("failed to initialize Anthropic model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetProvider returns the provider this factory supports
+func (f *AnthropicModelFactory) GetProvider() string {
+	return "anthropic"
+}
+
+// ValidateConfig validates Anthropic-specific configuration
+func (f *AnthropicModelFactory) ValidateConfig(config *interfaces.ModelConfig) error {
+	if config.Provider != "anthropic" {
+		return fmt.Errorf("invalid provider for Anthropic factory: %s", config.Provider)
+	}
+
+	if config.APIKey == "" {
+		return fmt.Errorf("api_key is required for Anthropic provider")
+	}
+
+	// The Messages API accepts a narrower temperature range than OpenAI-style APIs
+	if config.Temperature < 0 || config.Temperature > 1 {
+		return fmt.Errorf("temperature must be between 0 and 1 for Anthropic provider")
+	}
+
+	// Validate custom parameters for Anthropic
+	if config.CustomParams != nil {
+		if err := f.validateAnthropicCustomParams(config.CustomParams); err != nil {
+			return fmt.Errorf("invalid custom parameters: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateAnthropicCustomParams validates Anthropic-specific custom parameters
+func (f *AnthropicModelFactory) validateAnthropicCustomParams(params map[string]interface{}) error {
+	validParams := map[string]bool{
+		"top_p": true,
+		"top_k": true,
+		"stop":  true,
+	}
+
+	for param := range params {
+		if !validParams[param] {
+			return fmt.Errorf("unknown Anthropic parameter: %s", param)
+		}
+	}
+
+	if topP, ok := params["top_p"]; ok {
+		if err := (&DeepSeekModelFactory{}).validateTopP(topP); err != nil {
+			return fmt.Errorf("invalid top_p: %w", err)
+		}
+	}
+
+	if topK, ok := params["top_k"]; ok {
+		if err := (&OllamaModelFactory{}).validatePositiveInt(topK, "top_k"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GeminiModelFactory creates Gemini model instances
+type GeminiModelFactory struct{}
+
+// NewGeminiModelFactory creates a new Gemini model factory
+func NewGeminiModelFactory() *GeminiModelFactory {
+	return &GeminiModelFactory{}
+}
+
+// CreateModel creates a Gemini model instance from configuration
+func (f *GeminiModelFactory) CreateModel(config *interfaces.ModelConfig) (interfaces.PonchoModel, error) {
+	if config.Provider != "gemini" {
+		return nil, fmt.Errorf("invalid provider for Gemini factory: %s", config.Provider)
+	}
+
+	model := gemini.NewGeminiModel()
+
+	// Convert ModelConfig to map[string]interface{} for Initialize
+	configMap := map[string]interface{}{
+		"api_key":     config.APIKey,
+		"model_name":  config.ModelName,
+		"max_tokens":  config.MaxTokens,
+		"temperature": config.Temperature,
+		"timeout":     config.Timeout,
+		"base_url":    config.BaseURL,
+		"supports":    config.Supports,
+	}
+
+	// Add custom parameters if any
+	if config.CustomParams != nil {
+		for k, v := range config.CustomParams {
+			configMap[k] = v
+		}
+	}
+
+	// Initialize model with the provided config
+	if err := model.Initialize(context.Background(), configMap); err != nil {
+		return nil, fmt.Errorf("failed to initialize Gemini model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetProvider returns the provider this factory supports
+func (f *GeminiModelFactory) GetProvider() string {
+	return "gemini"
+}
+
+// ValidateConfig validates Gemini-specific configuration
+func (f *GeminiModelFactory) ValidateConfig(config *interfaces.ModelConfig) error {
+	if config.Provider != "gemini" {
+		return fmt.Errorf("invalid provider for Gemini factory: %s", config.Provider)
+	}
+
+	if config.APIKey == "" {
+		return fmt.Errorf("api_key is required for Gemini provider")
+	}
+
+	if config.Temperature < 0 || config.Temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2 for Gemini provider")
+	}
+
+	// Validate custom parameters for Gemini
+	if config.CustomParams != nil {
+		if err := f.validateGeminiCustomParams(config.CustomParams); err != nil {
+			return fmt.Errorf("invalid custom parameters: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateGeminiCustomParams validates Gemini-specific custom parameters
+func (f *GeminiModelFactory) validateGeminiCustomParams(params map[string]interface{}) error {
+	validParams := map[string]bool{
+		"top_p":           true,
+		"top_k":           true,
+		"stop":            true,
+		"response_format": true,
+		"response_schema": true,
+	}
+
+	for param := range params {
+		if !validParams[param] {
+			return fmt.Errorf("unknown Gemini parameter: %s", param)
+		}
+	}
+
+	if topP, ok := params["top_p"]; ok {
+		if err := (&DeepSeekModelFactory{}).validateTopP(topP); err != nil {
+			return fmt.Errorf("invalid top_p: %w", err)
+		}
+	}
+
+	if topK, ok := params["top_k"]; ok {
+		if err := (&OllamaModelFactory{}).validatePositiveInt(topK, "top_k"); err != nil {
+			return err
+		}
+	}
+
+	if schema, ok := params["response_schema"]; ok {
+		if _, isMap := schema.(map[string]interface{}); !isMap {
+			return fmt.Errorf("response_schema must be an object")
+		}
+	}
+
+	return nil
+}
+
+// FakeModelFactory creates scripted fake model instances for offline tests
+type FakeModelFactory struct{}
+
+// NewFakeModelFactory creates a new fake model factory
+func NewFakeModelFactory() *FakeModelFactory {
+	return &FakeModelFactory{}
+}
+
+// CreateModel creates a fake model instance from configuration
+func (f *FakeModelFactory) CreateModel(config *interfaces.ModelConfig) (interfaces.PonchoModel, error) {
+	if config.Provider != "fake" {
+		return nil, fmt.Errorf("invalid provider for fake factory: %s", config.Provider)
+	}
+
+	model := fake.NewFakeModel()
+
+	// Convert ModelConfig to map[string]interface{} for Initialize
+	configMap := map[string]interface{}{
+		"api_key":     config.APIKey,
+		"model_name":  config.ModelName,
+		"max_tokens":  config.MaxTokens,
+		"temperature": config.Temperature,
+		"supports":    config.Supports,
+	}
+
+	// Add custom parameters if any
+	if config.CustomParams != nil {
+		for k, v := range config.CustomParams {
+			configMap[k] = v
+		}
+	}
+
+	// Initialize model with the provided config
+	if err := model.Initialize(context.Background(), configMap); err != nil {
+		return nil, fmt.Errorf("failed to initialize fake model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetProvider returns the provider this factory supports
+func (f *FakeModelFactory) GetProvider() string {
+	return "fake"
+}
+
+// ValidateConfig validates fake model configuration
+func (f *FakeModelFactory) ValidateConfig(config *interfaces.ModelConfig) error {
+	if config.Provider != "fake" {
+		return fmt.Errorf("invalid provider for fake factory: %s", config.Provider)
+	}
+
+	for param := range config.CustomParams {
+		if param != "script" && param != "script_file" {
+			return fmt.Errorf("invalid custom parameters: unknown fake parameter: %s", param)
+		}
+	}
+
 	return nil
 }
 
 // ModelFactoryManager manages all model factories
 type ModelFactoryManager struct {
-	factories map[string]interfaces.ModelFactory
-	logger    interfaces.Logger
+	factories          map[string]interfaces.ModelFactory
+	embeddingFactories map[string]interfaces.EmbeddingModelFactory
+	logger             interfaces.Logger
 }
 
 // NewModelFactoryManager creates a new model factory manager
 func NewModelFactoryManager(logger interfaces.Logger) *ModelFactoryManager {
 	manager := &ModelFactoryManager{
-		factories: make(map[string]interfaces.ModelFactory),
-		logger:    logger,
+		factories:          make(map[string]interfaces.ModelFactory),
+		embeddingFactories: make(map[string]interfaces.EmbeddingModelFactory),
+		logger:             logger,
 	}
 
 	// Register default factories
@@ -574,10 +1054,18 @@ func NewModelFactoryManager(logger interfaces.Logger) *ModelFactoryManager {
 func (m *ModelFactoryManager) registerDefaultFactories() {
 	m.RegisterFactory("deepseek", NewDeepSeekModelFactory())
 	m.RegisterFactory("zai", NewZAIModelFactory())
-	m.RegisterFactory("openai", NewOpenAIModelFactory()) // Placeholder for future
+	m.RegisterFactory("openai", NewOpenAIModelFactory())
+	m.RegisterFactory("ollama", NewOllamaModelFactory())
+	m.RegisterFactory("anthropic", NewAnthropicModelFactory())
+	m.RegisterFactory("gemini", NewGeminiModelFactory())
+	m.RegisterFactory("fake", NewFakeModelFactory())
 
 	m.logger.Info("Default model factories registered",
-		"providers", []string{"deepseek", "zai", "openai"})
+		"providers", []string{"deepseek", "zai", "openai", "ollama", "anthropic", "gemini", "fake"})
+
+	m.RegisterEmbeddingFactory("openai", NewOpenAIEmbeddingModelFactory())
+	m.RegisterEmbeddingFactory("ollama", NewOllamaEmbeddingModelFactory())
+	m.RegisterEmbeddingFactory("fake", NewFakeEmbeddingModelFactory())
 }
 
 // RegisterFactory registers a model factory
@@ -667,82 +1155,66 @@ func (m *ModelFactoryManager) ValidateConfig(config *interfaces.ModelConfig) err
 	return factory.ValidateConfig(config)
 }
 
-// PlaceholderModel is a temporary placeholder for model implementations
-// This will be replaced with actual model implementations in Phase 2
-type PlaceholderModel struct {
-	name         string
-	provider     string
-	capabilities *interfaces.ModelCapabilities
-}
+// RegisterEmbeddingFactory registers an embedding model factory
+func (m *ModelFactoryManager) RegisterEmbeddingFactory(provider string, factory interfaces.EmbeddingModelFactory) error {
+	if provider == "" {
+		return fmt.Errorf("provider cannot be empty")
+	}
 
-// Name returns model name
-func (p *PlaceholderModel) Name() string {
-	return p.name
-}
+	if factory == nil {
+		return fmt.Errorf("factory cannot be nil")
+	}
 
-// Provider returns model provider
-func (p *PlaceholderModel) Provider() string {
-	return p.provider
-}
+	m.embeddingFactories[provider] = factory
 
-// MaxTokens returns maximum tokens supported
-func (p *PlaceholderModel) MaxTokens() int {
-	return 4000 // Default placeholder value
+	m.logger.Info("Embedding model factory registered", "provider", provider)
+	return nil
 }
 
-// DefaultTemperature returns default temperature setting
-func (p *PlaceholderModel) DefaultTemperature() float32 {
-	return 0.7 // Default placeholder value
-}
+// GetEmbeddingFactory returns an embedding factory for the specified provider
+func (m *ModelFactoryManager) GetEmbeddingFactory(provider string) (interfaces.EmbeddingModelFactory, error) {
+	factory, exists := m.embeddingFactories[provider]
+	if !exists {
+		return nil, fmt.Errorf("no embedding factory registered for provider: %s", provider)
+	}
 
-// Initialize initializes model (placeholder implementation)
-func (p *PlaceholderModel) Initialize(ctx context.Context, config map[string]interface{}) error {
-	// Placeholder implementation - does nothing
-	return nil
+	return factory, nil
 }
 
-// Shutdown shuts down model (placeholder implementation)
-func (p *PlaceholderModel) Shutdown(ctx context.Context) error {
-	// Placeholder implementation - does nothing
-	return nil
-}
+// GetSupportedEmbeddingProviders returns list of supported embedding providers
+func (m *ModelFactoryManager) GetSupportedEmbeddingProviders() []string {
+	providers := make([]string, 0, len(m.embeddingFactories))
+	for provider := range m.embeddingFactories {
+		providers = append(providers, provider)
+	}
 
-// Temperature returns temperature setting (deprecated, use DefaultTemperature)
-func (p *PlaceholderModel) Temperature() float32 {
-	return p.DefaultTemperature()
+	return providers
 }
 
-// SupportsStreaming returns whether model supports streaming
-func (p *PlaceholderModel) SupportsStreaming() bool {
-	return p.capabilities != nil && p.capabilities.Streaming
-}
+// CreateEmbeddingModel creates an embedding model instance using the appropriate factory
+func (m *ModelFactoryManager) CreateEmbeddingModel(config *interfaces.ModelConfig) (interfaces.PonchoEmbeddingModel, error) {
+	if config == nil {
+		return nil, fmt.Errorf("model config cannot be nil")
+	}
 
-// SupportsTools returns whether model supports tools
-func (p *PlaceholderModel) SupportsTools() bool {
-	return p.capabilities != nil && p.capabilities.Tools
-}
+	if config.Provider == "" {
+		return nil, fmt.Errorf("model provider is required")
+	}
 
-// SupportsVision returns whether model supports vision
-func (p *PlaceholderModel) SupportsVision() bool {
-	return p.capabilities != nil && p.capabilities.Vision
-}
+	factory, err := m.GetEmbeddingFactory(config.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding factory for provider %s: %w", config.Provider, err)
+	}
 
-// SupportsSystemRole returns whether model supports system role
-func (p *PlaceholderModel) SupportsSystemRole() bool {
-	return p.capabilities != nil && p.capabilities.System
-}
+	model, err := factory.CreateEmbeddingModel(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding model with factory %s: %w", config.Provider, err)
+	}
 
-// SupportsJSONMode returns whether model supports JSON mode
-func (p *PlaceholderModel) SupportsJSONMode() bool {
-	return p.capabilities != nil && p.capabilities.JSONMode
-}
+	m.logger.Info("Embedding model created successfully",
+		"provider", config.Provider,
+		"model_name", config.ModelName,
+		"model", model.Name())
 
-// Generate generates a response (placeholder implementation)
-func (p *PlaceholderModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
-	return nil, fmt.Errorf("placeholder model %s does not support generation - implementation pending in Phase 2", p.name)
+	return model, nil
 }
-
-// GenerateStreaming generates a streaming response (placeholder implementation)
-func (p *PlaceholderModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
-	return fmt.Errorf("placeholder model %s does not support streaming - implementation pending in Phase 2", p.name)
-}
\ No newline at end of file
@@ -3,6 +3,7 @@ module github.com/ilkoid/PonchoAiFramework
 go 1.25.1
 
 require (
+	github.com/disintegration/imaging v1.6.2
 	github.com/minio/minio-go/v7 v7.0.63
 	github.com/stretchr/testify v1.9.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -10,7 +11,6 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/disintegration/imaging v1.6.2 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
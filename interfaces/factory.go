@@ -12,6 +12,18 @@ type ModelFactory interface {
 	GetProvider() string
 }
 
+// EmbeddingModelFactory defines the interface for creating embedding model instances
+type EmbeddingModelFactory interface {
+	// CreateEmbeddingModel creates an embedding model instance from configuration
+	CreateEmbeddingModel(config *ModelConfig) (PonchoEmbeddingModel, error)
+
+	// ValidateConfig validates model-specific configuration
+	ValidateConfig(config *ModelConfig) error
+
+	// GetProvider returns the provider this factory supports
+	GetProvider() string
+}
+
 // ToolFactory defines the interface for creating tool instances
 type ToolFactory interface {
 	// CreateTool creates a tool instance from configuration
@@ -42,6 +54,22 @@ type ModelFactoryManager interface {
 	ValidateConfig(config *ModelConfig) error
 }
 
+// EmbeddingFactoryManager is implemented by model factory managers that also
+// manage embedding model factories
+type EmbeddingFactoryManager interface {
+	// RegisterEmbeddingFactory registers an embedding model factory for a provider
+	RegisterEmbeddingFactory(provider string, factory EmbeddingModelFactory) error
+
+	// GetEmbeddingFactory returns an embedding factory for the specified provider
+	GetEmbeddingFactory(provider string) (EmbeddingModelFactory, error)
+
+	// GetSupportedEmbeddingProviders returns list of supported embedding providers
+	GetSupportedEmbeddingProviders() []string
+
+	// CreateEmbeddingModel creates an embedding model using the appropriate factory
+	CreateEmbeddingModel(config *ModelConfig) (PonchoEmbeddingModel, error)
+}
+
 // ToolFactoryManager defines the interface for managing tool factories
 type ToolFactoryManager interface {
 	// RegisterFactory registers a tool factory for a type
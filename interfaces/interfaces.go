@@ -7,6 +7,7 @@
 //
 // Core interfaces:
 // - PonchoModel: AI model abstraction with streaming and capabilities
+// - PonchoEmbeddingModel: Batched text embedding abstraction
 // - PonchoTool: Tool execution interface with schema validation
 // - PonchoFlow: Workflow orchestration interface with dependencies
 // - PonchoFramework: Main framework orchestrator
@@ -20,6 +21,7 @@ package interfaces
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -50,6 +52,23 @@ type PonchoModel interface {
 	Shutdown(ctx context.Context) error
 }
 
+// PonchoEmbeddingModel defines the interface for text embedding models
+type PonchoEmbeddingModel interface {
+	// Embed returns one vector per input, in input order. Implementations split
+	// inputs larger than MaxBatchSize into several provider calls.
+	Embed(ctx context.Context, inputs []string) ([][]float32, *PonchoUsage, error)
+
+	// Metadata
+	Name() string
+	Provider() string
+	Dimensions() int   // vector length, 0 until known for servers that do not declare it
+	MaxBatchSize() int // inputs per provider call
+
+	// Lifecycle
+	Initialize(ctx context.Context, config map[string]interface{}) error
+	Shutdown(ctx context.Context) error
+}
+
 // PonchoTool defines the interface for all tools in the framework
 type PonchoTool interface {
 	// Identity
@@ -75,6 +94,10 @@ type PonchoTool interface {
 	Shutdown(ctx context.Context) error
 }
 
+// ErrInvalidToolInput is wrapped in the error PonchoFramework.ExecuteTool returns
+// when the tool's Validate rejects the input
+var ErrInvalidToolInput = errors.New("input validation failed")
+
 // PonchoFlow defines the interface for all workflows in the framework
 type PonchoFlow interface {
 	// Identity
@@ -103,6 +126,13 @@ type PonchoFlow interface {
 // PonchoStreamCallback is the callback function for streaming responses
 type PonchoStreamCallback func(chunk *PonchoStreamChunk) error
 
+// HealthChecker is an optional interface for models, tools and flows that can probe
+// their own health (credentials, upstream availability, etc.).
+// PonchoFramework.Health calls it with a per-probe timeout; nil means healthy.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
 // Registry interfaces
 
 // PonchoModelRegistry defines the interface for model registry
@@ -114,6 +144,15 @@ type PonchoModelRegistry interface {
 	Clear() error
 }
 
+// PonchoEmbeddingModelRegistry defines the interface for embedding model registry
+type PonchoEmbeddingModelRegistry interface {
+	Register(name string, model PonchoEmbeddingModel) error
+	Get(name string) (PonchoEmbeddingModel, error)
+	List() []string
+	Unregister(name string) error
+	Clear() error
+}
+
 // PonchoToolRegistry defines the interface for tool registry
 type PonchoToolRegistry interface {
 	Register(name string, tool PonchoTool) error
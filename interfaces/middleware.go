@@ -0,0 +1,45 @@
+package interfaces
+
+import "context"
+
+// ModelHandler handles a unary generation request
+type ModelHandler func(ctx context.Context, req *PonchoModelRequest) (*PonchoModelResponse, error)
+
+// StreamHandler handles a streaming generation request
+type StreamHandler func(ctx context.Context, req *PonchoModelRequest, callback PonchoStreamCallback) error
+
+// ModelMiddleware wraps a unary handler with cross-cutting behavior
+type ModelMiddleware func(next ModelHandler) ModelHandler
+
+// StreamMiddleware wraps a streaming handler with cross-cutting behavior
+type StreamMiddleware func(next StreamHandler) StreamHandler
+
+// Middleware bundles interceptors for unary and streaming model calls.
+// Either interceptor may be nil, in which case that kind of call passes through.
+type Middleware struct {
+	Name   string
+	Unary  ModelMiddleware
+	Stream StreamMiddleware
+}
+
+// ChainModel wraps handler with the given middlewares.
+// The first middleware is the outermost one and sees the request first.
+func ChainModel(handler ModelHandler, middlewares ...*Middleware) ModelHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil && middlewares[i].Unary != nil {
+			handler = middlewares[i].Unary(handler)
+		}
+	}
+	return handler
+}
+
+// ChainStream wraps handler with the given middlewares.
+// The first middleware is the outermost one and sees the request first.
+func ChainStream(handler StreamHandler, middlewares ...*Middleware) StreamHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil && middlewares[i].Stream != nil {
+			handler = middlewares[i].Stream(handler)
+		}
+	}
+	return handler
+}
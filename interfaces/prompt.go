@@ -62,6 +62,17 @@ type PromptTemplate struct {
 	MaxTokens   *int              `json:"max_tokens,omitempty"`
 	Temperature *float32          `json:"temperature,omitempty"`
 	
+	// Generation parameters copied to the model request
+	TopP             *float32             `json:"top_p,omitempty"`
+	Stop             []string             `json:"stop,omitempty"`
+	Seed             *int                 `json:"seed,omitempty"`
+	FrequencyPenalty *float32             `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float32             `json:"presence_penalty,omitempty"`
+	ResponseFormat   PonchoResponseFormat `json:"response_format,omitempty"`
+	Thinking         *bool                `json:"thinking,omitempty"`
+	LogProbs         *bool                `json:"logprobs,omitempty"`
+	TopLogProbs      *int                 `json:"top_logprobs,omitempty"`
+	
 	// Fashion-specific settings
 	FashionContext *FashionContext `json:"fashion_context,omitempty"`
 	
@@ -36,9 +36,11 @@ const (
 type PonchoContentType string
 
 const (
-	PonchoContentTypeText  PonchoContentType = "text"
-	PonchoContentTypeMedia PonchoContentType = "media"
-	PonchoContentTypeTool  PonchoContentType = "tool_call"
+	PonchoContentTypeText       PonchoContentType = "text"
+	PonchoContentTypeMedia      PonchoContentType = "media"
+	PonchoContentTypeTool       PonchoContentType = "tool_call"
+	PonchoContentTypeToolResult PonchoContentType = "tool_result"
+	PonchoContentTypeReasoning  PonchoContentType = "reasoning" // model thinking, carried in Text
 )
 
 // PonchoFinishReason represents the reason why generation finished
@@ -51,6 +53,14 @@ const (
 	PonchoFinishReasonError  PonchoFinishReason = "error"
 )
 
+// PonchoResponseFormat represents the output format requested from a model
+type PonchoResponseFormat string
+
+const (
+	PonchoResponseFormatText       PonchoResponseFormat = "text"
+	PonchoResponseFormatJSONObject PonchoResponseFormat = "json_object"
+)
+
 // PonchoModelRequest represents a request to an AI model
 type PonchoModelRequest struct {
 	Model       string                 `json:"model"`
@@ -60,6 +70,18 @@ type PonchoModelRequest struct {
 	Stream      bool                   `json:"stream,omitempty"`
 	Tools       []*PonchoToolDef       `json:"tools,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	// Generation parameters; unset ones fall back to the model's defaults.
+	// Providers reject parameters they do not support.
+	TopP             *float32             `json:"top_p,omitempty"`
+	Stop             []string             `json:"stop,omitempty"`
+	Seed             *int                 `json:"seed,omitempty"`
+	FrequencyPenalty *float32             `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float32             `json:"presence_penalty,omitempty"`
+	ResponseFormat   PonchoResponseFormat `json:"response_format,omitempty"`
+	Thinking         *bool                `json:"thinking,omitempty"`
+	LogProbs         *bool                `json:"logprobs,omitempty"`
+	TopLogProbs      *int                 `json:"top_logprobs,omitempty"`
 }
 
 // PonchoModelResponse represents a response from an AI model
@@ -67,14 +89,38 @@ type PonchoModelResponse struct {
 	Message      *PonchoMessage         `json:"message"`
 	Usage        *PonchoUsage           `json:"usage"`
 	FinishReason PonchoFinishReason     `json:"finish_reason"`
+	LogProbs     *PonchoLogProbs        `json:"logprobs,omitempty"` // set when logprobs were requested and returned
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// PonchoLogProbs holds the log probabilities of generated tokens
+type PonchoLogProbs struct {
+	Content   []PonchoTokenLogProb `json:"content,omitempty"`
+	Reasoning []PonchoTokenLogProb `json:"reasoning,omitempty"` // thinking tokens, where reported
+}
+
+// PonchoTokenLogProb is a generated token with its log probability and the most
+// likely alternatives at its position (as many as top_logprobs asked for)
+type PonchoTokenLogProb struct {
+	Token       string             `json:"token"`
+	LogProb     float64            `json:"logprob"`
+	Bytes       []int              `json:"bytes,omitempty"`
+	TopLogProbs []PonchoTopLogProb `json:"top_logprobs,omitempty"`
+}
+
+// PonchoTopLogProb is an alternative token with its log probability
+type PonchoTopLogProb struct {
+	Token   string  `json:"token"`
+	LogProb float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+}
+
 // PonchoMessage represents a message in a conversation
 type PonchoMessage struct {
-	Role    PonchoRole           `json:"role"`
-	Content []*PonchoContentPart `json:"content"`
-	Name    *string              `json:"name,omitempty"`
+	Role       PonchoRole           `json:"role"`
+	Content    []*PonchoContentPart `json:"content"`
+	Name       *string              `json:"name,omitempty"`
+	ToolCallID string               `json:"tool_call_id,omitempty"` // tool messages: ID of the answered tool call
 }
 
 // PonchoContentPart represents a part of message content
@@ -83,6 +129,8 @@ type PonchoContentPart struct {
 	Text  string            `json:"text,omitempty"`
 	Media *PonchoMediaPart  `json:"media,omitempty"`
 	Tool  *PonchoToolPart   `json:"tool,omitempty"`
+
+	ToolResult *PonchoToolResultPart `json:"tool_result,omitempty"`
 }
 
 // PonchoMediaPart represents media content (images, videos, etc.)
@@ -98,6 +146,14 @@ type PonchoToolPart struct {
 	Args map[string]interface{} `json:"args"`
 }
 
+// PonchoToolResultPart represents the result of a tool call in content
+type PonchoToolResultPart struct {
+	ToolCallID string      `json:"tool_call_id"`
+	Name       string      `json:"name,omitempty"`
+	Content    interface{} `json:"content,omitempty"` // string or any JSON-encodable value
+	IsError    bool        `json:"is_error,omitempty"`
+}
+
 // PonchoToolDef represents a tool definition for model
 type PonchoToolDef struct {
 	Name        string                 `json:"name"`
@@ -118,6 +174,7 @@ type PonchoStreamChunk struct {
 	Usage        *PonchoUsage           `json:"usage,omitempty"`
 	FinishReason PonchoFinishReason     `json:"finish_reason,omitempty"`
 	Done         bool                   `json:"done"`
+	LogProbs     *PonchoLogProbs        `json:"logprobs,omitempty"` // logprobs of the tokens in Delta
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -139,6 +196,9 @@ type PonchoFrameworkConfig struct {
 	Logging          *LoggingConfig          `json:"logging"`
 	Metrics          *MetricsConfig          `json:"metrics"`
 	Cache            *CacheConfig            `json:"cache"`
+	Usage            *UsageConfig            `json:"usage,omitempty"`
+	Budgets          *BudgetConfig           `json:"budgets,omitempty"`
+	Tokenizers       *TokenizersConfig       `json:"tokenizers,omitempty"`
 	Security         *SecurityConfig         `json:"security"`
 	S3               *S3Config               `json:"s3"`
 	Wildberries      *WildberriesConfig      `json:"wildberries"`
@@ -148,16 +208,18 @@ type PonchoFrameworkConfig struct {
 
 // ModelConfig represents configuration for a specific model
 type ModelConfig struct {
-	Provider     string                 `json:"provider"`
-	ModelName    string                 `json:"model_name"`
-	APIKey       string                 `json:"api_key"`
-	BaseURL      string                 `json:"base_url,omitempty"`
-	MaxTokens    int                    `json:"max_tokens"`
-	Temperature  float32                `json:"temperature"`
-	Timeout      string                 `json:"timeout"`
-	Retry        *RetryConfig           `json:"retry,omitempty"`
-	Supports     *ModelCapabilities     `json:"supports"`
-	CustomParams map[string]interface{} `json:"custom_params,omitempty"`
+	Provider       string                 `json:"provider"`
+	ModelName      string                 `json:"model_name"`
+	APIKey         string                 `json:"api_key"`
+	BaseURL        string                 `json:"base_url,omitempty"`
+	MaxTokens      int                    `json:"max_tokens"`
+	Temperature    float32                `json:"temperature"`
+	Timeout        string                 `json:"timeout"`
+	Retry          *RetryConfig           `json:"retry,omitempty"`
+	CircuitBreaker *CircuitBreakerConfig  `json:"circuit_breaker,omitempty"`
+	Supports       *ModelCapabilities     `json:"supports"`
+	Middleware     []string               `json:"middleware,omitempty"` // named middlewares applied to this model
+	CustomParams   map[string]interface{} `json:"custom_params,omitempty"`
 }
 
 // ModelCapabilities represents model capabilities
@@ -226,6 +288,12 @@ type RetryConfig struct {
 	MaxDelay    string `json:"max_delay,omitempty"`
 }
 
+// CircuitBreakerConfig represents circuit breaker configuration for a model
+type CircuitBreakerConfig struct {
+	MaxFailures  int    `json:"max_failures"`  // consecutive failures that open the circuit
+	ResetTimeout string `json:"reset_timeout"` // how long the circuit stays open before a trial request
+}
+
 // ToolCacheConfig represents cache configuration for tools
 type ToolCacheConfig struct {
 	TTL     string `json:"ttl"`
@@ -248,12 +316,47 @@ type MetricsConfig struct {
 
 // CacheConfig represents cache configuration
 type CacheConfig struct {
-	Type     string `json:"type"` // memory, redis
+	Type     string `json:"type"` // memory, file, redis
 	RedisURL string `json:"redis_url,omitempty"`
+	Dir      string `json:"dir,omitempty"` // directory for file cache
 	TTL      string `json:"ttl"`
 	MaxSize  int    `json:"max_size"`
 }
 
+// UsageConfig represents token usage ledger configuration
+type UsageConfig struct {
+	Path string `json:"path"` // append-only JSONL file
+}
+
+// BudgetConfig represents model spend budgets
+type BudgetConfig struct {
+	FlowExecution *BudgetLimit            `json:"flow_execution,omitempty"` // per flow execution
+	Flows         map[string]*BudgetLimit `json:"flows,omitempty"`          // per flow execution, by flow name
+	TenantDaily   *BudgetLimit            `json:"tenant_daily,omitempty"`   // per tenant per UTC day
+	Tenants       map[string]*BudgetLimit `json:"tenants,omitempty"`        // per UTC day, by tenant
+	Models        map[string]*BudgetLimit `json:"models,omitempty"`         // per model per UTC day
+}
+
+// BudgetLimit caps tokens and estimated cost; zero values are unlimited
+type BudgetLimit struct {
+	MaxTokens int     `json:"max_tokens,omitempty"`
+	MaxCost   float64 `json:"max_cost,omitempty"`
+}
+
+// TokenizersConfig maps models to tokenizer files for accurate token counting
+type TokenizersConfig struct {
+	Files     map[string]*TokenizerConfig `json:"files,omitempty"`     // by tokenizer name
+	Calibrate bool                        `json:"calibrate,omitempty"` // compare estimates with provider usage
+}
+
+// TokenizerConfig represents one tokenizer loaded from disk
+type TokenizerConfig struct {
+	Type       string   `json:"type"`                  // tiktoken, bpe, sentencepiece
+	Path       string   `json:"path"`                  // .tiktoken, vocab.json, .model or .vocab file
+	MergesPath string   `json:"merges_path,omitempty"` // merges.txt, bpe only
+	Models     []string `json:"models"`                // model names; a trailing "*" matches a prefix
+}
+
 // SecurityConfig represents security configuration
 type SecurityConfig struct {
 	APIKeys      []string          `json:"api_keys"`
@@ -263,7 +366,9 @@ type SecurityConfig struct {
 
 // RateLimitConfig represents rate limiting configuration
 type RateLimitConfig struct {
-	RequestsPerMinute int `json:"requests_per_minute"`
+	RequestsPerMinute int            `json:"requests_per_minute"`                 // per API key
+	Burst             int            `json:"burst,omitempty"`                     // bucket size, defaults to RequestsPerMinute
+	ModelLimits       map[string]int `json:"model_requests_per_minute,omitempty"` // per model, across all keys
 }
 
 // EncryptionConfig represents encryption configuration
@@ -287,6 +392,13 @@ type WildberriesConfig struct {
 	Timeout int    `json:"timeout"`
 }
 
+// Health status values used by PonchoHealthStatus and ComponentHealth
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
 // PonchoHealthStatus represents health status of framework
 type PonchoHealthStatus struct {
 	Status     string                      `json:"status"`
@@ -305,12 +417,15 @@ type ComponentHealth struct {
 
 // PonchoMetrics represents framework metrics
 type PonchoMetrics struct {
-	GeneratedRequests *GenerationMetrics `json:"generated_requests"`
-	ToolExecutions    *ToolMetrics       `json:"tool_executions"`
-	FlowExecutions    *FlowMetrics       `json:"flow_executions"`
-	Errors            *ErrorMetrics      `json:"errors"`
-	System            *SystemMetrics     `json:"system"`
-	Timestamp         time.Time          `json:"timestamp"`
+	GeneratedRequests *GenerationMetrics            `json:"generated_requests"`
+	ToolExecutions    *ToolMetrics                  `json:"tool_executions"`
+	FlowExecutions    *FlowMetrics                  `json:"flow_executions"`
+	Errors            *ErrorMetrics                 `json:"errors"`
+	System            *SystemMetrics                `json:"system"`
+	Cache             *CacheMetrics                 `json:"cache,omitempty"`
+	Resilience        map[string]*ResilienceMetrics `json:"resilience,omitempty"`  // by model
+	RateLimits        map[string]*RateLimitMetrics  `json:"rate_limits,omitempty"` // by provider account
+	Timestamp         time.Time                     `json:"timestamp"`
 }
 
 // GenerationMetrics represents generation-related metrics
@@ -325,28 +440,94 @@ type GenerationMetrics struct {
 
 // ModelMetrics represents metrics for a specific model
 type ModelMetrics struct {
-	Requests    int64   `json:"requests"`
-	SuccessRate float64 `json:"success_rate"`
-	AvgLatency  float64 `json:"avg_latency_ms"`
-	TotalTokens int64   `json:"total_tokens"`
+	Requests    int64             `json:"requests"`
+	ErrorCount  int64             `json:"error_count"`
+	SuccessRate float64           `json:"success_rate"`
+	AvgLatency  float64           `json:"avg_latency_ms"`
+	TotalTokens int64             `json:"total_tokens"`
+	Latency     *HistogramMetrics `json:"latency,omitempty"`
 }
 
 // ToolMetrics represents tool execution metrics
 type ToolMetrics struct {
-	TotalExecutions int64            `json:"total_executions"`
-	SuccessCount    int64            `json:"success_count"`
-	ErrorCount      int64            `json:"error_count"`
-	AvgLatency      float64          `json:"avg_latency_ms"`
-	ByTool          map[string]int64 `json:"by_tool"`
+	TotalExecutions int64                        `json:"total_executions"`
+	SuccessCount    int64                        `json:"success_count"`
+	ErrorCount      int64                        `json:"error_count"`
+	AvgLatency      float64                      `json:"avg_latency_ms"`
+	ByTool          map[string]int64             `json:"by_tool"`
+	ErrorsByTool    map[string]int64             `json:"errors_by_tool,omitempty"`
+	LatencyByTool   map[string]*HistogramMetrics `json:"latency_by_tool,omitempty"`
 }
 
 // FlowMetrics represents flow execution metrics
 type FlowMetrics struct {
-	TotalExecutions int64            `json:"total_executions"`
-	SuccessCount    int64            `json:"success_count"`
-	ErrorCount      int64            `json:"error_count"`
-	AvgLatency      float64          `json:"avg_latency_ms"`
-	ByFlow          map[string]int64 `json:"by_flow"`
+	TotalExecutions int64                        `json:"total_executions"`
+	SuccessCount    int64                        `json:"success_count"`
+	ErrorCount      int64                        `json:"error_count"`
+	AvgLatency      float64                      `json:"avg_latency_ms"`
+	ByFlow          map[string]int64             `json:"by_flow"`
+	ErrorsByFlow    map[string]int64             `json:"errors_by_flow,omitempty"`
+	LatencyByFlow   map[string]*HistogramMetrics `json:"latency_by_flow,omitempty"`
+}
+
+// DefaultLatencyBuckets are the default histogram upper bounds (in seconds) for
+// model, tool and flow latencies. LLM calls range from sub-second to minutes.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// HistogramMetrics represents a latency histogram with fixed buckets (in seconds).
+// Counts[i] is the number of observations <= Buckets[i] (non-cumulative per bucket),
+// observations above the last bucket are only reflected in Count and Sum.
+type HistogramMetrics struct {
+	Buckets []float64 `json:"buckets"`
+	Counts  []int64   `json:"counts"`
+	Sum     float64   `json:"sum"`
+	Count   int64     `json:"count"`
+}
+
+// NewHistogramMetrics creates a histogram with the given bucket upper bounds
+func NewHistogramMetrics(buckets []float64) *HistogramMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	bounds := make([]float64, len(buckets))
+	copy(bounds, buckets)
+
+	return &HistogramMetrics{
+		Buckets: bounds,
+		Counts:  make([]int64, len(bounds)),
+	}
+}
+
+// Observe records a single observation
+func (h *HistogramMetrics) Observe(value float64) {
+	h.Sum += value
+	h.Count++
+
+	for i, bound := range h.Buckets {
+		if value <= bound {
+			h.Counts[i]++
+			return
+		}
+	}
+}
+
+// Clone returns a deep copy of the histogram
+func (h *HistogramMetrics) Clone() *HistogramMetrics {
+	if h == nil {
+		return nil
+	}
+
+	clone := &HistogramMetrics{
+		Buckets: make([]float64, len(h.Buckets)),
+		Counts:  make([]int64, len(h.Counts)),
+		Sum:     h.Sum,
+		Count:   h.Count,
+	}
+	copy(clone.Buckets, h.Buckets)
+	copy(clone.Counts, h.Counts)
+
+	return clone
 }
 
 // ErrorMetrics represents error-related metrics
@@ -375,4 +556,34 @@ type SystemMetrics struct {
 	HeapAlloc      int64   `json:"heap_alloc_bytes"`
 }
 
+// ResilienceMetrics represents the retry and circuit breaker state of a model
+type ResilienceMetrics struct {
+	Retries           int64  `json:"retries"`
+	CircuitState      string `json:"circuit_state,omitempty"` // closed, open, half_open; empty without a breaker
+	CircuitFailures   int    `json:"circuit_failures"`
+	CircuitOpens      int64  `json:"circuit_opens"`
+	CircuitRejections int64  `json:"circuit_rejections"`
+}
+
+// RateLimitMetrics represents the client-side rate limit headroom of a provider account
+type RateLimitMetrics struct {
+	RequestsPerMinute int   `json:"requests_per_minute"` // 0 = unlimited
+	TokensPerMinute   int   `json:"tokens_per_minute"`   // 0 = unlimited
+	RemainingRequests int   `json:"remaining_requests"`
+	RemainingTokens   int   `json:"remaining_tokens"`
+	PausedMs          int64 `json:"paused_ms"` // left of a Retry-After or reset pause
+	Waits             int64 `json:"waits"`
+	WaitTimeMs        int64 `json:"wait_time_ms"`
+}
+
+// CacheMetrics represents response cache metrics
+type CacheMetrics struct {
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRate    float64 `json:"hit_rate"`
+	Entries    int64   `json:"entries"`
+	MaxEntries int64   `json:"max_entries"`
+	Evictions  int64   `json:"evictions"`
+}
+
 
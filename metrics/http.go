@@ -0,0 +1,145 @@
+package metrics
+
+// This file implements HTTP request instrumentation for the /metrics endpoint.
+// HTTPMetrics wraps an http.Handler and records http_requests_total and the
+// http_request_duration_seconds histogram, labelled by method, route pattern and
+// status code. Route patterns (e.g. "POST /api/v1/tools/{name}/execute") are used
+// instead of raw paths to keep label cardinality bounded.
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// unmatchedRoute is the path label for requests that did not match any route
+const unmatchedRoute = "unmatched"
+
+type httpRequestKey struct {
+	method string
+	path   string
+	status int
+}
+
+type httpRouteKey struct {
+	method string
+	path   string
+}
+
+// HTTPMetrics collects HTTP request metrics
+type HTTPMetrics struct {
+	requests  map[httpRequestKey]int64
+	durations map[httpRouteKey]*interfaces.HistogramMetrics
+	buckets   []float64
+	mutex     sync.Mutex
+}
+
+// NewHTTPMetrics creates HTTP metrics with the given latency buckets.
+// If buckets is empty, interfaces.DefaultLatencyBuckets is used.
+func NewHTTPMetrics(buckets []float64) *HTTPMetrics {
+	if len(buckets) == 0 {
+		buckets = interfaces.DefaultLatencyBuckets
+	}
+
+	return &HTTPMetrics{
+		requests:  make(map[httpRequestKey]int64),
+		durations: make(map[httpRouteKey]*interfaces.HistogramMetrics),
+		buckets:   buckets,
+	}
+}
+
+// Middleware instruments the given handler.
+// The route label is read from r.Pattern, which http.ServeMux sets while routing.
+func (h *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			path := r.Pattern
+			if path == "" {
+				path = unmatchedRoute
+			}
+			h.Observe(r.Method, path, recorder.status, time.Since(start))
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// Observe records a single HTTP request
+func (h *HTTPMetrics) Observe(method, path string, status int, duration time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.requests[httpRequestKey{method: method, path: path, status: status}]++
+
+	routeKey := httpRouteKey{method: method, path: path}
+	histogram, exists := h.durations[routeKey]
+	if !exists {
+		histogram = interfaces.NewHistogramMetrics(h.buckets)
+		h.durations[routeKey] = histogram
+	}
+	histogram.Observe(duration.Seconds())
+}
+
+// Collect implements Collector
+func (h *HTTPMetrics) Collect(ctx context.Context, enc *PrometheusEncoder) error {
+	h.mutex.Lock()
+	requests := make([]Sample, 0, len(h.requests))
+	for key, count := range h.requests {
+		requests = append(requests, Sample{
+			Labels: Labels{"method": key.method, "path": key.path, "status": strconv.Itoa(key.status)},
+			Value:  float64(count),
+		})
+	}
+	durations := make([]HistogramSample, 0, len(h.durations))
+	for key, histogram := range h.durations {
+		durations = append(durations, HistogramSample{
+			Labels:    Labels{"method": key.method, "path": key.path},
+			Histogram: histogram.Clone(),
+		})
+	}
+	h.mutex.Unlock()
+
+	enc.Counter("http_requests_total", "Total number of HTTP requests.", requests)
+	enc.Histogram("http_request_duration_seconds", "HTTP request latency in seconds.", durations)
+
+	return enc.Err()
+}
+
+// statusRecorder captures the response status code
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming (Server-Sent Events) responses working through the recorder
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
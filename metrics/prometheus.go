@@ -0,0 +1,449 @@
+package metrics
+
+// This file implements a native Prometheus text exposition format (version 0.0.4)
+// exporter for the PonchoFramework. It renders interfaces.PonchoMetrics as real
+// Prometheus series so the shipped prometheus/alert_rules.yml can be evaluated
+// against the service.
+//
+// Key features:
+// - Dependency-free text encoder for counters, gauges and histograms
+// - Collector interface so any component can contribute series to /metrics
+// - Framework collector: per model/tool/flow counters, token counters,
+//   latency histograms, error counters by type/component and system gauges
+// - Retry and circuit breaker series per guarded model
+// - Exporter implementing http.Handler for the /metrics endpoint
+//
+// Series names follow the alert rules (model_requests_total,
+// model_requests_failed_total, model_request_duration_seconds, model_tokens_used_total,
+// model_availability); framework-wide series use the poncho_ prefix.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Labels represents metric labels
+type Labels map[string]string
+
+// Sample is a single counter or gauge value
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// HistogramSample is a single histogram series
+type HistogramSample struct {
+	Labels    Labels
+	Histogram *interfaces.HistogramMetrics
+}
+
+// Collector contributes metric families to an exporter
+type Collector interface {
+	Collect(ctx context.Context, enc *PrometheusEncoder) error
+}
+
+// PrometheusEncoder writes metric families in the Prometheus text format
+type PrometheusEncoder struct {
+	w   io.Writer
+	err error
+}
+
+// NewPrometheusEncoder creates a new encoder writing to w
+func NewPrometheusEncoder(w io.Writer) *PrometheusEncoder {
+	return &PrometheusEncoder{w: w}
+}
+
+// Err returns the first write error encountered by the encoder
+func (e *PrometheusEncoder) Err() error {
+	return e.err
+}
+
+// Counter writes a counter family
+func (e *PrometheusEncoder) Counter(name, help string, samples []Sample) {
+	e.writeFamily(name, help, "counter", samples)
+}
+
+// Gauge writes a gauge family
+func (e *PrometheusEncoder) Gauge(name, help string, samples []Sample) {
+	e.writeFamily(name, help, "gauge", samples)
+}
+
+// Histogram writes a histogram family with cumulative buckets, _sum and _count
+func (e *PrometheusEncoder) Histogram(name, help string, samples []HistogramSample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		return formatLabels(samples[i].Labels) < formatLabels(samples[j].Labels)
+	})
+
+	e.writeHeader(name, help, "histogram")
+
+	for _, sample := range samples {
+		h := sample.Histogram
+		if h == nil {
+			continue
+		}
+
+		var cumulative int64
+		for i, bound := range h.Buckets {
+			if i < len(h.Counts) {
+				cumulative += h.Counts[i]
+			}
+			e.writeLine(name+"_bucket", withLabel(sample.Labels, "le", formatValue(bound)), float64(cumulative))
+		}
+		e.writeLine(name+"_bucket", withLabel(sample.Labels, "le", "+Inf"), float64(h.Count))
+		e.writeLine(name+"_sum", sample.Labels, h.Sum)
+		e.writeLine(name+"_count", sample.Labels, float64(h.Count))
+	}
+}
+
+// writeFamily writes a counter or gauge family sorted by labels
+func (e *PrometheusEncoder) writeFamily(name, help, metricType string, samples []Sample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		return formatLabels(samples[i].Labels) < formatLabels(samples[j].Labels)
+	})
+
+	e.writeHeader(name, help, metricType)
+	for _, sample := range samples {
+		e.writeLine(name, sample.Labels, sample.Value)
+	}
+}
+
+// writeHeader writes the HELP and TYPE lines of a family
+func (e *PrometheusEncoder) writeHeader(name, help, metricType string) {
+	e.printf("# HELP %s %s\n", name, escapeHelp(help))
+	e.printf("# TYPE %s %s\n", name, metricType)
+}
+
+// writeLine writes a single sample line
+func (e *PrometheusEncoder) writeLine(name string, labels Labels, value float64) {
+	e.printf("%s%s %s\n", name, formatLabels(labels), formatValue(value))
+}
+
+// printf writes formatted output, remembering the first error
+func (e *PrometheusEncoder) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
+
+// formatLabels renders labels as {k="v",...} with sorted keys
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(labels[k]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+// withLabel returns a copy of labels with an extra label
+func withLabel(labels Labels, key, value string) Labels {
+	result := make(Labels, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
+
+// formatValue renders a sample value
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// MetricsSource provides framework metrics (implemented by PonchoFramework)
+type MetricsSource interface {
+	Metrics(ctx context.Context) (*interfaces.PonchoMetrics, error)
+}
+
+// FrameworkCollector exports interfaces.PonchoMetrics
+type FrameworkCollector struct {
+	source MetricsSource
+}
+
+// NewFrameworkCollector creates a collector for framework metrics
+func NewFrameworkCollector(source MetricsSource) *FrameworkCollector {
+	return &FrameworkCollector{source: source}
+}
+
+// Collect implements Collector
+func (c *FrameworkCollector) Collect(ctx context.Context, enc *PrometheusEncoder) error {
+	m, err := c.source.Metrics(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get framework metrics: %w", err)
+	}
+
+	WritePonchoMetrics(enc, m)
+	return enc.Err()
+}
+
+// WritePonchoMetrics writes all framework metric families
+func WritePonchoMetrics(enc *PrometheusEncoder, m *interfaces.PonchoMetrics) {
+	if m == nil {
+		return
+	}
+
+	if gen := m.GeneratedRequests; gen != nil {
+		var requests, failed, tokens, availability []Sample
+		var latency []HistogramSample
+
+		for model, mm := range gen.ByModel {
+			labels := Labels{"model": model}
+			requests = append(requests, Sample{labels, float64(mm.Requests)})
+			failed = append(failed, Sample{labels, float64(mm.ErrorCount)})
+			tokens = append(tokens, Sample{labels, float64(mm.TotalTokens)})
+			availability = append(availability, Sample{labels, mm.SuccessRate})
+			if mm.Latency != nil {
+				latency = append(latency, HistogramSample{labels, mm.Latency})
+			}
+		}
+
+		enc.Counter("model_requests_total", "Total number of model generation requests.", requests)
+		enc.Counter("model_requests_failed_total", "Total number of failed model generation requests.", failed)
+		enc.Counter("model_tokens_used_total", "Total number of tokens used by model.", tokens)
+		enc.Gauge("model_availability", "Ratio of successful model requests.", availability)
+		enc.Histogram("model_request_duration_seconds", "Model generation latency in seconds.", latency)
+	}
+
+	if tools := m.ToolExecutions; tools != nil {
+		writeExecutionMetrics(enc, "tool", tools.ByTool, tools.ErrorsByTool, tools.LatencyByTool)
+	}
+
+	if flows := m.FlowExecutions; flows != nil {
+		writeExecutionMetrics(enc, "flow", flows.ByFlow, flows.ErrorsByFlow, flows.LatencyByFlow)
+	}
+
+	if errs := m.Errors; errs != nil {
+		enc.Counter("poncho_errors_total", "Total number of framework errors.",
+			[]Sample{{Value: float64(errs.TotalErrors)}})
+		enc.Counter("poncho_errors_by_type_total", "Framework errors by error type.",
+			countersToSamples("type", errs.ByType))
+		enc.Counter("poncho_errors_by_component_total", "Framework errors by component.",
+			countersToSamples("component", errs.ByComponent))
+	}
+
+	if m.System != nil {
+		writeSystemMetrics(enc, "poncho", m.System)
+	}
+
+	if c := m.Cache; c != nil {
+		enc.Counter("cache_hits_total", "Total number of response cache hits.", []Sample{{Value: float64(c.Hits)}})
+		enc.Counter("cache_misses_total", "Total number of response cache misses.", []Sample{{Value: float64(c.Misses)}})
+		enc.Counter("cache_evictions_total", "Total number of response cache evictions.", []Sample{{Value: float64(c.Evictions)}})
+		enc.Gauge("cache_hit_rate", "Ratio of response cache hits to lookups.", []Sample{{Value: c.HitRate}})
+		enc.Gauge("cache_entries", "Number of cached responses.", []Sample{{Value: float64(c.Entries)}})
+	}
+
+	if len(m.Resilience) > 0 {
+		var retries, opens, rejections, states []Sample
+		for model, rm := range m.Resilience {
+			labels := Labels{"model": model}
+			retries = append(retries, Sample{labels, float64(rm.Retries)})
+			if rm.CircuitState == "" {
+				continue
+			}
+			opens = append(opens, Sample{labels, float64(rm.CircuitOpens)})
+			rejections = append(rejections, Sample{labels, float64(rm.CircuitRejections)})
+			states = append(states, Sample{labels, circuitStateValue(rm.CircuitState)})
+		}
+
+		enc.Counter("model_retries_total", "Total number of retried model calls.", retries)
+		enc.Counter("model_circuit_opens_total", "Total number of times the model circuit breaker opened.", opens)
+		enc.Counter("model_circuit_rejections_total", "Total number of model calls rejected by an open circuit breaker.", rejections)
+		enc.Gauge("model_circuit_state", "Model circuit breaker state: 0 closed, 1 half-open, 2 open.", states)
+	}
+
+	if len(m.RateLimits) > 0 {
+		var limits, remaining, paused, waits, waitSeconds []Sample
+		for account, rl := range m.RateLimits {
+			// Limiters are named provider@host#key-hash
+			provider, _, _ := strings.Cut(account, "@")
+			labels := Labels{"provider": provider, "account": account}
+			waits = append(waits, Sample{labels, float64(rl.Waits)})
+			waitSeconds = append(waitSeconds, Sample{labels, float64(rl.WaitTimeMs) / 1000})
+			paused = append(paused, Sample{labels, float64(rl.PausedMs) / 1000})
+			if rl.RequestsPerMinute > 0 {
+				requests := Labels{"provider": provider, "account": account, "kind": "requests"}
+				limits = append(limits, Sample{requests, float64(rl.RequestsPerMinute)})
+				remaining = append(remaining, Sample{requests, float64(rl.RemainingRequests)})
+			}
+			if rl.TokensPerMinute > 0 {
+				tokens := Labels{"provider": provider, "account": account, "kind": "tokens"}
+				limits = append(limits, Sample{tokens, float64(rl.TokensPerMinute)})
+				remaining = append(remaining, Sample{tokens, float64(rl.RemainingTokens)})
+			}
+		}
+
+		enc.Gauge("provider_rate_limit_per_minute", "Provider rate limit per minute by kind (requests, tokens).", limits)
+		enc.Gauge("provider_rate_limit_remaining", "Rate limit budget left by kind (requests, tokens).", remaining)
+		enc.Gauge("provider_rate_limit_paused_seconds", "Seconds left of a provider-requested pause.", paused)
+		enc.Counter("provider_rate_limit_waits_total", "Total number of calls queued by the client-side rate limiter.", waits)
+		enc.Counter("provider_rate_limit_wait_seconds_total", "Total time calls were queued by the client-side rate limiter.", waitSeconds)
+	}
+}
+
+// circuitStateValue maps a circuit breaker state name to its gauge value
+func circuitStateValue(state string) float64 {
+	switch state {
+	case common.CircuitStateOpen.String():
+		return 2
+	case common.CircuitStateHalfOpen.String():
+		return 1
+	default:
+		return 0
+	}
+}
+
+// writeExecutionMetrics writes counters and latency histograms for tools or flows
+func writeExecutionMetrics(enc *PrometheusEncoder, kind string, executions, errors map[string]int64, latency map[string]*interfaces.HistogramMetrics) {
+	var total, failed []Sample
+	var durations []HistogramSample
+
+	for name, count := range executions {
+		labels := Labels{kind: name}
+		total = append(total, Sample{labels, float64(count)})
+		failed = append(failed, Sample{labels, float64(errors[name])})
+		if h := latency[name]; h != nil {
+			durations = append(durations, HistogramSample{labels, h})
+		}
+	}
+
+	enc.Counter(kind+"_executions_total", fmt.Sprintf("Total number of %s executions.", kind), total)
+	enc.Counter(kind+"_executions_failed_total", fmt.Sprintf("Total number of failed %s executions.", kind), failed)
+	enc.Histogram(kind+"_execution_duration_seconds", fmt.Sprintf("%s execution latency in seconds.", strings.ToUpper(kind[:1])+kind[1:]), durations)
+}
+
+// writeSystemMetrics writes runtime gauges with the given prefix
+func writeSystemMetrics(enc *PrometheusEncoder, prefix string, system *interfaces.SystemMetrics) {
+	enc.Gauge(prefix+"_memory_usage_bytes", "Bytes of allocated heap objects.", []Sample{{Value: float64(system.MemoryUsage)}})
+	enc.Gauge(prefix+"_goroutines", "Number of goroutines.", []Sample{{Value: float64(system.GoroutineCount)}})
+	enc.Counter(prefix+"_gc_cycles_total", "Number of completed GC cycles.", []Sample{{Value: float64(system.GCCount)}})
+	enc.Gauge(prefix+"_heap_size_bytes", "Bytes of heap memory obtained from the OS.", []Sample{{Value: float64(system.HeapSize)}})
+	enc.Gauge(prefix+"_heap_alloc_bytes", "Bytes of allocated heap objects.", []Sample{{Value: float64(system.HeapAlloc)}})
+}
+
+// countersToSamples converts a counter map into samples with a single label
+func countersToSamples(label string, counters map[string]int64) []Sample {
+	samples := make([]Sample, 0, len(counters))
+	for key, value := range counters {
+		samples = append(samples, Sample{Labels{label: key}, float64(value)})
+	}
+	return samples
+}
+
+// Exporter serves registered collectors on /metrics
+type Exporter struct {
+	collectors []Collector
+	logger     interfaces.Logger
+	mutex      sync.RWMutex
+}
+
+// NewExporter creates a new Prometheus exporter
+func NewExporter(logger interfaces.Logger) *Exporter {
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	return &Exporter{
+		collectors: make([]Collector, 0),
+		logger:     logger,
+	}
+}
+
+// Register adds a collector to the exporter
+func (e *Exporter) Register(collector Collector) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.collectors = append(e.collectors, collector)
+}
+
+// Write renders all collectors into w
+func (e *Exporter) Write(ctx context.Context, w io.Writer) error {
+	e.mutex.RLock()
+	collectors := make([]Collector, len(e.collectors))
+	copy(collectors, e.collectors)
+	e.mutex.RUnlock()
+
+	enc := NewPrometheusEncoder(w)
+	for _, collector := range collectors {
+		if err := collector.Collect(ctx, enc); err != nil {
+			return err
+		}
+	}
+
+	return enc.Err()
+}
+
+// ServeHTTP implements http.Handler
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := e.Write(r.Context(), &buf); err != nil {
+		e.logger.Error("Failed to export metrics", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+type staticSource struct {
+	metrics *interfaces.PonchoMetrics
+}
+
+func (s *staticSource) Metrics(ctx context.Context) (*interfaces.PonchoMetrics, error) {
+	return s.metrics, nil
+}
+
+func TestEncoderCounterAndLabels(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewPrometheusEncoder(&buf)
+
+	enc.Counter("requests_total", "Total requests.", []Sample{
+		{Labels: Labels{"model": "b"}, Value: 2},
+		{Labels: Labels{"model": `a"x\y` + "\n"}, Value: 1},
+	})
+	require.NoError(t, enc.Err())
+
+	expected := "# HELP requests_total Total requests.\n" +
+		"# TYPE requests_total counter\n" +
+		`requests_total{model="a\"x\\y\n"} 1` + "\n" +
+		`requests_total{model="b"} 2` + "\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestEncoderSkipsEmptyFamilies(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewPrometheusEncoder(&buf)
+
+	enc.Counter("empty_total", "Nothing.", nil)
+	enc.Histogram("empty_seconds", "Nothing.", nil)
+
+	assert.Empty(t, buf.String())
+}
+
+func TestEncoderHistogram(t *testing.T) {
+	h := interfaces.NewHistogramMetrics([]float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(0.7)
+	h.Observe(3)
+
+	var buf bytes.Buffer
+	enc := NewPrometheusEncoder(&buf)
+	enc.Histogram("latency_seconds", "Latency.", []HistogramSample{{Labels: Labels{"model": "m"}, Histogram: h}})
+	require.NoError(t, enc.Err())
+
+	output := buf.String()
+	assert.Contains(t, output, "# TYPE latency_seconds histogram\n")
+	assert.Contains(t, output, `latency_seconds_bucket{le="0.1",model="m"} 1`+"\n")
+	assert.Contains(t, output, `latency_seconds_bucket{le="1",model="m"} 3`+"\n")
+	assert.Contains(t, output, `latency_seconds_bucket{le="+Inf",model="m"} 4`+"\n")
+	assert.Contains(t, output, `latency_seconds_sum{model="m"} 4.25`+"\n")
+	assert.Contains(t, output, `latency_seconds_count{model="m"} 4`+"\n")
+}
+
+func TestWritePonchoMetrics(t *testing.T) {
+	latency := interfaces.NewHistogramMetrics(nil)
+	latency.Observe(0.3)
+
+	source := &staticSource{metrics: &interfaces.PonchoMetrics{
+		GeneratedRequests: &interfaces.GenerationMetrics{
+			ByModel: map[string]*interfaces.ModelMetrics{
+				"deepseek-chat": {Requests: 4, ErrorCount: 1, TotalTokens: 120, SuccessRate: 0.75, Latency: latency},
+			},
+		},
+		ToolExecutions: &interfaces.ToolMetrics{
+			ByTool:       map[string]int64{"s3": 2},
+			ErrorsByTool: map[string]int64{"s3": 1},
+		},
+		Errors: &interfaces.ErrorMetrics{
+			TotalErrors: 1,
+			ByType:      map[string]int64{"generation": 1},
+			ByComponent: map[string]int64{"model": 1},
+		},
+		System: &interfaces.SystemMetrics{GoroutineCount: 7},
+		Cache:  &interfaces.CacheMetrics{Hits: 3, Misses: 1, HitRate: 0.75, Evictions: 2},
+		Resilience: map[string]*interfaces.ResilienceMetrics{
+			"glm": {Retries: 5, CircuitState: "open", CircuitOpens: 1, CircuitRejections: 3},
+		},
+		RateLimits: map[string]*interfaces.RateLimitMetrics{
+			"zai@api.z.ai/api/paas/v4#1a2b3c4d": {RequestsPerMinute: 120, RemainingRequests: 40, PausedMs: 1500, Waits: 6, WaitTimeMs: 2500},
+		},
+	}}
+
+	exporter := NewExporter(interfaces.NewNoOpLogger())
+	exporter.Register(NewFrameworkCollector(source))
+
+	var buf bytes.Buffer
+	require.NoError(t, exporter.Write(context.Background(), &buf))
+	output := buf.String()
+
+	assert.Contains(t, output, `model_requests_total{model="deepseek-chat"} 4`)
+	assert.Contains(t, output, `model_requests_failed_total{model="deepseek-chat"} 1`)
+	assert.Contains(t, output, `model_tokens_used_total{model="deepseek-chat"} 120`)
+	assert.Contains(t, output, `model_availability{model="deepseek-chat"} 0.75`)
+	assert.Contains(t, output, `model_request_duration_seconds_bucket{le="0.5",model="deepseek-chat"} 1`)
+	assert.Contains(t, output, `tool_executions_total{tool="s3"} 2`)
+	assert.Contains(t, output, `tool_executions_failed_total{tool="s3"} 1`)
+	assert.Contains(t, output, `poncho_errors_by_type_total{type="generation"} 1`)
+	assert.Contains(t, output, `poncho_errors_by_component_total{component="model"} 1`)
+	assert.Contains(t, output, "poncho_goroutines 7\n")
+	assert.NotContains(t, output, "cpu_usage")
+	assert.Contains(t, output, "cache_hit_rate 0.75\n")
+	assert.Contains(t, output, "cache_evictions_total 2\n")
+	assert.Contains(t, output, `model_retries_total{model="glm"} 5`)
+	assert.Contains(t, output, `model_circuit_rejections_total{model="glm"} 3`)
+	assert.Contains(t, output, `model_circuit_state{model="glm"} 2`)
+	assert.Contains(t, output, `provider_rate_limit_per_minute{account="zai@api.z.ai/api/paas/v4#1a2b3c4d",kind="requests",provider="zai"} 120`)
+	assert.Contains(t, output, `provider_rate_limit_remaining{account="zai@api.z.ai/api/paas/v4#1a2b3c4d",kind="requests",provider="zai"} 40`)
+	assert.NotContains(t, output, `kind="tokens"`)
+	assert.Contains(t, output, `provider_rate_limit_paused_seconds{account="zai@api.z.ai/api/paas/v4#1a2b3c4d",provider="zai"} 1.5`)
+	assert.Contains(t, output, `provider_rate_limit_wait_seconds_total{account="zai@api.z.ai/api/paas/v4#1a2b3c4d",provider="zai"} 2.5`)
+}
+
+func TestHTTPMetricsMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	httpMetrics := NewHTTPMetrics(nil)
+	handler := httpMetrics.Middleware(mux)
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	exporter := NewExporter(interfaces.NewNoOpLogger())
+	exporter.Register(httpMetrics)
+
+	rec := httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+
+	output := rec.Body.String()
+	assert.Contains(t, output, `http_requests_total{method="GET",path="GET /items/{id}",status="418"} 2`)
+	assert.Contains(t, output, `http_requests_total{method="GET",path="unmatched",status="404"} 1`)
+	assert.Contains(t, output, `http_request_duration_seconds_count{method="GET",path="GET /items/{id}"} 2`)
+	assert.False(t, strings.Contains(output, "/items/1"), "raw paths must not be used as labels")
+}
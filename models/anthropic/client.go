@@ -0,0 +1,300 @@
+// Package anthropic provides an Anthropic Messages API client for PonchoFramework.
+// This file implements the HTTP client used by AnthropicModel.
+//
+// Key Features:
+// - x-api-key authentication with a pinned anthropic-version header
+// - HTTP client with connection pooling and retry logic
+// - Non-streaming and SSE streaming message requests
+// - API error messages preserved in ModelError
+// - Structured logging of requests, responses and errors
+//
+// Usage Example:
+//
+//	config := &common.CommonModelConfig{
+//	    APIKey: "sk-ant-...",
+//	    Model: "claude-sonnet-4-5",
+//	    MaxTokens: 4000,
+//	    Temperature: 0.7,
+//	    Timeout: 60 * time.Second,
+//	}
+//	client, _ := NewAnthropicClient(config, logger)
+//	resp, err := client.CreateMessage(ctx, request)
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// AnthropicClient represents a client for the Anthropic Messages API
+type AnthropicClient struct {
+	httpClient  *common.HTTPClient
+	config      *common.CommonModelConfig
+	logger      interfaces.Logger
+	apiKey      string
+	baseURL     string
+	rateLimiter *common.RateLimiter
+	tokenizer   *common.Tokenizer
+
+	releaseRateLimiter func()
+}
+
+// NewAnthropicClient creates a new Anthropic client
+func NewAnthropicClient(config *common.CommonModelConfig, logger interfaces.Logger) (*AnthropicClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	// Validate configuration
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Create HTTP client from a copy of the shared defaults
+	httpConfig := common.DefaultHTTPConfig
+	httpConfig.Timeout = config.Timeout
+	httpConfig.UserAgent = "PonchoFramework-Anthropic/1.0"
+
+	retryConfig := common.DefaultRetryConfig
+	retryConfig.MaxAttempts = 3
+	retryConfig.BaseDelay = 1 * time.Second
+	retryConfig.MaxDelay = 30 * time.Second
+
+	httpClient, err := common.NewHTTPClient(&httpConfig, retryConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = common.AnthropicDefaultBaseURL
+	}
+
+	client := &AnthropicClient{
+		httpClient: httpClient,
+		config:     config,
+		logger:     logger,
+		apiKey:     config.APIKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		tokenizer:  common.NewTokenizer(logger),
+	}
+	client.claimRateLimiter()
+
+	logger.Info("Anthropic client created",
+		"model", config.Model,
+		"base_url", client.baseURL,
+		"timeout", config.Timeout)
+
+	return client, nil
+}
+
+// validateConfig validates Anthropic configuration
+func validateConfig(config *common.CommonModelConfig) error {
+	if config.APIKey == "" {
+		return fmt.Errorf("API key is required")
+	}
+
+	if config.Model == "" {
+		return fmt.Errorf("model name is required")
+	}
+
+	if config.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be positive")
+	}
+
+	// The Messages API accepts temperature between 0 and 1
+	if config.Temperature < 0 || config.Temperature > 1 {
+		return fmt.Errorf("temperature must be between 0 and 1")
+	}
+
+	if config.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+// Close closes the Anthropic client and cleans up resources
+func (c *AnthropicClient) Close() error {
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	if c.httpClient != nil {
+		return c.httpClient.Close()
+	}
+	return nil
+}
+
+// GetConfig returns the client configuration
+func (c *AnthropicClient) GetConfig() *common.CommonModelConfig {
+	return c.config
+}
+
+// BuildURL builds the full URL for API endpoints
+func (c *AnthropicClient) BuildURL(endpoint string) string {
+	return c.baseURL + endpoint
+}
+
+// PrepareHeaders prepares HTTP headers for API requests
+func (c *AnthropicClient) PrepareHeaders() map[string]string {
+	headers := make(map[string]string)
+	headers[common.HeaderContentType] = common.MIMETypeJSON
+	headers[common.HeaderAccept] = common.MIMETypeJSON
+	headers[AnthropicHeaderAPIKey] = c.apiKey
+	headers[AnthropicHeaderVersion] = common.AnthropicAPIVersion
+	return headers
+}
+
+// GetRateLimitInfo returns current rate limit information
+func (c *AnthropicClient) GetRateLimitInfo() *common.RateLimitInfo {
+	// Shared by the clients of the account; adapted to the rate limit headers of responses
+	return c.rateLimiter.Info()
+}
+
+// claimRateLimiter switches to the rate limiter of the client's account with the
+// limits of its config, releasing the previous one
+func (c *AnthropicClient) claimRateLimiter() {
+	limiter, release := common.ConfiguredRateLimiter(c.config, c.baseURL, c.apiKey)
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	c.rateLimiter, c.releaseRateLimiter = limiter, release
+	c.httpClient.SetRateLimiter(limiter)
+}
+
+// rateLimitContext returns ctx carrying the estimated tokens of req for the rate limiter
+func (c *AnthropicClient) rateLimitContext(ctx context.Context, req *interfaces.PonchoModelRequest) context.Context {
+	return common.WithRateLimitTokens(ctx, common.EstimateRateLimitTokens(c.tokenizer, req, c.config.Provider, c.config.Model))
+}
+
+// IsHealthy checks if the Anthropic client is configured
+func (c *AnthropicClient) IsHealthy(ctx context.Context) error {
+	if c.apiKey == "" {
+		return fmt.Errorf("API key is not configured")
+	}
+	return nil
+}
+
+// CreateMessage makes a non-streaming Messages API call
+func (c *AnthropicClient) CreateMessage(ctx context.Context, req *AnthropicRequest) (*AnthropicResponse, error) {
+	req.Stream = false
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var anthropicResp AnthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to parse response", string(common.ProviderAnthropic), req.Model)
+	}
+
+	return &anthropicResp, nil
+}
+
+// CreateMessageStream makes a streaming Messages API call and passes every event to callback
+func (c *AnthropicClient) CreateMessageStream(ctx context.Context, req *AnthropicRequest, callback func(*AnthropicStreamEvent) error) error {
+	req.Stream = true
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return ProcessSSEStream(ctx, resp.Body, func(event *AnthropicStreamEvent) error {
+		if event.Type == AnthropicEventError && event.Error != nil {
+			return common.NewModelError(common.ErrorCodeStreamError,
+				fmt.Sprintf("%s: %s", event.Error.Type, event.Error.Message),
+				string(common.ProviderAnthropic), req.Model)
+		}
+		return callback(event)
+	})
+}
+
+// post sends a Messages API request and returns the response on HTTP 200
+func (c *AnthropicClient) post(ctx context.Context, req *AnthropicRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to marshal request", string(common.ProviderAnthropic), req.Model)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BuildURL(common.AnthropicEndpoint), bytes.NewReader(body))
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeNetworkError, "Failed to create request", string(common.ProviderAnthropic), req.Model)
+	}
+
+	for key, value := range c.PrepareHeaders() {
+		httpReq.Header.Set(key, value)
+	}
+	if req.Stream {
+		httpReq.Header.Set(common.HeaderAccept, common.MIMETypeEventStream)
+	}
+
+	resp, err := c.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeNetworkError, "Failed to make API request", string(common.ProviderAnthropic), req.Model)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, errorFromResponse(resp, req.Model)
+	}
+
+	return resp, nil
+}
+
+// errorFromResponse maps a non-200 response to a ModelError, keeping the API's error message
+func errorFromResponse(resp *http.Response, model string) *common.ModelError {
+	return common.ErrorFromAPIResponse(resp, string(common.ProviderAnthropic), model, func(body []byte) (string, interface{}) {
+		var apiErr AnthropicError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return "", nil
+		}
+		return apiErr.Error.Message, apiErr.Error
+	})
+}
+
+// LogRequest logs a request to the API
+func (c *AnthropicClient) LogRequest(req *interfaces.PonchoModelRequest, requestID string) {
+	c.logger.Debug("Anthropic API request",
+		"request_id", requestID,
+		"model", c.config.Model,
+		"messages_count", len(req.Messages),
+		"max_tokens", req.MaxTokens,
+		"temperature", req.Temperature,
+		"stream", req.Stream,
+		"tools_count", len(req.Tools))
+}
+
+// LogResponse logs a response from the API
+func (c *AnthropicClient) LogResponse(resp *interfaces.PonchoModelResponse, requestID string, duration time.Duration) {
+	if resp != nil && resp.Usage != nil {
+		c.logger.Debug("Anthropic API response",
+			"request_id", requestID,
+			"duration_ms", duration.Milliseconds(),
+			"prompt_tokens", resp.Usage.PromptTokens,
+			"completion_tokens", resp.Usage.CompletionTokens,
+			"finish_reason", resp.FinishReason)
+	}
+}
+
+// LogError logs an error from the API
+func (c *AnthropicClient) LogError(err error, requestID string, duration time.Duration) {
+	c.logger.Error("Anthropic API error",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds(),
+		"error", err.Error())
+}
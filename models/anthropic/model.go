@@ -0,0 +1,556 @@
+// Package anthropic provides the Anthropic model implementation for PonchoFramework.
+// This file implements the PonchoModel interface on top of AnthropicClient.
+//
+// Model Capabilities:
+// - Text generation, streaming and tool calling
+// - Vision through image blocks (base64 for data: URLs, url source otherwise)
+// - System messages, sent as the top-level "system" field
+//
+// Message Mapping:
+// - System messages are joined into the request's system prompt
+// - Tool messages become user tool_result blocks answering ToolCallID (or Name)
+// - Consecutive user turns are merged, as tool results must share one message
+//
+// Configuration (map passed to Initialize, as built by the model factory):
+// - api_key, model_name, base_url, max_tokens, temperature, timeout
+// - top_p, top_k, stop (stop sequences)
+//
+// Usage Example:
+//
+//	model := NewAnthropicModel()
+//	err := model.Initialize(ctx, map[string]interface{}{
+//	    "api_key":    os.Getenv("ANTHROPIC_API_KEY"),
+//	    "model_name": "claude-sonnet-4-5",
+//	})
+//	resp, err := model.Generate(ctx, request)
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// AnthropicModel represents an Anthropic model implementation
+type AnthropicModel struct {
+	*base.PonchoBaseModel
+	client *AnthropicClient
+	topK   *int
+}
+
+// NewAnthropicModel creates a new Anthropic model instance
+func NewAnthropicModel() *AnthropicModel {
+	baseModel := base.NewPonchoBaseModel(common.AnthropicDefaultModel, string(common.ProviderAnthropic), interfaces.ModelCapabilities{
+		Streaming: true,
+		Tools:     true,
+		Vision:    true,
+		System:    true,
+		JSONMode:  false,
+	})
+
+	return &AnthropicModel{
+		PonchoBaseModel: baseModel,
+	}
+}
+
+// Name returns the configured model name
+func (m *AnthropicModel) Name() string {
+	if m.client != nil {
+		return m.client.GetConfig().Model
+	}
+	return m.PonchoBaseModel.Name()
+}
+
+// Initialize initializes the Anthropic model with configuration
+func (m *AnthropicModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	commonConfig, err := m.convertConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to convert config: %w", err)
+	}
+
+	client, err := NewAnthropicClient(commonConfig, m.GetLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Anthropic client: %w", err)
+	}
+
+	if supports, ok := config["supports"].(*interfaces.ModelCapabilities); ok && supports != nil {
+		m.SetCapabilities(*supports)
+	}
+
+	if err := m.PonchoBaseModel.Initialize(ctx, config); err != nil {
+		return fmt.Errorf("failed to initialize base model: %w", err)
+	}
+
+	m.client = client
+
+	m.GetLogger().Info("Anthropic model initialized",
+		"model", commonConfig.Model,
+		"max_tokens", commonConfig.MaxTokens,
+		"temperature", commonConfig.Temperature)
+
+	return nil
+}
+
+// Generate generates a response using the Messages API
+func (m *AnthropicModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	anthropicReq, requestID, err := m.prepareRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	anthropicResp, err := m.client.CreateMessage(ctx, anthropicReq)
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return nil, err
+	}
+
+	resp, err := m.convertResponse(anthropicResp)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return nil, fmt.Errorf("failed to convert response: %w", err)
+	}
+
+	m.client.LogResponse(resp, requestID, duration)
+	return resp, nil
+}
+
+// GenerateStreaming generates a streaming response using the Messages API.
+// Text is forwarded as it arrives; tool calls, the finish reason and usage
+// are delivered in the final chunk.
+func (m *AnthropicModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if m.isInitialized() && !m.SupportsStreaming() {
+		return fmt.Errorf("model '%s' does not support streaming", m.Name())
+	}
+
+	anthropicReq, requestID, err := m.prepareRequest(req)
+	if err != nil {
+		return err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	assembler := newStreamAssembler()
+	err = m.client.CreateMessageStream(ctx, anthropicReq, func(event *AnthropicStreamEvent) error {
+		if chunk := assembler.add(event); chunk != nil {
+			return callback(chunk)
+		}
+		return nil
+	})
+	if err == nil {
+		var final *interfaces.PonchoStreamChunk
+		if final, err = assembler.final(); err == nil {
+			err = callback(final)
+		}
+	}
+
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return err
+	}
+
+	m.GetLogger().Debug("Anthropic streaming generation completed",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds())
+
+	return nil
+}
+
+// HealthCheck implements interfaces.HealthChecker
+func (m *AnthropicModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("Anthropic model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
+// Shutdown shuts down the Anthropic model
+func (m *AnthropicModel) Shutdown(ctx context.Context) error {
+	if m.client != nil {
+		if err := m.client.Close(); err != nil {
+			m.GetLogger().Error("Failed to close Anthropic client", "error", err.Error())
+			return fmt.Errorf("failed to close Anthropic client: %w", err)
+		}
+	}
+
+	return m.PonchoBaseModel.Shutdown(ctx)
+}
+
+// Helper methods
+
+// prepareRequest validates a request and converts it to the API format
+func (m *AnthropicModel) prepareRequest(req *interfaces.PonchoModelRequest) (*AnthropicRequest, string, error) {
+	if !m.isInitialized() {
+		return nil, "", fmt.Errorf("model '%s' is not initialized", m.Name())
+	}
+
+	if err := m.ValidateRequest(req); err != nil {
+		return nil, "", fmt.Errorf("invalid request: %w", err)
+	}
+
+	requestID := m.generateRequestID()
+	m.client.LogRequest(req, requestID)
+
+	anthropicReq, err := m.convertRequest(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert request: %w", err)
+	}
+
+	return anthropicReq, requestID, nil
+}
+
+// convertConfig converts generic config to CommonModelConfig
+func (m *AnthropicModel) convertConfig(config map[string]interface{}) (*common.CommonModelConfig, error) {
+	commonConfig := &common.CommonModelConfig{
+		Provider:    common.ProviderAnthropic,
+		Model:       common.AnthropicDefaultModel,
+		MaxTokens:   4096,
+		Temperature: 0.7,
+		Timeout:     60 * time.Second,
+	}
+
+	if apiKey, ok := config["api_key"].(string); ok {
+		commonConfig.APIKey = apiKey
+	}
+
+	if model, ok := config["model_name"].(string); ok && model != "" {
+		commonConfig.Model = model
+	}
+
+	if maxTokens, ok := config["max_tokens"].(int); ok && maxTokens > 0 {
+		commonConfig.MaxTokens = maxTokens
+	}
+
+	if temperature, ok := common.ParamFloat(config["temperature"]); ok {
+		commonConfig.Temperature = temperature
+	}
+
+	if baseURL, ok := config["base_url"].(string); ok {
+		commonConfig.BaseURL = baseURL
+	}
+
+	switch timeout := config["timeout"].(type) {
+	case time.Duration:
+		if timeout > 0 {
+			commonConfig.Timeout = timeout
+		}
+	case string:
+		if timeout != "" {
+			parsed, err := time.ParseDuration(timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q: %w", timeout, err)
+			}
+			commonConfig.Timeout = parsed
+		}
+	}
+
+	if topP, ok := common.ParamFloat(config["top_p"]); ok {
+		commonConfig.TopP = &topP
+	}
+
+	if topK, exists := config["top_k"]; exists {
+		value, ok := common.ParamFloat(topK)
+		if !ok || value < 1 || value != float32(int(value)) {
+			return nil, fmt.Errorf("top_k must be a positive integer")
+		}
+		number := int(value)
+		m.topK = &number
+	}
+
+	if stop, ok := config["stop"]; ok {
+		if _, err := common.StopSequences(stop); err != nil {
+			return nil, err
+		}
+		commonConfig.Stop = stop
+	}
+
+	if customParams, ok := config["custom_params"].(map[string]interface{}); ok {
+		if err := common.RateLimitFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+
+	return commonConfig, nil
+}
+
+// convertRequest converts a Poncho request to the Messages API format
+func (m *AnthropicModel) convertRequest(req *interfaces.PonchoModelRequest) (*AnthropicRequest, error) {
+	config := m.client.GetConfig()
+
+	// max_tokens is mandatory for the Messages API
+	maxTokens := config.MaxTokens
+	if req.MaxTokens != nil && *req.MaxTokens > 0 {
+		maxTokens = *req.MaxTokens
+	}
+
+	// The Messages API has no seed, penalties, JSON mode or logprobs, and
+	// extended thinking needs a token budget
+	if err := common.CheckRequestParams(req, string(common.ProviderAnthropic), config.Model,
+		common.ParamSeed, common.ParamFrequencyPenalty, common.ParamPresencePenalty,
+		common.ParamResponseFormat, common.ParamThinking, common.ParamLogProbs, common.ParamTopLogProbs); err != nil {
+		return nil, err
+	}
+	params, err := common.ResolveGenerationParams(req, config)
+	if err != nil {
+		return nil, err
+	}
+
+	system, conversation := common.SplitSystemMessages(req.Messages)
+	anthropicReq := &AnthropicRequest{
+		Model:         config.Model,
+		System:        system,
+		Messages:      make([]AnthropicMessage, 0, len(conversation)),
+		MaxTokens:     maxTokens,
+		Temperature:   req.Temperature,
+		TopP:          params.TopP,
+		TopK:          m.topK,
+		StopSequences: params.Stop,
+	}
+
+	// Convert tools
+	for _, tool := range req.Tools {
+		schema := tool.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object"}
+		}
+		anthropicReq.Tools = append(anthropicReq.Tools, AnthropicTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: schema,
+		})
+	}
+
+	// Convert messages, merging consecutive turns of the same role
+	for _, msg := range conversation {
+		anthropicMsg, err := convertMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(anthropicReq.Messages) - 1
+		if last >= 0 && anthropicReq.Messages[last].Role == anthropicMsg.Role {
+			anthropicReq.Messages[last].Content = append(anthropicReq.Messages[last].Content, anthropicMsg.Content...)
+			continue
+		}
+		anthropicReq.Messages = append(anthropicReq.Messages, anthropicMsg)
+	}
+
+	if len(anthropicReq.Messages) == 0 {
+		return nil, fmt.Errorf("at least one user or assistant message is required")
+	}
+
+	return anthropicReq, nil
+}
+
+// convertMessage converts a Poncho message to Messages API content blocks
+func convertMessage(msg *interfaces.PonchoMessage) (AnthropicMessage, error) {
+	switch msg.Role {
+	case interfaces.PonchoRoleUser, interfaces.PonchoRoleAssistant:
+	case interfaces.PonchoRoleTool:
+		return convertToolResult(msg), nil
+	default:
+		return AnthropicMessage{}, fmt.Errorf("unsupported role: %s", msg.Role)
+	}
+
+	anthropicMsg := AnthropicMessage{
+		Role:    string(msg.Role),
+		Content: make([]AnthropicContentBlock, 0, len(msg.Content)),
+	}
+
+	for _, part := range msg.Content {
+		switch part.Type {
+		case interfaces.PonchoContentTypeText:
+			if part.Text != "" {
+				anthropicMsg.Content = append(anthropicMsg.Content, AnthropicContentBlock{
+					Type: AnthropicContentTypeText,
+					Text: part.Text,
+				})
+			}
+		case interfaces.PonchoContentTypeMedia:
+			source, err := convertImageSource(part.Media)
+			if err != nil {
+				return AnthropicMessage{}, err
+			}
+			anthropicMsg.Content = append(anthropicMsg.Content, AnthropicContentBlock{
+				Type:   AnthropicContentTypeImage,
+				Source: source,
+			})
+		case interfaces.PonchoContentTypeTool:
+			if part.Tool != nil {
+				input := part.Tool.Args
+				if input == nil {
+					input = make(map[string]interface{})
+				}
+				anthropicMsg.Content = append(anthropicMsg.Content, AnthropicContentBlock{
+					Type:  AnthropicContentTypeToolUse,
+					ID:    part.Tool.ID,
+					Name:  part.Tool.Name,
+					Input: input,
+				})
+			}
+		case interfaces.PonchoContentTypeReasoning:
+			// Thinking blocks need the provider's signature and are not sent back
+		default:
+			return AnthropicMessage{}, fmt.Errorf("unsupported content type: %s", part.Type)
+		}
+	}
+
+	return anthropicMsg, nil
+}
+
+// convertToolResult converts a tool message to a user message with a tool_result block
+func convertToolResult(msg *interfaces.PonchoMessage) AnthropicMessage {
+	block := AnthropicContentBlock{
+		Type:      AnthropicContentTypeToolResult,
+		ToolUseID: common.ToolCallID(msg),
+		IsError:   common.ToolResultIsError(msg),
+	}
+
+	var text strings.Builder
+	for _, part := range msg.Content {
+		switch part.Type {
+		case interfaces.PonchoContentTypeText:
+			text.WriteString(part.Text)
+		case interfaces.PonchoContentTypeToolResult:
+			if part.ToolResult != nil {
+				// is_error carries the failure, so error text is sent unwrapped
+				result := *part.ToolResult
+				result.IsError = false
+				text.WriteString(common.ToolResultText(&result))
+			}
+		}
+	}
+	block.Content = text.String()
+
+	return AnthropicMessage{
+		Role:    AnthropicRoleUser,
+		Content: []AnthropicContentBlock{block},
+	}
+}
+
+// convertImageSource converts a media part to an image source. data: URLs
+// become base64 sources, http(s) URLs are passed as url sources.
+func convertImageSource(media *interfaces.PonchoMediaPart) (*AnthropicImageSource, error) {
+	if media == nil || media.URL == "" {
+		return nil, fmt.Errorf("media URL cannot be empty")
+	}
+
+	if strings.HasPrefix(media.URL, "data:") {
+		comma := strings.Index(media.URL, ",")
+		if comma < 0 || !strings.HasSuffix(media.URL[:comma], ";base64") {
+			return nil, fmt.Errorf("only base64 data URLs are supported")
+		}
+
+		mediaType := strings.TrimSuffix(strings.TrimPrefix(media.URL[:comma], "data:"), ";base64")
+		if mediaType == "" {
+			mediaType = media.MimeType
+		}
+		if mediaType == "" {
+			mediaType = "image/jpeg"
+		}
+
+		return &AnthropicImageSource{
+			Type:      AnthropicImageSourceBase64,
+			MediaType: mediaType,
+			Data:      media.URL[comma+1:],
+		}, nil
+	}
+
+	if err := common.ValidateMediaURL(media.URL); err != nil {
+		return nil, err
+	}
+
+	return &AnthropicImageSource{
+		Type: AnthropicImageSourceURL,
+		URL:  media.URL,
+	}, nil
+}
+
+// convertResponse converts a Messages API response to a Poncho response
+func (m *AnthropicModel) convertResponse(anthropicResp *AnthropicResponse) (*interfaces.PonchoModelResponse, error) {
+	if anthropicResp == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+
+	resp := &interfaces.PonchoModelResponse{
+		Message: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: make([]*interfaces.PonchoContentPart, 0, len(anthropicResp.Content)),
+		},
+		Usage:        convertUsage(anthropicResp.Usage),
+		FinishReason: convertStopReason(anthropicResp.StopReason),
+		Metadata: map[string]interface{}{
+			"id":          anthropicResp.ID,
+			"model":       anthropicResp.Model,
+			"stop_reason": anthropicResp.StopReason,
+		},
+	}
+
+	for _, block := range anthropicResp.Content {
+		switch block.Type {
+		case AnthropicContentTypeText:
+			resp.Message.Content = append(resp.Message.Content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeText,
+				Text: block.Text,
+			})
+		case AnthropicContentTypeToolUse:
+			args, _ := block.Input.(map[string]interface{})
+			if args == nil {
+				args = make(map[string]interface{})
+			}
+			resp.Message.Content = append(resp.Message.Content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeTool,
+				Tool: &interfaces.PonchoToolPart{
+					ID:   block.ID,
+					Name: block.Name,
+					Args: args,
+				},
+			})
+		}
+	}
+
+	return resp, nil
+}
+
+// convertStopReason maps a stop reason to a Poncho finish reason
+func convertStopReason(stopReason string) interfaces.PonchoFinishReason {
+	switch stopReason {
+	case AnthropicStopReasonMaxTokens:
+		return interfaces.PonchoFinishReasonLength
+	case AnthropicStopReasonToolUse:
+		return interfaces.PonchoFinishReasonTool
+	default:
+		// end_turn, stop_sequence and refusal; the raw reason stays in metadata
+		return interfaces.PonchoFinishReasonStop
+	}
+}
+
+// convertUsage converts API usage to Poncho usage. Cached prompt tokens
+// are still prompt tokens.
+func convertUsage(usage AnthropicUsage) *interfaces.PonchoUsage {
+	promptTokens := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+	return &interfaces.PonchoUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      promptTokens + usage.OutputTokens,
+	}
+}
+
+// generateRequestID generates a unique request ID
+func (m *AnthropicModel) generateRequestID() string {
+	return fmt.Sprintf("anthropic_%d", time.Now().UnixNano())
+}
+
+// isInitialized checks if model is properly initialized
+func (m *AnthropicModel) isInitialized() bool {
+	return m.client != nil
+}
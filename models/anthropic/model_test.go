@@ -0,0 +1,234 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOfflineModel returns a model for testing conversions; it never reaches the API
+func newOfflineModel(t *testing.T) *AnthropicModel {
+	t.Helper()
+
+	model := NewAnthropicModel()
+	modeltest.Initialize(t, model, map[string]interface{}{
+		"api_key":    "sk-ant-test-key",
+		"base_url":   "http://localhost:1/v1",
+		"max_tokens": 1000,
+	})
+	return model
+}
+
+func TestAnthropicModel_ConvertRequest(t *testing.T) {
+	model := newOfflineModel(t)
+
+	anthropicReq, err := model.convertRequest(modeltest.Conversation())
+	require.NoError(t, err)
+
+	// The system prompt is a top-level field, not a message
+	assert.Equal(t, "You are a fashion expert", anthropicReq.System)
+	assert.Equal(t, 1000, anthropicReq.MaxTokens)
+	require.Len(t, anthropicReq.Messages, 3)
+
+	user := anthropicReq.Messages[0]
+	assert.Equal(t, AnthropicRoleUser, user.Role)
+	assert.Equal(t, []AnthropicContentBlock{
+		{Type: AnthropicContentTypeText, Text: "Describe the dress"},
+		{Type: AnthropicContentTypeImage, Source: &AnthropicImageSource{Type: AnthropicImageSourceBase64, MediaType: "image/png", Data: "AAAA"}},
+	}, user.Content)
+
+	// Tool calls are tool_use blocks of the assistant turn
+	toolUse := anthropicReq.Messages[1].Content[0]
+	assert.Equal(t, AnthropicContentTypeToolUse, toolUse.Type)
+	assert.Equal(t, modeltest.ToolCallID, toolUse.ID)
+	assert.Equal(t, map[string]interface{}{"id": 42}, toolUse.Input)
+
+	// Tool results are tool_result blocks of a user turn
+	result := anthropicReq.Messages[2]
+	assert.Equal(t, AnthropicRoleUser, result.Role)
+	assert.Equal(t, AnthropicContentTypeToolResult, result.Content[0].Type)
+	assert.Equal(t, modeltest.ToolCallID, result.Content[0].ToolUseID)
+	assert.Equal(t, `{"color": "red"}`, result.Content[0].Content)
+
+	require.Len(t, anthropicReq.Tools, 1)
+	assert.Equal(t, "object", anthropicReq.Tools[0].InputSchema["type"])
+
+	// Roles must alternate: a tool result followed by a user message share one turn
+	req := modeltest.Conversation()
+	req.Messages = append(req.Messages, &interfaces.PonchoMessage{Role: interfaces.PonchoRoleUser, Content: []*interfaces.PonchoContentPart{modeltest.Text("And the size?")}})
+	anthropicReq, err = model.convertRequest(req)
+	require.NoError(t, err)
+	require.Len(t, anthropicReq.Messages, 3)
+	last := anthropicReq.Messages[2].Content
+	require.Len(t, last, 2)
+	assert.Equal(t, AnthropicContentTypeToolResult, last[0].Type)
+	assert.Equal(t, "And the size?", last[1].Text)
+
+	// A system prompt alone is not a conversation
+	_, err = model.convertRequest(&interfaces.PonchoModelRequest{Messages: modeltest.Conversation().Messages[:1]})
+	assert.Error(t, err)
+}
+
+func TestConvertToolResult_Error(t *testing.T) {
+	message := convertToolResult(&interfaces.PonchoMessage{
+		Role: interfaces.PonchoRoleTool,
+		Content: []*interfaces.PonchoContentPart{{
+			Type:       interfaces.PonchoContentTypeToolResult,
+			ToolResult: &interfaces.PonchoToolResultPart{ToolCallID: "toolu_1", Content: "article not found", IsError: true},
+		}},
+	})
+
+	// is_error carries the failure, so the text is not wrapped in an error object
+	block := message.Content[0]
+	assert.Equal(t, "toolu_1", block.ToolUseID)
+	assert.True(t, block.IsError)
+	assert.Equal(t, "article not found", block.Content)
+}
+
+func TestConvertImageSource(t *testing.T) {
+	source, err := convertImageSource(&interfaces.PonchoMediaPart{URL: "https://example.com/dress.jpg"})
+	require.NoError(t, err)
+	assert.Equal(t, &AnthropicImageSource{Type: AnthropicImageSourceURL, URL: "https://example.com/dress.jpg"}, source)
+
+	// A data URL without a media type falls back to the part's MIME type
+	source, err = convertImageSource(&interfaces.PonchoMediaPart{URL: "data:;base64,AAAA", MimeType: "image/webp"})
+	require.NoError(t, err)
+	assert.Equal(t, "image/webp", source.MediaType)
+
+	_, err = convertImageSource(&interfaces.PonchoMediaPart{URL: "data:image/png,raw-bytes"})
+	assert.Error(t, err, "only base64 data URLs are supported")
+}
+
+func TestAnthropicModel_Generate(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "sk-ant-test-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, common.AnthropicAPIVersion, r.Header.Get("anthropic-version"))
+
+		var req AnthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "claude-sonnet-4-5", req.Model)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"model": "claude-sonnet-4-5",
+			"content": [
+				{"type": "text", "text": "Checking the article"},
+				{"type": "tool_use", "id": "toolu_2", "name": "get_article", "input": {"id": 43}}
+			],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 50, "output_tokens": 20, "cache_read_input_tokens": 10}
+		}`)
+	})
+
+	model := NewAnthropicModel()
+	modeltest.Initialize(t, model, map[string]interface{}{
+		"api_key":    "sk-ant-test-key",
+		"base_url":   url + "/v1",
+		"model_name": "claude-sonnet-4-5",
+	})
+
+	resp, err := model.Generate(context.Background(), modeltest.Conversation())
+	require.NoError(t, err)
+
+	// Response: text, tool call, usage including cached prompt tokens
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, resp.FinishReason)
+	require.Len(t, resp.Message.Content, 2)
+	assert.Equal(t, "Checking the article", resp.Message.Content[0].Text)
+	assert.Equal(t, "toolu_2", resp.Message.Content[1].Tool.ID)
+	assert.Equal(t, float64(43), resp.Message.Content[1].Tool.Args["id"])
+	assert.Equal(t, 60, resp.Usage.PromptTokens)
+	assert.Equal(t, 80, resp.Usage.TotalTokens)
+	assert.Equal(t, "tool_use", resp.Metadata["stop_reason"])
+}
+
+func TestAnthropicModel_ErrorResponse(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type": "error", "error": {"type": "invalid_request_error", "message": "messages: roles must alternate"}}`)
+	})
+
+	model := NewAnthropicModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"api_key": "sk-ant-test-key", "base_url": url + "/v1"})
+
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Hello")))
+	modelErr := modeltest.RequireModelError(t, err, common.ErrorCodeInvalidRequest)
+	assert.Contains(t, modelErr.Message, "roles must alternate")
+}
+
+func TestAnthropicModel_RateLimitHeaders(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("anthropic-ratelimit-requests-limit", "50")
+		w.Header().Set("anthropic-ratelimit-requests-remaining", "49")
+		w.Header().Set("anthropic-ratelimit-tokens-limit", "40000")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": "msg_1", "type": "message", "role": "assistant", "content": [{"type": "text", "text": "Hi"}], "stop_reason": "end_turn", "usage": {"input_tokens": 5, "output_tokens": 1}}`)
+	})
+
+	model := NewAnthropicModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"api_key": "sk-ant-test-key", "base_url": url + "/v1"})
+
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Hello")))
+	require.NoError(t, err)
+
+	// The shared limiter of the account takes the reported limits
+	info := model.client.GetRateLimitInfo()
+	assert.Equal(t, 50, info.RequestsPerMinute)
+	assert.Equal(t, 40000, info.TokensPerMinute)
+	require.NotNil(t, info.RemainingRequests)
+	assert.Equal(t, 49, *info.RemainingRequests)
+}
+
+func TestAnthropicModel_GenerationParams(t *testing.T) {
+	model := newOfflineModel(t)
+
+	topP := float32(0.8)
+	req := modeltest.Request(modeltest.Text("Hello"))
+	req.TopP = &topP
+	req.Stop = []string{"END"}
+
+	anthropicReq, err := model.convertRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.8), *anthropicReq.TopP)
+	assert.Equal(t, []string{"END"}, anthropicReq.StopSequences)
+
+	// The Messages API has no JSON mode
+	req.ResponseFormat = interfaces.PonchoResponseFormatJSONObject
+	_, err = model.convertRequest(req)
+	modeltest.RequireModelError(t, err, common.ErrorCodeUnsupportedParameter)
+
+	// Out of range values are invalid for every provider
+	topP = 1.5
+	req.ResponseFormat = ""
+	_, err = model.convertRequest(req)
+	modeltest.RequireModelError(t, err, common.ErrorCodeInvalidRequest)
+}
+
+func TestConvertStopReason(t *testing.T) {
+	assert.Equal(t, interfaces.PonchoFinishReasonStop, convertStopReason("end_turn"))
+	assert.Equal(t, interfaces.PonchoFinishReasonStop, convertStopReason("stop_sequence"))
+	assert.Equal(t, interfaces.PonchoFinishReasonLength, convertStopReason("max_tokens"))
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, convertStopReason("tool_use"))
+}
+
+func TestAnthropicModel_InitializeValidation(t *testing.T) {
+	err := NewAnthropicModel().Initialize(context.Background(), map[string]interface{}{"model_name": "claude-sonnet-4-5"})
+	assert.Error(t, err, "api_key is required")
+
+	err = NewAnthropicModel().Initialize(context.Background(), map[string]interface{}{
+		"api_key":     "sk-ant-test-key",
+		"temperature": 1.5,
+	})
+	assert.Error(t, err, "temperature above 1 is rejected")
+}
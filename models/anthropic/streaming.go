@@ -0,0 +1,208 @@
+// Package anthropic provides streaming response processing for the Messages API.
+// This file implements Server-Sent Events parsing and the conversion of
+// typed stream events to PonchoFramework stream chunks.
+//
+// A stream is a sequence of events: message_start carries the input token
+// count, each content block is opened by content_block_start, extended by
+// content_block_delta events and closed by content_block_stop, message_delta
+// carries the stop reason and output token count, and message_stop ends the
+// stream. Tool input arrives as partial JSON strings that only parse once
+// the block is complete.
+//
+// Data Flow:
+// 1. HTTP response with SSE content-type
+// 2. Line-by-line parsing of data: lines, event: lines are implied by "type"
+// 3. Text deltas forwarded as they arrive
+// 4. Tool input, stop reason and usage accumulated
+// 5. One final chunk with Done set on message_stop
+package anthropic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// ProcessSSEStream processes a Server-Sent Events stream of Messages API events
+func ProcessSSEStream(ctx context.Context, body io.ReadCloser, callback func(*AnthropicStreamEvent) error) error {
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	// Events with long tool input can exceed the default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		line := scanner.Text()
+
+		// Skip empty lines, event: lines and comments; data carries the type
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		if err := callback(&event); err != nil {
+			return err
+		}
+
+		if event.Type == AnthropicEventMessageStop {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream processing error: %w", err)
+	}
+
+	return nil
+}
+
+// toolUseBuffer collects a tool_use block while its input streams in
+type toolUseBuffer struct {
+	id    string
+	name  string
+	input strings.Builder
+}
+
+// streamAssembler converts stream events to Poncho chunks, holding back tool
+// calls, the stop reason and usage until the message is finished
+type streamAssembler struct {
+	toolUses   map[int]*toolUseBuffer
+	stopReason string
+	usage      AnthropicUsage
+	metadata   map[string]interface{}
+	finished   bool
+}
+
+// newStreamAssembler creates an empty stream assembler
+func newStreamAssembler() *streamAssembler {
+	return &streamAssembler{
+		toolUses: make(map[int]*toolUseBuffer),
+		metadata: make(map[string]interface{}),
+	}
+}
+
+// add records a stream event and returns the text delta to forward, or nil
+// when the event carries no text
+func (a *streamAssembler) add(event *AnthropicStreamEvent) *interfaces.PonchoStreamChunk {
+	switch event.Type {
+	case AnthropicEventMessageStart:
+		if event.Message != nil {
+			a.metadata["id"] = event.Message.ID
+			a.metadata["model"] = event.Message.Model
+			a.usage = event.Message.Usage
+		}
+
+	case AnthropicEventContentBlockStart:
+		if block := event.ContentBlock; block != nil && block.Type == AnthropicContentTypeToolUse {
+			a.toolUses[event.Index] = &toolUseBuffer{id: block.ID, name: block.Name}
+		} else if block != nil && block.Text != "" {
+			return textChunk(block.Text)
+		}
+
+	case AnthropicEventContentBlockDelta:
+		if event.Delta == nil {
+			return nil
+		}
+		switch event.Delta.Type {
+		case AnthropicDeltaText:
+			if event.Delta.Text != "" {
+				return textChunk(event.Delta.Text)
+			}
+		case AnthropicDeltaInputJSON:
+			if toolUse, exists := a.toolUses[event.Index]; exists {
+				toolUse.input.WriteString(event.Delta.PartialJSON)
+			}
+		}
+
+	case AnthropicEventMessageDelta:
+		if event.Delta != nil && event.Delta.StopReason != "" {
+			a.stopReason = event.Delta.StopReason
+		}
+		// message_delta usage is cumulative
+		if event.Usage != nil {
+			a.usage.OutputTokens = event.Usage.OutputTokens
+			if event.Usage.InputTokens > 0 {
+				a.usage.InputTokens = event.Usage.InputTokens
+			}
+		}
+
+	case AnthropicEventMessageStop:
+		a.finished = true
+	}
+
+	return nil
+}
+
+// final builds the closing chunk with complete tool calls, finish reason and usage
+func (a *streamAssembler) final() (*interfaces.PonchoStreamChunk, error) {
+	if !a.finished {
+		return nil, fmt.Errorf("stream ended before message_stop")
+	}
+
+	indexes := make([]int, 0, len(a.toolUses))
+	for index := range a.toolUses {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	content := make([]*interfaces.PonchoContentPart, 0, len(indexes))
+	for _, index := range indexes {
+		toolUse := a.toolUses[index]
+		args := make(map[string]interface{})
+		if toolUse.input.Len() > 0 {
+			if err := json.Unmarshal([]byte(toolUse.input.String()), &args); err != nil {
+				return nil, fmt.Errorf("invalid input for tool call %s: %w", toolUse.name, err)
+			}
+		}
+		content = append(content, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeTool,
+			Tool: &interfaces.PonchoToolPart{ID: toolUse.id, Name: toolUse.name, Args: args},
+		})
+	}
+
+	a.metadata["stop_reason"] = a.stopReason
+
+	return &interfaces.PonchoStreamChunk{
+		Delta: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: content,
+		},
+		Usage:        convertUsage(a.usage),
+		FinishReason: convertStopReason(a.stopReason),
+		Done:         true,
+		Metadata:     a.metadata,
+	}, nil
+}
+
+// textChunk wraps a text delta in a stream chunk
+func textChunk(text string) *interfaces.PonchoStreamChunk {
+	return &interfaces.PonchoStreamChunk{
+		Delta: &interfaces.PonchoMessage{
+			Role: interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{
+				{Type: interfaces.PonchoContentTypeText, Text: text},
+			},
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSSEStream(t *testing.T) {
+	stream := `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","content":[],"usage":{"input_tokens":25}}}
+
+event: ping
+data: {"type":"ping"}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+event: ping
+data: {"type":"ping"}
+`
+
+	var types []string
+	err := ProcessSSEStream(context.Background(), modeltest.Body(stream), func(event *AnthropicStreamEvent) error {
+		types = append(types, event.Type)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"message_start", "ping", "message_stop"}, types, "message_stop ends the stream")
+}
+
+func TestStreamAssembler(t *testing.T) {
+	assembler := newStreamAssembler()
+	var forwarded []string
+	for _, data := range []string{
+		`{"type":"message_start","message":{"id":"msg_1","model":"claude-sonnet-4-5","content":[],"usage":{"input_tokens":25,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Looking "}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"it up"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		// Tool input streams as partial JSON that parses only once the block is complete
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_article","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"id\""}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":": 42}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		// message_delta usage is cumulative
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":15}}`,
+		`{"type":"message_stop"}`,
+	} {
+		var event AnthropicStreamEvent
+		require.NoError(t, json.Unmarshal([]byte(data), &event))
+		if chunk := assembler.add(&event); chunk != nil {
+			forwarded = append(forwarded, chunk.Delta.Content[0].Text)
+		}
+	}
+
+	assert.Equal(t, []string{"Looking ", "it up"}, forwarded)
+
+	final, err := assembler.final()
+	require.NoError(t, err)
+	assert.True(t, final.Done)
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, final.FinishReason)
+	assert.Equal(t, 25, final.Usage.PromptTokens)
+	assert.Equal(t, 15, final.Usage.CompletionTokens)
+	assert.Equal(t, "tool_use", final.Metadata["stop_reason"])
+	require.Len(t, final.Delta.Content, 1)
+	assert.Equal(t, "toolu_1", final.Delta.Content[0].Tool.ID)
+	assert.Equal(t, float64(42), final.Delta.Content[0].Tool.Args["id"])
+
+	// A stream cut off before message_stop is incomplete
+	_, err = newStreamAssembler().final()
+	assert.Error(t, err)
+}
+
+func TestAnthropicModel_GenerateStreaming(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		var req AnthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"type":"message_start","message":{"id":"msg_1","content":[],"usage":{"input_tokens":5}}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":1}}`,
+			`{"type":"message_stop"}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+		}
+	})
+
+	model := NewAnthropicModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"api_key": "sk-ant-test-key", "base_url": url + "/v1"})
+
+	chunks, err := modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Hello")))
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "Hi", chunks[0].Delta.Content[0].Text)
+	assert.True(t, chunks[1].Done)
+	assert.Equal(t, 6, chunks[1].Usage.TotalTokens)
+}
+
+func TestAnthropicModel_StreamErrorEvent(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	})
+
+	model := NewAnthropicModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"api_key": "sk-ant-test-key", "base_url": url + "/v1"})
+
+	_, err := modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Hello")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "overloaded_error")
+}
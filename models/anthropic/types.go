@@ -0,0 +1,179 @@
+// Package anthropic provides type definitions for the Anthropic Messages API.
+//
+// Protocol Notes:
+// - The system prompt is a top-level field, "system" is not a message role
+// - Message content is an array of typed blocks: text, image, tool_use, tool_result
+// - Tool results are sent back in a user message as tool_result blocks
+// - max_tokens is required on every request
+// - Streaming is SSE with typed events: content_block_delta, message_delta, ...
+//
+// Usage Example:
+//
+//	req := &AnthropicRequest{
+//	    Model: "claude-sonnet-4-5",
+//	    System: "You are a fashion expert",
+//	    Messages: []AnthropicMessage{{Role: "user", Content: []AnthropicContentBlock{{Type: "text", Text: "Hi"}}}},
+//	    MaxTokens: 1024,
+//	}
+package anthropic
+
+// AnthropicMessage represents a message in Messages API format
+type AnthropicMessage struct {
+	Role    string                  `json:"role"` // "user" or "assistant"
+	Content []AnthropicContentBlock `json:"content"`
+}
+
+// AnthropicContentBlock represents a content block. Which fields are set
+// depends on Type.
+type AnthropicContentBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// image
+	Source *AnthropicImageSource `json:"source,omitempty"`
+
+	// tool_use
+	ID    string      `json:"id,omitempty"`
+	Name  string      `json:"name,omitempty"`
+	Input interface{} `json:"input,omitempty"` // object; an empty map is still sent
+
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// AnthropicImageSource represents the source of an image block
+type AnthropicImageSource struct {
+	Type      string `json:"type"`                 // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"` // e.g. "image/jpeg", base64 only
+	Data      string `json:"data,omitempty"`       // base64 only
+	URL       string `json:"url,omitempty"`        // url only
+}
+
+// AnthropicTool represents a tool definition
+type AnthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// AnthropicRequest represents a Messages API request
+type AnthropicRequest struct {
+	Model         string             `json:"model"`
+	System        string             `json:"system,omitempty"`
+	Messages      []AnthropicMessage `json:"messages"`
+	MaxTokens     int                `json:"max_tokens"`
+	Temperature   *float32           `json:"temperature,omitempty"`
+	TopP          *float32           `json:"top_p,omitempty"`
+	TopK          *int               `json:"top_k,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Tools         []AnthropicTool    `json:"tools,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+}
+
+// AnthropicUsage represents token usage information
+type AnthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// AnthropicResponse represents a Messages API response
+type AnthropicResponse struct {
+	ID           string                  `json:"id"`
+	Type         string                  `json:"type"` // "message"
+	Role         string                  `json:"role"`
+	Model        string                  `json:"model"`
+	Content      []AnthropicContentBlock `json:"content"`
+	StopReason   string                  `json:"stop_reason"`
+	StopSequence *string                 `json:"stop_sequence,omitempty"`
+	Usage        AnthropicUsage          `json:"usage"`
+}
+
+// AnthropicStreamEvent represents one SSE event of a streaming response.
+// Which fields are set depends on Type.
+type AnthropicStreamEvent struct {
+	Type string `json:"type"`
+
+	// message_start
+	Message *AnthropicResponse `json:"message,omitempty"`
+
+	// content_block_start, content_block_delta, content_block_stop
+	Index        int                    `json:"index"`
+	ContentBlock *AnthropicContentBlock `json:"content_block,omitempty"`
+
+	// content_block_delta and message_delta
+	Delta *AnthropicStreamDelta `json:"delta,omitempty"`
+
+	// message_delta
+	Usage *AnthropicUsage `json:"usage,omitempty"`
+
+	// error
+	Error *AnthropicErrorDetail `json:"error,omitempty"`
+}
+
+// AnthropicStreamDelta represents the delta of a content_block_delta or message_delta event
+type AnthropicStreamDelta struct {
+	Type        string `json:"type,omitempty"` // "text_delta" or "input_json_delta"
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+// AnthropicError represents an error response from the API
+type AnthropicError struct {
+	Type  string               `json:"type"` // "error"
+	Error AnthropicErrorDetail `json:"error"`
+}
+
+// AnthropicErrorDetail represents error details
+type AnthropicErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Constants for Anthropic API
+const (
+	// Headers
+	AnthropicHeaderAPIKey  = "x-api-key"
+	AnthropicHeaderVersion = "anthropic-version"
+
+	// Roles
+	AnthropicRoleUser      = "user"
+	AnthropicRoleAssistant = "assistant"
+
+	// Content block types
+	AnthropicContentTypeText       = "text"
+	AnthropicContentTypeImage      = "image"
+	AnthropicContentTypeToolUse    = "tool_use"
+	AnthropicContentTypeToolResult = "tool_result"
+
+	// Image source types
+	AnthropicImageSourceBase64 = "base64"
+	AnthropicImageSourceURL    = "url"
+
+	// Stream event types
+	AnthropicEventMessageStart      = "message_start"
+	AnthropicEventContentBlockStart = "content_block_start"
+	AnthropicEventContentBlockDelta = "content_block_delta"
+	AnthropicEventContentBlockStop  = "content_block_stop"
+	AnthropicEventMessageDelta      = "message_delta"
+	AnthropicEventMessageStop       = "message_stop"
+	AnthropicEventPing              = "ping"
+	AnthropicEventError             = "error"
+
+	// Delta types
+	AnthropicDeltaText      = "text_delta"
+	AnthropicDeltaInputJSON = "input_json_delta"
+
+	// Stop reasons
+	AnthropicStopReasonEndTurn      = "end_turn"
+	AnthropicStopReasonMaxTokens    = "max_tokens"
+	AnthropicStopReasonStopSequence = "stop_sequence"
+	AnthropicStopReasonToolUse      = "tool_use"
+	AnthropicStopReasonRefusal      = "refusal"
+)
@@ -0,0 +1,246 @@
+package common
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// bpeCacheSize bounds the per-tokenizer cache of encoded pre-tokens
+const bpeCacheSize = 10000
+
+// BPETokenizer is a byte-level byte pair encoding tokenizer
+type BPETokenizer struct {
+	name   string
+	vocab  map[string]int    // token bytes to ID
+	merges map[[2]string]int // merge rank of a token pair; nil merges by vocab rank (tiktoken)
+
+	mutex sync.Mutex
+	cache map[string][]int
+}
+
+// LoadTiktokenFile loads a tiktoken rank file: one base64 token and its rank per line
+func LoadTiktokenFile(name, path string) (*BPETokenizer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tiktoken file: %w", err)
+	}
+	defer file.Close()
+
+	vocab := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		encoded, rankText, found := strings.Cut(text, " ")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected \"<base64 token> <rank>\"", path, line)
+		}
+		token, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid token: %w", path, line, err)
+		}
+		rank, err := strconv.Atoi(rankText)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rank: %w", path, line, err)
+		}
+		vocab[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tiktoken file: %w", err)
+	}
+	if len(vocab) == 0 {
+		return nil, fmt.Errorf("tiktoken file %s has no tokens", path)
+	}
+
+	return NewBPETokenizer(name, vocab, nil), nil
+}
+
+// LoadBPEFiles loads a byte-level BPE vocabulary (vocab.json) and its merges
+// (merges.txt) in the GPT-2 format used by Hugging Face tokenizers
+func LoadBPEFiles(name, vocabPath, mergesPath string) (*BPETokenizer, error) {
+	data, err := os.ReadFile(vocabPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BPE vocabulary: %w", err)
+	}
+
+	var encodedVocab map[string]int
+	if err := json.Unmarshal(data, &encodedVocab); err != nil {
+		return nil, fmt.Errorf("failed to parse BPE vocabulary %s: %w", vocabPath, err)
+	}
+
+	decode := byteLevelDecoder()
+	vocab := make(map[string]int, len(encodedVocab))
+	for token, id := range encodedVocab {
+		vocab[decode(token)] = id
+	}
+
+	file, err := os.Open(mergesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open BPE merges: %w", err)
+	}
+	defer file.Close()
+
+	merges := make(map[[2]string]int)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "#version") {
+			continue
+		}
+
+		left, right, found := strings.Cut(text, " ")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected \"<left> <right>\"", mergesPath, line)
+		}
+		pair := [2]string{decode(left), decode(right)}
+		if _, exists := merges[pair]; !exists {
+			merges[pair] = len(merges)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read BPE merges: %w", err)
+	}
+
+	return NewBPETokenizer(name, vocab, merges), nil
+}
+
+// NewBPETokenizer creates a BPE tokenizer from token bytes to IDs. With nil merges,
+// pairs merge in the order of the IDs of their concatenation, as in tiktoken.
+func NewBPETokenizer(name string, vocab map[string]int, merges map[[2]string]int) *BPETokenizer {
+	return &BPETokenizer{
+		name:   name,
+		vocab:  vocab,
+		merges: merges,
+		cache:  make(map[string][]int),
+	}
+}
+
+// Name returns the tokenizer name
+func (b *BPETokenizer) Name() string {
+	return b.name
+}
+
+// Encode returns the token IDs of text
+func (b *BPETokenizer) Encode(text string) []int {
+	var ids []int
+	for _, piece := range splitPretokens(text) {
+		ids = append(ids, b.encodePiece(piece)...)
+	}
+	return ids
+}
+
+// Count returns the number of tokens in text
+func (b *BPETokenizer) Count(text string) int {
+	count := 0
+	for _, piece := range splitPretokens(text) {
+		count += len(b.encodePiece(piece))
+	}
+	return count
+}
+
+// encodePiece encodes one pre-token, using the cache for repeated words
+func (b *BPETokenizer) encodePiece(piece string) []int {
+	// tiktoken keeps whole-word tokens even when no merge order produces them
+	if id, exists := b.vocab[piece]; exists && b.merges == nil {
+		return []int{id}
+	}
+
+	b.mutex.Lock()
+	ids, cached := b.cache[piece]
+	b.mutex.Unlock()
+	if cached {
+		return ids
+	}
+
+	parts := b.merge(piece)
+	ids = make([]int, len(parts))
+	for i, part := range parts {
+		id, exists := b.vocab[part]
+		if !exists {
+			id = UnknownTokenID
+		}
+		ids[i] = id
+	}
+
+	b.mutex.Lock()
+	if len(b.cache) >= bpeCacheSize {
+		b.cache = make(map[string][]int)
+	}
+	b.cache[piece] = ids
+	b.mutex.Unlock()
+
+	return ids
+}
+
+// merge splits piece into single bytes and repeatedly merges the adjacent pair
+// with the lowest rank until no pair can be merged
+func (b *BPETokenizer) merge(piece string) []string {
+	parts := make([]string, len(piece))
+	for i := 0; i < len(piece); i++ {
+		parts[i] = piece[i : i+1]
+	}
+
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i+1 < len(parts); i++ {
+			if rank, ok := b.pairRank(parts[i], parts[i+1]); ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+
+	return parts
+}
+
+// pairRank returns the merge rank of two adjacent parts
+func (b *BPETokenizer) pairRank(left, right string) (int, bool) {
+	if b.merges != nil {
+		rank, ok := b.merges[[2]string{left, right}]
+		return rank, ok
+	}
+	rank, ok := b.vocab[left+right]
+	return rank, ok
+}
+
+// byteLevelDecoder returns a function mapping GPT-2 byte-level token strings,
+// where every byte is written as a printable rune, back to their bytes
+func byteLevelDecoder() func(string) string {
+	runeToByte := make(map[rune]byte, 256)
+	next := rune(256)
+	for i := 0; i < 256; i++ {
+		c := byte(i)
+		if (c >= '!' && c <= '~') || (c >= 0xA1 && c <= 0xAC) || c >= 0xAE {
+			runeToByte[rune(c)] = c
+			continue
+		}
+		runeToByte[next] = c
+		next++
+	}
+
+	return func(token string) string {
+		var decoded strings.Builder
+		for _, r := range token {
+			if c, ok := runeToByte[r]; ok {
+				decoded.WriteByte(c)
+			} else {
+				decoded.WriteRune(r)
+			}
+		}
+		return decoded.String()
+	}
+}
@@ -0,0 +1,162 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// TokenCalibrator compares the token estimates of a Tokenizer with the usage
+// providers report for the same calls. Drift is (estimated - actual) / actual:
+// negative values mean the estimates are too low and limits and budgets let
+// through more than they should.
+type TokenCalibrator struct {
+	tokenizer *Tokenizer
+	logger    interfaces.Logger
+	stats     map[string]*CalibrationStats // by provider and model
+	mutex     sync.Mutex
+}
+
+// CalibrationStats accumulates the estimates and reported usage of one model
+type CalibrationStats struct {
+	Provider  string `json:"provider"`
+	Model     string `json:"model"`
+	Tokenizer string `json:"tokenizer"` // tokenizer name, "heuristic" without one
+	Samples   int    `json:"samples"`
+
+	EstimatedPromptTokens     int `json:"estimated_prompt_tokens"`
+	ActualPromptTokens        int `json:"actual_prompt_tokens"`
+	EstimatedCompletionTokens int `json:"estimated_completion_tokens"`
+	ActualCompletionTokens    int `json:"actual_completion_tokens"`
+
+	// MaxPromptDrift is the prompt drift of the call furthest from its usage
+	MaxPromptDrift float64 `json:"max_prompt_drift"`
+}
+
+// HeuristicTokenizerName names estimates made without a mapped tokenizer
+const HeuristicTokenizerName = "heuristic"
+
+// NewTokenCalibrator creates a calibrator for the estimates of tokenizer
+func NewTokenCalibrator(tokenizer *Tokenizer, logger interfaces.Logger) *TokenCalibrator {
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+	if tokenizer == nil {
+		tokenizer = NewTokenizer(interfaces.NewNoOpLogger())
+	}
+	return &TokenCalibrator{
+		tokenizer: tokenizer,
+		logger:    logger,
+		stats:     make(map[string]*CalibrationStats),
+	}
+}
+
+// Observe estimates the tokens of a completed call and records them with the
+// usage in the response. Calls without reported usage are skipped.
+func (c *TokenCalibrator) Observe(req *interfaces.PonchoModelRequest, resp *interfaces.PonchoModelResponse, provider Provider, modelName string) error {
+	if req == nil || resp == nil || resp.Usage == nil || resp.Usage.PromptTokens <= 0 {
+		return nil
+	}
+
+	estimated, err := c.tokenizer.CountRequestTokens(req, provider, modelName)
+	if err != nil {
+		return fmt.Errorf("failed to estimate request tokens: %w", err)
+	}
+
+	completion := 0
+	if resp.Message != nil && resp.Usage.CompletionTokens > 0 {
+		if completion, err = c.tokenizer.CountMessageTokens(resp.Message, provider, modelName); err != nil {
+			return fmt.Errorf("failed to estimate completion tokens: %w", err)
+		}
+	}
+
+	tokenizerName := HeuristicTokenizerName
+	if textTokenizer, exists := TextTokenizerFor(modelName); exists {
+		tokenizerName = textTokenizer.Name()
+	}
+
+	drift := tokenDrift(estimated.PromptTokens, resp.Usage.PromptTokens)
+
+	c.mutex.Lock()
+	key := string(provider) + "/" + modelName
+	stats, exists := c.stats[key]
+	if !exists {
+		stats = &CalibrationStats{Provider: string(provider), Model: modelName}
+		c.stats[key] = stats
+	}
+	stats.Tokenizer = tokenizerName
+	stats.Samples++
+	stats.EstimatedPromptTokens += estimated.PromptTokens
+	stats.ActualPromptTokens += resp.Usage.PromptTokens
+	if completion > 0 {
+		stats.EstimatedCompletionTokens += completion
+		stats.ActualCompletionTokens += resp.Usage.CompletionTokens
+	}
+	if math.Abs(drift) > math.Abs(stats.MaxPromptDrift) {
+		stats.MaxPromptDrift = drift
+	}
+	c.mutex.Unlock()
+
+	c.logger.Debug("Token estimate calibrated",
+		"provider", provider,
+		"model", modelName,
+		"tokenizer", tokenizerName,
+		"estimated_prompt_tokens", estimated.PromptTokens,
+		"actual_prompt_tokens", resp.Usage.PromptTokens,
+		"drift", drift)
+
+	return nil
+}
+
+// Report returns the statistics of every observed model, sorted by provider and model
+func (c *TokenCalibrator) Report() []CalibrationStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	report := make([]CalibrationStats, 0, len(c.stats))
+	for _, stats := range c.stats {
+		report = append(report, *stats)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Provider != report[j].Provider {
+			return report[i].Provider < report[j].Provider
+		}
+		return report[i].Model < report[j].Model
+	})
+	return report
+}
+
+// Reset discards all observations
+func (c *TokenCalibrator) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stats = make(map[string]*CalibrationStats)
+}
+
+// PromptDrift returns the drift of all prompt estimates of the model
+func (s CalibrationStats) PromptDrift() float64 {
+	return tokenDrift(s.EstimatedPromptTokens, s.ActualPromptTokens)
+}
+
+// CompletionDrift returns the drift of all completion estimates of the model
+func (s CalibrationStats) CompletionDrift() float64 {
+	return tokenDrift(s.EstimatedCompletionTokens, s.ActualCompletionTokens)
+}
+
+// String formats the statistics for logs and reports
+func (s CalibrationStats) String() string {
+	return fmt.Sprintf("%s/%s (%s): %d calls, prompt %+.1f%% (worst %+.1f%%), completion %+.1f%%",
+		s.Provider, s.Model, s.Tokenizer, s.Samples,
+		s.PromptDrift()*100, s.MaxPromptDrift*100, s.CompletionDrift()*100)
+}
+
+// tokenDrift returns (estimated - actual) / actual, or 0 without actual tokens
+func tokenDrift(estimated, actual int) float64 {
+	if actual <= 0 {
+		return 0
+	}
+	return float64(estimated-actual) / float64(actual)
+}
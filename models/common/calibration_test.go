@@ -0,0 +1,92 @@
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+func TestTokenCalibrator_Observe(t *testing.T) {
+	tokenizer := NewTokenizer(interfaces.NewNoOpLogger())
+	calibrator := NewTokenCalibrator(tokenizer, interfaces.NewNoOpLogger())
+
+	req := &interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Опиши платье из вискозы для карточки товара"}},
+		}},
+	}
+	answer := &interfaces.PonchoMessage{
+		Role:    interfaces.PonchoRoleAssistant,
+		Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Лёгкое летнее платье"}},
+	}
+
+	estimated, err := tokenizer.CountRequestTokens(req, ProviderDeepSeek, "deepseek-chat")
+	if err != nil {
+		t.Fatalf("CountRequestTokens() error = %v", err)
+	}
+
+	for _, actual := range []int{100, 50} {
+		resp := &interfaces.PonchoModelResponse{
+			Message: answer,
+			Usage:   &interfaces.PonchoUsage{PromptTokens: actual, CompletionTokens: 10, TotalTokens: actual + 10},
+		}
+		if err := calibrator.Observe(req, resp, ProviderDeepSeek, "deepseek-chat"); err != nil {
+			t.Fatalf("Observe() error = %v", err)
+		}
+	}
+
+	// Calls without usage are skipped
+	if err := calibrator.Observe(req, &interfaces.PonchoModelResponse{Message: answer}, ProviderDeepSeek, "deepseek-chat"); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+
+	report := calibrator.Report()
+	if len(report) != 1 {
+		t.Fatalf("expected one model in the report, got %d", len(report))
+	}
+
+	stats := report[0]
+	if stats.Samples != 2 || stats.Tokenizer != HeuristicTokenizerName {
+		t.Errorf("Samples = %d, Tokenizer = %q, want 2 and %q", stats.Samples, stats.Tokenizer, HeuristicTokenizerName)
+	}
+	if stats.EstimatedPromptTokens != 2*estimated.PromptTokens || stats.ActualPromptTokens != 150 {
+		t.Errorf("prompt tokens = %d estimated / %d actual, want %d / 150",
+			stats.EstimatedPromptTokens, stats.ActualPromptTokens, 2*estimated.PromptTokens)
+	}
+
+	wantDrift := float64(2*estimated.PromptTokens-150) / 150
+	if math.Abs(stats.PromptDrift()-wantDrift) > 1e-9 {
+		t.Errorf("PromptDrift() = %f, want %f", stats.PromptDrift(), wantDrift)
+	}
+	wantMax := float64(estimated.PromptTokens-100) / 100
+	if other := float64(estimated.PromptTokens-50) / 50; math.Abs(other) > math.Abs(wantMax) {
+		wantMax = other
+	}
+	if math.Abs(stats.MaxPromptDrift-wantMax) > 1e-9 {
+		t.Errorf("MaxPromptDrift = %f, want %f", stats.MaxPromptDrift, wantMax)
+	}
+	if stats.ActualCompletionTokens != 20 || stats.EstimatedCompletionTokens == 0 {
+		t.Errorf("completion tokens = %d estimated / %d actual", stats.EstimatedCompletionTokens, stats.ActualCompletionTokens)
+	}
+
+	calibrator.Reset()
+	if len(calibrator.Report()) != 0 {
+		t.Error("expected an empty report after Reset()")
+	}
+}
+
+func TestTokenCalibrator_UnknownModel(t *testing.T) {
+	calibrator := NewTokenCalibrator(nil, interfaces.NewNoOpLogger())
+
+	err := calibrator.Observe(&interfaces.PonchoModelRequest{}, &interfaces.PonchoModelResponse{
+		Usage: &interfaces.PonchoUsage{PromptTokens: 10, TotalTokens: 10},
+	}, ProviderCustom, "unknown-model")
+	if err == nil {
+		t.Error("expected an error for a model without token configuration or tokenizer")
+	}
+	if len(calibrator.Report()) != 0 {
+		t.Error("failed observations must not be recorded")
+	}
+}
@@ -6,6 +6,8 @@
 // Key Features:
 // - Connection pooling with configurable limits
 // - Automatic retry with exponential backoff and jitter
+// - Retry-After headers honored between attempts
+// - Optional client-side rate limiting fed by rate limit headers
 // - Circuit breaker pattern for fault tolerance
 // - Request/response cloning for retry safety
 // - Configurable timeouts and TLS settings
@@ -58,6 +60,7 @@ type HTTPClient struct {
 	client      *http.Client
 	config      *HTTPConfig
 	retryConfig *RetryConfig
+	rateLimiter *RateLimiter
 }
 
 // NewHTTPClient creates a new HTTP client with given configuration
@@ -103,11 +106,45 @@ func NewHTTPClient(config *HTTPConfig, retryConfig RetryConfig) (*HTTPClient, er
 	}, nil
 }
 
-// Do executes an HTTP request with retry logic
+// httpRetryKey marks contexts whose requests must not be retried by HTTPClient
+type httpRetryKey struct{}
+
+// WithoutHTTPRetry returns a context in which HTTPClient makes a single attempt per
+// request, for callers that apply their own retry policy (see RetryExecutor)
+func WithoutHTTPRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, httpRetryKey{}, true)
+}
+
+// httpRetryDisabled reports whether ctx was marked by WithoutHTTPRetry
+func httpRetryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(httpRetryKey{}).(bool)
+	return disabled
+}
+
+// SetRateLimiter makes every attempt wait for the limiter's budget and
+// report the rate limit headers of its response to it
+func (c *HTTPClient) SetRateLimiter(limiter *RateLimiter) {
+	c.rateLimiter = limiter
+}
+
+// RateLimiter returns the rate limiter of the client, nil when unlimited
+func (c *HTTPClient) RateLimiter() *RateLimiter {
+	return c.rateLimiter
+}
+
+// Do executes an HTTP request with retry logic. Retryable responses are retried
+// after the Retry-After delay when the server sends one; the last retryable
+// response is returned to the caller, so its status and headers can be mapped
+// with ErrorFromHTTPResponse.
 func (c *HTTPClient) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	maxAttempts := c.retryConfig.MaxAttempts
+	if httpRetryDisabled(ctx) {
+		maxAttempts = 1
+	}
+
 	var lastErr error
 
-	for attempt := 1; attempt <= c.retryConfig.MaxAttempts; attempt++ {
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		// Clone request for each attempt to avoid body consumption issues
 		reqClone := c.cloneRequest(req)
 
@@ -116,29 +153,50 @@ func (c *HTTPClient) Do(ctx context.Context, req *http.Request) (*http.Response,
 			reqClone.Header.Set("User-Agent", c.config.UserAgent)
 		}
 
+		// Wait for the provider's rate limit budget
+		if c.rateLimiter != nil {
+			if err := c.rateLimiter.Wait(ctx, rateLimitTokens(ctx)); err != nil {
+				return nil, err
+			}
+		}
+
 		// Execute request
 		resp, err := c.client.Do(reqClone.WithContext(ctx))
-		if err == nil {
-			// Check for non-retryable status codes
-			if !c.isRetryableStatus(resp.StatusCode) {
-				return resp, nil
+		if err == nil && c.rateLimiter != nil {
+			if info, ok := ParseRateLimitHeaders(resp.Header, time.Now()); ok {
+				c.rateLimiter.Update(info)
 			}
-			resp.Body.Close() // Close body before retry
+		}
+		if err == nil && !c.isRetryableStatus(resp.StatusCode) {
+			return resp, nil
 		}
 
-		lastErr = err
-
-		// If this is the last attempt, return the error
-		if attempt == c.retryConfig.MaxAttempts {
+		// If this is the last attempt, return the error or the retryable response
+		if attempt == maxAttempts {
 			if err != nil {
-				return nil, fmt.Errorf("request failed after %d attempts: %w", c.retryConfig.MaxAttempts, err)
+				return nil, fmt.Errorf("request failed after %d attempts: %w", attempt, err)
 			}
-			return nil, fmt.Errorf("request failed after %d attempts with status code: %d", c.retryConfig.MaxAttempts, resp.StatusCode)
+			return resp, nil
 		}
 
 		// Calculate delay for next attempt
 		delay := c.calculateDelay(attempt)
 
+		if err == nil {
+			// Honor the server's Retry-After; give up when it asks for more than MaxDelay
+			if retryAfter, ok := RetryAfterFromHeader(resp.Header, time.Now()); ok {
+				if retryAfter > c.retryConfig.MaxDelay {
+					return resp, nil
+				}
+				if retryAfter > delay {
+					delay = retryAfter
+				}
+			}
+			resp.Body.Close() // Close body before retry
+		}
+
+		lastErr = err
+
 		// Wait before retry (with context cancellation)
 		select {
 		case <-ctx.Done():
@@ -220,6 +278,13 @@ func (c *HTTPClient) cloneRequest(req *http.Request) *http.Request {
 		reqClone.Header[k] = append([]string(nil), v...)
 	}
 
+	// Give every attempt a fresh body; the previous attempt consumed it
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqClone.Body = body
+		}
+	}
+
 	return reqClone
 }
 
@@ -7,7 +7,7 @@
 // - Bidirectional conversion between PonchoFramework and provider formats
 // - Multimodal content support (text + images + tools)
 // - Media processing with base64 encoding and validation
-// - Provider-specific format handling (DeepSeek, Z.AI, OpenAI)
+// - Provider-specific format handling (DeepSeek, Z.AI, OpenAI, Anthropic)
 // - Tool definition and tool call conversion
 // - Fashion-specific media processing capabilities
 //
@@ -15,12 +15,15 @@
 // - Text: Plain text content with role-based messaging
 // - Media: Images and other media with URL/base64 support
 // - Tool: Tool calls and tool definitions with arguments
+// - Tool result: Tool output answering a call ID, optionally flagged as an error
+// - Reasoning: Model thinking, kept out of provider requests
 // - Multimodal: Combinations of text, media, and tools
 //
 // Provider Formats:
 // - DeepSeek: OpenAI-compatible format with tool calling
 // - Z.AI: Custom format with vision support and multimodal arrays
 // - OpenAI: Standard OpenAI API format
+// - Anthropic: Top-level system prompt and typed content blocks
 //
 // Media Processing:
 // - Image validation (JPEG, PNG, WebP, GIF)
@@ -43,6 +46,7 @@ package common
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -77,6 +81,8 @@ func (cc *ContentConverter) ConvertToProviderFormat(messages []*interfaces.Ponch
 		return cc.convertToZAIFormat(messages)
 	case ProviderOpenAI:
 		return cc.convertToOpenAIFormat(messages)
+	case ProviderAnthropic:
+		return cc.convertToAnthropicFormat(messages)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
@@ -91,6 +97,8 @@ func (cc *ContentConverter) ConvertFromProviderFormat(providerMessages interface
 		return cc.convertFromZAIFormat(providerMessages)
 	case ProviderOpenAI:
 		return cc.convertFromOpenAIFormat(providerMessages)
+	case ProviderAnthropic:
+		return cc.convertFromAnthropicFormat(providerMessages)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
@@ -111,6 +119,10 @@ func (cc *ContentConverter) convertToDeepSeekFormat(messages []*interfaces.Ponch
 			deepseekMsg["name"] = *msg.Name
 		}
 
+		if msg.ToolCallID != "" {
+			deepseekMsg["tool_call_id"] = msg.ToolCallID
+		}
+
 		// Add tool calls if present
 		if len(msg.Content) > 0 {
 			toolCalls := cc.extractToolCalls(msg)
@@ -145,6 +157,10 @@ func (cc *ContentConverter) convertToZAIFormat(messages []*interfaces.PonchoMess
 			zaiMsg["name"] = *msg.Name
 		}
 
+		if msg.ToolCallID != "" {
+			zaiMsg["tool_call_id"] = msg.ToolCallID
+		}
+
 		// Add tool calls if present
 		if len(msg.Content) > 0 {
 			toolCalls := cc.extractToolCalls(msg)
@@ -165,6 +181,158 @@ func (cc *ContentConverter) convertToOpenAIFormat(messages []*interfaces.PonchoM
 	return cc.convertToDeepSeekFormat(messages)
 }
 
+// SplitSystemMessages separates system messages from the conversation.
+// Providers with a top-level system field (Anthropic) take the joined system
+// text separately and reject "system" as a message role.
+func SplitSystemMessages(messages []*interfaces.PonchoMessage) (string, []*interfaces.PonchoMessage) {
+	var systemParts []string
+	conversation := make([]*interfaces.PonchoMessage, 0, len(messages))
+
+	for _, msg := range messages {
+		if msg.Role != interfaces.PonchoRoleSystem {
+			conversation = append(conversation, msg)
+			continue
+		}
+		for _, part := range msg.Content {
+			if part.Type == interfaces.PonchoContentTypeText && part.Text != "" {
+				systemParts = append(systemParts, part.Text)
+			}
+		}
+	}
+
+	return strings.Join(systemParts, "\n\n"), conversation
+}
+
+// ToolCallID returns the ID of the tool call a tool message answers: the
+// message's ToolCallID, else that of its tool_result part. Messages written
+// before either existed carried the ID in Name.
+func ToolCallID(msg *interfaces.PonchoMessage) string {
+	if msg.ToolCallID != "" {
+		return msg.ToolCallID
+	}
+	for _, part := range msg.Content {
+		if part != nil && part.Type == interfaces.PonchoContentTypeToolResult && part.ToolResult != nil && part.ToolResult.ToolCallID != "" {
+			return part.ToolResult.ToolCallID
+		}
+	}
+	if msg.Name != nil {
+		return *msg.Name
+	}
+	return ""
+}
+
+// ToolResultText formats a tool result for providers that take tool output as
+// text: strings as they are, other values as JSON. Errors are wrapped as
+// {"error": ...} so providers without an error flag still see the failure.
+func ToolResultText(result *interfaces.PonchoToolResultPart) string {
+	if result == nil || result.Content == nil {
+		return ""
+	}
+
+	content := result.Content
+	if text, ok := content.(string); ok {
+		if !result.IsError {
+			return text
+		}
+		content = map[string]interface{}{"error": text}
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Sprintf("%v", content)
+	}
+	return string(data)
+}
+
+// ToolResultIsError reports whether a tool message carries a failed tool result
+func ToolResultIsError(msg *interfaces.PonchoMessage) bool {
+	for _, part := range msg.Content {
+		if part != nil && part.Type == interfaces.PonchoContentTypeToolResult && part.ToolResult != nil && part.ToolResult.IsError {
+			return true
+		}
+	}
+	return false
+}
+
+// MessageText joins the text and tool_result parts of a message. Reasoning is
+// left out: providers reject or ignore earlier thinking sent back as input.
+func MessageText(msg *interfaces.PonchoMessage) string {
+	var textBuilder strings.Builder
+
+	for _, part := range msg.Content {
+		if part == nil {
+			continue
+		}
+		switch part.Type {
+		case interfaces.PonchoContentTypeText:
+			textBuilder.WriteString(part.Text)
+		case interfaces.PonchoContentTypeToolResult:
+			textBuilder.WriteString(ToolResultText(part.ToolResult))
+		}
+	}
+
+	return textBuilder.String()
+}
+
+// convertToAnthropicFormat converts to Anthropic Messages API format.
+// Returns a map with "system" (string) and "messages" (content block arrays);
+// tool messages become user messages with tool_result blocks.
+func (cc *ContentConverter) convertToAnthropicFormat(messages []*interfaces.PonchoMessage) (interface{}, error) {
+	system, conversation := SplitSystemMessages(messages)
+	anthropicMessages := make([]map[string]interface{}, 0, len(conversation))
+
+	for _, msg := range conversation {
+		role := string(msg.Role)
+		blocks := make([]map[string]interface{}, 0, len(msg.Content))
+
+		if msg.Role == interfaces.PonchoRoleTool {
+			role = string(interfaces.PonchoRoleUser)
+			block := map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": ToolCallID(msg),
+				"content":     cc.extractTextContent(msg),
+			}
+			if ToolResultIsError(msg) {
+				block["is_error"] = true
+			}
+			blocks = append(blocks, block)
+		} else {
+			for _, part := range msg.Content {
+				switch part.Type {
+				case interfaces.PonchoContentTypeText:
+					blocks = append(blocks, map[string]interface{}{"type": "text", "text": part.Text})
+				case interfaces.PonchoContentTypeMedia:
+					if part.Media != nil {
+						blocks = append(blocks, map[string]interface{}{
+							"type":   "image",
+							"source": map[string]interface{}{"type": "url", "url": part.Media.URL},
+						})
+					}
+				case interfaces.PonchoContentTypeTool:
+					if part.Tool != nil {
+						blocks = append(blocks, map[string]interface{}{
+							"type":  "tool_use",
+							"id":    part.Tool.ID,
+							"name":  part.Tool.Name,
+							"input": part.Tool.Args,
+						})
+					}
+				}
+			}
+		}
+
+		anthropicMessages = append(anthropicMessages, map[string]interface{}{
+			"role":    role,
+			"content": blocks,
+		})
+	}
+
+	return map[string]interface{}{
+		"system":   system,
+		"messages": anthropicMessages,
+	}, nil
+}
+
 // convertFromDeepSeekFormat converts from DeepSeek message format
 func (cc *ContentConverter) convertFromDeepSeekFormat(providerMessages interface{}) ([]*interfaces.PonchoMessage, error) {
 	messages, ok := providerMessages.([]map[string]interface{})
@@ -187,6 +355,7 @@ func (cc *ContentConverter) convertFromDeepSeekFormat(providerMessages interface
 				},
 			},
 		}
+		cc.addReasoningAndToolResult(ponchoMsg, msg)
 
 		// Add name if present
 		if name, hasName := msg["name"]; hasName {
@@ -240,6 +409,7 @@ func (cc *ContentConverter) convertFromZAIFormat(providerMessages interface{}) (
 			contentParts := cc.convertContentFromZAI(content)
 			ponchoMsg.Content = contentParts
 		}
+		cc.addReasoningAndToolResult(ponchoMsg, msg)
 
 		// Add name if present
 		if name, hasName := msg["name"]; hasName {
@@ -277,6 +447,85 @@ func (cc *ContentConverter) convertFromOpenAIFormat(providerMessages interface{}
 	return cc.convertFromDeepSeekFormat(providerMessages)
 }
 
+// convertFromAnthropicFormat converts from Anthropic message format
+func (cc *ContentConverter) convertFromAnthropicFormat(providerMessages interface{}) ([]*interfaces.PonchoMessage, error) {
+	messages, ok := providerMessages.([]map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid Anthropic message format")
+	}
+
+	ponchoMessages := make([]*interfaces.PonchoMessage, len(messages))
+
+	for i, msg := range messages {
+		role, _ := msg["role"].(string)
+
+		ponchoMsg := &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRole(role),
+			Content: []*interfaces.PonchoContentPart{},
+		}
+
+		switch content := msg["content"].(type) {
+		case string:
+			ponchoMsg.Content = append(ponchoMsg.Content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeText,
+				Text: content,
+			})
+		case []interface{}:
+			for _, item := range content {
+				block, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				switch block["type"] {
+				case "text":
+					text, _ := block["text"].(string)
+					ponchoMsg.Content = append(ponchoMsg.Content, &interfaces.PonchoContentPart{
+						Type: interfaces.PonchoContentTypeText,
+						Text: text,
+					})
+				case "tool_use":
+					tool := &interfaces.PonchoToolPart{}
+					tool.ID, _ = block["id"].(string)
+					tool.Name, _ = block["name"].(string)
+					tool.Args, _ = block["input"].(map[string]interface{})
+					ponchoMsg.Content = append(ponchoMsg.Content, &interfaces.PonchoContentPart{
+						Type: interfaces.PonchoContentTypeTool,
+						Tool: tool,
+					})
+				}
+			}
+		}
+
+		ponchoMessages[i] = ponchoMsg
+	}
+
+	return ponchoMessages, nil
+}
+
+// addReasoningAndToolResult completes a message converted from an
+// OpenAI-style map: the text of a tool message becomes a tool_result part
+// answering tool_call_id, and reasoning_content a reasoning part after the text.
+func (cc *ContentConverter) addReasoningAndToolResult(ponchoMsg *interfaces.PonchoMessage, msg map[string]interface{}) {
+	if ponchoMsg.Role == interfaces.PonchoRoleTool {
+		ponchoMsg.ToolCallID, _ = msg["tool_call_id"].(string)
+		text := cc.extractTextContent(ponchoMsg)
+		ponchoMsg.Content = []*interfaces.PonchoContentPart{{
+			Type: interfaces.PonchoContentTypeToolResult,
+			ToolResult: &interfaces.PonchoToolResultPart{
+				ToolCallID: ponchoMsg.ToolCallID,
+				Content:    text,
+			},
+		}}
+	}
+
+	if reasoning, _ := msg["reasoning_content"].(string); reasoning != "" {
+		ponchoMsg.Content = append(ponchoMsg.Content, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeReasoning,
+			Text: reasoning,
+		})
+	}
+}
+
 // convertContentForZAI converts PonchoFramework content to Z.AI format
 func (cc *ContentConverter) convertContentForZAI(msg *interfaces.PonchoMessage) interface{} {
 	// Check if this is a multimodal message
@@ -302,6 +551,10 @@ func (cc *ContentConverter) convertToMultimodalContent(parts []*interfaces.Ponch
 	multimodalParts := make([]map[string]interface{}, 0, len(parts))
 
 	for _, part := range parts {
+		if part.Type == interfaces.PonchoContentTypeReasoning {
+			continue
+		}
+
 		partMap := map[string]interface{}{
 			"type": string(part.Type),
 		}
@@ -309,6 +562,9 @@ func (cc *ContentConverter) convertToMultimodalContent(parts []*interfaces.Ponch
 		switch part.Type {
 		case interfaces.PonchoContentTypeText:
 			partMap["text"] = part.Text
+		case interfaces.PonchoContentTypeToolResult:
+			partMap["type"] = string(interfaces.PonchoContentTypeText)
+			partMap["text"] = ToolResultText(part.ToolResult)
 		case interfaces.PonchoContentTypeMedia:
 			if part.Media != nil {
 				mediaMap := map[string]interface{}{
@@ -427,15 +683,7 @@ func (cc *ContentConverter) convertContentPartFromMap(partMap map[string]interfa
 
 // extractTextContent extracts text content from message
 func (cc *ContentConverter) extractTextContent(msg *interfaces.PonchoMessage) string {
-	var textBuilder strings.Builder
-
-	for _, part := range msg.Content {
-		if part.Type == interfaces.PonchoContentTypeText && part.Text != "" {
-			textBuilder.WriteString(part.Text)
-		}
-	}
-
-	return textBuilder.String()
+	return MessageText(msg)
 }
 
 // extractToolCalls extracts tool calls from message
@@ -705,6 +953,8 @@ func ConvertToolsToProviderFormat(tools []*interfaces.PonchoToolDef, provider Pr
 		return convertToolsToOpenAIFormat(tools), nil
 	case ProviderZAI:
 		return convertToolsToZAIFormat(tools), nil
+	case ProviderAnthropic:
+		return convertToolsToAnthropicFormat(tools), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
@@ -733,4 +983,19 @@ func convertToolsToOpenAIFormat(tools []*interfaces.PonchoToolDef) []map[string]
 func convertToolsToZAIFormat(tools []*interfaces.PonchoToolDef) []map[string]interface{} {
 	// Z.AI format is similar to OpenAI
 	return convertToolsToOpenAIFormat(tools)
-}
\ No newline at end of file
+}
+
+// convertToolsToAnthropicFormat converts tools to Anthropic format
+func convertToolsToAnthropicFormat(tools []*interfaces.PonchoToolDef) []map[string]interface{} {
+	providerTools := make([]map[string]interface{}, len(tools))
+
+	for i, tool := range tools {
+		providerTools[i] = map[string]interface{}{
+			"name":         tool.Name,
+			"description":  tool.Description,
+			"input_schema": tool.Parameters,
+		}
+	}
+
+	return providerTools
+}
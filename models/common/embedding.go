@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// EmbedBatchFunc embeds one batch of at most MaxBatchSize inputs
+type EmbedBatchFunc func(ctx context.Context, batch []string) ([][]float32, *interfaces.PonchoUsage, error)
+
+// EmbedInBatches splits inputs into batches of batchSize, calls embed for each
+// batch in order and joins the vectors and usage. Every batch must return one
+// vector per input.
+func EmbedInBatches(ctx context.Context, inputs []string, batchSize int, embed EmbedBatchFunc) ([][]float32, *interfaces.PonchoUsage, error) {
+	if batchSize <= 0 {
+		batchSize = len(inputs)
+	}
+
+	vectors := make([][]float32, 0, len(inputs))
+	usage := &interfaces.PonchoUsage{}
+
+	for start := 0; start < len(inputs); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		end := start + batchSize
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+
+		batch, batchUsage, err := embed(ctx, inputs[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(batch) != end-start {
+			return nil, nil, fmt.Errorf("embedding batch returned %d vectors for %d inputs", len(batch), end-start)
+		}
+
+		vectors = append(vectors, batch...)
+		if batchUsage != nil {
+			usage.PromptTokens += batchUsage.PromptTokens
+			usage.TotalTokens += batchUsage.TotalTokens
+		}
+	}
+
+	return vectors, usage, nil
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 when
+// their lengths differ or one of them is zero
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -67,7 +67,7 @@ func NewErrorHandler(provider Provider, logger interfaces.Logger) *ErrorHandler
 
 // IsRetryableError checks if an error is retryable for this provider
 func (eh *ErrorHandler) IsRetryableError(err error) bool {
-	if modelErr, ok := err.(*ModelError); ok {
+	if modelErr, ok := IsModelError(err); ok {
 		return modelErr.Retryable
 	}
 	
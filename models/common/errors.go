@@ -22,11 +22,14 @@
 // - RATE_LIMIT_ERROR: API rate limit exceeded (retryable)
 // - TOKEN_LIMIT_EXCEEDED: Model token limit exceeded
 // - CONTENT_FILTERED: Content blocked by safety filters
+// - BUDGET_EXCEEDED: Request rejected by a spend budget
 // - SERVER_ERROR: Internal server error (retryable)
+// - CIRCUIT_OPEN: Request rejected by an open circuit breaker
 //
 // Retry Logic:
 // - Automatic retryability determination based on error type
 // - HTTP status code analysis for retry decisions
+// - Retry-After hints from provider responses (ErrorFromHTTPResponse)
 // - Provider-specific retry strategies
 // - Circuit breaker integration support
 //
@@ -45,8 +48,13 @@
 package common
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ModelError represents a unified error type for all models
@@ -59,6 +67,8 @@ type ModelError struct {
 	Retryable   bool          `json:"retryable"`
 	Details     interface{}   `json:"details,omitempty"`
 	Cause       error         `json:"cause,omitempty"`
+	// RetryAfter is the delay the provider asked for before retrying (Retry-After header)
+	RetryAfter  time.Duration `json:"retry_after,omitempty"`
 }
 
 // ModelErrorCode represents different types of model errors
@@ -79,6 +89,7 @@ const (
 
 	// API errors
 	ErrorCodeInvalidRequest   ModelErrorCode = "INVALID_REQUEST"
+	ErrorCodeUnsupportedParameter ModelErrorCode = "UNSUPPORTED_PARAMETER"
 	ErrorCodeUnauthorized     ModelErrorCode = "UNAUTHORIZED"
 	ErrorCodeForbidden        ModelErrorCode = "FORBIDDEN"
 	ErrorCodeNotFound        ModelErrorCode = "NOT_FOUND"
@@ -90,6 +101,8 @@ const (
 	ErrorCodeInvalidModel     ModelErrorCode = "INVALID_MODEL"
 	ErrorCodeTokenLimitExceeded ModelErrorCode = "TOKEN_LIMIT_EXCEEDED"
 	ErrorCodeContentFiltered  ModelErrorCode = "CONTENT_FILTERED"
+	ErrorCodeBudgetExceeded   ModelErrorCode = "BUDGET_EXCEEDED"
+	ErrorCodeCircuitOpen      ModelErrorCode = "CIRCUIT_OPEN"
 
 	// Streaming errors
 	ErrorCodeStreamError      ModelErrorCode = "STREAM_ERROR"
@@ -272,9 +285,78 @@ func ErrorFromHTTPStatus(statusCode int, provider, model string) *ModelError {
 	}
 }
 
-// IsModelError checks if an error is a ModelError
+// ErrorFromHTTPResponse creates a model error from an HTTP response, keeping the
+// Retry-After hint of rate limited and unavailable responses
+func ErrorFromHTTPResponse(resp *http.Response, provider, model string) *ModelError {
+	modelErr := ErrorFromHTTPStatus(resp.StatusCode, provider, model)
+	if delay, ok := RetryAfterFromHeader(resp.Header, time.Now()); ok {
+		modelErr.RetryAfter = delay
+	}
+	return modelErr
+}
+
+// ErrorFromAPIResponse creates a model error from a failed HTTP response like
+// ErrorFromHTTPResponse, keeping the error message the API returned in the body.
+// apiError extracts the message and its details from the body; an empty message
+// means the body carries none.
+func ErrorFromAPIResponse(resp *http.Response, provider, model string, apiError func(body []byte) (message string, details interface{})) *ModelError {
+	modelErr := ErrorFromHTTPResponse(resp, provider, model)
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil || len(data) == 0 {
+		return modelErr
+	}
+
+	message, details := apiError(data)
+	if message == "" {
+		return modelErr
+	}
+	modelErr.Message = fmt.Sprintf("%s: %s", modelErr.Message, message)
+	if details != nil {
+		modelErr = modelErr.WithDetails(details)
+	}
+	return modelErr
+}
+
+// RetryAfterFromHeader returns the retry delay requested by response headers:
+// retry-after-ms (OpenAI compatible APIs) or the standard Retry-After
+func RetryAfterFromHeader(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := strings.TrimSpace(header.Get("Retry-After-Ms")); value != "" {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+	return ParseRetryAfter(header.Get("Retry-After"), now)
+}
+
+// ParseRetryAfter parses a Retry-After value: delay seconds or an HTTP date
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// IsModelError checks if an error is or wraps a ModelError
 func IsModelError(err error) (*ModelError, bool) {
-	if modelErr, ok := err.(*ModelError); ok {
+	var modelErr *ModelError
+	if errors.As(err, &modelErr) {
 		return modelErr, true
 	}
 	return nil, false
@@ -282,7 +364,7 @@ func IsModelError(err error) (*ModelError, bool) {
 
 // GetErrorCode extracts the error code from an error
 func GetErrorCode(err error) ModelErrorCode {
-	if modelErr, ok := err.(*ModelError); ok {
+	if modelErr, ok := IsModelError(err); ok {
 		return modelErr.Code
 	}
 	return ErrorCodeUnknownError
@@ -290,7 +372,7 @@ func GetErrorCode(err error) ModelErrorCode {
 
 // IsRetryableModelError checks if an error is retryable
 func IsRetryableModelError(err error) bool {
-	if modelErr, ok := err.(*ModelError); ok {
+	if modelErr, ok := IsModelError(err); ok {
 		return modelErr.Retryable
 	}
 	return false
@@ -333,6 +415,24 @@ func NewContentFilteredError(message, provider, model string) *ModelError {
 	return NewModelError(ErrorCodeContentFiltered, message, provider, model)
 }
 
+// NewBudgetExceededError creates a budget exceeded error
+func NewBudgetExceededError(message, provider, model string) *ModelError {
+	return NewModelError(ErrorCodeBudgetExceeded, message, provider, model)
+}
+
+// NewUnsupportedParameterError creates an error for a generation parameter the
+// provider cannot honor
+func NewUnsupportedParameterError(param, provider, model string) *ModelError {
+	message := fmt.Sprintf("parameter %s is not supported by provider %s", param, provider)
+	return NewModelError(ErrorCodeUnsupportedParameter, message, provider, model).
+		WithDetails(map[string]interface{}{"parameter": param})
+}
+
+// NewCircuitOpenError creates an error for a call rejected by an open circuit breaker
+func NewCircuitOpenError(message, provider, model string) *ModelError {
+	return NewModelError(ErrorCodeCircuitOpen, message, provider, model)
+}
+
 // NewInternalError creates an internal error
 func NewInternalError(message, provider, model string) *ModelError {
 	return NewModelError(ErrorCodeInternalError, message, provider, model)
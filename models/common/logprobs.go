@@ -0,0 +1,106 @@
+package common
+
+// Log probabilities and sequence confidence
+//
+// Providers that return logprobs (request LogProbs, or logprobs in the model's
+// custom_params) fill PonchoModelResponse.LogProbs and PonchoStreamChunk.LogProbs.
+// The helpers below turn them into confidence signals in [0, 1]:
+//
+//	resp, _ := model.Generate(ctx, req)
+//	overall := common.SequenceConfidence(resp.LogProbs.Content)
+//	subject, ok := common.TextConfidence(resp.LogProbs, "1234") // a value inside JSON output
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// SequenceLogProb returns the total log probability of tokens
+func SequenceLogProb(tokens []interfaces.PonchoTokenLogProb) float64 {
+	total := 0.0
+	for _, token := range tokens {
+		total += token.LogProb
+	}
+	return total
+}
+
+// SequenceConfidence returns the geometric mean probability of tokens, the
+// exponent of their mean logprob. It does not shrink with the sequence length
+// like the joint probability does. Without tokens it is 0.
+func SequenceConfidence(tokens []interfaces.PonchoTokenLogProb) float64 {
+	if len(tokens) == 0 {
+		return 0
+	}
+	return math.Exp(SequenceLogProb(tokens) / float64(len(tokens)))
+}
+
+// MinTokenConfidence returns the probability of the least likely token, which
+// points at the single place the model was most unsure. Without tokens it is 0.
+func MinTokenConfidence(tokens []interfaces.PonchoTokenLogProb) float64 {
+	if len(tokens) == 0 {
+		return 0
+	}
+	lowest := tokens[0].LogProb
+	for _, token := range tokens[1:] {
+		lowest = math.Min(lowest, token.LogProb)
+	}
+	return math.Exp(lowest)
+}
+
+// TokensForText returns the tokens that generated the first occurrence of text
+// in the sequence, including tokens that only partly overlap it
+func TokensForText(tokens []interfaces.PonchoTokenLogProb, text string) []interfaces.PonchoTokenLogProb {
+	if text == "" {
+		return nil
+	}
+
+	var generated strings.Builder
+	offsets := make([]int, len(tokens))
+	for i, token := range tokens {
+		offsets[i] = generated.Len()
+		generated.WriteString(token.Token)
+	}
+
+	start := strings.Index(generated.String(), text)
+	if start < 0 {
+		return nil
+	}
+	end := start + len(text)
+
+	var span []interfaces.PonchoTokenLogProb
+	for i, token := range tokens {
+		if offsets[i] < end && offsets[i]+len(token.Token) > start {
+			span = append(span, token)
+		}
+	}
+	return span
+}
+
+// TextConfidence returns the confidence of the tokens that generated text in the
+// content, e.g. a classification label inside JSON output. ok is false when
+// there are no logprobs or the text was not generated.
+func TextConfidence(logProbs *interfaces.PonchoLogProbs, text string) (confidence float64, ok bool) {
+	if logProbs == nil {
+		return 0, false
+	}
+	span := TokensForText(logProbs.Content, text)
+	if len(span) == 0 {
+		return 0, false
+	}
+	return SequenceConfidence(span), true
+}
+
+// AppendLogProbs appends the logprobs of a stream chunk to those collected so far
+func AppendLogProbs(collected, chunk *interfaces.PonchoLogProbs) *interfaces.PonchoLogProbs {
+	if chunk == nil {
+		return collected
+	}
+	if collected == nil {
+		collected = &interfaces.PonchoLogProbs{}
+	}
+	collected.Content = append(collected.Content, chunk.Content...)
+	collected.Reasoning = append(collected.Reasoning, chunk.Reasoning...)
+	return collected
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// token returns a token generated with probability p
+func token(text string, p float64) interfaces.PonchoTokenLogProb {
+	return interfaces.PonchoTokenLogProb{Token: text, LogProb: math.Log(p)}
+}
+
+func TestSequenceConfidence(t *testing.T) {
+	tokens := []interfaces.PonchoTokenLogProb{token("a", 0.5), token("b", 0.5), token("c", 0.125)}
+
+	if got := SequenceLogProb(tokens); math.Abs(got-math.Log(0.03125)) > 1e-9 {
+		t.Errorf("SequenceLogProb() = %v, want log(0.03125)", got)
+	}
+	if got := SequenceConfidence(tokens); math.Abs(got-math.Cbrt(0.03125)) > 1e-9 {
+		t.Errorf("SequenceConfidence() = %v, want the geometric mean", got)
+	}
+	if got := MinTokenConfidence(tokens); math.Abs(got-0.125) > 1e-9 {
+		t.Errorf("MinTokenConfidence() = %v, want 0.125", got)
+	}
+	if SequenceConfidence(nil) != 0 || MinTokenConfidence(nil) != 0 {
+		t.Error("expected zero confidence without tokens")
+	}
+}
+
+func TestTextConfidence(t *testing.T) {
+	// {"subject_id": 1234} with the ID split over two tokens
+	tokens := []interfaces.PonchoTokenLogProb{
+		token(`{"subject_id": `, 0.99), token("12", 0.8), token("34", 0.2), token("}", 0.99),
+	}
+
+	span := TokensForText(tokens, "1234")
+	if len(span) != 2 || span[0].Token != "12" || span[1].Token != "34" {
+		t.Fatalf("TokensForText() = %+v, want the two ID tokens", span)
+	}
+	if span := TokensForText(tokens, ": 1"); len(span) != 2 || span[0].Token != `{"subject_id": ` {
+		t.Errorf("expected partly overlapping tokens to be included, got %+v", span)
+	}
+
+	confidence, ok := TextConfidence(&interfaces.PonchoLogProbs{Content: tokens}, "1234")
+	if !ok || math.Abs(confidence-0.4) > 1e-9 {
+		t.Errorf("TextConfidence() = %v, %v, want 0.4", confidence, ok)
+	}
+	if _, ok := TextConfidence(&interfaces.PonchoLogProbs{Content: tokens}, "5678"); ok {
+		t.Error("expected no confidence for text that was not generated")
+	}
+	if _, ok := TextConfidence(nil, "1234"); ok {
+		t.Error("expected no confidence without logprobs")
+	}
+}
+
+func TestAppendLogProbs(t *testing.T) {
+	if collected := AppendLogProbs(nil, nil); collected != nil {
+		t.Fatal("expected chunks without logprobs to add nothing")
+	}
+
+	collected := AppendLogProbs(nil, &interfaces.PonchoLogProbs{Content: []interfaces.PonchoTokenLogProb{token("Hel", 0.9)}})
+	collected = AppendLogProbs(collected, &interfaces.PonchoLogProbs{
+		Content:   []interfaces.PonchoTokenLogProb{token("lo", 0.8)},
+		Reasoning: []interfaces.PonchoTokenLogProb{token("Hmm", 0.7)},
+	})
+	if len(collected.Content) != 2 || collected.Content[1].Token != "lo" || len(collected.Reasoning) != 1 {
+		t.Errorf("unexpected collected logprobs: %+v", collected)
+	}
+}
@@ -136,7 +136,7 @@ func NewMetricsCollector(config *MetricsConfig, logger interfaces.Logger) *Metri
 	}
 
 	// Initialize provider metrics
-	for _, provider := range []Provider{ProviderDeepSeek, ProviderZAI, ProviderOpenAI} {
+	for _, provider := range []Provider{ProviderDeepSeek, ProviderZAI, ProviderOpenAI, ProviderOllama, ProviderAnthropic, ProviderGemini} {
 		collector.providerMetrics[provider] = &ProviderMetrics{
 			Provider: provider,
 		}
@@ -0,0 +1,358 @@
+package common
+
+// Per-request generation parameters
+//
+// PonchoModelRequest carries optional generation parameters: top_p, stop, seed,
+// penalties, response_format, thinking and logprobs. Providers check them with
+// CheckRequestParams, which rejects values out of range and the parameters the
+// provider's API cannot honor, and merge them over the model's configured defaults
+// with ResolveGenerationParams. A request never silently loses a setting it asked for.
+//
+// The defaults come from the model config; DeepSeek and Z.AI read them from
+// custom_params with GenerationDefaultsFromParams:
+//
+//	models:
+//	  deepseek-chat:
+//	    provider: deepseek
+//	    custom_params:
+//	      top_p: 0.9
+//	      response_format: json_object
+//	      thinking: false
+
+import (
+	"fmt"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// Generation parameter names, as used in requests, custom_params and errors
+const (
+	ParamTopP             = "top_p"
+	ParamStop             = "stop"
+	ParamSeed             = "seed"
+	ParamFrequencyPenalty = "frequency_penalty"
+	ParamPresencePenalty  = "presence_penalty"
+	ParamResponseFormat   = "response_format"
+	ParamThinking         = "thinking"
+	ParamLogProbs         = "logprobs"
+	ParamTopLogProbs      = "top_logprobs"
+)
+
+// MaxTopLogProbs is the largest number of alternatives per token providers return
+const MaxTopLogProbs = 20
+
+// GenerationParams are the generation parameters of a request after falling back
+// to the model's configured defaults
+type GenerationParams struct {
+	TopP             *float32
+	Stop             []string
+	Seed             *int
+	FrequencyPenalty *float32
+	PresencePenalty  *float32
+	ResponseFormat   *ResponseFormat
+	Thinking         *ThinkingType
+	LogProbs         bool
+	TopLogProbs      *int
+}
+
+// RequestParams returns the names of the generation parameters a request sets.
+// Thinking and logprobs count only when enabled: turning off a feature the
+// provider lacks needs no support.
+func RequestParams(req *interfaces.PonchoModelRequest) []string {
+	if req == nil {
+		return nil
+	}
+
+	var params []string
+	if req.TopP != nil {
+		params = append(params, ParamTopP)
+	}
+	if len(req.Stop) > 0 {
+		params = append(params, ParamStop)
+	}
+	if req.Seed != nil {
+		params = append(params, ParamSeed)
+	}
+	if req.FrequencyPenalty != nil {
+		params = append(params, ParamFrequencyPenalty)
+	}
+	if req.PresencePenalty != nil {
+		params = append(params, ParamPresencePenalty)
+	}
+	if req.ResponseFormat != "" {
+		params = append(params, ParamResponseFormat)
+	}
+	if req.Thinking != nil && *req.Thinking {
+		params = append(params, ParamThinking)
+	}
+	if req.LogProbs != nil && *req.LogProbs {
+		params = append(params, ParamLogProbs)
+	}
+	if req.TopLogProbs != nil {
+		params = append(params, ParamTopLogProbs)
+	}
+	return params
+}
+
+// CheckRequestParams validates the generation parameters of a request and rejects
+// those listed as unsupported by the provider. Invalid values yield an
+// INVALID_REQUEST error, unsupported parameters an UNSUPPORTED_PARAMETER error.
+func CheckRequestParams(req *interfaces.PonchoModelRequest, provider, model string, unsupported ...string) error {
+	if req == nil {
+		return nil
+	}
+
+	invalid := func(format string, args ...interface{}) error {
+		return NewModelError(ErrorCodeInvalidRequest, fmt.Sprintf(format, args...), provider, model)
+	}
+
+	if req.TopP != nil && (*req.TopP < 0 || *req.TopP > 1) {
+		return invalid("top_p must be between 0 and 1, got %v", *req.TopP)
+	}
+	for name, penalty := range map[string]*float32{
+		ParamFrequencyPenalty: req.FrequencyPenalty,
+		ParamPresencePenalty:  req.PresencePenalty,
+	} {
+		if penalty != nil && (*penalty < -2 || *penalty > 2) {
+			return invalid("%s must be between -2 and 2, got %v", name, *penalty)
+		}
+	}
+	for _, sequence := range req.Stop {
+		if sequence == "" {
+			return invalid("stop sequences must not be empty")
+		}
+	}
+	switch ResponseFormat(req.ResponseFormat) {
+	case "", ResponseFormatText, ResponseFormatJSONObject:
+	default:
+		return invalid("unsupported response_format: %s", req.ResponseFormat)
+	}
+	if req.TopLogProbs != nil {
+		if *req.TopLogProbs < 0 || *req.TopLogProbs > MaxTopLogProbs {
+			return invalid("top_logprobs must be between 0 and %d, got %d", MaxTopLogProbs, *req.TopLogProbs)
+		}
+		if req.LogProbs != nil && !*req.LogProbs {
+			return invalid("top_logprobs requires logprobs")
+		}
+	}
+
+	for _, param := range RequestParams(req) {
+		for _, name := range unsupported {
+			if param == name {
+				return NewUnsupportedParameterError(param, provider, model)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveGenerationParams merges the generation parameters of a request over the
+// model's configured defaults. A request asking for top_logprobs enables logprobs.
+func ResolveGenerationParams(req *interfaces.PonchoModelRequest, config *CommonModelConfig) (GenerationParams, error) {
+	if config == nil {
+		config = &CommonModelConfig{}
+	}
+
+	stop, err := StopSequences(config.Stop)
+	if err != nil {
+		return GenerationParams{}, err
+	}
+
+	params := GenerationParams{
+		TopP:             config.TopP,
+		Stop:             stop,
+		Seed:             config.Seed,
+		FrequencyPenalty: config.FrequencyPenalty,
+		PresencePenalty:  config.PresencePenalty,
+		ResponseFormat:   config.ResponseFormat,
+		Thinking:         config.Thinking,
+		LogProbs:         config.LogProbs,
+		TopLogProbs:      config.TopLogProbs,
+	}
+	if req == nil {
+		return params, nil
+	}
+
+	if req.TopP != nil {
+		params.TopP = req.TopP
+	}
+	if len(req.Stop) > 0 {
+		params.Stop = req.Stop
+	}
+	if req.Seed != nil {
+		params.Seed = req.Seed
+	}
+	if req.FrequencyPenalty != nil {
+		params.FrequencyPenalty = req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		params.PresencePenalty = req.PresencePenalty
+	}
+	if format, ok := RequestResponseFormat(req); ok {
+		params.ResponseFormat = &format
+	}
+	if req.Thinking != nil {
+		thinking := ThinkingDisabled
+		if *req.Thinking {
+			thinking = ThinkingEnabled
+		}
+		params.Thinking = &thinking
+	}
+	if req.LogProbs != nil {
+		params.LogProbs = *req.LogProbs
+	}
+	if req.TopLogProbs != nil {
+		params.TopLogProbs = req.TopLogProbs
+		if req.LogProbs == nil {
+			params.LogProbs = true
+		}
+	}
+	if !params.LogProbs {
+		params.TopLogProbs = nil
+	}
+
+	return params, nil
+}
+
+// StopSequences converts a "stop" setting (string or list) to stop sequences
+func StopSequences(value interface{}) ([]string, error) {
+	switch stop := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{stop}, nil
+	case []string:
+		return stop, nil
+	case []interface{}:
+		sequences := make([]string, 0, len(stop))
+		for _, item := range stop {
+			sequence, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("stop sequences must be strings")
+			}
+			sequences = append(sequences, sequence)
+		}
+		return sequences, nil
+	default:
+		return nil, fmt.Errorf("stop must be a string or a list of strings")
+	}
+}
+
+// GenerationDefaultsFromParams reads the default generation parameters of a model
+// from its custom_params. Parameters that are absent keep the config's values.
+func GenerationDefaultsFromParams(config *CommonModelConfig, params map[string]interface{}) error {
+	for key, target := range map[string]**float32{
+		ParamTopP:             &config.TopP,
+		ParamFrequencyPenalty: &config.FrequencyPenalty,
+		ParamPresencePenalty:  &config.PresencePenalty,
+	} {
+		if value, exists := params[key]; exists {
+			number, ok := ParamFloat(value)
+			if !ok {
+				return fmt.Errorf("%s must be a number, got %T", key, value)
+			}
+			*target = &number
+		}
+	}
+
+	for key, target := range map[string]**int{
+		ParamSeed:        &config.Seed,
+		ParamTopLogProbs: &config.TopLogProbs,
+	} {
+		if value, exists := params[key]; exists {
+			number, ok := paramInt(value)
+			if !ok {
+				return fmt.Errorf("%s must be a whole number, got %v", key, value)
+			}
+			*target = &number
+		}
+	}
+
+	if stop, exists := params[ParamStop]; exists {
+		if _, err := StopSequences(stop); err != nil {
+			return err
+		}
+		config.Stop = stop
+	}
+
+	if value, exists := params[ParamResponseFormat]; exists {
+		formatType, _ := value.(string)
+		if formatMap, isMap := value.(map[string]interface{}); isMap {
+			formatType, _ = formatMap["type"].(string)
+		}
+		switch format := ResponseFormat(formatType); format {
+		case ResponseFormatText, ResponseFormatJSONObject:
+			config.ResponseFormat = &format
+		default:
+			return fmt.Errorf("unsupported response_format: %v", value)
+		}
+	}
+
+	if value, exists := params[ParamThinking]; exists {
+		thinking, err := parseThinking(value)
+		if err != nil {
+			return err
+		}
+		config.Thinking = &thinking
+	}
+
+	if value, exists := params[ParamLogProbs]; exists {
+		logProbs, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("logprobs must be a boolean")
+		}
+		config.LogProbs = logProbs
+	}
+
+	return nil
+}
+
+// parseThinking accepts true/false, "enabled"/"disabled" or {"type": "enabled"}
+func parseThinking(value interface{}) (ThinkingType, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return ThinkingEnabled, nil
+		}
+		return ThinkingDisabled, nil
+	case map[string]interface{}:
+		return parseThinking(v["type"])
+	case string:
+		switch thinking := ThinkingType(v); thinking {
+		case ThinkingEnabled, ThinkingDisabled:
+			return thinking, nil
+		}
+	}
+	return "", fmt.Errorf("thinking must be a boolean, \"enabled\" or \"disabled\", got %v", value)
+}
+
+// ParamFloat converts numeric parameter and config values to float32
+func ParamFloat(value interface{}) (float32, bool) {
+	switch v := value.(type) {
+	case float32:
+		return v, true
+	case float64:
+		return float32(v), true
+	case int:
+		return float32(v), true
+	default:
+		return 0, false
+	}
+}
+
+// paramInt converts whole numeric parameter values to int; YAML and JSON yield float64
+func paramInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
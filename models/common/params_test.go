@@ -0,0 +1,101 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+func TestCheckRequestParams(t *testing.T) {
+	topP := float32(0.9)
+	seed := 42
+	logProbs := true
+	req := &interfaces.PonchoModelRequest{TopP: &topP, Seed: &seed, LogProbs: &logProbs}
+
+	if err := CheckRequestParams(req, "zai", "glm-4.6"); err != nil {
+		t.Fatalf("CheckRequestParams() error = %v", err)
+	}
+
+	err := CheckRequestParams(req, "zai", "glm-4.6", ParamThinking, ParamSeed)
+	modelErr, ok := IsModelError(err)
+	if !ok || modelErr.Code != ErrorCodeUnsupportedParameter || modelErr.Retryable {
+		t.Fatalf("CheckRequestParams() error = %v, want a non-retryable UNSUPPORTED_PARAMETER", err)
+	}
+	if details, _ := modelErr.Details.(map[string]interface{}); details["parameter"] != ParamSeed {
+		t.Errorf("Details = %v, want the seed parameter", modelErr.Details)
+	}
+
+	// Disabling a feature needs no support
+	disabled := false
+	if err := CheckRequestParams(&interfaces.PonchoModelRequest{Thinking: &disabled}, "openai", "gpt-4o", ParamThinking); err != nil {
+		t.Errorf("CheckRequestParams() error = %v, want thinking=false accepted", err)
+	}
+
+	tooHigh := float32(2.5)
+	topLogProbs := 30
+	for _, invalid := range []*interfaces.PonchoModelRequest{
+		{TopP: &tooHigh},
+		{PresencePenalty: &tooHigh},
+		{ResponseFormat: "yaml"},
+		{Stop: []string{""}},
+		{TopLogProbs: &topLogProbs},
+		{TopLogProbs: &seed, LogProbs: &disabled},
+	} {
+		if code := GetErrorCode(CheckRequestParams(invalid, "zai", "glm-4.6")); code != ErrorCodeInvalidRequest {
+			t.Errorf("CheckRequestParams(%+v) code = %s, want INVALID_REQUEST", invalid, code)
+		}
+	}
+}
+
+func TestResolveGenerationParams(t *testing.T) {
+	config := &CommonModelConfig{}
+	if err := GenerationDefaultsFromParams(config, map[string]interface{}{
+		"top_p":           0.8,
+		"stop":            []interface{}{"END"},
+		"response_format": map[string]interface{}{"type": "json_object"},
+		"thinking":        map[string]interface{}{"type": "enabled"},
+		"seed":            float64(7),
+	}); err != nil {
+		t.Fatalf("GenerationDefaultsFromParams() error = %v", err)
+	}
+
+	params, err := ResolveGenerationParams(&interfaces.PonchoModelRequest{}, config)
+	if err != nil {
+		t.Fatalf("ResolveGenerationParams() error = %v", err)
+	}
+	if *params.TopP != 0.8 || params.Stop[0] != "END" || *params.ResponseFormat != ResponseFormatJSONObject || *params.Thinking != ThinkingEnabled || *params.Seed != 7 {
+		t.Errorf("unexpected defaults: %+v", params)
+	}
+
+	// Request values win
+	thinking := false
+	topLogProbs := 3
+	params, err = ResolveGenerationParams(&interfaces.PonchoModelRequest{
+		Stop:           []string{"STOP"},
+		Thinking:       &thinking,
+		TopLogProbs:    &topLogProbs,
+		ResponseFormat: interfaces.PonchoResponseFormatText,
+	}, config)
+	if err != nil {
+		t.Fatalf("ResolveGenerationParams() error = %v", err)
+	}
+	if params.Stop[0] != "STOP" || *params.Thinking != ThinkingDisabled || *params.ResponseFormat != ResponseFormatText {
+		t.Errorf("unexpected overrides: %+v", params)
+	}
+	if !params.LogProbs || *params.TopLogProbs != 3 {
+		t.Errorf("expected top_logprobs to enable logprobs, got %+v", params)
+	}
+
+	for _, invalid := range []map[string]interface{}{
+		{"top_p": "high"},
+		{"seed": 1.5},
+		{"stop": 3},
+		{"response_format": "yaml"},
+		{"thinking": "sometimes"},
+		{"logprobs": "yes"},
+	} {
+		if err := GenerationDefaultsFromParams(&CommonModelConfig{}, invalid); err == nil {
+			t.Errorf("expected an error for %v", invalid)
+		}
+	}
+}
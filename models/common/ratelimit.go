@@ -0,0 +1,601 @@
+package common
+
+// Client-side rate limiting driven by provider rate-limit headers
+//
+// A RateLimiter is a requests-per-minute and tokens-per-minute budget shared by all
+// clients of a provider account, that is a provider, base URL and API key (see
+// ConfiguredRateLimiter). HTTPClient waits on it before every attempt, so concurrent
+// callers queue for their turn instead of running into 429s, and feeds it the rate
+// limit headers of every response:
+//   - limits reported by the provider apply where the config sets none
+//   - the reported remaining budget caps the local one
+//   - Retry-After, or an exhausted budget with a reset time, pauses all callers
+//
+// Requests carry their estimated token count in the context (WithRateLimitTokens);
+// requests without one only count against the requests budget.
+//
+// Limits are off until a model sets them in its custom_params (requests_per_minute,
+// tokens_per_minute) or the provider reports them. Configured limits hold while the
+// model's client is open and win over reported ones; an explicit 0 keeps a limit
+// off. When models of an account configure different limits, the strictest applies.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// ParseRateLimitHeaders reads the rate limit headers of a response:
+// x-ratelimit-{limit,remaining,reset}-{requests,tokens}, plain
+// x-ratelimit-{limit,remaining,reset} (counted as requests),
+// anthropic-ratelimit-{requests,tokens}-{limit,remaining,reset} and Retry-After.
+// Limits are taken as per minute. Reset values may be durations ("6m0s"),
+// seconds, Unix timestamps or RFC 3339 times. It returns false when the
+// response carries none of them.
+func ParseRateLimitHeaders(header http.Header, now time.Time) (*RateLimitInfo, bool) {
+	info := &RateLimitInfo{}
+	found := false
+
+	if limit, ok := headerInt(header, "X-Ratelimit-Limit-Requests", "X-Ratelimit-Limit", "Anthropic-Ratelimit-Requests-Limit"); ok {
+		info.RequestsPerMinute = limit
+		found = true
+	}
+	if limit, ok := headerInt(header, "X-Ratelimit-Limit-Tokens", "Anthropic-Ratelimit-Tokens-Limit"); ok {
+		info.TokensPerMinute = limit
+		found = true
+	}
+	if remaining, ok := headerInt(header, "X-Ratelimit-Remaining-Requests", "X-Ratelimit-Remaining", "Anthropic-Ratelimit-Requests-Remaining"); ok {
+		info.RemainingRequests = &remaining
+		found = true
+	}
+	if remaining, ok := headerInt(header, "X-Ratelimit-Remaining-Tokens", "Anthropic-Ratelimit-Tokens-Remaining"); ok {
+		info.RemainingTokens = &remaining
+		found = true
+	}
+
+	requestsReset, hasRequestsReset := headerReset(header, now, "X-Ratelimit-Reset-Requests", "X-Ratelimit-Reset", "Anthropic-Ratelimit-Requests-Reset")
+	tokensReset, hasTokensReset := headerReset(header, now, "X-Ratelimit-Reset-Tokens", "Anthropic-Ratelimit-Tokens-Reset")
+
+	// Callers wait for every exhausted budget; otherwise the first reset is reported
+	var reset time.Time
+	requestsExhausted := info.RemainingRequests != nil && *info.RemainingRequests <= 0
+	tokensExhausted := info.RemainingTokens != nil && *info.RemainingTokens <= 0
+	switch {
+	case requestsExhausted || tokensExhausted:
+		if requestsExhausted && hasRequestsReset {
+			reset = requestsReset
+		}
+		if tokensExhausted && hasTokensReset && tokensReset.After(reset) {
+			reset = tokensReset
+		}
+	case hasRequestsReset && (!hasTokensReset || requestsReset.Before(tokensReset)):
+		reset = requestsReset
+	case hasTokensReset:
+		reset = tokensReset
+	}
+	if !reset.IsZero() {
+		info.ResetTime = &reset
+		found = true
+	}
+
+	if retryAfter, ok := RetryAfterFromHeader(header, now); ok {
+		info.RetryAfter = &retryAfter
+		found = true
+	}
+
+	return info, found
+}
+
+// headerInt returns the first of names set to a non-negative integer
+func headerInt(header http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// headerReset returns the reset time in the first of names that parses
+func headerReset(header http.Header, now time.Time, names ...string) (time.Time, bool) {
+	for _, name := range names {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		if reset, ok := parseResetValue(value, now); ok {
+			return reset, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseResetValue parses a reset value: a duration, seconds from now,
+// a Unix timestamp or an RFC 3339 time
+func parseResetValue(value string, now time.Time) (time.Time, bool) {
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(d), true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		// Values this large are timestamps, not delays
+		if seconds > 1e9 {
+			return time.Unix(0, int64(seconds*float64(time.Second))), true
+		}
+		return now.Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// rateLimitTokensKey carries the estimated tokens of a request
+type rateLimitTokensKey struct{}
+
+// WithRateLimitTokens returns a context whose requests are charged tokens
+// against the tokens-per-minute budget of the rate limiter
+func WithRateLimitTokens(ctx context.Context, tokens int) context.Context {
+	return context.WithValue(ctx, rateLimitTokensKey{}, tokens)
+}
+
+// rateLimitTokens returns the tokens set with WithRateLimitTokens
+func rateLimitTokens(ctx context.Context) int {
+	tokens, _ := ctx.Value(rateLimitTokensKey{}).(int)
+	return tokens
+}
+
+// EstimateRateLimitTokens estimates the prompt and completion tokens of a request
+// for the rate limiter, or 0 when the model cannot be counted
+func EstimateRateLimitTokens(tokenizer *Tokenizer, req *interfaces.PonchoModelRequest, provider Provider, model string) int {
+	if tokenizer == nil || req == nil {
+		return 0
+	}
+	usage, err := tokenizer.CountRequestTokens(req, provider, model)
+	if err != nil {
+		return 0
+	}
+	return usage.TotalTokens
+}
+
+// RateLimiter queues callers to stay within a provider's requests and tokens per
+// minute. Each caller reserves its share of the budget at once and waits until the
+// budget has refilled, so callers go out in arrival order.
+type RateLimiter struct {
+	mutex             sync.Mutex
+	requestsPerMinute int     // enforced limits, 0 = unlimited
+	tokensPerMinute   int     // 0 = unlimited
+	requests          float64 // available budget, negative while callers wait
+	tokens            float64
+	updated           time.Time
+	blockedUntil      time.Time
+
+	// Limits reported by the provider, used where no client configured one
+	reportedRequestsPerMinute int
+	reportedTokensPerMinute   int
+	claims                    map[*rateLimitClaim]struct{}
+
+	waits    int64
+	waitTime time.Duration
+}
+
+// rateLimitClaim holds the limits a client configured; nil leaves a limit to the provider
+type rateLimitClaim struct {
+	requestsPerMinute *int
+	tokensPerMinute   *int
+}
+
+// RateLimiterStats counts the callers a rate limiter made wait
+type RateLimiterStats struct {
+	Waits    int64         `json:"waits"`
+	WaitTime time.Duration `json:"wait_time"`
+}
+
+// NewRateLimiter creates a rate limiter with full budgets. A zero limit is unlimited.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	return &RateLimiter{
+		requestsPerMinute:         max(requestsPerMinute, 0),
+		tokensPerMinute:           max(tokensPerMinute, 0),
+		requests:                  float64(max(requestsPerMinute, 0)),
+		tokens:                    float64(max(tokensPerMinute, 0)),
+		updated:                   time.Now(),
+		reportedRequestsPerMinute: max(requestsPerMinute, 0),
+		reportedTokensPerMinute:   max(tokensPerMinute, 0),
+	}
+}
+
+// SetLimits changes the per-minute limits used where no client configured one,
+// as if reported by the provider. A zero limit is unlimited.
+func (l *RateLimiter) SetLimits(requestsPerMinute, tokensPerMinute int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.refill(time.Now())
+	l.reportedRequestsPerMinute, l.reportedTokensPerMinute = max(requestsPerMinute, 0), max(tokensPerMinute, 0)
+	l.applyLimits()
+}
+
+// Limits returns the requests and tokens per minute
+func (l *RateLimiter) Limits() (requestsPerMinute, tokensPerMinute int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.requestsPerMinute, l.tokensPerMinute
+}
+
+// Wait blocks until a request of the given tokens fits the budget or ctx is done.
+// A request larger than the tokens per minute waits for a full budget.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	delay, charged := l.reserve(tokens)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.release(charged)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Update adapts the limiter to the rate limit state reported by the provider
+func (l *RateLimiter) Update(info *RateLimitInfo) {
+	if info == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	if info.RequestsPerMinute > 0 {
+		l.reportedRequestsPerMinute = info.RequestsPerMinute
+	}
+	if info.TokensPerMinute > 0 {
+		l.reportedTokensPerMinute = info.TokensPerMinute
+	}
+	l.applyLimits()
+
+	exhausted := false
+	if info.RemainingRequests != nil && l.requestsPerMinute > 0 {
+		l.requests = math.Min(l.requests, float64(*info.RemainingRequests))
+		exhausted = *info.RemainingRequests <= 0
+	}
+	if info.RemainingTokens != nil && l.tokensPerMinute > 0 {
+		l.tokens = math.Min(l.tokens, float64(*info.RemainingTokens))
+		exhausted = exhausted || *info.RemainingTokens <= 0
+	}
+
+	if info.RetryAfter != nil {
+		l.block(now.Add(*info.RetryAfter))
+	}
+	if exhausted && info.ResetTime != nil {
+		l.block(*info.ResetTime)
+	}
+}
+
+// Info returns the limits and the budget left right now
+func (l *RateLimiter) Info() *RateLimitInfo {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	info := &RateLimitInfo{
+		RequestsPerMinute: l.requestsPerMinute,
+		TokensPerMinute:   l.tokensPerMinute,
+	}
+	if l.requestsPerMinute > 0 {
+		remaining := int(math.Max(0, math.Floor(l.requests)))
+		info.RemainingRequests = &remaining
+	}
+	if l.tokensPerMinute > 0 {
+		remaining := int(math.Max(0, math.Floor(l.tokens)))
+		info.RemainingTokens = &remaining
+	}
+	if l.blockedUntil.After(now) {
+		retryAfter := l.blockedUntil.Sub(now)
+		reset := l.blockedUntil
+		info.RetryAfter = &retryAfter
+		info.ResetTime = &reset
+	}
+	return info
+}
+
+// Stats returns how many callers waited and for how long in total
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return RateLimiterStats{Waits: l.waits, WaitTime: l.waitTime}
+}
+
+// reserve takes a request and tokens from the budget, returning how long the caller
+// must wait for them and the tokens charged
+func (l *RateLimiter) reserve(tokens int) (time.Duration, int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	var delay time.Duration
+	if l.requestsPerMinute > 0 {
+		l.requests--
+		delay = max(delay, deficitDelay(l.requests, l.requestsPerMinute))
+	}
+
+	charged := 0
+	if l.tokensPerMinute > 0 && tokens > 0 {
+		charged = min(tokens, l.tokensPerMinute)
+		l.tokens -= float64(charged)
+		delay = max(delay, deficitDelay(l.tokens, l.tokensPerMinute))
+	}
+
+	delay = max(delay, l.blockedUntil.Sub(now))
+	if delay > 0 {
+		l.waits++
+		l.waitTime += delay
+	}
+	return delay, charged
+}
+
+// release returns the reservation of a caller that gave up waiting
+func (l *RateLimiter) release(tokens int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.refill(time.Now())
+	if l.requestsPerMinute > 0 {
+		l.requests = math.Min(l.requests+1, float64(l.requestsPerMinute))
+	}
+	if l.tokensPerMinute > 0 {
+		l.tokens = math.Min(l.tokens+float64(tokens), float64(l.tokensPerMinute))
+	}
+}
+
+// refill adds the budget earned since the last update
+func (l *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.updated).Minutes()
+	if elapsed <= 0 {
+		return
+	}
+	l.updated = now
+
+	if l.requestsPerMinute > 0 {
+		l.requests = math.Min(l.requests+elapsed*float64(l.requestsPerMinute), float64(l.requestsPerMinute))
+	}
+	if l.tokensPerMinute > 0 {
+		l.tokens = math.Min(l.tokens+elapsed*float64(l.tokensPerMinute), float64(l.tokensPerMinute))
+	}
+}
+
+// claim applies limits configured by a client until unclaimed
+func (l *RateLimiter) claim(requestsPerMinute, tokensPerMinute *int) *rateLimitClaim {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	claim := &rateLimitClaim{requestsPerMinute: requestsPerMinute, tokensPerMinute: tokensPerMinute}
+	if l.claims == nil {
+		l.claims = make(map[*rateLimitClaim]struct{})
+	}
+	l.claims[claim] = struct{}{}
+
+	l.refill(time.Now())
+	l.applyLimits()
+	return claim
+}
+
+// unclaim withdraws the limits of a client, returning how many clients are left
+func (l *RateLimiter) unclaim(claim *rateLimitClaim) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.claims, claim)
+	l.refill(time.Now())
+	l.applyLimits()
+	return len(l.claims)
+}
+
+// applyLimits enforces the configured limits, falling back to the reported ones
+func (l *RateLimiter) applyLimits() {
+	requestsPerMinute, tokensPerMinute := l.reportedRequestsPerMinute, l.reportedTokensPerMinute
+	if limit, ok := l.configuredLimit(func(c *rateLimitClaim) *int { return c.requestsPerMinute }); ok {
+		requestsPerMinute = limit
+	}
+	if limit, ok := l.configuredLimit(func(c *rateLimitClaim) *int { return c.tokensPerMinute }); ok {
+		tokensPerMinute = limit
+	}
+	l.setLimits(requestsPerMinute, tokensPerMinute)
+}
+
+// configuredLimit returns the strictest limit configured by the clients; a limit
+// configured as 0 only applies when no client set a positive one
+func (l *RateLimiter) configuredLimit(limit func(*rateLimitClaim) *int) (int, bool) {
+	strictest, configured := 0, false
+	for claim := range l.claims {
+		value := limit(claim)
+		if value == nil {
+			continue
+		}
+		configured = true
+		if *value > 0 && (strictest == 0 || *value < strictest) {
+			strictest = *value
+		}
+	}
+	return strictest, configured
+}
+
+// setLimits changes the limits; a newly limited budget starts full
+func (l *RateLimiter) setLimits(requestsPerMinute, tokensPerMinute int) {
+	requestsPerMinute, tokensPerMinute = max(requestsPerMinute, 0), max(tokensPerMinute, 0)
+
+	if l.requestsPerMinute == 0 {
+		l.requests = float64(requestsPerMinute)
+	}
+	l.requestsPerMinute = requestsPerMinute
+	l.requests = math.Min(l.requests, float64(requestsPerMinute))
+
+	if l.tokensPerMinute == 0 {
+		l.tokens = float64(tokensPerMinute)
+	}
+	l.tokensPerMinute = tokensPerMinute
+	l.tokens = math.Min(l.tokens, float64(tokensPerMinute))
+}
+
+// block pauses all callers until the given time
+func (l *RateLimiter) block(until time.Time) {
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// deficitDelay returns how long a negative budget takes to refill to zero
+func deficitDelay(available float64, perMinute int) time.Duration {
+	if available >= 0 {
+		return 0
+	}
+	return time.Duration(-available / float64(perMinute) * float64(time.Minute))
+}
+
+var (
+	rateLimitersMutex sync.RWMutex
+	rateLimiters      = make(map[string]*RateLimiter)
+)
+
+// RateLimiterName names the rate limiter of a provider account, for example
+// "deepseek@api.deepseek.com#1a2b3c4d". The API key is only included as a hash.
+func RateLimiterName(provider Provider, baseURL, apiKey string) string {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host + strings.TrimSuffix(u.Path, "/")
+	}
+
+	name := string(provider) + "@" + host
+	if apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		name += "#" + hex.EncodeToString(sum[:4])
+	}
+	return name
+}
+
+// SharedRateLimiter returns the rate limiter registered under name, creating it with
+// the given limits. The limits of an existing limiter are kept.
+func SharedRateLimiter(name string, requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	rateLimitersMutex.Lock()
+	defer rateLimitersMutex.Unlock()
+
+	return sharedRateLimiter(name, requestsPerMinute, tokensPerMinute)
+}
+
+// sharedRateLimiter is SharedRateLimiter with rateLimitersMutex held
+func sharedRateLimiter(name string, requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	limiter, exists := rateLimiters[name]
+	if !exists {
+		limiter = NewRateLimiter(requestsPerMinute, tokensPerMinute)
+		rateLimiters[name] = limiter
+	}
+	return limiter
+}
+
+// ConfiguredRateLimiter returns the rate limiter shared by the clients of a provider
+// account and applies the limits set in config. A new limiter starts unlimited.
+// Call release when the client closes: it withdraws the configured limits, and
+// unregisters the limiter once no client uses it.
+func ConfiguredRateLimiter(config *CommonModelConfig, baseURL, apiKey string) (limiter *RateLimiter, release func()) {
+	name := RateLimiterName(config.Provider, baseURL, apiKey)
+
+	// Claim under the registry lock, so a release cannot unregister the limiter in between
+	rateLimitersMutex.Lock()
+	limiter = sharedRateLimiter(name, 0, 0)
+	claim := limiter.claim(config.RequestsPerMinute, config.TokensPerMinute)
+	rateLimitersMutex.Unlock()
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			rateLimitersMutex.Lock()
+			defer rateLimitersMutex.Unlock()
+
+			if limiter.unclaim(claim) == 0 && rateLimiters[name] == limiter {
+				delete(rateLimiters, name)
+			}
+		})
+	}
+	return limiter, release
+}
+
+// RateLimiters returns the shared rate limiters by name
+func RateLimiters() map[string]*RateLimiter {
+	rateLimitersMutex.RLock()
+	defer rateLimitersMutex.RUnlock()
+
+	limiters := make(map[string]*RateLimiter, len(rateLimiters))
+	for name, limiter := range rateLimiters {
+		limiters[name] = limiter
+	}
+	return limiters
+}
+
+// UnregisterRateLimiter removes a shared rate limiter
+func UnregisterRateLimiter(name string) {
+	rateLimitersMutex.Lock()
+	defer rateLimitersMutex.Unlock()
+
+	delete(rateLimiters, name)
+}
+
+// RateLimitFromParams reads "requests_per_minute" and "tokens_per_minute"
+// from a model's custom_params into config
+func RateLimitFromParams(config *CommonModelConfig, params map[string]interface{}) error {
+	for key, target := range map[string]**int{
+		"requests_per_minute": &config.RequestsPerMinute,
+		"tokens_per_minute":   &config.TokensPerMinute,
+	} {
+		value, exists := params[key]
+		if !exists {
+			continue
+		}
+
+		var n int
+		switch v := value.(type) {
+		case int:
+			n = v
+		case int64:
+			n = int(v)
+		case float64:
+			n = int(v)
+			if float64(n) != v {
+				return fmt.Errorf("%s must be a whole number, got %v", key, v)
+			}
+		default:
+			return fmt.Errorf("%s must be a number, got %T", key, value)
+		}
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", key, n)
+		}
+		*target = &n
+	}
+	return nil
+}
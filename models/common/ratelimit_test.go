@@ -0,0 +1,238 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	header.Set("X-Ratelimit-Limit-Requests", "500")
+	header.Set("X-Ratelimit-Limit-Tokens", "30000")
+	header.Set("X-Ratelimit-Remaining-Requests", "499")
+	header.Set("X-Ratelimit-Remaining-Tokens", "0")
+	header.Set("X-Ratelimit-Reset-Requests", "120ms")
+	header.Set("X-Ratelimit-Reset-Tokens", "6m0s")
+
+	info, ok := ParseRateLimitHeaders(header, now)
+	if !ok {
+		t.Fatal("expected rate limit headers to be found")
+	}
+	if info.RequestsPerMinute != 500 || info.TokensPerMinute != 30000 || *info.RemainingRequests != 499 || *info.RemainingTokens != 0 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if info.ResetTime == nil || !info.ResetTime.Equal(now.Add(6*time.Minute)) {
+		t.Errorf("ResetTime = %v, want the reset of the exhausted tokens budget", info.ResetTime)
+	}
+
+	header = http.Header{}
+	header.Set("Anthropic-Ratelimit-Requests-Limit", "50")
+	header.Set("Anthropic-Ratelimit-Requests-Remaining", "0")
+	header.Set("Anthropic-Ratelimit-Requests-Reset", "2025-03-01T12:00:30Z")
+	header.Set("Retry-After", "30")
+
+	info, ok = ParseRateLimitHeaders(header, now)
+	if !ok || info.RequestsPerMinute != 50 || info.ResetTime == nil || !info.ResetTime.Equal(now.Add(30*time.Second)) {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if info.RetryAfter == nil || *info.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", info.RetryAfter)
+	}
+
+	header = http.Header{}
+	header.Set("X-Ratelimit-Remaining", "0")
+	header.Set("X-Ratelimit-Reset", "1740830415")
+	if info, ok := ParseRateLimitHeaders(header, now); !ok || info.ResetTime == nil || !info.ResetTime.Equal(now.Add(15*time.Second)) {
+		t.Errorf("expected a Unix timestamp reset, got %+v", info)
+	}
+
+	if _, ok := ParseRateLimitHeaders(http.Header{"Content-Type": []string{MIMETypeJSON}}, now); ok {
+		t.Error("expected no rate limit info without rate limit headers")
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	// 6000 requests per minute refill one request every 10ms
+	limiter := NewRateLimiter(6000, 0)
+	zero := 0
+	limiter.Update(&RateLimitInfo{RemainingRequests: &zero})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), 1000); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("3 requests took %s, want them spread over ~30ms", elapsed)
+	}
+	if stats := limiter.Stats(); stats.Waits != 3 || stats.WaitTime <= 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// A caller that gives up returns its reservation
+	limiter = NewRateLimiter(1, 0)
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want the deadline", err)
+	}
+	if remaining := *limiter.Info().RemainingRequests; remaining != 0 {
+		t.Errorf("RemainingRequests = %d, want the cancelled reservation returned", remaining)
+	}
+}
+
+func TestRateLimiter_Tokens(t *testing.T) {
+	// 60000 tokens per minute refill 1000 tokens a second
+	limiter := NewRateLimiter(0, 60000)
+
+	if err := limiter.Wait(context.Background(), 100000); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if info := limiter.Info(); info.RemainingRequests != nil || *info.RemainingTokens != 0 {
+		t.Errorf("unexpected info after draining the tokens budget: %+v", info)
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background(), 20); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("waited %s for 20 tokens, want ~20ms", elapsed)
+	}
+}
+
+func TestRateLimiter_Update(t *testing.T) {
+	limiter := NewRateLimiter(60, 0)
+
+	retryAfter := 30 * time.Millisecond
+	limiter.Update(&RateLimitInfo{RequestsPerMinute: 600, TokensPerMinute: 1000, RetryAfter: &retryAfter})
+
+	info := limiter.Info()
+	if info.RequestsPerMinute != 600 || info.TokensPerMinute != 1000 || *info.RemainingTokens != 1000 {
+		t.Errorf("unexpected limits: %+v", info)
+	}
+	if info.RetryAfter == nil || *info.RetryAfter <= 0 {
+		t.Fatalf("expected the Retry-After pause to be reported, got %+v", info)
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("waited %s, want the 30ms Retry-After honored", elapsed)
+	}
+}
+
+func TestHTTPClient_RateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit-Requests", "1200")
+		w.Header().Set("X-Ratelimit-Remaining-Requests", "0")
+		w.Header().Set("X-Ratelimit-Reset-Requests", "40ms")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(&DefaultHTTPConfig, RetryConfig{MaxAttempts: 1})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	limiter := NewRateLimiter(60, 0)
+	client.SetRateLimiter(limiter)
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The exhausted budget reported by the first response holds back the second
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("2 requests took %s, want the second to wait for the reset", elapsed)
+	}
+	if requestsPerMinute, _ := limiter.Limits(); requestsPerMinute != 1200 {
+		t.Errorf("RequestsPerMinute = %d, want the reported 1200", requestsPerMinute)
+	}
+}
+
+func TestConfiguredRateLimiter(t *testing.T) {
+	config := &CommonModelConfig{Provider: ProviderCustom}
+	if err := RateLimitFromParams(config, map[string]interface{}{"requests_per_minute": float64(30)}); err != nil {
+		t.Fatalf("RateLimitFromParams() error = %v", err)
+	}
+
+	limiter, release := ConfiguredRateLimiter(config, "https://llm.example.com/v1", "key-a")
+	if requestsPerMinute, tokensPerMinute := limiter.Limits(); requestsPerMinute != 30 || tokensPerMinute != 0 {
+		t.Errorf("Limits() = %d, %d, want the configured 30 and no token limit", requestsPerMinute, tokensPerMinute)
+	}
+	name := RateLimiterName(ProviderCustom, "https://llm.example.com/v1", "key-a")
+	if _, exists := RateLimiters()[name]; !exists || strings.Contains(name, "key-a") {
+		t.Errorf("expected the limiter to be listed under a name hiding the key, got %q", name)
+	}
+
+	// Clients of the same account share the limiter, the strictest configured limit applies
+	twenty := 20
+	stricter := &CommonModelConfig{Provider: ProviderCustom, RequestsPerMinute: &twenty}
+	other, releaseOther := ConfiguredRateLimiter(stricter, "https://llm.example.com/v1", "key-a")
+	if requestsPerMinute, _ := limiter.Limits(); other != limiter || requestsPerMinute != 20 {
+		t.Errorf("expected a shared limiter at 20 requests, got %d", requestsPerMinute)
+	}
+	if separate, releaseSeparate := ConfiguredRateLimiter(config, "https://llm.example.com/v1", "key-b"); separate == limiter {
+		t.Error("expected another API key to get its own limiter")
+	} else {
+		releaseSeparate()
+	}
+
+	// Closing a client withdraws its limits, the last one unregisters the limiter
+	releaseOther()
+	if requestsPerMinute, _ := limiter.Limits(); requestsPerMinute != 30 {
+		t.Errorf("RequestsPerMinute = %d after release, want 30", requestsPerMinute)
+	}
+	release()
+	release()
+	if requestsPerMinute, _ := limiter.Limits(); requestsPerMinute != 0 {
+		t.Errorf("RequestsPerMinute = %d after releasing all clients, want unlimited", requestsPerMinute)
+	}
+	if _, exists := RateLimiters()[name]; exists {
+		t.Error("expected an unused limiter to be unregistered")
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"requests_per_minute": "many"},
+		{"tokens_per_minute": -1},
+		{"tokens_per_minute": 1.5},
+	} {
+		if err := RateLimitFromParams(&CommonModelConfig{}, params); err == nil {
+			t.Errorf("expected an error for %v", params)
+		}
+	}
+}
+
+func TestConfiguredRateLimiter_ExplicitZero(t *testing.T) {
+	zero := 0
+	config := &CommonModelConfig{Provider: ProviderCustom, RequestsPerMinute: &zero}
+	limiter, release := ConfiguredRateLimiter(config, "https://zero.example.com", "")
+	defer release()
+
+	remaining := 5
+	limiter.Update(&RateLimitInfo{RequestsPerMinute: 100, TokensPerMinute: 5000, RemainingRequests: &remaining})
+
+	// The configured 0 keeps the requests limit off, the unconfigured tokens limit is taken
+	if requestsPerMinute, tokensPerMinute := limiter.Limits(); requestsPerMinute != 0 || tokensPerMinute != 5000 {
+		t.Errorf("Limits() = %d, %d, want 0 and the reported 5000", requestsPerMinute, tokensPerMinute)
+	}
+}
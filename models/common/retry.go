@@ -15,12 +15,16 @@
 // - Exponential: delay = 2^(attempt-1) * base_delay
 // - Fixed: delay = base_delay (constant)
 // - Jitter: ±25% random variation applied to delays
+// - Retry-After: a delay requested by the provider (ModelError.RetryAfter) replaces
+//   a shorter computed delay; a request to wait longer than max_delay ends retries
 //
 // Circuit Breaker Pattern:
 // - Closed: Normal operation, requests pass through
 // - Open: All requests fail immediately after threshold
-// - Half-Open: Limited requests test recovery
+// - Half-Open: a single trial request tests recovery
 // - Automatic reset after timeout period
+// - Only retryable errors count as failures; invalid requests and caller
+//   cancellations leave the circuit alone
 //
 // Usage Examples:
 //   executor := NewRetryExecutor(retryConfig, logger)
@@ -41,9 +45,11 @@ package common
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
@@ -62,10 +68,10 @@ type RetryState struct {
 
 // RetryExecutor handles retry logic for model operations
 type RetryExecutor struct {
-	config      RetryConfig
-	logger      interfaces.Logger
+	config       RetryConfig
+	logger       interfaces.Logger
 	errorHandler *ErrorHandler
-	randSource  rand.Source
+	onRetry      func(attempt int, delay time.Duration, err error)
 }
 
 // NewRetryExecutor creates a new retry executor
@@ -80,13 +86,19 @@ func NewRetryExecutor(config RetryConfig, logger interfaces.Logger) *RetryExecut
 	}
 
 	return &RetryExecutor{
-		config:      config,
-		logger:      logger,
+		config:       config,
+		logger:       logger,
 		errorHandler: NewErrorHandler(ProviderCustom, logger),
-		randSource:  rand.NewSource(time.Now().UnixNano()),
 	}
 }
 
+// OnRetry sets a hook called before every retry with the failed attempt,
+// the delay before the next one and the error
+func (re *RetryExecutor) OnRetry(hook func(attempt int, delay time.Duration, err error)) *RetryExecutor {
+	re.onRetry = hook
+	return re
+}
+
 // Execute executes a function with retry logic
 func (re *RetryExecutor) Execute(ctx context.Context, operation func() error) error {
 	state := &RetryState{
@@ -119,6 +131,15 @@ func (re *RetryExecutor) Execute(ctx context.Context, operation func() error) er
 			return err
 		}
 
+		// Don't wait longer than the policy allows for a provider that asks to
+		if retryAfter := retryAfterOf(err); retryAfter > re.config.MaxDelay {
+			re.logger.Debug("Retry-After exceeds max delay, not retrying",
+				"attempt", state.Attempt,
+				"retry_after", retryAfter,
+				"max_delay", re.config.MaxDelay)
+			return err
+		}
+
 		// Calculate delay for next attempt
 		state.NextDelay = re.calculateDelay(state.Attempt, err)
 		state.TotalDelay += state.NextDelay
@@ -136,6 +157,10 @@ func (re *RetryExecutor) Execute(ctx context.Context, operation func() error) er
 			break
 		}
 
+		if re.onRetry != nil {
+			re.onRetry(state.Attempt, state.NextDelay, err)
+		}
+
 		// Wait for the delay or context cancellation
 		select {
 		case <-ctx.Done():
@@ -180,10 +205,14 @@ func (re *RetryExecutor) calculateDelay(attempt int, err error) time.Duration {
 		delay = maxDelay
 	}
 
-	// Use provider-specific delay adjustment if available
-	providerDelay := re.errorHandler.GetRetryDelay(err, attempt)
-	if providerDelay > delay {
-		delay = providerDelay
+	// The provider knows best when it will accept requests again; without a
+	// Retry-After, fall back to the provider-specific delay
+	if retryAfter := retryAfterOf(err); retryAfter > 0 {
+		if retryAfter > delay && retryAfter <= maxDelay {
+			delay = retryAfter
+		}
+	} else if providerDelay := re.errorHandler.GetRetryDelay(err, attempt); providerDelay > delay {
+		delay = min(providerDelay, maxDelay)
 	}
 
 	return delay
@@ -191,11 +220,19 @@ func (re *RetryExecutor) calculateDelay(attempt int, err error) time.Duration {
 
 // applyJitter applies random jitter to delay
 func (re *RetryExecutor) applyJitter(delay time.Duration) time.Duration {
-	// Add ±25% random jitter
-	jitterFactor := 0.75 + (rand.New(re.randSource).Float64() * 0.5)
+	// Add ±25% random jitter; the global source is safe for concurrent executions
+	jitterFactor := 0.75 + (rand.Float64() * 0.5)
 	return time.Duration(float64(delay) * jitterFactor)
 }
 
+// retryAfterOf returns the Retry-After delay carried by a ModelError
+func retryAfterOf(err error) time.Duration {
+	if modelErr, ok := IsModelError(err); ok {
+		return modelErr.RetryAfter
+	}
+	return 0
+}
+
 // GetRetryState returns the current retry state
 func (re *RetryExecutor) GetRetryState(attempt int, lastError error) *RetryState {
 	return &RetryState{
@@ -207,14 +244,18 @@ func (re *RetryExecutor) GetRetryState(attempt int, lastError error) *RetryState
 	}
 }
 
-// CircuitBreaker implements circuit breaker pattern for retries
+// CircuitBreaker implements circuit breaker pattern for retries.
+// It is safe for concurrent use.
 type CircuitBreaker struct {
 	maxFailures   int
 	resetTimeout  time.Duration
 	failures      int
 	lastFailure   time.Time
 	state         CircuitState
+	probing       bool // the half-open trial request is in flight
+	onStateChange func(from, to CircuitState)
 	logger        interfaces.Logger
+	mutex         sync.Mutex
 }
 
 // CircuitState represents the state of a circuit breaker
@@ -226,6 +267,20 @@ const (
 	CircuitStateHalfOpen
 )
 
+// String returns the state name
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitStateClosed:
+		return "closed"
+	case CircuitStateOpen:
+		return "open"
+	case CircuitStateHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
 // NewCircuitBreaker creates a new circuit breaker
 func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration, logger interfaces.Logger) *CircuitBreaker {
 	if logger == nil {
@@ -240,10 +295,24 @@ func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration, logger inter
 	}
 }
 
-// Execute executes an operation through the circuit breaker
+// OnStateChange sets a hook called on every state transition. The hook runs
+// with the breaker locked and must not call back into it.
+func (cb *CircuitBreaker) OnStateChange(hook func(from, to CircuitState)) *CircuitBreaker {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.onStateChange = hook
+	return cb
+}
+
+// Execute executes an operation through the circuit breaker. Rejected operations
+// fail with a CIRCUIT_OPEN ModelError whose RetryAfter is the time left until
+// the circuit lets a trial request through.
 func (cb *CircuitBreaker) Execute(operation func() error) error {
-	if !cb.canExecute() {
-		return fmt.Errorf("circuit breaker is %v, operation rejected", cb.state)
+	if wait, ok := cb.canExecute(); !ok {
+		err := NewCircuitOpenError(fmt.Sprintf("circuit breaker is %s, operation rejected", cb.GetState()), "", "")
+		err.RetryAfter = wait
+		return err
 	}
 
 	err := operation()
@@ -254,63 +323,117 @@ func (cb *CircuitBreaker) Execute(operation func() error) error {
 // Note: Generic methods not supported in current Go version
 // Use Execute method and handle results in calling code
 
-// canExecute checks if operation can be executed based on circuit state
-func (cb *CircuitBreaker) canExecute() bool {
+// canExecute checks if operation can be executed based on circuit state,
+// returning the time left until a trial request when it cannot
+func (cb *CircuitBreaker) canExecute() (time.Duration, bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
 	switch cb.state {
 	case CircuitStateClosed:
-		return true
+		return 0, true
 	case CircuitStateOpen:
 		// Check if reset timeout has passed
-		if time.Since(cb.lastFailure) > cb.resetTimeout {
-			cb.state = CircuitStateHalfOpen
-			cb.logger.Info("Circuit breaker transitioning to half-open")
-			return true
+		if elapsed := time.Since(cb.lastFailure); elapsed < cb.resetTimeout {
+			return cb.resetTimeout - elapsed, false
 		}
-		return false
+		cb.setState(CircuitStateHalfOpen)
+		cb.probing = true
+		cb.logger.Info("Circuit breaker transitioning to half-open")
+		return 0, true
 	case CircuitStateHalfOpen:
-		return true
+		// A single trial request at a time
+		if cb.probing {
+			return 0, false
+		}
+		cb.probing = true
+		return 0, true
 	default:
-		return false
+		return 0, false
 	}
 }
 
 // recordResult records the result of an operation
 func (cb *CircuitBreaker) recordResult(err error) {
-	if err == nil {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.probing = false
+
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		// The caller gave up; this says nothing about the service
+		return
+	}
+
+	if !isCircuitFailure(err) {
 		// Success - reset failure count and close circuit
 		cb.failures = 0
 		if cb.state != CircuitStateClosed {
-			cb.state = CircuitStateClosed
+			cb.setState(CircuitStateClosed)
 			cb.logger.Info("Circuit breaker closed after successful operation")
 		}
-	} else {
-		// Failure - increment failure count
-		cb.failures++
-		cb.lastFailure = time.Now()
-
-		if cb.failures >= cb.maxFailures {
-			cb.state = CircuitStateOpen
-			cb.logger.Warn("Circuit breaker opened after too many failures",
-				"failures", cb.failures,
-				"max_failures", cb.maxFailures)
-		}
+		return
+	}
+
+	// Failure - increment failure count
+	cb.failures++
+	cb.lastFailure = time.Now()
+
+	// A failed trial reopens the circuit at once
+	if cb.state == CircuitStateHalfOpen || (cb.state == CircuitStateClosed && cb.failures >= cb.maxFailures) {
+		cb.setState(CircuitStateOpen)
+		cb.logger.Warn("Circuit breaker opened after too many failures",
+			"failures", cb.failures,
+			"max_failures", cb.maxFailures)
+	}
+}
+
+// isCircuitFailure reports whether an error indicates an unhealthy service.
+// Non-retryable model errors (invalid request, content filtered, ...) mean the
+// service answered and do not count.
+func isCircuitFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if modelErr, ok := IsModelError(err); ok {
+		return modelErr.Retryable
+	}
+	return true
+}
+
+// setState changes the state and calls the state change hook; callers hold the mutex
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	previous := cb.state
+	cb.state = state
+	if cb.onStateChange != nil && previous != state {
+		cb.onStateChange(previous, state)
 	}
 }
 
 // GetState returns the current circuit breaker state
 func (cb *CircuitBreaker) GetState() CircuitState {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
 	return cb.state
 }
 
 // GetFailures returns the current failure count
 func (cb *CircuitBreaker) GetFailures() int {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
 	return cb.failures
 }
 
 // Reset resets the circuit breaker to closed state
 func (cb *CircuitBreaker) Reset() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
 	cb.failures = 0
-	cb.state = CircuitStateClosed
+	cb.probing = false
+	cb.setState(CircuitStateClosed)
 	cb.logger.Info("Circuit breaker manually reset")
 }
 
@@ -358,7 +481,7 @@ func (rcb *RetryWithCircuitBreaker) ResetCircuitBreaker() {
 
 // IsRetryableErrorWithConfig checks if an error is retryable based on configuration
 func IsRetryableErrorWithConfig(err error, config RetryConfig) bool {
-	if modelErr, ok := err.(*ModelError); ok {
+	if modelErr, ok := IsModelError(err); ok {
 		for _, retryableErr := range config.RetryableErrors {
 			if string(modelErr.Code) == retryableErr {
 				return true
@@ -425,6 +548,82 @@ func GetRetryDelayForError(err error, attempt int) time.Duration {
 	return time.Second
 }
 
+// RetryConfigFromSpec converts a configured retry policy (ModelConfig.Retry) to a
+// RetryConfig. Unset fields fall back to DefaultRetryConfig.
+func RetryConfigFromSpec(spec *interfaces.RetryConfig) (RetryConfig, error) {
+	config := DefaultRetryConfig
+	if spec == nil {
+		return config, nil
+	}
+
+	if spec.MaxAttempts < 0 {
+		return config, fmt.Errorf("retry max_attempts must not be negative")
+	}
+	if spec.MaxAttempts > 0 {
+		config.MaxAttempts = spec.MaxAttempts
+	}
+
+	switch BackoffType(spec.Backoff) {
+	case "":
+	case BackoffTypeLinear, BackoffTypeExponential, BackoffTypeFixed:
+		config.BackoffType = BackoffType(spec.Backoff)
+	default:
+		return config, fmt.Errorf("unknown retry backoff %q (linear, exponential or fixed)", spec.Backoff)
+	}
+
+	if spec.BaseDelay != "" {
+		delay, err := time.ParseDuration(spec.BaseDelay)
+		if err != nil || delay < 0 {
+			return config, fmt.Errorf("invalid retry base_delay %q", spec.BaseDelay)
+		}
+		config.BaseDelay = delay
+	}
+	if spec.MaxDelay != "" {
+		delay, err := time.ParseDuration(spec.MaxDelay)
+		if err != nil || delay < 0 {
+			return config, fmt.Errorf("invalid retry max_delay %q", spec.MaxDelay)
+		}
+		config.MaxDelay = delay
+	}
+	if config.BaseDelay > config.MaxDelay {
+		return config, fmt.Errorf("retry base_delay %s exceeds max_delay %s", config.BaseDelay, config.MaxDelay)
+	}
+
+	return config, nil
+}
+
+// Circuit breaker defaults for configured policies (see CircuitBreakerFromSpec)
+const (
+	DefaultCircuitMaxFailures  = 5
+	DefaultCircuitResetTimeout = 30 * time.Second
+)
+
+// CircuitBreakerFromSpec creates a circuit breaker from a configured policy
+// (ModelConfig.CircuitBreaker). Unset fields fall back to the defaults above.
+func CircuitBreakerFromSpec(spec *interfaces.CircuitBreakerConfig, logger interfaces.Logger) (*CircuitBreaker, error) {
+	maxFailures, resetTimeout := DefaultCircuitMaxFailures, DefaultCircuitResetTimeout
+	if spec == nil {
+		return NewCircuitBreaker(maxFailures, resetTimeout, logger), nil
+	}
+
+	if spec.MaxFailures < 0 {
+		return nil, fmt.Errorf("circuit breaker max_failures must not be negative")
+	}
+	if spec.MaxFailures > 0 {
+		maxFailures = spec.MaxFailures
+	}
+
+	if spec.ResetTimeout != "" {
+		timeout, err := time.ParseDuration(spec.ResetTimeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid circuit breaker reset_timeout %q", spec.ResetTimeout)
+		}
+		resetTimeout = timeout
+	}
+
+	return NewCircuitBreaker(maxFailures, resetTimeout, logger), nil
+}
+
 // RetryConfigBuilder helps build retry configurations
 type RetryConfigBuilder struct {
 	config RetryConfig
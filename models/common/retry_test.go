@@ -0,0 +1,287 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"1.5", 1500 * time.Millisecond, true},
+		{"Sat, 01 Mar 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"Sat, 01 Mar 2025 11:59:00 GMT", 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", "10")
+	header.Set("Retry-After-Ms", "250")
+	if got, ok := RetryAfterFromHeader(header, now); !ok || got != 250*time.Millisecond {
+		t.Errorf("RetryAfterFromHeader() = %v, %v, want retry-after-ms to win", got, ok)
+	}
+}
+
+func TestErrorFromHTTPResponse(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"3"}}}
+
+	modelErr := ErrorFromHTTPResponse(resp, "zai", "glm-4.6")
+	if modelErr.Code != ErrorCodeRateLimitError || !modelErr.Retryable || modelErr.RetryAfter != 3*time.Second {
+		t.Errorf("unexpected error: %+v", modelErr)
+	}
+
+	// Wrapped model errors are still found
+	wrapped := errors.Join(errors.New("generation failed"), modelErr)
+	if !IsRetryableModelError(wrapped) || GetErrorCode(wrapped) != ErrorCodeRateLimitError {
+		t.Error("expected the wrapped rate limit error to be retryable")
+	}
+}
+
+func TestErrorFromAPIResponse(t *testing.T) {
+	apiError := func(body []byte) (string, interface{}) {
+		var payload struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return "", nil
+		}
+		return payload.Error, payload.Error
+	}
+
+	resp := &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader(`{"error": "max_tokens is too large"}`))}
+	modelErr := ErrorFromAPIResponse(resp, "openai", "gpt-4o-mini", apiError)
+	if modelErr.Code != ErrorCodeInvalidRequest || !strings.HasSuffix(modelErr.Message, ": max_tokens is too large") || modelErr.Details != "max_tokens is too large" {
+		t.Errorf("unexpected error: %+v", modelErr)
+	}
+
+	// Bodies without an API error keep the status message
+	resp = &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(strings.NewReader("<html>bad gateway</html>"))}
+	modelErr = ErrorFromAPIResponse(resp, "openai", "gpt-4o-mini", apiError)
+	if modelErr.Code != ErrorCodeServerError || modelErr.Details != nil || strings.Contains(modelErr.Message, "html") {
+		t.Errorf("unexpected error: %+v", modelErr)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker := NewCircuitBreaker(2, 20*time.Millisecond, interfaces.NewNoOpLogger())
+
+	var transitions []string
+	breaker.OnStateChange(func(from, to CircuitState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	rateLimited := NewRateLimitError("slow down", "zai", "glm-4.6")
+	invalid := NewModelError(ErrorCodeInvalidRequest, "bad request", "zai", "glm-4.6")
+
+	// Non-retryable errors and cancellations do not count as failures
+	_ = breaker.Execute(func() error { return rateLimited })
+	_ = breaker.Execute(func() error { return invalid })
+	_ = breaker.Execute(func() error { return rateLimited })
+	_ = breaker.Execute(func() error { return context.Canceled })
+	if state := breaker.GetState(); state != CircuitStateClosed || breaker.GetFailures() != 1 {
+		t.Fatalf("state = %s, failures = %d, want closed with 1 failure", state, breaker.GetFailures())
+	}
+
+	_ = breaker.Execute(func() error { return rateLimited })
+	if breaker.GetState() != CircuitStateOpen {
+		t.Fatalf("expected the circuit to open, got %s", breaker.GetState())
+	}
+
+	called := false
+	err := breaker.Execute(func() error { called = true; return nil })
+	modelErr, ok := IsModelError(err)
+	if called || !ok || modelErr.Code != ErrorCodeCircuitOpen || modelErr.Retryable || modelErr.RetryAfter <= 0 {
+		t.Fatalf("expected a non-retryable CIRCUIT_OPEN rejection, got %v", err)
+	}
+
+	// After the reset timeout a single trial request goes through
+	time.Sleep(25 * time.Millisecond)
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- breaker.Execute(func() error { <-release; return nil })
+	}()
+	for breaker.GetState() != CircuitStateHalfOpen {
+		time.Sleep(time.Millisecond)
+	}
+	if err := breaker.Execute(func() error { return nil }); GetErrorCode(err) != ErrorCodeCircuitOpen {
+		t.Errorf("expected a second request to be rejected while half-open, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("trial request error = %v", err)
+	}
+
+	if breaker.GetState() != CircuitStateClosed {
+		t.Errorf("expected the circuit to close after a successful trial, got %s", breaker.GetState())
+	}
+	want := "closed->open,open->half_open,half_open->closed"
+	if got := strings.Join(transitions, ","); got != want {
+		t.Errorf("transitions = %s, want %s", got, want)
+	}
+}
+
+func TestRetryExecutor_RetryAfter(t *testing.T) {
+	config := NewRetryConfigBuilder().
+		WithMaxAttempts(3).
+		WithBaseDelay(time.Millisecond).
+		WithMaxDelay(time.Second).
+		WithJitter(false).
+		Build()
+
+	var retries []time.Duration
+	executor := NewRetryExecutor(config, interfaces.NewNoOpLogger()).OnRetry(func(attempt int, delay time.Duration, err error) {
+		retries = append(retries, delay)
+	})
+
+	calls := 0
+	err := executor.Execute(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			modelErr := NewRateLimitError("slow down", "zai", "glm-4.6")
+			modelErr.RetryAfter = 30 * time.Millisecond
+			return modelErr
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("Execute() = %v after %d calls, want success on the second", err, calls)
+	}
+	if len(retries) != 1 || retries[0] != 30*time.Millisecond {
+		t.Errorf("retry delays = %v, want the 30ms Retry-After", retries)
+	}
+
+	// A Retry-After beyond max delay ends retries at once
+	calls = 0
+	err = executor.Execute(context.Background(), func() error {
+		calls++
+		modelErr := NewRateLimitError("slow down", "zai", "glm-4.6")
+		modelErr.RetryAfter = time.Minute
+		return modelErr
+	})
+	if calls != 1 || GetErrorCode(err) != ErrorCodeRateLimitError {
+		t.Errorf("Execute() = %v after %d calls, want the rate limit error after 1", err, calls)
+	}
+
+	// Non-retryable errors are returned as is
+	calls = 0
+	err = executor.Execute(context.Background(), func() error {
+		calls++
+		return NewModelError(ErrorCodeInvalidRequest, "bad request", "zai", "glm-4.6")
+	})
+	if calls != 1 || GetErrorCode(err) != ErrorCodeInvalidRequest {
+		t.Errorf("Execute() = %v after %d calls, want 1 call", err, calls)
+	}
+}
+
+func TestRetryExecutor_ProviderDelay(t *testing.T) {
+	config := NewRetryConfigBuilder().
+		WithBaseDelay(time.Millisecond).
+		WithMaxDelay(50 * time.Millisecond).
+		WithJitter(false).
+		Build()
+	executor := NewRetryExecutor(config, interfaces.NewNoOpLogger())
+
+	// Without a Retry-After the provider-specific delay applies, within max delay
+	modelErr := NewRateLimitError("slow down", "zai", "glm-4.6")
+	if got := executor.calculateDelay(1, modelErr); got != 50*time.Millisecond {
+		t.Errorf("calculateDelay() = %v, want the provider delay capped at 50ms", got)
+	}
+
+	modelErr.RetryAfter = 20 * time.Millisecond
+	if got := executor.calculateDelay(1, modelErr); got != 20*time.Millisecond {
+		t.Errorf("calculateDelay() = %v, want the 20ms Retry-After", got)
+	}
+}
+
+func TestRetryConfigFromSpec(t *testing.T) {
+	config, err := RetryConfigFromSpec(&interfaces.RetryConfig{MaxAttempts: 5, Backoff: "linear", BaseDelay: "200ms", MaxDelay: "10s"})
+	if err != nil {
+		t.Fatalf("RetryConfigFromSpec() error = %v", err)
+	}
+	if config.MaxAttempts != 5 || config.BackoffType != BackoffTypeLinear || config.BaseDelay != 200*time.Millisecond || config.MaxDelay != 10*time.Second {
+		t.Errorf("unexpected config: %+v", config)
+	}
+
+	invalid := []*interfaces.RetryConfig{
+		{Backoff: "random"},
+		{BaseDelay: "soon"},
+		{BaseDelay: "1m", MaxDelay: "1s"},
+		{MaxAttempts: -1},
+	}
+	for _, spec := range invalid {
+		if _, err := RetryConfigFromSpec(spec); err == nil {
+			t.Errorf("expected an error for %+v", spec)
+		}
+	}
+
+	if _, err := CircuitBreakerFromSpec(&interfaces.CircuitBreakerConfig{ResetTimeout: "never"}, nil); err == nil {
+		t.Error("expected an error for an invalid reset_timeout")
+	}
+}
+
+func TestHTTPClient_RetryAfter(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(&DefaultHTTPConfig, RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+
+	resp, err := client.Post(context.Background(), server.URL, MIMETypeJSON, `{"prompt":"hi"}`)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("status = %d after %d requests, want 200 after 2", resp.StatusCode, requests)
+	}
+
+	// Without HTTP retries the rate limited response is returned for mapping
+	atomic.StoreInt32(&requests, 0)
+	resp, err = client.Post(WithoutHTTPRetry(context.Background()), server.URL, MIMETypeJSON, `{"prompt":"hi"}`)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("status = %d after %d requests, want 429 after 1", resp.StatusCode, requests)
+	}
+	if modelErr := ErrorFromHTTPResponse(resp, "zai", "glm-4.6"); modelErr.Code != ErrorCodeRateLimitError {
+		t.Errorf("unexpected error: %+v", modelErr)
+	}
+}
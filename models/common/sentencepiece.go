@@ -0,0 +1,338 @@
+package common
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// sentencePieceSpace is the SentencePiece whitespace marker (U+2581)
+const sentencePieceSpace = "▁"
+
+// SentencePiece piece types as stored in a .model file
+const (
+	pieceTypeNormal      = 1
+	pieceTypeUnknown     = 2
+	pieceTypeControl     = 3
+	pieceTypeUserDefined = 4
+	pieceTypeByte        = 6
+)
+
+// sentencePiece is one vocabulary entry
+type sentencePiece struct {
+	text      string
+	score     float64
+	pieceType int
+}
+
+// SentencePieceTokenizer segments text into the pieces of a SentencePiece
+// vocabulary with the highest total score (unigram Viterbi). Characters outside
+// the vocabulary become byte pieces (<0xE2>) when the vocabulary has them, or
+// the unknown piece otherwise.
+type SentencePieceTokenizer struct {
+	name           string
+	pieces         map[string]int // piece text to ID, normal and user-defined pieces
+	scores         []float64      // by ID
+	bytePieces     map[byte]int
+	unknownID      int
+	unknownScore   float64
+	maxPieceLen    int // bytes
+	addDummyPrefix bool
+}
+
+// LoadSentencePieceFile loads a SentencePiece model: a binary .model file, or a
+// .vocab file of "<piece>\t<score>" lines as written next to it by spm_train
+func LoadSentencePieceFile(name, path string) (*SentencePieceTokenizer, error) {
+	var (
+		pieces         []sentencePiece
+		addDummyPrefix = true
+		err            error
+	)
+
+	if strings.EqualFold(filepath.Ext(path), ".vocab") {
+		pieces, err = readSentencePieceVocab(path)
+	} else {
+		pieces, addDummyPrefix, err = readSentencePieceModel(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pieces) == 0 {
+		return nil, fmt.Errorf("SentencePiece file %s has no pieces", path)
+	}
+
+	return newSentencePieceTokenizer(name, pieces, addDummyPrefix), nil
+}
+
+// newSentencePieceTokenizer creates a tokenizer from pieces in ID order
+func newSentencePieceTokenizer(name string, pieces []sentencePiece, addDummyPrefix bool) *SentencePieceTokenizer {
+	t := &SentencePieceTokenizer{
+		name:           name,
+		pieces:         make(map[string]int, len(pieces)),
+		scores:         make([]float64, len(pieces)),
+		bytePieces:     make(map[byte]int),
+		unknownID:      UnknownTokenID,
+		addDummyPrefix: addDummyPrefix,
+	}
+
+	minScore := 0.0
+	for id, piece := range pieces {
+		t.scores[id] = piece.score
+		switch piece.pieceType {
+		case pieceTypeNormal, pieceTypeUserDefined:
+			t.pieces[piece.text] = id
+			if len(piece.text) > t.maxPieceLen {
+				t.maxPieceLen = len(piece.text)
+			}
+			minScore = math.Min(minScore, piece.score)
+		case pieceTypeUnknown:
+			t.unknownID = id
+		case pieceTypeByte:
+			if value, ok := parseBytePiece(piece.text); ok {
+				t.bytePieces[value] = id
+			}
+		}
+	}
+
+	// Unknown characters lose to any segmentation the vocabulary covers
+	t.unknownScore = minScore - 10
+	return t
+}
+
+// Name returns the tokenizer name
+func (t *SentencePieceTokenizer) Name() string {
+	return t.name
+}
+
+// Count returns the number of tokens in text
+func (t *SentencePieceTokenizer) Count(text string) int {
+	return len(t.Encode(text))
+}
+
+// Encode returns the token IDs of text
+func (t *SentencePieceTokenizer) Encode(text string) []int {
+	if text == "" {
+		return nil
+	}
+
+	normalized := strings.ReplaceAll(text, " ", sentencePieceSpace)
+	if t.addDummyPrefix {
+		normalized = sentencePieceSpace + normalized
+	}
+
+	n := len(normalized)
+	best := make([]float64, n+1)
+	from := make([]int, n+1)
+	ids := make([]int, n+1) // piece ending at each position; UnknownTokenID for a single unknown rune
+	for i := 1; i <= n; i++ {
+		best[i] = math.Inf(-1)
+	}
+
+	relax := func(start, end int, score float64, id int) {
+		if score := best[start] + score; score > best[end] {
+			best[end], from[end], ids[end] = score, start, id
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if math.IsInf(best[i], -1) {
+			continue
+		}
+		for length := 1; length <= t.maxPieceLen && i+length <= n; length++ {
+			if id, exists := t.pieces[normalized[i:i+length]]; exists {
+				relax(i, i+length, t.scores[id], id)
+			}
+		}
+		_, size := utf8.DecodeRuneInString(normalized[i:])
+		relax(i, i+size, t.unknownScore, UnknownTokenID)
+	}
+
+	var reversed []int
+	for end := n; end > 0; end = from[end] {
+		if ids[end] != UnknownTokenID {
+			reversed = append(reversed, ids[end])
+			continue
+		}
+		reversed = append(reversed, t.unknownIDs(normalized[from[end]:end])...)
+	}
+
+	tokens := make([]int, len(reversed))
+	for i, id := range reversed {
+		tokens[len(reversed)-1-i] = id
+	}
+	return tokens
+}
+
+// unknownIDs returns the byte pieces of an unknown character in reverse order,
+// or the unknown piece when the vocabulary has no byte fallback
+func (t *SentencePieceTokenizer) unknownIDs(char string) []int {
+	if len(t.bytePieces) == 0 {
+		return []int{t.unknownID}
+	}
+
+	ids := make([]int, 0, len(char))
+	for i := len(char) - 1; i >= 0; i-- {
+		id, exists := t.bytePieces[char[i]]
+		if !exists {
+			id = t.unknownID
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// readSentencePieceVocab reads "<piece>\t<score>" lines; the line number is the ID
+func readSentencePieceVocab(path string) ([]sentencePiece, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SentencePiece vocabulary: %w", err)
+	}
+	defer file.Close()
+
+	var pieces []sentencePiece
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text, scoreText, found := strings.Cut(scanner.Text(), "\t")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected \"<piece>\\t<score>\"", path, line)
+		}
+		score, err := strconv.ParseFloat(strings.TrimSpace(scoreText), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid score: %w", path, line, err)
+		}
+
+		// The .vocab file has no piece types; spm_train writes the special pieces first
+		pieceType := pieceTypeNormal
+		switch {
+		case text == "<unk>":
+			pieceType = pieceTypeUnknown
+		case text == "<s>" || text == "</s>" || text == "<pad>":
+			pieceType = pieceTypeControl
+		default:
+			if _, ok := parseBytePiece(text); ok {
+				pieceType = pieceTypeByte
+			}
+		}
+
+		pieces = append(pieces, sentencePiece{text: text, score: score, pieceType: pieceType})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read SentencePiece vocabulary: %w", err)
+	}
+
+	return pieces, nil
+}
+
+// readSentencePieceModel reads the pieces and the add_dummy_prefix flag of a
+// serialized ModelProto. Only the fields needed for segmentation are decoded:
+// pieces (1) with piece (1), score (2) and type (3), and normalizer_spec (3)
+// with add_dummy_prefix (3).
+func readSentencePieceModel(path string) ([]sentencePiece, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read SentencePiece model: %w", err)
+	}
+
+	var pieces []sentencePiece
+	addDummyPrefix := true
+
+	err = walkProto(data, func(field int, value []byte, number uint64) error {
+		switch field {
+		case 1:
+			piece := sentencePiece{pieceType: pieceTypeNormal}
+			err := walkProto(value, func(field int, value []byte, number uint64) error {
+				switch field {
+				case 1:
+					piece.text = string(value)
+				case 2:
+					piece.score = float64(math.Float32frombits(uint32(number)))
+				case 3:
+					piece.pieceType = int(number)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			pieces = append(pieces, piece)
+		case 3:
+			return walkProto(value, func(field int, value []byte, number uint64) error {
+				if field == 3 {
+					addDummyPrefix = number != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid SentencePiece model %s: %w", path, err)
+	}
+
+	return pieces, addDummyPrefix, nil
+}
+
+// walkProto calls visit for every field of a protobuf message with the bytes of
+// length-delimited fields or the number of varint and fixed-width fields
+func walkProto(data []byte, visit func(field int, value []byte, number uint64) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("malformed field key")
+		}
+		data = data[n:]
+		field, wireType := int(key>>3), key&7
+
+		var value []byte
+		var number uint64
+		switch wireType {
+		case 0: // varint
+			number, n = binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("malformed varint in field %d", field)
+			}
+			data = data[n:]
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return fmt.Errorf("truncated field %d", field)
+			}
+			number, data = binary.LittleEndian.Uint64(data), data[8:]
+		case 2: // length-delimited
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return fmt.Errorf("truncated field %d", field)
+			}
+			value, data = data[n:n+int(length)], data[n+int(length):]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return fmt.Errorf("truncated field %d", field)
+			}
+			number, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", wireType, field)
+		}
+
+		if err := visit(field, value, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseBytePiece parses a byte fallback piece such as "<0xE2>"
+func parseBytePiece(text string) (byte, bool) {
+	if len(text) != 6 || !strings.HasPrefix(text, "<0x") || text[5] != '>' {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(text[3:5], 16, 8)
+	if err != nil {
+		return 0, false
+	}
+	return byte(value), true
+}
@@ -0,0 +1,308 @@
+package common
+
+// Stream accumulation and iteration
+//
+// StreamAccumulator merges the chunks of GenerateStreaming into the response
+// Generate would have returned: text and reasoning deltas are joined, tool call
+// fragments are merged into whole calls, and usage, finish reason, logprobs and
+// metadata are kept as they arrive (usage usually comes with the last chunk):
+//
+//	stream := common.NewStreamAccumulator()
+//	err := model.GenerateStreaming(ctx, req, stream.Callback(printChunk))
+//	resp := stream.Response()
+//
+// StreamChunks and StreamChannel turn the callback into an iterator or a channel.
+// The stream advances only as fast as chunks are consumed, and stopping early
+// cancels the underlying call:
+//
+//	for chunk, err := range common.StreamChunks(ctx, model, req) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Print(common.ChunkText(chunk))
+//	}
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"strings"
+	"sync"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// rawArgumentsKey holds tool call arguments that are not (yet) valid JSON
+const rawArgumentsKey = "raw_arguments"
+
+// StreamGenerator produces streaming responses; models and the framework implement it
+type StreamGenerator interface {
+	GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error
+}
+
+// Generator produces unary and streaming responses; models and the framework implement it
+type Generator interface {
+	Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error)
+	StreamGenerator
+}
+
+// StreamAccumulator merges stream chunks into a complete response.
+// It is safe to read the response while chunks are still added.
+type StreamAccumulator struct {
+	mutex        sync.Mutex
+	text         strings.Builder
+	reasoning    strings.Builder
+	other        []*interfaces.PonchoContentPart
+	tools        []*streamToolCall
+	usage        *interfaces.PonchoUsage
+	finishReason interfaces.PonchoFinishReason
+	logProbs     *interfaces.PonchoLogProbs
+	metadata     map[string]interface{}
+	chunks       int
+}
+
+// streamToolCall is a tool call assembled from deltas
+type streamToolCall struct {
+	id   string
+	name string
+	args map[string]interface{}
+	raw  strings.Builder // argument fragments
+}
+
+// NewStreamAccumulator creates an empty stream accumulator
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{}
+}
+
+// Add merges one chunk into the response
+func (a *StreamAccumulator) Add(chunk *interfaces.PonchoStreamChunk) {
+	if chunk == nil {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.chunks++
+	if chunk.Delta != nil {
+		for _, part := range chunk.Delta.Content {
+			switch {
+			case part == nil:
+			case part.Type == interfaces.PonchoContentTypeText:
+				a.text.WriteString(part.Text)
+			case part.Type == interfaces.PonchoContentTypeReasoning:
+				a.reasoning.WriteString(part.Text)
+			case part.Type == interfaces.PonchoContentTypeTool && part.Tool != nil:
+				a.addToolDelta(part.Tool)
+			default:
+				a.other = append(a.other, part)
+			}
+		}
+	}
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+	if chunk.FinishReason != "" {
+		a.finishReason = chunk.FinishReason
+	}
+	a.logProbs = AppendLogProbs(a.logProbs, chunk.LogProbs)
+	if len(chunk.Metadata) > 0 {
+		if a.metadata == nil {
+			a.metadata = make(map[string]interface{}, len(chunk.Metadata))
+		}
+		for key, value := range chunk.Metadata {
+			a.metadata[key] = value
+		}
+	}
+}
+
+// addToolDelta merges a tool call delta. A delta with a new ID starts a call, one
+// without ID continues the last call. Arguments are merged, argument fragments
+// passed as raw_arguments are joined and parsed when the response is built.
+func (a *StreamAccumulator) addToolDelta(tool *interfaces.PonchoToolPart) {
+	var call *streamToolCall
+	for _, existing := range a.tools {
+		if tool.ID != "" && existing.id == tool.ID {
+			call = existing
+			break
+		}
+	}
+	if call == nil && tool.ID == "" && len(a.tools) > 0 {
+		call = a.tools[len(a.tools)-1]
+	}
+	if call == nil {
+		call = &streamToolCall{id: tool.ID, args: make(map[string]interface{})}
+		a.tools = append(a.tools, call)
+	}
+
+	if tool.Name != "" {
+		call.name = tool.Name
+	}
+	if fragment, ok := tool.Args[rawArgumentsKey].(string); ok && len(tool.Args) == 1 {
+		call.raw.WriteString(fragment)
+		return
+	}
+	for key, value := range tool.Args {
+		call.args[key] = value
+	}
+}
+
+// Callback returns a stream callback that adds each chunk before passing it to
+// next. next may be nil.
+func (a *StreamAccumulator) Callback(next interfaces.PonchoStreamCallback) interfaces.PonchoStreamCallback {
+	return func(chunk *interfaces.PonchoStreamChunk) error {
+		a.Add(chunk)
+		if next == nil {
+			return nil
+		}
+		return next(chunk)
+	}
+}
+
+// Text returns the text received so far
+func (a *StreamAccumulator) Text() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.text.String()
+}
+
+// Usage returns the usage reported by the stream, nil when none was reported
+func (a *StreamAccumulator) Usage() *interfaces.PonchoUsage {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.usage
+}
+
+// Chunks returns the number of chunks added
+func (a *StreamAccumulator) Chunks() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.chunks
+}
+
+// Response returns the response assembled so far. Content is laid out as in
+// Generate responses: text, reasoning, other parts, then tool calls.
+func (a *StreamAccumulator) Response() *interfaces.PonchoModelResponse {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	content := make([]*interfaces.PonchoContentPart, 0, 2+len(a.other)+len(a.tools))
+	if a.text.Len() > 0 {
+		content = append(content, &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeText, Text: a.text.String()})
+	}
+	if a.reasoning.Len() > 0 {
+		content = append(content, &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeReasoning, Text: a.reasoning.String()})
+	}
+	content = append(content, a.other...)
+	for _, call := range a.tools {
+		content = append(content, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeTool,
+			Tool: &interfaces.PonchoToolPart{ID: call.id, Name: call.name, Args: call.arguments()},
+		})
+	}
+
+	return &interfaces.PonchoModelResponse{
+		Message:      &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: content},
+		Usage:        a.usage,
+		FinishReason: a.finishReason,
+		LogProbs:     a.logProbs,
+		Metadata:     a.metadata,
+	}
+}
+
+// arguments returns the merged arguments of the call; fragments that do not form
+// a JSON object are kept as raw_arguments, as providers do
+func (c *streamToolCall) arguments() map[string]interface{} {
+	args := make(map[string]interface{}, len(c.args)+1)
+	if c.raw.Len() > 0 {
+		if err := json.Unmarshal([]byte(c.raw.String()), &args); err != nil {
+			args = map[string]interface{}{rawArgumentsKey: c.raw.String()}
+		}
+	}
+	for key, value := range c.args {
+		args[key] = value
+	}
+	return args
+}
+
+// ChunkText returns the text of a chunk, without reasoning
+func ChunkText(chunk *interfaces.PonchoStreamChunk) string {
+	if chunk == nil || chunk.Delta == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	for _, part := range chunk.Delta.Content {
+		if part != nil && part.Type == interfaces.PonchoContentTypeText {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
+
+// StreamChunks streams a response as an iterator. Each chunk is handed over only
+// when the loop asks for it, holding back the generator meanwhile. Leaving the
+// loop early cancels the call and waits for it to return. A failed stream yields
+// its error last, with a nil chunk.
+func StreamChunks(ctx context.Context, generator StreamGenerator, req *interfaces.PonchoModelRequest) iter.Seq2[*interfaces.PonchoStreamChunk, error] {
+	return func(yield func(*interfaces.PonchoStreamChunk, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		chunks := make(chan *interfaces.PonchoStreamChunk)
+		done := make(chan error, 1)
+		go func() {
+			done <- generator.GenerateStreaming(ctx, req, func(chunk *interfaces.PonchoStreamChunk) error {
+				select {
+				case chunks <- chunk:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}()
+
+		for {
+			select {
+			case chunk := <-chunks:
+				if !yield(chunk, nil) {
+					cancel()
+					<-done
+					return
+				}
+			case err := <-done:
+				if err != nil {
+					yield(nil, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// StreamEvent is a chunk of a streamed response, or the error that ended it
+type StreamEvent struct {
+	Chunk *interfaces.PonchoStreamChunk
+	Err   error
+}
+
+// StreamChannel streams a response over a channel buffering up to buffer chunks;
+// the generator is held back while the buffer is full. The channel is closed when
+// the stream ends, after an event carrying the error of a failed stream. Cancel
+// ctx to stop the call when abandoning the channel.
+func StreamChannel(ctx context.Context, generator StreamGenerator, req *interfaces.PonchoModelRequest, buffer int) <-chan StreamEvent {
+	events := make(chan StreamEvent, max(buffer, 0))
+
+	go func() {
+		defer close(events)
+		for chunk, err := range StreamChunks(ctx, generator, req) {
+			select {
+			case events <- StreamEvent{Chunk: chunk, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
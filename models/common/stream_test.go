@@ -0,0 +1,192 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// streamFunc adapts a function to StreamGenerator
+type streamFunc func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error
+
+func (f streamFunc) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	return f(ctx, req, callback)
+}
+
+func deltaChunk(parts ...*interfaces.PonchoContentPart) *interfaces.PonchoStreamChunk {
+	return &interfaces.PonchoStreamChunk{Delta: &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: parts}}
+}
+
+func textDelta(text string) *interfaces.PonchoContentPart {
+	return &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeText, Text: text}
+}
+
+func toolDelta(id, name string, args map[string]interface{}) *interfaces.PonchoContentPart {
+	return &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeTool, Tool: &interfaces.PonchoToolPart{ID: id, Name: name, Args: args}}
+}
+
+// countingStream streams n text chunks and records how many were sent
+func countingStream(n int, sent *atomic.Int64) streamFunc {
+	return func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+		for i := 0; i < n; i++ {
+			if err := callback(deltaChunk(textDelta("x"))); err != nil {
+				return err
+			}
+			sent.Add(1)
+		}
+		return nil
+	}
+}
+
+func TestStreamAccumulator(t *testing.T) {
+	stream := NewStreamAccumulator()
+	for _, chunk := range []*interfaces.PonchoStreamChunk{
+		deltaChunk(&interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeReasoning, Text: "Look up "}),
+		deltaChunk(&interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeReasoning, Text: "the article."}, textDelta("Checking")),
+		// Fragments as sent by ConvertStreamChunkToPoncho: the first carries ID and name
+		deltaChunk(toolDelta("call_1", "get_article", map[string]interface{}{})),
+		deltaChunk(toolDelta("", "", map[string]interface{}{"raw_arguments": `{"id": `})),
+		deltaChunk(toolDelta("", "", map[string]interface{}{"raw_arguments": `42}`})),
+		// A whole call, then arguments added to it by ID
+		deltaChunk(toolDelta("call_2", "search", map[string]interface{}{"query": "dress"})),
+		deltaChunk(toolDelta("call_2", "", map[string]interface{}{"limit": float64(5)})),
+		{
+			Delta:        &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: []*interfaces.PonchoContentPart{textDelta("...")}},
+			Usage:        &interfaces.PonchoUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			FinishReason: interfaces.PonchoFinishReasonTool,
+			Done:         true,
+			LogProbs:     &interfaces.PonchoLogProbs{Content: []interfaces.PonchoTokenLogProb{{Token: "...", LogProb: -0.5}}},
+			Metadata:     map[string]interface{}{"model": "deepseek-chat"},
+		},
+		nil,
+	} {
+		stream.Add(chunk)
+	}
+
+	resp := stream.Response()
+	content := resp.Message.Content
+	if len(content) != 4 {
+		t.Fatalf("expected text, reasoning and two tool calls, got %d parts", len(content))
+	}
+	if content[0].Text != "Checking..." || content[1].Text != "Look up the article." {
+		t.Errorf("unexpected text parts: %q, %q", content[0].Text, content[1].Text)
+	}
+	if tool := content[2].Tool; tool.ID != "call_1" || tool.Name != "get_article" || tool.Args["id"] != float64(42) || len(tool.Args) != 1 {
+		t.Errorf("unexpected first tool call: %+v", tool)
+	}
+	if tool := content[3].Tool; tool.ID != "call_2" || tool.Args["query"] != "dress" || tool.Args["limit"] != float64(5) {
+		t.Errorf("unexpected second tool call: %+v", tool)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 15 || resp.FinishReason != interfaces.PonchoFinishReasonTool {
+		t.Errorf("unexpected usage or finish reason: %+v, %s", resp.Usage, resp.FinishReason)
+	}
+	if resp.LogProbs == nil || len(resp.LogProbs.Content) != 1 || resp.Metadata["model"] != "deepseek-chat" {
+		t.Errorf("unexpected logprobs or metadata: %+v, %+v", resp.LogProbs, resp.Metadata)
+	}
+	if stream.Text() != "Checking..." || stream.Chunks() != 8 {
+		t.Errorf("Text() = %q, Chunks() = %d", stream.Text(), stream.Chunks())
+	}
+
+	// Incomplete argument fragments are kept raw
+	stream = NewStreamAccumulator()
+	stream.Add(deltaChunk(toolDelta("call_1", "get_article", map[string]interface{}{"raw_arguments": `{"id": 4`})))
+	if args := stream.Response().Message.Content[0].Tool.Args; args["raw_arguments"] != `{"id": 4` {
+		t.Errorf("expected raw arguments, got %+v", args)
+	}
+}
+
+func TestStreamAccumulator_Callback(t *testing.T) {
+	stream := NewStreamAccumulator()
+	forwarded := 0
+	generator := countingStream(3, new(atomic.Int64))
+
+	err := generator.GenerateStreaming(context.Background(), nil, stream.Callback(func(chunk *interfaces.PonchoStreamChunk) error {
+		forwarded++
+		return nil
+	}))
+	if err != nil || forwarded != 3 || stream.Text() != "xxx" {
+		t.Errorf("err = %v, forwarded %d, text %q", err, forwarded, stream.Text())
+	}
+
+	if err := generator.GenerateStreaming(context.Background(), nil, NewStreamAccumulator().Callback(nil)); err != nil {
+		t.Errorf("expected a nil next callback to be allowed, got %v", err)
+	}
+}
+
+func TestStreamChunks(t *testing.T) {
+	var sent atomic.Int64
+	text := ""
+	for chunk, err := range StreamChunks(context.Background(), countingStream(5, &sent), nil) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		text += ChunkText(chunk)
+	}
+	if text != "xxxxx" || sent.Load() != 5 {
+		t.Errorf("text = %q, sent = %d", text, sent.Load())
+	}
+
+	// Leaving the loop cancels the stream once the consumed chunk is handed over
+	sent.Store(0)
+	for range StreamChunks(context.Background(), countingStream(100, &sent), nil) {
+		break
+	}
+	if sent.Load() != 1 {
+		t.Errorf("sent = %d chunks after breaking on the first, want 1", sent.Load())
+	}
+
+	// The error of a failed stream comes last
+	failure := errors.New("connection reset")
+	var errs []error
+	for chunk, err := range StreamChunks(context.Background(), streamFunc(func(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+		if err := callback(deltaChunk(textDelta("x"))); err != nil {
+			return err
+		}
+		return failure
+	}), nil) {
+		if chunk == nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], failure) {
+		t.Errorf("errors = %v, want the stream failure", errs)
+	}
+}
+
+func TestStreamChannel(t *testing.T) {
+	var sent atomic.Int64
+	events := StreamChannel(context.Background(), countingStream(10, &sent), nil, 2)
+
+	// The generator stops once the buffer is full
+	time.Sleep(20 * time.Millisecond)
+	if sent.Load() > 4 {
+		t.Errorf("sent = %d chunks before any was read, want the buffer to hold it back", sent.Load())
+	}
+
+	received := 0
+	for event := range events {
+		if event.Err != nil {
+			t.Fatalf("unexpected error: %v", event.Err)
+		}
+		received++
+	}
+	if received != 10 {
+		t.Errorf("received %d chunks, want 10", received)
+	}
+
+	// Cancelling the context stops the stream and closes the channel
+	ctx, cancel := context.WithCancel(context.Background())
+	sent.Store(0)
+	events = StreamChannel(ctx, countingStream(100, &sent), nil, 0)
+	<-events
+	cancel()
+	for range events {
+	}
+	if sent.Load() == 100 {
+		t.Error("expected the stream to stop after cancellation")
+	}
+}
@@ -0,0 +1,227 @@
+package common
+
+// Text tokenizers count tokens exactly as a model's own tokenizer does, from
+// vocabulary files loaded from disk:
+// - "tiktoken": a .tiktoken rank file (base64 token and rank per line), e.g. cl100k_base, o200k_base
+// - "bpe": a byte-level BPE vocab.json and merges.txt pair, e.g. from a Hugging Face model repo
+// - "sentencepiece": a SentencePiece .model file or its exported .vocab file
+//
+// Model names are mapped to tokenizers with RegisterTextTokenizer. Tokenizer.CountTokens
+// uses the mapped tokenizer and falls back to the character/word heuristic of
+// ModelTokenConfig for models without one.
+//
+// Both BPE formats split text with the cl100k_base pre-tokenization rules; models
+// trained with other rules (o200k_base, DeepSeek, GLM) may differ by a few tokens
+// on mixed-case words and long digit runs. Use TokenCalibrator to measure the drift.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// Tokenizer file formats accepted by LoadTextTokenizer
+const (
+	TokenizerTypeTiktoken      = "tiktoken"
+	TokenizerTypeBPE           = "bpe"
+	TokenizerTypeSentencePiece = "sentencepiece"
+)
+
+// UnknownTokenID is returned by Encode for text the vocabulary does not cover
+const UnknownTokenID = -1
+
+// TextTokenizer splits text into the tokens of a model vocabulary
+type TextTokenizer interface {
+	// Name returns the tokenizer name, e.g. "cl100k_base"
+	Name() string
+
+	// Encode returns the token IDs of text
+	Encode(text string) []int
+
+	// Count returns the number of tokens in text
+	Count(text string) int
+}
+
+// textTokenizers maps model names to tokenizers; names ending with "*" are prefixes
+var textTokenizers = struct {
+	sync.RWMutex
+	models map[string]TextTokenizer
+}{models: make(map[string]TextTokenizer)}
+
+// RegisterTextTokenizer maps a model name to a tokenizer. A name ending with "*"
+// matches every model starting with the rest of it, e.g. "deepseek-*"; exact
+// names win over prefixes and longer prefixes over shorter ones.
+func RegisterTextTokenizer(model string, tokenizer TextTokenizer) {
+	textTokenizers.Lock()
+	defer textTokenizers.Unlock()
+	textTokenizers.models[model] = tokenizer
+}
+
+// UnregisterTextTokenizer removes a model name mapping
+func UnregisterTextTokenizer(model string) {
+	textTokenizers.Lock()
+	defer textTokenizers.Unlock()
+	delete(textTokenizers.models, model)
+}
+
+// TextTokenizerFor returns the tokenizer mapped to a model name
+func TextTokenizerFor(modelName string) (TextTokenizer, bool) {
+	textTokenizers.RLock()
+	defer textTokenizers.RUnlock()
+
+	if tokenizer, exists := textTokenizers.models[modelName]; exists {
+		return tokenizer, true
+	}
+
+	var match TextTokenizer
+	longest := -1
+	for pattern, tokenizer := range textTokenizers.models {
+		prefix, isPrefix := strings.CutSuffix(pattern, "*")
+		if isPrefix && len(prefix) > longest && strings.HasPrefix(modelName, prefix) {
+			match, longest = tokenizer, len(prefix)
+		}
+	}
+	return match, match != nil
+}
+
+// LoadTextTokenizer loads a tokenizer from the files of a tokenizer configuration
+func LoadTextTokenizer(name string, config *interfaces.TokenizerConfig) (TextTokenizer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("tokenizer %s has no configuration", name)
+	}
+	if config.Path == "" {
+		return nil, fmt.Errorf("tokenizer %s: path is required", name)
+	}
+
+	switch config.Type {
+	case TokenizerTypeTiktoken:
+		return LoadTiktokenFile(name, config.Path)
+	case TokenizerTypeBPE:
+		if config.MergesPath == "" {
+			return nil, fmt.Errorf("tokenizer %s: merges_path is required for bpe tokenizers", name)
+		}
+		return LoadBPEFiles(name, config.Path, config.MergesPath)
+	case TokenizerTypeSentencePiece:
+		return LoadSentencePieceFile(name, config.Path)
+	default:
+		return nil, fmt.Errorf("tokenizer %s: unsupported type %q (want %s, %s or %s)",
+			name, config.Type, TokenizerTypeTiktoken, TokenizerTypeBPE, TokenizerTypeSentencePiece)
+	}
+}
+
+// RegisteredTextTokenizers returns the mapped model names in sorted order
+func RegisteredTextTokenizers() []string {
+	textTokenizers.RLock()
+	defer textTokenizers.RUnlock()
+
+	models := make([]string, 0, len(textTokenizers.models))
+	for model := range textTokenizers.models {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// splitPretokens splits text the way the cl100k_base pattern does:
+//
+//	's|'t|'re|'ve|'m|'ll|'d | [^\r\n\p{L}\p{N}]?\p{L}+ | \p{N}{1,3} | ?[^\s\p{L}\p{N}]+[\r\n]* |
+//	\s*[\r\n]+ | \s+(?!\S) | \s+
+//
+// RE2 has no lookahead, so the alternatives are matched by hand, first match wins.
+func splitPretokens(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		n := matchPretoken(text)
+		pieces = append(pieces, text[:n])
+		text = text[n:]
+	}
+	return pieces
+}
+
+// contractions are the English suffixes split off by the cl100k_base pattern
+var contractions = []string{"s", "t", "re", "ve", "m", "ll", "d"}
+
+// matchPretoken returns the byte length of the first pre-token of s
+func matchPretoken(s string) int {
+	if s[0] == '\'' {
+		for _, suffix := range contractions {
+			if len(s) > len(suffix) && strings.EqualFold(s[1:1+len(suffix)], suffix) {
+				return 1 + len(suffix)
+			}
+		}
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+
+	// Letters, with one optional leading symbol or space
+	start := 0
+	if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\r' && r != '\n' {
+		start = size
+	}
+	if n := spanOf(s[start:], unicode.IsLetter, -1); n > 0 {
+		return start + n
+	}
+
+	// Up to three digits
+	if unicode.IsNumber(r) {
+		return spanOf(s, unicode.IsNumber, 3)
+	}
+
+	// Punctuation, with one optional leading space and trailing newlines
+	start = 0
+	if r == ' ' {
+		start = 1
+	}
+	if n := spanOf(s[start:], isSymbol, -1); n > 0 {
+		end := start + n
+		return end + spanOf(s[end:], isNewline, -1)
+	}
+
+	// Whitespace up to the last newline
+	spaces := spanOf(s, unicode.IsSpace, -1)
+	if spaces == 0 {
+		return size
+	}
+	if last := strings.LastIndexAny(s[:spaces], "\r\n"); last >= 0 {
+		return last + 1
+	}
+
+	// Whitespace, leaving the last space to the next word
+	if spaces < len(s) {
+		_, lastSize := utf8.DecodeLastRuneInString(s[:spaces])
+		if spaces > lastSize {
+			return spaces - lastSize
+		}
+	}
+	return spaces
+}
+
+// spanOf returns the byte length of the leading runes of s matching match,
+// at most limit runes when limit is positive
+func spanOf(s string, match func(rune) bool, limit int) int {
+	n, count := 0, 0
+	for n < len(s) && count != limit {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !match(r) {
+			break
+		}
+		n += size
+		count++
+	}
+	return n
+}
+
+// isSymbol reports whether r is neither whitespace, a letter nor a number
+func isSymbol(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// isNewline reports whether r is a carriage return or line feed
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
@@ -0,0 +1,282 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+// writeTestFile writes content to a file in a temporary directory
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// writeTiktokenFile writes a rank file with every single byte (rank = byte value)
+// followed by the given merged tokens
+func writeTiktokenFile(t *testing.T, tokens ...string) string {
+	t.Helper()
+	var content strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&content, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	for i, token := range tokens {
+		fmt.Fprintf(&content, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), 256+i)
+	}
+	return writeTestFile(t, "test.tiktoken", content.String())
+}
+
+func TestSplitPretokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello world", []string{"Hello", " world"}},
+		{"Привет, мир!", []string{"Привет", ",", " мир", "!"}},
+		{"don't", []string{"don", "'t"}},
+		{"12345", []string{"123", "45"}},
+		{"a  b", []string{"a", " ", " b"}},
+		{"x\n\ny", []string{"x", "\n\n", "y"}},
+		{"end  ", []string{"end", "  "}},
+		{"size: 42cm", []string{"size", ":", " ", "42", "cm"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := splitPretokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitPretokens(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBPETokenizer_Tiktoken(t *testing.T) {
+	tokenizer, err := LoadTiktokenFile("test", writeTiktokenFile(t, "he", "ll", "hell", "hello"))
+	if err != nil {
+		t.Fatalf("LoadTiktokenFile() error = %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"hello", []int{259}},
+		{"hellx", []int{258, 'x'}},
+		{" hello", []int{' ', 259}},
+		{"Привет", []int{0xD0, 0x9F, 0xD1, 0x80, 0xD0, 0xB8, 0xD0, 0xB2, 0xD0, 0xB5, 0xD1, 0x82}},
+	}
+
+	for _, tt := range tests {
+		if got := tokenizer.Encode(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Encode(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+		if got := tokenizer.Count(tt.text); got != len(tt.want) {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, len(tt.want))
+		}
+	}
+
+	if _, err := LoadTiktokenFile("broken", writeTestFile(t, "broken.tiktoken", "aGk=\n")); err == nil {
+		t.Error("expected an error for a line without rank")
+	}
+}
+
+func TestBPETokenizer_VocabAndMerges(t *testing.T) {
+	// Byte-level vocabulary: the space is written as "Ġ"
+	vocabPath := writeTestFile(t, "vocab.json", `{"h": 0, "e": 1, "l": 2, "o": 3, "Ġ": 4, "he": 5, "ll": 6, "hell": 7, "hello": 8, "Ġhello": 9}`)
+	mergesPath := writeTestFile(t, "merges.txt", "#version: 0.2\nh e\nl l\nhe ll\nhell o\n")
+
+	tokenizer, err := LoadBPEFiles("test-bpe", vocabPath, mergesPath)
+	if err != nil {
+		t.Fatalf("LoadBPEFiles() error = %v", err)
+	}
+
+	// "Ġhello" is in the vocabulary, but no merge produces it
+	if got := tokenizer.Encode(" hello hell"); !reflect.DeepEqual(got, []int{4, 8, 4, 7}) {
+		t.Errorf("Encode() = %v, want [4 8 4 7]", got)
+	}
+	if got := tokenizer.Encode("hex"); !reflect.DeepEqual(got, []int{5, UnknownTokenID}) {
+		t.Errorf("Encode(\"hex\") = %v, want [5 %d]", got, UnknownTokenID)
+	}
+}
+
+func TestSentencePieceTokenizer_Vocab(t *testing.T) {
+	vocab := "<unk>\t0\n<s>\t0\n</s>\t0\n▁\t-2\n▁при\t-1\nвет\t-1.5\nп\t-3\nр\t-3\nи\t-3\nв\t-3\nе\t-3\nт\t-3\n"
+	tokenizer, err := LoadSentencePieceFile("test-sp", writeTestFile(t, "test.vocab", vocab))
+	if err != nil {
+		t.Fatalf("LoadSentencePieceFile() error = %v", err)
+	}
+
+	if got := tokenizer.Encode("привет"); !reflect.DeepEqual(got, []int{4, 5}) {
+		t.Errorf("Encode(\"привет\") = %v, want [4 5]", got)
+	}
+	if got := tokenizer.Encode("привет!"); !reflect.DeepEqual(got, []int{4, 5, 0}) {
+		t.Errorf("Encode(\"привет!\") = %v, want the unknown piece last", got)
+	}
+
+	// With byte pieces, unknown characters fall back to their UTF-8 bytes
+	withBytes, err := LoadSentencePieceFile("test-sp", writeTestFile(t, "bytes.vocab", vocab+"<0xE2>\t0\n<0x84>\t0\n<0x96>\t0\n"))
+	if err != nil {
+		t.Fatalf("LoadSentencePieceFile() error = %v", err)
+	}
+	if got := withBytes.Encode("при №"); !reflect.DeepEqual(got, []int{4, 3, 12, 13, 14}) {
+		t.Errorf("Encode(\"при №\") = %v, want [4 3 12 13 14]", got)
+	}
+}
+
+func TestSentencePieceTokenizer_Model(t *testing.T) {
+	piece := func(text string, score float32, pieceType int) []byte {
+		var message []byte
+		message = binary.AppendUvarint(message, 1<<3|2)
+		message = binary.AppendUvarint(message, uint64(len(text)))
+		message = append(message, text...)
+		message = binary.AppendUvarint(message, 2<<3|5)
+		message = binary.LittleEndian.AppendUint32(message, math.Float32bits(score))
+		message = binary.AppendUvarint(message, 3<<3|0)
+		message = binary.AppendUvarint(message, uint64(pieceType))
+		return message
+	}
+	field := func(number int, value []byte) []byte {
+		data := binary.AppendUvarint(nil, uint64(number)<<3|2)
+		data = binary.AppendUvarint(data, uint64(len(value)))
+		return append(data, value...)
+	}
+
+	var model []byte
+	model = append(model, field(1, piece("<unk>", 0, pieceTypeUnknown))...)
+	model = append(model, field(1, piece("<s>", 0, pieceTypeControl))...)
+	model = append(model, field(1, piece("hello", -1, pieceTypeNormal))...)
+	model = append(model, field(1, piece("▁", -2, pieceTypeNormal))...)
+	model = append(model, field(1, piece("▁world", -1, pieceTypeNormal))...)
+	// normalizer_spec with add_dummy_prefix = false
+	model = append(model, field(3, []byte{3 << 3, 0})...)
+
+	tokenizer, err := LoadSentencePieceFile("test-model", writeTestFile(t, "test.model", string(model)))
+	if err != nil {
+		t.Fatalf("LoadSentencePieceFile() error = %v", err)
+	}
+
+	if got := tokenizer.Encode("hello world"); !reflect.DeepEqual(got, []int{2, 4}) {
+		t.Errorf("Encode() = %v, want [2 4]", got)
+	}
+	if got := tokenizer.Encode("<s>"); len(got) != 3 {
+		t.Errorf("control pieces must not match text, got %v", got)
+	}
+
+	if _, err := LoadSentencePieceFile("broken", writeTestFile(t, "broken.model", "\x0a\x05ab")); err == nil {
+		t.Error("expected an error for a truncated model")
+	}
+}
+
+func TestTextTokenizerFor(t *testing.T) {
+	exact := NewBPETokenizer("exact", map[string]int{}, nil)
+	short := NewBPETokenizer("short", map[string]int{}, nil)
+	long := NewBPETokenizer("long", map[string]int{}, nil)
+
+	RegisterTextTokenizer("test-model", exact)
+	RegisterTextTokenizer("test-*", short)
+	RegisterTextTokenizer("test-model-*", long)
+	t.Cleanup(func() {
+		UnregisterTextTokenizer("test-model")
+		UnregisterTextTokenizer("test-*")
+		UnregisterTextTokenizer("test-model-*")
+	})
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"test-model", "exact"},
+		{"test-model-mini", "long"},
+		{"test-other", "short"},
+		{"other", ""},
+	}
+
+	for _, tt := range tests {
+		tokenizer, exists := TextTokenizerFor(tt.model)
+		got := ""
+		if exists {
+			got = tokenizer.Name()
+		}
+		if got != tt.want {
+			t.Errorf("TextTokenizerFor(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestLoadTextTokenizer(t *testing.T) {
+	path := writeTiktokenFile(t)
+
+	tokenizer, err := LoadTextTokenizer("cl100k_base", &interfaces.TokenizerConfig{Type: TokenizerTypeTiktoken, Path: path})
+	if err != nil {
+		t.Fatalf("LoadTextTokenizer() error = %v", err)
+	}
+	if tokenizer.Name() != "cl100k_base" {
+		t.Errorf("Name() = %q, want cl100k_base", tokenizer.Name())
+	}
+
+	invalid := []*interfaces.TokenizerConfig{
+		nil,
+		{Type: TokenizerTypeTiktoken},
+		{Type: TokenizerTypeBPE, Path: path},
+		{Type: "wordpiece", Path: path},
+	}
+	for _, config := range invalid {
+		if _, err := LoadTextTokenizer("invalid", config); err == nil {
+			t.Errorf("expected an error for %+v", config)
+		}
+	}
+}
+
+func TestTokenizer_CountTokensWithTextTokenizer(t *testing.T) {
+	tokenizer := NewTokenizer(interfaces.NewNoOpLogger())
+	text := "Платье из вискозы"
+
+	heuristic, err := tokenizer.CountTokens(text, ProviderDeepSeek, "deepseek-chat")
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+
+	textTokenizer, err := LoadTiktokenFile("bytes", writeTiktokenFile(t))
+	if err != nil {
+		t.Fatalf("LoadTiktokenFile() error = %v", err)
+	}
+	RegisterTextTokenizer("deepseek-chat", textTokenizer)
+	t.Cleanup(func() { UnregisterTextTokenizer("deepseek-chat") })
+
+	exact, err := tokenizer.CountTokens(text, ProviderDeepSeek, "deepseek-chat")
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	if exact != len(text) || exact == heuristic {
+		t.Errorf("CountTokens() = %d, want %d bytes (heuristic gave %d)", exact, len(text), heuristic)
+	}
+
+	// Models with a tokenizer but no token configuration can be counted
+	RegisterTextTokenizer("unconfigured-model", textTokenizer)
+	t.Cleanup(func() { UnregisterTextTokenizer("unconfigured-model") })
+
+	usage, err := tokenizer.CountRequestTokens(&interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "abc"}},
+		}},
+	}, ProviderCustom, "unconfigured-model")
+	if err != nil {
+		t.Fatalf("CountRequestTokens() error = %v", err)
+	}
+	if usage.PromptTokens != 5 {
+		t.Errorf("PromptTokens = %d, want 3 text tokens and 2 role tokens", usage.PromptTokens)
+	}
+}
@@ -3,7 +3,7 @@
 // with model-specific configurations, cost estimation, and usage validation.
 //
 // Key Features:
-// - Multi-provider token counting (DeepSeek, Z.AI, OpenAI)
+// - Multi-provider token counting (DeepSeek, Z.AI, OpenAI, Anthropic, Gemini, Ollama)
 // - Model-specific token configurations and limits
 // - Cost estimation for different models and providers
 // - Token usage validation and limit checking
@@ -11,16 +11,23 @@
 // - Fashion-specific token optimizations
 //
 // Token Counting Methods:
+// - Exact: BPE or SentencePiece tokenizer mapped to the model (see text_tokenizer.go)
 // - Character-based: tokens = chars * tokens_per_char
 // - Word-based: tokens = words * tokens_per_word
 // - Weighted average: Uses higher estimate for safety
 // - Content-specific: Different rates for text, media, tools
+// - Heuristics apply only to models without a tokenizer; TokenCalibrator
+//   measures the drift of either method against provider usage
 //
 // Model Configurations:
 // - DeepSeek: 4000-8000 tokens, no vision support
 // - Z.AI GLM-4.6V: 2000 tokens, 85 token vision cost
 // - Z.AI GLM-4.6V-Flash: 4000 tokens, 65 token vision cost
 // - OpenAI GPT-4: 8000 tokens, vision support available
+// - OpenAI GPT-4o, GPT-4o mini: 128K token context, 85 token vision cost
+// - Anthropic Claude 4.x: 200K token context, ~1600 token vision cost per image
+// - Gemini 2.5: 1M token context, 258 token vision cost per image
+// - Ollama Llama 3.1: 128K token context; local models cost nothing
 //
 // Usage Examples:
 //   tokenizer := NewTokenizer(logger)
@@ -143,6 +150,93 @@ var defaultTokenConfigs = map[Provider]map[string]ModelTokenConfig{
 			VisionTokenCost:     85,
 			OverheadTokens:      20,
 		},
+		"gpt-4o": {
+			ModelName:           "gpt-4o",
+			MaxTokens:          128000,
+			TokensPerCharacter:  0.25,
+			TokensPerWord:       1.3,
+			SupportsVision:      true,
+			VisionTokenCost:     85,
+			OverheadTokens:      10,
+		},
+		"gpt-4o-mini": {
+			ModelName:           "gpt-4o-mini",
+			MaxTokens:          128000,
+			TokensPerCharacter:  0.25,
+			TokensPerWord:       1.3,
+			SupportsVision:      true,
+			VisionTokenCost:     85,
+			OverheadTokens:      10,
+		},
+	},
+	ProviderAnthropic: {
+		"claude-sonnet-4-5": {
+			ModelName:           "claude-sonnet-4-5",
+			MaxTokens:          200000,
+			TokensPerCharacter:  0.3,
+			TokensPerWord:       1.4,
+			SupportsVision:      true,
+			VisionTokenCost:     1600,
+			OverheadTokens:      10,
+		},
+		"claude-opus-4-1": {
+			ModelName:           "claude-opus-4-1",
+			MaxTokens:          200000,
+			TokensPerCharacter:  0.3,
+			TokensPerWord:       1.4,
+			SupportsVision:      true,
+			VisionTokenCost:     1600,
+			OverheadTokens:      10,
+		},
+		"claude-haiku-4-5": {
+			ModelName:           "claude-haiku-4-5",
+			MaxTokens:          200000,
+			TokensPerCharacter:  0.3,
+			TokensPerWord:       1.4,
+			SupportsVision:      true,
+			VisionTokenCost:     1600,
+			OverheadTokens:      10,
+		},
+	},
+	ProviderOllama: {
+		"llama3.1": {
+			ModelName:           "llama3.1",
+			MaxTokens:          128000,
+			TokensPerCharacter:  0.25,
+			TokensPerWord:       1.3,
+			SupportsVision:      false,
+			VisionTokenCost:     0,
+			OverheadTokens:      10,
+		},
+	},
+	ProviderGemini: {
+		"gemini-2.5-pro": {
+			ModelName:           "gemini-2.5-pro",
+			MaxTokens:          1048576,
+			TokensPerCharacter:  0.25,
+			TokensPerWord:       1.3,
+			SupportsVision:      true,
+			VisionTokenCost:     258,
+			OverheadTokens:      10,
+		},
+		"gemini-2.5-flash": {
+			ModelName:           "gemini-2.5-flash",
+			MaxTokens:          1048576,
+			TokensPerCharacter:  0.25,
+			TokensPerWord:       1.3,
+			SupportsVision:      true,
+			VisionTokenCost:     258,
+			OverheadTokens:      10,
+		},
+		"gemini-2.5-flash-lite": {
+			ModelName:           "gemini-2.5-flash-lite",
+			MaxTokens:          1048576,
+			TokensPerCharacter:  0.25,
+			TokensPerWord:       1.3,
+			SupportsVision:      true,
+			VisionTokenCost:     258,
+			OverheadTokens:      10,
+		},
 	},
 }
 
@@ -152,6 +246,17 @@ func (t *Tokenizer) CountTokens(text string, provider Provider, modelName string
 		return 0, nil
 	}
 
+	// Count exactly when the model has a tokenizer (see text_tokenizer.go)
+	if textTokenizer, exists := TextTokenizerFor(modelName); exists {
+		tokens := textTokenizer.Count(text)
+		t.logger.Debug("Token count computed",
+			"model", modelName,
+			"provider", provider,
+			"tokenizer", textTokenizer.Name(),
+			"final_tokens", tokens)
+		return tokens, nil
+	}
+
 	config, err := t.getModelConfig(provider, modelName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get model config: %w", err)
@@ -185,7 +290,7 @@ func (t *Tokenizer) CountTokens(text string, provider Provider, modelName string
 
 // CountMessageTokens counts tokens in a message for a specific model
 func (t *Tokenizer) CountMessageTokens(message *interfaces.PonchoMessage, provider Provider, modelName string) (int, error) {
-	config, err := t.getModelConfig(provider, modelName)
+	config, err := t.countingConfig(provider, modelName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get model config: %w", err)
 	}
@@ -218,7 +323,7 @@ func (t *Tokenizer) CountMessageTokens(message *interfaces.PonchoMessage, provid
 
 // CountRequestTokens counts tokens in a request for a specific model
 func (t *Tokenizer) CountRequestTokens(request *interfaces.PonchoModelRequest, provider Provider, modelName string) (*interfaces.PonchoUsage, error) {
-	config, err := t.getModelConfig(provider, modelName)
+	config, err := t.countingConfig(provider, modelName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get model config: %w", err)
 	}
@@ -307,9 +412,26 @@ func (t *Tokenizer) EstimateCost(usage *interfaces.PonchoUsage, provider Provide
 		ProviderOpenAI: {
 			"gpt-4":               0.0300, // $0.0300 per 1K tokens
 			"gpt-4-vision-preview": 0.0500, // $0.0500 per 1K tokens
+			"gpt-4o":               0.0050, // $0.0050 per 1K tokens
+			"gpt-4o-mini":          0.0003, // $0.0003 per 1K tokens
+		},
+		ProviderAnthropic: {
+			"claude-sonnet-4-5": 0.0060, // $0.0060 per 1K tokens
+			"claude-opus-4-1":   0.0300, // $0.0300 per 1K tokens
+			"claude-haiku-4-5":  0.0020, // $0.0020 per 1K tokens
+		},
+		ProviderGemini: {
+			"gemini-2.5-pro":        0.0050, // $0.0050 per 1K tokens
+			"gemini-2.5-flash":      0.0010, // $0.0010 per 1K tokens
+			"gemini-2.5-flash-lite": 0.0002, // $0.0002 per 1K tokens
 		},
 	}
 
+	// Ollama runs models locally, whichever model it is
+	if provider == ProviderOllama {
+		return 0, nil
+	}
+
 	providerCosts, exists := costPer1K[provider]
 	if !exists {
 		return 0, fmt.Errorf("no cost information available for provider: %s", provider)
@@ -347,6 +469,19 @@ func (t *Tokenizer) getModelConfig(provider Provider, modelName string) (*ModelT
 	return &config, nil
 }
 
+// countingConfig returns the token configuration used for counting. Models with
+// a tokenizer but no configuration are counted without overhead tokens.
+func (t *Tokenizer) countingConfig(provider Provider, modelName string) (*ModelTokenConfig, error) {
+	config, err := t.getModelConfig(provider, modelName)
+	if err != nil {
+		if _, exists := TextTokenizerFor(modelName); exists {
+			return &ModelTokenConfig{ModelName: modelName}, nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
 // countWords counts words in text (simple implementation)
 func (t *Tokenizer) countWords(text string) int {
 	// Remove punctuation and split on whitespace
@@ -369,7 +504,7 @@ func (t *Tokenizer) countContentPartTokens(part *interfaces.PonchoContentPart, p
 	case interfaces.PonchoContentTypeText:
 		return t.CountTokens(part.Text, provider, modelName)
 	case interfaces.PonchoContentTypeMedia:
-		config, err := t.getModelConfig(provider, modelName)
+		config, err := t.countingConfig(provider, modelName)
 		if err != nil {
 			return 0, err
 		}
@@ -406,6 +541,30 @@ func (t *Tokenizer) countContentPartTokens(part *interfaces.PonchoContentPart, p
 			tokens += argsTokens
 		}
 		return tokens, nil
+	case interfaces.PonchoContentTypeToolResult:
+		if part.ToolResult == nil {
+			return 0, nil
+		}
+		// Count tokens for tool result
+		tokens := 5 // Base tool result overhead
+		if part.ToolResult.ToolCallID != "" {
+			idTokens, err := t.CountTokens(part.ToolResult.ToolCallID, provider, modelName)
+			if err != nil {
+				return 0, err
+			}
+			tokens += idTokens
+		}
+		if content := ToolResultText(part.ToolResult); content != "" {
+			contentTokens, err := t.CountTokens(content, provider, modelName)
+			if err != nil {
+				return 0, err
+			}
+			tokens += contentTokens
+		}
+		return tokens, nil
+	case interfaces.PonchoContentTypeReasoning:
+		// Reasoning is billed as output; providers drop it from later requests
+		return t.CountTokens(part.Text, provider, modelName)
 	default:
 		return 0, fmt.Errorf("unsupported content type: %s", part.Type)
 	}
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+)
+
+func TestTokenizer_DefaultModels(t *testing.T) {
+	tokenizer := NewTokenizer(interfaces.NewNoOpLogger())
+	usage := &interfaces.PonchoUsage{PromptTokens: 800, CompletionTokens: 200, TotalTokens: 1000}
+
+	for provider, model := range map[Provider]string{
+		ProviderDeepSeek:  DeepSeekDefaultModel,
+		ProviderZAI:       ZAIDefaultModel,
+		ProviderOpenAI:    OpenAIDefaultModel,
+		ProviderAnthropic: AnthropicDefaultModel,
+		ProviderGemini:    GeminiDefaultModel,
+		ProviderOllama:    OllamaDefaultModel,
+	} {
+		if _, err := tokenizer.GetModelConfig(provider, model); err != nil {
+			t.Errorf("%s/%s: %v", provider, model, err)
+		}
+
+		cost, err := tokenizer.EstimateCost(usage, provider, model)
+		if err != nil {
+			t.Errorf("%s/%s: %v", provider, model, err)
+		}
+		if provider != ProviderOllama && cost <= 0 {
+			t.Errorf("%s/%s: expected a positive cost, got %v", provider, model, cost)
+		}
+	}
+
+	// Local models cost nothing, configured or not
+	if cost, err := tokenizer.EstimateCost(usage, ProviderOllama, "qwen2.5vl"); err != nil || cost != 0 {
+		t.Errorf("EstimateCost(ollama) = %v, %v, want 0", cost, err)
+	}
+}
@@ -3,7 +3,7 @@
 // across all model implementations with provider-specific configurations and defaults.
 //
 // Key Type Categories:
-// - Provider Types: DeepSeek, Z.AI, OpenAI, Custom
+// - Provider Types: DeepSeek, Z.AI, OpenAI, Ollama, Anthropic, Gemini, Fake, Custom
 // - Model Types: Text, Vision, Multimodal, Embedding
 // - Configuration Types: HTTP, Retry, Validation, Model capabilities
 // - Error Types: Standardized error codes and handling
@@ -13,6 +13,10 @@
 // - DeepSeek: OpenAI-compatible, text generation focus
 // - Z.AI: Custom API, vision and multimodal support
 // - OpenAI: Standard OpenAI API compatibility
+// - Ollama: Local models through Ollama's native /api/chat protocol
+// - Anthropic: Messages API with top-level system prompt and content blocks
+// - Gemini: generateContent REST API with inline image data
+// - Fake: scripted offline responses for tests
 // - Custom: Extensible for new providers
 //
 // Configuration Management:
@@ -49,6 +53,10 @@ const (
 	ProviderDeepSeek Provider = "deepseek"
 	ProviderZAI     Provider = "zai"
 	ProviderOpenAI   Provider = "openai"
+	ProviderOllama   Provider = "ollama"
+	ProviderAnthropic Provider = "anthropic"
+	ProviderGemini   Provider = "gemini"
+	ProviderFake     Provider = "fake"
 	ProviderCustom   Provider = "custom"
 )
 
@@ -100,6 +108,21 @@ const (
 	ResponseFormatJSONObject ResponseFormat = "json_object"
 )
 
+// RequestResponseFormat returns the response format a request selects through its
+// ResponseFormat field, overriding the model's configured format, if it selects a
+// supported one
+func RequestResponseFormat(req *interfaces.PonchoModelRequest) (ResponseFormat, bool) {
+	if req == nil {
+		return "", false
+	}
+
+	switch format := ResponseFormat(req.ResponseFormat); format {
+	case ResponseFormatText, ResponseFormatJSONObject:
+		return format, true
+	}
+	return "", false
+}
+
 // ThinkingType represents thinking mode options
 type ThinkingType string
 
@@ -122,10 +145,14 @@ type CommonModelConfig struct {
 	FrequencyPenalty *float32        `json:"frequency_penalty,omitempty"`
 	PresencePenalty  *float32        `json:"presence_penalty,omitempty"`
 	Stop             interface{}     `json:"stop,omitempty"`
+	Seed             *int            `json:"seed,omitempty"`
 	ResponseFormat   *ResponseFormat `json:"response_format,omitempty"`
 	Thinking         *ThinkingType   `json:"thinking,omitempty"`
 	LogProbs         bool            `json:"logprobs,omitempty"`
 	TopLogProbs      *int            `json:"top_logprobs,omitempty"`
+	// Client-side rate limits; nil keeps the provider default, zero disables the limit
+	RequestsPerMinute *int `json:"requests_per_minute,omitempty"`
+	TokensPerMinute   *int `json:"tokens_per_minute,omitempty"`
 }
 
 // ModelCapabilities represents what a model can do
@@ -163,6 +190,8 @@ type TokenUsage struct {
 type RateLimitInfo struct {
 	RequestsPerMinute int           `json:"requests_per_minute"`
 	TokensPerMinute   int           `json:"tokens_per_minute"`
+	RemainingRequests *int           `json:"remaining_requests,omitempty"`
+	RemainingTokens   *int           `json:"remaining_tokens,omitempty"`
 	RetryAfter        *time.Duration `json:"retry_after,omitempty"`
 	ResetTime         *time.Time     `json:"reset_time,omitempty"`
 }
@@ -321,6 +350,37 @@ const (
 	ZAIEndpoint       = "/chat/completions"
 	ZAIVisionModel    = "glm-4.6v"
 
+	// OpenAI and OpenAI-compatible servers
+	OpenAIDefaultBaseURL = "https://api.openai.com/v1"
+	OpenAIDefaultModel   = "gpt-4o-mini"
+	OpenAIEndpoint       = "/chat/completions"
+
+	OpenAIDefaultEmbeddingModel = "text-embedding-3-small"
+	OpenAIEmbeddingsEndpoint    = "/embeddings"
+
+	// Ollama
+	OllamaDefaultBaseURL = "http://localhost:11434"
+	OllamaDefaultModel   = "llama3.1"
+	OllamaChatEndpoint   = "/api/chat"
+
+	OllamaDefaultEmbeddingModel = "nomic-embed-text"
+	OllamaEmbedEndpoint         = "/api/embed"
+
+	// Anthropic
+	AnthropicDefaultBaseURL = "https://api.anthropic.com/v1"
+	AnthropicDefaultModel   = "claude-sonnet-4-5"
+	AnthropicEndpoint       = "/messages"
+	AnthropicAPIVersion     = "2023-06-01"
+
+	// Google Gemini
+	GeminiDefaultBaseURL    = "https://generativelanguage.googleapis.com/v1beta"
+	GeminiDefaultModel      = "gemini-2.5-flash"
+	GeminiGenerateEndpoint  = ":generateContent"
+	GeminiStreamEndpoint    = ":streamGenerateContent"
+
+	// Fake (scripted, offline)
+	FakeDefaultModel = "fake-model"
+
 	// Common headers
 	HeaderContentType     = "Content-Type"
 	HeaderAccept         = "Accept"
@@ -463,7 +463,13 @@ func (v *Validator) validateContentPart(part *interfaces.PonchoContentPart, msgI
 		})
 	} else {
 		// Validate type value
-		validTypes := []string{"text", "media", "tool"}
+		validTypes := []string{
+			string(interfaces.PonchoContentTypeText),
+			string(interfaces.PonchoContentTypeMedia),
+			string(interfaces.PonchoContentTypeTool),
+			string(interfaces.PonchoContentTypeToolResult),
+			string(interfaces.PonchoContentTypeReasoning),
+		}
 		if !v.contains(validTypes, string(part.Type)) {
 			result.Valid = false
 			result.Errors = append(result.Errors, ValidationError{
@@ -544,6 +550,31 @@ func (v *Validator) validateContentPart(part *interfaces.PonchoContentPart, msgI
 				})
 			}
 		}
+	case interfaces.PonchoContentTypeToolResult:
+		if part.ToolResult == nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fieldName + ".tool_result",
+				Message: "tool result content is required for tool_result type",
+				Rule:    "required",
+			})
+		} else if part.ToolResult.ToolCallID == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fieldName + ".tool_result.tool_call_id",
+				Message: "tool call ID is required",
+				Rule:    "required",
+			})
+		}
+	case interfaces.PonchoContentTypeReasoning:
+		if part.Text == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fieldName + ".text",
+				Message: "text content is required for reasoning type",
+				Rule:    "required",
+			})
+		}
 	}
 }
 
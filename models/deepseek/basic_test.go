@@ -103,3 +103,132 @@ func TestRequestValidation(t *testing.T) {
 		t.Error("Request with no messages should produce error")
 	}
 }
+
+// Test tool results and reasoning in request and response conversion
+func TestToolResultAndReasoningConversion(t *testing.T) {
+	model := NewDeepSeekModel()
+	if err := model.Initialize(context.Background(), map[string]interface{}{"api_key": "test-key"}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	req := &interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{
+			{
+				Role: interfaces.PonchoRoleAssistant,
+				Content: []*interfaces.PonchoContentPart{
+					{Type: interfaces.PonchoContentTypeReasoning, Text: "The user wants the weather."},
+					{Type: interfaces.PonchoContentTypeTool, Tool: &interfaces.PonchoToolPart{ID: "call_1", Name: "get_weather"}},
+				},
+			},
+			{
+				Role: interfaces.PonchoRoleTool,
+				Content: []*interfaces.PonchoContentPart{{
+					Type:       interfaces.PonchoContentTypeToolResult,
+					ToolResult: &interfaces.PonchoToolResultPart{ToolCallID: "call_1", Content: map[string]interface{}{"temp": 21}},
+				}},
+			},
+		},
+	}
+
+	deepseekReq, err := model.convertRequest(req)
+	if err != nil {
+		t.Fatalf("convertRequest() error = %v", err)
+	}
+	if got := deepseekReq.Messages[0].Content; got != "" {
+		t.Errorf("reasoning must not be sent back, got content %q", got)
+	}
+	if got := deepseekReq.Messages[1]; got.ToolCallID != "call_1" || got.Content != `{"temp":21}` {
+		t.Errorf("unexpected tool message: %+v", got)
+	}
+
+	resp, err := model.convertResponse(&DeepSeekResponse{
+		Choices: []DeepSeekChoice{{
+			Message:      DeepSeekMessage{Role: "assistant", Content: "21 degrees", ReasoningContent: "Read the tool result."},
+			FinishReason: "stop",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("convertResponse() error = %v", err)
+	}
+	if len(resp.Message.Content) != 2 {
+		t.Fatalf("expected text and reasoning parts, got %d", len(resp.Message.Content))
+	}
+	if part := resp.Message.Content[0]; part.Type != interfaces.PonchoContentTypeText || part.Text != "21 degrees" {
+		t.Errorf("unexpected first part: %+v", part)
+	}
+	if part := resp.Message.Content[1]; part.Type != interfaces.PonchoContentTypeReasoning || part.Text != "Read the tool result." {
+		t.Errorf("unexpected reasoning part: %+v", part)
+	}
+}
+
+// Test the per-request JSON mode set through request metadata
+func TestResponseFormat(t *testing.T) {
+	model := NewDeepSeekModel()
+	if err := model.Initialize(context.Background(), map[string]interface{}{"api_key": "test-key"}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	req := &interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Return JSON"}},
+		}},
+		ResponseFormat: interfaces.PonchoResponseFormatJSONObject,
+	}
+
+	deepseekReq, err := model.convertRequest(req)
+	if err != nil {
+		t.Fatalf("convertRequest() error = %v", err)
+	}
+	if deepseekReq.ResponseFormat == nil || deepseekReq.ResponseFormat.Type != DeepSeekResponseFormatJSONObject {
+		t.Errorf("ResponseFormat = %+v, want json_object", deepseekReq.ResponseFormat)
+	}
+	if !model.GetCapabilities().JSONMode {
+		t.Error("expected the JSONMode capability")
+	}
+}
+
+// Test per-request generation parameters over custom_params defaults
+func TestGenerationParams(t *testing.T) {
+	model := NewDeepSeekModel()
+	if err := model.Initialize(context.Background(), map[string]interface{}{
+		"api_key": "test-key",
+		"custom_params": map[string]interface{}{
+			"top_p":           0.9,
+			"response_format": "json_object",
+			"thinking":        true,
+		},
+	}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	thinking := false
+	req := &interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Describe the dress"}},
+		}},
+		ResponseFormat: interfaces.PonchoResponseFormatText,
+		Thinking:       &thinking,
+	}
+
+	deepseekReq, err := model.convertRequest(req)
+	if err != nil {
+		t.Fatalf("convertRequest() error = %v", err)
+	}
+	if deepseekReq.TopP == nil || *deepseekReq.TopP != 0.9 {
+		t.Errorf("TopP = %v, want the configured 0.9", deepseekReq.TopP)
+	}
+	if deepseekReq.ResponseFormat == nil || deepseekReq.ResponseFormat.Type != DeepSeekResponseFormatText {
+		t.Errorf("ResponseFormat = %+v, want the requested text", deepseekReq.ResponseFormat)
+	}
+	if deepseekReq.Thinking == nil || deepseekReq.Thinking.Type != DeepSeekThinkingDisabled {
+		t.Errorf("Thinking = %+v, want the requested disabled", deepseekReq.Thinking)
+	}
+
+	seed := 1
+	req.Seed = &seed
+	if _, err := model.convertRequest(req); common.GetErrorCode(err) != common.ErrorCodeUnsupportedParameter {
+		t.Errorf("convertRequest() error = %v, want UNSUPPORTED_PARAMETER for seed", err)
+	}
+}
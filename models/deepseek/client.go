@@ -194,11 +194,15 @@ import (
 
 // DeepSeekClient represents a client for DeepSeek API
 type DeepSeekClient struct {
-	httpClient *common.HTTPClient
-	config     *common.CommonModelConfig
-	logger     interfaces.Logger
-	apiKey     string
-	baseURL    string
+	httpClient  *common.HTTPClient
+	config      *common.CommonModelConfig
+	logger      interfaces.Logger
+	apiKey      string
+	baseURL     string
+	rateLimiter *common.RateLimiter
+	tokenizer   *common.Tokenizer
+
+	releaseRateLimiter func()
 }
 
 // NewDeepSeekClient creates a new DeepSeek client
@@ -237,12 +241,14 @@ func NewDeepSeekClient(config *common.CommonModelConfig, logger interfaces.Logge
 		logger:     logger,
 		apiKey:     config.APIKey,
 		baseURL:    config.BaseURL,
+		tokenizer:  common.NewTokenizer(logger),
 	}
 
 	// Use default base URL if not provided
 	if client.baseURL == "" {
 		client.baseURL = common.DeepSeekDefaultBaseURL
 	}
+	client.claimRateLimiter()
 
 	logger.Info("DeepSeek client created",
 		"model", config.Model,
@@ -279,6 +285,9 @@ func validateConfig(config *common.CommonModelConfig) error {
 
 // Close closes the DeepSeek client and cleans up resources
 func (c *DeepSeekClient) Close() error {
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
 	if c.httpClient != nil {
 		return c.httpClient.Close()
 	}
@@ -307,6 +316,8 @@ func (c *DeepSeekClient) UpdateConfig(config *common.CommonModelConfig) error {
 		c.baseURL = config.BaseURL
 	}
 
+	c.claimRateLimiter()
+
 	c.logger.Info("DeepSeek client configuration updated",
 		"model", config.Model,
 		"base_url", c.baseURL)
@@ -399,11 +410,24 @@ func (c *DeepSeekClient) IsHealthy(ctx context.Context) error {
 
 // GetRateLimitInfo returns current rate limit information
 func (c *DeepSeekClient) GetRateLimitInfo() *common.RateLimitInfo {
-	// DeepSeek rate limits (example values - should be updated based on actual API docs)
-	return &common.RateLimitInfo{
-		RequestsPerMinute: 60,
-		TokensPerMinute:   100000,
+	// Shared by the clients of the account; adapted to the rate limit headers of responses
+	return c.rateLimiter.Info()
+}
+
+// claimRateLimiter switches to the rate limiter of the client's account with the
+// limits of its config, releasing the previous one
+func (c *DeepSeekClient) claimRateLimiter() {
+	limiter, release := common.ConfiguredRateLimiter(c.config, c.baseURL, c.apiKey)
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
 	}
+	c.rateLimiter, c.releaseRateLimiter = limiter, release
+	c.httpClient.SetRateLimiter(limiter)
+}
+
+// rateLimitContext returns ctx carrying the estimated tokens of req for the rate limiter
+func (c *DeepSeekClient) rateLimitContext(ctx context.Context, req *interfaces.PonchoModelRequest) context.Context {
+	return common.WithRateLimitTokens(ctx, common.EstimateRateLimitTokens(c.tokenizer, req, c.config.Provider, c.config.Model))
 }
 
 // PrepareRequestMetrics creates metrics for a request
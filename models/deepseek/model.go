@@ -27,6 +27,7 @@ func NewDeepSeekModel() *DeepSeekModel {
 		Tools:     true,
 		Vision:    false,
 		System:    true,
+		JSONMode:  true,
 	})
 
 	return &DeepSeekModel{
@@ -92,7 +93,8 @@ func (m *DeepSeekModel) Generate(ctx context.Context, req *interfaces.PonchoMode
 		return nil, fmt.Errorf("failed to convert request: %w", err)
 	}
 
-	// Make API call
+	// Make API call within the provider's rate limits
+	ctx = m.client.rateLimitContext(ctx, req)
 	deepseekResp, err := m.createChatCompletion(ctx, deepseekReq)
 	duration := time.Since(startTime)
 
@@ -182,8 +184,16 @@ func (m *DeepSeekModel) GenerateStreaming(ctx context.Context, req *interfaces.P
 		return fmt.Errorf("failed to convert request: %w", err)
 	}
 
-	// Make streaming API call
+	// Make streaming API call within the provider's rate limits
+	ctx = m.client.rateLimitContext(ctx, req)
+	toolCalls := newToolCallAccumulator()
 	err = m.createChatCompletionStream(ctx, deepseekReq, func(streamResp *DeepSeekStreamResponse) error {
+		// Tool call arguments arrive in fragments; whole calls are sent with the finish reason
+		if len(streamResp.Choices) > 0 {
+			toolCalls.add(streamResp.Choices[0].Delta.ToolCalls)
+			streamResp.Choices[0].Delta.ToolCalls = nil
+		}
+
 		// Convert stream chunk
 		chunk, err := m.convertStreamChunk(streamResp)
 		if err != nil {
@@ -193,6 +203,10 @@ func (m *DeepSeekModel) GenerateStreaming(ctx context.Context, req *interfaces.P
 			return err
 		}
 
+		if chunk.FinishReason != "" && chunk.Delta != nil {
+			chunk.Delta.Content = append(chunk.Delta.Content, toolCalls.flush()...)
+		}
+
 		// Call callback
 		if err := callback(chunk); err != nil {
 			m.GetLogger().Error("Stream callback error",
@@ -204,6 +218,18 @@ func (m *DeepSeekModel) GenerateStreaming(ctx context.Context, req *interfaces.P
 		return nil
 	})
 
+	// A stream can end with [DONE] or EOF and no finish reason; send the calls it left
+	if err == nil && len(toolCalls.calls) > 0 {
+		err = callback(&interfaces.PonchoStreamChunk{
+			Delta: &interfaces.PonchoMessage{
+				Role:    interfaces.PonchoRoleAssistant,
+				Content: toolCalls.flush(),
+			},
+			FinishReason: interfaces.PonchoFinishReasonTool,
+			Done:         true,
+		})
+	}
+
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -234,6 +260,15 @@ func (m *DeepSeekModel) GenerateStreaming(ctx context.Context, req *interfaces.P
 	return nil
 }
 
+// HealthCheck implements interfaces.HealthChecker
+func (m *DeepSeekModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("DeepSeek model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
 // Shutdown shuts down DeepSeek model
 func (m *DeepSeekModel) Shutdown(ctx context.Context) error {
 	if m.client != nil {
@@ -283,6 +318,15 @@ func (m *DeepSeekModel) convertConfig(config map[string]interface{}) (*common.Co
 		commonConfig.BaseURL = baseURL
 	}
 
+	if customParams, ok := config["custom_params"].(map[string]interface{}); ok {
+		if err := common.RateLimitFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+		if err := common.GenerationDefaultsFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid custom_params: %w", err)
+		}
+	}
+
 	// Validate required fields
 	if commonConfig.APIKey == "" {
 		return nil, fmt.Errorf("api_key is required")
@@ -293,12 +337,38 @@ func (m *DeepSeekModel) convertConfig(config map[string]interface{}) (*common.Co
 
 // convertRequest converts Poncho request to DeepSeek request format
 func (m *DeepSeekModel) convertRequest(req *interfaces.PonchoModelRequest) (*DeepSeekRequest, error) {
+	config := m.client.GetConfig()
+
+	// The chat completions API has no seed
+	if err := common.CheckRequestParams(req, string(common.ProviderDeepSeek), config.Model, common.ParamSeed); err != nil {
+		return nil, err
+	}
+	params, err := common.ResolveGenerationParams(req, config)
+	if err != nil {
+		return nil, err
+	}
+
 	deepseekReq := &DeepSeekRequest{
-		Model:       m.client.GetConfig().Model,
-		Messages:    make([]DeepSeekMessage, 0),
-		MaxTokens:   req.MaxTokens,
-		Temperature: req.Temperature,
-		Stream:      req.Stream,
+		Model:            config.Model,
+		Messages:         make([]DeepSeekMessage, 0),
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		Stream:           req.Stream,
+		TopP:             params.TopP,
+		FrequencyPenalty: params.FrequencyPenalty,
+		PresencePenalty:  params.PresencePenalty,
+		LogProbs:         params.LogProbs,
+		TopLogProbs:      params.TopLogProbs,
+	}
+
+	if len(params.Stop) > 0 {
+		deepseekReq.Stop = params.Stop
+	}
+	if params.ResponseFormat != nil {
+		deepseekReq.ResponseFormat = &DeepSeekResponseFormat{Type: string(*params.ResponseFormat)}
+	}
+	if params.Thinking != nil {
+		deepseekReq.Thinking = &DeepSeekThinking{Type: string(*params.Thinking)}
 	}
 
 	// Convert tools
@@ -328,11 +398,19 @@ func (m *DeepSeekModel) convertRequest(req *interfaces.PonchoModelRequest) (*Dee
 			deepseekMsg.Name = msg.Name
 		}
 
+		if msg.Role == interfaces.PonchoRoleTool {
+			deepseekMsg.ToolCallID = common.ToolCallID(msg)
+		}
+
 		// Convert content parts
 		for _, part := range msg.Content {
 			switch part.Type {
 			case interfaces.PonchoContentTypeText:
 				deepseekMsg.Content += part.Text
+			case interfaces.PonchoContentTypeToolResult:
+				deepseekMsg.Content += common.ToolResultText(part.ToolResult)
+			case interfaces.PonchoContentTypeReasoning:
+				// DeepSeek rejects reasoning_content in input messages
 			case interfaces.PonchoContentTypeTool:
 				if part.Tool != nil {
 					toolCall := DeepSeekToolCall{
@@ -375,13 +453,19 @@ func (m *DeepSeekModel) convertResponse(deepseekResp *DeepSeekResponse) (*interf
 			Content: make([]*interfaces.PonchoContentPart, 0),
 		}
 
-		// Convert content
+		// Convert content, then reasoning so the answer stays the first part
 		if choice.Message.Content != "" {
 			resp.Message.Content = append(resp.Message.Content, &interfaces.PonchoContentPart{
 				Type: interfaces.PonchoContentTypeText,
 				Text: choice.Message.Content,
 			})
 		}
+		if choice.Message.ReasoningContent != "" {
+			resp.Message.Content = append(resp.Message.Content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeReasoning,
+				Text: choice.Message.ReasoningContent,
+			})
+		}
 
 		// Convert tool calls
 		if len(choice.Message.ToolCalls) > 0 {
@@ -404,6 +488,7 @@ func (m *DeepSeekModel) convertResponse(deepseekResp *DeepSeekResponse) (*interf
 
 		// Convert finish reason
 		resp.FinishReason = common.ToPonchoFinishReason(common.FinishReason(choice.FinishReason))
+		resp.LogProbs = convertLogProbs(choice.LogProbs)
 	}
 
 	// Convert usage
@@ -442,7 +527,13 @@ func (m *DeepSeekModel) convertStreamChunk(streamResp *DeepSeekStreamResponse) (
 			Content: make([]*interfaces.PonchoContentPart, 0),
 		}
 
-		// Convert content delta
+		// Convert reasoning and content deltas
+		if choice.Delta.ReasoningContent != "" {
+			chunk.Delta.Content = append(chunk.Delta.Content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeReasoning,
+				Text: choice.Delta.ReasoningContent,
+			})
+		}
 		if choice.Delta.Content != "" {
 			chunk.Delta.Content = append(chunk.Delta.Content, &interfaces.PonchoContentPart{
 				Type: interfaces.PonchoContentTypeText,
@@ -473,6 +564,7 @@ func (m *DeepSeekModel) convertStreamChunk(streamResp *DeepSeekStreamResponse) (
 		if choice.FinishReason != nil {
 			chunk.FinishReason = common.ToPonchoFinishReason(common.FinishReason(*choice.FinishReason))
 		}
+		chunk.LogProbs = convertLogProbs(choice.LogProbs)
 	}
 
 	// Convert usage
@@ -487,6 +579,38 @@ func (m *DeepSeekModel) convertStreamChunk(streamResp *DeepSeekStreamResponse) (
 	return chunk, nil
 }
 
+// convertLogProbs converts DeepSeek logprobs to Poncho logprobs
+func convertLogProbs(logProbs *DeepSeekLogProbs) *interfaces.PonchoLogProbs {
+	if logProbs == nil || len(logProbs.Content)+len(logProbs.ReasoningContent) == 0 {
+		return nil
+	}
+	return &interfaces.PonchoLogProbs{
+		Content:   convertTokenLogProbs(logProbs.Content),
+		Reasoning: convertTokenLogProbs(logProbs.ReasoningContent),
+	}
+}
+
+// convertTokenLogProbs converts DeepSeek token logprobs with their alternatives
+func convertTokenLogProbs(tokens []DeepSeekTokenLogProb) []interfaces.PonchoTokenLogProb {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	converted := make([]interfaces.PonchoTokenLogProb, 0, len(tokens))
+	for _, token := range tokens {
+		tokenLogProb := interfaces.PonchoTokenLogProb{Token: token.Token, LogProb: token.LogProb, Bytes: token.Bytes}
+		for _, top := range token.TopLogProbs {
+			tokenLogProb.TopLogProbs = append(tokenLogProb.TopLogProbs, interfaces.PonchoTopLogProb{
+				Token:   top.Token,
+				LogProb: top.LogProb,
+				Bytes:   top.Bytes,
+			})
+		}
+		converted = append(converted, tokenLogProb)
+	}
+	return converted
+}
+
 // generateRequestID generates a unique request ID
 func (m *DeepSeekModel) generateRequestID() string {
 	return fmt.Sprintf("deepseek_%d", time.Now().UnixNano())
@@ -540,7 +664,7 @@ func (m *DeepSeekModel) createChatCompletion(ctx context.Context, req *DeepSeekR
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, common.ErrorFromHTTPStatus(resp.StatusCode, string(common.ProviderDeepSeek), req.Model)
+		return nil, common.ErrorFromHTTPResponse(resp, string(common.ProviderDeepSeek), req.Model)
 	}
 
 	// Parse response
@@ -590,7 +714,7 @@ func (m *DeepSeekModel) createChatCompletionStream(ctx context.Context, req *Dee
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return common.ErrorFromHTTPStatus(resp.StatusCode, string(common.ProviderDeepSeek), req.Model)
+		return common.ErrorFromHTTPResponse(resp, string(common.ProviderDeepSeek), req.Model)
 	}
 
 	// Process stream
@@ -49,7 +49,13 @@ func ConvertStreamChunkToPoncho(chunk *DeepSeekStreamResponse) (*interfaces.Ponc
 			Content: make([]*interfaces.PonchoContentPart, 0),
 		}
 
-		// Convert content delta
+		// Convert reasoning and content deltas
+		if choice.Delta.ReasoningContent != "" {
+			ponchoChunk.Delta.Content = append(ponchoChunk.Delta.Content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeReasoning,
+				Text: choice.Delta.ReasoningContent,
+			})
+		}
 		if choice.Delta.Content != "" {
 			ponchoChunk.Delta.Content = append(ponchoChunk.Delta.Content, &interfaces.PonchoContentPart{
 				Type: interfaces.PonchoContentTypeText,
@@ -205,3 +211,73 @@ func ProcessSSEStream(ctx context.Context, body io.ReadCloser, callback func(*De
 
 	return nil
 }
+
+// toolCallAccumulator joins the tool call fragments of a stream. The first
+// fragment of a call carries its id and name; arguments arrive in pieces.
+type toolCallAccumulator struct {
+	calls   []*DeepSeekToolCall
+	byIndex map[int]*DeepSeekToolCall
+}
+
+// newToolCallAccumulator creates an empty tool call accumulator
+func newToolCallAccumulator() *toolCallAccumulator {
+	return &toolCallAccumulator{byIndex: make(map[int]*DeepSeekToolCall)}
+}
+
+// add merges tool call fragments from a stream delta
+func (a *toolCallAccumulator) add(fragments []DeepSeekToolCall) {
+	for _, fragment := range fragments {
+		var call *DeepSeekToolCall
+		switch {
+		case fragment.Index != nil:
+			call = a.byIndex[*fragment.Index]
+		case fragment.ID == "" && len(a.calls) > 0:
+			// Without an index, a fragment without an id continues the last call
+			call = a.calls[len(a.calls)-1]
+		}
+
+		if call == nil {
+			call = &DeepSeekToolCall{Type: "function"}
+			a.calls = append(a.calls, call)
+			if fragment.Index != nil {
+				a.byIndex[*fragment.Index] = call
+			}
+		}
+
+		if fragment.ID != "" {
+			call.ID = fragment.ID
+		}
+		if fragment.Function.Name != "" {
+			call.Function.Name = fragment.Function.Name
+		}
+		call.Function.Arguments += fragment.Function.Arguments
+	}
+}
+
+// flush returns the complete tool calls as content parts and resets the accumulator
+func (a *toolCallAccumulator) flush() []*interfaces.PonchoContentPart {
+	parts := make([]*interfaces.PonchoContentPart, 0, len(a.calls))
+	for _, call := range a.calls {
+		args := make(map[string]interface{})
+		if call.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				args = map[string]interface{}{
+					"raw_arguments": call.Function.Arguments,
+				}
+			}
+		}
+
+		parts = append(parts, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeTool,
+			Tool: &interfaces.PonchoToolPart{
+				ID:   call.ID,
+				Name: call.Function.Name,
+				Args: args,
+			},
+		})
+	}
+
+	a.calls = nil
+	a.byIndex = make(map[int]*DeepSeekToolCall)
+	return parts
+}
@@ -3,6 +3,9 @@ package deepseek
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
@@ -222,6 +225,44 @@ data: [DONE]
 	assert.Equal(t, "chunk2", processedChunks[1].ID)
 }
 
+func TestDeepSeekModel_StreamToolCallsWithoutFinishReason(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c1","model":"deepseek-chat","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_article","arguments":"{\"id\""}}]}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"id":"c1","model":"deepseek-chat","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":": 42}"}}]}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	model := NewDeepSeekModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{
+		"api_key":  "test-api-key",
+		"base_url": server.URL,
+	}))
+	defer model.Shutdown(context.Background())
+
+	var chunks []*interfaces.PonchoStreamChunk
+	err := model.GenerateStreaming(context.Background(), &interfaces.PonchoModelRequest{
+		Model: "deepseek-chat",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Article 42?"}},
+		}},
+	}, func(chunk *interfaces.PonchoStreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The stream ended at [DONE] without a finish reason; the calls are still sent
+	final := chunks[len(chunks)-1]
+	assert.True(t, final.Done)
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, final.FinishReason)
+	require.Len(t, final.Delta.Content, 1)
+	assert.Equal(t, "call_1", final.Delta.Content[0].Tool.ID)
+	assert.Equal(t, float64(42), final.Delta.Content[0].Tool.Args["id"])
+}
+
 func TestProcessSSEStreamWithContextCancellation(t *testing.T) {
 	// Test SSE stream processing with context cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -338,3 +379,29 @@ func BenchmarkConvertStreamChunkToPoncho(b *testing.B) {
 		_, _ = ConvertStreamChunkToPoncho(streamChunk)
 	}
 }
+
+func TestToolCallAccumulator(t *testing.T) {
+	first, second := 0, 1
+	acc := newToolCallAccumulator()
+	acc.add([]DeepSeekToolCall{{Index: &first, ID: "call_1", Type: "function", Function: DeepSeekFunctionCall{Name: "get_weather", Arguments: `{"loc`}}})
+	acc.add([]DeepSeekToolCall{{Index: &first, Function: DeepSeekFunctionCall{Arguments: `ation": "Boston"}`}}})
+	acc.add([]DeepSeekToolCall{{Index: &second, ID: "call_2", Type: "function", Function: DeepSeekFunctionCall{Name: "get_time", Arguments: `{broken`}}})
+
+	parts := acc.flush()
+	require.Len(t, parts, 2)
+	assert.Equal(t, "call_1", parts[0].Tool.ID)
+	assert.Equal(t, "get_weather", parts[0].Tool.Name)
+	assert.Equal(t, "Boston", parts[0].Tool.Args["location"])
+	assert.Equal(t, "call_2", parts[1].Tool.ID)
+	assert.Equal(t, "{broken", parts[1].Tool.Args["raw_arguments"])
+}
+
+func TestConvertStreamChunkWithReasoning(t *testing.T) {
+	ponchoChunk, err := ConvertStreamChunkToPoncho(&DeepSeekStreamResponse{
+		Choices: []DeepSeekStreamChoice{{Delta: DeepSeekStreamDelta{ReasoningContent: "Thinking..."}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, ponchoChunk.Delta.Content, 1)
+	assert.Equal(t, interfaces.PonchoContentTypeReasoning, ponchoChunk.Delta.Content[0].Type)
+	assert.Equal(t, "Thinking...", ponchoChunk.Delta.Content[0].Text)
+}
@@ -6,14 +6,17 @@ import "time"
 
 // DeepSeekMessage represents a message in DeepSeek API format
 type DeepSeekMessage struct {
-	Role      string             `json:"role"`
-	Content   string             `json:"content"`
-	Name      *string            `json:"name,omitempty"`
-	ToolCalls []DeepSeekToolCall `json:"tool_calls,omitempty"`
+	Role             string             `json:"role"`
+	Content          string             `json:"content"`
+	Name             *string            `json:"name,omitempty"`
+	ToolCalls        []DeepSeekToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string             `json:"tool_call_id,omitempty"`      // role "tool" only
+	ReasoningContent string             `json:"reasoning_content,omitempty"` // responses only
 }
 
 // DeepSeekToolCall represents a tool call in DeepSeek API format
 type DeepSeekToolCall struct {
+	Index    *int                 `json:"index,omitempty"` // stream deltas only
 	ID       string               `json:"id"`
 	Type     string               `json:"type"` // always "function"
 	Function DeepSeekFunctionCall `json:"function"`
@@ -0,0 +1,184 @@
+package fake
+
+// This file implements a deterministic embedding model for offline tests.
+// Vectors are hashed bags of lowercased words and their character trigrams,
+// normalized to unit length, so equal texts get equal vectors and texts sharing
+// words or word stems ("красное платье", "красный") get a high cosine similarity.
+//
+// Configuration (map passed to Initialize, as built by the embedding factory):
+// - model_name
+// - dimensions: vector length, DefaultEmbeddingDimensions by default
+// - max_batch_size: inputs per recorded batch, DefaultEmbeddingBatchSize by default
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// Defaults of the fake embedding model
+const (
+	FakeDefaultEmbeddingModel  = "fake-embedding"
+	DefaultEmbeddingDimensions = 64
+	DefaultEmbeddingBatchSize  = 16
+)
+
+// FakeEmbeddingModel represents a deterministic embedding model
+type FakeEmbeddingModel struct {
+	name         string
+	dimensions   int
+	maxBatchSize int
+	batches      [][]string
+	mutex        sync.Mutex
+}
+
+// NewFakeEmbeddingModel creates a fake embedding model with the default settings
+func NewFakeEmbeddingModel() *FakeEmbeddingModel {
+	return &FakeEmbeddingModel{
+		name:         FakeDefaultEmbeddingModel,
+		dimensions:   DefaultEmbeddingDimensions,
+		maxBatchSize: DefaultEmbeddingBatchSize,
+	}
+}
+
+// Initialize applies model_name, dimensions and max_batch_size
+func (m *FakeEmbeddingModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if name, ok := config["model_name"].(string); ok && name != "" {
+		m.name = name
+	}
+
+	for key, target := range map[string]*int{
+		"dimensions":     &m.dimensions,
+		"max_batch_size": &m.maxBatchSize,
+	} {
+		value, exists := config[key]
+		if !exists {
+			continue
+		}
+		number, ok := positiveInt(value)
+		if !ok {
+			return fmt.Errorf("%s must be a positive integer", key)
+		}
+		*target = number
+	}
+
+	return nil
+}
+
+// Embed returns one vector per input and records every batch
+func (m *FakeEmbeddingModel) Embed(ctx context.Context, inputs []string) ([][]float32, *interfaces.PonchoUsage, error) {
+	return common.EmbedInBatches(ctx, inputs, m.maxBatchSize, func(ctx context.Context, batch []string) ([][]float32, *interfaces.PonchoUsage, error) {
+		m.mutex.Lock()
+		m.batches = append(m.batches, append([]string(nil), batch...))
+		m.mutex.Unlock()
+
+		vectors := make([][]float32, len(batch))
+		tokens := 0
+		for i, text := range batch {
+			words := embeddingWords(text)
+			vectors[i] = m.vector(words)
+			tokens += len(words)
+		}
+		return vectors, &interfaces.PonchoUsage{PromptTokens: tokens, TotalTokens: tokens}, nil
+	})
+}
+
+// vector hashes words and their trigrams into a unit vector
+func (m *FakeEmbeddingModel) vector(words []string) []float32 {
+	sums := make([]float64, m.dimensions)
+	add := func(feature string, weight float64) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		sums[sum%uint64(m.dimensions)] += weight
+	}
+
+	for _, word := range words {
+		add("w:"+word, 1)
+		runes := []rune("^" + word + "$")
+		for i := 0; i+3 <= len(runes); i++ {
+			add("t:"+string(runes[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, value := range sums {
+		norm += value * value
+	}
+	norm = math.Sqrt(norm)
+
+	vector := make([]float32, m.dimensions)
+	if norm == 0 {
+		return vector
+	}
+	for i, value := range sums {
+		vector[i] = float32(value / norm)
+	}
+	return vector
+}
+
+// Batches returns the recorded input batches in call order
+func (m *FakeEmbeddingModel) Batches() [][]string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	batches := make([][]string, len(m.batches))
+	copy(batches, m.batches)
+	return batches
+}
+
+// Name returns the configured model name
+func (m *FakeEmbeddingModel) Name() string {
+	return m.name
+}
+
+// Provider returns the provider name
+func (m *FakeEmbeddingModel) Provider() string {
+	return string(common.ProviderFake)
+}
+
+// Dimensions returns the vector length
+func (m *FakeEmbeddingModel) Dimensions() int {
+	return m.dimensions
+}
+
+// MaxBatchSize returns the number of inputs per recorded batch
+func (m *FakeEmbeddingModel) MaxBatchSize() int {
+	return m.maxBatchSize
+}
+
+// Shutdown does nothing, the fake holds no resources
+func (m *FakeEmbeddingModel) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// embeddingWords splits text into lowercased words of letters and digits
+func embeddingWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// positiveInt converts numeric config values to a positive int
+func positiveInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, v > 0
+	case int64:
+		return int(v), v > 0
+	case float64:
+		return int(v), v > 0 && v == math.Trunc(v)
+	default:
+		return 0, false
+	}
+}
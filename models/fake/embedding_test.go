@@ -0,0 +1,43 @@
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeEmbeddingModel_Embed(t *testing.T) {
+	model := NewFakeEmbeddingModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{"dimensions": 128, "max_batch_size": 2}))
+
+	inputs := []string{"Платье красное, хлопок", "платье  красное хлопок", "Красный сарафан из хлопка", "Кожаные ботинки"}
+	vectors, usage, err := model.Embed(context.Background(), inputs)
+	require.NoError(t, err)
+
+	require.Len(t, vectors, 4)
+	assert.Len(t, vectors[0], 128)
+	assert.Equal(t, vectors[0], vectors[1], "case and punctuation must not matter")
+	assert.Equal(t, [][]string{inputs[:2], inputs[2:]}, model.Batches())
+	assert.Equal(t, 12, usage.PromptTokens)
+
+	// Shared stems score higher than unrelated texts
+	related := common.CosineSimilarity(vectors[0], vectors[2])
+	unrelated := common.CosineSimilarity(vectors[0], vectors[3])
+	assert.Greater(t, related, unrelated)
+	assert.InDelta(t, 1.0, common.CosineSimilarity(vectors[0], vectors[1]), 1e-6)
+
+	// The same text gives the same vector on every call
+	again, _, err := NewFakeEmbeddingModel().Embed(context.Background(), inputs[:1])
+	require.NoError(t, err)
+	reference, _, err := NewFakeEmbeddingModel().Embed(context.Background(), inputs[:1])
+	require.NoError(t, err)
+	assert.Equal(t, reference, again)
+}
+
+func TestFakeEmbeddingModel_InitializeValidation(t *testing.T) {
+	assert.Error(t, NewFakeEmbeddingModel().Initialize(context.Background(), map[string]interface{}{"dimensions": 0}))
+	assert.Error(t, NewFakeEmbeddingModel().Initialize(context.Background(), map[string]interface{}{"max_batch_size": 1.5}))
+}
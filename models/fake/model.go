@@ -0,0 +1,479 @@
+// Package fake provides a scripted model implementation for PonchoFramework.
+// It answers from a script instead of a network API, so flows and tools can be
+// tested end-to-end, offline and deterministically.
+//
+// Key Features:
+// - Responses picked by regex on the last user message or by call number
+// - Text, tool calls, streamed chunks with delays and injected ModelErrors
+// - Every received request is recorded for assertions
+//
+// Configuration (map passed to Initialize, as built by the model factory):
+// - model_name, max_tokens, temperature, supports
+// - script: list of steps (see Step), from YAML custom_params or []Step in Go
+// - script_file: path to a YAML file holding the list of steps
+//
+// Usage Example:
+//
+//	model, _ := fake.NewScriptedModel(
+//	    fake.Step{Match: "article", ToolCalls: []fake.ToolCall{{Name: "get_article"}}},
+//	    fake.Step{Text: "The dress is red"},
+//	)
+//	resp, err := model.Generate(ctx, request)
+//	assert.Len(t, model.Requests(), 1)
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// FakeModel represents a scripted model
+type FakeModel struct {
+	*base.PonchoBaseModel
+	modelName string
+	steps     []Step
+	used      []bool
+	requests  []*interfaces.PonchoModelRequest
+	ready     bool
+	mutex     sync.Mutex
+}
+
+// NewFakeModel creates a new fake model instance
+func NewFakeModel() *FakeModel {
+	baseModel := base.NewPonchoBaseModel(common.FakeDefaultModel, string(common.ProviderFake), interfaces.ModelCapabilities{
+		Streaming: true,
+		Tools:     true,
+		Vision:    true,
+		System:    true,
+		JSONMode:  true,
+	})
+
+	return &FakeModel{
+		PonchoBaseModel: baseModel,
+		modelName:       common.FakeDefaultModel,
+	}
+}
+
+// NewScriptedModel creates an initialized fake model answering from steps
+func NewScriptedModel(steps ...Step) (*FakeModel, error) {
+	model := NewFakeModel()
+	if err := model.Initialize(context.Background(), map[string]interface{}{"script": steps}); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Name returns the configured model name
+func (m *FakeModel) Name() string {
+	return m.modelName
+}
+
+// Initialize initializes the fake model with configuration
+func (m *FakeModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+
+	steps, err := loadScript(config)
+	if err != nil {
+		return fmt.Errorf("failed to load script: %w", err)
+	}
+
+	if supports, ok := config["supports"].(*interfaces.ModelCapabilities); ok && supports != nil {
+		m.SetCapabilities(*supports)
+	}
+
+	// The fake model needs no credentials
+	baseConfig := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		baseConfig[k] = v
+	}
+	if apiKey, ok := baseConfig["api_key"].(string); ok && apiKey == "" {
+		delete(baseConfig, "api_key")
+	}
+
+	if err := m.PonchoBaseModel.Initialize(ctx, baseConfig); err != nil {
+		return fmt.Errorf("failed to initialize base model: %w", err)
+	}
+
+	if modelName, ok := config["model_name"].(string); ok && modelName != "" {
+		m.modelName = modelName
+	}
+
+	m.mutex.Lock()
+	m.steps = steps
+	m.used = make([]bool, len(steps))
+	m.ready = true
+	m.mutex.Unlock()
+
+	m.GetLogger().Info("Fake model initialized",
+		"model", m.modelName,
+		"steps", len(steps))
+
+	return nil
+}
+
+// Generate returns the scripted response for the request
+func (m *FakeModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	step, err := m.nextStep(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wait(ctx, step.Delay); err != nil {
+		return nil, err
+	}
+
+	if step.Error != nil {
+		return nil, m.stepError(step.Error)
+	}
+
+	content := make([]*interfaces.PonchoContentPart, 0, 1+len(step.ToolCalls))
+	if text := step.responseText(); text != "" {
+		content = append(content, &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeText, Text: text})
+	}
+	content = append(content, toolCallParts(step.ToolCalls)...)
+
+	resp := m.PrepareResponse(&interfaces.PonchoMessage{
+		Role:    interfaces.PonchoRoleAssistant,
+		Content: content,
+	}, m.usage(req, step), step.finishReason())
+	resp.Metadata["model"] = m.modelName
+
+	return resp, nil
+}
+
+// GenerateStreaming streams the scripted chunks, then a final chunk with tool
+// calls, usage and finish reason. A scripted error is returned after the chunks.
+func (m *FakeModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if m.isInitialized() && !m.SupportsStreaming() {
+		return fmt.Errorf("model '%s' does not support streaming", m.Name())
+	}
+
+	step, err := m.nextStep(req)
+	if err != nil {
+		return err
+	}
+
+	chunks := step.Chunks
+	if len(chunks) == 0 && step.Text != "" {
+		chunks = []string{step.Text}
+	}
+
+	for _, text := range chunks {
+		if err := wait(ctx, step.Delay); err != nil {
+			return err
+		}
+		if err := callback(m.PrepareStreamChunk(&interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: text}},
+		}, nil, "", false)); err != nil {
+			return err
+		}
+	}
+
+	if step.Error != nil {
+		return m.stepError(step.Error)
+	}
+
+	if err := wait(ctx, step.Delay); err != nil {
+		return err
+	}
+
+	return callback(m.PrepareStreamChunk(&interfaces.PonchoMessage{
+		Role:    interfaces.PonchoRoleAssistant,
+		Content: toolCallParts(step.ToolCalls),
+	}, m.usage(req, step), step.finishReason(), true))
+}
+
+// HealthCheck implements interfaces.HealthChecker
+func (m *FakeModel) HealthCheck(ctx context.Context) error {
+	if !m.isInitialized() {
+		return fmt.Errorf("fake model is not initialized")
+	}
+	return nil
+}
+
+// Shutdown shuts down the fake model
+func (m *FakeModel) Shutdown(ctx context.Context) error {
+	m.mutex.Lock()
+	m.ready = false
+	m.mutex.Unlock()
+
+	return m.PonchoBaseModel.Shutdown(ctx)
+}
+
+// Requests returns copies of all requests received so far, in order
+func (m *FakeModel) Requests() []*interfaces.PonchoModelRequest {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	requests := make([]*interfaces.PonchoModelRequest, len(m.requests))
+	copy(requests, m.requests)
+	return requests
+}
+
+// LastRequest returns the most recent request, or nil before the first call
+func (m *FakeModel) LastRequest() *interfaces.PonchoModelRequest {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.requests) == 0 {
+		return nil
+	}
+	return m.requests[len(m.requests)-1]
+}
+
+// CallCount returns the number of calls received so far
+func (m *FakeModel) CallCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return len(m.requests)
+}
+
+// Reset forgets recorded requests and rewinds the script
+func (m *FakeModel) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.requests = nil
+	m.used = make([]bool, len(m.steps))
+}
+
+// Helper methods
+
+// nextStep records the request and picks the step that answers it
+func (m *FakeModel) nextStep(req *interfaces.PonchoModelRequest) (*Step, error) {
+	if !m.isInitialized() {
+		return nil, fmt.Errorf("model '%s' is not initialized", m.Name())
+	}
+
+	if err := m.ValidateRequest(req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Copy the request, callers often append to Messages between calls
+	recorded := *req
+	recorded.Messages = append([]*interfaces.PonchoMessage(nil), req.Messages...)
+	m.requests = append(m.requests, &recorded)
+	call := len(m.requests)
+
+	prompt := lastUserText(req)
+	for i := range m.steps {
+		step := &m.steps[i]
+		switch {
+		case step.Call != 0:
+			if step.Call != call {
+				continue
+			}
+		case step.matcher != nil:
+			if !step.matcher.MatchString(prompt) {
+				continue
+			}
+		default:
+			if m.used[i] {
+				continue
+			}
+			m.used[i] = true
+		}
+
+		m.GetLogger().Debug("Fake model step selected",
+			"model", m.modelName,
+			"call", call,
+			"step", i)
+		return step, nil
+	}
+
+	return nil, common.NewModelError(common.ErrorCodeInternalError,
+		fmt.Sprintf("fake script has no response for call %d", call), string(common.ProviderFake), m.modelName)
+}
+
+// stepError converts a scripted error to a ModelError
+func (m *FakeModel) stepError(stepErr *StepError) error {
+	message := stepErr.Message
+	if message == "" {
+		message = "scripted error"
+	}
+	modelErr := common.NewModelError(stepErr.Code, message, string(common.ProviderFake), m.modelName)
+	modelErr.RetryAfter = stepErr.RetryAfter
+	return modelErr
+}
+
+// usage returns the scripted usage, or a word-count estimate
+func (m *FakeModel) usage(req *interfaces.PonchoModelRequest, step *Step) *interfaces.PonchoUsage {
+	if step.Usage != nil {
+		usage := *step.Usage
+		if usage.TotalTokens == 0 {
+			usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+		}
+		return &usage
+	}
+
+	promptTokens := 0
+	for _, msg := range req.Messages {
+		promptTokens += len(strings.Fields(messageText(msg)))
+	}
+	completionTokens := len(strings.Fields(step.responseText())) + len(step.ToolCalls)
+
+	return m.PrepareUsage(promptTokens, completionTokens)
+}
+
+// isInitialized checks if model is properly initialized
+func (m *FakeModel) isInitialized() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.ready
+}
+
+// responseText returns the full text of a step
+func (s *Step) responseText() string {
+	if s.Text != "" {
+		return s.Text
+	}
+	return strings.Join(s.Chunks, "")
+}
+
+// finishReason returns the scripted finish reason, or tool/stop by content
+func (s *Step) finishReason() interfaces.PonchoFinishReason {
+	if s.FinishReason != "" {
+		return s.FinishReason
+	}
+	if len(s.ToolCalls) > 0 {
+		return interfaces.PonchoFinishReasonTool
+	}
+	return interfaces.PonchoFinishReasonStop
+}
+
+// toolCallParts converts scripted tool calls to content parts
+func toolCallParts(calls []ToolCall) []*interfaces.PonchoContentPart {
+	parts := make([]*interfaces.PonchoContentPart, 0, len(calls))
+	for i, call := range calls {
+		id := call.ID
+		if id == "" {
+			id = fmt.Sprintf("fake_call_%d", i+1)
+		}
+		args := call.Args
+		if args == nil {
+			args = make(map[string]interface{})
+		}
+		parts = append(parts, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeTool,
+			Tool: &interfaces.PonchoToolPart{ID: id, Name: call.Name, Args: args},
+		})
+	}
+	return parts
+}
+
+// lastUserText returns the text of the last user message
+func lastUserText(req *interfaces.PonchoModelRequest) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == interfaces.PonchoRoleUser {
+			return messageText(req.Messages[i])
+		}
+	}
+	return ""
+}
+
+// messageText joins the text parts of a message
+func messageText(msg *interfaces.PonchoMessage) string {
+	var texts []string
+	for _, part := range msg.Content {
+		if part.Type == interfaces.PonchoContentTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// wait sleeps for delay unless the context is cancelled first
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// loadScript reads the steps from "script" or "script_file"
+func loadScript(config map[string]interface{}) ([]Step, error) {
+	script, hasScript := config["script"]
+
+	if path, ok := config["script_file"].(string); ok && path != "" {
+		if hasScript {
+			return nil, fmt.Errorf("script and script_file are mutually exclusive")
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &script); err != nil {
+			return nil, fmt.Errorf("failed to parse script file %s: %w", path, err)
+		}
+		// A file may hold the list itself or a document with a "script" key
+		if document, ok := script.(map[string]interface{}); ok {
+			script = document["script"]
+		}
+	}
+
+	steps, err := decodeSteps(script)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range steps {
+		if err := steps[i].compile(); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return steps, nil
+}
+
+// decodeSteps converts []Step or a decoded YAML/JSON list to steps
+func decodeSteps(script interface{}) ([]Step, error) {
+	switch value := script.(type) {
+	case nil:
+		return nil, nil
+	case []Step:
+		steps := make([]Step, len(value))
+		copy(steps, value)
+		return steps, nil
+	case []interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script: %w", err)
+		}
+		var steps []Step
+		if err := json.Unmarshal(data, &steps); err != nil {
+			return nil, fmt.Errorf("invalid script: %w", err)
+		}
+		return steps, nil
+	default:
+		return nil, fmt.Errorf("script must be a list of steps, got %T", script)
+	}
+}
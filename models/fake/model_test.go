@@ -0,0 +1,160 @@
+package fake
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func userRequest(text string) *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: text}},
+		}},
+	}
+}
+
+func TestFakeModel_StepSelection(t *testing.T) {
+	model, err := NewScriptedModel(
+		Step{Call: 3, Error: &StepError{Code: common.ErrorCodeRateLimitError, Message: "slow down"}},
+		Step{Match: `(?i)article \d+`, ToolCalls: []ToolCall{{Name: "get_article", Args: map[string]interface{}{"id": 42}}}},
+		Step{Text: "first"},
+		Step{Text: "second"},
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Regex steps are reusable and take precedence by script order
+	resp, err := model.Generate(ctx, userRequest("Find Article 42"))
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, resp.FinishReason)
+	require.Len(t, resp.Message.Content, 1)
+	assert.Equal(t, "fake_call_1", resp.Message.Content[0].Tool.ID)
+	assert.Equal(t, "get_article", resp.Message.Content[0].Tool.Name)
+
+	// Sequential steps are used once, in order
+	resp, err = model.Generate(ctx, userRequest("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", resp.Message.Content[0].Text)
+	assert.Equal(t, interfaces.PonchoFinishReasonStop, resp.FinishReason)
+
+	// Call 3 returns the injected error
+	_, err = model.Generate(ctx, userRequest("Find article 7"))
+	modelErr, ok := common.IsModelError(err)
+	require.True(t, ok, "expected ModelError, got %T", err)
+	assert.Equal(t, common.ErrorCodeRateLimitError, modelErr.Code)
+	assert.Equal(t, "fake", modelErr.Provider)
+
+	resp, err = model.Generate(ctx, userRequest("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", resp.Message.Content[0].Text)
+
+	// The script is exhausted
+	_, err = model.Generate(ctx, userRequest("hello"))
+	assert.Error(t, err)
+
+	// Every request is recorded, including failed ones
+	requests := model.Requests()
+	require.Len(t, requests, 5)
+	assert.Equal(t, "Find Article 42", requests[0].Messages[0].Content[0].Text)
+	assert.Equal(t, 5, model.CallCount())
+
+	model.Reset()
+	assert.Nil(t, model.LastRequest())
+	resp, err = model.Generate(ctx, userRequest("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", resp.Message.Content[0].Text)
+}
+
+func TestFakeModel_GenerateStreaming(t *testing.T) {
+	model, err := NewScriptedModel(
+		Step{Chunks: []string{"Red ", "dress"}, Delay: 5 * time.Millisecond, Usage: &interfaces.PonchoUsage{PromptTokens: 10, CompletionTokens: 2}},
+		Step{Chunks: []string{"partial"}, Error: &StepError{Code: common.ErrorCodeStreamInterrupted}},
+	)
+	require.NoError(t, err)
+
+	var chunks []*interfaces.PonchoStreamChunk
+	collect := func(chunk *interfaces.PonchoStreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	}
+
+	start := time.Now()
+	require.NoError(t, model.GenerateStreaming(context.Background(), userRequest("Describe"), collect))
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "Red ", chunks[0].Delta.Content[0].Text)
+	assert.Equal(t, "dress", chunks[1].Delta.Content[0].Text)
+	assert.True(t, chunks[2].Done)
+	assert.Equal(t, 12, chunks[2].Usage.TotalTokens)
+
+	// Chunks are delivered before the injected error
+	chunks = nil
+	err = model.GenerateStreaming(context.Background(), userRequest("Describe"), collect)
+	modelErr, ok := common.IsModelError(err)
+	require.True(t, ok, "expected ModelError, got %T", err)
+	assert.Equal(t, common.ErrorCodeStreamInterrupted, modelErr.Code)
+	require.Len(t, chunks, 1)
+	assert.False(t, chunks[0].Done)
+}
+
+func TestFakeModel_DelayHonoursContext(t *testing.T) {
+	model, err := NewScriptedModel(Step{Text: "late", Delay: time.Minute})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = model.Generate(ctx, userRequest("hello"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFakeModel_ScriptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+script:
+  - match: "sketch"
+    text: '{"category": "dress"}'
+    delay: 1ms
+    usage: {prompt_tokens: 100, completion_tokens: 5}
+  - error: {code: TIMEOUT_ERROR}
+`), 0o644))
+
+	model := NewFakeModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{
+		"api_key":     "",
+		"model_name":  "sketch-analyzer",
+		"script_file": path,
+	}))
+	assert.Equal(t, "sketch-analyzer", model.Name())
+
+	resp, err := model.Generate(context.Background(), userRequest("Analyze this sketch"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"category": "dress"}`, resp.Message.Content[0].Text)
+	assert.Equal(t, 105, resp.Usage.TotalTokens)
+
+	_, err = model.Generate(context.Background(), userRequest("Anything else"))
+	modelErr, ok := common.IsModelError(err)
+	require.True(t, ok, "expected ModelError, got %T", err)
+	assert.Equal(t, common.ErrorCodeTimeoutError, modelErr.Code)
+}
+
+func TestFakeModel_InitializeValidation(t *testing.T) {
+	_, err := NewScriptedModel(Step{Match: "("})
+	assert.Error(t, err, "invalid regex is rejected")
+
+	_, err = NewScriptedModel(Step{Error: &StepError{Message: "no code"}})
+	assert.Error(t, err, "error code is required")
+
+	err = NewFakeModel().Initialize(context.Background(), map[string]interface{}{"script": "text"})
+	assert.Error(t, err, "script must be a list")
+}
@@ -0,0 +1,115 @@
+// Package fake provides type definitions for the scripted fake model provider.
+//
+// A script is an ordered list of steps. For every call the model picks the
+// first step that applies:
+// - a step with Call applies to that call only (1 = first call)
+// - a step with Match applies whenever the regex matches the last user message
+// - a step with neither is used once, in script order
+//
+// Usage Example (YAML, under a model's custom_params):
+//
+//	script:
+//	  - match: "(?i)article \\d+"
+//	    tool_calls:
+//	      - name: get_article
+//	        args: {id: 42}
+//	  - call: 3
+//	    error: {code: RATE_LIMIT_ERROR, message: "slow down"}
+//	  - chunks: ["Red ", "dress"]
+//	    delay: 20ms
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// Step represents one scripted response
+type Step struct {
+	Match string `json:"match,omitempty"` // regex on the text of the last user message
+	Call  int    `json:"call,omitempty"`  // 1-based call number
+
+	Text         string                        `json:"text,omitempty"`
+	Chunks       []string                      `json:"chunks,omitempty"` // streamed one by one; joined for Generate
+	ToolCalls    []ToolCall                    `json:"tool_calls,omitempty"`
+	FinishReason interfaces.PonchoFinishReason `json:"finish_reason,omitempty"`
+	Usage        *interfaces.PonchoUsage       `json:"usage,omitempty"`
+	Error        *StepError                    `json:"error,omitempty"` // returned after any chunks are streamed
+	Delay        time.Duration                 `json:"-"`               // before the response and before every chunk
+
+	matcher *regexp.Regexp
+}
+
+// ToolCall represents a scripted tool call
+type ToolCall struct {
+	ID   string                 `json:"id,omitempty"` // defaults to "fake_call_N"
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// StepError represents a scripted error, returned as a *common.ModelError
+type StepError struct {
+	Code       common.ModelErrorCode `json:"code"`
+	Message    string                `json:"message,omitempty"`
+	RetryAfter time.Duration         `json:"-"` // as sent by a provider in Retry-After
+}
+
+// UnmarshalJSON decodes a step, reading delay as a duration string such as "50ms"
+func (s *Step) UnmarshalJSON(data []byte) error {
+	type plainStep Step
+	aux := struct {
+		*plainStep
+		Delay string `json:"delay,omitempty"`
+	}{plainStep: (*plainStep)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Delay != "" {
+		delay, err := time.ParseDuration(aux.Delay)
+		if err != nil {
+			return fmt.Errorf("invalid delay %q: %w", aux.Delay, err)
+		}
+		s.Delay = delay
+	}
+
+	return nil
+}
+
+// compile validates a step and prepares its matcher
+func (s *Step) compile() error {
+	if s.Call < 0 {
+		return fmt.Errorf("call must be positive")
+	}
+
+	if s.Match != "" {
+		matcher, err := regexp.Compile(s.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match %q: %w", s.Match, err)
+		}
+		s.matcher = matcher
+	}
+
+	if s.Error != nil && s.Error.Code == "" {
+		return fmt.Errorf("error code is required")
+	}
+
+	for _, call := range s.ToolCalls {
+		if call.Name == "" {
+			return fmt.Errorf("tool call name is required")
+		}
+	}
+
+	return nil
+}
+
+// sequential reports whether the step is used once, in script order
+func (s *Step) sequential() bool {
+	return s.Call == 0 && s.Match == ""
+}
@@ -0,0 +1,365 @@
+// Package gemini provides a Google Gemini client for PonchoFramework.
+// This file implements the HTTP client for the generateContent and
+// streamGenerateContent REST endpoints.
+//
+// Key Features:
+// - x-goog-api-key authentication
+// - HTTP client with connection pooling and retry logic
+// - Non-streaming and SSE streaming requests (alt=sse)
+// - Image loading: data: URLs are unpacked, http(s) URLs are downloaded
+// - API error messages and status preserved in ModelError
+//
+// Usage Example:
+//
+//	config := &common.CommonModelConfig{
+//	    APIKey: "AIza...",
+//	    Model: "gemini-2.5-flash-lite",
+//	    MaxTokens: 2000,
+//	    Temperature: 0.2,
+//	    Timeout: 60 * time.Second,
+//	}
+//	client, _ := NewGeminiClient(config, logger)
+//	resp, err := client.GenerateContent(ctx, request)
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// maxImageSize limits images downloaded for a request; inline request data
+// is capped at 20MB by the API
+const maxImageSize = 20 * 1024 * 1024
+
+// GeminiClient represents a client for the Gemini API
+type GeminiClient struct {
+	httpClient  *common.HTTPClient
+	config      *common.CommonModelConfig
+	logger      interfaces.Logger
+	apiKey      string
+	baseURL     string
+	rateLimiter *common.RateLimiter
+	tokenizer   *common.Tokenizer
+
+	releaseRateLimiter func()
+}
+
+// NewGeminiClient creates a new Gemini client
+func NewGeminiClient(config *common.CommonModelConfig, logger interfaces.Logger) (*GeminiClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	// Validate configuration
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Create HTTP client from a copy of the shared defaults
+	httpConfig := common.DefaultHTTPConfig
+	httpConfig.Timeout = config.Timeout
+	httpConfig.UserAgent = "PonchoFramework-Gemini/1.0"
+
+	retryConfig := common.DefaultRetryConfig
+	retryConfig.MaxAttempts = 3
+	retryConfig.BaseDelay = 1 * time.Second
+	retryConfig.MaxDelay = 30 * time.Second
+
+	httpClient, err := common.NewHTTPClient(&httpConfig, retryConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = common.GeminiDefaultBaseURL
+	}
+
+	client := &GeminiClient{
+		httpClient: httpClient,
+		config:     config,
+		logger:     logger,
+		apiKey:     config.APIKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		tokenizer:  common.NewTokenizer(logger),
+	}
+	client.claimRateLimiter()
+
+	logger.Info("Gemini client created",
+		"model", config.Model,
+		"base_url", client.baseURL,
+		"timeout", config.Timeout)
+
+	return client, nil
+}
+
+// validateConfig validates Gemini configuration
+func validateConfig(config *common.CommonModelConfig) error {
+	if config.APIKey == "" {
+		return fmt.Errorf("API key is required")
+	}
+
+	if config.Model == "" {
+		return fmt.Errorf("model name is required")
+	}
+
+	if config.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be positive")
+	}
+
+	if config.Temperature < 0 || config.Temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+
+	if config.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+// Close closes the Gemini client and cleans up resources
+func (c *GeminiClient) Close() error {
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	if c.httpClient != nil {
+		return c.httpClient.Close()
+	}
+	return nil
+}
+
+// GetConfig returns the client configuration
+func (c *GeminiClient) GetConfig() *common.CommonModelConfig {
+	return c.config
+}
+
+// BuildURL builds the full URL for a model method, e.g. ":generateContent"
+func (c *GeminiClient) BuildURL(method string) string {
+	return c.baseURL + "/models/" + url.PathEscape(c.config.Model) + method
+}
+
+// PrepareHeaders prepares HTTP headers for API requests
+func (c *GeminiClient) PrepareHeaders() map[string]string {
+	headers := make(map[string]string)
+	headers[common.HeaderContentType] = common.MIMETypeJSON
+	headers[common.HeaderAccept] = common.MIMETypeJSON
+	headers[GeminiHeaderAPIKey] = c.apiKey
+	return headers
+}
+
+// GetRateLimitInfo returns current rate limit information
+func (c *GeminiClient) GetRateLimitInfo() *common.RateLimitInfo {
+	// Shared by the clients of the account; adapted to the rate limit headers of responses
+	return c.rateLimiter.Info()
+}
+
+// claimRateLimiter switches to the rate limiter of the client's account with the
+// limits of its config, releasing the previous one
+func (c *GeminiClient) claimRateLimiter() {
+	limiter, release := common.ConfiguredRateLimiter(c.config, c.baseURL, c.apiKey)
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	c.rateLimiter, c.releaseRateLimiter = limiter, release
+	c.httpClient.SetRateLimiter(limiter)
+}
+
+// rateLimitContext returns ctx carrying the estimated tokens of req for the rate limiter
+func (c *GeminiClient) rateLimitContext(ctx context.Context, req *interfaces.PonchoModelRequest) context.Context {
+	return common.WithRateLimitTokens(ctx, common.EstimateRateLimitTokens(c.tokenizer, req, c.config.Provider, c.config.Model))
+}
+
+// IsHealthy checks if the Gemini client is configured
+func (c *GeminiClient) IsHealthy(ctx context.Context) error {
+	if c.apiKey == "" {
+		return fmt.Errorf("API key is not configured")
+	}
+	return nil
+}
+
+// GenerateContent makes a non-streaming generateContent call
+func (c *GeminiClient) GenerateContent(ctx context.Context, req *GeminiRequest) (*GeminiResponse, error) {
+	resp, err := c.post(ctx, c.BuildURL(common.GeminiGenerateEndpoint), req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var geminiResp GeminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to parse response", string(common.ProviderGemini), c.config.Model)
+	}
+
+	return &geminiResp, nil
+}
+
+// StreamGenerateContent makes a streaming call and passes every event to callback
+func (c *GeminiClient) StreamGenerateContent(ctx context.Context, req *GeminiRequest, callback func(*GeminiResponse) error) error {
+	resp, err := c.post(ctx, c.BuildURL(common.GeminiStreamEndpoint)+"?alt=sse", req)
+	if err != nil {
+		return err
+	}
+
+	return ProcessSSEStream(ctx, resp.Body, callback)
+}
+
+// post sends a request and returns the response on HTTP 200
+func (c *GeminiClient) post(ctx context.Context, endpoint string, req *GeminiRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to marshal request", string(common.ProviderGemini), c.config.Model)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeNetworkError, "Failed to create request", string(common.ProviderGemini), c.config.Model)
+	}
+
+	for key, value := range c.PrepareHeaders() {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeNetworkError, "Failed to make API request", string(common.ProviderGemini), c.config.Model)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, errorFromResponse(resp, c.config.Model)
+	}
+
+	return resp, nil
+}
+
+// errorFromResponse maps a non-200 response to a ModelError, keeping the API's error message
+func errorFromResponse(resp *http.Response, model string) *common.ModelError {
+	modelErr := common.ErrorFromAPIResponse(resp, string(common.ProviderGemini), model, func(body []byte) (string, interface{}) {
+		var apiErr GeminiError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return "", nil
+		}
+		return apiErr.Error.Message, apiErr.Error
+	})
+
+	// An invalid key is reported as 400 INVALID_ARGUMENT
+	if strings.Contains(modelErr.Message, "API key not valid") {
+		modelErr.Code = common.ErrorCodeInvalidAPIKey
+	}
+	return modelErr
+}
+
+// LoadImage returns the MIME type and raw base64 data of an image. data: URLs
+// are unpacked, http(s) URLs are downloaded.
+func (c *GeminiClient) LoadImage(ctx context.Context, media *interfaces.PonchoMediaPart) (*GeminiInlineData, error) {
+	if media == nil || media.URL == "" {
+		return nil, fmt.Errorf("media URL cannot be empty")
+	}
+
+	mediaURL := media.URL
+	if strings.HasPrefix(mediaURL, "data:") {
+		comma := strings.Index(mediaURL, ",")
+		if comma < 0 || !strings.HasSuffix(mediaURL[:comma], ";base64") {
+			return nil, fmt.Errorf("only base64 data URLs are supported")
+		}
+
+		mimeType := strings.TrimSuffix(strings.TrimPrefix(mediaURL[:comma], "data:"), ";base64")
+		if mimeType == "" {
+			mimeType = media.MimeType
+		}
+		return &GeminiInlineData{MIMEType: mimeTypeOrDefault(mimeType), Data: mediaURL[comma+1:]}, nil
+	}
+
+	if err := common.ValidateMediaURL(mediaURL); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image exceeds %d bytes", maxImageSize)
+	}
+
+	mimeType := media.MimeType
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	return &GeminiInlineData{
+		MIMEType: mimeTypeOrDefault(mimeType),
+		Data:     base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// mimeTypeOrDefault returns mimeType, or image/jpeg when it is empty or generic
+func mimeTypeOrDefault(mimeType string) string {
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		return "image/jpeg"
+	}
+	return mimeType
+}
+
+// LogRequest logs a request to the API
+func (c *GeminiClient) LogRequest(req *interfaces.PonchoModelRequest, requestID string) {
+	c.logger.Debug("Gemini API request",
+		"request_id", requestID,
+		"model", c.config.Model,
+		"messages_count", len(req.Messages),
+		"max_tokens", req.MaxTokens,
+		"temperature", req.Temperature,
+		"stream", req.Stream,
+		"tools_count", len(req.Tools))
+}
+
+// LogResponse logs a response from the API
+func (c *GeminiClient) LogResponse(resp *interfaces.PonchoModelResponse, requestID string, duration time.Duration) {
+	if resp != nil && resp.Usage != nil {
+		c.logger.Debug("Gemini API response",
+			"request_id", requestID,
+			"duration_ms", duration.Milliseconds(),
+			"prompt_tokens", resp.Usage.PromptTokens,
+			"completion_tokens", resp.Usage.CompletionTokens,
+			"finish_reason", resp.FinishReason)
+	}
+}
+
+// LogError logs an error from the API
+func (c *GeminiClient) LogError(err error, requestID string, duration time.Duration) {
+	c.logger.Error("Gemini API error",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds(),
+		"error", err.Error())
+}
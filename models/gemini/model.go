@@ -0,0 +1,630 @@
+// Package gemini provides the Google Gemini model implementation for PonchoFramework.
+// This file implements the PonchoModel interface on top of GeminiClient.
+//
+// Model Capabilities:
+// - Text generation, streaming and function calling
+// - Vision through inlineData image parts (images are always sent inline)
+// - System messages, sent as systemInstruction
+// - JSON output through "response_schema" or response_format "json_object"
+//
+// Message Mapping:
+// - Assistant turns use the "model" role
+// - Tool messages become functionResponse parts, named after the call ToolCallID answers (or Name)
+// - Tool results that are not JSON objects are wrapped as {"result": text}
+//
+// Configuration (map passed to Initialize, as built by the model factory):
+// - api_key, model_name, base_url, max_tokens, temperature, timeout, supports
+// - top_p, top_k, stop, response_format, response_schema
+//
+// Usage Example:
+//
+//	model := NewGeminiModel()
+//	err := model.Initialize(ctx, map[string]interface{}{
+//	    "api_key":         os.Getenv("GEMINI_API_KEY"),
+//	    "model_name":      "gemini-2.5-flash-lite",
+//	    "response_schema": map[string]interface{}{"type": "OBJECT", ...},
+//	})
+//	resp, err := model.Generate(ctx, request)
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// GeminiModel represents a Gemini model implementation
+type GeminiModel struct {
+	*base.PonchoBaseModel
+	client         *GeminiClient
+	topK           *int
+	responseSchema map[string]interface{}
+}
+
+// NewGeminiModel creates a new Gemini model instance
+func NewGeminiModel() *GeminiModel {
+	baseModel := base.NewPonchoBaseModel(common.GeminiDefaultModel, string(common.ProviderGemini), interfaces.ModelCapabilities{
+		Streaming: true,
+		Tools:     true,
+		Vision:    true,
+		System:    true,
+		JSONMode:  true,
+	})
+
+	return &GeminiModel{
+		PonchoBaseModel: baseModel,
+	}
+}
+
+// Name returns the configured model name
+func (m *GeminiModel) Name() string {
+	if m.client != nil {
+		return m.client.GetConfig().Model
+	}
+	return m.PonchoBaseModel.Name()
+}
+
+// Initialize initializes the Gemini model with configuration
+func (m *GeminiModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	commonConfig, err := m.convertConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to convert config: %w", err)
+	}
+
+	client, err := NewGeminiClient(commonConfig, m.GetLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	if supports, ok := config["supports"].(*interfaces.ModelCapabilities); ok && supports != nil {
+		m.SetCapabilities(*supports)
+	}
+
+	if err := m.PonchoBaseModel.Initialize(ctx, config); err != nil {
+		return fmt.Errorf("failed to initialize base model: %w", err)
+	}
+
+	m.client = client
+
+	m.GetLogger().Info("Gemini model initialized",
+		"model", commonConfig.Model,
+		"max_tokens", commonConfig.MaxTokens,
+		"temperature", commonConfig.Temperature)
+
+	return nil
+}
+
+// Generate generates a response using generateContent
+func (m *GeminiModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	geminiReq, requestID, err := m.prepareRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	geminiResp, err := m.client.GenerateContent(ctx, geminiReq)
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return nil, err
+	}
+
+	resp, err := m.convertResponse(geminiResp)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return nil, err
+	}
+
+	m.client.LogResponse(resp, requestID, duration)
+	return resp, nil
+}
+
+// GenerateStreaming generates a streaming response using streamGenerateContent.
+// Text and function calls are forwarded as they arrive; the final chunk
+// carries the finish reason and usage.
+func (m *GeminiModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if m.isInitialized() && !m.SupportsStreaming() {
+		return fmt.Errorf("model '%s' does not support streaming", m.Name())
+	}
+
+	geminiReq, requestID, err := m.prepareRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	toolCallCount := 0
+	var finishReason string
+	var usage *GeminiUsageMetadata
+	metadata := make(map[string]interface{})
+
+	err = m.client.StreamGenerateContent(ctx, geminiReq, func(event *GeminiResponse) error {
+		if event.UsageMetadata != nil {
+			usage = event.UsageMetadata
+		}
+		if event.ModelVersion != "" {
+			metadata["model"] = event.ModelVersion
+		}
+		if event.PromptFeedback != nil && event.PromptFeedback.BlockReason != "" {
+			return blockedPromptError(event.PromptFeedback.BlockReason, m.Name())
+		}
+		if len(event.Candidates) == 0 {
+			return nil
+		}
+
+		candidate := event.Candidates[0]
+		if candidate.FinishReason != "" {
+			finishReason = candidate.FinishReason
+		}
+
+		content := convertParts(candidate.Content.Parts, &toolCallCount)
+		if len(content) == 0 {
+			return nil
+		}
+		return callback(&interfaces.PonchoStreamChunk{
+			Delta: &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: content},
+		})
+	})
+	if err == nil {
+		if finishReason == "" {
+			err = fmt.Errorf("stream ended without a finish reason")
+		} else {
+			metadata["finish_reason"] = finishReason
+			err = callback(&interfaces.PonchoStreamChunk{
+				Delta:        &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: []*interfaces.PonchoContentPart{}},
+				Usage:        convertUsage(usage),
+				FinishReason: convertFinishReason(finishReason, toolCallCount > 0),
+				Done:         true,
+				Metadata:     metadata,
+			})
+		}
+	}
+
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return err
+	}
+
+	m.GetLogger().Debug("Gemini streaming generation completed",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds())
+
+	return nil
+}
+
+// HealthCheck implements interfaces.HealthChecker
+func (m *GeminiModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("Gemini model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
+// Shutdown shuts down the Gemini model
+func (m *GeminiModel) Shutdown(ctx context.Context) error {
+	if m.client != nil {
+		if err := m.client.Close(); err != nil {
+			m.GetLogger().Error("Failed to close Gemini client", "error", err.Error())
+			return fmt.Errorf("failed to close Gemini client: %w", err)
+		}
+	}
+
+	return m.PonchoBaseModel.Shutdown(ctx)
+}
+
+// Helper methods
+
+// prepareRequest validates a request and converts it to the API format
+func (m *GeminiModel) prepareRequest(ctx context.Context, req *interfaces.PonchoModelRequest) (*GeminiRequest, string, error) {
+	if !m.isInitialized() {
+		return nil, "", fmt.Errorf("model '%s' is not initialized", m.Name())
+	}
+
+	if err := m.ValidateRequest(req); err != nil {
+		return nil, "", fmt.Errorf("invalid request: %w", err)
+	}
+
+	requestID := m.generateRequestID()
+	m.client.LogRequest(req, requestID)
+
+	geminiReq, err := m.convertRequest(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert request: %w", err)
+	}
+
+	return geminiReq, requestID, nil
+}
+
+// convertConfig converts generic config to CommonModelConfig
+func (m *GeminiModel) convertConfig(config map[string]interface{}) (*common.CommonModelConfig, error) {
+	commonConfig := &common.CommonModelConfig{
+		Provider:    common.ProviderGemini,
+		Model:       common.GeminiDefaultModel,
+		MaxTokens:   4000,
+		Temperature: 0.7,
+		Timeout:     60 * time.Second,
+	}
+
+	if apiKey, ok := config["api_key"].(string); ok {
+		commonConfig.APIKey = apiKey
+	}
+
+	if model, ok := config["model_name"].(string); ok && model != "" {
+		commonConfig.Model = model
+	}
+
+	if maxTokens, ok := config["max_tokens"].(int); ok && maxTokens > 0 {
+		commonConfig.MaxTokens = maxTokens
+	}
+
+	if temperature, ok := common.ParamFloat(config["temperature"]); ok {
+		commonConfig.Temperature = temperature
+	}
+
+	if baseURL, ok := config["base_url"].(string); ok {
+		commonConfig.BaseURL = baseURL
+	}
+
+	switch timeout := config["timeout"].(type) {
+	case time.Duration:
+		if timeout > 0 {
+			commonConfig.Timeout = timeout
+		}
+	case string:
+		if timeout != "" {
+			parsed, err := time.ParseDuration(timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q: %w", timeout, err)
+			}
+			commonConfig.Timeout = parsed
+		}
+	}
+
+	if topP, ok := common.ParamFloat(config["top_p"]); ok {
+		commonConfig.TopP = &topP
+	}
+
+	if topK, exists := config["top_k"]; exists {
+		value, ok := common.ParamFloat(topK)
+		if !ok || value < 1 || value != float32(int(value)) {
+			return nil, fmt.Errorf("top_k must be a positive integer")
+		}
+		number := int(value)
+		m.topK = &number
+	}
+
+	if stop, ok := config["stop"]; ok {
+		if _, err := common.StopSequences(stop); err != nil {
+			return nil, err
+		}
+		commonConfig.Stop = stop
+	}
+
+	if format, ok := config["response_format"]; ok {
+		formatType, _ := format.(string)
+		if formatMap, isMap := format.(map[string]interface{}); isMap {
+			formatType, _ = formatMap["type"].(string)
+		}
+		switch responseFormat := common.ResponseFormat(formatType); responseFormat {
+		case common.ResponseFormatText, common.ResponseFormatJSONObject:
+			commonConfig.ResponseFormat = &responseFormat
+		default:
+			return nil, fmt.Errorf("unsupported response_format: %s", formatType)
+		}
+	}
+
+	if schema, exists := config["response_schema"]; exists {
+		schemaMap, ok := schema.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("response_schema must be an object")
+		}
+		m.responseSchema = schemaMap
+	}
+
+	if customParams, ok := config["custom_params"].(map[string]interface{}); ok {
+		if err := common.RateLimitFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+
+	return commonConfig, nil
+}
+
+// convertRequest converts a Poncho request to the generateContent format
+func (m *GeminiModel) convertRequest(ctx context.Context, req *interfaces.PonchoModelRequest) (*GeminiRequest, error) {
+	config := m.client.GetConfig()
+
+	// Logprobs are not read from generateContent responses
+	if err := common.CheckRequestParams(req, string(common.ProviderGemini), config.Model,
+		common.ParamLogProbs, common.ParamTopLogProbs); err != nil {
+		return nil, err
+	}
+	params, err := common.ResolveGenerationParams(req, config)
+	if err != nil {
+		return nil, err
+	}
+
+	maxTokens := config.MaxTokens
+	if req.MaxTokens != nil && *req.MaxTokens > 0 {
+		maxTokens = *req.MaxTokens
+	}
+
+	temperature := config.Temperature
+	if req.Temperature != nil {
+		temperature = *req.Temperature
+	}
+
+	generationConfig := &GeminiGenerationConfig{
+		Temperature:      &temperature,
+		MaxOutputTokens:  &maxTokens,
+		TopP:             params.TopP,
+		TopK:             m.topK,
+		StopSequences:    params.Stop,
+		Seed:             params.Seed,
+		PresencePenalty:  params.PresencePenalty,
+		FrequencyPenalty: params.FrequencyPenalty,
+	}
+	if m.responseSchema != nil {
+		generationConfig.ResponseMIMEType = GeminiMIMETypeJSON
+		generationConfig.ResponseSchema = m.responseSchema
+	} else if params.ResponseFormat != nil && *params.ResponseFormat == common.ResponseFormatJSONObject {
+		generationConfig.ResponseMIMEType = GeminiMIMETypeJSON
+	}
+	if params.Thinking != nil {
+		if *params.Thinking == common.ThinkingEnabled {
+			generationConfig.ThinkingConfig = &GeminiThinkingConfig{IncludeThoughts: true}
+		} else {
+			budget := 0
+			generationConfig.ThinkingConfig = &GeminiThinkingConfig{ThinkingBudget: &budget}
+		}
+	}
+
+	system, conversation := common.SplitSystemMessages(req.Messages)
+	geminiReq := &GeminiRequest{
+		Contents:         make([]GeminiContent, 0, len(conversation)),
+		GenerationConfig: generationConfig,
+	}
+	if system != "" {
+		geminiReq.SystemInstruction = &GeminiContent{Parts: []GeminiPart{{Text: system}}}
+	}
+
+	// Convert tools
+	if len(req.Tools) > 0 {
+		declarations := make([]GeminiFunctionDeclaration, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			declarations = append(declarations, GeminiFunctionDeclaration{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			})
+		}
+		geminiReq.Tools = []GeminiTool{{FunctionDeclarations: declarations}}
+	}
+
+	// Function responses are matched by name; remember the name behind each call ID
+	callNames := make(map[string]string)
+
+	// Convert messages, merging consecutive turns of the same role
+	for _, msg := range conversation {
+		content, err := m.convertMessage(ctx, msg, callNames)
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(geminiReq.Contents) - 1
+		if last >= 0 && geminiReq.Contents[last].Role == content.Role {
+			geminiReq.Contents[last].Parts = append(geminiReq.Contents[last].Parts, content.Parts...)
+			continue
+		}
+		geminiReq.Contents = append(geminiReq.Contents, content)
+	}
+
+	return geminiReq, nil
+}
+
+// convertMessage converts a Poncho message to a Gemini content turn
+func (m *GeminiModel) convertMessage(ctx context.Context, msg *interfaces.PonchoMessage, callNames map[string]string) (GeminiContent, error) {
+	switch msg.Role {
+	case interfaces.PonchoRoleUser:
+		return m.convertParts(ctx, GeminiRoleUser, msg.Content, callNames)
+	case interfaces.PonchoRoleAssistant:
+		return m.convertParts(ctx, GeminiRoleModel, msg.Content, callNames)
+	case interfaces.PonchoRoleTool:
+		return convertFunctionResponse(msg, callNames), nil
+	default:
+		return GeminiContent{}, fmt.Errorf("unsupported role: %s", msg.Role)
+	}
+}
+
+// convertParts converts content parts of a user or model turn
+func (m *GeminiModel) convertParts(ctx context.Context, role string, parts []*interfaces.PonchoContentPart, callNames map[string]string) (GeminiContent, error) {
+	content := GeminiContent{Role: role, Parts: make([]GeminiPart, 0, len(parts))}
+
+	for _, part := range parts {
+		switch part.Type {
+		case interfaces.PonchoContentTypeText:
+			if part.Text != "" {
+				content.Parts = append(content.Parts, GeminiPart{Text: part.Text})
+			}
+		case interfaces.PonchoContentTypeMedia:
+			inlineData, err := m.client.LoadImage(ctx, part.Media)
+			if err != nil {
+				return GeminiContent{}, fmt.Errorf("failed to load image: %w", err)
+			}
+			content.Parts = append(content.Parts, GeminiPart{InlineData: inlineData})
+		case interfaces.PonchoContentTypeTool:
+			if part.Tool != nil {
+				callNames[part.Tool.ID] = part.Tool.Name
+				content.Parts = append(content.Parts, GeminiPart{FunctionCall: &GeminiFunctionCall{
+					Name: part.Tool.Name,
+					Args: part.Tool.Args,
+				}})
+			}
+		case interfaces.PonchoContentTypeReasoning:
+			// Thoughts from earlier turns are not sent back
+		default:
+			return GeminiContent{}, fmt.Errorf("unsupported content type: %s", part.Type)
+		}
+	}
+
+	return content, nil
+}
+
+// convertFunctionResponse converts a tool message to a functionResponse part
+func convertFunctionResponse(msg *interfaces.PonchoMessage, callNames map[string]string) GeminiContent {
+	var name string
+	if msg.Name != nil {
+		name = *msg.Name
+	}
+	if functionName, isCallID := callNames[common.ToolCallID(msg)]; isCallID {
+		name = functionName
+	}
+
+	if name == "" {
+		for _, part := range msg.Content {
+			if part.Type == interfaces.PonchoContentTypeToolResult && part.ToolResult != nil {
+				name = part.ToolResult.Name
+			}
+		}
+	}
+
+	text := common.MessageText(msg)
+	response := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		response = map[string]interface{}{"result": text}
+	}
+
+	return GeminiContent{
+		Role: GeminiRoleUser,
+		Parts: []GeminiPart{{FunctionResponse: &GeminiFunctionResponse{
+			Name:     name,
+			Response: response,
+		}}},
+	}
+}
+
+// convertResponse converts a generateContent response to a Poncho response
+func (m *GeminiModel) convertResponse(geminiResp *GeminiResponse) (*interfaces.PonchoModelResponse, error) {
+	if geminiResp == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+
+	if geminiResp.PromptFeedback != nil && geminiResp.PromptFeedback.BlockReason != "" {
+		return nil, blockedPromptError(geminiResp.PromptFeedback.BlockReason, m.Name())
+	}
+
+	if len(geminiResp.Candidates) == 0 {
+		return nil, fmt.Errorf("response contains no candidates")
+	}
+
+	candidate := geminiResp.Candidates[0]
+	toolCallCount := 0
+	content := convertParts(candidate.Content.Parts, &toolCallCount)
+
+	return &interfaces.PonchoModelResponse{
+		Message: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: content,
+		},
+		Usage:        convertUsage(geminiResp.UsageMetadata),
+		FinishReason: convertFinishReason(candidate.FinishReason, toolCallCount > 0),
+		Metadata: map[string]interface{}{
+			"id":            geminiResp.ResponseID,
+			"model":         geminiResp.ModelVersion,
+			"finish_reason": candidate.FinishReason,
+		},
+	}, nil
+}
+
+// convertParts converts response parts to Poncho content parts, skipping
+// thought summaries. Calls without an ID get one numbered through toolCallCount.
+func convertParts(parts []GeminiPart, toolCallCount *int) []*interfaces.PonchoContentPart {
+	content := make([]*interfaces.PonchoContentPart, 0, len(parts))
+
+	for _, part := range parts {
+		switch {
+		case part.Thought:
+			continue
+		case part.FunctionCall != nil:
+			*toolCallCount++
+			id := part.FunctionCall.ID
+			if id == "" {
+				id = fmt.Sprintf("gemini_call_%d", *toolCallCount)
+			}
+			args := part.FunctionCall.Args
+			if args == nil {
+				args = make(map[string]interface{})
+			}
+			content = append(content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeTool,
+				Tool: &interfaces.PonchoToolPart{ID: id, Name: part.FunctionCall.Name, Args: args},
+			})
+		case part.Text != "":
+			content = append(content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeText,
+				Text: part.Text,
+			})
+		}
+	}
+
+	return content
+}
+
+// convertFinishReason maps a Gemini finish reason to a Poncho finish reason.
+// Gemini reports STOP after function calls, so calls take precedence.
+func convertFinishReason(finishReason string, hasToolCalls bool) interfaces.PonchoFinishReason {
+	if hasToolCalls {
+		return interfaces.PonchoFinishReasonTool
+	}
+
+	switch finishReason {
+	case GeminiFinishReasonMaxTokens:
+		return interfaces.PonchoFinishReasonLength
+	case GeminiFinishReasonSafety, GeminiFinishReasonRecitation, GeminiFinishReasonBlocklist,
+		GeminiFinishReasonProhibitedContent, GeminiFinishReasonSPII:
+		return common.ToPonchoFinishReason(common.FinishReasonFilter)
+	case GeminiFinishReasonMalformedCall:
+		return interfaces.PonchoFinishReasonError
+	default:
+		return interfaces.PonchoFinishReasonStop
+	}
+}
+
+// convertUsage converts usage metadata to Poncho usage. Thinking tokens are
+// billed as output, so they count as completion tokens.
+func convertUsage(usage *GeminiUsageMetadata) *interfaces.PonchoUsage {
+	if usage == nil {
+		return &interfaces.PonchoUsage{}
+	}
+
+	completionTokens := usage.CandidatesTokenCount + usage.ThoughtsTokenCount
+	return &interfaces.PonchoUsage{
+		PromptTokens:     usage.PromptTokenCount,
+		CompletionTokens: completionTokens,
+		TotalTokens:      usage.PromptTokenCount + completionTokens,
+	}
+}
+
+// blockedPromptError reports a prompt rejected by Gemini's safety filters
+func blockedPromptError(blockReason, model string) error {
+	return common.NewContentFilteredError("prompt blocked: "+blockReason, string(common.ProviderGemini), model)
+}
+
+// generateRequestID generates a unique request ID
+func (m *GeminiModel) generateRequestID() string {
+	return fmt.Sprintf("gemini_%d", time.Now().UnixNano())
+}
+
+// isInitialized checks if model is properly initialized
+func (m *GeminiModel) isInitialized() bool {
+	return m.client != nil
+}
@@ -0,0 +1,261 @@
+package gemini
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOfflineModel returns a model for testing conversions; it never reaches the API
+func newOfflineModel(t *testing.T, extra map[string]interface{}) *GeminiModel {
+	t.Helper()
+
+	config := map[string]interface{}{
+		"api_key":     "AIza-test-key",
+		"base_url":    "http://localhost:1/v1beta",
+		"max_tokens":  1000,
+		"temperature": 0.5,
+	}
+	for key, value := range extra {
+		config[key] = value
+	}
+
+	model := NewGeminiModel()
+	modeltest.Initialize(t, model, config)
+	return model
+}
+
+func TestGeminiModel_ConvertRequest(t *testing.T) {
+	model := newOfflineModel(t, nil)
+
+	geminiReq, err := model.convertRequest(context.Background(), modeltest.Conversation())
+	require.NoError(t, err)
+
+	// The system prompt is a systemInstruction; generation settings are grouped
+	require.NotNil(t, geminiReq.SystemInstruction)
+	assert.Equal(t, []GeminiPart{{Text: "You are a fashion expert"}}, geminiReq.SystemInstruction.Parts)
+	assert.Equal(t, 1000, *geminiReq.GenerationConfig.MaxOutputTokens)
+	assert.Equal(t, float32(0.5), *geminiReq.GenerationConfig.Temperature)
+	require.Len(t, geminiReq.Contents, 3)
+
+	// Images are sent inline as parts of the user turn
+	user := geminiReq.Contents[0]
+	assert.Equal(t, GeminiRoleUser, user.Role)
+	assert.Equal(t, []GeminiPart{
+		{Text: "Describe the dress"},
+		{InlineData: &GeminiInlineData{MIMEType: "image/png", Data: "AAAA"}},
+	}, user.Parts)
+
+	// The assistant is the "model" role, tool calls are functionCall parts
+	call := geminiReq.Contents[1]
+	assert.Equal(t, GeminiRoleModel, call.Role)
+	assert.Equal(t, &GeminiFunctionCall{Name: "get_article", Args: map[string]interface{}{"id": 42}}, call.Parts[0].FunctionCall)
+
+	// Function responses are matched by function name, not call ID, and carry an object
+	response := geminiReq.Contents[2].Parts[0].FunctionResponse
+	assert.Equal(t, GeminiRoleUser, geminiReq.Contents[2].Role)
+	assert.Equal(t, "get_article", response.Name)
+	assert.Equal(t, map[string]interface{}{"color": "red"}, response.Response)
+
+	require.Len(t, geminiReq.Tools, 1)
+	assert.Equal(t, "get_article", geminiReq.Tools[0].FunctionDeclarations[0].Name)
+}
+
+func TestConvertFunctionResponse(t *testing.T) {
+	// Results that are not JSON objects are wrapped under "result"
+	callID := "call_7"
+	content := convertFunctionResponse(&interfaces.PonchoMessage{
+		Role:    interfaces.PonchoRoleTool,
+		Name:    &callID,
+		Content: []*interfaces.PonchoContentPart{modeltest.Text("not found")},
+	}, map[string]string{"call_7": "get_stock"})
+	assert.Equal(t, "get_stock", content.Parts[0].FunctionResponse.Name)
+	assert.Equal(t, map[string]interface{}{"result": "not found"}, content.Parts[0].FunctionResponse.Response)
+
+	// Without a known call, the tool result names the function
+	content = convertFunctionResponse(&interfaces.PonchoMessage{
+		Role: interfaces.PonchoRoleTool,
+		Content: []*interfaces.PonchoContentPart{{
+			Type:       interfaces.PonchoContentTypeToolResult,
+			ToolResult: &interfaces.PonchoToolResultPart{ToolCallID: "call_8", Name: "get_price", Content: map[string]interface{}{"price": 990}},
+		}},
+	}, map[string]string{})
+	assert.Equal(t, "get_price", content.Parts[0].FunctionResponse.Name)
+	assert.Equal(t, float64(990), content.Parts[0].FunctionResponse.Response["price"])
+}
+
+func TestGeminiClient_LoadImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n-image-bytes")
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(png)
+	})
+	model := newOfflineModel(t, nil)
+
+	// Downloaded images are inlined with the detected MIME type
+	data, err := model.client.LoadImage(context.Background(), &interfaces.PonchoMediaPart{URL: url + "/dress"})
+	require.NoError(t, err)
+	assert.Equal(t, GeminiInlineData{MIMEType: "image/png", Data: base64.StdEncoding.EncodeToString(png)}, *data)
+
+	_, err = model.client.LoadImage(context.Background(), &interfaces.PonchoMediaPart{URL: "data:image/png,raw-bytes"})
+	assert.Error(t, err, "only base64 data URLs are supported")
+}
+
+func TestConvertParts(t *testing.T) {
+	count := 0
+	content := convertParts([]GeminiPart{
+		{Text: "thinking it over", Thought: true},
+		{Text: "Checking the article"},
+		{FunctionCall: &GeminiFunctionCall{Name: "get_article", Args: map[string]interface{}{"id": float64(43)}}},
+		{FunctionCall: &GeminiFunctionCall{ID: "fc_1", Name: "get_stock"}},
+	}, &count)
+
+	// Thought summaries are skipped, calls without an ID are numbered
+	require.Len(t, content, 3)
+	assert.Equal(t, "Checking the article", content[0].Text)
+	assert.Equal(t, "gemini_call_1", content[1].Tool.ID)
+	assert.Equal(t, float64(43), content[1].Tool.Args["id"])
+	assert.Equal(t, "fc_1", content[2].Tool.ID)
+	assert.NotNil(t, content[2].Tool.Args)
+	assert.Equal(t, 2, count)
+}
+
+func TestGeminiModel_Generate(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-2.5-flash:generateContent", r.URL.Path)
+		assert.Equal(t, "AIza-test-key", r.Header.Get("x-goog-api-key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"functionCall": {"name": "get_article", "args": {"id": 43}}}]},
+				"finishReason": "STOP",
+				"index": 0
+			}],
+			"usageMetadata": {"promptTokenCount": 50, "candidatesTokenCount": 20, "thoughtsTokenCount": 5, "totalTokenCount": 75},
+			"modelVersion": "gemini-2.5-flash",
+			"responseId": "resp_1"
+		}`)
+	})
+
+	model := NewGeminiModel()
+	modeltest.Initialize(t, model, map[string]interface{}{
+		"api_key":    "AIza-test-key",
+		"base_url":   url + "/v1beta",
+		"model_name": "gemini-2.5-flash",
+	})
+
+	resp, err := model.Generate(context.Background(), modeltest.Conversation())
+	require.NoError(t, err)
+
+	// Gemini reports STOP after function calls; thinking is billed as completion
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, resp.FinishReason)
+	assert.Equal(t, "get_article", resp.Message.Content[0].Tool.Name)
+	assert.Equal(t, 25, resp.Usage.CompletionTokens)
+	assert.Equal(t, 75, resp.Usage.TotalTokens)
+	assert.Equal(t, "STOP", resp.Metadata["finish_reason"])
+	assert.Equal(t, "resp_1", resp.Metadata["id"])
+}
+
+func TestGeminiModel_ResponseSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":       "OBJECT",
+		"properties": map[string]interface{}{"color": map[string]interface{}{"type": "STRING"}},
+	}
+	model := newOfflineModel(t, map[string]interface{}{"response_schema": schema, "top_k": 40})
+
+	geminiReq, err := model.convertRequest(context.Background(), modeltest.Request(modeltest.Text("Which color?")))
+	require.NoError(t, err)
+	assert.Equal(t, GeminiMIMETypeJSON, geminiReq.GenerationConfig.ResponseMIMEType)
+	assert.Equal(t, "OBJECT", geminiReq.GenerationConfig.ResponseSchema["type"])
+	assert.Equal(t, 40, *geminiReq.GenerationConfig.TopK)
+
+	// Without a schema, JSON mode only sets the MIME type
+	req := modeltest.Request(modeltest.Text("Which color?"))
+	req.ResponseFormat = interfaces.PonchoResponseFormatJSONObject
+	geminiReq, err = newOfflineModel(t, nil).convertRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, GeminiMIMETypeJSON, geminiReq.GenerationConfig.ResponseMIMEType)
+	assert.Nil(t, geminiReq.GenerationConfig.ResponseSchema)
+}
+
+func TestGeminiModel_Thinking(t *testing.T) {
+	model := newOfflineModel(t, nil)
+
+	enabled, disabled := true, false
+	req := modeltest.Request(modeltest.Text("Hello"))
+	req.Thinking = &enabled
+	geminiReq, err := model.convertRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, geminiReq.GenerationConfig.ThinkingConfig.IncludeThoughts)
+
+	// Switching thinking off is a zero budget
+	req.Thinking = &disabled
+	geminiReq, err = model.convertRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, *geminiReq.GenerationConfig.ThinkingConfig.ThinkingBudget)
+}
+
+func TestGeminiModel_BlockedPrompt(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"promptFeedback": {"blockReason": "SAFETY"}}`)
+	})
+
+	model := NewGeminiModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"api_key": "AIza-test-key", "base_url": url + "/v1beta"})
+
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Hello")))
+	modeltest.RequireModelError(t, err, common.ErrorCodeContentFiltered)
+}
+
+func TestGeminiModel_ErrorResponse(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": {"code": 400, "message": "API key not valid. Please pass a valid API key.", "status": "INVALID_ARGUMENT"}}`)
+	})
+
+	model := NewGeminiModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"api_key": "AIza-test-key", "base_url": url + "/v1beta"})
+
+	// An invalid key is reported as 400 INVALID_ARGUMENT
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Hello")))
+	modelErr := modeltest.RequireModelError(t, err, common.ErrorCodeInvalidAPIKey)
+	assert.Contains(t, modelErr.Message, "API key not valid")
+
+	var details GeminiErrorDetail
+	data, _ := json.Marshal(modelErr.Details)
+	require.NoError(t, json.Unmarshal(data, &details))
+	assert.Equal(t, "INVALID_ARGUMENT", details.Status)
+}
+
+func TestConvertFinishReason(t *testing.T) {
+	assert.Equal(t, interfaces.PonchoFinishReasonStop, convertFinishReason("STOP", false))
+	assert.Equal(t, interfaces.PonchoFinishReasonLength, convertFinishReason("MAX_TOKENS", false))
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, convertFinishReason("STOP", true))
+	assert.Equal(t, common.ToPonchoFinishReason(common.FinishReasonFilter), convertFinishReason("SAFETY", false))
+}
+
+func TestGeminiModel_InitializeValidation(t *testing.T) {
+	err := NewGeminiModel().Initialize(context.Background(), map[string]interface{}{"model_name": "gemini-2.5-flash"})
+	assert.Error(t, err, "api_key is required")
+
+	err = NewGeminiModel().Initialize(context.Background(), map[string]interface{}{
+		"api_key": "AIza-test-key",
+		"top_k":   0,
+	})
+	assert.Error(t, err, "top_k must be positive")
+
+	err = NewGeminiModel().Initialize(context.Background(), map[string]interface{}{
+		"api_key":         "AIza-test-key",
+		"response_schema": "OBJECT",
+	})
+	assert.Error(t, err, "response_schema must be an object")
+}
@@ -0,0 +1,66 @@
+// Package gemini provides streaming response processing for the Gemini API.
+// This file implements Server-Sent Events parsing for streamGenerateContent
+// with alt=sse.
+//
+// Every event is a complete GeminiResponse holding the next slice of the
+// candidate: text parts are deltas, functionCall parts arrive whole. The
+// finish reason and usage metadata come with the last events. There is no
+// end marker; the stream ends when the server closes the connection.
+//
+// Data Flow:
+// 1. HTTP response with SSE content-type
+// 2. Line-by-line parsing with data: prefix handling
+// 3. Text and function calls forwarded as they arrive
+// 4. Finish reason and usage kept until the body ends, then one final chunk
+package gemini
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ProcessSSEStream processes a Server-Sent Events stream of generateContent responses
+func ProcessSSEStream(ctx context.Context, body io.ReadCloser, callback func(*GeminiResponse) error) error {
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	// Events with long function call arguments can exceed the default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+
+		var event GeminiResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		if err := callback(&event); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream processing error: %w", err)
+	}
+
+	return nil
+}
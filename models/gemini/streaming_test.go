@@ -0,0 +1,89 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSSEStream(t *testing.T) {
+	// Events are separated by CRLF and there is no end marker
+	stream := "data: {\"responseId\":\"r1\"}\r\n\r\n" +
+		": comment\r\n" +
+		"data:{\"responseId\":\"r2\"}\r\n\r\n"
+
+	var ids []string
+	err := ProcessSSEStream(context.Background(), modeltest.Body(stream), func(event *GeminiResponse) error {
+		ids = append(ids, event.ResponseID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2"}, ids)
+
+	err = ProcessSSEStream(context.Background(), modeltest.Body("data: {not json}\r\n\r\n"), func(*GeminiResponse) error { return nil })
+	assert.Error(t, err)
+}
+
+// streamingModel serves the given events from streamGenerateContent
+func streamingModel(t *testing.T, events ...string) *GeminiModel {
+	t.Helper()
+
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-2.5-flash:streamGenerateContent", r.URL.Path)
+		assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprintf(w, "data: %s\r\n\r\n", event)
+		}
+	})
+
+	model := NewGeminiModel()
+	modeltest.Initialize(t, model, map[string]interface{}{
+		"api_key":    "AIza-test-key",
+		"base_url":   url + "/v1beta",
+		"model_name": "gemini-2.5-flash",
+	})
+	return model
+}
+
+func TestGeminiModel_GenerateStreaming(t *testing.T) {
+	model := streamingModel(t,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Looking "}]}}],"modelVersion":"gemini-2.5-flash"}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"it up"},{"functionCall":{"name":"get_article","args":{"id":42}}}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":3,"totalTokenCount":8}}`,
+	)
+
+	chunks, err := modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Hello")))
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+
+	// Text and whole function calls are forwarded as they arrive
+	assert.Equal(t, "Looking ", chunks[0].Delta.Content[0].Text)
+	require.Len(t, chunks[1].Delta.Content, 2)
+	assert.Equal(t, "gemini_call_1", chunks[1].Delta.Content[1].Tool.ID)
+
+	final := chunks[2]
+	assert.True(t, final.Done)
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, final.FinishReason)
+	assert.Equal(t, 8, final.Usage.TotalTokens)
+	assert.Equal(t, "gemini-2.5-flash", final.Metadata["model"])
+}
+
+func TestGeminiModel_StreamIncomplete(t *testing.T) {
+	// Without a finish reason the stream was cut off
+	model := streamingModel(t, `{"candidates":[{"content":{"role":"model","parts":[{"text":"Looking"}]}}]}`)
+	_, err := modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Hello")))
+	assert.Error(t, err)
+
+	model = streamingModel(t, `{"promptFeedback":{"blockReason":"SAFETY"}}`)
+	_, err = modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Hello")))
+	modeltest.RequireModelError(t, err, common.ErrorCodeContentFiltered)
+}
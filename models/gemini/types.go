@@ -0,0 +1,159 @@
+// Package gemini provides type definitions for the Google Gemini generateContent REST API.
+//
+// Protocol Notes:
+// - Messages are "contents" with role "user" or "model", each a list of parts
+// - The system prompt is the top-level systemInstruction
+// - Images are inlineData parts with raw base64 data and a MIME type
+// - Tools are functionDeclarations; calls come back as functionCall parts
+// - Tool results are sent as functionResponse parts, matched by function name
+// - JSON output is requested with responseMimeType and an optional responseSchema
+// - streamGenerateContent with alt=sse sends one full response object per event
+//
+// Usage Example:
+//
+//	req := &GeminiRequest{
+//	    Contents: []GeminiContent{{Role: "user", Parts: []GeminiPart{{Text: "Describe"}}}},
+//	    GenerationConfig: &GeminiGenerationConfig{ResponseMIMEType: GeminiMIMETypeJSON},
+//	}
+package gemini
+
+// GeminiContent represents a conversation turn
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model"; empty for systemInstruction
+	Parts []GeminiPart `json:"parts"`
+}
+
+// GeminiPart represents one part of a turn. Exactly one field is set.
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	InlineData       *GeminiInlineData       `json:"inlineData,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"` // set on thinking summaries
+}
+
+// GeminiInlineData represents inline binary data such as an image
+type GeminiInlineData struct {
+	MIMEType string `json:"mimeType"`
+	Data     string `json:"data"` // raw base64, no data: prefix
+}
+
+// GeminiFunctionCall represents a function call requested by the model
+type GeminiFunctionCall struct {
+	ID   string                 `json:"id,omitempty"`
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// GeminiFunctionResponse represents the result of a function call
+type GeminiFunctionResponse struct {
+	ID       string                 `json:"id,omitempty"`
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
+}
+
+// GeminiTool represents a tool entry holding function declarations
+type GeminiTool struct {
+	FunctionDeclarations []GeminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+// GeminiFunctionDeclaration represents a function the model may call
+type GeminiFunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// GeminiGenerationConfig represents generation parameters
+type GeminiGenerationConfig struct {
+	Temperature      *float32               `json:"temperature,omitempty"`
+	MaxOutputTokens  *int                   `json:"maxOutputTokens,omitempty"`
+	TopP             *float32               `json:"topP,omitempty"`
+	TopK             *int                   `json:"topK,omitempty"`
+	StopSequences    []string               `json:"stopSequences,omitempty"`
+	Seed             *int                   `json:"seed,omitempty"`
+	PresencePenalty  *float32               `json:"presencePenalty,omitempty"`
+	FrequencyPenalty *float32               `json:"frequencyPenalty,omitempty"`
+	ResponseMIMEType string                 `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]interface{} `json:"responseSchema,omitempty"`
+	ThinkingConfig   *GeminiThinkingConfig  `json:"thinkingConfig,omitempty"`
+}
+
+// GeminiThinkingConfig controls thinking; a zero budget turns it off
+type GeminiThinkingConfig struct {
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+	ThinkingBudget  *int `json:"thinkingBudget,omitempty"`
+}
+
+// GeminiRequest represents a generateContent request
+type GeminiRequest struct {
+	Contents          []GeminiContent         `json:"contents"`
+	SystemInstruction *GeminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []GeminiTool            `json:"tools,omitempty"`
+	GenerationConfig  *GeminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// GeminiCandidate represents a response candidate
+type GeminiCandidate struct {
+	Content      GeminiContent `json:"content"`
+	FinishReason string        `json:"finishReason,omitempty"`
+	Index        int           `json:"index"`
+}
+
+// GeminiUsageMetadata represents token usage information
+type GeminiUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+}
+
+// GeminiPromptFeedback reports why a prompt was blocked
+type GeminiPromptFeedback struct {
+	BlockReason string `json:"blockReason,omitempty"`
+}
+
+// GeminiResponse represents a generateContent response, or one event of a stream
+type GeminiResponse struct {
+	Candidates     []GeminiCandidate     `json:"candidates"`
+	PromptFeedback *GeminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *GeminiUsageMetadata  `json:"usageMetadata,omitempty"`
+	ModelVersion   string                `json:"modelVersion,omitempty"`
+	ResponseID     string                `json:"responseId,omitempty"`
+}
+
+// GeminiError represents an error response from the API
+type GeminiError struct {
+	Error GeminiErrorDetail `json:"error"`
+}
+
+// GeminiErrorDetail represents error details
+type GeminiErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"` // e.g. "INVALID_ARGUMENT"
+}
+
+// Constants for Gemini API
+const (
+	// Headers
+	GeminiHeaderAPIKey = "x-goog-api-key"
+
+	// Roles
+	GeminiRoleUser  = "user"
+	GeminiRoleModel = "model"
+
+	// Response MIME types
+	GeminiMIMETypeJSON = "application/json"
+
+	// Finish reasons
+	GeminiFinishReasonStop              = "STOP"
+	GeminiFinishReasonMaxTokens         = "MAX_TOKENS"
+	GeminiFinishReasonSafety            = "SAFETY"
+	GeminiFinishReasonRecitation        = "RECITATION"
+	GeminiFinishReasonBlocklist         = "BLOCKLIST"
+	GeminiFinishReasonProhibitedContent = "PROHIBITED_CONTENT"
+	GeminiFinishReasonSPII              = "SPII"
+	GeminiFinishReasonMalformedCall     = "MALFORMED_FUNCTION_CALL"
+)
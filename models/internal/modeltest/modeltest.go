@@ -0,0 +1,106 @@
+// Package modeltest provides the scaffolding shared by the tests of the HTTP
+// model providers: stand-in API servers, request and conversation builders,
+// stream collection and error assertions. Provider tests use it to exercise
+// what each API does differently without repeating the plumbing.
+package modeltest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// ToolCallID is the ID of the tool call in Conversation
+const ToolCallID = "call_1"
+
+// Server starts a stand-in API server for the duration of the test and returns its URL
+func Server(t testing.TB, handler http.HandlerFunc) string {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// Initialize initializes model with config and shuts it down when the test ends
+func Initialize(t testing.TB, model interfaces.PonchoModel, config map[string]interface{}) {
+	t.Helper()
+
+	require.NoError(t, model.Initialize(context.Background(), config))
+	t.Cleanup(func() { model.Shutdown(context.Background()) })
+}
+
+// Text returns a text content part
+func Text(text string) *interfaces.PonchoContentPart {
+	return &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeText, Text: text}
+}
+
+// Image returns a media content part referring to an image URL or data URL
+func Image(url string) *interfaces.PonchoContentPart {
+	return &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeMedia, Media: &interfaces.PonchoMediaPart{URL: url}}
+}
+
+// Request returns a request with a single user message made of parts
+func Request(parts ...*interfaces.PonchoContentPart) *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{{Role: interfaces.PonchoRoleUser, Content: parts}},
+	}
+}
+
+// Conversation returns a request covering what providers encode differently: a
+// system prompt, a user message with an image (a base64 PNG data URL), an
+// assistant tool call (ToolCallID) and the tool result answering it
+func Conversation() *interfaces.PonchoModelRequest {
+	toolCallID := ToolCallID
+	return &interfaces.PonchoModelRequest{
+		Messages: []*interfaces.PonchoMessage{
+			{Role: interfaces.PonchoRoleSystem, Content: []*interfaces.PonchoContentPart{Text("You are a fashion expert")}},
+			{Role: interfaces.PonchoRoleUser, Content: []*interfaces.PonchoContentPart{Text("Describe the dress"), Image("data:image/png;base64,AAAA")}},
+			{Role: interfaces.PonchoRoleAssistant, Content: []*interfaces.PonchoContentPart{{
+				Type: interfaces.PonchoContentTypeTool,
+				Tool: &interfaces.PonchoToolPart{ID: ToolCallID, Name: "get_article", Args: map[string]interface{}{"id": 42}},
+			}}},
+			{Role: interfaces.PonchoRoleTool, Name: &toolCallID, Content: []*interfaces.PonchoContentPart{Text(`{"color": "red"}`)}},
+		},
+		Tools: []*interfaces.PonchoToolDef{{
+			Name:        "get_article",
+			Description: "Fetch an article",
+			Parameters:  map[string]interface{}{"type": "object"},
+		}},
+	}
+}
+
+// Collect streams req from model and returns the chunks received
+func Collect(t testing.TB, model interfaces.PonchoModel, req *interfaces.PonchoModelRequest) ([]*interfaces.PonchoStreamChunk, error) {
+	t.Helper()
+
+	var chunks []*interfaces.PonchoStreamChunk
+	err := model.GenerateStreaming(context.Background(), req, func(chunk *interfaces.PonchoStreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	return chunks, err
+}
+
+// Body returns data as a response body, for feeding stream parsers
+func Body(data string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(data))
+}
+
+// RequireModelError asserts that err is a ModelError with code and returns it
+func RequireModelError(t testing.TB, err error, code common.ModelErrorCode) *common.ModelError {
+	t.Helper()
+
+	modelErr, ok := common.IsModelError(err)
+	require.True(t, ok, "expected ModelError, got %v", err)
+	require.Equal(t, code, modelErr.Code, "unexpected error: %v", modelErr)
+	return modelErr
+}
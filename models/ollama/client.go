@@ -0,0 +1,368 @@
+// Package ollama provides an Ollama client for PonchoFramework.
+// This file implements the HTTP client for Ollama's native /api/chat endpoint,
+// used to run flows against local models on laptops and air-gapped machines.
+//
+// Key Features:
+// - No authentication by default; optional Bearer api_key for proxied instances
+// - Non-streaming and NDJSON streaming chat requests
+// - Images inlined as base64: data: URLs unpacked, http(s) URLs downloaded
+// - Ollama error messages preserved in ModelError
+//
+// Usage Example:
+//
+//	config := &common.CommonModelConfig{
+//	    Model: "llava",
+//	    MaxTokens: 2000,
+//	    Temperature: 0.2,
+//	    Timeout: 5 * time.Minute,
+//	}
+//	client, _ := NewOllamaClient(config, logger)
+//	resp, err := client.Chat(ctx, request)
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// maxImageSize limits images downloaded for a request
+const maxImageSize = 20 * 1024 * 1024
+
+// OllamaClient represents a client for the Ollama API
+type OllamaClient struct {
+	httpClient  *common.HTTPClient
+	config      *common.CommonModelConfig
+	logger      interfaces.Logger
+	apiKey      string
+	baseURL     string
+	rateLimiter *common.RateLimiter
+	tokenizer   *common.Tokenizer
+
+	releaseRateLimiter func()
+}
+
+// NewOllamaClient creates a new Ollama client
+func NewOllamaClient(config *common.CommonModelConfig, logger interfaces.Logger) (*OllamaClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Create HTTP client from a copy of the shared defaults
+	httpConfig := common.DefaultHTTPConfig
+	httpConfig.Timeout = config.Timeout
+	httpConfig.UserAgent = "PonchoFramework-Ollama/1.0"
+
+	// Local servers fail fast or not at all, one retry is enough
+	retryConfig := common.DefaultRetryConfig
+	retryConfig.MaxAttempts = 2
+	retryConfig.BaseDelay = 500 * time.Millisecond
+	retryConfig.MaxDelay = 5 * time.Second
+
+	httpClient, err := common.NewHTTPClient(&httpConfig, retryConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = common.OllamaDefaultBaseURL
+	}
+
+	client := &OllamaClient{
+		httpClient: httpClient,
+		config:     config,
+		logger:     logger,
+		apiKey:     config.APIKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		tokenizer:  common.NewTokenizer(logger),
+	}
+	client.claimRateLimiter()
+
+	logger.Info("Ollama client created",
+		"model", config.Model,
+		"base_url", client.baseURL,
+		"timeout", config.Timeout)
+
+	return client, nil
+}
+
+// validateConfig validates Ollama configuration
+func validateConfig(config *common.CommonModelConfig) error {
+	if config.Model == "" {
+		return fmt.Errorf("model name is required")
+	}
+
+	if config.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be positive")
+	}
+
+	if config.Temperature < 0 || config.Temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+
+	if config.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+// Close closes the Ollama client and cleans up resources
+func (c *OllamaClient) Close() error {
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	if c.httpClient != nil {
+		return c.httpClient.Close()
+	}
+	return nil
+}
+
+// GetConfig returns the client configuration
+func (c *OllamaClient) GetConfig() *common.CommonModelConfig {
+	return c.config
+}
+
+// BuildURL builds the full URL for API endpoints
+func (c *OllamaClient) BuildURL(endpoint string) string {
+	return c.baseURL + endpoint
+}
+
+// PrepareHeaders prepares HTTP headers for API requests
+func (c *OllamaClient) PrepareHeaders() map[string]string {
+	headers := make(map[string]string)
+	headers[common.HeaderContentType] = common.MIMETypeJSON
+	headers[common.HeaderAccept] = "application/x-ndjson"
+	if c.apiKey != "" {
+		headers[common.HeaderAuthorization] = "Bearer " + c.apiKey
+	}
+	return headers
+}
+
+// GetRateLimitInfo returns current rate limit information
+func (c *OllamaClient) GetRateLimitInfo() *common.RateLimitInfo {
+	// Shared by the clients of the account; adapted to the rate limit headers of responses
+	return c.rateLimiter.Info()
+}
+
+// claimRateLimiter switches to the rate limiter of the client's account with the
+// limits of its config, releasing the previous one
+func (c *OllamaClient) claimRateLimiter() {
+	limiter, release := common.ConfiguredRateLimiter(c.config, c.baseURL, c.apiKey)
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	c.rateLimiter, c.releaseRateLimiter = limiter, release
+	c.httpClient.SetRateLimiter(limiter)
+}
+
+// rateLimitContext returns ctx carrying the estimated tokens of req for the rate limiter
+func (c *OllamaClient) rateLimitContext(ctx context.Context, req *interfaces.PonchoModelRequest) context.Context {
+	return common.WithRateLimitTokens(ctx, common.EstimateRateLimitTokens(c.tokenizer, req, c.config.Provider, c.config.Model))
+}
+
+// IsHealthy checks if the Ollama client is configured
+func (c *OllamaClient) IsHealthy(ctx context.Context) error {
+	if c.baseURL == "" {
+		return fmt.Errorf("base URL is not configured")
+	}
+	return nil
+}
+
+// Chat makes a non-streaming /api/chat call
+func (c *OllamaClient) Chat(ctx context.Context, req *OllamaChatRequest) (*OllamaChatResponse, error) {
+	req.Stream = false
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var chatResp OllamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to parse response", string(common.ProviderOllama), req.Model)
+	}
+
+	return &chatResp, nil
+}
+
+// ChatStream makes a streaming /api/chat call and passes every NDJSON line to callback
+func (c *OllamaClient) ChatStream(ctx context.Context, req *OllamaChatRequest, callback func(*OllamaChatResponse) error) error {
+	req.Stream = true
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return ProcessNDJSONStream(ctx, resp.Body, func(chunk *OllamaChatResponse) error {
+		if chunk.Error != "" {
+			return common.NewModelError(common.ErrorCodeStreamError, chunk.Error, string(common.ProviderOllama), req.Model)
+		}
+		return callback(chunk)
+	})
+}
+
+// Embed makes an /api/embed call
+func (c *OllamaClient) Embed(ctx context.Context, req *OllamaEmbedRequest) (*OllamaEmbedResponse, error) {
+	resp, err := c.postJSON(ctx, common.OllamaEmbedEndpoint, req, req.Model)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var embedResp OllamaEmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to parse embed response", string(common.ProviderOllama), req.Model)
+	}
+
+	return &embedResp, nil
+}
+
+// post sends a chat request and returns the response on HTTP 200
+func (c *OllamaClient) post(ctx context.Context, req *OllamaChatRequest) (*http.Response, error) {
+	return c.postJSON(ctx, common.OllamaChatEndpoint, req, req.Model)
+}
+
+// postJSON sends a JSON request to endpoint and returns the response on HTTP 200
+func (c *OllamaClient) postJSON(ctx context.Context, endpoint string, req interface{}, model string) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to marshal request", string(common.ProviderOllama), model)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BuildURL(endpoint), bytes.NewReader(body))
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeNetworkError, "Failed to create request", string(common.ProviderOllama), model)
+	}
+
+	for key, value := range c.PrepareHeaders() {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeConnectionError, "Failed to reach Ollama at "+c.baseURL, string(common.ProviderOllama), model)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, errorFromResponse(resp, model)
+	}
+
+	return resp, nil
+}
+
+// errorFromResponse maps a non-200 response to a ModelError, keeping Ollama's
+// error message (e.g. "model 'llava' not found, try pulling it first")
+func errorFromResponse(resp *http.Response, model string) *common.ModelError {
+	modelErr := common.ErrorFromAPIResponse(resp, string(common.ProviderOllama), model, func(body []byte) (string, interface{}) {
+		var apiErr OllamaError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return "", nil
+		}
+		return apiErr.Error, nil
+	})
+	if resp.StatusCode == http.StatusNotFound {
+		modelErr.Code = common.ErrorCodeModelNotFound
+	}
+	return modelErr
+}
+
+// LoadImage returns the raw base64 data of an image. data: URLs are unpacked,
+// http(s) URLs are downloaded, anything else is assumed to be base64 already.
+func (c *OllamaClient) LoadImage(ctx context.Context, media *interfaces.PonchoMediaPart) (string, error) {
+	if media == nil || media.URL == "" {
+		return "", fmt.Errorf("media URL cannot be empty")
+	}
+
+	url := media.URL
+	switch {
+	case strings.HasPrefix(url, "data:"):
+		comma := strings.Index(url, ",")
+		if comma < 0 || !strings.Contains(url[:comma], ";base64") {
+			return "", fmt.Errorf("only base64 data URLs are supported")
+		}
+		return url[comma+1:], nil
+
+	case strings.HasPrefix(url, "http://"), strings.HasPrefix(url, "https://"):
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create image request: %w", err)
+		}
+
+		resp, err := c.httpClient.Do(ctx, httpReq)
+		if err != nil {
+			return "", fmt.Errorf("failed to download image: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+		if err != nil {
+			return "", fmt.Errorf("failed to read image: %w", err)
+		}
+		if len(data) > maxImageSize {
+			return "", fmt.Errorf("image exceeds %d bytes", maxImageSize)
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+
+	default:
+		return url, nil
+	}
+}
+
+// LogRequest logs a request to the API
+func (c *OllamaClient) LogRequest(req *interfaces.PonchoModelRequest, requestID string) {
+	c.logger.Debug("Ollama API request",
+		"request_id", requestID,
+		"model", c.config.Model,
+		"base_url", c.baseURL,
+		"messages_count", len(req.Messages),
+		"max_tokens", req.MaxTokens,
+		"temperature", req.Temperature,
+		"stream", req.Stream,
+		"tools_count", len(req.Tools))
+}
+
+// LogResponse logs a response from the API
+func (c *OllamaClient) LogResponse(resp *interfaces.PonchoModelResponse, requestID string, duration time.Duration) {
+	if resp != nil && resp.Usage != nil {
+		c.logger.Debug("Ollama API response",
+			"request_id", requestID,
+			"duration_ms", duration.Milliseconds(),
+			"prompt_tokens", resp.Usage.PromptTokens,
+			"completion_tokens", resp.Usage.CompletionTokens,
+			"finish_reason", resp.FinishReason)
+	}
+}
+
+// LogError logs an error from the API
+func (c *OllamaClient) LogError(err error, requestID string, duration time.Duration) {
+	c.logger.Error("Ollama API error",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds(),
+		"error", err.Error())
+}
@@ -0,0 +1,204 @@
+package ollama
+
+// This file implements PonchoEmbeddingModel on top of Ollama's /api/embed
+// endpoint, e.g. with nomic-embed-text or bge-m3 pulled locally.
+//
+// Configuration (map passed to Initialize, as built by the embedding factory):
+// - api_key (optional), model_name, base_url, timeout, keep_alive
+// - dimensions: declared vector length; learned from the first call otherwise
+// - max_batch_size: inputs per call, DefaultEmbeddingBatchSize by default
+// - truncate: false makes Ollama fail on inputs longer than the context
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// DefaultEmbeddingBatchSize keeps a single /api/embed call short on a laptop
+const DefaultEmbeddingBatchSize = 64
+
+// OllamaEmbeddingModel represents an Ollama embedding model
+type OllamaEmbeddingModel struct {
+	client       *OllamaClient
+	logger       interfaces.Logger
+	name         string
+	maxBatchSize int
+	truncate     *bool
+	keepAlive    string
+
+	mutex      sync.RWMutex
+	dimensions int
+}
+
+// NewOllamaEmbeddingModel creates a new Ollama embedding model instance
+func NewOllamaEmbeddingModel() *OllamaEmbeddingModel {
+	return &OllamaEmbeddingModel{
+		logger:       interfaces.NewDefaultLogger(),
+		name:         common.OllamaDefaultEmbeddingModel,
+		maxBatchSize: DefaultEmbeddingBatchSize,
+	}
+}
+
+// Initialize initializes the embedding model with configuration
+func (m *OllamaEmbeddingModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	commonConfig := &common.CommonModelConfig{
+		Provider: common.ProviderOllama,
+		Model:    common.OllamaDefaultEmbeddingModel,
+		// Not sent with embeddings, but required by the shared client
+		MaxTokens:   2000,
+		Temperature: 0,
+		Timeout:     5 * time.Minute,
+	}
+
+	if apiKey, ok := config["api_key"].(string); ok {
+		commonConfig.APIKey = apiKey
+	}
+
+	if model, ok := config["model_name"].(string); ok && model != "" {
+		commonConfig.Model = model
+	}
+
+	if baseURL, ok := config["base_url"].(string); ok {
+		commonConfig.BaseURL = baseURL
+	}
+
+	switch timeout := config["timeout"].(type) {
+	case time.Duration:
+		if timeout > 0 {
+			commonConfig.Timeout = timeout
+		}
+	case string:
+		if timeout != "" {
+			parsed, err := time.ParseDuration(timeout)
+			if err != nil {
+				return fmt.Errorf("invalid timeout %q: %w", timeout, err)
+			}
+			commonConfig.Timeout = parsed
+		}
+	}
+
+	if value, exists := config["dimensions"]; exists {
+		dimensions, ok := toInt(value)
+		if !ok || dimensions < 0 {
+			return fmt.Errorf("dimensions must be a non-negative integer")
+		}
+		m.dimensions = dimensions
+	}
+
+	if value, exists := config["max_batch_size"]; exists {
+		batchSize, ok := toInt(value)
+		if !ok || batchSize <= 0 {
+			return fmt.Errorf("max_batch_size must be a positive integer")
+		}
+		m.maxBatchSize = batchSize
+	}
+
+	if value, exists := config["truncate"]; exists {
+		truncate, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("truncate must be a boolean")
+		}
+		m.truncate = &truncate
+	}
+
+	if keepAlive, ok := config["keep_alive"].(string); ok {
+		m.keepAlive = keepAlive
+	}
+
+	client, err := NewOllamaClient(commonConfig, m.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create Ollama client: %w", err)
+	}
+
+	m.client = client
+	m.name = commonConfig.Model
+
+	m.logger.Info("Ollama embedding model initialized",
+		"model", m.name,
+		"base_url", client.baseURL,
+		"max_batch_size", m.maxBatchSize)
+
+	return nil
+}
+
+// Embed returns one vector per input, splitting the inputs into batches
+func (m *OllamaEmbeddingModel) Embed(ctx context.Context, inputs []string) ([][]float32, *interfaces.PonchoUsage, error) {
+	if m.client == nil {
+		return nil, nil, fmt.Errorf("embedding model '%s' is not initialized", m.name)
+	}
+
+	return common.EmbedInBatches(ctx, inputs, m.maxBatchSize, m.embedBatch)
+}
+
+// embedBatch embeds one batch with a single /api/embed call
+func (m *OllamaEmbeddingModel) embedBatch(ctx context.Context, batch []string) ([][]float32, *interfaces.PonchoUsage, error) {
+	resp, err := m.client.Embed(ctx, &OllamaEmbedRequest{
+		Model:     m.name,
+		Input:     batch,
+		Truncate:  m.truncate,
+		KeepAlive: m.keepAlive,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(resp.Embeddings) > 0 {
+		m.mutex.Lock()
+		m.dimensions = len(resp.Embeddings[0])
+		m.mutex.Unlock()
+	}
+
+	return resp.Embeddings, &interfaces.PonchoUsage{
+		PromptTokens: resp.PromptEvalCount,
+		TotalTokens:  resp.PromptEvalCount,
+	}, nil
+}
+
+// Name returns the configured model name
+func (m *OllamaEmbeddingModel) Name() string {
+	return m.name
+}
+
+// Provider returns the provider name
+func (m *OllamaEmbeddingModel) Provider() string {
+	return string(common.ProviderOllama)
+}
+
+// Dimensions returns the vector length: the configured dimensions, or the
+// length returned by the last call, or 0 before the first call
+func (m *OllamaEmbeddingModel) Dimensions() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.dimensions
+}
+
+// MaxBatchSize returns the number of inputs sent per call
+func (m *OllamaEmbeddingModel) MaxBatchSize() int {
+	return m.maxBatchSize
+}
+
+// HealthCheck implements interfaces.HealthChecker
+func (m *OllamaEmbeddingModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("Ollama embedding model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
+// Shutdown shuts down the embedding model
+func (m *OllamaEmbeddingModel) Shutdown(ctx context.Context) error {
+	if m.client == nil {
+		return nil
+	}
+
+	if err := m.client.Close(); err != nil {
+		return fmt.Errorf("failed to close Ollama client: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaEmbeddingModel_Embed(t *testing.T) {
+	var requests []OllamaEmbedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+
+		var req OllamaEmbedRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		resp := OllamaEmbedResponse{Model: req.Model, PromptEvalCount: 2 * len(req.Input)}
+		for range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float32{0.1, 0.2, 0.3, 0.4})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	model := NewOllamaEmbeddingModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{
+		"base_url":       server.URL,
+		"model_name":     "nomic-embed-text",
+		"max_batch_size": 2.0,
+		"truncate":       false,
+		"keep_alive":     "10m",
+	}))
+	assert.Equal(t, 0, model.Dimensions(), "dimensions are unknown before the first call")
+
+	vectors, usage, err := model.Embed(context.Background(), []string{"платье", "юбка", "блузка"})
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, []string{"платье", "юбка"}, requests[0].Input)
+	require.NotNil(t, requests[0].Truncate)
+	assert.False(t, *requests[0].Truncate)
+	assert.Equal(t, "10m", requests[0].KeepAlive)
+
+	assert.Len(t, vectors, 3)
+	assert.Equal(t, 6, usage.PromptTokens)
+	assert.Equal(t, 4, model.Dimensions())
+}
+
+func TestOllamaEmbeddingModel_ModelNotPulled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"bge-m3\" not found, try pulling it first"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	model := NewOllamaEmbeddingModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{"base_url": server.URL, "model_name": "bge-m3"}))
+
+	_, _, err := model.Embed(context.Background(), []string{"text"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "try pulling it first")
+}
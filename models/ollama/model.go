@@ -0,0 +1,599 @@
+// Package ollama provides the Ollama model implementation for PonchoFramework.
+// This file implements the PonchoModel interface on top of OllamaClient, so
+// flows can run against local models without any cloud provider.
+//
+// Model Capabilities:
+// - Text generation, NDJSON streaming, tool calling and system messages
+// - Vision for llava-style models; images are sent inline as base64
+// - JSON mode through the "format" setting ("json" or a JSON schema)
+// - Vision is detected from the model name, "supports" overrides it
+//
+// Configuration (map passed to Initialize, as built by the model factory):
+// - model_name, base_url, max_tokens, temperature, timeout, supports
+// - api_key: optional, for Ollama behind an authenticating proxy
+// - top_p, top_k, num_ctx, seed, stop, keep_alive
+// - format: "json" or a JSON schema; response_format "json_object" is accepted too
+//
+// Usage Example:
+//
+//	model := NewOllamaModel()
+//	err := model.Initialize(ctx, map[string]interface{}{
+//	    "model_name": "llava",
+//	    "format":     "json",
+//	})
+//	resp, err := model.Generate(ctx, request)
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// visionModelFamilies lists name fragments of Ollama models that accept images
+var visionModelFamilies = []string{
+	"llava",
+	"bakllava",
+	"llama3.2-vision",
+	"llama4",
+	"qwen2.5vl",
+	"qwen2-vl",
+	"gemma3",
+	"minicpm-v",
+	"moondream",
+	"granite3.2-vision",
+	"mistral-small3.1",
+}
+
+// OllamaModel represents an Ollama model implementation
+type OllamaModel struct {
+	*base.PonchoBaseModel
+	client    *OllamaClient
+	options   OllamaOptions
+	format    interface{}
+	keepAlive string
+}
+
+// NewOllamaModel creates a new Ollama model instance
+func NewOllamaModel() *OllamaModel {
+	baseModel := base.NewPonchoBaseModel(common.OllamaDefaultModel, string(common.ProviderOllama), interfaces.ModelCapabilities{
+		Streaming: true,
+		Tools:     true,
+		Vision:    false,
+		System:    true,
+		JSONMode:  true,
+	})
+
+	return &OllamaModel{
+		PonchoBaseModel: baseModel,
+	}
+}
+
+// Name returns the configured model name, e.g. "llava:13b"
+func (m *OllamaModel) Name() string {
+	if m.client != nil {
+		return m.client.GetConfig().Model
+	}
+	return m.PonchoBaseModel.Name()
+}
+
+// IsVisionModel reports whether an Ollama model name belongs to a vision family
+func IsVisionModel(model string) bool {
+	name := strings.ToLower(model)
+	// Strip registry namespace and tag: "library/llava:13b" -> "llava"
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	if colon := strings.Index(name, ":"); colon >= 0 {
+		name = name[:colon]
+	}
+
+	for _, family := range visionModelFamilies {
+		if strings.HasPrefix(name, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// Initialize initializes the Ollama model with configuration
+func (m *OllamaModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	commonConfig, err := m.convertConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to convert config: %w", err)
+	}
+
+	client, err := NewOllamaClient(commonConfig, m.GetLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create Ollama client: %w", err)
+	}
+
+	capabilities := m.GetCapabilities()
+	capabilities.Vision = IsVisionModel(commonConfig.Model)
+	if supports, ok := config["supports"].(*interfaces.ModelCapabilities); ok && supports != nil {
+		capabilities = *supports
+	}
+	m.SetCapabilities(capabilities)
+
+	// Local Ollama needs no API key, but the base model rejects an empty one
+	baseConfig := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		baseConfig[key] = value
+	}
+	if commonConfig.APIKey == "" {
+		delete(baseConfig, "api_key")
+	}
+
+	if err := m.PonchoBaseModel.Initialize(ctx, baseConfig); err != nil {
+		return fmt.Errorf("failed to initialize base model: %w", err)
+	}
+
+	m.client = client
+
+	m.GetLogger().Info("Ollama model initialized",
+		"model", commonConfig.Model,
+		"base_url", client.baseURL,
+		"vision", capabilities.Vision,
+		"max_tokens", commonConfig.MaxTokens)
+
+	return nil
+}
+
+// Generate generates a response using the /api/chat endpoint
+func (m *OllamaModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	ollamaReq, requestID, err := m.prepareRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	ollamaResp, err := m.client.Chat(ctx, ollamaReq)
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return nil, err
+	}
+
+	resp := m.convertResponse(ollamaResp)
+	m.client.LogResponse(resp, requestID, duration)
+	return resp, nil
+}
+
+// GenerateStreaming generates a streaming response using the /api/chat endpoint.
+// Text and tool calls are forwarded as they arrive; the final chunk carries
+// the finish reason and usage.
+func (m *OllamaModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if m.isInitialized() && !m.SupportsStreaming() {
+		return fmt.Errorf("model '%s' does not support streaming", m.Name())
+	}
+
+	ollamaReq, requestID, err := m.prepareRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	toolCallCount := 0
+	hasToolCalls := false
+	err = m.client.ChatStream(ctx, ollamaReq, func(line *OllamaChatResponse) error {
+		content := convertMessageContent(&line.Message, &toolCallCount)
+		if len(line.Message.ToolCalls) > 0 {
+			hasToolCalls = true
+		}
+
+		if !line.Done {
+			if len(content) == 0 {
+				return nil
+			}
+			return callback(&interfaces.PonchoStreamChunk{
+				Delta: &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: content},
+			})
+		}
+
+		return callback(&interfaces.PonchoStreamChunk{
+			Delta:        &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: content},
+			Usage:        convertUsage(line),
+			FinishReason: convertFinishReason(line.DoneReason, hasToolCalls),
+			Done:         true,
+			Metadata:     map[string]interface{}{"model": line.Model},
+		})
+	})
+
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return err
+	}
+
+	m.GetLogger().Debug("Ollama streaming generation completed",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds())
+
+	return nil
+}
+
+// HealthCheck implements interfaces.HealthChecker
+func (m *OllamaModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("Ollama model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
+// Shutdown shuts down the Ollama model
+func (m *OllamaModel) Shutdown(ctx context.Context) error {
+	if m.client != nil {
+		if err := m.client.Close(); err != nil {
+			m.GetLogger().Error("Failed to close Ollama client", "error", err.Error())
+			return fmt.Errorf("failed to close Ollama client: %w", err)
+		}
+	}
+
+	return m.PonchoBaseModel.Shutdown(ctx)
+}
+
+// Helper methods
+
+// prepareRequest validates a request and converts it to the API format
+func (m *OllamaModel) prepareRequest(ctx context.Context, req *interfaces.PonchoModelRequest) (*OllamaChatRequest, string, error) {
+	if !m.isInitialized() {
+		return nil, "", fmt.Errorf("model '%s' is not initialized", m.Name())
+	}
+
+	if err := m.ValidateRequest(req); err != nil {
+		return nil, "", fmt.Errorf("invalid request: %w", err)
+	}
+
+	requestID := m.generateRequestID()
+	m.client.LogRequest(req, requestID)
+
+	ollamaReq, err := m.convertRequest(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert request: %w", err)
+	}
+
+	return ollamaReq, requestID, nil
+}
+
+// convertConfig converts generic config to CommonModelConfig and collects
+// the Ollama-only options
+func (m *OllamaModel) convertConfig(config map[string]interface{}) (*common.CommonModelConfig, error) {
+	commonConfig := &common.CommonModelConfig{
+		Provider:    common.ProviderOllama,
+		Model:       common.OllamaDefaultModel,
+		MaxTokens:   2000,
+		Temperature: 0.7,
+		// Local models can take minutes to load and answer on a laptop
+		Timeout: 5 * time.Minute,
+	}
+
+	if apiKey, ok := config["api_key"].(string); ok {
+		commonConfig.APIKey = apiKey
+	}
+
+	if model, ok := config["model_name"].(string); ok && model != "" {
+		commonConfig.Model = model
+	}
+
+	if maxTokens, ok := config["max_tokens"].(int); ok && maxTokens > 0 {
+		commonConfig.MaxTokens = maxTokens
+	}
+
+	if temperature, ok := common.ParamFloat(config["temperature"]); ok {
+		commonConfig.Temperature = temperature
+	}
+
+	if baseURL, ok := config["base_url"].(string); ok {
+		commonConfig.BaseURL = baseURL
+	}
+
+	switch timeout := config["timeout"].(type) {
+	case time.Duration:
+		if timeout > 0 {
+			commonConfig.Timeout = timeout
+		}
+	case string:
+		if timeout != "" {
+			parsed, err := time.ParseDuration(timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q: %w", timeout, err)
+			}
+			commonConfig.Timeout = parsed
+		}
+	}
+
+	if topP, ok := common.ParamFloat(config["top_p"]); ok {
+		commonConfig.TopP = &topP
+		m.options.TopP = &topP
+	}
+
+	if stop, ok := config["stop"]; ok {
+		commonConfig.Stop = stop
+		m.options.Stop = stop
+	}
+
+	for key, target := range map[string]**int{
+		"top_k":   &m.options.TopK,
+		"num_ctx": &m.options.NumCtx,
+		"seed":    &m.options.Seed,
+	} {
+		if value, exists := config[key]; exists {
+			number, ok := toInt(value)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an integer", key)
+			}
+			*target = &number
+		}
+	}
+
+	if keepAlive, ok := config["keep_alive"].(string); ok {
+		m.keepAlive = keepAlive
+	}
+
+	format, err := parseFormat(config)
+	if err != nil {
+		return nil, err
+	}
+	m.format = format
+
+	if customParams, ok := config["custom_params"].(map[string]interface{}); ok {
+		if err := common.RateLimitFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+
+	return commonConfig, nil
+}
+
+// parseFormat reads "format" ("json" or a schema object), falling back to
+// "response_format" for configs shared with OpenAI-style providers
+func parseFormat(config map[string]interface{}) (interface{}, error) {
+	if format, ok := config["format"]; ok {
+		switch value := format.(type) {
+		case string:
+			if value != OllamaFormatJSON {
+				return nil, fmt.Errorf("unsupported format: %s", value)
+			}
+			return value, nil
+		case map[string]interface{}:
+			return value, nil
+		default:
+			return nil, fmt.Errorf("format must be \"json\" or a JSON schema object")
+		}
+	}
+
+	if responseFormat, ok := config["response_format"]; ok {
+		formatType, _ := responseFormat.(string)
+		if formatMap, isMap := responseFormat.(map[string]interface{}); isMap {
+			formatType, _ = formatMap["type"].(string)
+		}
+		switch common.ResponseFormat(formatType) {
+		case common.ResponseFormatJSONObject:
+			return OllamaFormatJSON, nil
+		case common.ResponseFormatText:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unsupported response_format: %s", formatType)
+		}
+	}
+
+	return nil, nil
+}
+
+// toInt converts numeric config values to int; YAML and JSON yield float64
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// convertRequest converts a Poncho request to the /api/chat format
+func (m *OllamaModel) convertRequest(ctx context.Context, req *interfaces.PonchoModelRequest) (*OllamaChatRequest, error) {
+	config := m.client.GetConfig()
+
+	// Thinking and logprobs are not read from /api/chat responses
+	if err := common.CheckRequestParams(req, string(common.ProviderOllama), config.Model,
+		common.ParamThinking, common.ParamLogProbs, common.ParamTopLogProbs); err != nil {
+		return nil, err
+	}
+
+	options := m.options
+	options.Temperature = req.Temperature
+	options.NumPredict = req.MaxTokens
+	if req.TopP != nil {
+		options.TopP = req.TopP
+	}
+	if len(req.Stop) > 0 {
+		options.Stop = req.Stop
+	}
+	if req.Seed != nil {
+		options.Seed = req.Seed
+	}
+	if req.FrequencyPenalty != nil {
+		options.FrequencyPenalty = req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		options.PresencePenalty = req.PresencePenalty
+	}
+
+	ollamaReq := &OllamaChatRequest{
+		Model:     config.Model,
+		Messages:  make([]OllamaMessage, 0, len(req.Messages)),
+		Format:    m.format,
+		Options:   &options,
+		KeepAlive: m.keepAlive,
+	}
+
+	switch format, _ := common.RequestResponseFormat(req); format {
+	case common.ResponseFormatJSONObject:
+		ollamaReq.Format = OllamaFormatJSON
+	case common.ResponseFormatText:
+		ollamaReq.Format = nil
+	}
+
+	// Convert tools
+	for _, tool := range req.Tools {
+		ollamaReq.Tools = append(ollamaReq.Tools, OllamaTool{
+			Type: "function",
+			Function: OllamaToolFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	// Convert messages
+	for _, msg := range req.Messages {
+		ollamaMsg, err := m.convertMessage(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		ollamaReq.Messages = append(ollamaReq.Messages, ollamaMsg)
+	}
+
+	return ollamaReq, nil
+}
+
+// convertMessage converts a Poncho message to the /api/chat format.
+// Media parts are loaded and attached as base64 images.
+func (m *OllamaModel) convertMessage(ctx context.Context, msg *interfaces.PonchoMessage) (OllamaMessage, error) {
+	ollamaMsg := OllamaMessage{
+		Role: string(msg.Role),
+	}
+
+	if msg.Role == interfaces.PonchoRoleTool && msg.Name != nil {
+		ollamaMsg.ToolName = *msg.Name
+	}
+
+	for _, part := range msg.Content {
+		switch part.Type {
+		case interfaces.PonchoContentTypeText:
+			ollamaMsg.Content += part.Text
+		case interfaces.PonchoContentTypeToolResult:
+			if part.ToolResult != nil && ollamaMsg.ToolName == "" {
+				ollamaMsg.ToolName = part.ToolResult.Name
+			}
+			ollamaMsg.Content += common.ToolResultText(part.ToolResult)
+		case interfaces.PonchoContentTypeReasoning:
+			// Thinking from earlier turns is not sent back
+		case interfaces.PonchoContentTypeMedia:
+			image, err := m.client.LoadImage(ctx, part.Media)
+			if err != nil {
+				return OllamaMessage{}, fmt.Errorf("failed to load image: %w", err)
+			}
+			ollamaMsg.Images = append(ollamaMsg.Images, image)
+		case interfaces.PonchoContentTypeTool:
+			if part.Tool != nil {
+				ollamaMsg.ToolCalls = append(ollamaMsg.ToolCalls, OllamaToolCall{
+					Function: OllamaFunctionCall{
+						Name:      part.Tool.Name,
+						Arguments: part.Tool.Args,
+					},
+				})
+			}
+		default:
+			return OllamaMessage{}, fmt.Errorf("unsupported content type: %s", part.Type)
+		}
+	}
+
+	return ollamaMsg, nil
+}
+
+// convertResponse converts an /api/chat response to a Poncho response
+func (m *OllamaModel) convertResponse(ollamaResp *OllamaChatResponse) *interfaces.PonchoModelResponse {
+	toolCallCount := 0
+	return &interfaces.PonchoModelResponse{
+		Message: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: convertMessageContent(&ollamaResp.Message, &toolCallCount),
+		},
+		Usage:        convertUsage(ollamaResp),
+		FinishReason: convertFinishReason(ollamaResp.DoneReason, len(ollamaResp.Message.ToolCalls) > 0),
+		Metadata: map[string]interface{}{
+			"model":          ollamaResp.Model,
+			"total_duration": time.Duration(ollamaResp.TotalDuration).String(),
+		},
+	}
+}
+
+// convertMessageContent converts message text and tool calls to content parts.
+// Ollama tool calls carry no IDs, so IDs are numbered per response through
+// toolCallCount.
+func convertMessageContent(msg *OllamaMessage, toolCallCount *int) []*interfaces.PonchoContentPart {
+	parts := make([]*interfaces.PonchoContentPart, 0, 1+len(msg.ToolCalls))
+
+	if msg.Content != "" {
+		parts = append(parts, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeText,
+			Text: msg.Content,
+		})
+	}
+
+	for _, toolCall := range msg.ToolCalls {
+		*toolCallCount++
+		args := toolCall.Function.Arguments
+		if args == nil {
+			args = make(map[string]interface{})
+		}
+		parts = append(parts, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeTool,
+			Tool: &interfaces.PonchoToolPart{
+				ID:   fmt.Sprintf("ollama_call_%d", *toolCallCount),
+				Name: toolCall.Function.Name,
+				Args: args,
+			},
+		})
+	}
+
+	return parts
+}
+
+// convertFinishReason maps done_reason to a Poncho finish reason. Ollama
+// reports "stop" after tool calls, so tool calls take precedence.
+func convertFinishReason(doneReason string, hasToolCalls bool) interfaces.PonchoFinishReason {
+	if hasToolCalls {
+		return interfaces.PonchoFinishReasonTool
+	}
+	if doneReason == OllamaDoneReasonLength {
+		return interfaces.PonchoFinishReasonLength
+	}
+	return interfaces.PonchoFinishReasonStop
+}
+
+// convertUsage converts prompt and eval counts to Poncho usage
+func convertUsage(resp *OllamaChatResponse) *interfaces.PonchoUsage {
+	return &interfaces.PonchoUsage{
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+	}
+}
+
+// generateRequestID generates a unique request ID
+func (m *OllamaModel) generateRequestID() string {
+	return fmt.Sprintf("ollama_%d", time.Now().UnixNano())
+}
+
+// isInitialized checks if model is properly initialized
+func (m *OllamaModel) isInitialized() bool {
+	return m.client != nil
+}
@@ -0,0 +1,194 @@
+package ollama
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOfflineModel returns a model for testing conversions; it never reaches the server
+func newOfflineModel(t *testing.T, extra map[string]interface{}) *OllamaModel {
+	t.Helper()
+
+	config := map[string]interface{}{
+		"base_url":   "http://localhost:1",
+		"model_name": "llava:13b",
+		"max_tokens": 1000,
+	}
+	for key, value := range extra {
+		config[key] = value
+	}
+
+	model := NewOllamaModel()
+	modeltest.Initialize(t, model, config)
+	return model
+}
+
+func TestOllamaModel_ConvertRequest(t *testing.T) {
+	model := newOfflineModel(t, map[string]interface{}{"format": "json", "num_ctx": 8192})
+
+	ollamaReq, err := model.convertRequest(context.Background(), modeltest.Conversation())
+	require.NoError(t, err)
+
+	// Sampling settings go in options, the format is top-level
+	assert.Equal(t, "llava:13b", ollamaReq.Model)
+	assert.Equal(t, OllamaFormatJSON, ollamaReq.Format)
+	assert.Equal(t, 8192, *ollamaReq.Options.NumCtx)
+	require.Len(t, ollamaReq.Tools, 1)
+	require.Len(t, ollamaReq.Messages, 4)
+
+	// The system prompt stays a message
+	assert.Equal(t, OllamaMessage{Role: "system", Content: "You are a fashion expert"}, ollamaReq.Messages[0])
+
+	// Images are raw base64 next to the text, without the data URL prefix
+	assert.Equal(t, OllamaMessage{Role: "user", Content: "Describe the dress", Images: []string{"AAAA"}}, ollamaReq.Messages[1])
+
+	// Tool calls carry object arguments and no ID
+	assert.Equal(t, []OllamaToolCall{{Function: OllamaFunctionCall{Name: "get_article", Arguments: map[string]interface{}{"id": 42}}}}, ollamaReq.Messages[2].ToolCalls)
+
+	result := ollamaReq.Messages[3]
+	assert.Equal(t, "tool", result.Role)
+	assert.Equal(t, `{"color": "red"}`, result.Content)
+
+	// A text response format overrides the configured JSON format
+	req := modeltest.Request(modeltest.Text("Hello"))
+	req.ResponseFormat = interfaces.PonchoResponseFormatText
+	ollamaReq, err = model.convertRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.Nil(t, ollamaReq.Format)
+}
+
+func TestOllamaModel_ConvertToolResult(t *testing.T) {
+	model := newOfflineModel(t, nil)
+
+	// Without a message name, the tool result names the tool the message answers
+	message, err := model.convertMessage(context.Background(), &interfaces.PonchoMessage{
+		Role: interfaces.PonchoRoleTool,
+		Content: []*interfaces.PonchoContentPart{{
+			Type:       interfaces.PonchoContentTypeToolResult,
+			ToolResult: &interfaces.PonchoToolResultPart{ToolCallID: "ollama_call_1", Name: "get_stock", Content: "out of stock"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "get_stock", message.ToolName)
+	assert.Equal(t, "out of stock", message.Content)
+}
+
+func TestOllamaClient_LoadImage(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("png-bytes"))
+	})
+	model := newOfflineModel(t, nil)
+
+	// Downloaded images are re-encoded as base64
+	image, err := model.client.LoadImage(context.Background(), &interfaces.PonchoMediaPart{URL: url + "/dress.png"})
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("png-bytes")), image)
+}
+
+func TestOllamaModel_Generate(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var req OllamaChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.False(t, req.Stream)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"model": "llava:13b",
+			"message": {
+				"role": "assistant",
+				"content": "Checking both",
+				"tool_calls": [
+					{"function": {"name": "get_article", "arguments": {"id": 42}}},
+					{"function": {"name": "get_stock", "arguments": {"sku": "A1"}}}
+				]
+			},
+			"done": true,
+			"done_reason": "stop",
+			"prompt_eval_count": 30,
+			"eval_count": 12
+		}`)
+	})
+
+	model := NewOllamaModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"base_url": url, "model_name": "llava:13b"})
+
+	resp, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Find article 42")))
+	require.NoError(t, err)
+
+	// Ollama reports "stop" after tool calls and gives them no IDs
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, resp.FinishReason)
+	require.Len(t, resp.Message.Content, 3)
+	assert.Equal(t, "Checking both", resp.Message.Content[0].Text)
+	assert.Equal(t, "ollama_call_1", resp.Message.Content[1].Tool.ID)
+	assert.Equal(t, "ollama_call_2", resp.Message.Content[2].Tool.ID)
+	assert.Equal(t, float64(42), resp.Message.Content[1].Tool.Args["id"])
+	assert.Equal(t, 42, resp.Usage.TotalTokens)
+}
+
+func TestOllamaModel_ErrorResponse(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "model \"llava:13b\" not found, try pulling it first"}`)
+	})
+
+	model := NewOllamaModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"base_url": url, "model_name": "llava:13b"})
+
+	// A model that is not pulled is a 404 with a plain error string
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Hello")))
+	modelErr := modeltest.RequireModelError(t, err, common.ErrorCodeModelNotFound)
+	assert.Contains(t, modelErr.Message, "try pulling it first")
+}
+
+func TestConvertFinishReason(t *testing.T) {
+	assert.Equal(t, interfaces.PonchoFinishReasonStop, convertFinishReason("stop", false))
+	assert.Equal(t, interfaces.PonchoFinishReasonLength, convertFinishReason(OllamaDoneReasonLength, false))
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, convertFinishReason("stop", true))
+}
+
+func TestOllamaModel_Capabilities(t *testing.T) {
+	assert.True(t, IsVisionModel("llava:13b"))
+	assert.True(t, IsVisionModel("library/llama3.2-vision"))
+	assert.False(t, IsVisionModel("llama3.1:8b"))
+
+	model := newOfflineModel(t, map[string]interface{}{"model_name": "llama3.1:8b"})
+	assert.False(t, model.SupportsVision())
+	assert.True(t, model.SupportsTools())
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Describe"), modeltest.Image("data:image/png;base64,AAAA")))
+	assert.Error(t, err)
+
+	// An explicit "supports" overrides name detection
+	override := newOfflineModel(t, map[string]interface{}{
+		"model_name": "my-finetune",
+		"supports":   &interfaces.ModelCapabilities{Streaming: true, Vision: true, System: true},
+	})
+	assert.True(t, override.SupportsVision())
+	assert.False(t, override.SupportsTools())
+}
+
+func TestOllamaModel_InitializeValidation(t *testing.T) {
+	err := NewOllamaModel().Initialize(context.Background(), map[string]interface{}{"format": "yaml"})
+	assert.Error(t, err)
+
+	err = NewOllamaModel().Initialize(context.Background(), map[string]interface{}{"num_ctx": "big"})
+	assert.Error(t, err)
+
+	// No API key and no base URL: a local Ollama is the default
+	model := NewOllamaModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"response_format": "json_object"})
+	assert.Equal(t, OllamaFormatJSON, model.format)
+	assert.Equal(t, common.OllamaDefaultBaseURL, model.client.baseURL)
+}
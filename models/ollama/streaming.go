@@ -0,0 +1,65 @@
+// Package ollama provides streaming response processing for the Ollama API.
+// This file implements newline-delimited JSON parsing and the conversion of
+// chat response lines to PonchoFramework stream chunks.
+//
+// Unlike SSE providers, Ollama writes one complete JSON object per line with
+// no data: prefix and no end marker. Tool calls arrive whole in a single
+// line, so they are forwarded as soon as they are seen. The last line has
+// done set and carries the done reason and token counts.
+//
+// Data Flow:
+// 1. HTTP response with application/x-ndjson content-type
+// 2. Line-by-line JSON decoding, blank lines skipped
+// 3. Text deltas and tool calls forwarded as they arrive
+// 4. The done line converted to a final chunk with finish reason and usage
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ProcessNDJSONStream processes a newline-delimited JSON stream of chat responses
+func ProcessNDJSONStream(ctx context.Context, body io.ReadCloser, callback func(*OllamaChatResponse) error) error {
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	// Lines with tool call arguments can exceed the default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var chunk OllamaChatResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream line: %w", err)
+		}
+
+		if err := callback(&chunk); err != nil {
+			return err
+		}
+
+		if chunk.Done {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream processing error: %w", err)
+	}
+
+	return fmt.Errorf("stream ended before done")
+}
@@ -0,0 +1,90 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessNDJSONStream(t *testing.T) {
+	// One JSON object per line; blank lines are skipped and done ends the stream
+	stream := `{"model":"llava:13b","message":{"role":"assistant","content":"Hel"},"done":false}
+
+{"model":"llava:13b","message":{"role":"assistant","content":"lo"},"done":false}
+{"model":"llava:13b","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}
+{"model":"llava:13b","message":{"role":"assistant","content":"ignored"},"done":false}
+`
+
+	var text string
+	err := ProcessNDJSONStream(context.Background(), modeltest.Body(stream), func(chunk *OllamaChatResponse) error {
+		text += chunk.Message.Content
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", text)
+
+	// A body that ends before the done line is incomplete
+	err = ProcessNDJSONStream(context.Background(), modeltest.Body(`{"message":{"content":"Hel"},"done":false}`+"\n"), func(*OllamaChatResponse) error { return nil })
+	assert.Error(t, err)
+
+	err = ProcessNDJSONStream(context.Background(), modeltest.Body("data: {}\n"), func(*OllamaChatResponse) error { return nil })
+	assert.Error(t, err, "SSE framing is not NDJSON")
+}
+
+func TestOllamaModel_GenerateStreaming(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range []string{
+			`{"model":"llava:13b","message":{"role":"assistant","content":"Looking "},"done":false}`,
+			`{"model":"llava:13b","message":{"role":"assistant","content":"it up"},"done":false}`,
+			`{"model":"llava:13b","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_article","arguments":{"id":42}}}]},"done":false}`,
+			`{"model":"llava:13b","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":20,"eval_count":8}`,
+		} {
+			fmt.Fprintln(w, line)
+		}
+	})
+
+	model := NewOllamaModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"base_url": url, "model_name": "llava:13b"})
+
+	chunks, err := modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Find article 42")))
+	require.NoError(t, err)
+	require.Len(t, chunks, 4)
+
+	// Tool calls arrive whole and are forwarded before the done line
+	assert.Equal(t, "Looking ", chunks[0].Delta.Content[0].Text)
+	assert.Equal(t, "it up", chunks[1].Delta.Content[0].Text)
+	assert.Equal(t, "ollama_call_1", chunks[2].Delta.Content[0].Tool.ID)
+	assert.False(t, chunks[2].Done)
+
+	final := chunks[3]
+	assert.True(t, final.Done)
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, final.FinishReason)
+	assert.Equal(t, 28, final.Usage.TotalTokens)
+}
+
+func TestOllamaModel_StreamError(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"model":"llava:13b","message":{"role":"assistant","content":"Hel"},"done":false}`)
+		fmt.Fprintln(w, `{"error":"model runner has unexpectedly stopped"}`)
+	})
+
+	model := NewOllamaModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"base_url": url, "model_name": "llava:13b"})
+
+	// Errors after the stream started come as an error line
+	_, err := modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Hello")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpectedly stopped")
+}
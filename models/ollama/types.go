@@ -0,0 +1,124 @@
+// Package ollama provides type definitions for Ollama's native /api/chat protocol.
+//
+// Protocol Notes:
+// - Streaming responses are newline-delimited JSON, one OllamaChatResponse per line
+// - The last line has done set and carries the token counts
+// - Images are sent as raw base64 strings in the message "images" field
+// - Tool call arguments are JSON objects, not strings, and calls carry no IDs
+// - "format" is either "json" or a JSON schema object
+// - Sampling parameters are sent in "options" (num_predict is max tokens)
+// - /api/embed takes a list of inputs and returns one vector per input
+//
+// Usage Example:
+//
+//	req := &OllamaChatRequest{
+//	    Model: "llava",
+//	    Messages: []OllamaMessage{{Role: "user", Content: "Describe", Images: []string{b64}}},
+//	    Format: OllamaFormatJSON,
+//	}
+package ollama
+
+// OllamaMessage represents a chat message
+type OllamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"` // raw base64, no data: prefix
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"` // name of the tool a "tool" message answers
+}
+
+// OllamaToolCall represents a tool call requested by the model
+type OllamaToolCall struct {
+	Function OllamaFunctionCall `json:"function"`
+}
+
+// OllamaFunctionCall represents the function part of a tool call
+type OllamaFunctionCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// OllamaTool represents a tool definition
+type OllamaTool struct {
+	Type     string             `json:"type"` // always "function"
+	Function OllamaToolFunction `json:"function"`
+}
+
+// OllamaToolFunction represents a tool function definition
+type OllamaToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// OllamaOptions represents model sampling options
+type OllamaOptions struct {
+	Temperature      *float32    `json:"temperature,omitempty"`
+	NumPredict       *int        `json:"num_predict,omitempty"`
+	NumCtx           *int        `json:"num_ctx,omitempty"`
+	TopP             *float32    `json:"top_p,omitempty"`
+	TopK             *int        `json:"top_k,omitempty"`
+	Seed             *int        `json:"seed,omitempty"`
+	Stop             interface{} `json:"stop,omitempty"` // []string
+	FrequencyPenalty *float32    `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float32    `json:"presence_penalty,omitempty"`
+}
+
+// OllamaChatRequest represents a request to /api/chat
+type OllamaChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []OllamaMessage `json:"messages"`
+	Tools     []OllamaTool    `json:"tools,omitempty"`
+	Format    interface{}     `json:"format,omitempty"` // "json" or a JSON schema
+	Options   *OllamaOptions  `json:"options,omitempty"`
+	Stream    bool            `json:"stream"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+}
+
+// OllamaChatResponse represents a response, or one NDJSON line of a streaming response
+type OllamaChatResponse struct {
+	Model              string        `json:"model"`
+	CreatedAt          string        `json:"created_at"`
+	Message            OllamaMessage `json:"message"`
+	Done               bool          `json:"done"`
+	DoneReason         string        `json:"done_reason,omitempty"`
+	TotalDuration      int64         `json:"total_duration,omitempty"` // nanoseconds
+	LoadDuration       int64         `json:"load_duration,omitempty"`
+	PromptEvalCount    int           `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64         `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int           `json:"eval_count,omitempty"`
+	EvalDuration       int64         `json:"eval_duration,omitempty"`
+	Error              string        `json:"error,omitempty"` // set when a stream fails midway
+}
+
+// OllamaEmbedRequest represents a request to /api/embed
+type OllamaEmbedRequest struct {
+	Model     string   `json:"model"`
+	Input     []string `json:"input"`
+	Truncate  *bool    `json:"truncate,omitempty"`
+	KeepAlive string   `json:"keep_alive,omitempty"`
+}
+
+// OllamaEmbedResponse represents a response from /api/embed
+type OllamaEmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float32 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration,omitempty"` // nanoseconds
+	PromptEvalCount int         `json:"prompt_eval_count,omitempty"`
+}
+
+// OllamaError represents an error response
+type OllamaError struct {
+	Error string `json:"error"`
+}
+
+// Constants for Ollama API
+const (
+	// Format values
+	OllamaFormatJSON = "json"
+
+	// Done reasons
+	OllamaDoneReasonStop   = "stop"
+	OllamaDoneReasonLength = "length"
+	OllamaDoneReasonLoad   = "load"
+)
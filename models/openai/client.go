@@ -0,0 +1,383 @@
+// Package openai provides an OpenAI Chat Completions client for PonchoFramework.
+// This file implements the HTTP client used by OpenAIModel. The base URL is
+// configurable, so the same client drives api.openai.com as well as
+// self-hosted OpenAI-compatible servers (vLLM, llama.cpp server, LM Studio).
+//
+// Key Features:
+// - Bearer token authentication (optional for self-hosted servers)
+// - HTTP client with connection pooling and retry logic
+// - Request validation against model capabilities
+// - API error messages preserved in ModelError
+// - Structured logging of requests, responses and errors
+//
+// Configuration Options:
+// - api_key: required for the default base URL, optional otherwise
+// - base_url: e.g. "http://localhost:8000/v1" for vLLM
+// - model_name: model id as the server knows it
+// - timeout, max_tokens, temperature
+//
+// Usage Example:
+//
+//	config := &common.CommonModelConfig{
+//	    BaseURL: "http://localhost:8080/v1",
+//	    Model: "qwen2.5-7b-instruct",
+//	    MaxTokens: 4000,
+//	    Temperature: 0.7,
+//	    Timeout: 60 * time.Second,
+//	}
+//	client, _ := NewOpenAIClient(config, logger)
+//	resp, err := client.CreateChatCompletion(ctx, request)
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// OpenAIClient represents a client for OpenAI-compatible chat completion APIs
+type OpenAIClient struct {
+	httpClient  *common.HTTPClient
+	config      *common.CommonModelConfig
+	logger      interfaces.Logger
+	apiKey      string
+	baseURL     string
+	rateLimiter *common.RateLimiter
+	tokenizer   *common.Tokenizer
+
+	releaseRateLimiter func()
+}
+
+// NewOpenAIClient creates a new OpenAI client
+func NewOpenAIClient(config *common.CommonModelConfig, logger interfaces.Logger) (*OpenAIClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	// Validate configuration
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Create HTTP client from a copy of the shared defaults
+	httpConfig := common.DefaultHTTPConfig
+	httpConfig.Timeout = config.Timeout
+	httpConfig.UserAgent = "PonchoFramework-OpenAI/1.0"
+
+	retryConfig := common.DefaultRetryConfig
+	retryConfig.MaxAttempts = 3
+	retryConfig.BaseDelay = 1 * time.Second
+	retryConfig.MaxDelay = 30 * time.Second
+
+	httpClient, err := common.NewHTTPClient(&httpConfig, retryConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	client := &OpenAIClient{
+		httpClient: httpClient,
+		config:     config,
+		logger:     logger,
+		apiKey:     config.APIKey,
+		baseURL:    strings.TrimRight(baseURLOrDefault(config.BaseURL), "/"),
+		tokenizer:  common.NewTokenizer(logger),
+	}
+	client.claimRateLimiter()
+
+	logger.Info("OpenAI client created",
+		"model", config.Model,
+		"base_url", client.baseURL,
+		"timeout", config.Timeout)
+
+	return client, nil
+}
+
+// baseURLOrDefault returns baseURL, or the OpenAI API URL when it is empty
+func baseURLOrDefault(baseURL string) string {
+	if baseURL == "" {
+		return common.OpenAIDefaultBaseURL
+	}
+	return baseURL
+}
+
+// validateConfig validates OpenAI configuration
+func validateConfig(config *common.CommonModelConfig) error {
+	// Self-hosted servers usually run without authentication
+	if config.APIKey == "" && baseURLOrDefault(config.BaseURL) == common.OpenAIDefaultBaseURL {
+		return fmt.Errorf("API key is required for %s", common.OpenAIDefaultBaseURL)
+	}
+
+	if config.Model == "" {
+		return fmt.Errorf("model name is required")
+	}
+
+	if config.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be positive")
+	}
+
+	if config.Temperature < 0 || config.Temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+
+	if config.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+// Close closes the OpenAI client and cleans up resources
+func (c *OpenAIClient) Close() error {
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	if c.httpClient != nil {
+		return c.httpClient.Close()
+	}
+	return nil
+}
+
+// GetConfig returns the client configuration
+func (c *OpenAIClient) GetConfig() *common.CommonModelConfig {
+	return c.config
+}
+
+// GetLogger returns the client logger
+func (c *OpenAIClient) GetLogger() interfaces.Logger {
+	return c.logger
+}
+
+// PrepareHeaders prepares HTTP headers for API requests
+func (c *OpenAIClient) PrepareHeaders() map[string]string {
+	headers := make(map[string]string)
+	headers[common.HeaderContentType] = common.MIMETypeJSON
+	headers[common.HeaderAccept] = common.MIMETypeJSON
+	if c.apiKey != "" {
+		headers[common.HeaderAuthorization] = "Bearer " + c.apiKey
+	}
+	headers[common.HeaderUserAgent] = "PonchoFramework-OpenAI/1.0"
+	return headers
+}
+
+// BuildURL builds the full URL for API endpoints
+func (c *OpenAIClient) BuildURL(endpoint string) string {
+	return c.baseURL + endpoint
+}
+
+// ValidateRequest validates a request before sending it to the API
+func (c *OpenAIClient) ValidateRequest(req *interfaces.PonchoModelRequest, supportsVision bool) error {
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+
+	if len(req.Messages) == 0 {
+		return fmt.Errorf("request must contain at least one message")
+	}
+
+	// Validate max_tokens
+	if req.MaxTokens != nil && *req.MaxTokens > c.config.MaxTokens {
+		return fmt.Errorf("max_tokens (%d) exceeds model maximum (%d)", *req.MaxTokens, c.config.MaxTokens)
+	}
+
+	if !supportsVision {
+		for i, msg := range req.Messages {
+			for j, part := range msg.Content {
+				if part.Type == interfaces.PonchoContentTypeMedia {
+					return fmt.Errorf("model %s does not support media content (message %d, part %d)", c.config.Model, i, j)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetRateLimitInfo returns current rate limit information
+func (c *OpenAIClient) GetRateLimitInfo() *common.RateLimitInfo {
+	// Shared by the clients of the account; adapted to the rate limit headers of responses
+	return c.rateLimiter.Info()
+}
+
+// claimRateLimiter switches to the rate limiter of the client's account with the
+// limits of its config, releasing the previous one
+func (c *OpenAIClient) claimRateLimiter() {
+	limiter, release := common.ConfiguredRateLimiter(c.config, c.baseURL, c.apiKey)
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
+	c.rateLimiter, c.releaseRateLimiter = limiter, release
+	c.httpClient.SetRateLimiter(limiter)
+}
+
+// rateLimitContext returns ctx carrying the estimated tokens of req for the rate limiter
+func (c *OpenAIClient) rateLimitContext(ctx context.Context, req *interfaces.PonchoModelRequest) context.Context {
+	return common.WithRateLimitTokens(ctx, common.EstimateRateLimitTokens(c.tokenizer, req, c.config.Provider, c.config.Model))
+}
+
+// IsHealthy checks if the OpenAI client is configured
+func (c *OpenAIClient) IsHealthy(ctx context.Context) error {
+	if c.baseURL == "" {
+		return fmt.Errorf("base URL is not configured")
+	}
+
+	if c.apiKey == "" && c.baseURL == common.OpenAIDefaultBaseURL {
+		return fmt.Errorf("API key is not configured")
+	}
+
+	return nil
+}
+
+// CreateChatCompletion makes a non-streaming chat completion API call
+func (c *OpenAIClient) CreateChatCompletion(ctx context.Context, req *OpenAIRequest) (*OpenAIResponse, error) {
+	req.Stream = false
+	req.StreamOptions = nil
+
+	resp, err := c.post(ctx, req, common.MIMETypeJSON)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var openaiResp OpenAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openaiResp); err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to parse response", string(common.ProviderOpenAI), req.Model)
+	}
+
+	return &openaiResp, nil
+}
+
+// CreateChatCompletionStream makes a streaming chat completion API call and
+// passes every parsed chunk to callback
+func (c *OpenAIClient) CreateChatCompletionStream(ctx context.Context, req *OpenAIRequest, callback func(*OpenAIStreamResponse) error) error {
+	req.Stream = true
+	req.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+
+	resp, err := c.post(ctx, req, common.MIMETypeEventStream)
+	if err != nil {
+		return err
+	}
+
+	return ProcessSSEStream(ctx, resp.Body, callback)
+}
+
+// CreateEmbeddings makes an embeddings API call
+func (c *OpenAIClient) CreateEmbeddings(ctx context.Context, req *OpenAIEmbeddingRequest) (*OpenAIEmbeddingResponse, error) {
+	resp, err := c.postJSON(ctx, common.OpenAIEmbeddingsEndpoint, req, req.Model, common.MIMETypeJSON)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var embeddingResp OpenAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embeddingResp); err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to parse embeddings response", string(common.ProviderOpenAI), req.Model)
+	}
+
+	return &embeddingResp, nil
+}
+
+// post sends a chat completion request and returns the response on HTTP 200
+func (c *OpenAIClient) post(ctx context.Context, req *OpenAIRequest, accept string) (*http.Response, error) {
+	return c.postJSON(ctx, common.OpenAIEndpoint, req, req.Model, accept)
+}
+
+// postJSON sends a JSON request to endpoint and returns the response on HTTP 200
+func (c *OpenAIClient) postJSON(ctx context.Context, endpoint string, req interface{}, model, accept string) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrCodeParsingError, "Failed to marshal request", string(common.ProviderOpenAI), model)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BuildURL(endpoint), bytes.NewReader(body))
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeNetworkError, "Failed to create request", string(common.ProviderOpenAI), model)
+	}
+
+	for key, value := range c.PrepareHeaders() {
+		httpReq.Header.Set(key, value)
+	}
+	httpReq.Header.Set(common.HeaderAccept, accept)
+
+	resp, err := c.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, common.WrapError(err, common.ErrorCodeNetworkError, "Failed to make API request", string(common.ProviderOpenAI), model)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, errorFromResponse(resp, model)
+	}
+
+	return resp, nil
+}
+
+// errorFromResponse maps a non-200 response to a ModelError, keeping the
+// server's error message when the body carries one
+func errorFromResponse(resp *http.Response, model string) *common.ModelError {
+	return common.ErrorFromAPIResponse(resp, string(common.ProviderOpenAI), model, func(body []byte) (string, interface{}) {
+		var apiErr OpenAIError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return "", nil
+		}
+		return apiErr.Error.Message, apiErr.Error
+	})
+}
+
+// LogRequest logs a request to the API
+func (c *OpenAIClient) LogRequest(req *interfaces.PonchoModelRequest, requestID string) {
+	c.logger.Debug("OpenAI API request",
+		"request_id", requestID,
+		"model", c.config.Model,
+		"base_url", c.baseURL,
+		"messages_count", len(req.Messages),
+		"max_tokens", req.MaxTokens,
+		"temperature", req.Temperature,
+		"stream", req.Stream,
+		"tools_count", len(req.Tools))
+}
+
+// LogResponse logs a response from the API
+func (c *OpenAIClient) LogResponse(resp *interfaces.PonchoModelResponse, requestID string, duration time.Duration) {
+	if resp != nil && resp.Usage != nil {
+		c.logger.Debug("OpenAI API response",
+			"request_id", requestID,
+			"duration_ms", duration.Milliseconds(),
+			"prompt_tokens", resp.Usage.PromptTokens,
+			"completion_tokens", resp.Usage.CompletionTokens,
+			"total_tokens", resp.Usage.TotalTokens,
+			"finish_reason", resp.FinishReason)
+	} else if resp != nil {
+		c.logger.Debug("OpenAI API response",
+			"request_id", requestID,
+			"duration_ms", duration.Milliseconds(),
+			"finish_reason", resp.FinishReason)
+	}
+}
+
+// LogError logs an error from the API
+func (c *OpenAIClient) LogError(err error, requestID string, duration time.Duration) {
+	c.logger.Error("OpenAI API error",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds(),
+		"error", err.Error())
+
+	if modelErr, ok := err.(*common.ModelError); ok {
+		c.logger.Error("OpenAI API model error details",
+			"request_id", requestID,
+			"error_code", modelErr.Code,
+			"error_model", modelErr.Model,
+			"error_retryable", modelErr.Retryable,
+			"error_status_code", modelErr.StatusCode)
+	}
+}
@@ -0,0 +1,238 @@
+package openai
+
+// This file implements PonchoEmbeddingModel on top of the /embeddings endpoint
+// of OpenAI and OpenAI-compatible servers (vLLM, llama.cpp server, LM Studio).
+//
+// Configuration (map passed to Initialize, as built by the embedding factory):
+// - api_key, model_name, base_url, timeout
+// - dimensions: requested vector length (text-embedding-3 models only)
+// - max_batch_size: inputs per call, DefaultEmbeddingBatchSize by default
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// DefaultEmbeddingBatchSize is the OpenAI limit of inputs per embeddings request
+const DefaultEmbeddingBatchSize = 2048
+
+// OpenAIEmbeddingModel represents an OpenAI or OpenAI-compatible embedding model
+type OpenAIEmbeddingModel struct {
+	client       *OpenAIClient
+	logger       interfaces.Logger
+	name         string
+	requested    int // dimensions sent to the API, 0 for the model default
+	maxBatchSize int
+
+	mutex      sync.RWMutex
+	dimensions int
+}
+
+// NewOpenAIEmbeddingModel creates a new OpenAI embedding model instance
+func NewOpenAIEmbeddingModel() *OpenAIEmbeddingModel {
+	return &OpenAIEmbeddingModel{
+		logger:       interfaces.NewDefaultLogger(),
+		name:         common.OpenAIDefaultEmbeddingModel,
+		maxBatchSize: DefaultEmbeddingBatchSize,
+	}
+}
+
+// Initialize initializes the embedding model with configuration
+func (m *OpenAIEmbeddingModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	commonConfig := &common.CommonModelConfig{
+		Provider: common.ProviderOpenAI,
+		Model:    common.OpenAIDefaultEmbeddingModel,
+		// Not sent with embeddings, but required by the shared client
+		MaxTokens:   8191,
+		Temperature: 0,
+		Timeout:     60 * time.Second,
+	}
+
+	if apiKey, ok := config["api_key"].(string); ok {
+		commonConfig.APIKey = apiKey
+	}
+
+	if model, ok := config["model_name"].(string); ok && model != "" {
+		commonConfig.Model = model
+	}
+
+	if baseURL, ok := config["base_url"].(string); ok {
+		commonConfig.BaseURL = baseURL
+	}
+
+	timeout, err := parseTimeout(config["timeout"])
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		commonConfig.Timeout = timeout
+	}
+
+	if value, exists := config["dimensions"]; exists {
+		dimensions, ok := toInt(value)
+		if !ok || dimensions < 0 {
+			return fmt.Errorf("dimensions must be a non-negative integer")
+		}
+		m.requested = dimensions
+		m.dimensions = dimensions
+	}
+
+	if value, exists := config["max_batch_size"]; exists {
+		batchSize, ok := toInt(value)
+		if !ok || batchSize <= 0 {
+			return fmt.Errorf("max_batch_size must be a positive integer")
+		}
+		m.maxBatchSize = batchSize
+	}
+
+	client, err := NewOpenAIClient(commonConfig, m.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create OpenAI client: %w", err)
+	}
+
+	m.client = client
+	m.name = commonConfig.Model
+
+	m.logger.Info("OpenAI embedding model initialized",
+		"model", m.name,
+		"base_url", client.baseURL,
+		"dimensions", m.requested,
+		"max_batch_size", m.maxBatchSize)
+
+	return nil
+}
+
+// Embed returns one vector per input, splitting the inputs into batches
+func (m *OpenAIEmbeddingModel) Embed(ctx context.Context, inputs []string) ([][]float32, *interfaces.PonchoUsage, error) {
+	if m.client == nil {
+		return nil, nil, fmt.Errorf("embedding model '%s' is not initialized", m.name)
+	}
+
+	return common.EmbedInBatches(ctx, inputs, m.maxBatchSize, m.embedBatch)
+}
+
+// embedBatch embeds one batch with a single API call
+func (m *OpenAIEmbeddingModel) embedBatch(ctx context.Context, batch []string) ([][]float32, *interfaces.PonchoUsage, error) {
+	resp, err := m.client.CreateEmbeddings(ctx, &OpenAIEmbeddingRequest{
+		Model:          m.name,
+		Input:          batch,
+		Dimensions:     m.requested,
+		EncodingFormat: "float",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The API documents data in input order; index makes it explicit
+	data := resp.Data
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Index < data[j].Index })
+
+	vectors := make([][]float32, len(data))
+	for i, embedding := range data {
+		vectors[i] = embedding.Embedding
+	}
+	if len(vectors) > 0 {
+		m.setDimensions(len(vectors[0]))
+	}
+
+	usage := &interfaces.PonchoUsage{}
+	if resp.Usage != nil {
+		usage.PromptTokens = resp.Usage.PromptTokens
+		usage.TotalTokens = resp.Usage.TotalTokens
+	}
+
+	return vectors, usage, nil
+}
+
+// setDimensions records the vector length returned by the server
+func (m *OpenAIEmbeddingModel) setDimensions(dimensions int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.dimensions = dimensions
+}
+
+// Name returns the configured model name
+func (m *OpenAIEmbeddingModel) Name() string {
+	return m.name
+}
+
+// Provider returns the provider name
+func (m *OpenAIEmbeddingModel) Provider() string {
+	return string(common.ProviderOpenAI)
+}
+
+// Dimensions returns the vector length: the configured dimensions, or the
+// length returned by the last call, or 0 before the first call
+func (m *OpenAIEmbeddingModel) Dimensions() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.dimensions
+}
+
+// MaxBatchSize returns the number of inputs sent per call
+func (m *OpenAIEmbeddingModel) MaxBatchSize() int {
+	return m.maxBatchSize
+}
+
+// HealthCheck implements interfaces.HealthChecker
+func (m *OpenAIEmbeddingModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("OpenAI embedding model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
+// Shutdown shuts down the embedding model
+func (m *OpenAIEmbeddingModel) Shutdown(ctx context.Context) error {
+	if m.client == nil {
+		return nil
+	}
+
+	if err := m.client.Close(); err != nil {
+		return fmt.Errorf("failed to close OpenAI client: %w", err)
+	}
+	return nil
+}
+
+// parseTimeout reads a timeout given as a duration or a duration string
+func parseTimeout(value interface{}) (time.Duration, error) {
+	switch timeout := value.(type) {
+	case time.Duration:
+		return timeout, nil
+	case string:
+		if timeout == "" {
+			return 0, nil
+		}
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timeout %q: %w", timeout, err)
+		}
+		return parsed, nil
+	default:
+		return 0, nil
+	}
+}
+
+// toInt converts numeric config values to int; YAML and JSON yield float64
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,73 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIEmbeddingModel_Embed(t *testing.T) {
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+
+		var req OpenAIEmbeddingRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "bge-m3", req.Model)
+		assert.Equal(t, 3, req.Dimensions)
+		batches = append(batches, req.Input)
+
+		// Answer out of order, the model must sort by index
+		resp := OpenAIEmbeddingResponse{Object: "list", Model: req.Model, Usage: &OpenAIUsage{PromptTokens: len(req.Input), TotalTokens: len(req.Input)}}
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			resp.Data = append(resp.Data, OpenAIEmbedding{Index: i, Embedding: []float32{float32(len(req.Input[i])), 0, 1}})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	model := NewOpenAIEmbeddingModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{
+		"base_url":       server.URL + "/v1",
+		"model_name":     "bge-m3",
+		"dimensions":     3,
+		"max_batch_size": 2,
+		"timeout":        "5s",
+	}))
+	t.Cleanup(func() { model.Shutdown(context.Background()) })
+
+	vectors, usage, err := model.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"a", "bb"}, {"ccc"}}, batches)
+	require.Len(t, vectors, 3)
+	for i, want := range []float32{1, 2, 3} {
+		assert.Equal(t, want, vectors[i][0], "vector %d is out of order", i)
+	}
+	assert.Equal(t, 3, usage.PromptTokens)
+	assert.Equal(t, 3, model.Dimensions())
+	assert.Equal(t, 2, model.MaxBatchSize())
+}
+
+func TestOpenAIEmbeddingModel_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"input too long","type":"invalid_request_error"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	model := NewOpenAIEmbeddingModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{"base_url": server.URL}))
+
+	_, _, err := model.Embed(context.Background(), []string{"text"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "input too long")
+
+	assert.Error(t, NewOpenAIEmbeddingModel().Initialize(context.Background(), map[string]interface{}{}), "the OpenAI API requires an api_key")
+	assert.Error(t, NewOpenAIEmbeddingModel().Initialize(context.Background(), map[string]interface{}{"base_url": server.URL, "max_batch_size": 0}))
+}
@@ -0,0 +1,568 @@
+// Package openai provides the OpenAI model implementation for PonchoFramework.
+// This file implements the PonchoModel interface on top of OpenAIClient.
+//
+// Model Capabilities:
+// - Text generation, streaming, tool calling and system messages
+// - Vision through image_url content parts (http(s) or data: URLs)
+// - JSON mode through the "response_format" setting
+// - "supports" narrows capabilities for self-hosted models without vision or tools
+//
+// Configuration (map passed to Initialize, as built by the model factory):
+// - api_key, model_name, base_url, max_tokens, temperature, timeout, supports
+// - top_p, frequency_penalty, presence_penalty, stop
+// - response_format: "json_object" or "text"
+//
+// Usage Example:
+//
+//	model := NewOpenAIModel()
+//	err := model.Initialize(ctx, map[string]interface{}{
+//	    "base_url":   "http://localhost:8000/v1",
+//	    "model_name": "Qwen/Qwen2.5-7B-Instruct",
+//	})
+//	resp, err := model.Generate(ctx, request)
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// OpenAIModel represents an OpenAI or OpenAI-compatible model implementation
+type OpenAIModel struct {
+	*base.PonchoBaseModel
+	client *OpenAIClient
+}
+
+// NewOpenAIModel creates a new OpenAI model instance
+func NewOpenAIModel() *OpenAIModel {
+	baseModel := base.NewPonchoBaseModel(common.OpenAIDefaultModel, string(common.ProviderOpenAI), interfaces.ModelCapabilities{
+		Streaming: true,
+		Tools:     true,
+		Vision:    true,
+		System:    true,
+		JSONMode:  true,
+	})
+
+	return &OpenAIModel{
+		PonchoBaseModel: baseModel,
+	}
+}
+
+// Name returns the configured model name, which varies between OpenAI and self-hosted servers
+func (m *OpenAIModel) Name() string {
+	if m.client != nil {
+		return m.client.GetConfig().Model
+	}
+	return m.PonchoBaseModel.Name()
+}
+
+// Initialize initializes the OpenAI model with configuration
+func (m *OpenAIModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	commonConfig, err := m.convertConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to convert config: %w", err)
+	}
+
+	client, err := NewOpenAIClient(commonConfig, m.GetLogger())
+	if err != nil {
+		return fmt.Errorf("failed to create OpenAI client: %w", err)
+	}
+
+	if supports, ok := config["supports"].(*interfaces.ModelCapabilities); ok && supports != nil {
+		m.SetCapabilities(*supports)
+	}
+
+	// The base model rejects an empty api_key, which is valid for self-hosted servers
+	baseConfig := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		baseConfig[key] = value
+	}
+	if commonConfig.APIKey == "" {
+		delete(baseConfig, "api_key")
+	}
+
+	if err := m.PonchoBaseModel.Initialize(ctx, baseConfig); err != nil {
+		return fmt.Errorf("failed to initialize base model: %w", err)
+	}
+
+	m.client = client
+
+	m.GetLogger().Info("OpenAI model initialized",
+		"model", commonConfig.Model,
+		"base_url", client.baseURL,
+		"max_tokens", commonConfig.MaxTokens,
+		"temperature", commonConfig.Temperature)
+
+	return nil
+}
+
+// Generate generates a response using the chat completions API
+func (m *OpenAIModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	openaiReq, requestID, err := m.prepareRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	openaiResp, err := m.client.CreateChatCompletion(ctx, openaiReq)
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return nil, err
+	}
+
+	resp, err := m.convertResponse(openaiResp)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return nil, fmt.Errorf("failed to convert response: %w", err)
+	}
+
+	m.client.LogResponse(resp, requestID, duration)
+	return resp, nil
+}
+
+// GenerateStreaming generates a streaming response using the chat completions API.
+// Text is forwarded as it arrives; tool calls, the finish reason and usage are
+// delivered in the final chunk.
+func (m *OpenAIModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if m.isInitialized() && !m.SupportsStreaming() {
+		return fmt.Errorf("model '%s' does not support streaming", m.Name())
+	}
+
+	openaiReq, requestID, err := m.prepareRequest(req)
+	if err != nil {
+		return err
+	}
+
+	ctx = m.client.rateLimitContext(ctx, req)
+	startTime := time.Now()
+	assembler := newStreamAssembler()
+	err = m.client.CreateChatCompletionStream(ctx, openaiReq, func(streamResp *OpenAIStreamResponse) error {
+		if chunk := assembler.add(streamResp); chunk != nil {
+			return callback(chunk)
+		}
+		return nil
+	})
+	if err == nil {
+		var final *interfaces.PonchoStreamChunk
+		if final, err = assembler.final(); err == nil {
+			err = callback(final)
+		}
+	}
+
+	duration := time.Since(startTime)
+	if err != nil {
+		m.client.LogError(err, requestID, duration)
+		return err
+	}
+
+	m.GetLogger().Debug("OpenAI streaming generation completed",
+		"request_id", requestID,
+		"duration_ms", duration.Milliseconds())
+
+	return nil
+}
+
+// HealthCheck implements interfaces.HealthChecker
+func (m *OpenAIModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("OpenAI model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
+// Shutdown shuts down the OpenAI model
+func (m *OpenAIModel) Shutdown(ctx context.Context) error {
+	if m.client != nil {
+		if err := m.client.Close(); err != nil {
+			m.GetLogger().Error("Failed to close OpenAI client", "error", err.Error())
+			return fmt.Errorf("failed to close OpenAI client: %w", err)
+		}
+	}
+
+	return m.PonchoBaseModel.Shutdown(ctx)
+}
+
+// Helper methods
+
+// prepareRequest validates a request and converts it to the API format
+func (m *OpenAIModel) prepareRequest(req *interfaces.PonchoModelRequest) (*OpenAIRequest, string, error) {
+	if !m.isInitialized() {
+		return nil, "", fmt.Errorf("model '%s' is not initialized", m.Name())
+	}
+
+	if err := m.ValidateRequest(req); err != nil {
+		return nil, "", fmt.Errorf("invalid request: %w", err)
+	}
+
+	if err := m.client.ValidateRequest(req, m.SupportsVision()); err != nil {
+		return nil, "", fmt.Errorf("request validation failed: %w", err)
+	}
+
+	requestID := m.generateRequestID()
+	m.client.LogRequest(req, requestID)
+
+	openaiReq, err := m.convertRequest(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert request: %w", err)
+	}
+
+	return openaiReq, requestID, nil
+}
+
+// convertConfig converts generic config to CommonModelConfig
+func (m *OpenAIModel) convertConfig(config map[string]interface{}) (*common.CommonModelConfig, error) {
+	commonConfig := &common.CommonModelConfig{
+		Provider:    common.ProviderOpenAI,
+		Model:       common.OpenAIDefaultModel,
+		MaxTokens:   4000,
+		Temperature: 0.7,
+		Timeout:     60 * time.Second,
+	}
+
+	if apiKey, ok := config["api_key"].(string); ok {
+		commonConfig.APIKey = apiKey
+	}
+
+	if model, ok := config["model_name"].(string); ok && model != "" {
+		commonConfig.Model = model
+	}
+
+	if maxTokens, ok := config["max_tokens"].(int); ok && maxTokens > 0 {
+		commonConfig.MaxTokens = maxTokens
+	}
+
+	if temperature, ok := common.ParamFloat(config["temperature"]); ok {
+		commonConfig.Temperature = temperature
+	}
+
+	if baseURL, ok := config["base_url"].(string); ok {
+		commonConfig.BaseURL = baseURL
+	}
+
+	switch timeout := config["timeout"].(type) {
+	case time.Duration:
+		if timeout > 0 {
+			commonConfig.Timeout = timeout
+		}
+	case string:
+		if timeout != "" {
+			parsed, err := time.ParseDuration(timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q: %w", timeout, err)
+			}
+			commonConfig.Timeout = parsed
+		}
+	}
+
+	if topP, ok := common.ParamFloat(config["top_p"]); ok {
+		commonConfig.TopP = &topP
+	}
+
+	if penalty, ok := common.ParamFloat(config["frequency_penalty"]); ok {
+		commonConfig.FrequencyPenalty = &penalty
+	}
+
+	if penalty, ok := common.ParamFloat(config["presence_penalty"]); ok {
+		commonConfig.PresencePenalty = &penalty
+	}
+
+	if stop, ok := config["stop"]; ok {
+		if _, err := common.StopSequences(stop); err != nil {
+			return nil, err
+		}
+		commonConfig.Stop = stop
+	}
+
+	if seed, exists := config["seed"]; exists {
+		number, ok := toInt(seed)
+		if !ok {
+			return nil, fmt.Errorf("seed must be an integer")
+		}
+		commonConfig.Seed = &number
+	}
+
+	if format, ok := config["response_format"]; ok {
+		responseFormat, err := parseResponseFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		commonConfig.ResponseFormat = &responseFormat
+	}
+
+	if customParams, ok := config["custom_params"].(map[string]interface{}); ok {
+		if err := common.RateLimitFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+
+	return commonConfig, nil
+}
+
+// parseResponseFormat accepts "json_object" or {"type": "json_object"}
+func parseResponseFormat(value interface{}) (common.ResponseFormat, error) {
+	formatType, ok := value.(string)
+	if formatMap, isMap := value.(map[string]interface{}); isMap {
+		formatType, ok = formatMap["type"].(string)
+	}
+	if !ok {
+		return "", fmt.Errorf("response_format must be a string or an object with a type")
+	}
+
+	switch common.ResponseFormat(formatType) {
+	case common.ResponseFormatText, common.ResponseFormatJSONObject:
+		return common.ResponseFormat(formatType), nil
+	default:
+		return "", fmt.Errorf("unsupported response_format: %s", formatType)
+	}
+}
+
+// convertRequest converts a Poncho request to the chat completions format
+func (m *OpenAIModel) convertRequest(req *interfaces.PonchoModelRequest) (*OpenAIRequest, error) {
+	config := m.client.GetConfig()
+
+	// Reasoning effort is not a switch, so thinking cannot be requested
+	if err := common.CheckRequestParams(req, string(common.ProviderOpenAI), config.Model, common.ParamThinking); err != nil {
+		return nil, err
+	}
+	params, err := common.ResolveGenerationParams(req, config)
+	if err != nil {
+		return nil, err
+	}
+
+	openaiReq := &OpenAIRequest{
+		Model:            config.Model,
+		Messages:         make([]OpenAIMessage, 0, len(req.Messages)),
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		TopP:             params.TopP,
+		FrequencyPenalty: params.FrequencyPenalty,
+		PresencePenalty:  params.PresencePenalty,
+		Seed:             params.Seed,
+		LogProbs:         params.LogProbs,
+		TopLogProbs:      params.TopLogProbs,
+	}
+
+	if len(params.Stop) > 0 {
+		openaiReq.Stop = params.Stop
+	}
+
+	if params.ResponseFormat != nil {
+		openaiReq.ResponseFormat = &OpenAIResponseFormat{Type: string(*params.ResponseFormat)}
+	}
+
+	// Convert tools
+	for _, tool := range req.Tools {
+		openaiReq.Tools = append(openaiReq.Tools, OpenAITool{
+			Type: "function",
+			Function: OpenAIToolFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	// Convert messages
+	for _, msg := range req.Messages {
+		openaiMsg, err := convertMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		openaiReq.Messages = append(openaiReq.Messages, openaiMsg)
+	}
+
+	return openaiReq, nil
+}
+
+// convertMessage converts a Poncho message to the chat completions format.
+// Messages with media are sent as content part arrays, others as plain strings.
+func convertMessage(msg *interfaces.PonchoMessage) (OpenAIMessage, error) {
+	openaiMsg := OpenAIMessage{
+		Role: string(msg.Role),
+		Name: msg.Name,
+	}
+
+	if msg.Role == interfaces.PonchoRoleTool {
+		openaiMsg.ToolCallID = common.ToolCallID(msg)
+		openaiMsg.Name = nil
+	}
+
+	hasMedia := false
+	for _, part := range msg.Content {
+		if part.Type == interfaces.PonchoContentTypeMedia {
+			hasMedia = true
+			break
+		}
+	}
+
+	var text string
+	var parts []OpenAIContentPart
+	for _, part := range msg.Content {
+		switch part.Type {
+		case interfaces.PonchoContentTypeText:
+			if hasMedia {
+				parts = append(parts, OpenAIContentPart{Type: OpenAIContentTypeText, Text: part.Text})
+			} else {
+				text += part.Text
+			}
+		case interfaces.PonchoContentTypeMedia:
+			parts = append(parts, OpenAIContentPart{
+				Type: OpenAIContentTypeImageURL,
+				ImageURL: &OpenAIImageURL{
+					URL:    part.Media.URL,
+					Detail: OpenAIImageDetailAuto,
+				},
+			})
+		case interfaces.PonchoContentTypeToolResult:
+			text += common.ToolResultText(part.ToolResult)
+		case interfaces.PonchoContentTypeReasoning:
+			// Reasoning from earlier turns is not sent back
+		case interfaces.PonchoContentTypeTool:
+			if part.Tool != nil {
+				openaiMsg.ToolCalls = append(openaiMsg.ToolCalls, OpenAIToolCall{
+					ID:   part.Tool.ID,
+					Type: "function",
+					Function: OpenAIFunctionCall{
+						Name:      part.Tool.Name,
+						Arguments: mapToJSONString(part.Tool.Args),
+					},
+				})
+			}
+		default:
+			return OpenAIMessage{}, fmt.Errorf("unsupported content type: %s", part.Type)
+		}
+	}
+
+	if hasMedia {
+		openaiMsg.Content = parts
+	} else {
+		openaiMsg.Content = text
+	}
+
+	return openaiMsg, nil
+}
+
+// convertResponse converts a chat completion response to a Poncho response
+func (m *OpenAIModel) convertResponse(openaiResp *OpenAIResponse) (*interfaces.PonchoModelResponse, error) {
+	if openaiResp == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+
+	if len(openaiResp.Choices) == 0 {
+		return nil, fmt.Errorf("response contains no choices")
+	}
+
+	choice := openaiResp.Choices[0]
+	resp := &interfaces.PonchoModelResponse{
+		Message: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: make([]*interfaces.PonchoContentPart, 0),
+		},
+		FinishReason: common.ToPonchoFinishReason(common.FinishReason(choice.FinishReason)),
+		LogProbs:     convertLogProbs(choice.LogProbs),
+		Metadata: map[string]interface{}{
+			"id":    openaiResp.ID,
+			"model": openaiResp.Model,
+		},
+	}
+
+	if content, ok := choice.Message.Content.(string); ok && content != "" {
+		resp.Message.Content = append(resp.Message.Content, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeText,
+			Text: content,
+		})
+	}
+
+	toolParts, err := convertToolCalls(choice.Message.ToolCalls)
+	if err != nil {
+		return nil, err
+	}
+	resp.Message.Content = append(resp.Message.Content, toolParts...)
+
+	if openaiResp.Usage != nil {
+		resp.Usage = convertUsage(openaiResp.Usage)
+	}
+
+	return resp, nil
+}
+
+// convertLogProbs converts choice logprobs to Poncho logprobs
+func convertLogProbs(logProbs *OpenAILogProbs) *interfaces.PonchoLogProbs {
+	if logProbs == nil || len(logProbs.Content) == 0 {
+		return nil
+	}
+
+	converted := &interfaces.PonchoLogProbs{Content: make([]interfaces.PonchoTokenLogProb, 0, len(logProbs.Content))}
+	for _, token := range logProbs.Content {
+		tokenLogProb := interfaces.PonchoTokenLogProb{Token: token.Token, LogProb: token.LogProb, Bytes: token.Bytes}
+		for _, top := range token.TopLogProbs {
+			tokenLogProb.TopLogProbs = append(tokenLogProb.TopLogProbs, interfaces.PonchoTopLogProb{
+				Token:   top.Token,
+				LogProb: top.LogProb,
+				Bytes:   top.Bytes,
+			})
+		}
+		converted.Content = append(converted.Content, tokenLogProb)
+	}
+	return converted
+}
+
+// convertToolCalls converts tool calls to Poncho tool content parts
+func convertToolCalls(toolCalls []OpenAIToolCall) ([]*interfaces.PonchoContentPart, error) {
+	parts := make([]*interfaces.PonchoContentPart, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		args := make(map[string]interface{})
+		if toolCall.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool call %s: %w", toolCall.Function.Name, err)
+			}
+		}
+
+		parts = append(parts, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeTool,
+			Tool: &interfaces.PonchoToolPart{
+				ID:   toolCall.ID,
+				Name: toolCall.Function.Name,
+				Args: args,
+			},
+		})
+	}
+	return parts, nil
+}
+
+// convertUsage converts API usage to Poncho usage
+func convertUsage(usage *OpenAIUsage) *interfaces.PonchoUsage {
+	return &interfaces.PonchoUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// generateRequestID generates a unique request ID
+func (m *OpenAIModel) generateRequestID() string {
+	return fmt.Sprintf("openai_%d", time.Now().UnixNano())
+}
+
+// isInitialized checks if model is properly initialized
+func (m *OpenAIModel) isInitialized() bool {
+	return m.client != nil
+}
+
+// mapToJSONString converts map to JSON string
+func mapToJSONString(args map[string]interface{}) string {
+	if args == nil {
+		return "{}"
+	}
+	data, _ := json.Marshal(args)
+	return string(data)
+}
@@ -0,0 +1,212 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertMessage(t *testing.T) {
+	conversation := modeltest.Conversation().Messages
+
+	// Text only messages keep plain string content
+	system, err := convertMessage(conversation[0])
+	require.NoError(t, err)
+	assert.Equal(t, "system", system.Role)
+	assert.Equal(t, "You are a fashion expert", system.Content)
+
+	// Messages with images become content part arrays with image_url parts
+	user, err := convertMessage(conversation[1])
+	require.NoError(t, err)
+	assert.Equal(t, []OpenAIContentPart{
+		{Type: OpenAIContentTypeText, Text: "Describe the dress"},
+		{Type: OpenAIContentTypeImageURL, ImageURL: &OpenAIImageURL{URL: "data:image/png;base64,AAAA", Detail: OpenAIImageDetailAuto}},
+	}, user.Content)
+
+	// Tool call arguments are sent as a JSON string
+	assistant, err := convertMessage(conversation[2])
+	require.NoError(t, err)
+	require.Len(t, assistant.ToolCalls, 1)
+	assert.Equal(t, modeltest.ToolCallID, assistant.ToolCalls[0].ID)
+	assert.JSONEq(t, `{"id": 42}`, assistant.ToolCalls[0].Function.Arguments)
+
+	// Tool results answer the call by tool_call_id, without a name
+	result, err := convertMessage(conversation[3])
+	require.NoError(t, err)
+	assert.Equal(t, "tool", result.Role)
+	assert.Equal(t, modeltest.ToolCallID, result.ToolCallID)
+	assert.Nil(t, result.Name)
+	assert.Equal(t, `{"color": "red"}`, result.Content)
+
+	// Reasoning from earlier turns is not sent back
+	reasoning, err := convertMessage(&interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant, Content: []*interfaces.PonchoContentPart{
+		{Type: interfaces.PonchoContentTypeReasoning, Text: "The user wants a description"},
+		modeltest.Text("A red dress"),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "A red dress", reasoning.Content)
+}
+
+func TestOpenAIModel_Generate(t *testing.T) {
+	var received OpenAIRequest
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"model": "qwen2.5-7b-instruct",
+			"choices": [{
+				"index": 0,
+				"message": {
+					"role": "assistant",
+					"content": "{\"color\": \"red\"}",
+					"tool_calls": [{"id": "call_2", "type": "function", "function": {"name": "get_article", "arguments": "{\"id\": 43}"}}]
+				},
+				"finish_reason": "tool_calls"
+			}],
+			"usage": {"prompt_tokens": 30, "completion_tokens": 12, "total_tokens": 42}
+		}`)
+	})
+
+	model := NewOpenAIModel()
+	modeltest.Initialize(t, model, map[string]interface{}{
+		"api_key":         "sk-test",
+		"base_url":        url + "/v1",
+		"model_name":      "qwen2.5-7b-instruct",
+		"response_format": "json_object",
+	})
+
+	resp, err := model.Generate(context.Background(), modeltest.Conversation())
+	require.NoError(t, err)
+
+	// Request: configured model, JSON mode and function tools
+	assert.Equal(t, "qwen2.5-7b-instruct", received.Model)
+	assert.Equal(t, OpenAIResponseFormatJSONObject, received.ResponseFormat.Type)
+	require.Len(t, received.Tools, 1)
+	assert.Equal(t, "function", received.Tools[0].Type)
+	assert.Len(t, received.Messages, 4)
+
+	// Response: text, tool call with parsed arguments and usage
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, resp.FinishReason)
+	require.Len(t, resp.Message.Content, 2)
+	assert.Equal(t, `{"color": "red"}`, resp.Message.Content[0].Text)
+	assert.Equal(t, "call_2", resp.Message.Content[1].Tool.ID)
+	assert.Equal(t, float64(43), resp.Message.Content[1].Tool.Args["id"])
+	assert.Equal(t, 42, resp.Usage.TotalTokens)
+	assert.Equal(t, "chatcmpl-1", resp.Metadata["id"])
+}
+
+func TestOpenAIModel_ErrorResponse(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": {"message": "model 'gpt-5' not found", "type": "invalid_request_error"}}`)
+	})
+
+	model := NewOpenAIModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"base_url": url + "/v1"})
+
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Hello")))
+	modelErr := modeltest.RequireModelError(t, err, common.ErrorCodeInvalidRequest)
+	assert.Contains(t, modelErr.Message, "model 'gpt-5' not found")
+	assert.Equal(t, "invalid_request_error", modelErr.Details.(OpenAIErrorDetail).Type)
+}
+
+func TestOpenAIModel_Capabilities(t *testing.T) {
+	// Self-hosted servers declare what the served model supports
+	model := NewOpenAIModel()
+	modeltest.Initialize(t, model, map[string]interface{}{
+		"base_url": "http://localhost:8080/v1",
+		"supports": &interfaces.ModelCapabilities{Streaming: true, System: true},
+	})
+
+	assert.False(t, model.SupportsVision())
+	_, err := model.Generate(context.Background(), modeltest.Request(modeltest.Text("Describe"), modeltest.Image("https://example.com/a.jpg")))
+	assert.Error(t, err)
+}
+
+func TestOpenAIModel_GenerationParams(t *testing.T) {
+	model := NewOpenAIModel()
+	modeltest.Initialize(t, model, map[string]interface{}{
+		"base_url":        "http://localhost:8080/v1",
+		"response_format": "json_object",
+		"top_p":           0.5,
+		"seed":            7,
+	})
+
+	// Request parameters override the configured defaults, the rest fall back
+	seed := 42
+	logProbs := true
+	req := modeltest.Request(modeltest.Text("Describe the dress"))
+	req.ResponseFormat = interfaces.PonchoResponseFormatText
+	req.Stop = []string{"END"}
+	req.Seed = &seed
+	req.LogProbs = &logProbs
+
+	openaiReq, err := model.convertRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, OpenAIResponseFormatText, openaiReq.ResponseFormat.Type)
+	assert.Equal(t, []string{"END"}, openaiReq.Stop)
+	assert.Equal(t, 42, *openaiReq.Seed)
+	assert.Equal(t, float32(0.5), *openaiReq.TopP)
+	assert.True(t, openaiReq.LogProbs)
+
+	openaiReq, err = model.convertRequest(modeltest.Request(modeltest.Text("Describe the dress")))
+	require.NoError(t, err)
+	assert.Equal(t, OpenAIResponseFormatJSONObject, openaiReq.ResponseFormat.Type)
+	assert.Equal(t, 7, *openaiReq.Seed)
+
+	// Thinking cannot be switched on
+	thinking := true
+	req = modeltest.Request(modeltest.Text("Describe the dress"))
+	req.Thinking = &thinking
+	_, err = model.Generate(context.Background(), req)
+	modelErr := modeltest.RequireModelError(t, err, common.ErrorCodeUnsupportedParameter)
+	assert.Equal(t, map[string]interface{}{"parameter": common.ParamThinking}, modelErr.Details)
+}
+
+func TestConvertLogProbs(t *testing.T) {
+	logProbs := convertLogProbs(&OpenAILogProbs{Content: []OpenAITokenLogProb{{
+		Token:       "red",
+		LogProb:     -0.25,
+		Bytes:       []int{114, 101, 100},
+		TopLogProbs: []OpenAITopLogProb{{Token: "red", LogProb: -0.25}, {Token: "blue", LogProb: -1.5}},
+	}}})
+	require.NotNil(t, logProbs)
+	require.Len(t, logProbs.Content, 1)
+
+	token := logProbs.Content[0]
+	assert.Equal(t, "red", token.Token)
+	assert.Equal(t, []int{114, 101, 100}, token.Bytes)
+	require.Len(t, token.TopLogProbs, 2)
+	assert.Equal(t, "blue", token.TopLogProbs[1].Token)
+
+	confidence, ok := common.TextConfidence(logProbs, "red")
+	assert.True(t, ok)
+	assert.InDelta(t, 0.7788, confidence, 1e-4)
+
+	assert.Nil(t, convertLogProbs(&OpenAILogProbs{}))
+}
+
+func TestOpenAIModel_InitializeValidation(t *testing.T) {
+	// The OpenAI API itself requires a key
+	err := NewOpenAIModel().Initialize(context.Background(), map[string]interface{}{"model_name": "gpt-4o"})
+	assert.Error(t, err)
+
+	err = NewOpenAIModel().Initialize(context.Background(), map[string]interface{}{
+		"base_url":        "http://localhost:8080/v1",
+		"response_format": "yaml",
+	})
+	assert.Error(t, err)
+}
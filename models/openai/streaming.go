@@ -0,0 +1,193 @@
+// Package openai provides streaming response processing for the OpenAI API.
+// This file implements Server-Sent Events parsing and the conversion of
+// chat completion chunks to PonchoFramework stream chunks.
+//
+// Tool calls arrive as fragments: the first fragment of a call carries its
+// index, id and name, later fragments with the same index append to its
+// arguments. streamAssembler collects the fragments and emits complete tool
+// calls in the final chunk, together with the finish reason and the usage
+// that OpenAI sends after the last choice when stream_options.include_usage
+// is set.
+//
+// Data Flow:
+// 1. HTTP response with SSE content-type
+// 2. Line-by-line parsing with data: prefix handling and [DONE] marker
+// 3. Text deltas forwarded as they arrive
+// 4. Tool call fragments, finish reason and usage accumulated
+// 5. One final chunk with Done set when the stream ends
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// ProcessSSEStream processes a Server-Sent Events stream of chat completion chunks
+func ProcessSSEStream(ctx context.Context, body io.ReadCloser, callback func(*OpenAIStreamResponse) error) error {
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	// Chunks with long tool call arguments can exceed the default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		line := scanner.Text()
+
+		// Skip empty lines, comments and other SSE fields
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+
+		// Check for [DONE] marker
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var streamResp OpenAIStreamResponse
+		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		if err := callback(&streamResp); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream processing error: %w", err)
+	}
+
+	return nil
+}
+
+// streamAssembler converts stream chunks to Poncho chunks, holding back tool
+// calls, the finish reason and usage until the stream is finished
+type streamAssembler struct {
+	toolCalls    map[int]*OpenAIToolCall
+	finishReason string
+	usage        *OpenAIUsage
+	metadata     map[string]interface{}
+}
+
+// newStreamAssembler creates an empty stream assembler
+func newStreamAssembler() *streamAssembler {
+	return &streamAssembler{
+		toolCalls: make(map[int]*OpenAIToolCall),
+		metadata:  make(map[string]interface{}),
+	}
+}
+
+// add records a stream chunk and returns the text delta to forward, or nil
+// when the chunk carries no text
+func (a *streamAssembler) add(chunk *OpenAIStreamResponse) *interfaces.PonchoStreamChunk {
+	if chunk.ID != "" {
+		a.metadata["id"] = chunk.ID
+	}
+	if chunk.Model != "" {
+		a.metadata["model"] = chunk.Model
+	}
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+
+	if len(chunk.Choices) == 0 {
+		return nil
+	}
+
+	choice := chunk.Choices[0]
+	for i, fragment := range choice.Delta.ToolCalls {
+		index := i
+		if fragment.Index != nil {
+			index = *fragment.Index
+		}
+
+		call, exists := a.toolCalls[index]
+		if !exists {
+			call = &OpenAIToolCall{Type: "function"}
+			a.toolCalls[index] = call
+		}
+		if fragment.ID != "" {
+			call.ID = fragment.ID
+		}
+		if fragment.Function.Name != "" {
+			call.Function.Name = fragment.Function.Name
+		}
+		call.Function.Arguments += fragment.Function.Arguments
+	}
+
+	if choice.FinishReason != nil && *choice.FinishReason != "" {
+		a.finishReason = *choice.FinishReason
+	}
+
+	if choice.Delta.Content == "" {
+		return nil
+	}
+
+	return &interfaces.PonchoStreamChunk{
+		Delta: &interfaces.PonchoMessage{
+			Role: interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{{
+				Type: interfaces.PonchoContentTypeText,
+				Text: choice.Delta.Content,
+			}},
+		},
+		LogProbs: convertLogProbs(choice.LogProbs),
+		Metadata: map[string]interface{}{"id": chunk.ID, "model": chunk.Model},
+	}
+}
+
+// final returns the closing chunk with the assembled tool calls, finish reason and usage
+func (a *streamAssembler) final() (*interfaces.PonchoStreamChunk, error) {
+	chunk := &interfaces.PonchoStreamChunk{
+		Done:         true,
+		FinishReason: common.ToPonchoFinishReason(common.FinishReason(a.finishReason)),
+		Metadata:     a.metadata,
+	}
+
+	if len(a.toolCalls) > 0 {
+		indexes := make([]int, 0, len(a.toolCalls))
+		for index := range a.toolCalls {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+
+		calls := make([]OpenAIToolCall, 0, len(indexes))
+		for _, index := range indexes {
+			calls = append(calls, *a.toolCalls[index])
+		}
+
+		parts, err := convertToolCalls(calls)
+		if err != nil {
+			return nil, err
+		}
+		chunk.Delta = &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: parts,
+		}
+	}
+
+	if a.usage != nil {
+		chunk.Usage = convertUsage(a.usage)
+	}
+
+	return chunk, nil
+}
@@ -0,0 +1,114 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/internal/modeltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSSEStream(t *testing.T) {
+	stream := `: keep-alive
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"content":"Hello"}}]}
+
+event: ignored
+data: {"id":"c2","choices":[{"index":0,"delta":{"content":" world"}}]}
+
+data: [DONE]
+
+data: {"id":"c3","choices":[]}
+`
+
+	var ids []string
+	err := ProcessSSEStream(context.Background(), modeltest.Body(stream), func(chunk *OpenAIStreamResponse) error {
+		ids = append(ids, chunk.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c2"}, ids, "comments and other fields are skipped, [DONE] ends the stream")
+
+	err = ProcessSSEStream(context.Background(), modeltest.Body("data: {not json}\n\n"), func(*OpenAIStreamResponse) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestStreamAssembler(t *testing.T) {
+	assembler := newStreamAssembler()
+	var forwarded []*interfaces.PonchoStreamChunk
+	for _, data := range []string{
+		`{"id":"c1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"role":"assistant","content":"Looking it up"}}]}`,
+		// Two calls whose fragments interleave; later fragments carry only the index
+		`{"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_article","arguments":"{\"id\""}}]}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_stock","arguments":""}}]}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"{\"sku\": \"A1\"}"}}]}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":": 42}"}}]}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		// Usage comes after the last choice when stream_options.include_usage is set
+		`{"id":"c1","choices":[],"usage":{"prompt_tokens":20,"completion_tokens":8,"total_tokens":28}}`,
+	} {
+		var chunk OpenAIStreamResponse
+		require.NoError(t, json.Unmarshal([]byte(data), &chunk))
+		if forward := assembler.add(&chunk); forward != nil {
+			forwarded = append(forwarded, forward)
+		}
+	}
+
+	require.Len(t, forwarded, 1, "only text is forwarded while streaming")
+	assert.Equal(t, "Looking it up", forwarded[0].Delta.Content[0].Text)
+
+	final, err := assembler.final()
+	require.NoError(t, err)
+	assert.True(t, final.Done)
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, final.FinishReason)
+	assert.Equal(t, 28, final.Usage.TotalTokens)
+	assert.Equal(t, "gpt-4o-mini", final.Metadata["model"])
+
+	calls := final.Delta.Content
+	require.Len(t, calls, 2)
+	assert.Equal(t, "call_1", calls[0].Tool.ID)
+	assert.Equal(t, float64(42), calls[0].Tool.Args["id"])
+	assert.Equal(t, "get_stock", calls[1].Tool.Name)
+	assert.Equal(t, "A1", calls[1].Tool.Args["sku"])
+
+	// Arguments that never form a JSON object fail the stream
+	assembler = newStreamAssembler()
+	index := 0
+	assembler.add(&OpenAIStreamResponse{Choices: []OpenAIStreamChoice{{Delta: OpenAIStreamDelta{ToolCalls: []OpenAIToolCall{{
+		Index: &index, ID: "call_1", Function: OpenAIFunctionCall{Name: "get_article", Arguments: `{"id": 4`},
+	}}}}}})
+	_, err = assembler.final()
+	assert.Error(t, err)
+}
+
+func TestOpenAIModel_GenerateStreaming(t *testing.T) {
+	url := modeltest.Server(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"), "self-hosted servers need no API key")
+
+		var req OpenAIRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+		assert.True(t, req.StreamOptions.IncludeUsage, "usage must be requested for streams")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"c1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"id\":\"c1\",\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":1,\"total_tokens\":6}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	model := NewOpenAIModel()
+	modeltest.Initialize(t, model, map[string]interface{}{"base_url": url + "/v1"})
+
+	chunks, err := modeltest.Collect(t, model, modeltest.Request(modeltest.Text("Hello")))
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "Hi", chunks[0].Delta.Content[0].Text)
+	assert.True(t, chunks[1].Done)
+	assert.Equal(t, interfaces.PonchoFinishReasonStop, chunks[1].FinishReason)
+	assert.Equal(t, 6, chunks[1].Usage.TotalTokens)
+}
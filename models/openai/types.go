@@ -0,0 +1,233 @@
+// Package openai provides type definitions for the OpenAI Chat Completions API.
+// The same wire format is served by self-hosted OpenAI-compatible servers
+// (vLLM, llama.cpp server, LM Studio), so these types are kept to the
+// common subset every such server understands.
+//
+// Key Type Categories:
+// - Request Types: Message, content part, tool and response format structures
+// - Response Types: Choice, usage and error structures
+// - Embedding Types: Embeddings request and response structures
+// - Streaming Types: Chunk, delta and tool call fragment structures
+// - Constants: Endpoints, content types and enumeration values
+//
+// Message Content:
+// - Plain text messages are sent with string content
+// - Messages with images are sent as an array of text and image_url parts
+//
+// Usage Example:
+//
+//	req := &OpenAIRequest{
+//	    Model: "gpt-4o-mini",
+//	    Messages: []OpenAIMessage{...},
+//	    ResponseFormat: &OpenAIResponseFormat{Type: OpenAIResponseFormatJSONObject},
+//	}
+package openai
+
+// OpenAIMessage represents a message in OpenAI API format
+type OpenAIMessage struct {
+	Role       string           `json:"role"`
+	Content    interface{}      `json:"content"` // string or []OpenAIContentPart
+	Name       *string          `json:"name,omitempty"`
+	ToolCalls  []OpenAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// OpenAIContentPart represents a part of multimodal message content
+type OpenAIContentPart struct {
+	Type     string          `json:"type"` // "text" or "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *OpenAIImageURL `json:"image_url,omitempty"`
+}
+
+// OpenAIImageURL represents an image reference; URL may be an http(s) or data: URL
+type OpenAIImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"` // "auto", "low", "high"
+}
+
+// OpenAIToolCall represents a tool call in OpenAI API format
+type OpenAIToolCall struct {
+	Index    *int               `json:"index,omitempty"` // set on streaming fragments only
+	ID       string             `json:"id,omitempty"`
+	Type     string             `json:"type,omitempty"` // always "function"
+	Function OpenAIFunctionCall `json:"function"`
+}
+
+// OpenAIFunctionCall represents a function call in OpenAI API format
+type OpenAIFunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// OpenAITool represents a tool definition in OpenAI API format
+type OpenAITool struct {
+	Type     string             `json:"type"` // always "function"
+	Function OpenAIToolFunction `json:"function"`
+}
+
+// OpenAIToolFunction represents a tool function definition
+type OpenAIToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// OpenAIResponseFormat represents response format configuration
+type OpenAIResponseFormat struct {
+	Type string `json:"type"` // "text" or "json_object"
+}
+
+// OpenAIStreamOptions represents streaming options
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// OpenAIRequest represents a chat completion request
+type OpenAIRequest struct {
+	Model            string                `json:"model"`
+	Messages         []OpenAIMessage       `json:"messages"`
+	Temperature      *float32              `json:"temperature,omitempty"`
+	MaxTokens        *int                  `json:"max_tokens,omitempty"`
+	Stream           bool                  `json:"stream,omitempty"`
+	StreamOptions    *OpenAIStreamOptions  `json:"stream_options,omitempty"`
+	Tools            []OpenAITool          `json:"tools,omitempty"`
+	ToolChoice       interface{}           `json:"tool_choice,omitempty"` // "none", "auto", or specific tool
+	TopP             *float32              `json:"top_p,omitempty"`
+	FrequencyPenalty *float32              `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float32              `json:"presence_penalty,omitempty"`
+	Stop             interface{}           `json:"stop,omitempty"` // string or []string
+	Seed             *int                  `json:"seed,omitempty"`
+	ResponseFormat   *OpenAIResponseFormat `json:"response_format,omitempty"`
+	LogProbs         bool                  `json:"logprobs,omitempty"`
+	TopLogProbs      *int                  `json:"top_logprobs,omitempty"`
+}
+
+// OpenAIChoice represents a choice in a chat completion response
+type OpenAIChoice struct {
+	Index        int             `json:"index"`
+	Message      OpenAIMessage   `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+	LogProbs     *OpenAILogProbs `json:"logprobs,omitempty"`
+}
+
+// OpenAILogProbs represents log probabilities of a choice
+type OpenAILogProbs struct {
+	Content []OpenAITokenLogProb `json:"content,omitempty"`
+}
+
+// OpenAITokenLogProb represents token log probability information
+type OpenAITokenLogProb struct {
+	Token       string             `json:"token"`
+	LogProb     float64            `json:"logprob"`
+	Bytes       []int              `json:"bytes,omitempty"`
+	TopLogProbs []OpenAITopLogProb `json:"top_logprobs,omitempty"`
+}
+
+// OpenAITopLogProb represents top log probability information
+type OpenAITopLogProb struct {
+	Token   string  `json:"token"`
+	LogProb float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+}
+
+// OpenAIUsage represents token usage information
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// OpenAIResponse represents a chat completion response
+type OpenAIResponse struct {
+	ID                string         `json:"id"`
+	Object            string         `json:"object"` // "chat.completion"
+	Created           int64          `json:"created"`
+	Model             string         `json:"model"`
+	SystemFingerprint string         `json:"system_fingerprint,omitempty"`
+	Choices           []OpenAIChoice `json:"choices"`
+	Usage             *OpenAIUsage   `json:"usage,omitempty"`
+}
+
+// OpenAIEmbeddingRequest represents an embeddings request
+type OpenAIEmbeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"` // text-embedding-3 models only
+	EncodingFormat string   `json:"encoding_format,omitempty"`
+}
+
+// OpenAIEmbedding represents one vector of an embeddings response
+type OpenAIEmbedding struct {
+	Object    string    `json:"object"` // "embedding"
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// OpenAIEmbeddingResponse represents an embeddings response
+type OpenAIEmbeddingResponse struct {
+	Object string            `json:"object"` // "list"
+	Data   []OpenAIEmbedding `json:"data"`
+	Model  string            `json:"model"`
+	Usage  *OpenAIUsage      `json:"usage,omitempty"` // prompt_tokens and total_tokens
+}
+
+// OpenAIStreamDelta represents a delta in a streaming response
+type OpenAIStreamDelta struct {
+	Role      string           `json:"role,omitempty"`
+	Content   string           `json:"content,omitempty"`
+	ToolCalls []OpenAIToolCall `json:"tool_calls,omitempty"`
+}
+
+// OpenAIStreamChoice represents a choice in a streaming response
+type OpenAIStreamChoice struct {
+	Index        int               `json:"index"`
+	Delta        OpenAIStreamDelta `json:"delta"`
+	FinishReason *string           `json:"finish_reason,omitempty"`
+	LogProbs     *OpenAILogProbs   `json:"logprobs,omitempty"`
+}
+
+// OpenAIStreamResponse represents a chunk of a streaming response
+type OpenAIStreamResponse struct {
+	ID                string               `json:"id"`
+	Object            string               `json:"object"` // "chat.completion.chunk"
+	Created           int64                `json:"created"`
+	Model             string               `json:"model"`
+	SystemFingerprint string               `json:"system_fingerprint,omitempty"`
+	Choices           []OpenAIStreamChoice `json:"choices"`
+	Usage             *OpenAIUsage         `json:"usage,omitempty"`
+}
+
+// OpenAIError represents an error response from the API
+type OpenAIError struct {
+	Error OpenAIErrorDetail `json:"error"`
+}
+
+// OpenAIErrorDetail represents error details
+type OpenAIErrorDetail struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type,omitempty"`
+	Code    interface{} `json:"code,omitempty"` // string on OpenAI, number on some compatible servers
+}
+
+// Constants for OpenAI API
+const (
+	// Content part types
+	OpenAIContentTypeText     = "text"
+	OpenAIContentTypeImageURL = "image_url"
+
+	// Image detail levels
+	OpenAIImageDetailAuto = "auto"
+
+	// Finish reasons
+	OpenAIFinishReasonStop   = "stop"
+	OpenAIFinishReasonLength = "length"
+	OpenAIFinishReasonTool   = "tool_calls"
+
+	// Tool choices
+	OpenAIToolChoiceNone = "none"
+	OpenAIToolChoiceAuto = "auto"
+
+	// Response formats
+	OpenAIResponseFormatText       = "text"
+	OpenAIResponseFormatJSONObject = "json_object"
+)
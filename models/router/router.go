@@ -0,0 +1,535 @@
+// Package router provides a virtual model that routes requests to registered models
+//
+// A RouterModel is registered in the PonchoModelRegistry like any other model
+// (e.g. "vision-default") and forwards each request to one of its routes:
+//
+//   - Fallback: routes are tried in order; a retryable error or attempt timeout
+//     falls through to the next route
+//   - Weighted: routes are ordered by weighted random choice (A/B splits), the
+//     remaining routes still serve as fallbacks
+//   - Capability-based selection: routes whose model cannot serve the request
+//     (media parts without vision, tools without tool support, streaming without
+//     streaming support) are skipped
+//
+// Errors are classified with common.ModelError.IsRetryable(); non-retryable errors
+// are returned immediately. The chosen model is recorded in the response Metadata.
+// Routes that lead back to the router through other routers (A -> B -> A) are a
+// configuration error: NewRouterModel rejects them, and a router closed into a
+// cycle by a later registration fails its requests with INVALID_CONFIG.
+//
+// Once registered with the framework (or given it as Config.Dispatcher), each attempt
+// is a framework call to the routed model, so it passes that model's middleware,
+// response cache, budgets, resilience guards and metrics and is recorded and costed
+// as a call to it.
+//
+// Usage:
+//
+//	router, err := router.NewRouterModel("vision-default", framework.GetModelRegistry(), router.Config{
+//	    Strategy:       router.StrategyFallback,
+//	    Routes:         []router.Route{{Model: "glm-4.6v-flash"}, {Model: "glm-4.6v"}},
+//	    AttemptTimeout: 20 * time.Second,
+//	}, logger)
+//	err = framework.RegisterModel("vision-default", router)
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// Provider is the provider name reported by router models
+const Provider = "router"
+
+// Metadata keys set on routed responses and stream chunks
+const (
+	MetadataRouter        = "router"
+	MetadataRoutedModel   = "routed_model"
+	MetadataRouteAttempts = "route_attempts"
+)
+
+// Strategy defines how routes are ordered for a request
+type Strategy string
+
+const (
+	// StrategyFallback tries routes in the configured order
+	StrategyFallback Strategy = "fallback"
+	// StrategyWeighted picks routes by weighted random choice
+	StrategyWeighted Strategy = "weighted"
+)
+
+// Route is a target model of the router
+type Route struct {
+	// Model is the name of the target model in the registry
+	Model string
+	// Weight is the relative share of traffic for StrategyWeighted; zero means 1
+	Weight int
+}
+
+// Config represents router configuration
+type Config struct {
+	Strategy Strategy
+	Routes   []Route
+	// AttemptTimeout bounds a single attempt; for streaming it bounds the time to
+	// the first chunk. Zero disables the per-attempt timeout.
+	AttemptTimeout time.Duration
+	// Dispatcher runs the attempts on models named by PonchoModelRequest.Model,
+	// normally the framework (see SetDispatcher). Without one, routed models are
+	// called directly.
+	Dispatcher common.Generator
+}
+
+// RouterModel is a virtual PonchoModel that routes requests to registered models
+type RouterModel struct {
+	name     string
+	registry interfaces.PonchoModelRegistry
+	config   Config
+	logger   interfaces.Logger
+
+	random *rand.Rand
+	mutex  sync.Mutex
+}
+
+// candidate is a resolved route
+type candidate struct {
+	name  string
+	model interfaces.PonchoModel
+}
+
+// NewRouterModel creates a router model that resolves its routes in registry
+func NewRouterModel(name string, registry interfaces.PonchoModelRegistry, config Config, logger interfaces.Logger) (*RouterModel, error) {
+	if name == "" {
+		return nil, fmt.Errorf("router name cannot be empty")
+	}
+	if registry == nil {
+		return nil, fmt.Errorf("model registry cannot be nil")
+	}
+	if len(config.Routes) == 0 {
+		return nil, fmt.Errorf("router '%s' requires at least one route", name)
+	}
+
+	switch config.Strategy {
+	case "":
+		config.Strategy = StrategyFallback
+	case StrategyFallback, StrategyWeighted:
+	default:
+		return nil, fmt.Errorf("unsupported routing strategy: %s", config.Strategy)
+	}
+
+	for _, route := range config.Routes {
+		if route.Model == "" {
+			return nil, fmt.Errorf("route model cannot be empty")
+		}
+		if route.Model == name {
+			return nil, fmt.Errorf("router '%s' cannot route to itself", name)
+		}
+		if route.Weight < 0 {
+			return nil, fmt.Errorf("route '%s' has negative weight", route.Model)
+		}
+	}
+
+	if config.AttemptTimeout < 0 {
+		return nil, fmt.Errorf("attempt timeout cannot be negative")
+	}
+
+	if chain := routeCycle(registry, name, config.Routes); chain != nil {
+		return nil, cycleError(name, chain)
+	}
+
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	now := uint64(time.Now().UnixNano())
+	return &RouterModel{
+		name:     name,
+		registry: registry,
+		config:   config,
+		logger:   logger,
+		random:   rand.New(rand.NewPCG(now, now>>1)),
+	}, nil
+}
+
+// Generate routes a request to the first route that succeeds
+func (r *RouterModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	candidates, err := r.candidates(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for attempt, c := range candidates {
+		attemptCtx, cancel := r.attemptContext(ctx)
+		resp, err := r.dispatcher(c).Generate(attemptCtx, r.routedRequest(req, c))
+		timedOut := attemptCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
+
+		if err == nil {
+			if resp.Metadata == nil {
+				resp.Metadata = make(map[string]interface{})
+			}
+			resp.Metadata[MetadataRouter] = r.name
+			resp.Metadata[MetadataRoutedModel] = c.name
+			resp.Metadata[MetadataRouteAttempts] = attempt + 1
+			return resp, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		if !r.shouldFallback(ctx, err, timedOut) {
+			return nil, fmt.Errorf("router '%s': model '%s' failed: %w", r.name, c.name, err)
+		}
+
+		r.logger.Warn("Routed model failed, falling back",
+			"router", r.name, "model", c.name, "attempt", attempt+1, "error", err)
+	}
+
+	return nil, fmt.Errorf("router '%s': all routes failed: %w", r.name, errors.Join(errs...))
+}
+
+// GenerateStreaming routes a streaming request. Falling back is only possible
+// before the first chunk has been delivered to the callback.
+func (r *RouterModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	candidates, err := r.candidates(req, true)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for attempt, c := range candidates {
+		var delivered, timedOut atomic.Bool
+
+		attemptCtx, cancel := context.WithCancel(ctx)
+		var timer *time.Timer
+		if r.config.AttemptTimeout > 0 {
+			timer = time.AfterFunc(r.config.AttemptTimeout, func() {
+				if !delivered.Load() {
+					timedOut.Store(true)
+					cancel()
+				}
+			})
+		}
+
+		routedAttempt := attempt + 1
+		err := r.dispatcher(c).GenerateStreaming(attemptCtx, r.routedRequest(req, c), func(chunk *interfaces.PonchoStreamChunk) error {
+			if delivered.CompareAndSwap(false, true) && timer != nil {
+				timer.Stop()
+			}
+			if chunk.Metadata == nil {
+				chunk.Metadata = make(map[string]interface{})
+			}
+			chunk.Metadata[MetadataRouter] = r.name
+			chunk.Metadata[MetadataRoutedModel] = c.name
+			chunk.Metadata[MetadataRouteAttempts] = routedAttempt
+			return callback(chunk)
+		})
+		if timer != nil {
+			timer.Stop()
+		}
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		if delivered.Load() || !r.shouldFallback(ctx, err, timedOut.Load()) {
+			return fmt.Errorf("router '%s': model '%s' failed: %w", r.name, c.name, err)
+		}
+
+		r.logger.Warn("Routed model stream failed, falling back",
+			"router", r.name, "model", c.name, "attempt", routedAttempt, "error", err)
+	}
+
+	return fmt.Errorf("router '%s': all routes failed: %w", r.name, errors.Join(errs...))
+}
+
+// candidates resolves the routes able to serve the request, in attempt order
+func (r *RouterModel) candidates(req *interfaces.PonchoModelRequest, streaming bool) ([]candidate, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	// Routers registered after this one may close a cycle back to it
+	if chain := routeCycle(r.registry, r.name, r.config.Routes); chain != nil {
+		return nil, common.NewModelError(common.ErrorCodeInvalidConfig, cycleError(r.name, chain).Error(), Provider, r.name)
+	}
+
+	needsVision := hasMedia(req)
+	needsTools := len(req.Tools) > 0
+
+	candidates := make([]candidate, 0, len(r.config.Routes))
+	weights := make([]int, 0, len(r.config.Routes))
+	for _, route := range r.config.Routes {
+		model, err := r.registry.Get(route.Model)
+		if err != nil {
+			r.logger.Debug("Skipping unavailable route", "router", r.name, "model", route.Model)
+			continue
+		}
+
+		if (needsVision && !model.SupportsVision()) ||
+			(needsTools && !model.SupportsTools()) ||
+			(streaming && !model.SupportsStreaming()) {
+			continue
+		}
+
+		candidates = append(candidates, candidate{name: route.Model, model: model})
+		weights = append(weights, max(route.Weight, 1))
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("router '%s': no route can serve the request (vision=%t, tools=%t, streaming=%t)",
+			r.name, needsVision, needsTools, streaming)
+	}
+
+	if r.config.Strategy == StrategyWeighted {
+		r.shuffleWeighted(candidates, weights)
+	}
+
+	return candidates, nil
+}
+
+// shuffleWeighted orders candidates by weighted random sampling without replacement
+func (r *RouterModel) shuffleWeighted(candidates []candidate, weights []int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := 0; i < len(candidates)-1; i++ {
+		total := 0
+		for _, weight := range weights[i:] {
+			total += weight
+		}
+
+		pick := r.random.IntN(total)
+		for j := i; j < len(candidates); j++ {
+			pick -= weights[j]
+			if pick < 0 {
+				candidates[i], candidates[j] = candidates[j], candidates[i]
+				weights[i], weights[j] = weights[j], weights[i]
+				break
+			}
+		}
+	}
+}
+
+// attemptContext applies the per-attempt timeout
+func (r *RouterModel) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.config.AttemptTimeout > 0 {
+		return context.WithTimeout(ctx, r.config.AttemptTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// SetDispatcher makes the router run its attempts through dispatcher. The framework
+// calls it when the router is registered.
+func (r *RouterModel) SetDispatcher(dispatcher common.Generator) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.config.Dispatcher = dispatcher
+}
+
+// dispatcher returns what runs an attempt on c: the dispatcher, or the model itself
+func (r *RouterModel) dispatcher(c candidate) common.Generator {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.config.Dispatcher != nil {
+		return r.config.Dispatcher
+	}
+	return c.model
+}
+
+// routedRequest returns a shallow copy of the request addressed to the routed model
+func (r *RouterModel) routedRequest(req *interfaces.PonchoModelRequest, c candidate) *interfaces.PonchoModelRequest {
+	routed := *req
+	routed.Model = c.name
+	return &routed
+}
+
+// shouldFallback decides whether a failed attempt falls through to the next route
+func (r *RouterModel) shouldFallback(ctx context.Context, err error, timedOut bool) bool {
+	// The caller gave up, further attempts are pointless
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if timedOut {
+		return true
+	}
+
+	var modelErr *common.ModelError
+	if errors.As(err, &modelErr) {
+		return modelErr.IsRetryable()
+	}
+
+	return false
+}
+
+// hasMedia reports whether any message contains media parts
+func hasMedia(req *interfaces.PonchoModelRequest) bool {
+	for _, message := range req.Messages {
+		if message == nil {
+			continue
+		}
+		for _, part := range message.Content {
+			if part != nil && part.Type == interfaces.PonchoContentTypeMedia {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Targets returns the names of the routed models
+func (r *RouterModel) Targets() []string {
+	targets := make([]string, len(r.config.Routes))
+	for i, route := range r.config.Routes {
+		targets[i] = route.Model
+	}
+	return targets
+}
+
+// resolved returns the currently registered route models in configured order.
+// A router whose routes lead back to it resolves no routes.
+func (r *RouterModel) resolved() []interfaces.PonchoModel {
+	if routeCycle(r.registry, r.name, r.config.Routes) != nil {
+		return nil
+	}
+
+	models := make([]interfaces.PonchoModel, 0, len(r.config.Routes))
+	for _, route := range r.config.Routes {
+		if model, err := r.registry.Get(route.Model); err == nil {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// anyRoute reports whether any registered route satisfies check
+func (r *RouterModel) anyRoute(check func(interfaces.PonchoModel) bool) bool {
+	for _, model := range r.resolved() {
+		if check(model) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsStreaming returns whether any route supports streaming
+func (r *RouterModel) SupportsStreaming() bool {
+	return r.anyRoute(interfaces.PonchoModel.SupportsStreaming)
+}
+
+// SupportsTools returns whether any route supports tool calling
+func (r *RouterModel) SupportsTools() bool {
+	return r.anyRoute(interfaces.PonchoModel.SupportsTools)
+}
+
+// SupportsVision returns whether any route supports vision
+func (r *RouterModel) SupportsVision() bool {
+	return r.anyRoute(interfaces.PonchoModel.SupportsVision)
+}
+
+// SupportsSystemRole returns whether any route supports the system role
+func (r *RouterModel) SupportsSystemRole() bool {
+	return r.anyRoute(interfaces.PonchoModel.SupportsSystemRole)
+}
+
+// Name returns the router name
+func (r *RouterModel) Name() string {
+	return r.name
+}
+
+// Provider returns the router provider name
+func (r *RouterModel) Provider() string {
+	return Provider
+}
+
+// MaxTokens returns the max tokens of the first registered route
+func (r *RouterModel) MaxTokens() int {
+	if models := r.resolved(); len(models) > 0 {
+		return models[0].MaxTokens()
+	}
+	return 0
+}
+
+// DefaultTemperature returns the default temperature of the first registered route
+func (r *RouterModel) DefaultTemperature() float32 {
+	if models := r.resolved(); len(models) > 0 {
+		return models[0].DefaultTemperature()
+	}
+	return 0
+}
+
+// Initialize is a no-op, routed models are initialized on their own
+func (r *RouterModel) Initialize(ctx context.Context, config map[string]interface{}) error {
+	return nil
+}
+
+// Shutdown is a no-op, the router does not own routed models
+func (r *RouterModel) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// HealthCheck reports an error if the routes lead back to the router or no route
+// is registered. Routed models are probed on their own.
+func (r *RouterModel) HealthCheck(ctx context.Context) error {
+	if chain := routeCycle(r.registry, r.name, r.config.Routes); chain != nil {
+		return cycleError(r.name, chain)
+	}
+	if len(r.resolved()) == 0 {
+		return fmt.Errorf("router '%s' has no registered routes", r.name)
+	}
+	return nil
+}
+
+// routeCycle returns the chain of registered models through which routes lead back
+// to the router name, or nil when there is none. Routers are followed by their Targets.
+func routeCycle(registry interfaces.PonchoModelRegistry, name string, routes []Route) []string {
+	targets := make([]string, len(routes))
+	for i, route := range routes {
+		targets[i] = route.Model
+	}
+
+	visited := make(map[string]bool)
+	var walk func(chain, targets []string) []string
+	walk = func(chain, targets []string) []string {
+		for _, target := range targets {
+			// Full slice expression: siblings must not share the chain's backing array
+			next := append(chain[:len(chain):len(chain)], target)
+			if target == name {
+				return next
+			}
+			if visited[target] {
+				continue
+			}
+			visited[target] = true
+
+			model, err := registry.Get(target)
+			if err != nil {
+				continue
+			}
+			if composite, ok := model.(interface{ Targets() []string }); ok {
+				if cycle := walk(next, composite.Targets()); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		return nil
+	}
+
+	return walk([]string{name}, targets)
+}
+
+// cycleError reports routes that lead back to the router
+func cycleError(name string, chain []string) error {
+	return fmt.Errorf("router '%s' routes back to itself: %s", name, strings.Join(chain, " -> "))
+}
@@ -0,0 +1,301 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/core/registry"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// fakeModel returns a fixed error or a response naming the model
+type fakeModel struct {
+	*base.PonchoBaseModel
+	err   error
+	delay time.Duration
+	calls int
+}
+
+func (m *fakeModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	m.calls++
+	if m.delay > 0 {
+		select {
+		case <-time.After(m.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &interfaces.PonchoModelResponse{
+		Message: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: req.Model}},
+		},
+	}, nil
+}
+
+func (m *fakeModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	m.calls++
+	if m.err != nil {
+		return m.err
+	}
+	return callback(&interfaces.PonchoStreamChunk{Delta: &interfaces.PonchoMessage{Role: interfaces.PonchoRoleAssistant}, Done: true})
+}
+
+func newFakeModel(name string, caps interfaces.ModelCapabilities, err error) *fakeModel {
+	return &fakeModel{PonchoBaseModel: base.NewPonchoBaseModel(name, "fake", caps), err: err}
+}
+
+func retryableError() error {
+	return &common.ModelError{Code: common.ErrorCodeServerError, Message: "overloaded", Retryable: true}
+}
+
+func newTestRegistry(t *testing.T, models map[string]*fakeModel) interfaces.PonchoModelRegistry {
+	t.Helper()
+
+	reg := registry.NewPonchoModelRegistry(interfaces.NewNoOpLogger())
+	for name, model := range models {
+		require.NoError(t, reg.Register(name, model))
+	}
+	return reg
+}
+
+func newTestRouter(t *testing.T, reg interfaces.PonchoModelRegistry, config Config) *RouterModel {
+	t.Helper()
+
+	router, err := NewRouterModel("default", reg, config, interfaces.NewNoOpLogger())
+	require.NoError(t, err)
+	return router
+}
+
+func textRequest() *interfaces.PonchoModelRequest {
+	return &interfaces.PonchoModelRequest{
+		Model: "default",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "hi"}},
+		}},
+	}
+}
+
+func TestNewRouterModelValidation(t *testing.T) {
+	reg := newTestRegistry(t, nil)
+
+	_, err := NewRouterModel("default", reg, Config{}, nil)
+	assert.Error(t, err, "routes are required")
+
+	_, err = NewRouterModel("default", reg, Config{Routes: []Route{{Model: "default"}}}, nil)
+	assert.Error(t, err, "self routing must be rejected")
+
+	_, err = NewRouterModel("default", reg, Config{Strategy: "random", Routes: []Route{{Model: "a"}}}, nil)
+	assert.Error(t, err, "unknown strategy must be rejected")
+}
+
+func TestFallbackOnRetryableError(t *testing.T) {
+	flash := newFakeModel("flash", interfaces.ModelCapabilities{}, retryableError())
+	full := newFakeModel("full", interfaces.ModelCapabilities{}, nil)
+	router := newTestRouter(t, newTestRegistry(t, map[string]*fakeModel{"flash": flash, "full": full}),
+		Config{Routes: []Route{{Model: "flash"}, {Model: "full"}}})
+
+	resp, err := router.Generate(context.Background(), textRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, "full", resp.Message.Content[0].Text, "routed request must address the target model")
+	assert.Equal(t, "full", resp.Metadata[MetadataRoutedModel])
+	assert.Equal(t, "default", resp.Metadata[MetadataRouter])
+	assert.Equal(t, 2, resp.Metadata[MetadataRouteAttempts])
+}
+
+func TestNoFallbackOnNonRetryableError(t *testing.T) {
+	flash := newFakeModel("flash", interfaces.ModelCapabilities{}, &common.ModelError{Code: common.ErrorCodeInvalidRequest, Message: "bad request"})
+	full := newFakeModel("full", interfaces.ModelCapabilities{}, nil)
+	router := newTestRouter(t, newTestRegistry(t, map[string]*fakeModel{"flash": flash, "full": full}),
+		Config{Routes: []Route{{Model: "flash"}, {Model: "full"}}})
+
+	_, err := router.Generate(context.Background(), textRequest())
+	require.Error(t, err)
+
+	var modelErr *common.ModelError
+	assert.True(t, errors.As(err, &modelErr))
+	assert.Equal(t, 0, full.calls)
+}
+
+func TestFallbackOnAttemptTimeout(t *testing.T) {
+	slow := newFakeModel("slow", interfaces.ModelCapabilities{}, nil)
+	slow.delay = time.Second
+	fast := newFakeModel("fast", interfaces.ModelCapabilities{}, nil)
+	router := newTestRouter(t, newTestRegistry(t, map[string]*fakeModel{"slow": slow, "fast": fast}),
+		Config{Routes: []Route{{Model: "slow"}, {Model: "fast"}}, AttemptTimeout: 20 * time.Millisecond})
+
+	resp, err := router.Generate(context.Background(), textRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "fast", resp.Metadata[MetadataRoutedModel])
+}
+
+func TestAllRoutesFailed(t *testing.T) {
+	a := newFakeModel("a", interfaces.ModelCapabilities{}, retryableError())
+	b := newFakeModel("b", interfaces.ModelCapabilities{}, retryableError())
+	router := newTestRouter(t, newTestRegistry(t, map[string]*fakeModel{"a": a, "b": b}),
+		Config{Routes: []Route{{Model: "a"}, {Model: "b"}, {Model: "missing"}}})
+
+	_, err := router.Generate(context.Background(), textRequest())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all routes failed")
+	assert.Equal(t, 1, a.calls)
+	assert.Equal(t, 1, b.calls)
+}
+
+func TestCapabilityBasedSelection(t *testing.T) {
+	text := newFakeModel("text", interfaces.ModelCapabilities{}, nil)
+	vision := newFakeModel("vision", interfaces.ModelCapabilities{Vision: true}, nil)
+	router := newTestRouter(t, newTestRegistry(t, map[string]*fakeModel{"text": text, "vision": vision}),
+		Config{Routes: []Route{{Model: "text"}, {Model: "vision"}}})
+
+	resp, err := router.Generate(context.Background(), textRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "text", resp.Metadata[MetadataRoutedModel])
+
+	req := textRequest()
+	req.Messages[0].Content = append(req.Messages[0].Content, &interfaces.PonchoContentPart{
+		Type:  interfaces.PonchoContentTypeMedia,
+		Media: &interfaces.PonchoMediaPart{URL: "https://example.com/dress.jpg", MimeType: "image/jpeg"},
+	})
+	resp, err = router.Generate(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "vision", resp.Metadata[MetadataRoutedModel])
+
+	req.Tools = []*interfaces.PonchoToolDef{{Name: "search"}}
+	_, err = router.Generate(context.Background(), req)
+	assert.Error(t, err, "no route supports vision and tools")
+}
+
+func TestWeightedSplit(t *testing.T) {
+	a := newFakeModel("a", interfaces.ModelCapabilities{}, nil)
+	b := newFakeModel("b", interfaces.ModelCapabilities{}, nil)
+	router := newTestRouter(t, newTestRegistry(t, map[string]*fakeModel{"a": a, "b": b}),
+		Config{Strategy: StrategyWeighted, Routes: []Route{{Model: "a", Weight: 9}, {Model: "b", Weight: 1}}})
+	router.random = rand.New(rand.NewPCG(1, 2))
+
+	for i := 0; i < 1000; i++ {
+		_, err := router.Generate(context.Background(), textRequest())
+		require.NoError(t, err)
+	}
+
+	assert.InDelta(t, 900, a.calls, 60)
+	assert.InDelta(t, 100, b.calls, 60)
+}
+
+func TestStreamingFallback(t *testing.T) {
+	broken := newFakeModel("broken", interfaces.ModelCapabilities{Streaming: true}, retryableError())
+	noStream := newFakeModel("no-stream", interfaces.ModelCapabilities{}, nil)
+	stream := newFakeModel("stream", interfaces.ModelCapabilities{Streaming: true}, nil)
+	router := newTestRouter(t, newTestRegistry(t, map[string]*fakeModel{"broken": broken, "no-stream": noStream, "stream": stream}),
+		Config{Routes: []Route{{Model: "broken"}, {Model: "no-stream"}, {Model: "stream"}}})
+
+	assert.True(t, router.SupportsStreaming())
+
+	var chunks []*interfaces.PonchoStreamChunk
+	err := router.GenerateStreaming(context.Background(), textRequest(), func(chunk *interfaces.PonchoStreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "stream", chunks[0].Metadata[MetadataRoutedModel])
+	assert.Equal(t, 0, noStream.calls)
+}
+
+// recordingDispatcher runs calls on the models of a registry and records their names
+type recordingDispatcher struct {
+	registry interfaces.PonchoModelRegistry
+	models   []string
+}
+
+func (d *recordingDispatcher) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	d.models = append(d.models, req.Model)
+	model, err := d.registry.Get(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	return model.Generate(ctx, req)
+}
+
+func (d *recordingDispatcher) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	d.models = append(d.models, req.Model)
+	model, err := d.registry.Get(req.Model)
+	if err != nil {
+		return err
+	}
+	return model.GenerateStreaming(ctx, req, callback)
+}
+
+func TestDispatcher(t *testing.T) {
+	flash := newFakeModel("flash", interfaces.ModelCapabilities{Streaming: true}, retryableError())
+	full := newFakeModel("full", interfaces.ModelCapabilities{Streaming: true}, nil)
+	reg := newTestRegistry(t, map[string]*fakeModel{"flash": flash, "full": full})
+	dispatcher := &recordingDispatcher{registry: reg}
+	router := newTestRouter(t, reg, Config{Routes: []Route{{Model: "flash"}, {Model: "full"}}, Dispatcher: dispatcher})
+
+	resp, err := router.Generate(context.Background(), textRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "full", resp.Metadata[MetadataRoutedModel])
+	assert.Equal(t, []string{"flash", "full"}, dispatcher.models, "each attempt must be dispatched to its target")
+
+	// SetDispatcher replaces the configured dispatcher
+	streaming := &recordingDispatcher{registry: reg}
+	router.SetDispatcher(streaming)
+	err = router.GenerateStreaming(context.Background(), textRequest(), func(chunk *interfaces.PonchoStreamChunk) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"flash", "full"}, streaming.models)
+	assert.Len(t, dispatcher.models, 2)
+}
+
+func TestHealthCheck(t *testing.T) {
+	router := newTestRouter(t, newTestRegistry(t, nil), Config{Routes: []Route{{Model: "missing"}}})
+	assert.Error(t, router.HealthCheck(context.Background()))
+	assert.Equal(t, []string{"missing"}, router.Targets())
+}
+
+func TestRouteCycles(t *testing.T) {
+	chat := newFakeModel("chat", interfaces.ModelCapabilities{Streaming: true}, nil)
+	reg := newTestRegistry(t, map[string]*fakeModel{"chat": chat})
+
+	// Neither router is registered yet, so no cycle is visible on creation
+	a, err := NewRouterModel("a", reg, Config{Routes: []Route{{Model: "b"}, {Model: "chat"}}}, nil)
+	require.NoError(t, err)
+	b, err := NewRouterModel("b", reg, Config{Routes: []Route{{Model: "a"}}}, nil)
+	require.NoError(t, err)
+	require.NoError(t, reg.Register("a", a))
+
+	// A router closing a cycle through registered routers is rejected
+	_, err = NewRouterModel("b", reg, Config{Routes: []Route{{Model: "a"}}}, nil)
+	assert.ErrorContains(t, err, "b -> a -> b")
+
+	// Registering b closes the cycle: requests fail instead of recursing
+	require.NoError(t, reg.Register("b", b))
+	_, err = a.Generate(context.Background(), textRequest())
+	var modelErr *common.ModelError
+	require.ErrorAs(t, err, &modelErr)
+	assert.Equal(t, common.ErrorCodeInvalidConfig, modelErr.Code)
+	assert.Contains(t, err.Error(), "a -> b -> a")
+
+	err = b.GenerateStreaming(context.Background(), textRequest(), func(chunk *interfaces.PonchoStreamChunk) error { return nil })
+	require.ErrorAs(t, err, &modelErr)
+	assert.Equal(t, common.ErrorCodeInvalidConfig, modelErr.Code)
+
+	assert.False(t, a.SupportsStreaming())
+	assert.ErrorContains(t, a.HealthCheck(context.Background()), "routes back to itself")
+	assert.Zero(t, chat.calls)
+}
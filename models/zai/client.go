@@ -48,6 +48,10 @@ type ZAIClient struct {
 	apiKey       string
 	baseURL      string
 	visionConfig *ZAIVisionConfig
+	rateLimiter  *common.RateLimiter
+	tokenizer    *common.Tokenizer
+
+	releaseRateLimiter func()
 }
 
 // NewZAIClient creates a new Z.AI client
@@ -95,6 +99,7 @@ func NewZAIClient(config *common.CommonModelConfig, logger interfaces.Logger) (*
 		apiKey:       config.APIKey,
 		baseURL:      config.BaseURL,
 		visionConfig: visionConfig,
+		tokenizer:    common.NewTokenizer(logger),
 	}
 
 	// Use default base URL if not provided
@@ -109,6 +114,7 @@ func NewZAIClient(config *common.CommonModelConfig, logger interfaces.Logger) (*
 			return nil, fmt.Errorf("ZAI_API_KEY environment variable is required")
 		}
 	}
+	client.claimRateLimiter()
 
 	logger.Info("Z.AI client created",
 		"model", config.Model,
@@ -143,6 +149,9 @@ func validateConfig(config *common.CommonModelConfig) error {
 
 // Close closes Z.AI client and cleans up resources
 func (c *ZAIClient) Close() error {
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
+	}
 	if c.httpClient != nil {
 		return c.httpClient.Close()
 	}
@@ -178,6 +187,8 @@ func (c *ZAIClient) UpdateConfig(config *common.CommonModelConfig) error {
 		c.baseURL = config.BaseURL
 	}
 
+	c.claimRateLimiter()
+
 	c.logger.Info("Z.AI client configuration updated",
 		"model", config.Model,
 		"base_url", c.baseURL)
@@ -319,11 +330,24 @@ func (c *ZAIClient) IsHealthy(ctx context.Context) error {
 
 // GetRateLimitInfo returns current rate limit information
 func (c *ZAIClient) GetRateLimitInfo() *common.RateLimitInfo {
-	// Z.AI rate limits (example values - should be updated based on actual API docs)
-	return &common.RateLimitInfo{
-		RequestsPerMinute: 120,
-		TokensPerMinute:   200000,
+	// Shared by the clients of the account; adapted to the rate limit headers of responses
+	return c.rateLimiter.Info()
+}
+
+// claimRateLimiter switches to the rate limiter of the client's account with the
+// limits of its config, releasing the previous one
+func (c *ZAIClient) claimRateLimiter() {
+	limiter, release := common.ConfiguredRateLimiter(c.config, c.baseURL, c.apiKey)
+	if c.releaseRateLimiter != nil {
+		c.releaseRateLimiter()
 	}
+	c.rateLimiter, c.releaseRateLimiter = limiter, release
+	c.httpClient.SetRateLimiter(limiter)
+}
+
+// rateLimitContext returns ctx carrying the estimated tokens of req for the rate limiter
+func (c *ZAIClient) rateLimitContext(ctx context.Context, req *interfaces.PonchoModelRequest) context.Context {
+	return common.WithRateLimitTokens(ctx, common.EstimateRateLimitTokens(c.tokenizer, req, c.config.Provider, c.config.Model))
 }
 
 // PrepareRequestMetrics creates metrics for a request
@@ -369,7 +393,7 @@ func (c *ZAIClient) LogResponse(resp *interfaces.PonchoModelResponse, requestID
 			"completion_tokens", resp.Usage.CompletionTokens,
 			"total_tokens", resp.Usage.TotalTokens,
 			"finish_reason", resp.FinishReason)
-	} else {
+	} else if resp != nil {
 		c.logger.Debug("Z.AI API response",
 			"request_id", requestID,
 			"duration_ms", duration.Milliseconds(),
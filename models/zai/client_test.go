@@ -427,9 +427,10 @@ func TestZAIClient_GetRateLimitInfo(t *testing.T) {
 	client, err := NewZAIClient(config, nil)
 	require.NoError(t, err)
 
+	// No limits are enforced until configured or reported by the API
 	rateLimit := client.GetRateLimitInfo()
-	assert.Equal(t, 120, rateLimit.RequestsPerMinute)
-	assert.Equal(t, 200000, rateLimit.TokensPerMinute)
+	assert.Equal(t, 0, rateLimit.RequestsPerMinute)
+	assert.Equal(t, 0, rateLimit.TokensPerMinute)
 }
 
 func TestZAIClient_UpdateConfig(t *testing.T) {
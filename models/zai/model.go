@@ -63,6 +63,7 @@ func NewZAIModel() *ZAIModel {
 		Tools:     true,
 		Vision:    true,
 		System:    true,
+		JSONMode:  true,
 	})
 
 	return &ZAIModel{
@@ -143,7 +144,8 @@ func (m *ZAIModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequ
 		return nil, fmt.Errorf("failed to convert request: %w", err)
 	}
 
-	// Make API call
+	// Make API call within the provider's rate limits
+	ctx = m.client.rateLimitContext(ctx, req)
 	zaiResp, err := m.createChatCompletion(ctx, zaiReq)
 	duration := time.Since(startTime)
 
@@ -233,8 +235,16 @@ func (m *ZAIModel) GenerateStreaming(ctx context.Context, req *interfaces.Poncho
 		return fmt.Errorf("failed to convert request: %w", err)
 	}
 
-	// Make streaming API call
+	// Make streaming API call within the provider's rate limits
+	ctx = m.client.rateLimitContext(ctx, req)
+	toolCalls := newToolCallAccumulator()
 	err = m.createChatCompletionStream(ctx, zaiReq, func(streamResp *ZAIStreamResponse) error {
+		// Tool call arguments arrive in fragments; whole calls are sent with the finish reason
+		if len(streamResp.Choices) > 0 {
+			toolCalls.add(streamResp.Choices[0].Delta.ToolCalls)
+			streamResp.Choices[0].Delta.ToolCalls = nil
+		}
+
 		// Convert stream chunk
 		chunk, err := m.convertStreamChunk(streamResp)
 		if err != nil {
@@ -244,6 +254,10 @@ func (m *ZAIModel) GenerateStreaming(ctx context.Context, req *interfaces.Poncho
 			return err
 		}
 
+		if chunk.FinishReason != "" && chunk.Delta != nil {
+			chunk.Delta.Content = append(chunk.Delta.Content, toolCalls.flush()...)
+		}
+
 		// Call callback
 		if err := callback(chunk); err != nil {
 			m.GetLogger().Error("Stream callback error",
@@ -255,6 +269,18 @@ func (m *ZAIModel) GenerateStreaming(ctx context.Context, req *interfaces.Poncho
 		return nil
 	})
 
+	// A stream can end with [DONE] or EOF and no finish reason; send the calls it left
+	if err == nil && len(toolCalls.calls) > 0 {
+		err = callback(&interfaces.PonchoStreamChunk{
+			Delta: &interfaces.PonchoMessage{
+				Role:    interfaces.PonchoRoleAssistant,
+				Content: toolCalls.flush(),
+			},
+			FinishReason: interfaces.PonchoFinishReasonTool,
+			Done:         true,
+		})
+	}
+
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -285,6 +311,15 @@ func (m *ZAIModel) GenerateStreaming(ctx context.Context, req *interfaces.Poncho
 	return nil
 }
 
+// HealthCheck implements interfaces.HealthChecker
+func (m *ZAIModel) HealthCheck(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("Z.AI model is not initialized")
+	}
+
+	return m.client.IsHealthy(ctx)
+}
+
 // Shutdown shuts down Z.AI model
 func (m *ZAIModel) Shutdown(ctx context.Context) error {
 	if m.client != nil {
@@ -342,6 +377,15 @@ func (m *ZAIModel) convertConfig(config map[string]interface{}) (*common.CommonM
 		commonConfig.BaseURL = baseURL
 	}
 
+	if customParams, ok := config["custom_params"].(map[string]interface{}); ok {
+		if err := common.RateLimitFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+		if err := common.GenerationDefaultsFromParams(commonConfig, customParams); err != nil {
+			return nil, fmt.Errorf("invalid custom_params: %w", err)
+		}
+	}
+
 	// Validate required fields
 	if commonConfig.APIKey == "" {
 		return nil, fmt.Errorf("api_key is required (set via config or ZAI_API_KEY environment variable)")
@@ -352,12 +396,38 @@ func (m *ZAIModel) convertConfig(config map[string]interface{}) (*common.CommonM
 
 // convertRequest converts Poncho request to Z.AI request format
 func (m *ZAIModel) convertRequest(req *interfaces.PonchoModelRequest) (*ZAIRequest, error) {
+	config := m.client.GetConfig()
+
+	// The chat completions API has no seed
+	if err := common.CheckRequestParams(req, string(common.ProviderZAI), config.Model, common.ParamSeed); err != nil {
+		return nil, err
+	}
+	params, err := common.ResolveGenerationParams(req, config)
+	if err != nil {
+		return nil, err
+	}
+
 	zaiReq := &ZAIRequest{
-		Model:       m.client.GetConfig().Model,
-		Messages:    make([]ZAIMessage, 0),
-		MaxTokens:   req.MaxTokens,
-		Temperature: req.Temperature,
-		Stream:      req.Stream,
+		Model:            config.Model,
+		Messages:         make([]ZAIMessage, 0),
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		Stream:           req.Stream,
+		TopP:             params.TopP,
+		FrequencyPenalty: params.FrequencyPenalty,
+		PresencePenalty:  params.PresencePenalty,
+		LogProbs:         params.LogProbs,
+		TopLogProbs:      params.TopLogProbs,
+	}
+
+	if len(params.Stop) > 0 {
+		zaiReq.Stop = params.Stop
+	}
+	if params.ResponseFormat != nil {
+		zaiReq.ResponseFormat = &ZAIResponseFormat{Type: string(*params.ResponseFormat)}
+	}
+	if params.Thinking != nil {
+		zaiReq.Thinking = &ZAIThinking{Type: string(*params.Thinking)}
 	}
 
 	// Convert tools
@@ -399,6 +469,10 @@ func (m *ZAIModel) convertMessage(msg *interfaces.PonchoMessage) (ZAIMessage, er
 		zaiMsg.Name = msg.Name
 	}
 
+	if msg.Role == interfaces.PonchoRoleTool {
+		zaiMsg.ToolCallID = common.ToolCallID(msg)
+	}
+
 	// Check if we need multimodal content
 	hasMedia := false
 	for _, part := range msg.Content {
@@ -418,6 +492,13 @@ func (m *ZAIModel) convertMessage(msg *interfaces.PonchoMessage) (ZAIMessage, er
 					Type: ZAIContentTypeText,
 					Text: part.Text,
 				})
+			case interfaces.PonchoContentTypeToolResult:
+				contentParts = append(contentParts, ZAIContentPart{
+					Type: ZAIContentTypeText,
+					Text: common.ToolResultText(part.ToolResult),
+				})
+			case interfaces.PonchoContentTypeReasoning:
+				// Thinking output is not sent back to the model
 			case interfaces.PonchoContentTypeMedia:
 				imageURL, err := m.convertMediaToImageURL(part.Media)
 				if err != nil {
@@ -448,8 +529,22 @@ func (m *ZAIModel) convertMessage(msg *interfaces.PonchoMessage) (ZAIMessage, er
 		// Convert to simple string content
 		var content string
 		for _, part := range msg.Content {
-			if part.Type == interfaces.PonchoContentTypeText {
+			switch part.Type {
+			case interfaces.PonchoContentTypeText:
 				content += part.Text
+			case interfaces.PonchoContentTypeToolResult:
+				content += common.ToolResultText(part.ToolResult)
+			case interfaces.PonchoContentTypeTool:
+				if part.Tool != nil {
+					zaiMsg.ToolCalls = append(zaiMsg.ToolCalls, ZAIToolCall{
+						ID:   part.Tool.ID,
+						Type: "function",
+						Function: ZAIFunctionCall{
+							Name:      part.Tool.Name,
+							Arguments: m.mapToJSONString(part.Tool.Args),
+						},
+					})
+				}
 			}
 		}
 		zaiMsg.Content = content
@@ -496,6 +591,7 @@ func (m *ZAIModel) convertResponse(zaiResp *ZAIResponse) (*interfaces.PonchoMode
 
 		// Convert finish reason
 		resp.FinishReason = common.ToPonchoFinishReason(common.FinishReason(choice.FinishReason))
+		resp.LogProbs = convertLogProbs(choice.LogProbs)
 	}
 
 	// Convert usage
@@ -541,6 +637,14 @@ func (m *ZAIModel) convertZAIMessage(zaiMsg *ZAIMessage) (*interfaces.PonchoMess
 		}
 	}
 
+	// Convert thinking output after the content so the answer stays the first part
+	if zaiMsg.ReasoningContent != "" {
+		ponchoMsg.Content = append(ponchoMsg.Content, &interfaces.PonchoContentPart{
+			Type: interfaces.PonchoContentTypeReasoning,
+			Text: zaiMsg.ReasoningContent,
+		})
+	}
+
 	// Convert tool calls
 	for _, toolCall := range zaiMsg.ToolCalls {
 		args := make(map[string]interface{})
@@ -625,7 +729,13 @@ func (m *ZAIModel) convertStreamChunk(streamResp *ZAIStreamResponse) (*interface
 			Content: make([]*interfaces.PonchoContentPart, 0),
 		}
 
-		// Convert content delta
+		// Convert reasoning and content deltas
+		if choice.Delta.ReasoningContent != "" {
+			chunk.Delta.Content = append(chunk.Delta.Content, &interfaces.PonchoContentPart{
+				Type: interfaces.PonchoContentTypeReasoning,
+				Text: choice.Delta.ReasoningContent,
+			})
+		}
 		if choice.Delta.Content != "" {
 			chunk.Delta.Content = append(chunk.Delta.Content, &interfaces.PonchoContentPart{
 				Type: interfaces.PonchoContentTypeText,
@@ -654,6 +764,7 @@ func (m *ZAIModel) convertStreamChunk(streamResp *ZAIStreamResponse) (*interface
 		if choice.FinishReason != nil {
 			chunk.FinishReason = common.ToPonchoFinishReason(common.FinishReason(*choice.FinishReason))
 		}
+		chunk.LogProbs = convertLogProbs(choice.LogProbs)
 	}
 
 	// Convert usage
@@ -668,6 +779,27 @@ func (m *ZAIModel) convertStreamChunk(streamResp *ZAIStreamResponse) (*interface
 	return chunk, nil
 }
 
+// convertLogProbs converts Z.AI logprobs to Poncho logprobs
+func convertLogProbs(logProbs *ZAILogProbs) *interfaces.PonchoLogProbs {
+	if logProbs == nil || len(logProbs.Content) == 0 {
+		return nil
+	}
+
+	converted := &interfaces.PonchoLogProbs{Content: make([]interfaces.PonchoTokenLogProb, 0, len(logProbs.Content))}
+	for _, token := range logProbs.Content {
+		tokenLogProb := interfaces.PonchoTokenLogProb{Token: token.Token, LogProb: token.LogProb, Bytes: token.Bytes}
+		for _, top := range token.TopLogProbs {
+			tokenLogProb.TopLogProbs = append(tokenLogProb.TopLogProbs, interfaces.PonchoTopLogProb{
+				Token:   top.Token,
+				LogProb: top.LogProb,
+				Bytes:   top.Bytes,
+			})
+		}
+		converted.Content = append(converted.Content, tokenLogProb)
+	}
+	return converted
+}
+
 // generateRequestID generates a unique request ID
 func (m *ZAIModel) generateRequestID() string {
 	return fmt.Sprintf("zai_%d", time.Now().UnixNano())
@@ -721,7 +853,7 @@ func (m *ZAIModel) createChatCompletion(ctx context.Context, req *ZAIRequest) (*
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, common.ErrorFromHTTPStatus(resp.StatusCode, string(common.ProviderZAI), req.Model)
+		return nil, common.ErrorFromHTTPResponse(resp, string(common.ProviderZAI), req.Model)
 	}
 
 	// Parse response
@@ -771,7 +903,7 @@ func (m *ZAIModel) createChatCompletionStream(ctx context.Context, req *ZAIReque
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return common.ErrorFromHTTPStatus(resp.StatusCode, string(common.ProviderZAI), req.Model)
+		return common.ErrorFromHTTPResponse(resp, string(common.ProviderZAI), req.Model)
 	}
 
 	// Process stream
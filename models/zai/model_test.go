@@ -2,6 +2,9 @@ package zai
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -300,6 +303,42 @@ func TestZAIModel_GenerateStreaming(t *testing.T) {
 	assert.NotEqual(t, interfaces.PonchoFinishReasonStop, lastChunk.FinishReason)
 }
 
+func TestZAIModel_StreamToolCallsWithoutFinishReason(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c1","model":"glm-4.6","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_article","arguments":"{\"id\": 42}"}}]}}]}`+"\n\n")
+	}))
+	defer server.Close()
+
+	model := NewZAIModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{
+		"api_key":  "test-key",
+		"base_url": server.URL,
+	}))
+	defer model.Shutdown(context.Background())
+
+	var chunks []*interfaces.PonchoStreamChunk
+	err := model.GenerateStreaming(context.Background(), &interfaces.PonchoModelRequest{
+		Model: "glm-4.6",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Артикул 42?"}},
+		}},
+	}, func(chunk *interfaces.PonchoStreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The stream hit EOF without a finish reason; the call is still sent
+	final := chunks[len(chunks)-1]
+	assert.True(t, final.Done)
+	assert.Equal(t, interfaces.PonchoFinishReasonTool, final.FinishReason)
+	require.Len(t, final.Delta.Content, 1)
+	assert.Equal(t, "get_article", final.Delta.Content[0].Tool.Name)
+	assert.Equal(t, float64(42), final.Delta.Content[0].Tool.Args["id"])
+}
+
 func TestZAIModel_ValidateRequest(t *testing.T) {
 	model := NewZAIModel()
 
@@ -438,3 +477,84 @@ func TestZAIModel_convertConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestZAIModel_ToolResultAndReasoning(t *testing.T) {
+	model := NewZAIModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{"api_key": "test-key"}))
+
+	toolMsg, err := model.convertMessage(&interfaces.PonchoMessage{
+		Role: interfaces.PonchoRoleTool,
+		Content: []*interfaces.PonchoContentPart{{
+			Type:       interfaces.PonchoContentTypeToolResult,
+			ToolResult: &interfaces.PonchoToolResultPart{ToolCallID: "call_1", Content: "not found", IsError: true},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "call_1", toolMsg.ToolCallID)
+	assert.Equal(t, `{"error":"not found"}`, toolMsg.Content)
+
+	assistantMsg, err := model.convertMessage(&interfaces.PonchoMessage{
+		Role: interfaces.PonchoRoleAssistant,
+		Content: []*interfaces.PonchoContentPart{
+			{Type: interfaces.PonchoContentTypeReasoning, Text: "Let me check."},
+			{Type: interfaces.PonchoContentTypeText, Text: "Checking."},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Checking.", assistantMsg.Content)
+
+	ponchoMsg, err := model.convertZAIMessage(&ZAIMessage{Role: "assistant", Content: "Done.", ReasoningContent: "Looked it up."})
+	require.NoError(t, err)
+	require.Len(t, ponchoMsg.Content, 2)
+	assert.Equal(t, "Done.", ponchoMsg.Content[0].Text)
+	assert.Equal(t, interfaces.PonchoContentTypeReasoning, ponchoMsg.Content[1].Type)
+	assert.Equal(t, "Looked it up.", ponchoMsg.Content[1].Text)
+}
+
+func TestZAIModel_RateLimitHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit-Requests", "300")
+		w.Header().Set("X-Ratelimit-Remaining-Requests", "42")
+		w.Header().Set("X-Ratelimit-Reset-Requests", "20s")
+		w.Header().Set("X-Ratelimit-Limit-Tokens", "90000")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"glm-4.6","choices":[{"index":0,"message":{"role":"assistant","content":"Готово"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`))
+	}))
+	defer server.Close()
+
+	model := NewZAIModel()
+	require.NoError(t, model.Initialize(context.Background(), map[string]interface{}{
+		"api_key":       "test-key",
+		"base_url":      server.URL,
+		"custom_params": map[string]interface{}{"tokens_per_minute": 0},
+	}))
+
+	info := model.client.GetRateLimitInfo()
+	assert.Equal(t, 0, info.RequestsPerMinute)
+	assert.Equal(t, 0, info.TokensPerMinute, "a zero limit disables token limiting")
+
+	_, err := model.Generate(context.Background(), &interfaces.PonchoModelRequest{
+		Model: "glm-4.6",
+		Messages: []*interfaces.PonchoMessage{{
+			Role:    interfaces.PonchoRoleUser,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "Привет"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	// The reported requests limit applies, the configured 0 keeps tokens unlimited
+	info = model.client.GetRateLimitInfo()
+	assert.Equal(t, 300, info.RequestsPerMinute)
+	assert.Equal(t, 0, info.TokensPerMinute)
+	require.NotNil(t, info.RemainingRequests)
+	assert.Equal(t, 42, *info.RemainingRequests)
+
+	require.NoError(t, model.Shutdown(context.Background()))
+	assert.NotContains(t, common.RateLimiters(), common.RateLimiterName(common.ProviderZAI, server.URL, "test-key"))
+
+	_, err = model.convertConfig(map[string]interface{}{
+		"api_key":       "test-key",
+		"custom_params": map[string]interface{}{"requests_per_minute": "many"},
+	})
+	assert.Error(t, err)
+}
@@ -211,3 +211,17 @@ func TestProcessSSEStream_Integration(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestToolCallAccumulator(t *testing.T) {
+	index := 0
+	acc := newToolCallAccumulator()
+	acc.add([]ZAIToolCall{{Index: &index, ID: "call_1", Type: "function", Function: ZAIFunctionCall{Name: "search", Arguments: `{"query": `}}})
+	acc.add([]ZAIToolCall{{Index: &index, Function: ZAIFunctionCall{Arguments: `"red dress"}`}}})
+
+	parts := acc.flush()
+	if assert.Len(t, parts, 1) {
+		assert.Equal(t, "call_1", parts[0].Tool.ID)
+		assert.Equal(t, "search", parts[0].Tool.Name)
+		assert.Equal(t, "red dress", parts[0].Tool.Args["query"])
+	}
+}
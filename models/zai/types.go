@@ -38,10 +38,12 @@ import "time"
 
 // ZAIMessage represents a message in Z.AI API format
 type ZAIMessage struct {
-	Role      string        `json:"role"`
-	Content   interface{}   `json:"content"` // Can be string or []ZAIContentPart
-	Name      *string       `json:"name,omitempty"`
-	ToolCalls []ZAIToolCall `json:"tool_calls,omitempty"`
+	Role             string        `json:"role"`
+	Content          interface{}   `json:"content"` // Can be string or []ZAIContentPart
+	Name             *string       `json:"name,omitempty"`
+	ToolCalls        []ZAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string        `json:"tool_call_id,omitempty"`      // role "tool" only
+	ReasoningContent string        `json:"reasoning_content,omitempty"` // thinking output, responses only
 }
 
 // ZAIContentPart represents a content part in Z.AI API format
@@ -67,6 +69,7 @@ type ZAIMedia struct {
 
 // ZAIToolCall represents a tool call in Z.AI API format
 type ZAIToolCall struct {
+	Index    *int            `json:"index,omitempty"` // stream deltas only
 	ID       string          `json:"id"`
 	Type     string          `json:"type"` // always "function"
 	Function ZAIFunctionCall `json:"function"`
@@ -105,6 +108,7 @@ type ZAIRequest struct {
 	PresencePenalty  *float32           `json:"presence_penalty,omitempty"`
 	Stop             interface{}        `json:"stop,omitempty"` // string or []string
 	ResponseFormat   *ZAIResponseFormat `json:"response_format,omitempty"`
+	Thinking         *ZAIThinking       `json:"thinking,omitempty"`
 	LogProbs         bool               `json:"logprobs,omitempty"`
 	TopLogProbs      *int               `json:"top_logprobs,omitempty"`
 }
@@ -114,6 +118,11 @@ type ZAIResponseFormat struct {
 	Type string `json:"type"` // "text" or "json_object"
 }
 
+// ZAIThinking represents thinking mode configuration
+type ZAIThinking struct {
+	Type string `json:"type"` // "enabled" or "disabled"
+}
+
 // ZAIChoice represents a choice in Z.AI API response
 type ZAIChoice struct {
 	Index        int          `json:"index"`
@@ -169,9 +178,10 @@ type ZAIStreamChoice struct {
 
 // ZAIStreamDelta represents a delta in streaming response
 type ZAIStreamDelta struct {
-	Role      string        `json:"role,omitempty"`
-	Content   string        `json:"content,omitempty"`
-	ToolCalls []ZAIToolCall `json:"tool_calls,omitempty"`
+	Role             string        `json:"role,omitempty"`
+	Content          string        `json:"content,omitempty"`
+	ToolCalls        []ZAIToolCall `json:"tool_calls,omitempty"`
+	ReasoningContent string        `json:"reasoning_content,omitempty"`
 }
 
 // ZAIStreamResponse represents a streaming response from Z.AI API
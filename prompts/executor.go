@@ -197,6 +197,17 @@ func (pe *PromptExecutorImpl) BuildModelRequest(
 		request.Temperature = &pe.config.Execution.DefaultTemperature
 	}
 
+	// Generation parameters left unset fall back to the model's defaults
+	request.TopP = template.TopP
+	request.Stop = template.Stop
+	request.Seed = template.Seed
+	request.FrequencyPenalty = template.FrequencyPenalty
+	request.PresencePenalty = template.PresencePenalty
+	request.ResponseFormat = template.ResponseFormat
+	request.Thinking = template.Thinking
+	request.LogProbs = template.LogProbs
+	request.TopLogProbs = template.TopLogProbs
+
 	// Add fashion context if available
 	if template.FashionContext != nil {
 		request.Metadata["fashion_context"] = template.FashionContext
@@ -299,6 +299,8 @@ func (p *V1Parser) parseConfigValues(configStr string, template *interfaces.Prom
 					template.Temperature = &tempFloat
 				}
 			}
+
+			p.applyGenerationParams(configSection, template)
 		}
 		return
 	}
@@ -321,8 +323,113 @@ func (p *V1Parser) parseConfigValues(configStr string, template *interfaces.Prom
 			if val := p.extractFloatValue(line); val != nil {
 				template.Temperature = val
 			}
+		} else {
+			var values map[string]interface{}
+			if err := yaml.Unmarshal([]byte(line), &values); err == nil {
+				p.applyGenerationParams(values, template)
+			}
+		}
+	}
+}
+
+// applyGenerationParams extracts generation parameters from config values. Keys
+// are accepted in snake_case and in the camelCase of Gemini-style configs;
+// values of the wrong type are ignored.
+func (p *V1Parser) applyGenerationParams(values map[string]interface{}, template *interfaces.PromptTemplate) {
+	lookup := func(keys ...string) (interface{}, bool) {
+		for _, key := range keys {
+			if value, ok := values[key]; ok {
+				return value, true
+			}
+		}
+		return nil, false
+	}
+
+	for keys, target := range map[[2]string]**float32{
+		{"top_p", "topP"}:                         &template.TopP,
+		{"frequency_penalty", "frequencyPenalty"}: &template.FrequencyPenalty,
+		{"presence_penalty", "presencePenalty"}:   &template.PresencePenalty,
+	} {
+		if value, ok := lookup(keys[0], keys[1]); ok {
+			if number, ok := configFloat(value); ok {
+				*target = &number
+			}
+		}
+	}
+
+	for keys, target := range map[[2]string]**int{
+		{"seed", "seed"}:                &template.Seed,
+		{"top_logprobs", "topLogprobs"}: &template.TopLogProbs,
+	} {
+		if value, ok := lookup(keys[0], keys[1]); ok {
+			if number, ok := value.(int); ok {
+				*target = &number
+			}
+		}
+	}
+
+	if value, ok := lookup("stop", "stopSequences"); ok {
+		switch stop := value.(type) {
+		case string:
+			template.Stop = []string{stop}
+		case []interface{}:
+			template.Stop = nil
+			for _, item := range stop {
+				if sequence, ok := item.(string); ok {
+					template.Stop = append(template.Stop, sequence)
+				}
+			}
+		}
+	}
+
+	if value, ok := lookup("response_format", "responseFormat"); ok {
+		if formatMap, isMap := value.(map[string]interface{}); isMap {
+			value = formatMap["type"]
+		}
+		switch format := interfaces.PonchoResponseFormat(fmt.Sprint(value)); format {
+		case interfaces.PonchoResponseFormatText, interfaces.PonchoResponseFormatJSONObject:
+			template.ResponseFormat = format
+		}
+	} else if mimeType, ok := values["responseMimeType"].(string); ok {
+		if mimeType == "application/json" {
+			template.ResponseFormat = interfaces.PonchoResponseFormatJSONObject
+		} else {
+			template.ResponseFormat = interfaces.PonchoResponseFormatText
+		}
+	}
+
+	if value, ok := lookup("thinking"); ok {
+		if thinkingMap, isMap := value.(map[string]interface{}); isMap {
+			value = thinkingMap["type"]
+		}
+		switch thinking := value.(type) {
+		case bool:
+			template.Thinking = &thinking
+		case string:
+			if thinking == "enabled" || thinking == "disabled" {
+				enabled := thinking == "enabled"
+				template.Thinking = &enabled
+			}
 		}
 	}
+
+	if value, ok := lookup("logprobs", "logProbs"); ok {
+		if logProbs, ok := value.(bool); ok {
+			template.LogProbs = &logProbs
+		}
+	}
+}
+
+// configFloat converts a numeric YAML value to float32
+func configFloat(value interface{}) (float32, bool) {
+	switch v := value.(type) {
+	case float64:
+		return float32(v), true
+	case int:
+		return float32(v), true
+	default:
+		return 0, false
+	}
 }
 
 // extractIntValue extracts integer value from config line
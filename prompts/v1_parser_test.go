@@ -200,7 +200,7 @@ func TestV1Parser_ExtractMediaVars(t *testing.T) {
 func TestV1Parser_Integration(t *testing.T) {
 	// Integration test with the actual sketch_description.prompt file
 	parser := NewV1Parser()
-	
+
 	// Test parsing and conversion back to template
 	content := `{{role "config"}}
 model: zai-vision/glm-4.6v-flash
@@ -261,4 +261,51 @@ Analyze this sketch: {{media url=photoUrl}}`
 	} else if userPart.Content != "Analyze this sketch: {{media url=photoUrl}}" {
 		t.Errorf("Expected user content 'Analyze this sketch: {{media url=photoUrl}}', got '%s'", userPart.Content)
 	}
-}
\ No newline at end of file
+}
+
+func TestV1Parser_GenerationParams(t *testing.T) {
+	parser := NewV1Parser()
+
+	content := `{{role "config"}}
+model: zai/glm-4.6
+config:
+  temperature: 0.1
+  topP: 0.9
+  stopSequences: ["END"]
+  responseMimeType: application/json
+  thinking: false
+  seed: 42
+{{role "system"}}
+Отвечай только JSON.
+{{role "user"}}
+Опиши платье`
+
+	data, err := parser.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	template := parser.ToPromptTemplate(data, "dress-json")
+
+	if template.TopP == nil || *template.TopP != 0.9 {
+		t.Errorf("Expected top_p 0.9, got %v", template.TopP)
+	}
+	if len(template.Stop) != 1 || template.Stop[0] != "END" {
+		t.Errorf("Expected stop [END], got %v", template.Stop)
+	}
+	if template.ResponseFormat != interfaces.PonchoResponseFormatJSONObject {
+		t.Errorf("Expected json_object response format, got %q", template.ResponseFormat)
+	}
+	if template.Thinking == nil || *template.Thinking {
+		t.Errorf("Expected thinking disabled, got %v", template.Thinking)
+	}
+
+	// The settings reach the model request
+	executor := NewPromptExecutor(nil, &PromptConfig{}, interfaces.NewNoOpLogger()).(*PromptExecutorImpl)
+	request, err := executor.BuildModelRequest(template, nil, "glm")
+	if err != nil {
+		t.Fatalf("BuildModelRequest failed: %v", err)
+	}
+	if request.ResponseFormat != interfaces.PonchoResponseFormatJSONObject || request.Seed == nil || *request.Seed != 42 || request.TopP != template.TopP {
+		t.Errorf("Expected generation parameters on the request, got %+v", request)
+	}
+}
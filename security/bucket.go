@@ -0,0 +1,57 @@
+package security
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a thread-safe token bucket rate limiter.
+// Unlike the blocking RateLimiter of the wildberries client, it never waits:
+// Allow either takes a token or reports how long until one is available.
+type TokenBucket struct {
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+// NewTokenBucket creates a bucket refilled at requestsPerMinute.
+// burst is the bucket size; if it is not positive, requestsPerMinute is used.
+func NewTokenBucket(requestsPerMinute, burst int) *TokenBucket {
+	if burst <= 0 {
+		burst = requestsPerMinute
+	}
+
+	return &TokenBucket{
+		capacity: float64(burst),
+		rate:     float64(requestsPerMinute) / 60,
+		tokens:   float64(burst),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Allow takes a token if one is available.
+// Otherwise it returns false and the time until the next token.
+func (b *TokenBucket) Allow() (bool, time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if now := b.now(); now.After(b.last) {
+		b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if b.rate <= 0 {
+		return false, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
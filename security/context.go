@@ -0,0 +1,41 @@
+package security
+
+import "context"
+
+type (
+	apiKeyContextKey        struct{}
+	clientAddrContextKey    struct{}
+	authenticatedContextKey struct{}
+)
+
+// WithAPIKey returns a context carrying the caller's API key
+func WithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the caller's API key, or "" if none is set
+func APIKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyContextKey{}).(string)
+	return key
+}
+
+// WithClientAddr returns a context carrying the caller's network address
+func WithClientAddr(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, clientAddrContextKey{}, addr)
+}
+
+// ClientAddrFromContext returns the caller's network address, or "" if none is set
+func ClientAddrFromContext(ctx context.Context) string {
+	addr, _ := ctx.Value(clientAddrContextKey{}).(string)
+	return addr
+}
+
+// Authenticated reports whether the caller in ctx passed Guard.Authenticate
+func Authenticated(ctx context.Context) bool {
+	authenticated, _ := ctx.Value(authenticatedContextKey{}).(bool)
+	return authenticated
+}
+
+func withAuthenticated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authenticatedContextKey{}, true)
+}
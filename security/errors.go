@@ -0,0 +1,56 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrorCode identifies why a call was rejected
+type ErrorCode string
+
+// Error codes returned by the Guard
+const (
+	ErrorCodeMissingAPIKey     ErrorCode = "MISSING_API_KEY"
+	ErrorCodeInvalidAPIKey     ErrorCode = "INVALID_API_KEY"
+	ErrorCodeRateLimitExceeded ErrorCode = "RATE_LIMIT_EXCEEDED"
+)
+
+// Rate limit scopes
+const (
+	ScopeKey   = "key"
+	ScopeModel = "model"
+)
+
+// Error is returned when a call is rejected by the Guard
+type Error struct {
+	Code    ErrorCode
+	Message string
+	// Scope is the rate limit that rejected the call (ScopeKey or ScopeModel)
+	Scope string
+	// RetryAfter is how long to wait before the rate limit admits the call
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s: %s (retry after %s)", e.Code, e.Message, e.RetryAfter.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// IsAuthError reports whether err is an authentication failure
+func IsAuthError(err error) bool {
+	var secErr *Error
+	if !errors.As(err, &secErr) {
+		return false
+	}
+	return secErr.Code == ErrorCodeMissingAPIKey || secErr.Code == ErrorCodeInvalidAPIKey
+}
+
+// IsRateLimitError reports whether err is a rate limit rejection
+func IsRateLimitError(err error) bool {
+	var secErr *Error
+	return errors.As(err, &secErr) && secErr.Code == ErrorCodeRateLimitExceeded
+}
@@ -0,0 +1,288 @@
+// Package security enforces SecurityConfig in front of a PonchoFramework
+//
+// Guard wraps a framework and checks every Generate, GenerateStreaming,
+// ExecuteTool, ExecuteFlow and ExecuteFlowStreaming call:
+//  1. Authentication: the API key carried in the context (see WithAPIKey) must be
+//     one of SecurityConfig.APIKeys. With no keys configured, authentication is off.
+//  2. Per-key rate limit: RateLimitConfig.RequestsPerMinute, a token bucket per key.
+//  3. Per-model rate limit: RateLimitConfig.ModelLimits, a token bucket per model
+//     shared by all keys (Generate calls only).
+//
+// Rejections are returned as *Error and recorded in the wrapped framework's
+// ErrorMetrics when it implements ErrorRecorder. The HTTP server puts the key from
+// the request headers into the context, in-process callers use WithAPIKey.
+//
+// Callers without a key are rate limited by the client address in the context
+// (see WithClientAddr).
+//
+// Only the call methods are guarded; registries returned by the framework are not.
+// The HTTP server therefore calls Authenticate for every request before handling
+// it, so unknown callers learn nothing about registered models, tools or flows.
+package security
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"sync"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/metrics"
+)
+
+// ErrorComponent is the component name of rejections recorded in ErrorMetrics
+const ErrorComponent = "security"
+
+// Authentication failure reasons used as metric labels
+const (
+	reasonMissing = "missing"
+	reasonInvalid = "invalid"
+)
+
+// ErrorRecorder records errors in framework ErrorMetrics (implemented by PonchoFrameworkImpl)
+type ErrorRecorder interface {
+	RecordError(component, errorType string)
+}
+
+// Guard authenticates and rate limits calls to a PonchoFramework
+type Guard struct {
+	interfaces.PonchoFramework
+
+	keys        [][sha256.Size]byte
+	keyLimit    int
+	keyBurst    int
+	modelLimits map[string]int
+	recorder    ErrorRecorder
+	logger      interfaces.Logger
+
+	keyBuckets   map[string]*TokenBucket
+	modelBuckets map[string]*TokenBucket
+	rejected     map[string]int64
+	authFailures map[string]int64
+	mutex        sync.Mutex
+}
+
+// NewGuard wraps a framework with the given security configuration
+func NewGuard(framework interfaces.PonchoFramework, config *interfaces.SecurityConfig, logger interfaces.Logger) (*Guard, error) {
+	if framework == nil {
+		return nil, fmt.Errorf("framework cannot be nil")
+	}
+	if config == nil {
+		config = &interfaces.SecurityConfig{}
+	}
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	g := &Guard{
+		PonchoFramework: framework,
+		modelLimits:     make(map[string]int),
+		logger:          logger,
+		keyBuckets:      make(map[string]*TokenBucket),
+		modelBuckets:    make(map[string]*TokenBucket),
+		rejected:        make(map[string]int64),
+		authFailures:    make(map[string]int64),
+	}
+
+	for i, key := range config.APIKeys {
+		if key == "" {
+			return nil, fmt.Errorf("api key %d is empty", i)
+		}
+		g.keys = append(g.keys, sha256.Sum256([]byte(key)))
+	}
+
+	if rl := config.RateLimiting; rl != nil {
+		if rl.RequestsPerMinute < 0 || rl.Burst < 0 {
+			return nil, fmt.Errorf("rate limits cannot be negative")
+		}
+		g.keyLimit = rl.RequestsPerMinute
+		g.keyBurst = rl.Burst
+
+		for model, limit := range rl.ModelLimits {
+			if limit < 0 {
+				return nil, fmt.Errorf("rate limit for model '%s' cannot be negative", model)
+			}
+			g.modelLimits[model] = limit
+		}
+	}
+
+	if recorder, ok := framework.(ErrorRecorder); ok {
+		g.recorder = recorder
+	}
+
+	logger.Info("Security guard created",
+		"api_keys", len(g.keys),
+		"requests_per_minute", g.keyLimit,
+		"model_limits", len(g.modelLimits))
+
+	return g, nil
+}
+
+// AuthEnabled reports whether calls must carry a valid API key
+func (g *Guard) AuthEnabled() bool {
+	return len(g.keys) > 0
+}
+
+// Authenticate authenticates the caller in ctx and takes a token from its per-key
+// rate limit. The returned context marks the caller as checked, so calls made with
+// it only take a token from the per-model limit.
+func (g *Guard) Authenticate(ctx context.Context) (context.Context, error) {
+	key := APIKeyFromContext(ctx)
+
+	if g.AuthEnabled() {
+		if key == "" {
+			return ctx, g.reject(&Error{Code: ErrorCodeMissingAPIKey, Message: "API key is required"}, reasonMissing)
+		}
+		if !g.validKey(key) {
+			return ctx, g.reject(&Error{Code: ErrorCodeInvalidAPIKey, Message: "API key is not valid"}, reasonInvalid)
+		}
+	} else if key == "" {
+		// Anonymous callers do not share one bucket: limit them by address
+		key = "addr:" + ClientAddrFromContext(ctx)
+	}
+
+	if g.keyLimit > 0 {
+		if ok, retryAfter := g.bucket(g.keyBuckets, key, g.keyLimit, g.keyBurst).Allow(); !ok {
+			return ctx, g.reject(&Error{
+				Code:       ErrorCodeRateLimitExceeded,
+				Message:    "rate limit exceeded for API key",
+				Scope:      ScopeKey,
+				RetryAfter: retryAfter,
+			}, ScopeKey)
+		}
+	}
+
+	return withAuthenticated(ctx), nil
+}
+
+// Check authenticates the caller in ctx and takes a token from its rate limits.
+// model is empty for tool and flow calls, which skips the per-model limit.
+// Callers already checked by Authenticate are not authenticated again.
+func (g *Guard) Check(ctx context.Context, model string) error {
+	if !Authenticated(ctx) {
+		if _, err := g.Authenticate(ctx); err != nil {
+			return err
+		}
+	}
+
+	if limit := g.modelLimits[model]; model != "" && limit > 0 {
+		if ok, retryAfter := g.bucket(g.modelBuckets, model, limit, 0).Allow(); !ok {
+			return g.reject(&Error{
+				Code:       ErrorCodeRateLimitExceeded,
+				Message:    fmt.Sprintf("rate limit exceeded for model '%s'", model),
+				Scope:      ScopeModel,
+				RetryAfter: retryAfter,
+			}, ScopeModel)
+		}
+	}
+
+	return nil
+}
+
+// Generate implements interfaces.PonchoFramework
+func (g *Guard) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	if err := g.Check(ctx, requestModel(req)); err != nil {
+		return nil, err
+	}
+	return g.PonchoFramework.Generate(ctx, req)
+}
+
+// GenerateStreaming implements interfaces.PonchoFramework
+func (g *Guard) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if err := g.Check(ctx, requestModel(req)); err != nil {
+		return err
+	}
+	return g.PonchoFramework.GenerateStreaming(ctx, req, callback)
+}
+
+// ExecuteTool implements interfaces.PonchoFramework
+func (g *Guard) ExecuteTool(ctx context.Context, toolName string, input interface{}) (interface{}, error) {
+	if err := g.Check(ctx, ""); err != nil {
+		return nil, err
+	}
+	return g.PonchoFramework.ExecuteTool(ctx, toolName, input)
+}
+
+// ExecuteFlow implements interfaces.PonchoFramework
+func (g *Guard) ExecuteFlow(ctx context.Context, flowName string, input interface{}) (interface{}, error) {
+	if err := g.Check(ctx, ""); err != nil {
+		return nil, err
+	}
+	return g.PonchoFramework.ExecuteFlow(ctx, flowName, input)
+}
+
+// ExecuteFlowStreaming implements interfaces.PonchoFramework
+func (g *Guard) ExecuteFlowStreaming(ctx context.Context, flowName string, input interface{}, callback interfaces.PonchoStreamCallback) error {
+	if err := g.Check(ctx, ""); err != nil {
+		return err
+	}
+	return g.PonchoFramework.ExecuteFlowStreaming(ctx, flowName, input, callback)
+}
+
+// Collect implements metrics.Collector
+func (g *Guard) Collect(ctx context.Context, enc *metrics.PrometheusEncoder) error {
+	g.mutex.Lock()
+	rejected := make([]metrics.Sample, 0, len(g.rejected))
+	for scope, count := range g.rejected {
+		rejected = append(rejected, metrics.Sample{Labels: metrics.Labels{"scope": scope}, Value: float64(count)})
+	}
+	authFailures := make([]metrics.Sample, 0, len(g.authFailures))
+	for reason, count := range g.authFailures {
+		authFailures = append(authFailures, metrics.Sample{Labels: metrics.Labels{"reason": reason}, Value: float64(count)})
+	}
+	g.mutex.Unlock()
+
+	enc.Counter("rate_limit_exceeded_total", "Calls rejected by rate limits.", rejected)
+	enc.Counter("auth_failures_total", "Calls rejected by API key authentication.", authFailures)
+	return enc.Err()
+}
+
+// validKey compares key against the configured keys in constant time
+func (g *Guard) validKey(key string) bool {
+	digest := sha256.Sum256([]byte(key))
+
+	valid := 0
+	for _, configured := range g.keys {
+		valid |= subtle.ConstantTimeCompare(digest[:], configured[:])
+	}
+	return valid == 1
+}
+
+// bucket returns the token bucket for name, creating it on first use
+func (g *Guard) bucket(buckets map[string]*TokenBucket, name string, limit, burst int) *TokenBucket {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	b, exists := buckets[name]
+	if !exists {
+		b = NewTokenBucket(limit, burst)
+		buckets[name] = b
+	}
+	return b
+}
+
+// reject counts a rejection, records it in ErrorMetrics and returns err
+func (g *Guard) reject(err *Error, label string) error {
+	g.mutex.Lock()
+	if err.Code == ErrorCodeRateLimitExceeded {
+		g.rejected[label]++
+	} else {
+		g.authFailures[label]++
+	}
+	g.mutex.Unlock()
+
+	if g.recorder != nil {
+		g.recorder.RecordError(ErrorComponent, string(err.Code))
+	}
+
+	g.logger.Warn("Call rejected", "code", err.Code, "scope", err.Scope)
+	return err
+}
+
+func requestModel(req *interfaces.PonchoModelRequest) string {
+	if req == nil {
+		return ""
+	}
+	return req.Model
+}
@@ -0,0 +1,119 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/metrics"
+)
+
+// stubFramework counts the calls that reach it and records errors
+type stubFramework struct {
+	interfaces.PonchoFramework
+	calls  int
+	errors []string
+}
+
+func (f *stubFramework) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	f.calls++
+	return &interfaces.PonchoModelResponse{}, nil
+}
+
+func (f *stubFramework) ExecuteTool(ctx context.Context, toolName string, input interface{}) (interface{}, error) {
+	f.calls++
+	return nil, nil
+}
+
+func (f *stubFramework) RecordError(component, errorType string) {
+	f.errors = append(f.errors, component+":"+errorType)
+}
+
+func newTestGuard(t *testing.T, config *interfaces.SecurityConfig) (*Guard, *stubFramework) {
+	t.Helper()
+
+	framework := &stubFramework{}
+	guard, err := NewGuard(framework, config, interfaces.NewNoOpLogger())
+	require.NoError(t, err)
+	return guard, framework
+}
+
+func TestGuard_Authentication(t *testing.T) {
+	guard, framework := newTestGuard(t, &interfaces.SecurityConfig{APIKeys: []string{"key-a", "key-b"}})
+	ctx := context.Background()
+
+	_, err := guard.Generate(ctx, &interfaces.PonchoModelRequest{Model: "chat"})
+	assert.True(t, IsAuthError(err))
+	assert.Equal(t, ErrorCodeMissingAPIKey, err.(*Error).Code)
+
+	_, err = guard.ExecuteTool(WithAPIKey(ctx, "key-c"), "search", nil)
+	assert.Equal(t, ErrorCodeInvalidAPIKey, err.(*Error).Code)
+
+	_, err = guard.Generate(WithAPIKey(ctx, "key-b"), &interfaces.PonchoModelRequest{Model: "chat"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, framework.calls)
+	assert.Equal(t, []string{"security:MISSING_API_KEY", "security:INVALID_API_KEY"}, framework.errors)
+}
+
+func TestGuard_RateLimits(t *testing.T) {
+	guard, framework := newTestGuard(t, &interfaces.SecurityConfig{
+		RateLimiting: &interfaces.RateLimitConfig{
+			RequestsPerMinute: 2,
+			ModelLimits:       map[string]int{"vision": 1},
+		},
+	})
+	ctx := context.Background()
+	keyA, keyB := WithAPIKey(ctx, "key-a"), WithAPIKey(ctx, "key-b")
+
+	// Auth is off without configured keys, limits still apply per key
+	_, err := guard.Generate(keyA, &interfaces.PonchoModelRequest{Model: "vision"})
+	require.NoError(t, err)
+
+	_, err = guard.Generate(keyB, &interfaces.PonchoModelRequest{Model: "vision"})
+	require.True(t, IsRateLimitError(err))
+	assert.Equal(t, ScopeModel, err.(*Error).Scope)
+
+	_, err = guard.Generate(keyA, &interfaces.PonchoModelRequest{Model: "chat"})
+	require.NoError(t, err)
+
+	_, err = guard.ExecuteTool(keyA, "search", nil)
+	require.True(t, IsRateLimitError(err))
+	assert.Equal(t, ScopeKey, err.(*Error).Scope)
+	assert.InDelta(t, 30*time.Second, err.(*Error).RetryAfter, float64(time.Second))
+
+	_, err = guard.ExecuteTool(keyB, "search", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, framework.calls)
+
+	var buf bytes.Buffer
+	require.NoError(t, guard.Collect(ctx, metrics.NewPrometheusEncoder(&buf)))
+	assert.Contains(t, buf.String(), `rate_limit_exceeded_total{scope="key"} 1`)
+	assert.Contains(t, buf.String(), `rate_limit_exceeded_total{scope="model"} 1`)
+}
+
+func TestTokenBucket_Refill(t *testing.T) {
+	now := time.Now()
+	bucket := NewTokenBucket(60, 2)
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+
+	for i := 0; i < 2; i++ {
+		ok, _ := bucket.Allow()
+		require.True(t, ok)
+	}
+
+	ok, retryAfter := bucket.Allow()
+	assert.False(t, ok)
+	assert.InDelta(t, time.Second, retryAfter, float64(time.Millisecond))
+
+	now = now.Add(time.Second)
+	ok, _ = bucket.Allow()
+	assert.True(t, ok)
+}
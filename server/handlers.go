@@ -0,0 +1,510 @@
+package server
+
+// This file contains the HTTP handlers of the API server.
+// Handlers decode JSON requests, delegate to the PonchoFramework and encode
+// responses. Streaming endpoints write PonchoStreamChunk values as Server-Sent
+// Events and terminate the stream with a "[DONE]" data line, mirroring the
+// OpenAI-compatible format already consumed by the model providers.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/security"
+)
+
+// ExecuteRequest is the request body for tool and flow execution
+type ExecuteRequest struct {
+	Input interface{} `json:"input"`
+}
+
+// ExecuteResponse is the response body for tool and flow execution
+type ExecuteResponse struct {
+	Name   string      `json:"name"`
+	Result interface{} `json:"result"`
+}
+
+// ErrorResponse is the response body returned for failed requests
+type ErrorResponse struct {
+	Error *ErrorBody `json:"error"`
+}
+
+// ErrorBody describes an API error
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ModelInfo describes a registered model
+type ModelInfo struct {
+	Name               string                        `json:"name"`
+	Provider           string                        `json:"provider"`
+	MaxTokens          int                           `json:"max_tokens"`
+	DefaultTemperature float32                       `json:"default_temperature"`
+	Supports           *interfaces.ModelCapabilities `json:"supports"`
+}
+
+// ToolInfo describes a registered tool
+type ToolInfo struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Version      string                 `json:"version"`
+	Category     string                 `json:"category"`
+	Tags         []string               `json:"tags,omitempty"`
+	Dependencies []string               `json:"dependencies,omitempty"`
+	InputSchema  map[string]interface{} `json:"input_schema,omitempty"`
+	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+}
+
+// FlowInfo describes a registered flow
+type FlowInfo struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Version      string                 `json:"version"`
+	Category     string                 `json:"category"`
+	Tags         []string               `json:"tags,omitempty"`
+	Dependencies []string               `json:"dependencies,omitempty"`
+	InputSchema  map[string]interface{} `json:"input_schema,omitempty"`
+	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+}
+
+// handleHealth reports framework health; unhealthy frameworks answer with 503
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	health, err := s.framework.Health(r.Context())
+	if err != nil {
+		s.logger.Error("Health check failed", "error", err)
+		writeError(w, http.StatusServiceUnavailable, string(common.ErrorCodeServiceUnavailable), err.Error())
+		return
+	}
+
+	status := http.StatusOK
+	if health.Status == "unhealthy" {
+		status = http.StatusServiceUnavailable
+	}
+
+	// Probes without a key learn the status, not which components are registered
+	if _, guarded := s.framework.(authenticator); guarded && !security.Authenticated(r.Context()) {
+		writeJSON(w, status, map[string]interface{}{"status": health.Status})
+		return
+	}
+
+	writeJSON(w, status, health)
+}
+
+// handleListModels lists registered models
+func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
+	registry := s.framework.GetModelRegistry()
+	names := registry.List()
+	sort.Strings(names)
+
+	models := make([]*ModelInfo, 0, len(names))
+	for _, name := range names {
+		model, err := registry.Get(name)
+		if err != nil {
+			continue
+		}
+
+		models = append(models, &ModelInfo{
+			Name:               name,
+			Provider:           model.Provider(),
+			MaxTokens:          model.MaxTokens(),
+			DefaultTemperature: model.DefaultTemperature(),
+			Supports: &interfaces.ModelCapabilities{
+				Streaming: model.SupportsStreaming(),
+				Tools:     model.SupportsTools(),
+				Vision:    model.SupportsVision(),
+				System:    model.SupportsSystemRole(),
+			},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"models": models})
+}
+
+// handleListTools lists registered tools, optionally filtered by ?category=
+func (s *Server) handleListTools(w http.ResponseWriter, r *http.Request) {
+	registry := s.framework.GetToolRegistry()
+
+	var names []string
+	if category := r.URL.Query().Get("category"); category != "" {
+		names = registry.ListByCategory(category)
+	} else {
+		names = registry.List()
+	}
+	sort.Strings(names)
+
+	tools := make([]*ToolInfo, 0, len(names))
+	for _, name := range names {
+		tool, err := registry.Get(name)
+		if err != nil {
+			continue
+		}
+
+		tools = append(tools, &ToolInfo{
+			Name:         name,
+			Description:  tool.Description(),
+			Version:      tool.Version(),
+			Category:     tool.Category(),
+			Tags:         tool.Tags(),
+			Dependencies: tool.Dependencies(),
+			InputSchema:  tool.InputSchema(),
+			OutputSchema: tool.OutputSchema(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tools": tools})
+}
+
+// handleListFlows lists registered flows, optionally filtered by ?category=
+func (s *Server) handleListFlows(w http.ResponseWriter, r *http.Request) {
+	registry := s.framework.GetFlowRegistry()
+
+	var names []string
+	if category := r.URL.Query().Get("category"); category != "" {
+		names = registry.ListByCategory(category)
+	} else {
+		names = registry.List()
+	}
+	sort.Strings(names)
+
+	flows := make([]*FlowInfo, 0, len(names))
+	for _, name := range names {
+		flow, err := registry.Get(name)
+		if err != nil {
+			continue
+		}
+
+		flows = append(flows, &FlowInfo{
+			Name:         name,
+			Description:  flow.Description(),
+			Version:      flow.Version(),
+			Category:     flow.Category(),
+			Tags:         flow.Tags(),
+			Dependencies: flow.Dependencies(),
+			InputSchema:  flow.InputSchema(),
+			OutputSchema: flow.OutputSchema(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"flows": flows})
+}
+
+// handleGenerate performs a unary generation
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeModelRequest(w, r)
+	if !ok {
+		return
+	}
+	req.Stream = false
+
+	response, err := s.framework.Generate(r.Context(), req)
+	if err != nil {
+		s.writeFrameworkError(w, "generate", req.Model, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleGenerateStreaming performs a streaming generation over SSE
+func (s *Server) handleGenerateStreaming(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeModelRequest(w, r)
+	if !ok {
+		return
+	}
+	req.Stream = true
+
+	s.stream(w, r, "generate_stream", req.Model, func(ctx context.Context, callback interfaces.PonchoStreamCallback) error {
+		return s.framework.GenerateStreaming(ctx, req, callback)
+	})
+}
+
+// handleExecuteTool executes a registered tool
+func (s *Server) handleExecuteTool(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, err := s.framework.GetToolRegistry().Get(name); err != nil {
+		writeError(w, http.StatusNotFound, string(common.ErrorCodeNotFound), err.Error())
+		return
+	}
+
+	execReq, ok := s.decodeExecuteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.framework.ExecuteTool(r.Context(), name, execReq.Input)
+	if err != nil {
+		s.writeFrameworkError(w, "execute_tool", name, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &ExecuteResponse{Name: name, Result: result})
+}
+
+// handleExecuteFlow executes a registered flow
+func (s *Server) handleExecuteFlow(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, err := s.framework.GetFlowRegistry().Get(name); err != nil {
+		writeError(w, http.StatusNotFound, string(common.ErrorCodeNotFound), err.Error())
+		return
+	}
+
+	execReq, ok := s.decodeExecuteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.framework.ExecuteFlow(r.Context(), name, execReq.Input)
+	if err != nil {
+		s.writeFrameworkError(w, "execute_flow", name, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &ExecuteResponse{Name: name, Result: result})
+}
+
+// handleExecuteFlowStreaming executes a registered flow and streams its chunks over SSE
+func (s *Server) handleExecuteFlowStreaming(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, err := s.framework.GetFlowRegistry().Get(name); err != nil {
+		writeError(w, http.StatusNotFound, string(common.ErrorCodeNotFound), err.Error())
+		return
+	}
+
+	execReq, ok := s.decodeExecuteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	s.stream(w, r, "execute_flow_stream", name, func(ctx context.Context, callback interfaces.PonchoStreamCallback) error {
+		return s.framework.ExecuteFlowStreaming(ctx, name, execReq.Input, callback)
+	})
+}
+
+// decodeModelRequest decodes and validates a model request body
+func (s *Server) decodeModelRequest(w http.ResponseWriter, r *http.Request) (*interfaces.PonchoModelRequest, bool) {
+	var req interfaces.PonchoModelRequest
+	if err := s.decodeBody(w, r, &req, false); err != nil {
+		writeError(w, http.StatusBadRequest, string(common.ErrorCodeInvalidRequest), err.Error())
+		return nil, false
+	}
+
+	if req.Model == "" {
+		writeError(w, http.StatusBadRequest, string(common.ErrorCodeInvalidRequest), "model is required")
+		return nil, false
+	}
+
+	if _, err := s.framework.GetModelRegistry().Get(req.Model); err != nil {
+		writeError(w, http.StatusNotFound, string(common.ErrorCodeModelNotFound), err.Error())
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// decodeExecuteRequest decodes a tool/flow execution body; an empty body means nil input
+func (s *Server) decodeExecuteRequest(w http.ResponseWriter, r *http.Request) (*ExecuteRequest, bool) {
+	var req ExecuteRequest
+	if err := s.decodeBody(w, r, &req, true); err != nil {
+		writeError(w, http.StatusBadRequest, string(common.ErrorCodeInvalidRequest), err.Error())
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// decodeBody decodes a size-limited JSON body into target
+func (s *Server) decodeBody(w http.ResponseWriter, r *http.Request, target interface{}, allowEmpty bool) error {
+	body := r.Body
+	if s.config.MaxBodyBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes)
+	}
+
+	if err := json.NewDecoder(body).Decode(target); err != nil {
+		if errors.Is(err, io.EOF) {
+			if allowEmpty {
+				return nil
+			}
+			return fmt.Errorf("request body is empty")
+		}
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	return nil
+}
+
+// stream runs a streaming operation and forwards its chunks as Server-Sent Events.
+// Errors that happen before the first chunk are reported as regular JSON errors,
+// later errors are sent as an "error" event because the status line is already written.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, operation, target string, run func(ctx context.Context, callback interfaces.PonchoStreamCallback) error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, string(common.ErrorCodeStreamError), "streaming is not supported by the connection")
+		return
+	}
+
+	ctx := r.Context()
+	sse := newSSEWriter(w, flusher)
+
+	err := run(ctx, func(chunk *interfaces.PonchoStreamChunk) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return sse.writeData(chunk)
+	})
+
+	if err != nil {
+		if !sse.isStarted() {
+			s.writeFrameworkError(w, operation, target, err)
+			return
+		}
+
+		s.logger.Error("Streaming failed", "operation", operation, "target", target, "error", err)
+		_, code := statusFromError(err)
+		_ = sse.writeEvent("error", &ErrorResponse{Error: &ErrorBody{Code: code, Message: err.Error()}})
+		return
+	}
+
+	_ = sse.writeDone()
+}
+
+// writeFrameworkError logs a framework error and writes it with a matching status code
+func (s *Server) writeFrameworkError(w http.ResponseWriter, operation, target string, err error) {
+	status, code := statusFromError(err)
+	var secErr *security.Error
+	if errors.As(err, &secErr) && secErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(secErr.RetryAfter.Seconds()))))
+	}
+	s.logger.Error("Request failed", "operation", operation, "target", target, "status", status, "error", err)
+	writeError(w, status, code, err.Error())
+}
+
+// statusFromError maps framework errors to HTTP status codes and error codes
+func statusFromError(err error) (int, string) {
+	var secErr *security.Error
+	if errors.As(err, &secErr) {
+		if secErr.Code == security.ErrorCodeRateLimitExceeded {
+			return http.StatusTooManyRequests, string(secErr.Code)
+		}
+		return http.StatusUnauthorized, string(secErr.Code)
+	}
+
+	var modelErr *common.ModelError
+	if errors.As(err, &modelErr) {
+		switch modelErr.Code {
+		case common.ErrorCodeRateLimitError, common.ErrorCodeBudgetExceeded:
+			return http.StatusTooManyRequests, string(modelErr.Code)
+		case common.ErrorCodeTimeoutError:
+			return http.StatusGatewayTimeout, string(modelErr.Code)
+		case common.ErrorCodeInvalidRequest, common.ErrorCodeUnsupportedParameter, common.ErrorCodeTokenLimitExceeded, common.ErrorCodeContentFiltered:
+			return http.StatusBadRequest, string(modelErr.Code)
+		case common.ErrorCodeServiceUnavailable:
+			return http.StatusServiceUnavailable, string(modelErr.Code)
+		}
+		return http.StatusBadGateway, string(modelErr.Code)
+	}
+
+	if errors.Is(err, interfaces.ErrInvalidToolInput) {
+		return http.StatusBadRequest, string(common.ErrorCodeInvalidRequest)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, string(common.ErrorCodeTimeoutError)
+	}
+
+	return http.StatusInternalServerError, string(common.ErrorCodeInternalError)
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, &ErrorResponse{Error: &ErrorBody{Code: code, Message: message}})
+}
+
+// sseWriter writes Server-Sent Events, sending headers lazily on the first event
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool
+	mutex   sync.Mutex
+}
+
+// newSSEWriter creates a new SSE writer
+func newSSEWriter(w http.ResponseWriter, flusher http.Flusher) *sseWriter {
+	return &sseWriter{w: w, flusher: flusher}
+}
+
+// isStarted reports whether the SSE headers were already sent
+func (sw *sseWriter) isStarted() bool {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	return sw.started
+}
+
+// writeData writes an unnamed event with a JSON payload
+func (sw *sseWriter) writeData(payload interface{}) error {
+	return sw.writeEvent("", payload)
+}
+
+// writeEvent writes an optionally named event with a JSON payload
+func (sw *sseWriter) writeEvent(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+
+	return sw.writeRaw(event, data)
+}
+
+// writeDone terminates the stream
+func (sw *sseWriter) writeDone() error {
+	return sw.writeRaw("", []byte("[DONE]"))
+}
+
+// writeRaw writes a single SSE frame and flushes it to the client
+func (sw *sseWriter) writeRaw(event string, data []byte) error {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	if !sw.started {
+		header := sw.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no") // disable nginx proxy buffering
+		sw.w.WriteHeader(http.StatusOK)
+		sw.started = true
+	}
+
+	if event != "" {
+		if _, err := fmt.Fprintf(sw.w, "event: %s\n", event); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(sw.w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+
+	sw.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,289 @@
+package server
+
+// This file implements the HTTP API server for the PonchoFramework.
+// It exposes a running PonchoFramework instance as a JSON service so the framework
+// can be deployed behind the nginx/prometheus setup shipped with the repository
+// (ponchoai-app:8080) instead of every team writing its own wrapper.
+//
+// Endpoints:
+// - GET  /health                        - framework health backed by Health()
+// - GET  /api/v1/models                 - registered models with capabilities
+// - GET  /api/v1/tools                  - registered tools with schemas
+// - GET  /api/v1/flows                  - registered flows with dependencies
+// - POST /api/v1/generate               - unary model generation
+// - POST /api/v1/generate/stream        - streaming generation as Server-Sent Events
+// - POST /api/v1/tools/{name}/execute   - tool execution
+// - POST /api/v1/flows/{name}/execute   - flow execution
+// - POST /api/v1/flows/{name}/stream    - streaming flow execution as Server-Sent Events
+// - GET  /metrics                       - Prometheus metrics (framework + HTTP requests)
+//
+// The server only depends on the interfaces.PonchoFramework contract, so it can wrap
+// PonchoFrameworkImpl or any other implementation (e.g. test doubles).
+//
+// API keys are read from the "Authorization: Bearer <key>" or "X-API-Key" header and
+// put into the request context. Keys are enforced by serving a security.Guard: every
+// API request is authenticated and rate limited before its handler runs, so rejections
+// (401 or 429) never depend on which models, tools or flows exist. /metrics and
+// keyless /health probes stay open; such probes only get the overall status.
+// Callers without a key are rate limited by their remote address.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/metrics"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/security"
+)
+
+// DefaultAddr is the address the server listens on by default
+const DefaultAddr = ":8080"
+
+// Config holds HTTP server settings
+type Config struct {
+	Addr            string        `yaml:"addr" json:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" json:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" json:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" json:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" json:"shutdown_timeout"`
+	MaxBodyBytes    int64         `yaml:"max_body_bytes" json:"max_body_bytes"`
+}
+
+// DefaultConfig returns the default server configuration.
+// WriteTimeout is disabled by default because streaming responses can be long-lived.
+func DefaultConfig() *Config {
+	return &Config{
+		Addr:            DefaultAddr,
+		ReadTimeout:     30 * time.Second,
+		WriteTimeout:    0,
+		IdleTimeout:     120 * time.Second,
+		ShutdownTimeout: 30 * time.Second,
+		MaxBodyBytes:    32 << 20, // 32MB, enough for inline base64 images
+	}
+}
+
+// Server serves a PonchoFramework over HTTP
+type Server struct {
+	framework   interfaces.PonchoFramework
+	config      *Config
+	logger      interfaces.Logger
+	mux         *http.ServeMux
+	exporter    *metrics.Exporter
+	httpMetrics *metrics.HTTPMetrics
+	httpServer  *http.Server
+	mutex       sync.Mutex
+}
+
+// NewServer creates a new HTTP server for the given framework
+func NewServer(framework interfaces.PonchoFramework, cfg *Config, logger interfaces.Logger) *Server {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = DefaultAddr
+	}
+	if logger == nil {
+		logger = interfaces.NewDefaultLogger()
+	}
+
+	s := &Server{
+		framework:   framework,
+		config:      cfg,
+		logger:      logger,
+		mux:         http.NewServeMux(),
+		exporter:    metrics.NewExporter(logger),
+		httpMetrics: metrics.NewHTTPMetrics(nil),
+	}
+
+	s.exporter.Register(metrics.NewFrameworkCollector(framework))
+	s.exporter.Register(s.httpMetrics)
+
+	s.registerRoutes()
+
+	return s
+}
+
+// registerRoutes wires all API endpoints into the server mux
+func (s *Server) registerRoutes() {
+	s.handle("GET /health", http.HandlerFunc(s.handleHealth))
+
+	s.handle("GET /api/v1/models", http.HandlerFunc(s.handleListModels))
+	s.handle("GET /api/v1/tools", http.HandlerFunc(s.handleListTools))
+	s.handle("GET /api/v1/flows", http.HandlerFunc(s.handleListFlows))
+
+	s.handle("POST /api/v1/generate", http.HandlerFunc(s.handleGenerate))
+	s.handle("POST /api/v1/generate/stream", http.HandlerFunc(s.handleGenerateStreaming))
+	s.handle("POST /api/v1/tools/{name}/execute", http.HandlerFunc(s.handleExecuteTool))
+	s.handle("POST /api/v1/flows/{name}/execute", http.HandlerFunc(s.handleExecuteFlow))
+	s.handle("POST /api/v1/flows/{name}/stream", http.HandlerFunc(s.handleExecuteFlowStreaming))
+
+	s.handle("GET /metrics", s.exporter)
+}
+
+// handle registers a handler on the server mux behind authMiddleware, so callers
+// are authenticated once the mux has matched the route (and set r.Pattern, which
+// the metrics middleware labels requests with)
+func (s *Server) handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, s.authMiddleware(handler))
+}
+
+// Handle registers an additional handler on the server mux.
+// It allows callers to mount extra endpoints (e.g. metrics exporters) next to the API.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.handle(pattern, handler)
+}
+
+// Exporter returns the Prometheus exporter serving /metrics.
+// Additional collectors can be registered on it.
+func (s *Server) Exporter() *metrics.Exporter {
+	return s.exporter
+}
+
+// Handler returns the root HTTP handler of the server
+func (s *Server) Handler() http.Handler {
+	return s.httpMetrics.Middleware(s.recoverMiddleware(s.mux))
+}
+
+// Addr returns the configured listen address
+func (s *Server) Addr() string {
+	return s.config.Addr
+}
+
+// ListenAndServe starts serving requests and blocks until the context is cancelled
+// or the listener fails. On cancellation the server is shut down gracefully.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Addr, err)
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves requests on the given listener until the context is cancelled
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	s.mutex.Lock()
+	if s.httpServer != nil {
+		s.mutex.Unlock()
+		return fmt.Errorf("server is already running")
+	}
+	s.httpServer = &http.Server{
+		Handler:      s.Handler(),
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
+	}
+	httpServer := s.httpServer
+	s.mutex.Unlock()
+
+	s.logger.Info("HTTP server listening", "addr", listener.Addr().String())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+		defer cancel()
+		return s.Shutdown(shutdownCtx)
+	}
+}
+
+// Shutdown gracefully stops the server, waiting for in-flight requests to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	httpServer := s.httpServer
+	s.httpServer = nil
+	s.mutex.Unlock()
+
+	if httpServer == nil {
+		return nil
+	}
+
+	s.logger.Info("Shutting down HTTP server")
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown http server: %w", err)
+	}
+
+	s.logger.Info("HTTP server stopped")
+	return nil
+}
+
+// recoverMiddleware converts handler panics into 500 responses
+func (s *Server) recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				s.logger.Error("Panic while handling request", "method", r.Method, "path", r.URL.Path, "panic", rec)
+				writeError(w, http.StatusInternalServerError, string(common.ErrorCodeInternalError), "internal server error")
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticator authenticates callers before their requests are handled (security.Guard)
+type authenticator interface {
+	Authenticate(ctx context.Context) (context.Context, error)
+}
+
+// authMiddleware puts the API key and remote address of the caller into the request
+// context and, when a security.Guard is served, authenticates the caller
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	guard, guarded := s.framework.(authenticator)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := security.WithClientAddr(r.Context(), remoteHost(r))
+		key := apiKey(r)
+		if key != "" {
+			ctx = security.WithAPIKey(ctx, key)
+		}
+
+		// Scrapers and keyless health probes pass; health hides details from them
+		public := r.URL.Path == "/metrics" || (r.URL.Path == "/health" && key == "")
+		if guarded && !public {
+			authenticated, err := guard.Authenticate(ctx)
+			if err != nil {
+				s.writeFrameworkError(w, "authenticate", r.URL.Path, err)
+				return
+			}
+			ctx = authenticated
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiKey returns the API key from the X-API-Key or Authorization: Bearer header
+func apiKey(r *http.Request) string {
+	key := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		key = strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return key
+}
+
+// remoteHost returns the host of the remote address of a request
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
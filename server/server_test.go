@@ -0,0 +1,421 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilkoid/PonchoAiFramework/core"
+	"github.com/ilkoid/PonchoAiFramework/core/base"
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+	"github.com/ilkoid/PonchoAiFramework/security"
+)
+
+// testModel is a scripted model used by server tests
+type testModel struct {
+	*base.PonchoBaseModel
+	generateErr error
+	chunks      []string
+}
+
+func newTestModel(name string) *testModel {
+	return &testModel{
+		PonchoBaseModel: base.NewPonchoBaseModel(name, "test", interfaces.ModelCapabilities{
+			Streaming: true,
+			Tools:     true,
+			System:    true,
+		}),
+		chunks: []string{"Hello", ", world"},
+	}
+}
+
+func (m *testModel) Generate(ctx context.Context, req *interfaces.PonchoModelRequest) (*interfaces.PonchoModelResponse, error) {
+	if m.generateErr != nil {
+		return nil, m.generateErr
+	}
+
+	return &interfaces.PonchoModelResponse{
+		Message: &interfaces.PonchoMessage{
+			Role: interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{
+				{Type: interfaces.PonchoContentTypeText, Text: "echo: " + req.Messages[0].Content[0].Text},
+			},
+		},
+		Usage:        &interfaces.PonchoUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		FinishReason: interfaces.PonchoFinishReasonStop,
+	}, nil
+}
+
+func (m *testModel) GenerateStreaming(ctx context.Context, req *interfaces.PonchoModelRequest, callback interfaces.PonchoStreamCallback) error {
+	if m.generateErr != nil {
+		return m.generateErr
+	}
+
+	for i, text := range m.chunks {
+		chunk := &interfaces.PonchoStreamChunk{
+			Delta: &interfaces.PonchoMessage{
+				Role:    interfaces.PonchoRoleAssistant,
+				Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: text}},
+			},
+			Done: i == len(m.chunks)-1,
+		}
+		if err := callback(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// testTool echoes its input
+type testTool struct {
+	*base.PonchoBaseTool
+}
+
+func (t *testTool) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	return map[string]interface{}{"echo": input}, nil
+}
+
+func (t *testTool) Validate(input interface{}) error {
+	if _, ok := input.(map[string]interface{}); !ok {
+		return errors.New("input must be an object")
+	}
+	return nil
+}
+
+// testFlow streams a single chunk
+type testFlow struct {
+	*base.PonchoBaseFlow
+}
+
+func (f *testFlow) Execute(ctx context.Context, input interface{}) (interface{}, error) {
+	return map[string]interface{}{"done": true}, nil
+}
+
+func (f *testFlow) ExecuteStreaming(ctx context.Context, input interface{}, callback interfaces.PonchoStreamCallback) error {
+	return callback(&interfaces.PonchoStreamChunk{
+		Delta: &interfaces.PonchoMessage{
+			Role:    interfaces.PonchoRoleAssistant,
+			Content: []*interfaces.PonchoContentPart{{Type: interfaces.PonchoContentTypeText, Text: "flow chunk"}},
+		},
+		Done: true,
+	})
+}
+
+func newTestServer(t *testing.T) (*Server, *core.PonchoFrameworkImpl, *testModel) {
+	t.Helper()
+
+	logger := interfaces.NewNoOpLogger()
+	framework := core.NewPonchoFramework(&interfaces.PonchoFrameworkConfig{}, logger)
+	require.NoError(t, framework.Start(context.Background()))
+	t.Cleanup(func() { _ = framework.Stop(context.Background()) })
+
+	model := newTestModel("test-model")
+	require.NoError(t, framework.RegisterModel("test-model", model))
+	require.NoError(t, framework.RegisterTool("echo", &testTool{base.NewPonchoBaseTool("echo", "Echo tool", "1.0.0", "test")}))
+	require.NoError(t, framework.RegisterFlow("demo", &testFlow{base.NewPonchoBaseFlow("demo", "Demo flow", "1.0.0", "test")}))
+
+	return NewServer(framework, nil, logger), framework, model
+}
+
+func doRequest(t *testing.T, handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+const generateBody = `{"model":"test-model","messages":[{"role":"user","content":[{"type":"text","text":"hi"}]}]}`
+
+func TestHealth(t *testing.T) {
+	srv, framework, _ := newTestServer(t)
+
+	rec := doRequest(t, srv.Handler(), http.MethodGet, "/health", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var health interfaces.PonchoHealthStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+	assert.Equal(t, "healthy", health.Status)
+
+	require.NoError(t, framework.Stop(context.Background()))
+	rec = doRequest(t, srv.Handler(), http.MethodGet, "/health", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestListRegistries(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	rec := doRequest(t, srv.Handler(), http.MethodGet, "/api/v1/models", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var models struct {
+		Models []*ModelInfo `json:"models"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &models))
+	require.Len(t, models.Models, 1)
+	assert.Equal(t, "test-model", models.Models[0].Name)
+	assert.True(t, models.Models[0].Supports.Streaming)
+
+	rec = doRequest(t, srv.Handler(), http.MethodGet, "/api/v1/tools?category=test", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"echo"`)
+
+	rec = doRequest(t, srv.Handler(), http.MethodGet, "/api/v1/flows", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"demo"`)
+}
+
+func TestGenerate(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	rec := doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/generate", generateBody)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response interfaces.PonchoModelResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "echo: hi", response.Message.Content[0].Text)
+	assert.Equal(t, 5, response.Usage.TotalTokens)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	srv, _, model := newTestServer(t)
+
+	rec := doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/generate", `{"model":"missing","messages":[]}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/generate", `{not json`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/generate", ``)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	model.generateErr = common.NewRateLimitError("slow down", "test", "test-model")
+	rec = doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/generate", generateBody)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	var errResp ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+	assert.Equal(t, string(common.ErrorCodeRateLimitError), errResp.Error.Code)
+}
+
+func TestGenerateStreaming(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	rec := doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/generate/stream", generateBody)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	events := readSSEData(t, rec.Body.String())
+	require.Len(t, events, 3)
+	assert.Equal(t, "[DONE]", events[2])
+
+	var chunk interfaces.PonchoStreamChunk
+	require.NoError(t, json.Unmarshal([]byte(events[0]), &chunk))
+	assert.Equal(t, "Hello", chunk.Delta.Content[0].Text)
+}
+
+func TestGenerateStreamingErrorBeforeFirstChunk(t *testing.T) {
+	srv, _, model := newTestServer(t)
+	model.generateErr = common.NewModelError(common.ErrorCodeServerError, "boom", "test", "test-model")
+
+	rec := doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/generate/stream", generateBody)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
+
+func TestExecuteTool(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	rec := doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/tools/echo/execute", `{"input":{"article_id":"123"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response ExecuteResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "echo", response.Name)
+	assert.Equal(t, map[string]interface{}{"echo": map[string]interface{}{"article_id": "123"}}, response.Result)
+
+	rec = doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/tools/echo/execute", `{"input":"123"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), string(common.ErrorCodeInvalidRequest))
+	assert.Contains(t, rec.Body.String(), "input must be an object")
+
+	rec = doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/tools/missing/execute", `{}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestExecuteFlow(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	rec := doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/flows/demo/execute", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"done":true`)
+
+	rec = doRequest(t, srv.Handler(), http.MethodPost, "/api/v1/flows/demo/stream", `{"input":"x"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	events := readSSEData(t, rec.Body.String())
+	require.Len(t, events, 2)
+	assert.Contains(t, events[0], "flow chunk")
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+	handler := srv.Handler()
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/v1/generate", generateBody)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(t, handler, http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `model_requests_total{model="test-model"} 1`)
+	assert.Contains(t, body, `model_request_duration_seconds_count{model="test-model"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="POST",path="POST /api/v1/generate",status="200"} 1`)
+	assert.Contains(t, body, "poncho_goroutines ")
+}
+
+func TestListenAndServeShutdown(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+// readSSEData extracts data payloads from an SSE body
+func readSSEData(t *testing.T, body string) []string {
+	t.Helper()
+
+	var events []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data: ") {
+			events = append(events, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestAPIKeyGuard(t *testing.T) {
+	_, framework, _ := newTestServer(t)
+
+	guard, err := security.NewGuard(framework, &interfaces.SecurityConfig{
+		APIKeys:      []string{"team-key"},
+		RateLimiting: &interfaces.RateLimitConfig{RequestsPerMinute: 1},
+	}, interfaces.NewNoOpLogger())
+	require.NoError(t, err)
+	handler := NewServer(guard, nil, interfaces.NewNoOpLogger()).Handler()
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/v1/generate", generateBody)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), string(security.ErrorCodeMissingAPIKey))
+
+	send := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/generate", strings.NewReader(generateBody))
+		req.Header.Set(header, value)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("X-API-Key", "other-key").Code)
+	assert.Equal(t, http.StatusOK, send("Authorization", "Bearer team-key").Code)
+
+	rec = send("X-API-Key", "team-key")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), string(security.ErrorCodeRateLimitExceeded))
+}
+
+func TestAPIKeyGuard_BeforeRouting(t *testing.T) {
+	_, framework, _ := newTestServer(t)
+
+	guard, err := security.NewGuard(framework, &interfaces.SecurityConfig{APIKeys: []string{"team-key"}}, interfaces.NewNoOpLogger())
+	require.NoError(t, err)
+	handler := NewServer(guard, nil, interfaces.NewNoOpLogger()).Handler()
+
+	// Unknown callers cannot tell registered names from unknown ones
+	for _, path := range []string{"/api/v1/tools/echo/execute", "/api/v1/tools/missing/execute", "/api/v1/flows/missing/execute"} {
+		rec := doRequest(t, handler, http.MethodPost, path, `{"input": 1}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+	rec := doRequest(t, handler, http.MethodPost, "/api/v1/generate", `{"model":"missing","messages":[]}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	for _, path := range []string{"/api/v1/models", "/api/v1/tools", "/api/v1/flows"} {
+		assert.Equal(t, http.StatusUnauthorized, doRequest(t, handler, http.MethodGet, path, "").Code, path)
+	}
+
+	// Keyless health probes only get the status
+	rec = doRequest(t, handler, http.MethodGet, "/health", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var health map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+	assert.Equal(t, map[string]interface{}{"status": "healthy"}, health)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tools", nil)
+	req.Header.Set("X-API-Key", "team-key")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"echo"`)
+
+	// Rejected requests are labelled with the route they matched
+	rec = doRequest(t, handler, http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `http_requests_total{method="POST",path="POST /api/v1/tools/{name}/execute",status="401"} 2`)
+}
+
+func TestAnonymousRateLimitByAddress(t *testing.T) {
+	_, framework, _ := newTestServer(t)
+
+	guard, err := security.NewGuard(framework, &interfaces.SecurityConfig{
+		RateLimiting: &interfaces.RateLimitConfig{RequestsPerMinute: 1},
+	}, interfaces.NewNoOpLogger())
+	require.NoError(t, err)
+	handler := NewServer(guard, nil, interfaces.NewNoOpLogger()).Handler()
+
+	send := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/models", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:5000"))
+	assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.1:5001"))
+	assert.Equal(t, http.StatusOK, send("10.0.0.2:5000"))
+}
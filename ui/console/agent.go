@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
 )
 
 // AgentConfig represents configuration for the AI agent
@@ -95,28 +96,26 @@ func (ui *SimpleConsoleUI) handleAgentInputWithAI(ctx context.Context, line stri
 	// Stream the response
 	fmt.Fprint(ui.Out, "assistant: ")
 	fullResponse := ""
+	renderer := newContentRenderer(ui.Out, ui.CollapseReasoning)
 
 	// Handle streaming response using framework
 	if config.Stream {
-		err := ui.Framework.GenerateStreaming(ctx, request, func(chunk *interfaces.PonchoStreamChunk) error {
-			if chunk.Done {
-				return nil
-			}
-
-			if chunk.Delta != nil && chunk.Delta.Content != nil {
+		// The final chunk may still carry text, so render every chunk
+		stream := common.NewStreamAccumulator()
+		err := ui.Framework.GenerateStreaming(ctx, request, stream.Callback(func(chunk *interfaces.PonchoStreamChunk) error {
+			if chunk.Delta != nil {
 				for _, part := range chunk.Delta.Content {
-					if part.Type == interfaces.PonchoContentTypeText {
-						fmt.Fprint(ui.Out, part.Text)
-						fullResponse += part.Text
-					}
+					renderer.write(part)
 				}
 			}
 			return nil
-		})
+		}))
 
+		renderer.finish()
 		if err != nil {
 			return fmt.Errorf("failed to generate streaming response: %w", err)
 		}
+		fullResponse = stream.Text()
 		fmt.Fprintln(ui.Out) // New line after streaming
 	} else {
 		// Non-streaming response
@@ -126,13 +125,19 @@ func (ui *SimpleConsoleUI) handleAgentInputWithAI(ctx context.Context, line stri
 		}
 
 		if response.Message != nil && response.Message.Content != nil {
+			// Reasoning comes after the answer in responses; show it first
+			for _, part := range response.Message.Content {
+				if part.Type == interfaces.PonchoContentTypeReasoning {
+					renderer.write(part)
+				}
+			}
 			for _, part := range response.Message.Content {
-				if part.Type == interfaces.PonchoContentTypeText {
-					fmt.Fprint(ui.Out, part.Text)
-					fullResponse += part.Text
+				if part.Type != interfaces.PonchoContentTypeReasoning {
+					fullResponse += renderer.write(part)
 				}
 			}
 		}
+		renderer.finish()
 		fmt.Fprintln(ui.Out) // New line after response
 	}
 
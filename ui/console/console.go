@@ -26,6 +26,9 @@ type SimpleConsoleUI struct {
 	ArticleFlow interface{} // Will be *articleflow.ArticleFlow once imported
 	Logger     interfaces.Logger
 
+	// CollapseReasoning shows model reasoning as a one-line summary instead of dimmed text
+	CollapseReasoning bool
+
 	// Internal state
 	chatHistory      []*interfaces.PonchoMessage
 	lastFlowState    interface{} // Will hold the last ArticleFlowState
@@ -401,4 +401,37 @@ func TestBuildMessagesWithSystem(t *testing.T) {
 	if messages[1].Role != interfaces.PonchoRoleUser {
 		t.Errorf("Expected second message to be user")
 	}
-}
\ No newline at end of file
+}
+
+// Test rendering of reasoning and tool results
+func TestContentRenderer(t *testing.T) {
+	reasoning := &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeReasoning, Text: "Let me think"}
+	answer := &interfaces.PonchoContentPart{Type: interfaces.PonchoContentTypeText, Text: "Red dress"}
+
+	output := &bytes.Buffer{}
+	renderer := newContentRenderer(output, false)
+	renderer.write(reasoning)
+	if text := renderer.write(answer); text != "Red dress" {
+		t.Errorf("Expected answer text to be returned, got %q", text)
+	}
+	renderer.finish()
+
+	if want := ansiDim + "thinking: Let me think" + ansiReset + "\nRed dress"; output.String() != want {
+		t.Errorf("Expected dimmed reasoning before the answer, got %q", output.String())
+	}
+
+	output.Reset()
+	renderer = newContentRenderer(output, true)
+	renderer.write(reasoning)
+	renderer.write(&interfaces.PonchoContentPart{
+		Type:       interfaces.PonchoContentTypeToolResult,
+		ToolResult: &interfaces.PonchoToolResultPart{ToolCallID: "call_1", Name: "get_article", Content: "missing", IsError: true},
+	})
+
+	if strings.Contains(output.String(), "Let me think") {
+		t.Errorf("Expected collapsed reasoning to be hidden, got %q", output.String())
+	}
+	if !strings.Contains(output.String(), "12 characters hidden") || !strings.Contains(output.String(), `[get_article error] {"error":"missing"}`) {
+		t.Errorf("Unexpected collapsed output: %q", output.String())
+	}
+}
@@ -0,0 +1,88 @@
+package console
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ilkoid/PonchoAiFramework/interfaces"
+	"github.com/ilkoid/PonchoAiFramework/models/common"
+)
+
+// ANSI escape sequences used for secondary output
+const (
+	ansiDim   = "\033[2m"
+	ansiReset = "\033[0m"
+)
+
+// contentRenderer writes assistant content parts to the console. Reasoning is
+// shown dimmed before the answer, or collapsed to its size when collapse is set.
+type contentRenderer struct {
+	out      io.Writer
+	collapse bool
+
+	inReasoning    bool
+	reasoningChars int
+}
+
+// newContentRenderer creates a renderer writing to out
+func newContentRenderer(out io.Writer, collapse bool) *contentRenderer {
+	return &contentRenderer{out: out, collapse: collapse}
+}
+
+// write renders one content part and returns the answer text it contains
+func (r *contentRenderer) write(part *interfaces.PonchoContentPart) string {
+	if part == nil {
+		return ""
+	}
+
+	switch part.Type {
+	case interfaces.PonchoContentTypeReasoning:
+		if !r.inReasoning {
+			r.inReasoning = true
+			fmt.Fprint(r.out, ansiDim+"thinking: ")
+		}
+		r.reasoningChars += len([]rune(part.Text))
+		if !r.collapse {
+			fmt.Fprint(r.out, part.Text)
+		}
+	case interfaces.PonchoContentTypeText:
+		r.endReasoning()
+		fmt.Fprint(r.out, part.Text)
+		return part.Text
+	case interfaces.PonchoContentTypeTool:
+		if part.Tool != nil {
+			r.endReasoning()
+			fmt.Fprintf(r.out, "%s[calling %s]%s\n", ansiDim, part.Tool.Name, ansiReset)
+		}
+	case interfaces.PonchoContentTypeToolResult:
+		if part.ToolResult != nil {
+			r.endReasoning()
+			status := "result"
+			if part.ToolResult.IsError {
+				status = "error"
+			}
+			fmt.Fprintf(r.out, "%s[%s %s] %s%s\n", ansiDim, part.ToolResult.Name, status, common.ToolResultText(part.ToolResult), ansiReset)
+		}
+	}
+
+	return ""
+}
+
+// finish closes an open reasoning block
+func (r *contentRenderer) finish() {
+	r.endReasoning()
+}
+
+// endReasoning closes the dimmed reasoning block before other output
+func (r *contentRenderer) endReasoning() {
+	if !r.inReasoning {
+		return
+	}
+	r.inReasoning = false
+
+	if r.collapse {
+		fmt.Fprintf(r.out, "%d characters hidden", r.reasoningChars)
+	}
+	fmt.Fprintln(r.out, ansiReset)
+	r.reasoningChars = 0
+}